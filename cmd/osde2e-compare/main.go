@@ -0,0 +1,93 @@
+// Command osde2e-compare shows differences in key metrics between the snapshots of two runs.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/openshift/osde2e/pkg/metrics"
+)
+
+var threshold float64
+
+func init() {
+	flag.Float64Var(&threshold, "threshold", 10, "minimum percent change shown")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] <before> <after>\n\n", os.Args[0])
+		fmt.Fprintf(flag.CommandLine.Output(), "Snapshots are paths or URLs of %s artifacts.\n\n", metrics.SnapshotFile)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+}
+
+func main() {
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	before, err := loadSnapshot(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	after, err := loadSnapshot(flag.Arg(1))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	changes := metrics.Diff(before, after, threshold)
+	if len(changes) == 0 {
+		fmt.Printf("No metrics changed by more than %.1f%%.\n", threshold)
+		return
+	}
+
+	// changes may be due to differences in where clusters ran rather than the release
+	for k, v := range after.Labels {
+		if prev, ok := before.Labels[k]; ok && prev != v {
+			fmt.Printf("Warning: %s differs between snapshots ('%s' and '%s').\n", k, prev, v)
+		}
+	}
+
+	fmt.Printf("Comparing %s to %s:\n\n", before.Time, after.Time)
+	if err = metrics.WriteDiff(os.Stdout, changes); err != nil {
+		log.Fatalf("Failed to write diff: %v", err)
+	}
+}
+
+// loadSnapshot reads a snapshot from a file or HTTP(S) URL.
+func loadSnapshot(location string) (*metrics.Snapshot, error) {
+	var data []byte
+	if strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://") {
+		resp, err := http.Get(location)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't get snapshot '%s': %v", location, err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("getting snapshot '%s' returned status '%s'", location, resp.Status)
+		}
+
+		if data, err = ioutil.ReadAll(resp.Body); err != nil {
+			return nil, fmt.Errorf("couldn't read snapshot '%s': %v", location, err)
+		}
+	} else {
+		var err error
+		if data, err = ioutil.ReadFile(location); err != nil {
+			return nil, fmt.Errorf("couldn't read snapshot '%s': %v", location, err)
+		}
+	}
+
+	snapshot := new(metrics.Snapshot)
+	if err := json.Unmarshal(data, snapshot); err != nil {
+		return nil, fmt.Errorf("couldn't decode snapshot '%s': %v", location, err)
+	}
+	return snapshot, nil
+}
@@ -0,0 +1,90 @@
+// Command osde2e-decrypt decrypts artifacts encrypted because they may contain secrets, such as must-gather output
+// and kubeconfigs, decompressing them too.
+package main
+
+import (
+	"crypto/rsa"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/openshift/osde2e/pkg/artifacts"
+)
+
+var (
+	// keyFile is the PEM encoded RSA private key of the environment the artifacts were encrypted for.
+	keyFile string
+
+	// outDir is where decrypted artifacts are written. They're written next to the encrypted artifacts if empty.
+	outDir string
+)
+
+func init() {
+	flag.StringVar(&keyFile, "key", "", "PEM encoded RSA private key of the environment artifacts were encrypted for")
+	flag.StringVar(&outDir, "out", "", "directory to write decrypted artifacts to, defaults to that of each artifact")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s -key <private key> <artifact%s>...\n\n", os.Args[0],
+			artifacts.EncryptedExt)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+}
+
+func main() {
+	if keyFile == "" || flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	key, err := artifacts.LoadPrivateKey(keyFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	dec, err := zstd.NewReader(nil)
+	if err != nil {
+		log.Fatalf("Could not setup decompression: %v", err)
+	}
+
+	failed := false
+	for _, file := range flag.Args() {
+		out, err := decrypt(key, dec, file)
+		if err != nil {
+			log.Printf("Failed to decrypt '%s': %v", file, err)
+			failed = true
+			continue
+		}
+		log.Printf("Decrypted '%s' to '%s'.", file, out)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// decrypt writes the decrypted and decompressed contents of file, returning where they were written.
+func decrypt(key *rsa.PrivateKey, dec *zstd.Decoder, file string) (string, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+	if data, err = artifacts.Decrypt(key, data); err != nil {
+		return "", err
+	}
+
+	out := strings.TrimSuffix(file, artifacts.EncryptedExt)
+	if strings.HasSuffix(out, artifacts.Ext) {
+		if data, err = dec.DecodeAll(data, nil); err != nil {
+			return "", fmt.Errorf("couldn't decompress: %v", err)
+		}
+		out = strings.TrimSuffix(out, artifacts.Ext)
+	}
+	if outDir != "" {
+		out = filepath.Join(outDir, filepath.Base(out))
+	}
+	return out, ioutil.WriteFile(out, data, 0600)
+}
@@ -50,6 +50,22 @@ var (
 				Name:        "testgrid",
 				Description: "These options configure reporting test results to TestGrid.",
 			},
+			{
+				Name:        "slack",
+				Description: "These options configure posting progress of long runs to a Slack thread.",
+			},
+			{
+				Name:        "tracing",
+				Description: "These options configure exporting runs as OpenTelemetry traces, with spans for each phase and test.",
+			},
+			{
+				Name:        "pool",
+				Description: "These options configure `osde2e-pool`, which keeps clusters installed ahead of the runs claiming them.",
+			},
+			{
+				Name:        "webhook",
+				Description: "These options configure `osde2e-webhook`, which starts runs for webhooks announcing releases.",
+			},
 		},
 	}
 )
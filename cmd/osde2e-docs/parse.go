@@ -64,6 +64,7 @@ func parseOpts(dir string) (opts map[string]Options) {
 										Variable:    env,
 										Description: field.Doc.Text(),
 										Type:        getFieldType(field.Type),
+										Default:     tag.Get(config.DefaultTag),
 									})
 								}
 							}
@@ -83,6 +84,10 @@ func getFieldType(expr ast.Expr) string {
 	case *ast.ArrayType:
 		arrTyp := t.Elt
 		return fmt.Sprintf("[]%s", getFieldType(arrTyp))
+	case *ast.MapType:
+		return fmt.Sprintf("map[%s]%s", getFieldType(t.Key), getFieldType(t.Value))
+	case *ast.SelectorExpr:
+		return fmt.Sprintf("%s.%s", getFieldType(t.X), t.Sel)
 	}
 
 	typErr := fmt.Sprintf("encountered unexpected AST type while parsing: %T", expr)
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/openshift/osde2e/pkg/config"
+	"github.com/openshift/osde2e/pkg/doctor"
+)
+
+var (
+	// Cfg is the global configuration for the command.
+	Cfg = config.Cfg
+
+	// Out has the report written to it.
+	Out io.Writer = os.Stdout
+
+	// minFree is the least free space for artifacts which passes.
+	minFree string
+
+	// asJSON writes the report as JSON instead of a table.
+	asJSON bool
+)
+
+func init() {
+	flag.StringVar(&minFree, "min-free", doctor.DefaultMinFreeSpace.String(), "least free space for artifacts in REPORT_DIR")
+	flag.BoolVar(&asJSON, "json", false, "write the report as JSON")
+	flag.Parse()
+}
+
+func main() {
+	if config.LoadErr != nil {
+		log.Fatalf("Failed to load config from environment: %v", config.LoadErr)
+	}
+	min, err := resource.ParseQuantity(minFree)
+	if err != nil {
+		log.Fatalf("Invalid -min-free: %v", err)
+	}
+
+	d := doctor.New(Cfg)
+	d.KubeconfigErr = Cfg.ReadKubeconfig()
+	d.MinFreeSpace = min.Value()
+	r := d.Run()
+
+	if asJSON {
+		enc := json.NewEncoder(Out)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(r)
+	} else {
+		err = writeTable(r)
+	}
+	if err != nil {
+		log.Fatalf("Couldn't write report: %v", err)
+	}
+
+	if !r.Passed {
+		os.Exit(1)
+	}
+}
+
+func writeTable(r *doctor.Report) error {
+	status := "ready"
+	if !r.Passed {
+		status = "not ready"
+	}
+	fmt.Fprintf(Out, "Environment is %s to run osde2e\n\n", status)
+
+	w := tabwriter.NewWriter(Out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "CHECK\tSTATUS\tDETAIL")
+	for _, res := range r.Results {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", res.Check, res.Status, res.Detail)
+	}
+	return w.Flush()
+}
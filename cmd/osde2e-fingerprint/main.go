@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+	"log"
+	"os"
+
+	"github.com/onsi/gomega"
+
+	"github.com/openshift/osde2e/pkg/config"
+	"github.com/openshift/osde2e/pkg/fingerprint"
+	"github.com/openshift/osde2e/pkg/helper"
+	"github.com/openshift/osde2e/pkg/osd"
+)
+
+var (
+	// Cfg is the global configuration for the command.
+	Cfg = config.Cfg
+
+	// Out has the fingerprint written to it when no output file is set.
+	Out io.Writer = os.Stdout
+
+	// clusterID is the OSD cluster to fingerprint. TEST_KUBECONFIG is used if it's not set.
+	clusterID string
+
+	// outDir is the directory the fingerprint is written to. It's written to Out if empty.
+	outDir string
+)
+
+func init() {
+	flag.StringVar(&clusterID, "cluster-id", "", "OSD cluster to fingerprint, defaults to CLUSTER_ID or the cluster of TEST_KUBECONFIG")
+	flag.StringVar(&outDir, "out", "", "directory to write "+fingerprint.File+" to, defaults to stdout")
+	flag.Parse()
+}
+
+func main() {
+	if config.LoadErr != nil {
+		log.Fatalf("Failed to load config from environment: %v", config.LoadErr)
+	}
+	if clusterID != "" {
+		Cfg.ClusterID = clusterID
+	}
+	if Cfg.ClusterID == "" && len(Cfg.Kubeconfig) == 0 {
+		log.Fatal("A cluster ID must be specified with -cluster-id, or TEST_KUBECONFIG set")
+	}
+	if err := Cfg.ReadKubeconfig(); err != nil {
+		log.Fatal(err)
+	}
+
+	// add-ons are only known to OSD
+	var OSD *osd.OSD
+	if Cfg.ClusterID != "" {
+		var err error
+		if OSD, err = osd.NewForConfig(Cfg); err != nil {
+			log.Fatalf("Could not setup OSD client: %v", err)
+		}
+		if len(Cfg.Kubeconfig) == 0 {
+			if Cfg.Kubeconfig, err = OSD.ClusterKubeconfig(Cfg.ClusterID); err != nil {
+				log.Fatalf("Could not get kubeconfig for cluster '%s': %v", Cfg.ClusterID, err)
+			}
+		}
+	}
+
+	// helpers assert with gomega, so failures outside of tests must be handled
+	gomega.RegisterFailHandler(func(msg string, _ ...int) {
+		log.Fatal(msg)
+	})
+	h := &helper.H{Config: Cfg}
+	h.SetupClients()
+
+	f, err := fingerprint.Collect(h)
+	if err != nil {
+		log.Fatalf("Could not fingerprint cluster: %v", err)
+	}
+	if OSD != nil {
+		if snapshot, err := OSD.ClusterSnapshot(Cfg.ClusterID); err != nil {
+			log.Printf("Not fingerprinting add-ons of cluster '%s': %v", Cfg.ClusterID, err)
+		} else {
+			f.SetAddons(snapshot)
+		}
+	}
+
+	if outDir != "" {
+		err = f.Write(outDir)
+	} else {
+		enc := json.NewEncoder(Out)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(f)
+	}
+	if err != nil {
+		log.Fatalf("Couldn't write fingerprint: %v", err)
+	}
+}
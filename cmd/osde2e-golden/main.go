@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/onsi/gomega"
+
+	"github.com/openshift/osde2e/pkg/config"
+	"github.com/openshift/osde2e/pkg/golden"
+	"github.com/openshift/osde2e/pkg/helper"
+	"github.com/openshift/osde2e/pkg/osd"
+)
+
+var (
+	// Cfg is the global configuration for the command.
+	Cfg = config.Cfg
+
+	// Out has the report written to it.
+	Out io.Writer = os.Stdout
+
+	// clusterID is the OSD cluster to capture or compare. TEST_KUBECONFIG is used if it's not set.
+	clusterID string
+
+	// capture is the file a baseline of the cluster is written to.
+	capture string
+
+	// baseline is the file of the baseline the cluster is compared to.
+	baseline string
+
+	// namespaces are regular expressions matching the managed namespaces whose resources are captured.
+	namespaces string
+
+	// ignore are regular expressions matching differences which are expected.
+	ignore string
+
+	// asJSON writes the report as JSON instead of a table.
+	asJSON bool
+)
+
+func init() {
+	flag.StringVar(&clusterID, "cluster-id", "", "OSD cluster to capture or compare, defaults to CLUSTER_ID or the cluster of TEST_KUBECONFIG")
+	flag.StringVar(&capture, "capture", "", "file to write a baseline of the cluster to, such as "+golden.File)
+	flag.StringVar(&baseline, "baseline", "", "baseline to compare the cluster to")
+	flag.StringVar(&namespaces, "namespaces", "^openshift-,^kube-,^default$", "comma separated regular expressions matching managed namespaces")
+	flag.StringVar(&ignore, "ignore", "", "comma separated regular expressions matching differences to ignore, such as '^clusteroperator ' or 'openshift-monitoring/'")
+	flag.BoolVar(&asJSON, "json", false, "write the report as JSON")
+	flag.Parse()
+}
+
+func main() {
+	if config.LoadErr != nil {
+		log.Fatalf("Failed to load config from environment: %v", config.LoadErr)
+	}
+	if (capture == "") == (baseline == "") {
+		log.Fatal("Either -capture or -baseline must be given")
+	}
+	if clusterID != "" {
+		Cfg.ClusterID = clusterID
+	}
+	if Cfg.ClusterID == "" && len(Cfg.Kubeconfig) == 0 {
+		log.Fatal("A cluster ID must be specified with -cluster-id, or TEST_KUBECONFIG set")
+	}
+	if err := Cfg.ReadKubeconfig(); err != nil {
+		log.Fatal(err)
+	}
+
+	managed, err := golden.ParseExprs(strings.Split(namespaces, ","))
+	if err != nil {
+		log.Fatalf("Invalid -namespaces: %v", err)
+	}
+	ignored, err := golden.ParseExprs(strings.Split(ignore, ","))
+	if err != nil {
+		log.Fatalf("Invalid -ignore: %v", err)
+	}
+
+	var expected *golden.Baseline
+	if baseline != "" {
+		if expected, err = golden.Read(baseline); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if Cfg.ClusterID != "" && len(Cfg.Kubeconfig) == 0 {
+		OSD, err := osd.NewForConfig(Cfg)
+		if err != nil {
+			log.Fatalf("Could not setup OSD client: %v", err)
+		}
+		if Cfg.Kubeconfig, err = OSD.ClusterKubeconfig(Cfg.ClusterID); err != nil {
+			log.Fatalf("Could not get kubeconfig for cluster '%s': %v", Cfg.ClusterID, err)
+		}
+	}
+
+	// helpers assert with gomega, so failures outside of tests must be handled
+	gomega.RegisterFailHandler(func(msg string, _ ...int) {
+		log.Fatal(msg)
+	})
+	h := &helper.H{Config: Cfg}
+	h.SetupClients()
+
+	b, err := golden.Capture(h, managed)
+	if err != nil {
+		log.Fatalf("Could not capture cluster: %v", err)
+	}
+
+	if capture != "" {
+		if err = b.Write(capture); err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("Captured %d objects of cluster running %s to '%s'.", len(b.Objects), b.Version, capture)
+		return
+	}
+
+	r := golden.Compare(expected, b, ignored)
+	if asJSON {
+		enc := json.NewEncoder(Out)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(r)
+	} else {
+		err = writeTable(r)
+	}
+	if err != nil {
+		log.Fatalf("Couldn't write report: %v", err)
+	}
+
+	if len(r.Differences) != 0 {
+		os.Exit(1)
+	}
+}
+
+func writeTable(r *golden.Report) error {
+	fmt.Fprintf(Out, "Cluster running %s has %d differences from baseline of %s (%d ignored)\n\n",
+		r.CurrentVersion, len(r.Differences), r.BaselineVersion, r.Ignored)
+	if len(r.Differences) == 0 {
+		return nil
+	}
+
+	w := tabwriter.NewWriter(Out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "OBJECT\tCHANGE\tFIELD\tBASELINE\tCURRENT")
+	for _, d := range r.Differences {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", d.Object, d.Kind, orNone(d.Field), orNone(d.Baseline), orNone(d.Current))
+	}
+	return w.Flush()
+}
+
+// orNone returns s, or a dash if it's empty so table columns stay aligned.
+func orNone(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
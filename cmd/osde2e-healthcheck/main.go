@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/openshift-online/uhc-sdk-go/pkg/client/clustersmgmt/v1"
+	configclient "github.com/openshift/client-go/config/clientset/versioned"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/openshift/osde2e/pkg/config"
+	"github.com/openshift/osde2e/pkg/health"
+	"github.com/openshift/osde2e/pkg/hosted"
+	"github.com/openshift/osde2e/pkg/osd"
+)
+
+// checkOCM is the check that OCM considers the cluster ready.
+const checkOCM = "ocm"
+
+var (
+	// Cfg is the global configuration for the command.
+	Cfg = config.Cfg
+
+	// Out has the report written to it.
+	Out io.Writer = os.Stdout
+
+	// clusterID is the OSD cluster to check. TEST_KUBECONFIG is used if it's not set.
+	clusterID string
+
+	// wait is how long to wait for the cluster to become healthy. It's only checked once if 0.
+	wait time.Duration
+
+	// interval is how often the cluster is checked while waiting.
+	interval time.Duration
+
+	// asJSON writes the report as JSON instead of a table.
+	asJSON bool
+)
+
+func init() {
+	flag.StringVar(&clusterID, "cluster-id", "", "OSD cluster to check, defaults to CLUSTER_ID or the cluster of TEST_KUBECONFIG")
+	flag.DurationVar(&wait, "wait", 0, "how long to wait for the cluster to become healthy, only checking once if 0")
+	flag.DurationVar(&interval, "interval", 15*time.Second, "how often to check the cluster while waiting")
+	flag.BoolVar(&asJSON, "json", false, "write the report as JSON")
+	flag.Parse()
+}
+
+func main() {
+	if config.LoadErr != nil {
+		log.Fatalf("Failed to load config from environment: %v", config.LoadErr)
+	}
+	if clusterID != "" {
+		Cfg.ClusterID = clusterID
+	}
+	if Cfg.ClusterID == "" && len(Cfg.Kubeconfig) == 0 {
+		log.Fatal("A cluster ID must be specified with -cluster-id, or TEST_KUBECONFIG set")
+	}
+	if err := Cfg.ReadKubeconfig(); err != nil {
+		log.Fatal(err)
+	}
+
+	// OSD clusters must also be ready in OCM
+	var OSD *osd.OSD
+	if Cfg.ClusterID != "" {
+		var err error
+		if OSD, err = osd.NewForConfig(Cfg); err != nil {
+			log.Fatalf("Could not setup OSD client: %v", err)
+		}
+		if len(Cfg.Kubeconfig) == 0 {
+			if Cfg.Kubeconfig, err = OSD.ClusterKubeconfig(Cfg.ClusterID); err != nil {
+				log.Fatalf("Could not get kubeconfig for cluster '%s': %v", Cfg.ClusterID, err)
+			}
+		}
+	}
+
+	// hosted control planes are checked through the cluster hosting them
+	if Cfg.Hypershift {
+		if err := hosted.LoadKubeconfig(Cfg, OSD); err != nil {
+			log.Fatalf("Could not get kubeconfig of management cluster: %v", err)
+		}
+	}
+
+	checker, err := newChecker(Cfg.Kubeconfig)
+	if err != nil {
+		log.Fatalf("Could not setup clients: %v", err)
+	}
+
+	var r *health.Report
+	if wait == 0 {
+		r = checker.Check()
+	} else if r, err = checker.Wait(context.Background(), interval, wait); err != nil {
+		log.Print(err)
+	}
+	if OSD != nil {
+		r.Add(checkOCM, ocmProblems(OSD, Cfg.ClusterID))
+	}
+
+	if asJSON {
+		enc := json.NewEncoder(Out)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(r)
+	} else {
+		err = writeTable(r)
+	}
+	if err != nil {
+		log.Fatalf("Couldn't write report: %v", err)
+	}
+
+	if !r.Healthy {
+		os.Exit(1)
+	}
+}
+
+// newChecker returns a checker of the cluster accessed with kubeconfig.
+func newChecker(kubeconfig []byte) (*health.Checker, error) {
+	restConfig, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't load kubeconfig: %v", err)
+	}
+	kube, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't configure Kubernetes client: %v", err)
+	}
+	cfg, err := configclient.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't configure Config client: %v", err)
+	}
+	return hosted.Checker(Cfg, kube, cfg)
+}
+
+// ocmProblems returns why OCM doesn't consider clusterID ready.
+func ocmProblems(OSD *osd.OSD, clusterID string) []string {
+	state, err := OSD.ClusterState(clusterID)
+	if err != nil {
+		return []string{fmt.Sprintf("couldn't get cluster state: %v", err)}
+	} else if state != v1.ClusterStateReady {
+		return []string{fmt.Sprintf("cluster is %s", state)}
+	}
+	return nil
+}
+
+func writeTable(r *health.Report) error {
+	status := "healthy"
+	if !r.Healthy {
+		status = "unhealthy"
+	}
+	fmt.Fprintf(Out, "Cluster is %s as of %s\n\n", status, r.Checked.Format(time.RFC3339))
+
+	w := tabwriter.NewWriter(Out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "CHECK\tSTATUS\tPROBLEMS")
+	for _, res := range r.Results {
+		status, problems := "ok", "none"
+		if !res.Healthy {
+			status, problems = "failed", strings.Join(res.Problems, ", ")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", res.Name, status, problems)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/onsi/gomega"
+
+	"github.com/openshift/osde2e/pkg/config"
+	"github.com/openshift/osde2e/pkg/configurator"
+	"github.com/openshift/osde2e/pkg/guardrails"
+	"github.com/openshift/osde2e/pkg/helper"
+	"github.com/openshift/osde2e/pkg/osd"
+	"github.com/openshift/osde2e/pkg/pool"
+	"github.com/openshift/osde2e/pkg/report"
+)
+
+const (
+	// maintainCmd keeps the pool filled until the command is stopped.
+	maintainCmd = "maintain"
+
+	// profileTimeout is how long to wait for nodes to be updated with a configuration profile.
+	profileTimeout = 45 * time.Minute
+)
+
+var (
+	// Cfg is the global configuration for the command.
+	Cfg = config.Cfg
+
+	// addr is the address the status of the pool is served on.
+	addr string
+)
+
+func init() {
+	flag.StringVar(&addr, "addr", ":8080", "address the status of the pool is served on")
+	flag.Parse()
+}
+
+func main() {
+	if config.LoadErr != nil {
+		log.Fatalf("Failed to load config from environment: %v", config.LoadErr)
+	}
+	if cmd := flag.Arg(0); cmd != maintainCmd {
+		log.Fatalf("Unknown command '%s', usage: osde2e-pool [flags] %s", cmd, maintainCmd)
+	}
+
+	sizes, err := pool.ParseSizes(Cfg.PoolSizes)
+	if err != nil {
+		log.Fatalf("Could not parse POOL_SIZES: %v", err)
+	}
+
+	OSD, err := osd.NewForConfig(Cfg)
+	if err != nil {
+		log.Fatalf("Could not setup OSD client: %v", err)
+	}
+	OSD.CacheVersions(Cfg.VersionCache, Cfg.VersionCacheTTL, Cfg.RefreshVersions)
+
+	// pool clusters live longer than those of a single run, so must still be within limits
+	cfg := *Cfg
+	cfg.ClusterExpiry = Cfg.PoolClusterExpiry
+	if err = guardrails.Enforce(&cfg); err != nil {
+		log.Fatal(err)
+	}
+
+	if cfg.ClusterVersion == "" {
+		if cfg.ClusterVersion, err = OSD.DefaultVersion(); err != nil {
+			log.Fatalf("Could not get default version: %v", err)
+		}
+	}
+
+	p := &pool.Pool{
+		Clusters:    OSD,
+		Config:      &cfg,
+		Sizes:       sizes,
+		MinLifetime: Cfg.PoolMinLifetime,
+		Prepare: func(clusterID, profile string) error {
+			return applyProfile(OSD, &cfg, clusterID, profile)
+		},
+	}
+
+	http.Handle(path.Join(report.APIPrefix, "pool"), p)
+	go func() {
+		log.Printf("Serving pool status on '%s'", addr)
+		log.Fatal(http.ListenAndServe(addr, nil))
+	}()
+
+	log.Printf("Maintaining pool of %v clusters at version '%s'", sizes, cfg.ClusterVersion)
+	p.Maintain(Cfg.PoolInterval, make(chan struct{}))
+}
+
+// applyProfile configures clusterID with the configuration profile called profile.
+func applyProfile(OSD *osd.OSD, cfg *config.Config, clusterID, profile string) (err error) {
+	p, err := configurator.LoadProfile(profile)
+	if err != nil {
+		return err
+	}
+
+	clusterCfg := *cfg
+	clusterCfg.ClusterID = clusterID
+	if clusterCfg.Kubeconfig, err = OSD.ClusterKubeconfig(clusterID); err != nil {
+		return err
+	}
+
+	// helpers assert with gomega, so failures outside of tests must be returned
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("couldn't configure clients for cluster '%s': %v", clusterID, r)
+		}
+	}()
+	gomega.RegisterFailHandler(func(msg string, _ ...int) {
+		panic(msg)
+	})
+
+	h := &helper.H{Config: &clusterCfg}
+	h.SetupClients()
+	return configurator.Apply(h, p, profileTimeout)
+}
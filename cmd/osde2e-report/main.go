@@ -2,16 +2,24 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"log"
+	"net/http"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
+	"github.com/robfig/cron"
+
 	"github.com/openshift/osde2e/pkg/config"
+	"github.com/openshift/osde2e/pkg/quarantine"
 	"github.com/openshift/osde2e/pkg/report"
+	"github.com/openshift/osde2e/pkg/slack"
 )
 
 var (
@@ -21,37 +29,59 @@ var (
 	// Out has the reports contents written to it.
 	Out io.Writer = os.Stdout
 
-	// envs shown in report.
-	envs = []report.EnvConfig{
-		{
-			Name: "int",
-		},
-		{
-			Name: "stage",
-		},
-		{
-			Name: "prod",
-		},
-	}
-
-	// jobs are included for each env in the report
-	jobs = []report.JobConfig{
-		{
-			Name:    "osd",
-			Version: "4.1",
-		},
-		{
-			Name:    "osd-upgrade",
-			Version: "4.1-4.1",
-		},
-	}
+	// serveAddr is the address the latest report is served on. If set, reports are regenerated every interval.
+	serveAddr string
+
+	// locatorName selects how links to builds and artifacts are resolved.
+	locatorName string
+
+	// locatorLocation is passed to the locator, such as a Jenkins URL or results directory.
+	locatorLocation string
+
+	// interval is how often the report is regenerated when serving.
+	interval time.Duration
+
+	// schedule is a cron schedule the report is regenerated on when serving, instead of every interval.
+	schedule string
+
+	// teamsFile lists teams reported on their own jobs.
+	teamsFile string
+
+	// weather posts how each job has been doing to Slack instead of writing the failure report.
+	weather bool
+
+	// weatherMinRuns is the fewest finished runs a job needs to be included in the weather.
+	weatherMinRuns int
+
+	// weatherSkips is how many of the most skipped tests are included in the weather.
+	weatherSkips int
+
+	// weatherGroups includes how each group of suites did in the weather.
+	weatherGroups bool
+
+	// weatherInstalls includes how cluster installs did by version and provider in the weather.
+	weatherInstalls bool
 )
 
 func init() {
+	flag.StringVar(&serveAddr, "serve", "", "serve the latest report as HTML on this address, regenerating it every interval")
+	flag.DurationVar(&interval, "interval", time.Hour, "how often the report is regenerated when serving")
+	flag.StringVar(&schedule, "schedule", "", "cron schedule the report is regenerated on when serving, such as '0 8 * * 1-5', instead of every interval")
+	flag.StringVar(&teamsFile, "teams", "", "YAML file of teams given their own report and weather of their jobs")
+	flag.StringVar(&locatorName, "locator", report.ProwLocatorName, "how links to builds are resolved: prow, jenkins, or local")
+	flag.StringVar(&locatorLocation, "locator-location", "", "Jenkins URL or results directory used by the locator (defaults to the TestGrid bucket for prow)")
+	flag.BoolVar(&weather, "weather", false, "post the weather of each job to SLACK_CHANNEL instead of writing the failure report, or each time reports are regenerated when serving")
+	flag.IntVar(&weatherMinRuns, "weather-min-runs", report.DefaultWeatherMinRuns, "fewest finished runs a job needs to be included in the weather")
+	flag.IntVar(&weatherSkips, "weather-skips", report.DefaultWeatherSkips, "how many of the most skipped tests are included in the weather")
+	flag.BoolVar(&weatherGroups, "weather-groups", true, "include how each group of suites did in the weather")
+	flag.BoolVar(&weatherInstalls, "weather-installs", true, "include how cluster installs did by version and provider in the weather")
 	flag.Parse()
 }
 
 func main() {
+	if config.LoadErr != nil {
+		log.Fatalf("Failed to load config from environment: %v", config.LoadErr)
+	}
 	durStr := flag.Arg(0)
 	if len(durStr) == 0 {
 		log.Fatal("A duration to report on must be specified")
@@ -66,9 +96,187 @@ func main() {
 
 	// configure report
 	reportCfg := *report.DefaultConfig
-	reportCfg.Envs = envs
-	reportCfg.Jobs = jobs
 
+	// configure how builds are linked
+	if locatorName == report.ProwLocatorName && locatorLocation == "" {
+		locatorLocation = Cfg.TestGridBucket
+	}
+	if reportCfg.Locator, err = report.NewLocator(locatorName, locatorLocation); err != nil {
+		log.Fatalf("Could not configure locator: %v", err)
+	}
+
+	var teams []report.Team
+	if teamsFile != "" {
+		if teams, err = report.LoadTeams(teamsFile); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if len(serveAddr) != 0 {
+		next, err := nextUpdate()
+		if err != nil {
+			log.Fatal(err)
+		}
+		serve(reportCfg, teams, reportFile, dur, next)
+		return
+	}
+
+	if weather {
+		if err = postAllWeather(reportCfg, teams, dur); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	r, err := generate(reportCfg, reportFile, dur)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// write markdown
+	if err := r.Markdown(Out); err != nil {
+		log.Fatalf("couldn't render report: %v", err)
+	}
+}
+
+// nextUpdate returns when reports served are next regenerated after a time, using the cron schedule if one is set.
+func nextUpdate() (func(time.Time) time.Time, error) {
+	if schedule == "" {
+		return func(now time.Time) time.Time { return now.Add(interval) }, nil
+	}
+	sched, err := cron.ParseStandard(schedule)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule '%s': %v", schedule, err)
+	}
+	return sched.Next, nil
+}
+
+// serve regenerates the report and those of each team when next says to, serving the latest versions over HTTP.
+// Team reports are served under '/teams/<name>/'. The weather is posted each time if requested.
+func serve(reportCfg report.Config, teams []report.Team, reportFile string, dur time.Duration, next func(time.Time) time.Time) {
+	mux := http.NewServeMux()
+	srv := new(report.Server)
+	mux.Handle("/", srv)
+
+	teamSrvs := make([]*report.Server, len(teams))
+	for i, t := range teams {
+		teamSrvs[i] = new(report.Server)
+		prefix := "/teams/" + t.Name
+		mux.Handle(prefix+"/", http.StripPrefix(prefix, teamSrvs[i]))
+	}
+
+	go func() {
+		log.Printf("Serving report on '%s'", serveAddr)
+		log.Fatal(http.ListenAndServe(serveAddr, mux))
+	}()
+
+	for {
+		update(srv, reportCfg, reportFile, dur)
+		for i, t := range teams {
+			if teamCfg, err := t.Config(reportCfg); err != nil {
+				log.Printf("Failed to report on team '%s': %v", t.Name, err)
+			} else {
+				update(teamSrvs[i], teamCfg, teamFile(reportFile, t), dur)
+			}
+		}
+
+		if weather {
+			if err := postAllWeather(reportCfg, teams, dur); err != nil {
+				log.Printf("Failed to post weather: %v", err)
+			}
+		}
+
+		now := time.Now()
+		at := next(now)
+		log.Printf("Reports updated, next update at %s", at.Format(time.RFC3339))
+		time.Sleep(at.Sub(now))
+	}
+}
+
+// update regenerates the report of reportCfg served by srv.
+func update(srv *report.Server, reportCfg report.Config, reportFile string, dur time.Duration) {
+	if r, err := generate(reportCfg, reportFile, dur); err != nil {
+		log.Printf("Failed to generate report: %v", err)
+	} else if err = srv.Set(&r); err != nil {
+		log.Printf("Failed to update served report: %v", err)
+	}
+}
+
+// teamFile returns where the report of t is stored, next to reportFile. It's empty if reportFile is.
+func teamFile(reportFile string, t report.Team) string {
+	if reportFile == "" {
+		return ""
+	}
+	ext := filepath.Ext(reportFile)
+	return strings.TrimSuffix(reportFile, ext) + "-" + t.Name + ext
+}
+
+// postAllWeather posts the weather of every job to SLACK_CHANNEL and that of each team's jobs to its channel. Only
+// teams are posted to if SLACK_CHANNEL isn't set and there are teams.
+func postAllWeather(reportCfg report.Config, teams []report.Team, dur time.Duration) error {
+	if Cfg.SlackChannel != "" || len(teams) == 0 {
+		if err := postWeather(reportCfg, dur, Cfg.SlackChannel); err != nil {
+			return err
+		}
+	}
+
+	for _, t := range teams {
+		if t.Channel == "" {
+			continue
+		}
+		teamCfg, err := t.Config(reportCfg)
+		if err != nil {
+			return err
+		}
+		if err = postWeather(teamCfg, dur, t.Channel); err != nil {
+			return fmt.Errorf("couldn't post weather of team '%s': %v", t.Name, err)
+		}
+	}
+	return nil
+}
+
+// postWeather posts how each job has done over the last dur to channel in Slack, worst first.
+func postWeather(reportCfg report.Config, dur time.Duration, channel string) error {
+	if Cfg.SlackToken == "" || channel == "" {
+		return errors.New("SLACK_TOKEN and SLACK_CHANNEL must be set to post the weather")
+	}
+
+	results, err := reportCfg.Results(Cfg, time.Now().UTC().Add(-dur))
+	if err != nil {
+		return fmt.Errorf("couldn't retrieve results: %v", err)
+	}
+
+	weather := make([]report.Weather, len(results))
+	for i, j := range results {
+		weather[i] = j.Weather()
+	}
+
+	msg := report.SlackWeather(weather, weatherMinRuns)
+	if weatherSkips > 0 {
+		if skipped := report.SlackSkips(report.SkipWeather(results, ""), weatherSkips); skipped != "" {
+			msg += "\n" + skipped
+		}
+	}
+	if weatherGroups {
+		if groups := report.SlackGroups(report.GroupWeather(results)); groups != "" {
+			msg += "\n" + groups
+		}
+	}
+	if weatherInstalls {
+		if installs := report.SlackInstalls(report.InstallWeather(results), weatherMinRuns); installs != "" {
+			msg += "\n" + installs
+		}
+	}
+
+	client := slack.NewClient(Cfg.SlackToken, channel)
+	if _, err = client.PostMessage(msg, ""); err != nil {
+		return fmt.Errorf("couldn't post weather: %v", err)
+	}
+	return nil
+}
+
+// generate updates the report stored in reportFile with runs from the last dur.
+func generate(reportCfg report.Config, reportFile string, dur time.Duration) (report.Report, error) {
 	// load or initialize new report
 	r := loadReportOrCreateNew(reportCfg, reportFile)
 	r.Title = "osde2e Failure Report"
@@ -82,21 +290,32 @@ func main() {
 	}
 
 	// perform update
-	if err = r.Update(Cfg, rng); err != nil {
-		log.Fatalf("Error updating: %v", err)
+	if err := r.Update(Cfg, rng); err != nil {
+		return r, fmt.Errorf("error updating: %v", err)
 	}
 
-	// write markdown
-	if err := r.Markdown(Out); err != nil {
-		log.Fatalf("couldn't render report: %v", err)
+	// warn about quarantined tests that need attention
+	if list, err := quarantine.Load(Cfg.QuarantineFile); err != nil {
+		log.Printf("Failed to check quarantine list: %v", err)
+	} else {
+		r.CheckQuarantine(list, end)
+		r.MatchKnownIssues(list)
+	}
+
+	// install success rates need every finished run, not only the failed ones in the report
+	if results, err := reportCfg.Results(Cfg, start); err != nil {
+		log.Printf("Failed to summarize cluster installs: %v", err)
+	} else {
+		r.Installs = report.InstallWeather(results)
 	}
 
 	// write report to disk if filename specified
 	if len(reportFile) != 0 {
-		if err = writeReport(r, reportFile); err != nil {
+		if err := writeReport(r, reportFile); err != nil {
 			log.Printf("Failed writing report to '%s': %v", reportFile, err)
 		}
 	}
+	return r, nil
 }
 
 func loadReportOrCreateNew(cfg report.Config, filename string) (r report.Report) {
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/openshift/osde2e/pkg/config"
+	"github.com/openshift/osde2e/pkg/report"
+)
+
+var (
+	// Cfg is the global configuration for the command.
+	Cfg = config.Cfg
+
+	// addr is the address the API is served on.
+	addr string
+
+	// interval is how often results are refreshed from TestGrid.
+	interval time.Duration
+
+	// window is how far back runs are included.
+	window time.Duration
+
+	// locatorName selects how links to builds are resolved.
+	locatorName string
+
+	// locatorLocation is passed to the locator, such as a Jenkins URL or results directory.
+	locatorLocation string
+)
+
+func init() {
+	flag.StringVar(&addr, "addr", ":8080", "address the results API is served on")
+	flag.DurationVar(&interval, "interval", 15*time.Minute, "how often results are refreshed")
+	flag.DurationVar(&window, "window", 7*24*time.Hour, "how far back runs are included")
+	flag.StringVar(&locatorName, "locator", report.ProwLocatorName, "how links to builds are resolved: prow, jenkins, or local")
+	flag.StringVar(&locatorLocation, "locator-location", "", "Jenkins URL or results directory used by the locator (defaults to the TestGrid bucket for prow)")
+	flag.Parse()
+}
+
+func main() {
+	if config.LoadErr != nil {
+		log.Fatalf("Failed to load config from environment: %v", config.LoadErr)
+	}
+	cfg := *report.DefaultConfig
+	if locatorName == report.ProwLocatorName && locatorLocation == "" {
+		locatorLocation = Cfg.TestGridBucket
+	}
+
+	var err error
+	if cfg.Locator, err = report.NewLocator(locatorName, locatorLocation); err != nil {
+		log.Fatalf("Could not configure locator: %v", err)
+	}
+
+	srv := new(report.APIServer)
+	http.Handle(report.APIPrefix, srv)
+	http.HandleFunc(report.MetricsPath, srv.ServeMetrics)
+	go func() {
+		log.Printf("Serving results API on '%s'", addr)
+		log.Fatal(http.ListenAndServe(addr, nil))
+	}()
+
+	for {
+		if results, err := cfg.Results(Cfg, time.Now().UTC().Add(-window)); err != nil {
+			log.Printf("Failed to retrieve results: %v", err)
+		} else {
+			srv.Set(results)
+			log.Printf("Results updated, next update in %v", interval)
+		}
+		time.Sleep(interval)
+	}
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/onsi/gomega"
+
+	"github.com/openshift/osde2e/pkg/config"
+	"github.com/openshift/osde2e/pkg/helper"
+	"github.com/openshift/osde2e/pkg/osd"
+	osdtestgrid "github.com/openshift/osde2e/pkg/testgrid"
+	"github.com/openshift/osde2e/pkg/upgrade"
+)
+
+var (
+	// Cfg is the global configuration for the command.
+	Cfg = config.Cfg
+
+	// Out has the advice written to it.
+	Out io.Writer = os.Stdout
+
+	// builds is the number of recent builds in TestGrid used to estimate upgrade durations.
+	builds int
+
+	// at is when upgrades are planned to start, in RFC3339. Defaults to now.
+	at string
+
+	// asJSON writes advice as JSON instead of a table.
+	asJSON bool
+)
+
+func init() {
+	flag.IntVar(&builds, "builds", 50, "number of recent builds in TestGrid used to estimate upgrade durations")
+	flag.StringVar(&at, "at", "", "when upgrades are planned to start in RFC3339, defaults to now")
+	flag.BoolVar(&asJSON, "json", false, "write advice as JSON")
+	flag.Parse()
+}
+
+func main() {
+	if config.LoadErr != nil {
+		log.Fatalf("Failed to load config from environment: %v", config.LoadErr)
+	}
+	if clusterID := flag.Arg(0); clusterID != "" {
+		Cfg.ClusterID = clusterID
+	}
+	if Cfg.ClusterID == "" && len(Cfg.Kubeconfig) == 0 {
+		log.Fatal("A cluster ID must be specified, or TEST_KUBECONFIG set")
+	}
+
+	start := time.Now().UTC()
+	if at != "" {
+		var err error
+		if start, err = time.Parse(time.RFC3339, at); err != nil {
+			log.Fatalf("Could not parse start time: %v", err)
+		}
+	}
+
+	windows, err := upgrade.ParseMaintenanceWindows(Cfg.MaintenanceWindows)
+	if err != nil {
+		log.Fatalf("Could not parse maintenance windows: %v", err)
+	}
+
+	if err = Cfg.ReadKubeconfig(); err != nil {
+		log.Fatal(err)
+	} else if len(Cfg.Kubeconfig) == 0 {
+		OSD, err := osd.NewForConfig(Cfg)
+		if err != nil {
+			log.Fatalf("Could not setup OSD client: %v", err)
+		}
+		if Cfg.Kubeconfig, err = OSD.ClusterKubeconfig(Cfg.ClusterID); err != nil {
+			log.Fatalf("Could not get kubeconfig for cluster '%s': %v", Cfg.ClusterID, err)
+		}
+	}
+
+	// helpers assert with gomega, so failures outside of tests must be handled
+	gomega.RegisterFailHandler(func(msg string, _ ...int) {
+		log.Fatal(msg)
+	})
+	h := &helper.H{Config: Cfg}
+	h.SetupClients()
+
+	history, err := loadHistory()
+	if err != nil {
+		log.Printf("Failed to load upgrade history, durations won't be estimated from previous runs: %v", err)
+	}
+
+	advice, err := upgrade.Advise(h, history, windows, start)
+	if err != nil {
+		log.Fatalf("Could not check upgrades: %v", err)
+	}
+
+	if asJSON {
+		enc := json.NewEncoder(Out)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(advice)
+	} else {
+		err = writeTable(advice, start)
+	}
+	if err != nil {
+		log.Fatalf("Couldn't write advice: %v", err)
+	}
+}
+
+// loadHistory reads upgrade durations from the most recent builds recorded in TestGrid.
+func loadHistory() (upgrade.History, error) {
+	history := upgrade.History{}
+	tg, err := osdtestgrid.NewTestGrid(Cfg.TestGridBucket, Cfg.TestGridPrefix, Cfg.TestGridServiceAccount)
+	if err != nil {
+		return history, fmt.Errorf("couldn't setup TestGrid: %v", err)
+	}
+
+	ctx := context.Background()
+	_, latest, err := tg.LatestFinished(ctx)
+	if err != nil {
+		return history, fmt.Errorf("couldn't get latest build: %v", err)
+	}
+
+	for i := latest; i > 0 && i > latest-builds; i-- {
+		suites, err := tg.Suites(ctx, i)
+		if err != nil {
+			log.Printf("Error getting results for build %d: %v", i, err)
+			continue
+		}
+		history.Add(suites)
+	}
+	return history, nil
+}
+
+func writeTable(advice *upgrade.Advice, start time.Time) error {
+	fmt.Fprintf(Out, "Current version: %s\n", advice.Current)
+	if len(advice.Windows) != 0 {
+		windows := make([]string, len(advice.Windows))
+		for i, w := range advice.Windows {
+			windows[i] = w.String()
+		}
+		fmt.Fprintf(Out, "Maintenance windows: %s\n", strings.Join(windows, ", "))
+	}
+	fmt.Fprintf(Out, "Planned start: %s\n\n", start.Format(time.RFC3339))
+
+	if len(advice.Edges) == 0 {
+		fmt.Fprintln(Out, "No upgrades are available.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(Out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tESTIMATE\tGATES\tCONFLICT\tNEXT WINDOW")
+	for _, edge := range advice.Edges {
+		gates := make([]string, len(edge.Gates))
+		for i, g := range edge.Gates {
+			gates[i] = g.Name
+		}
+
+		next := "-"
+		if edge.NextWindow != nil {
+			next = edge.NextWindow.UTC().Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%s\t%v\t%s\t%s\t%s\n", edge.Version, edge.Estimate, orNone(strings.Join(gates, ", ")),
+			orNone(edge.Conflict), next)
+	}
+	return w.Flush()
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/openshift/osde2e/pkg/config"
+	"github.com/openshift/osde2e/pkg/webhook"
+)
+
+var (
+	// Cfg is the global configuration for the command.
+	Cfg = config.Cfg
+
+	// addr is the address webhooks are received on.
+	addr string
+
+	// command performs runs, with the options of each run added to its environment.
+	command string
+
+	// timeout limits how long each run takes.
+	timeout time.Duration
+
+	// logDir is where the output of each run is stored.
+	logDir string
+
+	// insecure accepts unsigned webhooks when WEBHOOK_SECRET isn't set.
+	insecure bool
+)
+
+func init() {
+	flag.StringVar(&addr, "addr", ":8080", "address webhooks are received on")
+	flag.StringVar(&command, "command", "out/osde2e", "osde2e test binary performing runs")
+	flag.DurationVar(&timeout, "timeout", 3*time.Hour, "how long each run may take")
+	flag.StringVar(&logDir, "logs", "webhook-runs", "directory the output of each run is stored in")
+	flag.BoolVar(&insecure, "insecure", false, "accept unsigned webhooks when WEBHOOK_SECRET isn't set")
+	flag.Parse()
+}
+
+func main() {
+	if config.LoadErr != nil {
+		log.Fatalf("Failed to load config from environment: %v", config.LoadErr)
+	}
+	triggers, err := webhook.Load(Cfg.WebhookTriggers)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(Cfg.WebhookSecret) == 0 {
+		if !insecure {
+			log.Fatal("WEBHOOK_SECRET must be set, or -insecure used to accept unsigned webhooks")
+		}
+		log.Println("WEBHOOK_SECRET is not set, accepting unsigned webhooks.")
+	}
+
+	audit, err := os.OpenFile(Cfg.WebhookAuditLog, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		log.Fatalf("Could not open audit log: %v", err)
+	}
+	defer audit.Close()
+
+	if err = os.MkdirAll(logDir, os.ModePerm); err != nil {
+		log.Fatalf("Could not create directory for run output: %v", err)
+	}
+
+	r := &webhook.Receiver{
+		Secret:   Cfg.WebhookSecret,
+		Triggers: triggers,
+		Start:    run,
+		MaxRuns:  Cfg.WebhookMaxRuns,
+		Audit:    audit,
+	}
+	http.Handle("/webhook", r)
+
+	log.Printf("Receiving webhooks for %d triggers on '%s'", len(triggers), addr)
+	log.Fatal(http.ListenAndServe(addr, nil))
+}
+
+// run performs r with the test binary, storing its output in logDir.
+func run(r webhook.Run) error {
+	output := filepath.Join(logDir, fmt.Sprintf("%s-%s.log", r.Profile, time.Now().UTC().Format("20060102-150405")))
+	f, err := os.Create(output)
+	if err != nil {
+		return fmt.Errorf("couldn't create output of run '%s': %v", r.Profile, err)
+	}
+	defer f.Close()
+
+	cmd := exec.Command(command, "-test.v", "-test.timeout", timeout.String())
+	cmd.Env = append(childEnv(os.Environ()), r.Env...)
+	cmd.Stdout, cmd.Stderr = f, f
+
+	log.Printf("Starting run '%s' with %v, output in '%s'", r.Profile, r.Env, output)
+	if err = cmd.Run(); err != nil {
+		log.Printf("Run '%s' failed: %v", r.Profile, err)
+		return fmt.Errorf("run '%s' failed, output in '%s': %v", r.Profile, output, err)
+	}
+	log.Printf("Run '%s' passed", r.Profile)
+	return nil
+}
+
+// childEnv returns env without the webhook secret, which runs have no use for.
+func childEnv(env []string) (child []string) {
+	for _, kv := range env {
+		if !strings.HasPrefix(kv, "WEBHOOK_SECRET=") {
+			child = append(child, kv)
+		}
+	}
+	return
+}
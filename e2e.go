@@ -3,39 +3,166 @@ package osde2e
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"os"
 	"path"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/onsi/ginkgo"
+	ginkgoconfig "github.com/onsi/ginkgo/config"
 	"github.com/onsi/ginkgo/reporters"
 	"github.com/onsi/gomega"
 	"k8s.io/test-infra/testgrid/metadata"
 
+	"github.com/openshift/osde2e/pkg/artifacts"
+	"github.com/openshift/osde2e/pkg/cassette"
+	"github.com/openshift/osde2e/pkg/clusterspec"
+	"github.com/openshift/osde2e/pkg/conditions"
 	"github.com/openshift/osde2e/pkg/config"
+	"github.com/openshift/osde2e/pkg/debug"
+	"github.com/openshift/osde2e/pkg/drift"
+	"github.com/openshift/osde2e/pkg/formats"
+	"github.com/openshift/osde2e/pkg/generic"
+	"github.com/openshift/osde2e/pkg/groups"
+	"github.com/openshift/osde2e/pkg/guardrails"
+	"github.com/openshift/osde2e/pkg/hosted"
+	"github.com/openshift/osde2e/pkg/imagescan"
+	"github.com/openshift/osde2e/pkg/junitprops"
+	"github.com/openshift/osde2e/pkg/local"
+	"github.com/openshift/osde2e/pkg/metrichooks"
+	"github.com/openshift/osde2e/pkg/naming"
+	"github.com/openshift/osde2e/pkg/netplugin"
 	"github.com/openshift/osde2e/pkg/osd"
+	"github.com/openshift/osde2e/pkg/privilege"
+	"github.com/openshift/osde2e/pkg/quarantine"
+	"github.com/openshift/osde2e/pkg/redact"
+	"github.com/openshift/osde2e/pkg/regions"
+	"github.com/openshift/osde2e/pkg/runner"
+	"github.com/openshift/osde2e/pkg/scale"
+	"github.com/openshift/osde2e/pkg/seed"
+	"github.com/openshift/osde2e/pkg/skips"
+	"github.com/openshift/osde2e/pkg/slack"
+	"github.com/openshift/osde2e/pkg/tenant"
+	"github.com/openshift/osde2e/pkg/testcases"
 	"github.com/openshift/osde2e/pkg/testgrid"
+	"github.com/openshift/osde2e/pkg/timeline"
+	"github.com/openshift/osde2e/pkg/topology"
+	"github.com/openshift/osde2e/pkg/tracing"
+	"github.com/openshift/osde2e/pkg/triage"
+	"github.com/openshift/osde2e/pkg/upgrade"
+	"github.com/openshift/osde2e/pkg/usage"
+	"github.com/openshift/osde2e/pkg/verdict"
+	"github.com/openshift/osde2e/pkg/workloads"
 )
 
-// OSD is used to deploy and manage clusters.
+// OSD is used to deploy and manage clusters. It is nil when a provider plugin is used.
 var OSD *osd.OSD
 
+// Provider creates and manages the cluster being tested. It is OSD unless a provider plugin is used.
+var Provider ClusterProvider
+
+// Topology describes where the cluster runs. It is set once the cluster is ready.
+var Topology *topology.Topology
+
+// Failures records setup and test failures so runs can be paused before teardown to inspect them.
+var Failures = new(debug.Recorder)
+
+// Skips records why specs were skipped so skips are reported instead of hidden.
+var Skips = new(skips.Recorder)
+
+// Triage adds probable causes to the failure messages of specs.
+var Triage = new(triage.Analyzer)
+
+// Progress posts updates about the run to Slack. It is nil when Slack isn't configured.
+var Progress *slack.Progress
+
+// SuiteUsage is the CPU and memory consumed by the workloads of each suite. It is set after testing.
+var SuiteUsage []usage.Usage
+
+// UpgradeFailure is why the upgrade failed. It is nil unless the upgrade failed.
+var UpgradeFailure *upgrade.Failure
+
+// SpecDrift is where the cluster disagreed with OCM at checkpoints of the run.
+var SpecDrift = new(clusterspec.Report)
+
+// Verdict records how each phase of the run ended to choose its exit code.
+var Verdict = new(verdict.Recorder)
+
+// Tracer records the run as a trace with spans for each phase and test. It is nil when tracing isn't configured.
+var Tracer *tracing.Tracer
+
 const (
 	// metadata key holding build-version
 	buildVersionKey = "build-version"
+
+	// metadata key holding the result of the run's verdict
+	verdictKey = "verdict"
+
+	// prowBuildIDEnv is set by Prow to the ID of the job's build.
+	prowBuildIDEnv = "BUILD_ID"
 )
 
 // RunE2ETests runs the osde2e test suite using the given cfg.
 func RunE2ETests(t *testing.T, cfg *config.Config) {
-	gomega.RegisterFailHandler(ginkgo.Fail)
+	if config.LoadErr != nil {
+		t.Fatalf("could not load config from environment: %v", config.LoadErr)
+	}
+
+	// mask secrets in everything the run writes
+	redactor, err := redact.New(cfg.Secrets(), cfg.RedactPatterns)
+	if err != nil {
+		t.Fatalf("could not setup redaction: %v", err)
+	}
+	redact.Current = redactor
+	log.SetOutput(redact.Current.Writer(os.Stderr))
+	defer log.SetOutput(os.Stderr)
+
+	// quarantined tests don't fail the run
+	quarantined, err := quarantine.Load(cfg.QuarantineFile)
+	if err != nil {
+		t.Fatalf("could not load quarantine list: %v", err)
+	}
+	gomega.RegisterFailHandler(quarantined.FailHandler(ginkgo.Fail))
+
+	// make random choices repeatable
+	if cfg.RunSeed == 0 {
+		cfg.RunSeed = seed.Generate()
+	}
+	seed.Set(cfg.RunSeed)
+	ginkgoconfig.GinkgoConfig.RandomSeed = cfg.RunSeed
+	log.Printf("Random choices are made from seed %d, set RUN_SEED=%d to repeat them.", cfg.RunSeed, cfg.RunSeed)
 
 	// set defaults
+	if cfg.JobID == "" {
+		cfg.JobID = os.Getenv(prowBuildIDEnv)
+	}
 	if cfg.Suffix == "" {
-		cfg.Suffix = randomStr(3)
+		cfg.Suffix = naming.New("", cfg.JobID, seed.String(seed.Suffix, 3)).Suffix()
+	}
+
+	// only run what failed last time, on the same cluster
+	if cfg.RerunFailed {
+		if cfg.ReportDir == "" {
+			t.Fatal("REPORT_DIR of the last run must be set to rerun its failed specs")
+		}
+		rerun, err := debug.LoadRerun(cfg.ReportDir)
+		if err != nil {
+			t.Fatalf("could not rerun failed specs: %v", err)
+		} else if len(rerun.Specs) == 0 {
+			log.Println("No specs failed in the last run, there's nothing to rerun.")
+			return
+		}
+		if cfg.ClusterID == "" {
+			cfg.ClusterID = rerun.ClusterID
+		}
+		ginkgoconfig.GinkgoConfig.FocusString = rerun.Focus()
+		log.Printf("Rerunning %d specs which failed on cluster '%s'.", len(rerun.Specs), cfg.ClusterID)
 	}
 
 	if cfg.ReportDir == "" {
@@ -44,42 +171,247 @@ func RunE2ETests(t *testing.T, cfg *config.Config) {
 		}
 	}
 
-	// ensure to wait longer than infra alerting rules thresholds
-	// otherwise startup failures won't trigger alerts
-	if cfg.ClusterUpTimeout == 0 {
-		cfg.ClusterUpTimeout = 135 * time.Minute
+	// check the selected phases can be run
+	if err = cfg.ValidatePhases(); err != nil {
+		t.Fatalf("invalid phases: %v", err)
+	}
+
+	if err = cfg.ValidateTeardown(); err != nil {
+		t.Fatalf("invalid teardown policy: %v", err)
 	}
 
-	// support deprecated USE_PROD option
-	if cfg.UseProd {
-		cfg.OSDEnv = "prod"
+	if err = cfg.ValidateSharing(); err != nil {
+		t.Fatalf("invalid shared cluster: %v", err)
+	} else if cfg.SharedCluster && cfg.Synthetics {
+		// probes run in a namespace each run would delete from the others
+		log.Println("Synthetic probes aren't run on shared clusters.")
+		cfg.Synthetics = false
+	}
+	if _, err = tenant.ParseQuota(cfg.SharedClusterQuota); err != nil {
+		t.Fatalf("invalid shared cluster quota: %v", err)
+	}
+
+	if cfg.ImageScanner != "" {
+		if _, err = imagescan.New(cfg.ImageScanner, cfg.ClairURL); err != nil {
+			t.Fatalf("invalid image scanner: %v", err)
+		}
 	}
 
-	// setup OSD client
-	var err error
-	if OSD, err = osd.New(cfg.UHCToken, cfg.OSDEnv, cfg.DebugOSD); err != nil {
-		t.Fatalf("could not setup OSD: %v", err)
+	if cfg.NetworkType != "" {
+		if _, err = netplugin.Get(cfg.NetworkType); err != nil {
+			t.Fatalf("invalid network type: %v", err)
+		}
+	}
+
+	if _, err = scale.ParseThresholds(cfg.ScaleThresholds); err != nil {
+		t.Fatalf("invalid scale thresholds: %v", err)
+	}
+
+	if err = workloads.Validate(cfg.WorkloadProfiles); err != nil {
+		t.Fatalf("invalid workload profiles: %v", err)
+	}
+
+	reportFormats, err := formats.Parse(cfg.ReportFormats)
+	if err != nil {
+		t.Fatalf("invalid report formats: %v", err)
+	}
+
+	groupBudgets, err := groups.ParseBudgets(cfg.GroupBudgets)
+	if err != nil {
+		t.Fatalf("invalid group budgets: %v", err)
+	}
+	groups.Budgets.SetBudgets(groupBudgets)
+
+	// suites share the time budget of the configuration profile
+	budget, profile, err := guardrails.LoadBudget(cfg)
+	if err != nil {
+		t.Fatalf("invalid configuration profile: %v", err)
+	} else if budget != nil {
+		groups.Budgets.SetTotalBudget(budget.SuiteTime())
+	}
+
+	rotation, err := regions.Parse(cfg.RegionRotation)
+	if err != nil {
+		t.Fatalf("invalid region rotation: %v", err)
+	} else if len(rotation) != 0 && cfg.RegionBlocklist == "" {
+		t.Fatal("REGION_BLOCKLIST must be set to a file shared by every run when using REGION_ROTATION")
+	}
+
+	if len(cfg.ClusterID) == 0 && len(cfg.Kubeconfig) == 0 && cfg.Provider != config.ProviderLocal &&
+		cfg.RunPhase(config.PhaseInstall) {
+		// regions are rotated within a cloud provider, so it's given when creating the cluster
+		if len(rotation) != 0 && cfg.CloudProvider == "" {
+			cfg.CloudProvider = config.DefaultCloudProvider
+		}
+
+		// skip regions which recently ran out of capacity
+		if list, ok := rotation[cfg.CloudProvider]; ok {
+			rotateRegion(cfg, list)
+		}
+
+		// compliance environments only create clusters in their own regions
+		if err = osd.CheckRegion(cfg.OSDEnv, cfg.Region); err != nil {
+			t.Fatalf("invalid region: %v", err)
+		}
+
+		// refuse to create clusters larger or longer lived than expected
+		if err = guardrails.Enforce(cfg); err != nil {
+			t.Fatalf("refusing to create cluster: %v", err)
+		}
 	}
 
-	// check that enough quota exists for this test if creating cluster
-	if len(cfg.ClusterID) == 0 {
-		if enoughQuota, err := OSD.CheckQuota(cfg); err != nil {
-			log.Printf("Failed to check if enough quota is available: %v", err)
-		} else if !enoughQuota {
-			log.Println("Currently not enough quota exists to run this test, skipping...")
-			t.SkipNow()
+	if cfg.ProviderPlugin != "" {
+		// clusters are managed by the provider plugin
+		provider, err := startProviderPlugin(cfg)
+		if err != nil {
+			t.Fatalf("could not setup provider plugin: %v", err)
+		}
+		defer provider.Close()
+		Provider = provider
+	} else if cfg.Provider == config.ProviderGeneric {
+		// any OpenShift cluster is tested without OSD
+		if Provider, err = useGenericProvider(cfg); err != nil {
+			t.Fatalf("could not setup generic provider: %v", err)
+		}
+	} else if cfg.Provider == config.ProviderLocal {
+		// a cluster on the developer's machine is tested without OSD
+		if Provider, err = useLocalProvider(cfg); err != nil {
+			t.Fatalf("could not setup local provider: %v", err)
+		}
+	} else if cfg.Provider != config.ProviderOSD {
+		t.Fatalf("unknown provider '%s', must be %s, %s, or %s", cfg.Provider, config.ProviderOSD, config.ProviderGeneric,
+			config.ProviderLocal)
+	} else {
+		// setup OSD client, recording interactions if requested
+		if cfg.OSDCassette != "" {
+			c := new(cassette.Cassette)
+			var stop func()
+			if OSD, stop, err = osd.Record(c, cfg); err != nil {
+				t.Fatalf("could not setup OSD: %v", err)
+			}
+			defer func() {
+				stop()
+				if err := c.Save(cfg.OSDCassette); err != nil {
+					log.Printf("Failed to save OSD recording: %v", err)
+				}
+			}()
+		} else if OSD, err = osd.NewForConfig(cfg); err != nil {
+			t.Fatalf("could not setup OSD: %v", err)
+		} else {
+			OSD.CacheVersions(cfg.VersionCache, cfg.VersionCacheTTL, cfg.RefreshVersions)
+		}
+
+		// check that enough quota exists for this test if creating cluster
+		if len(cfg.ClusterID) == 0 && cfg.RunPhase(config.PhaseInstall) {
+			if enoughQuota, err := OSD.CheckQuota(cfg); err != nil {
+				log.Printf("Failed to check if enough quota is available: %v", err)
+			} else if !enoughQuota {
+				log.Println("Currently not enough quota exists to run this test, skipping...")
+				t.SkipNow()
+			}
 		}
+
+		// configure cluster and upgrade versions
+		if err = ChooseVersions(cfg, OSD); err != nil {
+			t.Fatalf("failed to configure versions: %v", err)
+		}
+		Provider = OSD
 	}
 
-	// configure cluster and upgrade versions
-	if err = ChooseVersions(cfg, OSD); err != nil {
-		t.Fatalf("failed to configure versions: %v", err)
+	// define tests contained in suite plugins
+	suites, err := startSuitePlugins(cfg)
+	for _, s := range suites {
+		defer s.Close()
+	}
+	if err != nil {
+		t.Fatalf("could not setup suite plugins: %v", err)
+	}
+
+	// define specs from test cases written in YAML
+	if cfg.TestCasesDir != "" {
+		suites, err := testcases.Load(cfg.TestCasesDir)
+		if err != nil {
+			t.Fatalf("could not load test cases: %v", err)
+		}
+		testcases.Describe(suites)
+		log.Printf("Loaded %d suites of test cases from '%s'.", len(suites), cfg.TestCasesDir)
 	}
 
 	// setup reporter
 	os.Mkdir(cfg.ReportDir, os.ModePerm)
 	reportPath := path.Join(cfg.ReportDir, fmt.Sprintf("junit_%v.xml", cfg.Suffix))
 	reporter := reporters.NewJUnitReporter(reportPath)
+	customReporters := []ginkgo.Reporter{reporter, timeline.Current, Failures, Verdict, Skips, groups.Budgets}
+
+	// results are also recorded for formats other than JUnit
+	results := formats.NewRecorder()
+	if len(reportFormats) != 0 {
+		customReporters = append(customReporters, results)
+	}
+
+	// hints are added to failure messages before they're recorded
+	if cfg.TriageHints {
+		Triage.Known = func(test, message string) (string, bool) {
+			k, ok := quarantined.Match(test, message, cfg.ClusterVersion, upgradeVersion(cfg))
+			return k.Issue, ok
+		}
+		Triage.Collect = func(w triage.Window) (triage.Evidence, error) {
+			if len(cfg.Kubeconfig) == 0 {
+				return triage.Evidence{}, fmt.Errorf("no kubeconfig for the cluster")
+			}
+			collect, err := triage.ClusterCollector(cfg.Kubeconfig)
+			if err != nil {
+				return triage.Evidence{}, err
+			}
+			return collect(w)
+		}
+		customReporters = append([]ginkgo.Reporter{Triage}, customReporters...)
+	}
+
+	// setup artifact storage
+	budgets, err := artifacts.ParseBudgets(cfg.ArtifactBudgets)
+	if err != nil {
+		t.Fatalf("invalid artifact budgets: %v", err)
+	}
+	if artifacts.Current, err = artifacts.New(cfg.ReportDir, budgets); err != nil {
+		t.Fatalf("could not setup artifact storage: %v", err)
+	}
+	env := cfg.OSDEnv
+	if env == "" {
+		env = osd.Environments[""]
+	}
+	if keyFile, ok := cfg.ArtifactEncryptionKeys[env]; ok {
+		key, err := artifacts.LoadPublicKey(keyFile)
+		if err != nil {
+			t.Fatalf("could not setup artifact encryption for the %s environment: %v", env, err)
+		}
+		artifacts.Current.EncryptCategories(key, cfg.EncryptedArtifacts...)
+		log.Printf("Encrypting %s artifacts with '%s'.", strings.Join(cfg.EncryptedArtifacts, ", "), keyFile)
+	}
+
+	// setup slack progress
+	if cfg.SlackToken != "" {
+		client := slack.NewClient(cfg.SlackToken, cfg.SlackChannel)
+		title := fmt.Sprintf("osde2e run '%s' of %s", cfg.Suffix, buildVersion(cfg))
+		Progress = slack.NewProgress(client, title, cfg.SlackThreshold)
+		customReporters = append(customReporters, &slack.Reporter{Progress: Progress})
+	}
+
+	// setup tracing
+	if cfg.TracingEndpoint != "" {
+		Tracer = tracing.New(cfg.TracingServiceName, fmt.Sprintf("osde2e run '%s'", cfg.Suffix))
+		customReporters = append(customReporters, Tracer)
+	}
+
+	// check suites only need the permissions they declare
+	if cfg.LeastPrivilege {
+		privilege.Current = privilege.New()
+	}
+
+	// record how ClusterOperators change, including between health checks
+	if cfg.OperatorChurn {
+		conditions.Current = conditions.New(cfg.OperatorMaxDegraded, cfg.OperatorMaxProgressing)
+	}
 
 	// setup testgrid
 	if !cfg.NoTestGrid {
@@ -109,8 +441,140 @@ func RunE2ETests(t *testing.T, cfg *config.Config) {
 		log.Print("NO_TESTGRID is set, skipping submitting to TestGrid...")
 	}
 
+	// only setup and teardown are performed if tests aren't run
+	if !cfg.RunPhase(config.PhaseTests) {
+		log.Printf("The %s phase isn't selected, skipping all tests...", config.PhaseTests)
+		ginkgoconfig.GinkgoConfig.SkipString = ".*"
+	}
+
 	log.Println("Running e2e tests...")
-	ginkgo.RunSpecsWithDefaultAndCustomReporters(t, "OSD e2e suite", []ginkgo.Reporter{reporter})
+	ginkgo.RunSpecsWithDefaultAndCustomReporters(t, "OSD e2e suite", customReporters)
+
+	if err = results.Write(cfg.ReportDir, cfg.Suffix, reportFormats); err != nil {
+		log.Printf("Failed to write results: %v", err)
+	}
+
+	// record what failed so it can be rerun alone
+	if cfg.RunPhase(config.PhaseTests) {
+		rerun := &debug.Rerun{ClusterID: cfg.ClusterID, Specs: Failures.FailedSpecs()}
+		if err = rerun.Write(cfg.ReportDir); err != nil {
+			log.Printf("Failed to record failed specs: %v", err)
+		}
+	}
+
+	if err = quarantined.AnnotateJUnit(reportPath); err != nil {
+		log.Printf("Failed to mark quarantined tests in JUnit: %v", err)
+	}
+
+	// skipped tests carry why they were skipped
+	if err = Skips.AnnotateJUnit(reportPath); err != nil {
+		log.Printf("Failed to mark skip reasons in JUnit: %v", err)
+	}
+	if summary := Skips.Summary(); summary != "" {
+		log.Printf("Skipped tests: %s.", summary)
+	}
+
+	// testcases carry their group so results can be summarized by group
+	if err = groups.Budgets.AnnotateJUnit(reportPath); err != nil {
+		log.Printf("Failed to mark test groups in JUnit: %v", err)
+	}
+	for _, s := range groups.Budgets.Summaries() {
+		log.Printf("Group %s.", s)
+	}
+
+	// show teams adding suites when they spent the budget of their profile
+	if budget != nil {
+		plan := guardrails.Planned(cfg)
+		spent := guardrails.Usage{ClusterLifetime: plan.Expiry, ComputeNodes: plan.ComputeNodes, SuiteTime: groups.Budgets.Spent()}
+		for _, v := range guardrails.BudgetViolations(profile, budget, spent) {
+			log.Printf("Over budget: %s.", v)
+		}
+		if err = guardrails.WriteBudgetJUnit(cfg.ReportDir, cfg.Suffix, profile, budget, spent); err != nil {
+			log.Printf("Failed to report profile budget: %v", err)
+		}
+	}
+
+	// link failures to the issues known to cause them
+	if err = quarantined.AnnotateKnownIssues(reportPath, cfg.ClusterVersion, upgradeVersion(cfg)); err != nil {
+		log.Printf("Failed to mark known issues in JUnit: %v", err)
+	}
+	for _, m := range quarantined.KnownMatches() {
+		if m.Count != 0 {
+			log.Printf("%d failures were caused by known issue %s.", m.Count, m.Issue)
+		}
+	}
+
+	// give the OCM team signal on how their API handled our requests
+	if OSD != nil {
+		reportOCMStats(t, cfg)
+	}
+
+	// report runner Pods retried because of the cluster separately from failures of their tests
+	if err = runner.InfraRetries.WriteJUnit(cfg.ReportDir, cfg.Suffix); err != nil {
+		log.Printf("Failed to record runner infrastructure failures: %v", err)
+	}
+	for _, r := range runner.InfraRetries.Runners() {
+		if r.Exhausted {
+			Verdict.Fail(config.PhaseTests, verdict.Infra, fmt.Sprintf("%s runner failed for infrastructure reasons: %s",
+				r.Name, strings.Join(r.Reasons, ", ")))
+		}
+	}
+
+	// every testcase carries what was tested so results can be grouped without joining against metadata
+	if err = junitprops.AnnotateDir(cfg.ReportDir, runProperties(cfg)); err != nil {
+		log.Printf("Failed to add run properties to JUnit: %v", err)
+	}
+
+	// failure messages and output of harnesses may contain secrets
+	if err = redact.Current.JUnitDir(cfg.ReportDir); err != nil {
+		log.Printf("Failed to redact JUnit: %v", err)
+	}
+
+	if Tracer != nil {
+		Tracer.SetAttributes(runProperties(cfg))
+		if err = Tracer.Export(cfg.TracingEndpoint, cfg.TracingHeaders); err != nil {
+			log.Printf("Failed to export trace: %v", err)
+		} else {
+			log.Printf("Exported run as trace '%s'.", Tracer.TraceID())
+		}
+	}
+
+	// automation running osde2e reacts to how the run ended
+	v := Verdict.Verdict(cfg)
+	if err = v.Write(cfg.ReportDir); err != nil {
+		log.Printf("Failed to write verdict: %v", err)
+	}
+	log.Printf("Run ended with verdict '%s', exit code %d.", v.Result, v.ExitCode)
+
+	if count, removed := artifacts.Current.Summary(); count > 0 {
+		log.Printf("%d artifacts were trimmed or dropped to fit budgets, removing %d bytes.", count, removed)
+	}
+	if err = artifacts.Current.WriteManifest(); err != nil {
+		log.Printf("Failed to write artifact manifest: %v", err)
+	}
+}
+
+// reportOCMStats stores the requests made to the OCM API, failing the run if more failed than OCM_ERROR_BUDGET allows.
+func reportOCMStats(t *testing.T, cfg *config.Config) {
+	summary := osd.Stats.Summary()
+	log.Printf("Made %d OCM API calls with %d retries, %d failed because of OCM (%.2f%%).", summary.Calls,
+		summary.Retries, summary.Errors, summary.ErrorRate*100)
+
+	if data, err := json.MarshalIndent(summary, "", "  "); err != nil {
+		log.Printf("Failed to encode OCM API calls: %v", err)
+	} else if err = artifacts.Current.Write(artifacts.Logs, osd.StatsFile, data); err != nil {
+		log.Printf("Failed to store OCM API calls: %v", err)
+	}
+
+	if err := summary.WriteJUnit(cfg.ReportDir, cfg.Suffix, cfg.OCMErrorBudget); err != nil {
+		log.Printf("Failed to record OCM API error budget: %v", err)
+	}
+	if cfg.OCMErrorBudget > 0 && summary.ErrorRate > cfg.OCMErrorBudget {
+		msg := fmt.Sprintf("%.2f%% of OCM API calls failed, over OCM_ERROR_BUDGET of %.2f%%", summary.ErrorRate*100,
+			cfg.OCMErrorBudget*100)
+		Verdict.Fail("", verdict.Infra, msg)
+		t.Error(msg)
+	}
 }
 
 func reportToTestGrid(t *testing.T, cfg *config.Config, tg *testgrid.TestGrid, buildNum int) {
@@ -125,6 +589,95 @@ func reportToTestGrid(t *testing.T, cfg *config.Config, tg *testgrid.TestGrid, b
 		// create metadata from config and set build version
 		meta := cfg.TestGrid()
 		meta[buildVersionKey] = buildVersion(cfg)
+		meta[verdictKey] = Verdict.Verdict(cfg).Result
+
+		// include how long each install stage took
+		if OSD != nil && OSD.Install != nil {
+			for k, v := range OSD.Install.Metadata() {
+				meta[k] = v
+			}
+		}
+
+		// include why the cluster failed to install
+		if OSD != nil && OSD.InstallFailure != nil {
+			for k, v := range OSD.InstallFailure.Metadata() {
+				meta[k] = v
+			}
+		}
+
+		// include why the cluster failed to upgrade
+		if UpgradeFailure != nil {
+			for k, v := range UpgradeFailure.Metadata() {
+				meta[k] = v
+			}
+		}
+
+		// include how much was removed from artifacts to fit budgets
+		if artifacts.Current != nil {
+			count, removed := artifacts.Current.Summary()
+			meta["artifacts-trimmed"] = count
+			meta["artifacts-trimmed-bytes"] = removed
+		}
+
+		// include how many OCM API calls failed and were retried
+		if OSD != nil {
+			for k, v := range osd.Stats.Summary().Metadata() {
+				meta[k] = v
+			}
+		}
+
+		// include how many tests were skipped for each reason
+		for k, v := range Skips.Metadata() {
+			meta[k] = v
+		}
+
+		// include how long each group of tests ran and whether it spent its budget
+		for k, v := range groups.Budgets.Metadata() {
+			meta[k] = v
+		}
+
+		// include how many runner Pods failed because of the cluster rather than tests
+		for k, v := range runner.InfraRetries.Metadata() {
+			meta[k] = v
+		}
+
+		// include how many failures were given probable causes
+		if cfg.TriageHints {
+			meta["triage-hinted-failures"] = Triage.Hinted()
+		}
+
+		// include whether the cluster's version changed while testing
+		if drift.Current != nil {
+			for k, v := range drift.Current.Metadata() {
+				meta[k] = v
+			}
+		}
+
+		// include the suites consuming the most resources so cluster flavors can be sized for them
+		for k, v := range usage.Metadata(SuiteUsage) {
+			meta[k] = v
+		}
+
+		// include region, zones, instance types, and architectures so failures can be attributed to them
+		meta[topology.ArchitectureKey] = cfg.ComputeArchitecture
+
+		// hosted and classic control planes are compared by their results
+		meta[junitprops.ControlPlane] = hosted.ControlPlane(cfg)
+		if Topology != nil {
+			for k, v := range Topology.Metadata() {
+				meta[k] = v
+			}
+		}
+
+		// let hooks compute metrics from the results before they're uploaded
+		written, errs := metrichooks.Run(cfg.ReportDir, meta, cfg.MetricHooks)
+		for _, err := range errs {
+			log.Print(err)
+		}
+		if len(written) != 0 {
+			log.Printf("Metric hooks wrote %d files.", len(written))
+		}
+		meta["metric-hooks-failed"] = len(errs)
 
 		finished := metadata.Finished{
 			Timestamp: &end,
@@ -144,6 +697,85 @@ func reportToTestGrid(t *testing.T, cfg *config.Config, tg *testgrid.TestGrid, b
 	}
 }
 
+// useGenericProvider returns a provider for the cluster accessed with TEST_KUBECONFIG, identifying the cluster and
+// its version in cfg.
+func useGenericProvider(cfg *config.Config) (*generic.Provider, error) {
+	provider, err := generic.New(string(cfg.Kubeconfig))
+	if err != nil {
+		return nil, err
+	}
+
+	id, version, err := provider.Cluster()
+	if err != nil {
+		return nil, err
+	}
+	cfg.ClusterID = id
+	if cfg.ClusterVersion == "" {
+		cfg.ClusterVersion = osd.VersionPrefix + "v" + version
+	}
+
+	// the kubeconfig is read from the provider once the cluster is ready
+	cfg.Kubeconfig = nil
+	log.Printf("Testing cluster '%s' at version '%s' without OSD.", cfg.ClusterID, cfg.ClusterVersion)
+	return provider, nil
+}
+
+// useLocalProvider returns a provider for the cluster running on the developer's machine, identifying the cluster
+// and its version in cfg. kind clusters are versioned by Kubernetes.
+func useLocalProvider(cfg *config.Config) (*local.Provider, error) {
+	provider, err := local.New(string(cfg.Kubeconfig))
+	if err != nil {
+		return nil, err
+	}
+
+	id, version, err := provider.Cluster()
+	if err != nil {
+		return nil, err
+	}
+	cfg.ClusterID = id
+	if cfg.ClusterVersion == "" {
+		if provider.Flavor == local.FlavorCRC {
+			cfg.ClusterVersion = osd.VersionPrefix + "v" + version
+		} else {
+			cfg.ClusterVersion = "kubernetes-v" + version
+		}
+	}
+
+	// the kubeconfig is read from the provider once the cluster is ready
+	cfg.Kubeconfig = nil
+	log.Printf("Testing local %s cluster '%s' at version '%s'.", provider.Flavor, cfg.ClusterID, cfg.ClusterVersion)
+	return provider, nil
+}
+
+// runProperties describes the cluster and versions tested for attaching to every testcase.
+func runProperties(cfg *config.Config) map[string]string {
+	props := map[string]string{
+		junitprops.ClusterID:      cfg.ClusterID,
+		junitprops.InstallVersion: cfg.ClusterVersion,
+		junitprops.UpgradeVersion: upgradeVersion(cfg),
+		junitprops.Region:         cfg.Region,
+		junitprops.MultiAZ:        strconv.FormatBool(cfg.MultiAZ),
+		junitprops.Architecture:   cfg.ComputeArchitecture,
+		junitprops.NetworkType:    cfg.NetworkType,
+		junitprops.ControlPlane:   hosted.ControlPlane(cfg),
+	}
+
+	// prefer where the cluster was found to run
+	if Topology != nil {
+		props[junitprops.Cloud] = Topology.Cloud
+		if Topology.Region != "" {
+			props[junitprops.Region] = Topology.Region
+		}
+		if len(Topology.Zones) != 0 {
+			props[junitprops.MultiAZ] = strconv.FormatBool(Topology.AZLayout() == topology.MultiAZ)
+		}
+		if len(Topology.Architectures) != 0 {
+			props[junitprops.Architecture] = strings.Join(Topology.Architectures, ",")
+		}
+	}
+	return props
+}
+
 // doBuild checks if this run should be performed.
 func doBuild(ctx context.Context, cfg *config.Config, tg *testgrid.TestGrid) bool {
 	if cfg.CleanRuns > 0 {
@@ -1,17 +1,52 @@
 package osde2e
 
 import (
+	"flag"
+	"os"
 	"testing"
 
 	"github.com/openshift/osde2e/pkg/config"
 
 	// import suites to be tested
+	_ "github.com/openshift/osde2e/test/addons"
+	_ "github.com/openshift/osde2e/test/certs"
+	_ "github.com/openshift/osde2e/test/cloud"
+	_ "github.com/openshift/osde2e/test/console"
+	_ "github.com/openshift/osde2e/test/dns"
+	_ "github.com/openshift/osde2e/test/load"
+	_ "github.com/openshift/osde2e/test/machinepools"
+	_ "github.com/openshift/osde2e/test/management"
+	_ "github.com/openshift/osde2e/test/monitoring"
+	_ "github.com/openshift/osde2e/test/netperf"
+	_ "github.com/openshift/osde2e/test/netplugin"
 	_ "github.com/openshift/osde2e/test/openshift"
+	_ "github.com/openshift/osde2e/test/operators"
+	_ "github.com/openshift/osde2e/test/pruning"
+	_ "github.com/openshift/osde2e/test/scale"
+	_ "github.com/openshift/osde2e/test/security"
 	_ "github.com/openshift/osde2e/test/state"
+	_ "github.com/openshift/osde2e/test/storage"
+	_ "github.com/openshift/osde2e/test/sts"
+	_ "github.com/openshift/osde2e/test/telemetry"
 	_ "github.com/openshift/osde2e/test/verify"
-	_ "github.com/openshift/osde2e/test/operators"
 )
 
+func init() {
+	flag.BoolVar(&config.Cfg.Interactive, "interactive", config.Cfg.Interactive, "pause before teardown when setup or a test fails")
+	flag.BoolVar(&config.Cfg.RefreshVersions, "refresh-versions", config.Cfg.RefreshVersions, "list OSD versions even when they're cached")
+	flag.BoolVar(&config.Cfg.RerunFailed, "rerun-failed", config.Cfg.RerunFailed, "only run the specs which failed in the last run reporting to REPORT_DIR")
+}
+
+// TestMain exits with the code of the run's verdict when it fails, so failures of tests, infrastructure, provisioning,
+// and upgrades can be told apart.
+func TestMain(m *testing.M) {
+	code := m.Run()
+	if v := Verdict.Verdict(config.Cfg); code != 0 && v.ExitCode != 0 {
+		code = v.ExitCode
+	}
+	os.Exit(code)
+}
+
 func TestE2E(t *testing.T) {
 	cfg := config.Cfg
 	RunE2ETests(t, cfg)
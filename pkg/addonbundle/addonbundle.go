@@ -0,0 +1,303 @@
+// Package addonbundle installs sets of interdependent add-ons together, like managed services built from several
+// add-ons, and checks the points where they integrate such as shared SSO and federated monitoring.
+package addonbundle
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/osde2e/pkg/osd"
+)
+
+const (
+	// DefaultHarnessCmd runs harness images which don't set HarnessCmd.
+	DefaultHarnessCmd = "/harness"
+
+	// pollInterval is how often add-on installations are checked.
+	pollInterval = 30 * time.Second
+)
+
+// Bundle is a set of add-ons installed together.
+type Bundle struct {
+	// Name identifies the bundle in logs.
+	Name string `json:"name"`
+
+	// Addons are installed once the add-ons they depend on are ready.
+	Addons []Addon `json:"addons"`
+
+	// SSO is the single sign-on shared by add-ons. It's required if any add-on has an OAuth client.
+	SSO *SSO `json:"sso,omitempty"`
+
+	// Monitoring is the Prometheus federating the metrics of add-ons. It's required if any add-on is federated.
+	Monitoring *Monitoring `json:"monitoring,omitempty"`
+}
+
+// Addon is an add-on of a bundle.
+type Addon struct {
+	// ID of the add-on in OSD.
+	ID string `json:"id"`
+
+	// Params are the parameters the add-on is installed with.
+	Params map[string]string `json:"params,omitempty"`
+
+	// DependsOn are the IDs of add-ons which must be ready before this one is installed.
+	DependsOn []string `json:"depends_on,omitempty"`
+
+	// Namespace is where the add-on runs. It's given to the harnesses of the bundle.
+	Namespace string `json:"namespace"`
+
+	// Harness is the image of the add-on's test harness. The add-on isn't tested by a harness if it's empty.
+	Harness string `json:"harness,omitempty"`
+
+	// HarnessCmd runs the harness in its image. It's DefaultHarnessCmd if empty.
+	HarnessCmd string `json:"harness_cmd,omitempty"`
+
+	// OAuthClient is the OAuth client the add-on signs users in through the shared SSO with.
+	OAuthClient string `json:"oauth_client,omitempty"`
+
+	// Federated add-ons have their metrics scraped by the Prometheus of the bundle.
+	Federated bool `json:"federated,omitempty"`
+}
+
+// SSO is single sign-on shared by the add-ons of a bundle.
+type SSO struct {
+	// IdentityProvider is the name of the identity provider the cluster's OAuth is configured with.
+	IdentityProvider string `json:"identity_provider"`
+}
+
+// Monitoring is the Prometheus federating the metrics of the add-ons of a bundle.
+type Monitoring struct {
+	// Namespace and Pod locate the Prometheus, which is queried for its targets.
+	Namespace string `json:"namespace"`
+	Pod       string `json:"pod"`
+
+	// Container runs Prometheus in the pod. It's 'prometheus' if empty.
+	Container string `json:"container,omitempty"`
+}
+
+// Load reads a YAML bundle from file.
+func Load(file string) (*Bundle, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read add-on bundle '%s': %v", file, err)
+	}
+	return Parse(data)
+}
+
+// Parse decodes and validates a YAML bundle.
+func Parse(data []byte) (*Bundle, error) {
+	b := new(Bundle)
+	if err := yaml.Unmarshal(data, b); err != nil {
+		return nil, fmt.Errorf("couldn't decode add-on bundle: %v", err)
+	}
+
+	if len(b.Addons) == 0 {
+		return nil, fmt.Errorf("add-on bundle '%s' has no add-ons", b.Name)
+	}
+	ids := map[string]bool{}
+	for i, a := range b.Addons {
+		if a.Harness != "" && a.HarnessCmd == "" {
+			b.Addons[i].HarnessCmd = DefaultHarnessCmd
+		}
+
+		if a.ID == "" || a.Namespace == "" {
+			return nil, fmt.Errorf("add-ons of bundle '%s' must have an ID and a namespace", b.Name)
+		} else if ids[a.ID] {
+			return nil, fmt.Errorf("add-on '%s' is in bundle '%s' more than once", a.ID, b.Name)
+		}
+		ids[a.ID] = true
+
+		if a.OAuthClient != "" && (b.SSO == nil || b.SSO.IdentityProvider == "") {
+			return nil, fmt.Errorf("add-on '%s' has an OAuth client but bundle '%s' has no SSO identity provider", a.ID, b.Name)
+		} else if a.Federated && (b.Monitoring == nil || b.Monitoring.Namespace == "" || b.Monitoring.Pod == "") {
+			return nil, fmt.Errorf("add-on '%s' is federated but bundle '%s' has no monitoring namespace and pod", a.ID, b.Name)
+		}
+	}
+	for _, a := range b.Addons {
+		for _, dep := range a.DependsOn {
+			if !ids[dep] {
+				return nil, fmt.Errorf("add-on '%s' depends on '%s', which isn't in bundle '%s'", a.ID, dep, b.Name)
+			}
+		}
+	}
+
+	if _, err := b.Waves(); err != nil {
+		return nil, err
+	}
+	if b.Monitoring != nil && b.Monitoring.Container == "" {
+		b.Monitoring.Container = "prometheus"
+	}
+	return b, nil
+}
+
+// Waves groups the add-ons of b into the order they're installed. Each wave only depends on add-ons in earlier
+// waves, so its add-ons are installed together once those are ready.
+func (b *Bundle) Waves() (waves [][]Addon, err error) {
+	ready := map[string]bool{}
+	for len(ready) < len(b.Addons) {
+		var wave []Addon
+		for _, a := range b.Addons {
+			if !ready[a.ID] && dependenciesReady(a, ready) {
+				wave = append(wave, a)
+			}
+		}
+		if len(wave) == 0 {
+			var cycle []string
+			for _, a := range b.Addons {
+				if !ready[a.ID] {
+					cycle = append(cycle, a.ID)
+				}
+			}
+			return nil, fmt.Errorf("add-ons %v of bundle '%s' depend on each other", cycle, b.Name)
+		}
+
+		for _, a := range wave {
+			ready[a.ID] = true
+		}
+		waves = append(waves, wave)
+	}
+	return
+}
+
+// HarnessEnv describes the add-ons of b as set in harness.AddonsEnv.
+func (b *Bundle) HarnessEnv() string {
+	pairs := make([]string, len(b.Addons))
+	for i, a := range b.Addons {
+		pairs[i] = a.ID + "=" + a.Namespace
+	}
+	return strings.Join(pairs, ",")
+}
+
+// Install installs the add-ons of b on clusterID wave by wave, waiting for each wave to be ready before installing
+// the next. Add-ons already installed have their parameters updated.
+func Install(client *osd.OSD, clusterID string, b *Bundle, timeout time.Duration) error {
+	waves, err := b.Waves()
+	if err != nil {
+		return err
+	}
+
+	for _, wave := range waves {
+		for _, a := range wave {
+			if err = client.ConfigureAddon(clusterID, a.ID, a.Params); err != nil {
+				return err
+			}
+		}
+		for _, a := range wave {
+			if err = client.WaitForAddon(clusterID, a.ID, pollInterval, timeout); err != nil {
+				return fmt.Errorf("add-on '%s' of bundle '%s' wasn't ready: %v", a.ID, b.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// InstallProblems describes the add-ons of b which aren't ready in snapshot.
+func InstallProblems(b *Bundle, snapshot *osd.ClusterSnapshot) (problems []string) {
+	for _, a := range b.Addons {
+		if installed, ok := snapshot.Addons[a.ID]; !ok {
+			problems = append(problems, fmt.Sprintf("add-on '%s' isn't installed", a.ID))
+		} else if installed.State != osd.AddonReady {
+			problems = append(problems, fmt.Sprintf("add-on '%s' is %s, expected %s", a.ID, installed.State, osd.AddonReady))
+		}
+	}
+	return
+}
+
+// SSOProblems describes how the add-ons of b don't share SSO, given the names of the identity providers of the
+// cluster's OAuth and the OAuth clients which exist.
+func SSOProblems(b *Bundle, providers, clients []string) (problems []string) {
+	if b.SSO == nil {
+		return nil
+	}
+
+	if !contains(providers, b.SSO.IdentityProvider) {
+		problems = append(problems, fmt.Sprintf("cluster OAuth doesn't have identity provider '%s'", b.SSO.IdentityProvider))
+	}
+	for _, a := range b.Addons {
+		if a.OAuthClient != "" && !contains(clients, a.OAuthClient) {
+			problems = append(problems, fmt.Sprintf("add-on '%s' doesn't have OAuth client '%s'", a.ID, a.OAuthClient))
+		}
+	}
+	return
+}
+
+// Target is an active target of Prometheus.
+type Target struct {
+	Labels    map[string]string `json:"labels"`
+	Health    string            `json:"health"`
+	LastError string            `json:"lastError"`
+}
+
+// ParseTargets decodes the active targets from a response of the Prometheus targets API.
+func ParseTargets(data []byte) ([]Target, error) {
+	var resp struct {
+		Status string `json:"status"`
+		Error  string `json:"error"`
+		Data   struct {
+			ActiveTargets []Target `json:"activeTargets"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("couldn't decode targets: %v", err)
+	} else if resp.Status != "success" {
+		return nil, fmt.Errorf("failed getting targets: %s", resp.Error)
+	}
+	return resp.Data.ActiveTargets, nil
+}
+
+// FederationProblems describes the federated add-ons of b whose metrics aren't scraped by the Prometheus of the
+// bundle, given its active targets.
+func FederationProblems(b *Bundle, targets []Target) (problems []string) {
+	for _, a := range b.Addons {
+		if !a.Federated {
+			continue
+		}
+
+		var found, up bool
+		var lastError string
+		for _, t := range targets {
+			if t.Labels["namespace"] != a.Namespace {
+				continue
+			}
+			found = true
+			if t.Health == "up" {
+				up = true
+			} else if lastError == "" {
+				lastError = t.LastError
+			}
+		}
+
+		if !found {
+			problems = append(problems, fmt.Sprintf("metrics of add-on '%s' in namespace '%s' aren't federated", a.ID, a.Namespace))
+		} else if !up {
+			problems = append(problems, fmt.Sprintf("no targets of add-on '%s' are up: %s", a.ID, lastError))
+		}
+	}
+	sort.Strings(problems)
+	return
+}
+
+// dependenciesReady returns true if all add-ons a depends on are ready.
+func dependenciesReady(a Addon, ready map[string]bool) bool {
+	for _, dep := range a.DependsOn {
+		if !ready[dep] {
+			return false
+		}
+	}
+	return true
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
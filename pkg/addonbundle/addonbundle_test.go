@@ -0,0 +1,139 @@
+package addonbundle
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/openshift/osde2e/pkg/osd"
+)
+
+func TestBundles(t *testing.T) {
+	b, err := Load("../../addonbundles/managed-services.yaml")
+	if err != nil {
+		t.Fatalf("Failed loading bundle: %v", err)
+	}
+
+	waves, err := b.Waves()
+	if err != nil {
+		t.Fatalf("Failed ordering bundle: %v", err)
+	}
+	if len(waves) != 2 || waves[0][0].ID != "managed-api-service" || waves[1][0].ID != "managed-odh" {
+		t.Errorf("expected the API management add-on to be installed first, got %+v", waves)
+	}
+	if b.Monitoring.Container != "prometheus" {
+		t.Errorf("expected default Prometheus container, got '%s'", b.Monitoring.Container)
+	}
+
+	expected := "managed-api-service=redhat-rhoam-operator,managed-odh=redhat-ods-operator"
+	if env := b.HarnessEnv(); env != expected {
+		t.Errorf("expected harness env '%s', got '%s'", expected, env)
+	}
+}
+
+func TestWaves(t *testing.T) {
+	b, err := Parse([]byte(`
+name: diamond
+addons:
+- {id: d, namespace: d, depends_on: [b, c]}
+- {id: b, namespace: b, depends_on: [a]}
+- {id: c, namespace: c, depends_on: [a]}
+- {id: a, namespace: a}
+- {id: e, namespace: e}
+`))
+	if err != nil {
+		t.Fatalf("Failed parsing bundle: %v", err)
+	}
+
+	waves, err := b.Waves()
+	if err != nil {
+		t.Fatalf("Failed ordering bundle: %v", err)
+	}
+	var ids [][]string
+	for _, wave := range waves {
+		var wids []string
+		for _, a := range wave {
+			wids = append(wids, a.ID)
+		}
+		ids = append(ids, wids)
+	}
+	if expected := [][]string{{"a", "e"}, {"b", "c"}, {"d"}}; !reflect.DeepEqual(ids, expected) {
+		t.Errorf("expected waves %v, got %v", expected, ids)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, invalid := range []string{
+		"name: empty\n",
+		"addons:\n- namespace: a\n",
+		"addons:\n- id: a\n",
+		"addons:\n- {id: a, namespace: a}\n- {id: a, namespace: b}\n",
+		"addons:\n- {id: a, namespace: a, depends_on: [b]}\n",
+		"addons:\n- {id: a, namespace: a, depends_on: [b]}\n- {id: b, namespace: b, depends_on: [a]}\n",
+		"addons:\n- {id: a, namespace: a, oauth_client: a}\n",
+		"addons:\n- {id: a, namespace: a, federated: true}\n",
+		"monitoring: {namespace: m}\naddons:\n- {id: a, namespace: a, federated: true}\n",
+	} {
+		if _, err := Parse([]byte(invalid)); err == nil {
+			t.Errorf("bundle should be invalid: %s", invalid)
+		}
+	}
+}
+
+func TestProblems(t *testing.T) {
+	b, err := Load("../../addonbundles/managed-services.yaml")
+	if err != nil {
+		t.Fatalf("Failed loading bundle: %v", err)
+	}
+
+	snapshot := &osd.ClusterSnapshot{Addons: map[string]osd.AddonSnapshot{
+		"managed-api-service": {State: osd.AddonReady},
+		"managed-odh":         {State: "installing"},
+	}}
+	expected := []string{"add-on 'managed-odh' is installing, expected ready"}
+	if problems := InstallProblems(b, snapshot); !reflect.DeepEqual(problems, expected) {
+		t.Errorf("expected install problems %v, got %v", expected, problems)
+	}
+
+	if problems := SSOProblems(b, []string{"github", "rhoam"}, []string{"redhat-rhoam-rhssouser", "redhat-ods-dashboard"}); len(problems) != 0 {
+		t.Errorf("expected no SSO problems, got %v", problems)
+	}
+	expected = []string{
+		"cluster OAuth doesn't have identity provider 'rhoam'",
+		"add-on 'managed-odh' doesn't have OAuth client 'redhat-ods-dashboard'",
+	}
+	if problems := SSOProblems(b, []string{"github"}, []string{"redhat-rhoam-rhssouser"}); !reflect.DeepEqual(problems, expected) {
+		t.Errorf("expected SSO problems %v, got %v", expected, problems)
+	}
+}
+
+func TestFederationProblems(t *testing.T) {
+	b, err := Load("../../addonbundles/managed-services.yaml")
+	if err != nil {
+		t.Fatalf("Failed loading bundle: %v", err)
+	}
+
+	targets, err := ParseTargets([]byte(`{"status":"success","data":{"activeTargets":[
+		{"labels":{"namespace":"redhat-rhoam-operator","job":"rhoam"},"health":"up"},
+		{"labels":{"namespace":"redhat-ods-operator","job":"odh"},"health":"down","lastError":"connection refused"}
+	]}}`))
+	if err != nil {
+		t.Fatalf("Failed parsing targets: %v", err)
+	}
+
+	expected := []string{"no targets of add-on 'managed-odh' are up: connection refused"}
+	if problems := FederationProblems(b, targets); !reflect.DeepEqual(problems, expected) {
+		t.Errorf("expected federation problems %v, got %v", expected, problems)
+	}
+
+	expected = []string{
+		"metrics of add-on 'managed-api-service' in namespace 'redhat-rhoam-operator' aren't federated",
+		"metrics of add-on 'managed-odh' in namespace 'redhat-ods-operator' aren't federated",
+	}
+	if problems := FederationProblems(b, nil); !reflect.DeepEqual(problems, expected) {
+		t.Errorf("expected federation problems %v, got %v", expected, problems)
+	}
+
+	if _, err = ParseTargets([]byte(`{"status":"error","error":"unavailable"}`)); err == nil {
+		t.Error("expected an error from a failed response")
+	}
+}
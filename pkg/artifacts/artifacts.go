@@ -0,0 +1,312 @@
+// Package artifacts stores artifacts of a run, such as logs and must-gather, within per-category size budgets.
+package artifacts
+
+import (
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/openshift/osde2e/pkg/redact"
+)
+
+const (
+	// Logs are logs of the cluster and its components.
+	Logs = "logs"
+
+	// MustGather is output of must-gather.
+	MustGather = "must-gather"
+
+	// Events are events recorded in the cluster.
+	Events = "events"
+
+	// State is the state of cluster resources.
+	State = "state"
+
+	// Results are results written by test runners.
+	Results = "results"
+
+	// Credentials grant access to the cluster, such as its kubeconfig.
+	Credentials = "credentials"
+
+	// ManifestFile lists the artifacts stored and how they were trimmed.
+	ManifestFile = "artifacts.json"
+
+	// Ext is added to the name of compressed artifacts.
+	Ext = ".zst"
+
+	// minSize is the smallest amount of budget worth storing a truncated artifact in.
+	minSize = 1024
+
+	// maxTrims is the number of attempts made to trim an artifact to fit its budget.
+	maxTrims = 5
+
+	// sniffLen is the number of bytes checked to decide if an artifact is text.
+	sniffLen = 512
+)
+
+// Current stores the artifacts of the run in progress. It is set when the run starts.
+var Current *Manager
+
+// uncompressed are patterns of artifacts which readers look up by name, such as JUnit results gathered by Prow and
+// TestGrid, Prometheus metrics, and snapshots compared between runs. They're stored whole under their name.
+var uncompressed = []string{"junit*.xml", "*.prom", "*-snapshot.json"}
+
+// Compressible returns true if the artifact name may be compressed or trimmed when stored.
+func Compressible(name string) bool {
+	base := filepath.Base(name)
+	for _, pattern := range uncompressed {
+		if match, _ := filepath.Match(pattern, base); match {
+			return false
+		}
+	}
+	return true
+}
+
+// Record describes how an artifact was stored.
+type Record struct {
+	Name     string `json:"name"`
+	Category string `json:"category"`
+
+	// File is where the artifact was written, relative to the artifact directory. It's empty if dropped.
+	File string `json:"file,omitempty"`
+
+	// Size is the original size of the artifact.
+	Size int64 `json:"size"`
+
+	// Stored is the number of bytes written.
+	Stored int64 `json:"stored"`
+
+	// Trimmed is the number of bytes removed from the middle of the artifact.
+	Trimmed int64 `json:"trimmed,omitempty"`
+
+	// Dropped is set if there was no budget left for the artifact.
+	Dropped bool `json:"dropped,omitempty"`
+
+	// Encrypted is set if the artifact was encrypted because its category may contain secrets.
+	Encrypted bool `json:"encrypted,omitempty"`
+}
+
+// Manager writes artifacts to a directory, compressing them and trimming those exceeding the budget of their category.
+type Manager struct {
+	// Dir is where artifacts are written.
+	Dir string
+
+	// Budgets are the maximum number of bytes stored for each category. Categories without a budget are unlimited.
+	Budgets map[string]int64
+
+	mu        sync.Mutex
+	used      map[string]int64
+	records   []Record
+	enc       *zstd.Encoder
+	key       *rsa.PublicKey
+	encrypted map[string]bool
+}
+
+// New returns a Manager storing artifacts in dir.
+func New(dir string, budgets map[string]int64) (*Manager, error) {
+	enc, err := zstd.NewWriter(nil)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't setup compression: %v", err)
+	}
+	return &Manager{
+		Dir:     dir,
+		Budgets: budgets,
+		used:    map[string]int64{},
+		enc:     enc,
+	}, nil
+}
+
+// ParseBudgets converts budgets in the form of quantities, such as '50Mi', to bytes.
+func ParseBudgets(budgets map[string]string) (map[string]int64, error) {
+	parsed := make(map[string]int64, len(budgets))
+	for category, budget := range budgets {
+		q, err := resource.ParseQuantity(budget)
+		if err != nil {
+			return nil, fmt.Errorf("invalid budget '%s' for %s: %v", budget, category, err)
+		}
+		parsed[category] = q.Value()
+	}
+	return parsed, nil
+}
+
+// EncryptCategories encrypts artifacts of categories with pub once they're compressed, so artifacts which may contain
+// secrets can be uploaded to shared buckets. They can be decrypted with osde2e-decrypt and the private key.
+func (m *Manager) EncryptCategories(pub *rsa.PublicKey, categories ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.key, m.encrypted = pub, map[string]bool{}
+	for _, c := range categories {
+		m.encrypted[c] = true
+	}
+}
+
+// Encrypts returns true if artifacts of category are encrypted.
+func (m *Manager) Encrypts(category string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.key != nil && m.encrypted[category]
+}
+
+// Write stores data as name in category. Data is compressed and, when text, has secrets masked and its middle
+// removed until it fits the remaining budget. Artifacts that can't fit are dropped. Artifacts looked up by name, such as
+// JUnit results, are neither compressed nor trimmed. Artifacts of encrypted categories are encrypted last. What was
+// stored is recorded in the manifest.
+func (m *Manager) Write(category, name string, data []byte) (err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// credentials are meant to be secret and only stored when encrypted
+	if category != Credentials && isText(data) {
+		data = redact.Current.Bytes(data)
+	}
+
+	rec := Record{
+		Name:     name,
+		Category: category,
+		Size:     int64(len(data)),
+	}
+	defer func() {
+		m.records = append(m.records, rec)
+	}()
+
+	budget, limited := m.Budgets[category]
+	remaining := budget - m.used[category]
+
+	// artifacts not worth compressing, such as tarballs, are stored as is
+	out, file := data, name
+	if Compressible(name) {
+		if compressed := m.compress(data); len(compressed) < len(data) {
+			out, file = compressed, name+Ext
+		}
+	}
+
+	if limited && int64(len(out)) > remaining {
+		if !Compressible(name) {
+			log.Printf("Artifact '%s' is over the %s budget by %d bytes, storing it whole as it's looked up by name.",
+				name, category, int64(len(out))-remaining)
+		} else if remaining < minSize || !isText(data) {
+			log.Printf("Dropping artifact '%s', %d bytes remain of the %s budget.", name, remaining, category)
+			rec.Dropped = true
+			return nil
+		} else {
+			var trimmed []byte
+			if trimmed, out = m.trim(data, remaining); out == nil {
+				log.Printf("Dropping artifact '%s', it couldn't be trimmed to fit the %s budget.", name, category)
+				rec.Dropped = true
+				return nil
+			}
+			file = name + Ext
+			rec.Trimmed = int64(len(data) - len(trimmed))
+			log.Printf("Trimmed %d bytes from artifact '%s' to fit the %s budget.", rec.Trimmed, name, category)
+		}
+	}
+
+	if m.key != nil && m.encrypted[category] {
+		if out, err = Encrypt(m.key, out); err != nil {
+			return fmt.Errorf("couldn't encrypt artifact '%s': %v", name, err)
+		}
+		file += EncryptedExt
+		rec.Encrypted = true
+	}
+
+	path := filepath.Join(m.Dir, file)
+	if err = os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return fmt.Errorf("couldn't create directory for artifact '%s': %v", name, err)
+	}
+	if err = ioutil.WriteFile(path, out, os.ModePerm); err != nil {
+		return fmt.Errorf("couldn't write artifact '%s': %v", name, err)
+	}
+
+	rec.File, rec.Stored = file, int64(len(out))
+	m.used[category] += rec.Stored
+	return nil
+}
+
+// trim removes the middle of data until it compresses to within size, returning the trimmed and compressed data.
+// The compressed data is nil if it can't be made to fit.
+func (m *Manager) trim(data []byte, size int64) (trimmed, compressed []byte) {
+	keep, compressed := len(data), m.compress(data)
+	for i := 0; i < maxTrims; i++ {
+		// shrink in proportion to how far over the budget the compressed data is, with some margin
+		if keep = int(float64(keep) * float64(size) / float64(len(compressed)) * 0.9); keep <= 0 {
+			break
+		}
+
+		trimmed = headTail(data, keep)
+		if compressed = m.compress(trimmed); int64(len(compressed)) <= size {
+			return trimmed, compressed
+		}
+	}
+	return nil, nil
+}
+
+// headTail keeps the first and last halves of keep bytes of data, replacing the rest with a marker.
+func headTail(data []byte, keep int) []byte {
+	if keep >= len(data) {
+		return data
+	}
+
+	head, tail := data[:keep/2], data[len(data)-keep/2:]
+	marker := fmt.Sprintf("\n\n... [osde2e trimmed %d bytes] ...\n\n", len(data)-len(head)-len(tail))
+
+	out := make([]byte, 0, len(head)+len(marker)+len(tail))
+	out = append(out, head...)
+	out = append(out, marker...)
+	return append(out, tail...)
+}
+
+func (m *Manager) compress(data []byte) []byte {
+	return m.enc.EncodeAll(data, make([]byte, 0, len(data)/4))
+}
+
+// isText returns true if data appears to be text that can be usefully truncated.
+func isText(data []byte) bool {
+	if len(data) > sniffLen {
+		data = data[:sniffLen]
+	}
+	return strings.HasPrefix(http.DetectContentType(data), "text/")
+}
+
+// Records returns a copy of what has been stored.
+func (m *Manager) Records() []Record {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Record(nil), m.records...)
+}
+
+// Summary returns the number of artifacts trimmed or dropped and the bytes removed from them.
+func (m *Manager) Summary() (count int, removed int64) {
+	for _, rec := range m.Records() {
+		if rec.Dropped {
+			count++
+			removed += rec.Size
+		} else if rec.Trimmed > 0 {
+			count++
+			removed += rec.Trimmed
+		}
+	}
+	return
+}
+
+// WriteManifest writes the records of artifacts stored to ManifestFile.
+func (m *Manager) WriteManifest() error {
+	data, err := json.MarshalIndent(m.Records(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("couldn't encode artifact manifest: %v", err)
+	}
+
+	if err = ioutil.WriteFile(filepath.Join(m.Dir, ManifestFile), data, os.ModePerm); err != nil {
+		return fmt.Errorf("couldn't write artifact manifest: %v", err)
+	}
+	return nil
+}
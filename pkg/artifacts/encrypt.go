@@ -0,0 +1,155 @@
+package artifacts
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+)
+
+const (
+	// EncryptedExt is added to the name of encrypted artifacts.
+	EncryptedExt = ".enc"
+
+	// keySize is the size in bytes of the AES key each artifact is encrypted with.
+	keySize = 32
+)
+
+// encryptedMagic starts encrypted artifacts, identifying their format.
+var encryptedMagic = []byte("osde2e-encrypted-v1\n")
+
+// LoadPublicKey reads a PEM encoded RSA public key from file.
+func LoadPublicKey(file string) (*rsa.PublicKey, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read public key '%s': %v", file, err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("public key '%s' isn't PEM encoded", file)
+	}
+	if pub, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
+		if rsaPub, ok := pub.(*rsa.PublicKey); ok {
+			return rsaPub, nil
+		}
+		return nil, fmt.Errorf("public key '%s' isn't an RSA key", file)
+	}
+	pub, err := x509.ParsePKCS1PublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse public key '%s': %v", file, err)
+	}
+	return pub, nil
+}
+
+// LoadPrivateKey reads a PEM encoded RSA private key from file.
+func LoadPrivateKey(file string) (*rsa.PrivateKey, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read private key '%s': %v", file, err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("private key '%s' isn't PEM encoded", file)
+	}
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		if rsaKey, ok := key.(*rsa.PrivateKey); ok {
+			return rsaKey, nil
+		}
+		return nil, fmt.Errorf("private key '%s' isn't an RSA key", file)
+	}
+	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse private key '%s': %v", file, err)
+	}
+	return key, nil
+}
+
+// Encrypt encrypts data with a random AES-GCM key, which is itself encrypted with pub using RSA-OAEP so only the
+// holder of the private key can decrypt data.
+func Encrypt(pub *rsa.PublicKey, data []byte) ([]byte, error) {
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("couldn't generate key: %v", err)
+	}
+	wrapped, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, pub, key, nil)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't encrypt key: %v", err)
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("couldn't generate nonce: %v", err)
+	}
+
+	var out bytes.Buffer
+	out.Write(encryptedMagic)
+	binary.Write(&out, binary.BigEndian, uint16(len(wrapped)))
+	out.Write(wrapped)
+	out.Write(nonce)
+	out.Write(gcm.Seal(nil, nonce, data, encryptedMagic))
+	return out.Bytes(), nil
+}
+
+// Decrypt returns the data encrypted with the public key of priv by Encrypt.
+func Decrypt(priv *rsa.PrivateKey, data []byte) ([]byte, error) {
+	if !IsEncrypted(data) {
+		return nil, fmt.Errorf("data wasn't encrypted by osde2e")
+	}
+	data = data[len(encryptedMagic):]
+
+	if len(data) < 2 {
+		return nil, fmt.Errorf("encrypted data is truncated")
+	}
+	wrappedLen := int(binary.BigEndian.Uint16(data))
+	if data = data[2:]; len(data) < wrappedLen {
+		return nil, fmt.Errorf("encrypted data is truncated")
+	}
+
+	key, err := rsa.DecryptOAEP(sha256.New(), rand.Reader, priv, data[:wrappedLen], nil)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't decrypt key, it may have been encrypted for another key: %v", err)
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if data = data[wrappedLen:]; len(data) < gcm.NonceSize() {
+		return nil, fmt.Errorf("encrypted data is truncated")
+	}
+	plain, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], encryptedMagic)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't decrypt data: %v", err)
+	}
+	return plain, nil
+}
+
+// IsEncrypted returns true if data was encrypted by Encrypt.
+func IsEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, encryptedMagic)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't setup cipher: %v", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't setup cipher: %v", err)
+	}
+	return gcm, nil
+}
@@ -0,0 +1,113 @@
+package artifacts
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+// writeKeys generates an RSA key pair, writing it to PEM files in dir.
+func writeKeys(t *testing.T, dir string) (pubFile, privFile string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("failed to encode public key: %v", err)
+	}
+
+	pubFile, privFile = filepath.Join(dir, "key.pub.pem"), filepath.Join(dir, "key.pem")
+	pubPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER})
+	privPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	if err = ioutil.WriteFile(pubFile, pubPEM, 0600); err != nil {
+		t.Fatalf("failed to write public key: %v", err)
+	}
+	if err = ioutil.WriteFile(privFile, privPEM, 0600); err != nil {
+		t.Fatalf("failed to write private key: %v", err)
+	}
+	return
+}
+
+func TestEncrypt(t *testing.T) {
+	m, cleanup := newManager(t, nil)
+	defer cleanup()
+
+	pubFile, privFile := writeKeys(t, m.Dir)
+	pub, err := LoadPublicKey(pubFile)
+	if err != nil {
+		t.Fatalf("failed to load public key: %v", err)
+	}
+	priv, err := LoadPrivateKey(privFile)
+	if err != nil {
+		t.Fatalf("failed to load private key: %v", err)
+	}
+
+	m.EncryptCategories(pub, MustGather, Credentials)
+	if !m.Encrypts(Credentials) || m.Encrypts(Logs) {
+		t.Fatal("expected only must-gather and credentials to be encrypted")
+	}
+
+	kubeconfig := bytes.Repeat([]byte("client-key-data: c2VjcmV0\n"), 100)
+	if err = m.Write(Credentials, "kubeconfig", kubeconfig); err != nil {
+		t.Fatalf("failed to write artifact: %v", err)
+	}
+	if err = m.Write(Logs, "build-log.txt", []byte("not secret")); err != nil {
+		t.Fatalf("failed to write artifact: %v", err)
+	}
+
+	records := m.Records()
+	if rec := records[0]; !rec.Encrypted || rec.File != "kubeconfig"+Ext+EncryptedExt {
+		t.Fatalf("expected kubeconfig to be compressed then encrypted, got %+v", rec)
+	} else if rec := records[1]; rec.Encrypted || rec.File != "build-log.txt" {
+		t.Errorf("expected logs to be stored unencrypted, got %+v", rec)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(m.Dir, records[0].File))
+	if err != nil {
+		t.Fatalf("failed to read artifact: %v", err)
+	}
+	if !IsEncrypted(data) || bytes.Contains(data, []byte("client-key-data")) {
+		t.Fatal("expected artifact to be encrypted")
+	}
+
+	compressed, err := Decrypt(priv, data)
+	if err != nil {
+		t.Fatalf("failed to decrypt artifact: %v", err)
+	}
+	plainFile := filepath.Join(m.Dir, "decrypted"+Ext)
+	if err = ioutil.WriteFile(plainFile, compressed, 0600); err != nil {
+		t.Fatalf("failed to write decrypted artifact: %v", err)
+	}
+	if plain := readArtifact(t, m, filepath.Base(plainFile)); !bytes.Equal(plain, kubeconfig) {
+		t.Error("decrypted artifact doesn't match what was written")
+	}
+
+	// tampering and other keys are detected
+	data[len(data)-1] ^= 0xff
+	if _, err = Decrypt(priv, data); err == nil {
+		t.Error("expected tampered artifact not to decrypt")
+	}
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	if _, err = Decrypt(other, data); err == nil {
+		t.Error("expected artifact not to decrypt with another key")
+	}
+	if _, err = Decrypt(priv, []byte("plain text")); err == nil {
+		t.Error("expected unencrypted data not to decrypt")
+	}
+
+	if _, err = LoadPublicKey(filepath.Join(m.Dir, "missing.pem")); err == nil {
+		t.Error("expected an error loading a missing key")
+	}
+	if _, err = LoadPublicKey(privFile); err == nil {
+		t.Error("expected an error loading a private key as a public key")
+	}
+}
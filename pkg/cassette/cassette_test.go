@@ -0,0 +1,142 @@
+package cassette
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// upstream serves a token endpoint and an API returning credentials.
+func upstream() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch req.URL.Path {
+		case "/token":
+			w.Write([]byte(`{"access_token":"secret-access","refresh_token":"secret-refresh","expires_in":900}`))
+		case "/api/clusters/1":
+			w.Write([]byte(`{"id":"1","credentials":[{"kubeconfig":"secret-kubeconfig","user":"admin"}]}`))
+		default:
+			http.NotFound(w, req)
+		}
+	}))
+}
+
+func TestRecordReplay(t *testing.T) {
+	srv := upstream()
+	defer srv.Close()
+
+	c := new(Cassette)
+	proxy, err := NewProxy(c, srv.URL)
+	if err != nil {
+		t.Fatalf("failed to start proxy: %v", err)
+	}
+	defer proxy.Close()
+
+	resp, err := http.PostForm(proxy.URL+"/token", url.Values{"grant_type": {"refresh_token"}, "refresh_token": {"secret-offline"}})
+	if err != nil {
+		t.Fatalf("failed token request: %v", err)
+	}
+	resp.Body.Close()
+
+	resp, err = http.Get(proxy.URL + "/api/clusters/1?b=2&a=1")
+	if err != nil {
+		t.Fatalf("failed API request: %v", err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(body), "secret-kubeconfig") {
+		t.Errorf("expected client to receive unsanitized response, got %s", body)
+	}
+
+	// recordings are sanitized
+	dir, err := ioutil.TempDir("", "cassette")
+	if err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "cassette.yaml")
+	if err = c.Save(file); err != nil {
+		t.Fatalf("failed to save cassette: %v", err)
+	}
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatalf("failed to read cassette: %v", err)
+	}
+	if strings.Contains(string(data), "secret") {
+		t.Errorf("expected secrets to be removed from cassette:\n%s", data)
+	}
+
+	// recordings are replayed
+	loaded, err := Load(file)
+	if err != nil {
+		t.Fatalf("failed to load cassette: %v", err)
+	}
+	replay := httptest.NewServer(loaded)
+	defer replay.Close()
+
+	resp, err = http.Get(replay.URL + "/api/clusters/1?a=1&b=2")
+	if err != nil {
+		t.Fatalf("failed replayed request: %v", err)
+	}
+	body, _ = ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), `"kubeconfig":"REDACTED"`) {
+		t.Errorf("unexpected replayed response %d: %s", resp.StatusCode, body)
+	}
+
+	if resp, err = http.Get(replay.URL + "/api/clusters/2"); err != nil {
+		t.Fatalf("failed replayed request: %v", err)
+	} else if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected unrecorded request to be not found, got %d", resp.StatusCode)
+	}
+	if missed := loaded.Missed(); len(missed) != 1 || missed[0].Path != "/api/clusters/2" {
+		t.Errorf("expected unrecorded request to be missed: %v", missed)
+	}
+}
+
+func TestReplayOrder(t *testing.T) {
+	c := &Cassette{}
+	for _, state := range []string{"pending", "installing", "ready"} {
+		c.Add(Interaction{
+			Request:  Request{Method: "GET", Path: "/cluster"},
+			Response: Response{Status: 200, Body: state},
+		})
+	}
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+
+	for _, expected := range []string{"pending", "installing", "ready", "ready"} {
+		resp, err := http.Get(srv.URL + "/cluster")
+		if err != nil {
+			t.Fatalf("failed request: %v", err)
+		}
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(body) != expected {
+			t.Errorf("expected '%s', got '%s'", expected, body)
+		}
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	form := Sanitize("application/x-www-form-urlencoded", "client_secret=abc&grant_type=password&password=hunter2")
+	if values, _ := url.ParseQuery(form); values.Get("client_secret") != Redacted || values.Get("password") != Redacted ||
+		values.Get("grant_type") != "password" {
+		t.Errorf("unexpected sanitized form: %s", form)
+	}
+
+	if body := Sanitize("text/plain", "password=hunter2"); body != "password=hunter2" {
+		t.Errorf("expected text to be unchanged, got '%s'", body)
+	}
+
+	json := Sanitize("application/json", `{"access_token":"abc"}`)
+	if json != `{"access_token":"`+FakeToken("access")+`"}` {
+		t.Errorf("expected access token to be faked, got %s", json)
+	}
+}
@@ -0,0 +1,115 @@
+package cassette
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+)
+
+// Recorder is an http.RoundTripper which records sanitized interactions to Cassette.
+type Recorder struct {
+	// Transport performs requests. http.DefaultTransport is used if nil.
+	Transport http.RoundTripper
+
+	Cassette *Cassette
+}
+
+// RoundTrip performs req, recording it and the response.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport := r.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		if reqBody, err = ioutil.ReadAll(req.Body); err != nil {
+			return nil, fmt.Errorf("couldn't read request body: %v", err)
+		}
+		req.Body.Close()
+		req.Body = ioutil.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read response body: %v", err)
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+
+	r.Cassette.Add(Interaction{
+		Request: Request{
+			Method:      req.Method,
+			Path:        req.URL.Path,
+			Query:       req.URL.RawQuery,
+			ContentType: req.Header.Get("Content-Type"),
+			Body:        string(reqBody),
+		},
+		Response: Response{
+			Status:      resp.StatusCode,
+			ContentType: resp.Header.Get("Content-Type"),
+			Body:        string(respBody),
+		},
+	})
+	return resp, nil
+}
+
+// Proxy records requests forwarded to an upstream server. It allows recording clients whose transport can't be
+// replaced, by pointing them at URL instead of the upstream.
+type Proxy struct {
+	// URL is where the proxy is served.
+	URL string
+
+	listener net.Listener
+}
+
+// NewProxy serves a proxy on a local port that forwards requests to upstream, recording them in c.
+func NewProxy(c *Cassette, upstream string) (*Proxy, error) {
+	target, err := url.Parse(upstream)
+	if err != nil {
+		return nil, fmt.Errorf("invalid upstream '%s': %v", upstream, err)
+	}
+
+	rp := httputil.NewSingleHostReverseProxy(target)
+	director := rp.Director
+	rp.Director = func(req *http.Request) {
+		director(req)
+		req.Host = target.Host
+
+		// recorded bodies must be readable
+		req.Header.Del("Accept-Encoding")
+	}
+	rp.Transport = &Recorder{Cassette: c}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("couldn't listen for proxy: %v", err)
+	}
+
+	go func() {
+		if err := http.Serve(l, rp); err != nil {
+			log.Printf("Recording proxy for '%s' stopped: %v", upstream, err)
+		}
+	}()
+
+	return &Proxy{
+		URL:      "http://" + l.Addr().String(),
+		listener: l,
+	}, nil
+}
+
+// Close stops the proxy.
+func (p *Proxy) Close() error {
+	return p.listener.Close()
+}
@@ -0,0 +1,172 @@
+// Package certs checks certificates and tokens clusters issue are valid, and rotated before they expire, so
+// regressions in how clusters manage certificates are caught before certificates run out.
+package certs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+const (
+	// NotBeforeAnnotation is when a certificate managed by an OpenShift operator became valid.
+	NotBeforeAnnotation = "auth.openshift.io/certificate-not-before"
+
+	// NotAfterAnnotation is when a certificate managed by an OpenShift operator expires.
+	NotAfterAnnotation = "auth.openshift.io/certificate-not-after"
+)
+
+// Validity is when a certificate is valid.
+type Validity struct {
+	NotBefore, NotAfter time.Time
+}
+
+// FromCert returns when cert is valid.
+func FromCert(cert *x509.Certificate) Validity {
+	return Validity{NotBefore: cert.NotBefore, NotAfter: cert.NotAfter}
+}
+
+// FromAnnotations returns the validity recorded in the annotations of a secret holding a certificate managed by an
+// OpenShift operator. It returns false if they don't record one.
+func FromAnnotations(annotations map[string]string) (v Validity, ok bool, err error) {
+	notBefore, hasBefore := annotations[NotBeforeAnnotation]
+	notAfter, hasAfter := annotations[NotAfterAnnotation]
+	if !hasBefore || !hasAfter {
+		return v, false, nil
+	}
+	if v.NotBefore, err = time.Parse(time.RFC3339, notBefore); err != nil {
+		return v, true, fmt.Errorf("invalid %s '%s': %v", NotBeforeAnnotation, notBefore, err)
+	}
+	if v.NotAfter, err = time.Parse(time.RFC3339, notAfter); err != nil {
+		return v, true, fmt.Errorf("invalid %s '%s': %v", NotAfterAnnotation, notAfter, err)
+	}
+	return v, true, nil
+}
+
+// Used returns the fraction of the lifetime of the certificate which has passed at now.
+func (v Validity) Used(now time.Time) float64 {
+	lifetime := v.NotAfter.Sub(v.NotBefore)
+	if lifetime <= 0 {
+		return 1
+	}
+	return float64(now.Sub(v.NotBefore)) / float64(lifetime)
+}
+
+// Problems returns why the certificate isn't fine at now: it isn't valid yet or has expired, has less than
+// minRemaining left, or has used more than maxUsed of its lifetime, meaning it wasn't rotated when it was due.
+func (v Validity) Problems(now time.Time, minRemaining time.Duration, maxUsed float64) (problems []string) {
+	switch remaining := v.NotAfter.Sub(now); {
+	case now.Before(v.NotBefore):
+		problems = append(problems, fmt.Sprintf("isn't valid until %s", v.NotBefore.UTC().Format(time.RFC3339)))
+	case remaining <= 0:
+		problems = append(problems, fmt.Sprintf("expired at %s", v.NotAfter.UTC().Format(time.RFC3339)))
+	case remaining < minRemaining:
+		problems = append(problems, fmt.Sprintf("expires in %v, less than %v", remaining.Round(time.Minute), minRemaining))
+	}
+	if used := v.Used(now); len(problems) == 0 && maxUsed > 0 && used > maxUsed {
+		problems = append(problems, fmt.Sprintf("has used %.0f%% of its lifetime without being rotated", used*100))
+	}
+	return
+}
+
+// ParsePEM returns the certificates in PEM encoded data, in order.
+func ParsePEM(data []byte) (certs []*x509.Certificate, err error) {
+	for {
+		var block *pem.Block
+		if block, data = pem.Decode(data); block == nil {
+			break
+		} else if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't parse certificate: %v", err)
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no certificates found")
+	}
+	return certs, nil
+}
+
+// Pool returns a pool of certs.
+func Pool(certs []*x509.Certificate) *x509.CertPool {
+	pool := x509.NewCertPool()
+	for _, cert := range certs {
+		pool.AddCert(cert)
+	}
+	return pool
+}
+
+// Served returns the certificates served at addr, a host and port, starting with the leaf. They aren't verified so
+// they can be checked even when they're invalid.
+func Served(addr string, timeout time.Duration) ([]*x509.Certificate, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: timeout}, "tcp", addr, &tls.Config{
+		ServerName:         host,
+		InsecureSkipVerify: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("couldn't connect to '%s': %v", addr, err)
+	}
+	defer conn.Close()
+	return conn.ConnectionState().PeerCertificates, nil
+}
+
+// tokenClaims are the claims of a service account token used to check its expiry.
+type tokenClaims struct {
+	Expiry int64 `json:"exp"`
+}
+
+// TokenExpiry returns when the JWT token expires, without verifying it. It returns the zero time if the token
+// doesn't expire.
+func TokenExpiry(token string) (time.Time, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, fmt.Errorf("token isn't a JWT")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("couldn't decode token claims: %v", err)
+	}
+	var claims tokenClaims
+	if err = json.Unmarshal(payload, &claims); err != nil {
+		return time.Time{}, fmt.Errorf("couldn't parse token claims: %v", err)
+	} else if claims.Expiry == 0 {
+		return time.Time{}, nil
+	}
+	return time.Unix(claims.Expiry, 0).UTC(), nil
+}
+
+// WithExpiry returns the JWT token with its expiry changed to exp and its signature kept, which must be rejected by
+// anything verifying it.
+func WithExpiry(token string, exp time.Time) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", fmt.Errorf("token isn't a JWT")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", fmt.Errorf("couldn't decode token claims: %v", err)
+	}
+	var claims map[string]interface{}
+	if err = json.Unmarshal(payload, &claims); err != nil {
+		return "", fmt.Errorf("couldn't parse token claims: %v", err)
+	}
+	claims["exp"] = exp.Unix()
+	if payload, err = json.Marshal(claims); err != nil {
+		return "", err
+	}
+	parts[1] = base64.RawURLEncoding.EncodeToString(payload)
+	return strings.Join(parts, "."), nil
+}
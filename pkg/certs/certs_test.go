@@ -0,0 +1,110 @@
+package certs
+
+import (
+	"encoding/base64"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProblems(t *testing.T) {
+	now := time.Date(2019, 8, 1, 0, 0, 0, 0, time.UTC)
+	v := Validity{NotBefore: now.Add(-80 * 24 * time.Hour), NotAfter: now.Add(10 * 24 * time.Hour)}
+
+	for _, tc := range []struct {
+		name         string
+		now          time.Time
+		minRemaining time.Duration
+		maxUsed      float64
+		problem      string
+	}{
+		{name: "fine", now: now, minRemaining: 7 * 24 * time.Hour},
+		{name: "soon", now: now, minRemaining: 30 * 24 * time.Hour, problem: "expires in 240h0m0s, less than 720h0m0s"},
+		{name: "not rotated", now: now, maxUsed: 0.8, problem: "has used 89% of its lifetime without being rotated"},
+		{name: "expired", now: v.NotAfter.Add(time.Second), maxUsed: 0.8, problem: "expired at 2019-08-11T00:00:00Z"},
+		{name: "early", now: v.NotBefore.Add(-time.Second), problem: "isn't valid until 2019-05-13T00:00:00Z"},
+	} {
+		problems := v.Problems(tc.now, tc.minRemaining, tc.maxUsed)
+		if tc.problem == "" && len(problems) != 0 {
+			t.Errorf("%s: expected no problems, got %v", tc.name, problems)
+		} else if tc.problem != "" && (len(problems) != 1 || problems[0] != tc.problem) {
+			t.Errorf("%s: expected '%s', got %v", tc.name, tc.problem, problems)
+		}
+	}
+}
+
+func TestFromAnnotations(t *testing.T) {
+	v, ok, err := FromAnnotations(map[string]string{
+		NotBeforeAnnotation: "2019-07-01T00:00:00Z",
+		NotAfterAnnotation:  "2019-07-31T00:00:00Z",
+	})
+	if !ok || err != nil || v.NotAfter.Sub(v.NotBefore) != 30*24*time.Hour {
+		t.Errorf("expected 30 day validity, got %+v, %t: %v", v, ok, err)
+	}
+	if used := v.Used(time.Date(2019, 7, 16, 0, 0, 0, 0, time.UTC)); used != 0.5 {
+		t.Errorf("expected half the lifetime used, got %v", used)
+	}
+
+	if _, ok, _ = FromAnnotations(map[string]string{NotAfterAnnotation: "2019-07-31T00:00:00Z"}); ok {
+		t.Error("expected no validity without both annotations")
+	}
+	if _, _, err = FromAnnotations(map[string]string{NotBeforeAnnotation: "yesterday", NotAfterAnnotation: "today"}); err == nil {
+		t.Error("expected invalid annotations to fail")
+	}
+}
+
+func TestServed(t *testing.T) {
+	srv := httptest.NewTLSServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	served, err := Served(strings.TrimPrefix(srv.URL, "https://"), 5*time.Second)
+	if err != nil {
+		t.Fatalf("failed to get served certificates: %v", err)
+	}
+	if len(served) == 0 || !served[0].Equal(srv.Certificate()) {
+		t.Fatalf("expected the server's certificate to be served, got %d certificates", len(served))
+	}
+	if problems := FromCert(served[0]).Problems(time.Now(), 0, 0); len(problems) != 0 {
+		t.Errorf("expected served certificate to be valid, got %v", problems)
+	}
+
+	data := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: []byte("skipped")})
+	data = append(data, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: served[0].Raw})...)
+	parsed, err := ParsePEM(data)
+	if err != nil || len(parsed) != 1 || !parsed[0].Equal(served[0]) {
+		t.Errorf("expected to parse the served certificate, got %d: %v", len(parsed), err)
+	}
+	if _, err = ParsePEM([]byte("not PEM")); err == nil {
+		t.Error("expected data without certificates to fail")
+	}
+}
+
+func TestTokenExpiry(t *testing.T) {
+	enc := base64.RawURLEncoding.EncodeToString
+	token := enc([]byte(`{"alg":"RS256"}`)) + "." + enc([]byte(`{"exp":1564617600,"sub":"system:serviceaccount:a:b"}`)) +
+		".c2lnbmF0dXJl"
+
+	exp, err := TokenExpiry(token)
+	if err != nil || !exp.Equal(time.Date(2019, 8, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected expiry %v: %v", exp, err)
+	}
+
+	later := exp.Add(365 * 24 * time.Hour)
+	extended, err := WithExpiry(token, later)
+	if err != nil {
+		t.Fatalf("failed to change expiry: %v", err)
+	}
+	if exp, err = TokenExpiry(extended); err != nil || !exp.Equal(later) {
+		t.Errorf("expected expiry changed to %v, got %v: %v", later, exp, err)
+	}
+	if !strings.HasSuffix(extended, ".c2lnbmF0dXJl") || strings.Contains(extended, "system:serviceaccount") {
+		t.Errorf("expected signature kept and claims encoded, got '%s'", extended)
+	}
+
+	if _, err = TokenExpiry("opaque-token"); err == nil {
+		t.Error("expected token which isn't a JWT to fail")
+	}
+}
@@ -0,0 +1,253 @@
+// Package clusterspec compares what OCM expects a cluster to be against what the cluster reports at checkpoints of a
+// run, such as its version, nodes, network, and add-ons, finding where the management plane and the cluster disagree.
+package clusterspec
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/test-infra/testgrid/metadata/junit"
+
+	"github.com/openshift/osde2e/pkg/fingerprint"
+	"github.com/openshift/osde2e/pkg/junitprops"
+	"github.com/openshift/osde2e/pkg/osd"
+)
+
+const (
+	// SuiteName is the JUnit suite reporting whether OCM and the cluster agree.
+	SuiteName = "OCM cluster spec"
+
+	// File is the name reports are written as.
+	File = "spec-drift.json"
+
+	// csvSucceeded is the phase of ClusterServiceVersions whose operators are installed.
+	csvSucceeded = "Succeeded"
+)
+
+// Finding is a property of the cluster which isn't what OCM expects.
+type Finding struct {
+	// Field is the property, such as 'nodes.compute'.
+	Field    string `json:"field"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s is '%s' in the cluster but '%s' in OCM", f.Field, f.Actual, f.Expected)
+}
+
+// Compare returns the properties of the cluster fingerprinted in f which aren't what spec expects, sorted by field.
+// Add-ons OCM reports as ready must have a succeeded ClusterServiceVersion in their namespace, which is given by csvs.
+func Compare(spec *osd.ClusterSpec, f *fingerprint.Fingerprint, csvs map[string]string) (findings []Finding) {
+	check := func(field, expected, actual string) {
+		if expected != "" && expected != actual {
+			findings = append(findings, Finding{Field: field, Expected: expected, Actual: actual})
+		}
+	}
+
+	check("version", spec.Version, f.Version)
+	check("region", spec.Region.ID, f.Region)
+	check("cloud", spec.Cloud.ID, f.Cloud)
+
+	// nodes of each role, where compute nodes are those of every machine pool
+	roles := map[string]int{}
+	var types []string
+	for _, n := range f.Nodes {
+		role := nodeRole(n.Roles)
+		roles[role]++
+		if role == "compute" && !contains(types, n.InstanceType) {
+			types = append(types, n.InstanceType)
+		}
+	}
+	check("nodes.master", strconv.Itoa(spec.Nodes.Master), strconv.Itoa(roles["master"]))
+
+	// hosted control planes don't run on the cluster's own nodes
+	check("hypershift.enabled", strconv.FormatBool(spec.Hypershift.Enabled), strconv.FormatBool(roles["master"] == 0))
+	check("nodes.infra", strconv.Itoa(spec.Nodes.Infra), strconv.Itoa(roles["infra"]))
+	if spec.Nodes.Autoscale == nil {
+		compute := spec.Nodes.Compute
+		for _, p := range spec.MachinePools {
+			compute += p.Replicas
+		}
+		check("nodes.compute", strconv.Itoa(compute), strconv.Itoa(roles["compute"]))
+	} else if n := roles["compute"]; n < spec.Nodes.Autoscale.Min || n > spec.Nodes.Autoscale.Max {
+		findings = append(findings, Finding{
+			Field:    "nodes.compute",
+			Expected: fmt.Sprintf("%d-%d", spec.Nodes.Autoscale.Min, spec.Nodes.Autoscale.Max),
+			Actual:   strconv.Itoa(n),
+		})
+	}
+
+	// compute nodes should only be of the instance types of machine pools
+	if spec.Nodes.ComputeType.ID != "" {
+		expectedTypes := []string{spec.Nodes.ComputeType.ID}
+		for _, p := range spec.MachinePools {
+			expectedTypes = append(expectedTypes, p.InstanceType)
+		}
+		sort.Strings(expectedTypes)
+		for _, t := range types {
+			if !contains(expectedTypes, t) {
+				check("nodes.compute_machine_type", strings.Join(expectedTypes, ","), t)
+			}
+		}
+	}
+
+	if len(spec.Nodes.AvailabilityZones) != 0 {
+		zones := append([]string{}, spec.Nodes.AvailabilityZones...)
+		sort.Strings(zones)
+		actual := append([]string{}, f.Zones...)
+		sort.Strings(actual)
+		check("nodes.availability_zones", strings.Join(zones, ","), strings.Join(actual, ","))
+	}
+	if spec.MultiAZ && len(f.Zones) < 2 {
+		check("multi_az", "true", "false")
+	}
+
+	check("network.type", spec.Network.Type, f.Network.Type)
+	check("network.service_cidr", spec.Network.ServiceCIDR, strings.Join(f.Network.ServiceNetwork, ","))
+	check("network.pod_cidr", spec.Network.PodCIDR, strings.Join(f.Network.ClusterNetwork, ","))
+
+	for id, addon := range spec.Addons {
+		if addon.State != osd.AddonReady {
+			continue
+		}
+		ns := spec.AddonNamespaces[id]
+		if phase, ok := csvs[ns]; !ok {
+			check("addons."+id, "installed in "+ns, "missing")
+		} else {
+			check("addons."+id, csvSucceeded, phase)
+		}
+	}
+
+	sort.Slice(findings, func(i, j int) bool { return findings[i].Field < findings[j].Field })
+	return
+}
+
+// nodeRole returns whether a node with roles is a master, infra, or compute node.
+func nodeRole(roles []string) string {
+	for _, r := range roles {
+		if r == "master" || r == "infra" {
+			return r
+		}
+	}
+	return "compute"
+}
+
+func contains(strs []string, s string) bool {
+	for _, str := range strs {
+		if str == s {
+			return true
+		}
+	}
+	return false
+}
+
+// CSVPhases returns the phase of the ClusterServiceVersions of each namespace, which is Succeeded if any succeeded.
+// CSVs copied into other namespaces by OLM are left out.
+func CSVPhases(csvs []unstructured.Unstructured) map[string]string {
+	phases := map[string]string{}
+	for _, csv := range csvs {
+		if _, copied := csv.GetLabels()["olm.copiedFrom"]; copied {
+			continue
+		}
+		if phases[csv.GetNamespace()] != csvSucceeded {
+			phases[csv.GetNamespace()], _, _ = unstructured.NestedString(csv.Object, "status", "phase")
+		}
+	}
+	return phases
+}
+
+// Checkpoint is a point in the run where the cluster was compared to OCM.
+type Checkpoint struct {
+	Name     string    `json:"name"`
+	Time     time.Time `json:"time"`
+	Findings []Finding `json:"findings"`
+
+	// Error is why the comparison couldn't be made.
+	Error string `json:"error,omitempty"`
+}
+
+// Report is the comparisons of a run. Its zero value is ready to use.
+type Report struct {
+	mu          sync.Mutex
+	Checkpoints []Checkpoint `json:"checkpoints"`
+}
+
+// Add records the findings of comparing the cluster to OCM at checkpoint, or why they couldn't be compared.
+func (r *Report) Add(checkpoint string, findings []Finding, err error) {
+	c := Checkpoint{
+		Name:     checkpoint,
+		Time:     time.Now().UTC(),
+		Findings: findings,
+	}
+	if c.Findings == nil {
+		c.Findings = []Finding{}
+	}
+	if err != nil {
+		c.Error = err.Error()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Checkpoints = append(r.Checkpoints, c)
+}
+
+// Write stores the report as File in dir.
+func (r *Report) Write(dir string) error {
+	r.mu.Lock()
+	data, err := json.MarshalIndent(r, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("couldn't encode cluster spec report: %v", err)
+	}
+
+	os.MkdirAll(dir, os.ModePerm)
+	filename := filepath.Join(dir, File)
+	if err = ioutil.WriteFile(filename, data, os.ModePerm); err != nil {
+		return fmt.Errorf("couldn't write cluster spec report to '%s': %v", filename, err)
+	}
+	return nil
+}
+
+// WriteJUnit records a testcase for each checkpoint in dir, failing those where the cluster and OCM disagreed.
+// Checkpoints where they couldn't be compared are skipped.
+func (r *Report) WriteJUnit(dir, suffix string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	suite := junit.Suite{
+		Name:  SuiteName,
+		Tests: len(r.Checkpoints),
+	}
+	for _, c := range r.Checkpoints {
+		result := junit.Result{
+			Name:      fmt.Sprintf("[spec] cluster should match OCM after %s", c.Name),
+			ClassName: SuiteName,
+		}
+		if c.Error != "" {
+			msg := fmt.Sprintf("couldn't compare cluster to OCM: %s", c.Error)
+			result.Skipped = &msg
+		} else if len(c.Findings) != 0 {
+			msg := fmt.Sprintf("%d properties of the cluster disagree with OCM after %s", len(c.Findings), c.Name)
+			lines := make([]string, 0, len(c.Findings))
+			for _, f := range c.Findings {
+				lines = append(lines, f.String())
+			}
+			output := strings.Join(lines, "\n")
+			result.Failure, result.Output = &msg, &output
+			suite.Failures++
+		}
+		suite.Results = append(suite.Results, result)
+	}
+
+	return junitprops.WriteSuite(dir, "cluster_spec", suffix, suite)
+}
@@ -0,0 +1,189 @@
+package clusterspec
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/test-infra/testgrid/metadata/junit"
+
+	"github.com/openshift/osde2e/pkg/fingerprint"
+	"github.com/openshift/osde2e/pkg/osd"
+)
+
+func testSpec() *osd.ClusterSpec {
+	return &osd.ClusterSpec{
+		Version: "4.3.1",
+		MultiAZ: true,
+		Region:  osd.Ref{ID: "us-east-1"},
+		Cloud:   osd.Ref{ID: "aws"},
+		Nodes: osd.SpecNodes{
+			Master:            3,
+			Infra:             1,
+			Compute:           2,
+			ComputeType:       osd.Ref{ID: "m5.xlarge"},
+			AvailabilityZones: []string{"us-east-1b", "us-east-1a"},
+		},
+		Network: osd.SpecNetwork{
+			Type:        "OpenShiftSDN",
+			ServiceCIDR: "172.30.0.0/16",
+			PodCIDR:     "10.128.0.0/14",
+		},
+		Addons: map[string]osd.AddonSnapshot{
+			"descheduler": {State: osd.AddonReady},
+			"logging":     {State: osd.AddonReady},
+			"pending":     {State: "installing"},
+		},
+		MachinePools: map[string]osd.MachinePool{
+			"memory": {ID: "memory", InstanceType: "r5.xlarge", Replicas: 1},
+		},
+		AddonNamespaces: map[string]string{
+			"descheduler": "openshift-kube-descheduler-operator",
+			"logging":     "openshift-logging",
+			"pending":     "pending",
+		},
+	}
+}
+
+func testFingerprint() *fingerprint.Fingerprint {
+	return &fingerprint.Fingerprint{
+		Version: "4.3.1",
+		Cloud:   "aws",
+		Region:  "us-east-1",
+		Zones:   []string{"us-east-1a", "us-east-1b"},
+		Nodes: []fingerprint.Node{
+			{Name: "m0", Roles: []string{"master"}},
+			{Name: "m1", Roles: []string{"master"}},
+			{Name: "m2", Roles: []string{"master"}},
+			{Name: "i0", Roles: []string{"infra", "worker"}},
+			{Name: "w0", Roles: []string{"worker"}, InstanceType: "m5.xlarge"},
+			{Name: "w1", Roles: []string{"worker"}, InstanceType: "m5.xlarge"},
+			{Name: "w2", Roles: []string{"worker"}, InstanceType: "r5.xlarge"},
+		},
+		Network: fingerprint.Network{
+			Type:           "OpenShiftSDN",
+			ClusterNetwork: []string{"10.128.0.0/14"},
+			ServiceNetwork: []string{"172.30.0.0/16"},
+		},
+	}
+}
+
+var testCSVs = map[string]string{
+	"openshift-kube-descheduler-operator": "Succeeded",
+	"openshift-logging":                   "Succeeded",
+}
+
+func TestCompare(t *testing.T) {
+	if findings := Compare(testSpec(), testFingerprint(), testCSVs); len(findings) != 0 {
+		t.Errorf("expected cluster to match spec, got: %v", findings)
+	}
+
+	f := testFingerprint()
+	f.Version = "4.3.2"
+	f.Zones = []string{"us-east-1a"}
+	f.Nodes = append(f.Nodes[:5], fingerprint.Node{Name: "w3", Roles: []string{"worker"}, InstanceType: "c5.xlarge"})
+	f.Network.ServiceNetwork = []string{"172.31.0.0/16"}
+	csvs := map[string]string{"openshift-logging": "Failed"}
+
+	expected := []Finding{
+		{Field: "addons.descheduler", Expected: "installed in openshift-kube-descheduler-operator", Actual: "missing"},
+		{Field: "addons.logging", Expected: "Succeeded", Actual: "Failed"},
+		{Field: "multi_az", Expected: "true", Actual: "false"},
+		{Field: "network.service_cidr", Expected: "172.30.0.0/16", Actual: "172.31.0.0/16"},
+		{Field: "nodes.availability_zones", Expected: "us-east-1a,us-east-1b", Actual: "us-east-1a"},
+		{Field: "nodes.compute", Expected: "3", Actual: "2"},
+		{Field: "nodes.compute_machine_type", Expected: "m5.xlarge,r5.xlarge", Actual: "c5.xlarge"},
+		{Field: "version", Expected: "4.3.1", Actual: "4.3.2"},
+	}
+	if findings := Compare(testSpec(), f, csvs); !reflect.DeepEqual(findings, expected) {
+		t.Errorf("expected findings %v, got %v", expected, findings)
+	}
+
+	// autoscaled clusters only need enough compute nodes
+	spec := testSpec()
+	spec.Nodes.Autoscale = &osd.SpecAutoscale{Min: 4, Max: 6}
+	expected = []Finding{{Field: "nodes.compute", Expected: "4-6", Actual: "3"}}
+	if findings := Compare(spec, testFingerprint(), testCSVs); !reflect.DeepEqual(findings, expected) {
+		t.Errorf("expected findings %v, got %v", expected, findings)
+	}
+
+	// hosted control planes have no master nodes
+	spec = testSpec()
+	spec.Nodes.Master, spec.Hypershift.Enabled = 0, true
+	expected = []Finding{
+		{Field: "hypershift.enabled", Expected: "true", Actual: "false"},
+		{Field: "nodes.master", Expected: "0", Actual: "3"},
+	}
+	if findings := Compare(spec, testFingerprint(), testCSVs); !reflect.DeepEqual(findings, expected) {
+		t.Errorf("expected findings %v, got %v", expected, findings)
+	}
+}
+
+func TestCSVPhases(t *testing.T) {
+	csv := func(ns, phase string, copied bool) unstructured.Unstructured {
+		u := unstructured.Unstructured{Object: map[string]interface{}{
+			"status": map[string]interface{}{"phase": phase},
+		}}
+		u.SetNamespace(ns)
+		if copied {
+			u.SetLabels(map[string]string{"olm.copiedFrom": "openshift-operators"})
+		}
+		return u
+	}
+
+	phases := CSVPhases([]unstructured.Unstructured{
+		csv("openshift-logging", "Succeeded", false),
+		csv("openshift-logging", "Replacing", false),
+		csv("openshift-ingress", "Succeeded", true),
+		csv("openshift-monitoring", "Failed", false),
+	})
+	expected := map[string]string{"openshift-logging": "Succeeded", "openshift-monitoring": "Failed"}
+	if !reflect.DeepEqual(phases, expected) {
+		t.Errorf("expected phases %v, got %v", expected, phases)
+	}
+}
+
+func TestReport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "clusterspec")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	r := new(Report)
+	r.Add("install", nil, nil)
+	r.Add("upgrade", []Finding{{Field: "version", Expected: "4.3.1", Actual: "4.3.2"}}, nil)
+	r.Add("tests", nil, errors.New("OCM unavailable"))
+
+	if err = r.Write(dir); err != nil {
+		t.Fatalf("failed to write report: %v", err)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dir, File))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var read Report
+	if err = json.Unmarshal(data, &read); err != nil || len(read.Checkpoints) != 3 {
+		t.Errorf("expected report with 3 checkpoints, got %+v: %v", read.Checkpoints, err)
+	}
+
+	if err = r.WriteJUnit(dir, "test"); err != nil {
+		t.Fatalf("failed to write results: %v", err)
+	}
+	if data, err = ioutil.ReadFile(filepath.Join(dir, "junit_cluster_spec_test.xml")); err != nil {
+		t.Fatal(err)
+	}
+	var suite junit.Suite
+	if err = xml.Unmarshal(data, &suite); err != nil {
+		t.Fatal(err)
+	}
+	if suite.Tests != 3 || suite.Failures != 1 || suite.Results[2].Skipped == nil {
+		t.Errorf("expected the upgrade to fail and tests to be skipped, got %+v", suite)
+	}
+}
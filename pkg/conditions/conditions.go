@@ -0,0 +1,350 @@
+// Package conditions records how the conditions of ClusterOperators change during a run. Operators which become
+// Degraded or Progressing more often than allowed fail, even if they're Available at the end, so brief degradations
+// between health checks are noticed.
+package conditions
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	configclient "github.com/openshift/client-go/config/clientset/versioned"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/test-infra/testgrid/metadata/junit"
+
+	"github.com/openshift/osde2e/pkg/junitprops"
+	"github.com/openshift/osde2e/pkg/timeline"
+)
+
+const (
+	// SuiteName is the JUnit suite containing a testcase for each ClusterOperator observed.
+	SuiteName = "ClusterOperator conditions"
+
+	// HistoryFile is the name of the condition history written to the report directory.
+	HistoryFile = "operator-conditions.json"
+)
+
+// Types are the conditions of ClusterOperators recorded.
+var Types = []configv1.ClusterStatusConditionType{
+	configv1.OperatorAvailable,
+	configv1.OperatorDegraded,
+	configv1.OperatorProgressing,
+}
+
+// Transition is a condition of a ClusterOperator changing status.
+type Transition struct {
+	Operator  string                              `json:"operator"`
+	Condition configv1.ClusterStatusConditionType `json:"condition"`
+	From      configv1.ConditionStatus            `json:"from"`
+	To        configv1.ConditionStatus            `json:"to"`
+	Time      time.Time                           `json:"time"`
+	Reason    string                              `json:"reason,omitempty"`
+	Message   string                              `json:"message,omitempty"`
+}
+
+// Became returns true if the transition made the condition true.
+func (t Transition) Became(condition configv1.ClusterStatusConditionType) bool {
+	return t.Condition == condition && t.To == configv1.ConditionTrue
+}
+
+// String describes the transition and why it happened.
+func (t Transition) String() string {
+	s := fmt.Sprintf("%s %s %s -> %s at %s", t.Operator, t.Condition, t.From, t.To, t.Time.UTC().Format(time.RFC3339))
+	if t.Reason != "" {
+		s += fmt.Sprintf(" (%s: %s)", t.Reason, t.Message)
+	}
+	return s
+}
+
+// ListFunc returns the ClusterOperators of the cluster.
+type ListFunc func() ([]configv1.ClusterOperator, error)
+
+// ClusterOperators returns a ListFunc for the cluster accessed with kubeconfig.
+func ClusterOperators(kubeconfig []byte) (ListFunc, error) {
+	restConfig, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't configure client: %v", err)
+	}
+	cfg, err := configclient.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't create config client: %v", err)
+	}
+
+	return func() ([]configv1.ClusterOperator, error) {
+		list, err := cfg.ConfigV1().ClusterOperators().List(metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("couldn't list ClusterOperators: %v", err)
+		}
+		return list.Items, nil
+	}, nil
+}
+
+// Current records the conditions of this run. It is nil when they aren't recorded.
+var Current *Recorder
+
+// Recorder records the transitions of the conditions of ClusterOperators. A nil Recorder records nothing.
+type Recorder struct {
+	// MaxDegraded and MaxProgressing are how many times each operator may become Degraded or Progressing.
+	MaxDegraded, MaxProgressing int
+
+	mu          sync.Mutex
+	last        map[string]map[configv1.ClusterStatusConditionType]configv1.ClusterOperatorStatusCondition
+	transitions []Transition
+	excluded    []window
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// New returns a Recorder allowing each operator to become Degraded maxDegraded times and Progressing maxProgressing
+// times.
+func New(maxDegraded, maxProgressing int) *Recorder {
+	return &Recorder{
+		MaxDegraded:    maxDegraded,
+		MaxProgressing: maxProgressing,
+		last:           map[string]map[configv1.ClusterStatusConditionType]configv1.ClusterOperatorStatusCondition{},
+	}
+}
+
+// Start observes the operators returned by list every interval until stopped.
+func (r *Recorder) Start(list ListFunc, interval time.Duration) {
+	r.stop, r.done = make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(r.done)
+		wait.Until(func() {
+			operators, err := list()
+			if err != nil {
+				log.Printf("Failed to record ClusterOperator conditions: %v", err)
+				return
+			}
+			r.Observe(operators, time.Now())
+		}, interval, r.stop)
+	}()
+}
+
+// Stop stops observing operators, returning the transitions recorded.
+func (r *Recorder) Stop() []Transition {
+	if r == nil {
+		return nil
+	}
+	if r.stop != nil {
+		close(r.stop)
+		<-r.done
+		r.stop = nil
+	}
+	return r.Transitions()
+}
+
+// Observe records how the conditions of operators changed since they were last observed, at now if they don't say
+// when. The first observation of an operator is what later ones are compared to. A condition whose status is the same
+// but which transitioned since it was last observed changed and changed back in between, which is two transitions.
+func (r *Recorder) Observe(operators []configv1.ClusterOperator, now time.Time) (transitions []Transition) {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, co := range operators {
+		last, seen := r.last[co.Name]
+		if !seen {
+			last = map[configv1.ClusterStatusConditionType]configv1.ClusterOperatorStatusCondition{}
+			r.last[co.Name] = last
+		}
+
+		for _, cond := range co.Status.Conditions {
+			if !recorded(cond.Type) {
+				continue
+			}
+			prev, ok := last[cond.Type]
+			last[cond.Type] = cond
+			if !ok {
+				if seen {
+					prev.Status = configv1.ConditionUnknown
+				} else {
+					continue
+				}
+			}
+
+			at := now
+			if !cond.LastTransitionTime.IsZero() {
+				at = cond.LastTransitionTime.Time
+			}
+			t := Transition{Operator: co.Name, Condition: cond.Type, From: prev.Status, To: cond.Status, Time: at,
+				Reason: cond.Reason, Message: cond.Message}
+			if prev.Status != cond.Status {
+				transitions = append(transitions, t)
+			} else if cond.LastTransitionTime.After(prev.LastTransitionTime.Time) {
+				away := t
+				away.From, away.To, away.Reason, away.Message = prev.Status, opposite(prev.Status), "", ""
+				t.From = away.To
+				transitions = append(transitions, away, t)
+			}
+		}
+	}
+
+	for _, t := range transitions {
+		log.Printf("ClusterOperator condition changed: %s", t)
+		timeline.Current.Add(timeline.Entry{
+			Source: timeline.SourceHealth,
+			Name:   fmt.Sprintf("operator %s %s=%s", t.Operator, t.Condition, t.To),
+			Start:  t.Time,
+			End:    t.Time,
+			Detail: t.Message,
+			Failed: t.Became(configv1.OperatorDegraded) || (t.Condition == configv1.OperatorAvailable && t.To != configv1.ConditionTrue),
+		})
+	}
+	r.transitions = append(r.transitions, transitions...)
+	return transitions
+}
+
+// window is a period of time.
+type window struct {
+	start, end time.Time
+}
+
+// Exclude stops transitions between start and end from counting towards churn, such as while the cluster is upgraded
+// and operators are expected to progress and briefly degrade. They're still recorded.
+func (r *Recorder) Exclude(start, end time.Time) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.excluded = append(r.excluded, window{start: start, end: end})
+}
+
+// counted returns true if t counts towards churn.
+func (r *Recorder) counted(t Transition) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, w := range r.excluded {
+		if !t.Time.Before(w.start) && !t.Time.After(w.end) {
+			return false
+		}
+	}
+	return true
+}
+
+// Transitions returns the transitions recorded so far.
+func (r *Recorder) Transitions() []Transition {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Transition(nil), r.transitions...)
+}
+
+// Operators returns the names of the operators observed in order.
+func (r *Recorder) Operators() (names []string) {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for name := range r.last {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return
+}
+
+// Churn returns why operator changed too often outside of excluded windows, which is empty if it didn't.
+func (r *Recorder) Churn(operator string) (problems []string) {
+	if r == nil {
+		return nil
+	}
+	var degraded, progressing int
+	for _, t := range r.Transitions() {
+		if t.Operator != operator || !r.counted(t) {
+			continue
+		}
+		if t.Became(configv1.OperatorDegraded) {
+			degraded++
+		} else if t.Became(configv1.OperatorProgressing) {
+			progressing++
+		}
+	}
+	if degraded > r.MaxDegraded {
+		problems = append(problems, fmt.Sprintf("became Degraded %d times, more than the %d allowed", degraded, r.MaxDegraded))
+	}
+	if progressing > r.MaxProgressing {
+		problems = append(problems, fmt.Sprintf("became Progressing %d times, more than the %d allowed", progressing,
+			r.MaxProgressing))
+	}
+	return
+}
+
+// Write stores the transitions recorded in dir as JSON.
+func (r *Recorder) Write(dir string) error {
+	transitions := r.Transitions()
+	if transitions == nil {
+		transitions = []Transition{}
+	}
+	data, err := json.MarshalIndent(transitions, "", "  ")
+	if err != nil {
+		return fmt.Errorf("couldn't encode ClusterOperator conditions: %v", err)
+	}
+	if err = ioutil.WriteFile(filepath.Join(dir, HistoryFile), data, os.ModePerm); err != nil {
+		return fmt.Errorf("couldn't write ClusterOperator conditions: %v", err)
+	}
+	return nil
+}
+
+// WriteJUnit records a testcase in dir for each operator observed, failing if its conditions changed too often.
+func (r *Recorder) WriteJUnit(dir, suffix string) error {
+	operators := r.Operators()
+	suite := junit.Suite{
+		Name:  SuiteName,
+		Tests: len(operators),
+	}
+	transitions := r.Transitions()
+	for _, operator := range operators {
+		result := junit.Result{
+			Name:      fmt.Sprintf("[operators] %s should not repeatedly become Degraded or Progressing", operator),
+			ClassName: SuiteName,
+		}
+		if problems := r.Churn(operator); len(problems) != 0 {
+			var history []string
+			for _, t := range transitions {
+				if t.Operator == operator {
+					history = append(history, t.String())
+				}
+			}
+			msg := fmt.Sprintf("%s %s:\n%s", operator, strings.Join(problems, " and "), strings.Join(history, "\n"))
+			result.Failure = &msg
+			suite.Failures++
+		}
+		suite.Results = append(suite.Results, result)
+	}
+
+	return junitprops.WriteSuite(dir, "conditions", suffix, suite)
+}
+
+// recorded returns true if conditions of type t are recorded.
+func recorded(t configv1.ClusterStatusConditionType) bool {
+	for _, recorded := range Types {
+		if t == recorded {
+			return true
+		}
+	}
+	return false
+}
+
+// opposite returns the status a condition of status s changed to when it changed back to s.
+func opposite(s configv1.ConditionStatus) configv1.ConditionStatus {
+	if s == configv1.ConditionTrue {
+		return configv1.ConditionFalse
+	}
+	return configv1.ConditionTrue
+}
@@ -0,0 +1,138 @@
+package conditions
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/test-infra/testgrid/metadata/junit"
+)
+
+var start = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func operator(name string, available, degraded, progressing configv1.ConditionStatus) configv1.ClusterOperator {
+	co := configv1.ClusterOperator{}
+	co.Name = name
+	co.Status.Conditions = []configv1.ClusterOperatorStatusCondition{
+		{Type: configv1.OperatorAvailable, Status: available},
+		{Type: configv1.OperatorDegraded, Status: degraded},
+		{Type: configv1.OperatorProgressing, Status: progressing},
+	}
+	return co
+}
+
+// transitioned sets when conditions of co of types last transitioned.
+func transitioned(co configv1.ClusterOperator, at time.Duration, types ...configv1.ClusterStatusConditionType) configv1.ClusterOperator {
+	for i, cond := range co.Status.Conditions {
+		for _, t := range types {
+			if cond.Type == t {
+				co.Status.Conditions[i].LastTransitionTime = metav1.NewTime(start.Add(at))
+			}
+		}
+	}
+	return co
+}
+
+func TestObserve(t *testing.T) {
+	const (
+		T = configv1.ConditionTrue
+		F = configv1.ConditionFalse
+	)
+	r := New(0, 1)
+	if transitions := r.Observe([]configv1.ClusterOperator{operator("dns", T, F, F)}, start); len(transitions) != 0 {
+		t.Errorf("expected first observation to be a baseline, got %v", transitions)
+	}
+
+	// the operator degraded and recovered between observations, which only shows in when its conditions changed
+	dns := transitioned(operator("dns", T, F, F), time.Minute, configv1.OperatorDegraded, configv1.OperatorProgressing)
+	transitions := r.Observe([]configv1.ClusterOperator{dns}, start.Add(2*time.Minute))
+	if len(transitions) != 4 || !transitions[0].Became(configv1.OperatorDegraded) || transitions[1].To != F ||
+		!transitions[2].Became(configv1.OperatorProgressing) {
+		t.Fatalf("expected Degraded and Progressing to flap, got %v", transitions)
+	}
+
+	progressing := transitioned(operator("dns", T, F, T), time.Minute, configv1.OperatorDegraded)
+	r.Observe([]configv1.ClusterOperator{transitioned(progressing, 3*time.Minute, configv1.OperatorProgressing)},
+		start.Add(3*time.Minute))
+	r.Observe([]configv1.ClusterOperator{operator("ingress", T, F, F)}, start.Add(3*time.Minute))
+
+	if problems := r.Churn("dns"); len(problems) != 2 || !strings.Contains(problems[0], "Degraded 1 times") ||
+		!strings.Contains(problems[1], "Progressing 2 times") {
+		t.Errorf("expected dns to churn, got %v", problems)
+	}
+	if problems := r.Churn("ingress"); len(problems) != 0 {
+		t.Errorf("expected ingress not to churn, got %v", problems)
+	}
+
+	// transitions while upgrading don't count
+	r.Exclude(start.Add(30*time.Second), start.Add(2*time.Minute))
+	if problems := r.Churn("dns"); len(problems) != 0 {
+		t.Errorf("expected dns flapping while upgrading not to churn, got %v", problems)
+	} else if len(r.Transitions()) != 5 {
+		t.Errorf("expected excluded transitions to still be recorded, got %v", r.Transitions())
+	}
+
+	var nilRecorder *Recorder
+	nilRecorder.Exclude(start, start)
+	nilRecorder.Observe([]configv1.ClusterOperator{dns}, start)
+	if len(nilRecorder.Stop()) != 0 || len(nilRecorder.Churn("dns")) != 0 {
+		t.Error("expected nil recorder to record nothing")
+	}
+}
+
+func TestWrite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "conditions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	r := New(0, 3)
+	r.Observe([]configv1.ClusterOperator{
+		operator("dns", configv1.ConditionTrue, configv1.ConditionFalse, configv1.ConditionFalse),
+		operator("ingress", configv1.ConditionTrue, configv1.ConditionFalse, configv1.ConditionFalse),
+	}, start)
+	degraded := transitioned(operator("ingress", configv1.ConditionTrue, configv1.ConditionTrue, configv1.ConditionFalse),
+		time.Minute, configv1.OperatorDegraded)
+	degraded.Status.Conditions[1].Reason, degraded.Status.Conditions[1].Message = "RouterDown", "router is down"
+	r.Observe([]configv1.ClusterOperator{degraded}, start.Add(time.Minute))
+
+	if err = r.WriteJUnit(dir, "abc"); err != nil {
+		t.Fatalf("failed writing results: %v", err)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dir, "junit_conditions_abc.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var suite junit.Suite
+	if err = xml.Unmarshal(data, &suite); err != nil {
+		t.Fatal(err)
+	}
+	if suite.Tests != 2 || suite.Failures != 1 || len(suite.Results) != 2 || suite.Results[0].Failure != nil {
+		t.Fatalf("expected ingress to fail, got %+v", suite)
+	}
+	if failure := suite.Results[1].Failure; failure == nil || !strings.Contains(*failure, "ingress Degraded False -> True") ||
+		!strings.Contains(*failure, "RouterDown: router is down") {
+		t.Errorf("expected failure to include history, got %v", failure)
+	}
+
+	if err = r.Write(dir); err != nil {
+		t.Fatalf("failed writing history: %v", err)
+	}
+	if data, err = ioutil.ReadFile(filepath.Join(dir, HistoryFile)); err != nil {
+		t.Fatal(err)
+	}
+	var history []Transition
+	if err = json.Unmarshal(data, &history); err != nil {
+		t.Fatal(err)
+	} else if len(history) != 1 || history[0].Operator != "ingress" || !history[0].Time.Equal(start.Add(time.Minute)) {
+		t.Errorf("expected history of ingress degrading, got %+v", history)
+	}
+}
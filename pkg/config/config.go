@@ -11,6 +11,34 @@ const (
 
 	// SectionTag is the Go struct tag containing the documentation section of the option.
 	SectionTag = "sect"
+
+	// DefaultTag is the Go struct tag containing the value used when the environment variable isn't set.
+	DefaultTag = "default"
+)
+
+// Providers which can be selected with Provider.
+const (
+	ProviderOSD     = "osd"
+	ProviderGeneric = "generic"
+	ProviderLocal   = "local"
+)
+
+// Architectures which can be selected with ComputeArchitecture.
+const (
+	ArchitectureAMD64 = "amd64"
+	ArchitectureARM64 = "arm64"
+
+	// ArchitectureMulti clusters run a multi-architecture release so nodes of any architecture can be added.
+	ArchitectureMulti = "multi"
+)
+
+// DefaultCloudProvider is the cloud OSD creates clusters in when CloudProvider isn't set.
+const DefaultCloudProvider = "aws"
+
+// Network plugins which can be selected with NetworkType.
+const (
+	NetworkOpenShiftSDN  = "OpenShiftSDN"
+	NetworkOVNKubernetes = "OVNKubernetes"
 )
 
 // Cfg is the configuration used for end to end testing.
@@ -21,12 +49,79 @@ type Config struct {
 	// ReportDir is the location JUnit XML results are written.
 	ReportDir string `env:"REPORT_DIR" sect:"tests"`
 
-	// Suffix is used at the end of test names to identify them.
+	// RerunFailed only runs the specs which failed in the last run reporting to ReportDir, on its cluster unless
+	// CLUSTER_ID is set. The cluster must have been kept, such as with TEARDOWN_POLICY.
+	RerunFailed bool `env:"RERUN_FAILED" sect:"tests"`
+
+	// ReportFormats are formats results are written in along with JUnit, as a comma separated list of allure and
+	// sonarqube.
+	ReportFormats []string `env:"REPORT_FORMATS" sect:"tests"`
+
+	// ArtifactBudgets limit the size of artifacts stored for each category, as a comma separated list of
+	// category=quantity. Categories are logs, must-gather, events, state, results, and credentials. Unlisted
+	// categories are unlimited.
+	ArtifactBudgets map[string]string `env:"ARTIFACT_BUDGETS" sect:"tests" default:"logs=100Mi,must-gather=500Mi,events=50Mi,state=100Mi"`
+
+	// ArtifactEncryptionKeys are PEM encoded RSA public keys encrypting artifacts which may contain secrets, by the
+	// OSD environment they're used for, as a comma separated list of environment=file such as 'prod=keys/prod.pem'.
+	// Artifacts are only encrypted if there's a key for OSD_ENV.
+	ArtifactEncryptionKeys map[string]string `env:"ARTIFACT_ENCRYPTION_KEYS" sect:"tests"`
+
+	// EncryptedArtifacts are the categories of artifacts encrypted when there's a key for OSD_ENV. Kubeconfigs of
+	// kept clusters are only stored as credentials when they're encrypted.
+	EncryptedArtifacts []string `env:"ENCRYPTED_ARTIFACTS" sect:"tests" default:"must-gather,credentials"`
+
+	// RedactPatterns are regular expressions of secrets masked in logs, artifacts, and JUnit results, along with the
+	// values of sensitive options and well-known secrets such as tokens and AWS keys.
+	RedactPatterns []string `env:"REDACT_PATTERNS" sect:"tests"`
+
+	// Suffix is used at the end of test names to identify them. By default it's made from JOB_ID, when the run
+	// started, and a random nonce, and it ends the names of clusters the run creates.
 	Suffix string `env:"SUFFIX" sect:"tests"`
 
+	// JobID identifies the CI job of the run, such as a Prow build ID. It's encoded in the names of clusters and
+	// namespaces the run creates so they can be traced back to it. Prow's BUILD_ID is used if it isn't set.
+	JobID string `env:"JOB_ID" sect:"tests"`
+
+	// RunSeed is the seed the random choices of a run are made from, such as its suffix, the names of test
+	// namespaces, the data of workloads, and the order of specs. A new seed is chosen if it's 0. The seed of each run
+	// is logged and recorded in its metadata so a failed run can be repeated with the same choices.
+	RunSeed int64 `env:"RUN_SEED" sect:"tests"`
+
+	// LeastPrivilege runs specs as a ServiceAccount which only administers their project and has the permissions their
+	// suite declares it needs, reporting requests RBAC denied as failures of the suite. It checks suites don't
+	// secretly depend on cluster-admin.
+	LeastPrivilege bool `env:"LEAST_PRIVILEGE" sect:"tests"`
+
+	// SuitePlugins is a comma separated list of plugin binaries providing additional test suites.
+	SuitePlugins []string `env:"SUITE_PLUGINS" sect:"tests"`
+
+	// MetricHooks is a comma separated list of commands computing metrics from the merged JUnit results and metadata
+	// of the run before they're reported to TestGrid. Files they write to OSDE2E_METRICS_DIR are uploaded with the
+	// results as 'metrics_<binary>-<n>_<file>', where n is the position of the command in the list.
+	MetricHooks []string `env:"METRIC_HOOKS" sect:"testgrid"`
+
+	// TestCasesDir is a directory of YAML test cases, such as testcases/, which are run as specs along with the
+	// built-in suites.
+	TestCasesDir string `env:"TEST_CASES_DIR" sect:"tests"`
+
+	// Phases is a comma separated list of the phases to run: install, upgrade, tests, and teardown. All are run if empty.
+	Phases []string `env:"PHASES" sect:"tests"`
+
+	// PhaseTimeouts limits how long each phase may run as a comma separated list of phase=duration. Requests, polling,
+	// and runner Pods still in progress when a phase runs out of time are stopped. Phases not listed aren't limited.
+	PhaseTimeouts map[string]string `env:"PHASE_TIMEOUTS" sect:"tests" default:"install=3h,upgrade=4h,tests=3h,teardown=1h"`
+
 	// UHCToken is used to authenticate with UHC.
 	UHCToken string `env:"UHC_TOKEN" sect:"required"`
 
+	// OCMClientID identifies the client credentials used to authenticate with FedRAMP environments, which don't accept
+	// UHC_TOKEN.
+	OCMClientID string `env:"OCM_CLIENT_ID" sect:"environment"`
+
+	// OCMClientSecret is the secret of the client credentials used to authenticate with FedRAMP environments.
+	OCMClientSecret string `env:"OCM_CLIENT_SECRET" sect:"environment"`
+
 	// ClusterID identifies the cluster. If set at start, an existing cluster is tested.
 	ClusterID string `env:"CLUSTER_ID" sect:"cluster"`
 
@@ -42,8 +137,22 @@ type Config struct {
 	// MinorTarget is the minor version to target. If specified, it is used in version selection.
 	MinorTarget int64 `env:"MINOR_TARGET" sect:"version"`
 
+	// VersionCache is a file caching the versions offered by OSD, so runs started soon after each other don't list
+	// them again. The user's cache directory is used if it's empty.
+	VersionCache string `env:"VERSION_CACHE" sect:"version"`
+
+	// VersionCacheTTL is how long cached versions are used before they're listed again. Versions aren't cached if it's 0.
+	VersionCacheTTL time.Duration `env:"VERSION_CACHE_TTL" sect:"version" default:"10m"`
+
+	// RefreshVersions lists versions from OSD even when they're cached, such as with the -refresh-versions flag.
+	RefreshVersions bool `env:"REFRESH_VERSIONS" sect:"version"`
+
 	// ClusterUpTimeout is how long to wait before failing a cluster launch.
-	ClusterUpTimeout time.Duration
+	// It should be longer than infra alerting rules thresholds, otherwise startup failures won't trigger alerts.
+	ClusterUpTimeout time.Duration `env:"CLUSTER_UP_TIMEOUT" sect:"cluster" default:"135m"`
+
+	// InstallHeartbeat is how often install progress is checked and logged while waiting for a cluster.
+	InstallHeartbeat time.Duration `env:"INSTALL_HEARTBEAT" sect:"cluster" default:"45s"`
 
 	// TestGridBucket is the Google Cloud Storage bucket where results are reported for TestGrid.
 	TestGridBucket string `env:"TESTGRID_BUCKET" sect:"testgrid"`
@@ -54,32 +163,347 @@ type Config struct {
 	// TestGridServiceAccount is a Base64 encoded Google Cloud Service Account used to access the TestGridBucket.
 	TestGridServiceAccount []byte `env:"TESTGRID_SERVICE_ACCOUNT" sect:"testgrid"`
 
-	// UseProd sends requests to production OSD.
-	//
-	// Deprecated: Use OSD_ENV=prod instead.
-	UseProd bool `env:"USE_PROD"`
+	// CloudProvider is the cloud clusters are created in, such as 'aws' or 'gcp'. OSD creates them in aws when it
+	// isn't set.
+	CloudProvider string `env:"CLOUD_PROVIDER" sect:"cluster"`
+
+	// Region is the cloud region clusters are created in.
+	Region string `env:"REGION" sect:"cluster" default:"us-east-1"`
+
+	// RegionRotation is the regions created clusters rotate through for each cloud provider, separated by '|', such as
+	// 'aws=us-east-1|us-west-2'. REGION is ignored when creating clusters on a cloud provider with a rotation.
+	RegionRotation map[string]string `env:"REGION_ROTATION" sect:"cluster"`
+
+	// RegionBlocklist is a file remembering the region each cloud provider used last and regions which are skipped
+	// because they recently failed to install a cluster for lack of capacity. It must be set when using REGION_ROTATION,
+	// and be shared by every run, such as on a shared volume.
+	RegionBlocklist string `env:"REGION_BLOCKLIST" sect:"cluster"`
+
+	// RegionBlockDuration is how long a region is skipped after it failed to install a cluster for lack of capacity.
+	RegionBlockDuration time.Duration `env:"REGION_BLOCK_DURATION" sect:"cluster" default:"6h"`
 
 	// MultiAZ deploys a cluster across multiple availability zones.
 	MultiAZ bool `env:"MULTI_AZ" sect:"cluster"`
 
+	// ConfigProfile is a YAML file of day-2 configuration applied to the cluster before testing, such as 'profiles/customer.yaml'.
+	ConfigProfile string `env:"CONFIG_PROFILE" sect:"cluster"`
+
+	// MachinePools is a YAML file of machine pools with labels and taints added to created clusters before testing,
+	// such as 'machinepools/infra.yaml'. Setup fails if their labels and taints don't propagate to their nodes.
+	MachinePools string `env:"MACHINE_POOLS" sect:"cluster"`
+
+	// AddonBundle is a YAML file of interdependent add-ons installed together before testing, such as
+	// 'addonbundles/managed-services.yaml'. Their integration is checked and their harnesses run with shared context.
+	AddonBundle string `env:"ADDON_BUNDLE" sect:"cluster"`
+
+	// Provider manages the cluster under test. 'osd' creates clusters using OSD. 'generic' tests any OpenShift
+	// cluster accessed with TEST_KUBECONFIG, skipping what needs OSD such as creating the cluster and its logs.
+	// 'local' tests a CRC or kind cluster for developing suites, using the kubeconfig of CRC or KUBECONFIG when
+	// TEST_KUBECONFIG isn't set and also skipping tests of components managed by OSD.
+	Provider string `env:"PROVIDER" sect:"cluster" default:"osd"`
+
+	// ProviderPlugin is a plugin binary used to create and manage clusters instead of OSD.
+	ProviderPlugin string `env:"PROVIDER_PLUGIN" sect:"cluster"`
+
+	// ComputeNodes is the number of compute nodes clusters are created with. The flavour's count is used if it's 0.
+	ComputeNodes int `env:"COMPUTE_NODES" sect:"cluster"`
+
+	// ComputeMachineType is the instance type of compute nodes. The flavour's type is used if it's empty.
+	ComputeMachineType string `env:"COMPUTE_MACHINE_TYPE" sect:"cluster"`
+
+	// ComputeArchitecture is the CPU architecture of compute nodes: amd64, arm64, or multi. Clusters which aren't
+	// amd64 are installed from multi-architecture releases.
+	ComputeArchitecture string `env:"COMPUTE_ARCHITECTURE" sect:"cluster" default:"amd64"`
+
+	// NetworkType is the network plugin clusters are installed with: OpenShiftSDN or OVNKubernetes. OSD's default is
+	// used if it's empty.
+	NetworkType string `env:"NETWORK_TYPE" sect:"cluster"`
+
+	// Hypershift creates clusters with hosted control planes, which run on a management cluster instead of the
+	// cluster's own nodes. Their health is checked through the HostedCluster on the management cluster.
+	Hypershift bool `env:"HYPERSHIFT" sect:"cluster"`
+
+	// ManagementKubeconfig is the path of a kubeconfig accessing the management cluster which hosts the control plane
+	// of a Hypershift cluster. It's looked up through OSD if it isn't set.
+	ManagementKubeconfig []byte `env:"MANAGEMENT_KUBECONFIG" sect:"cluster"`
+
+	// ClusterExpiry is how long after creation clusters are deleted by OSD if they aren't destroyed by osde2e.
+	ClusterExpiry time.Duration `env:"CLUSTER_EXPIRY" sect:"cluster" default:"8h"`
+
+	// ClusterProperties are set on created clusters as a comma separated list of key=value, such as to identify them.
+	ClusterProperties map[string]string `env:"CLUSTER_PROPERTIES" sect:"cluster"`
+
+	// ClusterTemplate is a YAML file of the full OSD cluster body created instead of one described by the other
+	// cluster options, such as 'clustertemplates/private.yaml'. It's a Go template filled with options by field name,
+	// such as {{.ClusterName}}, and {{.Expiration}}. Clusters are validated against the OSD API schema before creation.
+	ClusterTemplate string `env:"CLUSTER_TEMPLATE" sect:"cluster"`
+
+	// MaxComputeNodes is the most compute nodes a cluster may be created with unless OverrideGuardrails is set.
+	MaxComputeNodes int `env:"MAX_COMPUTE_NODES" sect:"cluster" default:"9"`
+
+	// MaxClusterExpiry is the longest ClusterExpiry allowed unless OverrideGuardrails is set.
+	MaxClusterExpiry time.Duration `env:"MAX_CLUSTER_EXPIRY" sect:"cluster" default:"24h"`
+
+	// AllowedMachineTypes is a comma separated list of the compute instance types allowed unless OverrideGuardrails
+	// is set. Any type is allowed if it's empty.
+	AllowedMachineTypes []string `env:"ALLOWED_MACHINE_TYPES" sect:"cluster" default:"m5.xlarge,m5.2xlarge,r5.xlarge,r5.2xlarge,c5.2xlarge,m6g.xlarge,m6g.2xlarge"`
+
+	// OverrideGuardrails creates clusters exceeding the size and expiry limits.
+	OverrideGuardrails bool `env:"OVERRIDE_GUARDRAILS" sect:"cluster"`
+
+	// TeardownPolicy decides whether the cluster is destroyed after testing: always-destroy, keep-on-failure when
+	// provisioning or tests failed, keep-on-test-failure when only tests failed, or never-destroy.
+	TeardownPolicy string `env:"TEARDOWN_POLICY" sect:"cluster" default:"always-destroy"`
+
+	// TeardownExpiry is how long clusters kept by each teardown policy live after the run, as a comma separated list
+	// of policy=duration. The expiry of kept clusters is left unchanged for policies without one, such as never-destroy
+	// unless it's given one.
+	TeardownExpiry map[string]string `env:"TEARDOWN_EXPIRY" sect:"cluster" default:"keep-on-failure=24h,keep-on-test-failure=24h"`
+
 	// NoDestroy leaves the cluster running after testing.
+	//
+	// Deprecated: Use TEARDOWN_POLICY=never-destroy instead.
 	NoDestroy bool `env:"NO_DESTROY" sect:"cluster"`
 
+	// Interactive pauses before teardown when setup or a test fails, printing how to access the cluster and waiting
+	// for input so it can be inspected. It can also be enabled with the -interactive flag.
+	Interactive bool `env:"INTERACTIVE" sect:"cluster"`
+
+	// InteractiveTimeout is how long to wait for input when paused by Interactive before tearing down anyway.
+	InteractiveTimeout time.Duration `env:"INTERACTIVE_TIMEOUT" sect:"cluster" default:"1h"`
+
+	// ServiceLogs writes what the run did to the cluster, such as installing add-ons and upgrading it, to the cluster's
+	// service log in OSD, so SREs coming across the cluster can see what was done to it.
+	ServiceLogs bool `env:"SERVICE_LOGS" sect:"cluster" default:"true"`
+
+	// SharedCluster tests an existing cluster shared with other runs, such as for cheap smoke tests of PRs. Each run
+	// is a tenant whose namespaces are prefixed with its random name and limited by SHARED_CLUSTER_QUOTA. Suites
+	// changing the whole cluster are skipped and the cluster is never destroyed.
+	SharedCluster bool `env:"SHARED_CLUSTER" sect:"cluster"`
+
+	// SharedClusterTenants is how many runs may test a shared cluster at once. Others wait for room.
+	SharedClusterTenants int `env:"SHARED_CLUSTER_TENANTS" sect:"cluster" default:"4"`
+
+	// SharedClusterQuota limits what the namespaces of each run on a shared cluster may use, as a comma separated list
+	// of resource=quantity.
+	SharedClusterQuota map[string]string `env:"SHARED_CLUSTER_QUOTA" sect:"cluster" default:"pods=20,requests.cpu=2,requests.memory=4Gi"`
+
+	// SharedClusterWait is how long to wait for room on a shared cluster before giving up.
+	SharedClusterWait time.Duration `env:"SHARED_CLUSTER_WAIT" sect:"cluster" default:"30m"`
+
 	// NoTestGrid disables reporting to TestGrid.
 	NoTestGrid bool `env:"NO_TESTGRID" sect:"testgrid"`
 
 	// Kubeconfig is used to access a cluster.
 	Kubeconfig []byte `env:"TEST_KUBECONFIG" sect:"cluster"`
 
-	// OSDEnv is the OpenShift Dedicated environment used to provision clusters.
+	// OSDEnv is the OpenShift Dedicated environment used to provision clusters: 'int', 'stage', 'prod', their FedRAMP
+	// counterparts such as 'fedramp-int', or the URL of an OCM gateway.
 	OSDEnv string `env:"OSD_ENV" sect:"environment"`
 
+	// OSDCassette is a file sanitized interactions with OSD are recorded to, for replay in tests of the osd package.
+	OSDCassette string `env:"OSD_CASSETTE" sect:"environment"`
+
 	// DebugOSD shows debug level messages when enabled.
 	DebugOSD bool `env:"DEBUG_OSD" sect:"environment"`
 
+	// OCMErrorBudget is the highest ratio of OCM API calls which may fail with network, throttling, or server errors
+	// before the run fails. It isn't checked when 0.
+	OCMErrorBudget float64 `env:"OCM_ERROR_BUDGET" sect:"environment"`
+
+	// ConfigVersion is the version of options the job was written for. Options of older versions are migrated to the
+	// current version when loaded, logging how to update the job.
+	ConfigVersion int `env:"CONFIG_VERSION" sect:"environment" default:"1"`
+
+	// StrictEnv fails loading configuration when environment variables starting with OSDE2E_ don't match an option.
+	StrictEnv bool `env:"STRICT_ENV" sect:"environment"`
+
 	// CleanRuns is the number of times the test-version is run before skipping.
 	CleanRuns int `env:"CLEAN_RUNS" sect:"tests"`
 
+	// HarnessRetries is how many times runner Pods are recreated after failing for infrastructure reasons, such as
+	// image pull backoff or node preemption, before their tests fail.
+	HarnessRetries int `env:"HARNESS_RETRIES" sect:"tests" default:"2"`
+
+	// HarnessRetryBackoff is how long to wait before recreating a runner Pod, doubling with each retry.
+	HarnessRetryBackoff time.Duration `env:"HARNESS_RETRY_BACKOFF" sect:"tests" default:"30s"`
+
+	// SpecTimeout is how long each test may run before its context is cancelled, stopping in-flight requests and polling.
+	SpecTimeout time.Duration `env:"SPEC_TIMEOUT" sect:"tests" default:"30m"`
+
+	// GroupBudgets limits how long each group of suites may run as a comma separated list of group=duration, such as
+	// 'storage=45m,operators=1h'. Groups are networking, storage, operators, security, and other. Once a group spends
+	// its budget its running test is stopped and the rest are skipped as over-budget. Groups not listed aren't limited.
+	GroupBudgets map[string]string `env:"GROUP_BUDGETS" sect:"tests"`
+
+	// ConsoleChecks enables checking the web console renders using a headless browser.
+	ConsoleChecks bool `env:"CONSOLE_CHECKS" sect:"tests"`
+
+	// ManagementChecks enables checking cluster autoscaler and descheduler settings made through the OSD API take
+	// effect. The cluster's settings are changed and restored afterward.
+	ManagementChecks bool `env:"MANAGEMENT_CHECKS" sect:"tests"`
+
+	// HibernationChecks enables checking the configuration of the cluster kept by OCM, such as its properties,
+	// expiry, add-ons, and machine pools, survives hibernating and resuming it. The cluster is unavailable for up to
+	// an hour while it's checked.
+	HibernationChecks bool `env:"HIBERNATION_CHECKS" sect:"tests"`
+
+	// MUOChecks enables checking the managed-upgrade-operator doesn't act on upgrade policies it shouldn't, such as
+	// those for unavailable versions or scheduled later. UpgradeConfigs created by the checks are removed afterward.
+	MUOChecks bool `env:"MUO_CHECKS" sect:"tests"`
+
+	// MUOUpgradeChecks also upgrades the cluster to its next available version through the managed-upgrade-operator,
+	// checking its pre-checks, capacity reservation, and schedule are honored. The upgrade disrupts other suites, so
+	// it's best focused on alone.
+	MUOUpgradeChecks bool `env:"MUO_UPGRADE_CHECKS" sect:"tests"`
+
+	// LoadTest enables a light load test of the API server and a sample application route, failing on gross latency
+	// or error regressions.
+	LoadTest bool `env:"LOAD_TEST" sect:"tests"`
+
+	// LoadTestRate is how many requests per second each load test target is sent.
+	LoadTestRate int `env:"LOAD_TEST_RATE" sect:"tests" default:"20"`
+
+	// LoadTestDuration is how long each load test target is sent requests.
+	LoadTestDuration time.Duration `env:"LOAD_TEST_DURATION" sect:"tests" default:"1m"`
+
+	// LoadTestMaxP99 is the highest 99th percentile latency of a load test target before it fails.
+	LoadTestMaxP99 time.Duration `env:"LOAD_TEST_MAX_P99" sect:"tests" default:"2s"`
+
+	// LoadTestMinSuccess is the lowest ratio of successful requests to a load test target before it fails.
+	LoadTestMinSuccess float64 `env:"LOAD_TEST_MIN_SUCCESS" sect:"tests" default:"0.99"`
+
+	// NetworkPerfTest enables measuring throughput and latency between pods, through services, and across
+	// availability zones with iperf3.
+	NetworkPerfTest bool `env:"NETWORK_PERF_TEST" sect:"tests"`
+
+	// NetworkPerfDuration is how long traffic is sent over each path measured.
+	NetworkPerfDuration time.Duration `env:"NETWORK_PERF_DURATION" sect:"tests" default:"30s"`
+
+	// NetworkPerfBuilds is the number of recent builds in TestGrid whose results are baselines for network performance.
+	NetworkPerfBuilds int `env:"NETWORK_PERF_BUILDS" sect:"tests" default:"20"`
+
+	// NetworkPerfMinSamples is the fewest previous results on the same cloud and instance type needed to compare to.
+	NetworkPerfMinSamples int `env:"NETWORK_PERF_MIN_SAMPLES" sect:"tests" default:"3"`
+
+	// NetworkPerfMaxRegression is the most percent throughput may drop, or latency rise, from its baseline before
+	// failing.
+	NetworkPerfMaxRegression float64 `env:"NETWORK_PERF_MAX_REGRESSION" sect:"tests" default:"25"`
+
+	// DNSLookups is how many lookups of a service are timed from a pod to measure DNS latency under light load.
+	DNSLookups int `env:"DNS_LOOKUPS" sect:"tests" default:"200"`
+
+	// DNSMaxP99 is the highest 99th percentile latency of DNS lookups from pods before they fail.
+	DNSMaxP99 time.Duration `env:"DNS_MAX_P99" sect:"tests" default:"200ms"`
+
+	// DNSExternalHost is a name outside the cluster pods are expected to resolve. External lookups aren't checked if
+	// it's empty, such as for clusters without egress to the internet.
+	DNSExternalHost string `env:"DNS_EXTERNAL_HOST" sect:"tests" default:"www.redhat.com"`
+
+	// CertMinRemaining is how long the certificate served by the API server must stay valid. Certificates managed by
+	// certman are renewed 30 days before they expire.
+	CertMinRemaining time.Duration `env:"CERT_MIN_REMAINING" sect:"tests" default:"168h"`
+
+	// CertMaxLifetimeUsed is the fraction of their lifetime certificates may use before they're considered not to have
+	// been rotated when due.
+	CertMaxLifetimeUsed float64 `env:"CERT_MAX_LIFETIME_USED" sect:"tests" default:"0.9"`
+
+	// ScaleResultsDir is a directory of kube-burner results left by a scale run, whose KPIs are checked against
+	// ScaleThresholds.
+	ScaleResultsDir string `env:"SCALE_RESULTS_DIR" sect:"tests"`
+
+	// ScaleThresholds are the highest acceptable values of scale KPIs in their units, with pod latencies in milliseconds
+	// and Prometheus metrics such as API latencies in seconds.
+	ScaleThresholds map[string]string `env:"SCALE_THRESHOLDS" sect:"tests" default:"podLatencyQuantilesMeasurement.Ready.P99=5000,API99thLatency=1"`
+
+	// PrometheusStorage is whether the platform Prometheus is expected to store data on persistent volumes.
+	PrometheusStorage bool `env:"PROMETHEUS_STORAGE" sect:"tests" default:"true"`
+
+	// UserWorkloadMonitoring is whether customers' workloads are expected to be monitored by their own Prometheus.
+	UserWorkloadMonitoring bool `env:"USER_WORKLOAD_MONITORING" sect:"tests" default:"true"`
+
+	// OperatorVersions pins OLM operators to a version before tests run, as a comma separated list of
+	// namespace/subscription=csv, such as 'openshift-operators/my-operator=my-operator.v0.1.2'.
+	OperatorVersions map[string]string `env:"OPERATOR_VERSIONS" sect:"tests"`
+
+	// SecurityAllowlist is a YAML file listing the privileges workloads in managed namespaces may use.
+	SecurityAllowlist string `env:"SECURITY_ALLOWLIST" sect:"tests" default:"test/security/allowlist.yaml"`
+
+	// NodeLogAnalysis checks the kernel and kubelet logs of nodes for problems after testing.
+	NodeLogAnalysis bool `env:"NODE_LOG_ANALYSIS" sect:"tests" default:"true"`
+
+	// LogMetricsFile is a YAML file of patterns counted in logs, which fail when outside their thresholds.
+	LogMetricsFile string `env:"LOG_METRICS_FILE" sect:"tests" default:"logmetrics.yaml"`
+
+	// ImageScanner scans the images of managed components for vulnerabilities after testing, failing components with
+	// critical ones in an informing suite. It may be 'trivy', which must be installed, or 'clair' to use Quay's scans.
+	ImageScanner string `env:"IMAGE_SCANNER" sect:"tests"`
+
+	// ImageScanNamespaces are regular expressions matching namespaces of managed components whose images are scanned.
+	ImageScanNamespaces []string `env:"IMAGE_SCAN_NAMESPACES" sect:"tests" default:"^openshift-"`
+
+	// ClairURL is the Quay registry Clair scans are retrieved from.
+	ClairURL string `env:"CLAIR_URL" sect:"tests" default:"https://quay.io"`
+
+	// Synthetics deploys a workload after install that probes DNS, routes, and pod-to-pod traffic for the rest of the run.
+	// Gaps in availability are reported with the test failures and upgrades they overlap.
+	Synthetics bool `env:"SYNTHETICS" sect:"tests" default:"true"`
+
+	// SyntheticsInterval is how often synthetic probes are performed.
+	SyntheticsInterval time.Duration `env:"SYNTHETICS_INTERVAL" sect:"tests" default:"10s"`
+
+	// Timeline writes timeline.json and a timeline.html viewer to the report directory, placing phases, specs, health
+	// check transitions, upgrades, and gaps in availability alongside warning events and alerts of the cluster.
+	Timeline bool `env:"TIMELINE" sect:"tests" default:"true"`
+
+	// CloudVerification checks the cloud infrastructure of the cluster through the cloud's APIs after install, such as
+	// its instances in each zone, load balancers, and firewall rules. AWS credentials are read from the standard AWS
+	// environment variables, while GCP uses GCP_PROJECT and GCP_SERVICE_ACCOUNT.
+	CloudVerification bool `env:"CLOUD_VERIFICATION" sect:"tests"`
+
+	// CloudBaselineDir contains the infrastructure baseline of each cloud, such as 'aws.yaml'.
+	CloudBaselineDir string `env:"CLOUD_BASELINE_DIR" sect:"tests" default:"cloudbaselines"`
+
+	// GCPProject is the GCP project clusters on GCP are verified in.
+	GCPProject string `env:"GCP_PROJECT" sect:"tests"`
+
+	// GCPServiceAccount is a Base64 encoded GCP service account used to verify clusters on GCP.
+	GCPServiceAccount []byte `env:"GCP_SERVICE_ACCOUNT" sect:"tests"`
+
+	// VersionDriftInterval is how often the cluster's version is checked while testing, failing the run if it changes
+	// from the version tested, such as when a managed upgrade policy upgrades the cluster. It isn't checked if 0.
+	VersionDriftInterval time.Duration `env:"VERSION_DRIFT_INTERVAL" sect:"tests" default:"1m"`
+
+	// AbortOnVersionDrift skips the remaining tests once the cluster's version changes, rather than only reporting it,
+	// so results of different versions aren't mixed.
+	AbortOnVersionDrift bool `env:"ABORT_ON_VERSION_DRIFT" sect:"tests" default:"true"`
+
+	// OperatorChurn fails ClusterOperators which become Degraded or Progressing more often than allowed during a run.
+	// Transitions while the cluster is upgraded aren't counted.
+	OperatorChurn bool `env:"OPERATOR_CHURN" sect:"tests" default:"false"`
+
+	// OperatorConditionInterval is how often the conditions of ClusterOperators are recorded while testing, in addition
+	// to whenever the cluster's health is checked. They're only recorded during health checks if 0.
+	OperatorConditionInterval time.Duration `env:"OPERATOR_CONDITION_INTERVAL" sect:"tests" default:"30s"`
+
+	// OperatorMaxDegraded is how many times each ClusterOperator may become Degraded during a run before failing, even
+	// if it's Available at the end.
+	OperatorMaxDegraded int `env:"OPERATOR_MAX_DEGRADED" sect:"tests" default:"0"`
+
+	// OperatorMaxProgressing is how many times each ClusterOperator may become Progressing during a run before failing.
+	OperatorMaxProgressing int `env:"OPERATOR_MAX_PROGRESSING" sect:"tests" default:"3"`
+
+	// SpecChecks compares what OCM expects the cluster to be, such as its version, nodes, network, and add-ons, to
+	// what the cluster reports after install, upgrade, and testing, failing an informing suite where they disagree.
+	SpecChecks bool `env:"SPEC_CHECKS" sect:"tests" default:"true"`
+
+	// QuarantineFile is a YAML file listing known-broken tests whose failures don't fail the run.
+	QuarantineFile string `env:"QUARANTINE_FILE" sect:"tests" default:"quarantine.yaml"`
+
+	// TriageHints adds probable causes to the messages of failed specs, such as nodes which were NotReady, degraded
+	// cluster operators, warning events, OCM API errors, and known issues matching the failure.
+	TriageHints bool `env:"TRIAGE_HINTS" sect:"tests" default:"true"`
+
 	// UpgradeReleaseStream used to retrieve latest release images. If set, it will be used to perform an upgrade.
 	UpgradeReleaseStream string `env:"UPGRADE_RELEASE_STREAM" sect:"upgrade"`
 
@@ -88,4 +512,74 @@ type Config struct {
 
 	// UpgradeImage is the release image a cluster is upgraded to. If set, it overrides the release stream and upgrades.
 	UpgradeImage string `env:"UPGRADE_IMAGE" sect:"upgrade"`
+
+	// UpgradeImages is a comma separated list of release images the cluster is upgraded through in order. If set, it overrides UpgradeImage.
+	UpgradeImages []string `env:"UPGRADE_IMAGES" sect:"upgrade"`
+
+	// UpgradeSmokeSuite is an openshift-tests suite run after each hop but the last when upgrading through multiple
+	// images, such as 'openshift/conformance/parallel'. The hop fails if any of its tests fail.
+	UpgradeSmokeSuite string `env:"UPGRADE_SMOKE_SUITE" sect:"upgrade"`
+
+	// UpgradeAckGates acknowledges admin gates blocking upgrades, such as for API removals. Upgrades fail on unacknowledged gates if false.
+	UpgradeAckGates bool `env:"UPGRADE_ACK_GATES" sect:"upgrade" default:"true"`
+
+	// UpgradeRetries is how many times an upgrade which failed is triggered again before the run fails. Upgrades which
+	// were rolled back aren't retried.
+	UpgradeRetries int `env:"UPGRADE_RETRIES" sect:"upgrade"`
+
+	// UpgradeFailureGrace is how long the cluster-version-operator may report it's failing before the upgrade is
+	// considered failed, instead of waiting for it to time out.
+	UpgradeFailureGrace time.Duration `env:"UPGRADE_FAILURE_GRACE" sect:"upgrade" default:"20m"`
+
+	// WorkloadProfiles is a comma separated list of workloads deployed before upgrading and verified afterward: web, database, batch, and operator.
+	WorkloadProfiles []string `env:"WORKLOAD_PROFILES" sect:"upgrade"`
+
+	// MaintenanceWindows is a comma separated list of weekly windows upgrades are planned within, such as 'Sat 02:00 4h'. Times are UTC.
+	MaintenanceWindows []string `env:"MAINTENANCE_WINDOWS" sect:"upgrade"`
+
+	// SlackToken is a Slack bot token used to post run progress. Progress is only posted if set.
+	SlackToken string `env:"SLACK_TOKEN" sect:"slack"`
+
+	// SlackChannel is the channel run progress is posted to.
+	SlackChannel string `env:"SLACK_CHANNEL" sect:"slack"`
+
+	// SlackThreshold is how long a run lasts before progress is posted.
+	SlackThreshold time.Duration `env:"SLACK_THRESHOLD" sect:"slack" default:"30m"`
+
+	// TracingEndpoint is the OTLP/HTTP collector runs are exported to as traces, such as 'http://localhost:4318'.
+	// Traces are only recorded if set.
+	TracingEndpoint string `env:"TRACING_ENDPOINT" sect:"tracing"`
+
+	// TracingHeaders are sent with exported traces, such as for authentication, as a comma separated list of
+	// header=value.
+	TracingHeaders map[string]string `env:"TRACING_HEADERS" sect:"tracing"`
+
+	// TracingServiceName is the service traces of runs are reported as.
+	TracingServiceName string `env:"TRACING_SERVICE_NAME" sect:"tracing" default:"osde2e"`
+
+	// PoolSizes is how many unclaimed clusters osde2e-pool keeps ready for each configuration profile, as a comma
+	// separated list of profile=count. The 'default' profile leaves clusters unconfigured.
+	PoolSizes map[string]string `env:"POOL_SIZES" sect:"pool" default:"default=2"`
+
+	// PoolInterval is how often osde2e-pool checks its clusters and replaces those claimed or expiring.
+	PoolInterval time.Duration `env:"POOL_INTERVAL" sect:"pool" default:"5m"`
+
+	// PoolClusterExpiry is how long after creation pool clusters are deleted by OSD, limited by MaxClusterExpiry.
+	PoolClusterExpiry time.Duration `env:"POOL_CLUSTER_EXPIRY" sect:"pool" default:"24h"`
+
+	// PoolMinLifetime is how long pool clusters must have left before expiring to be offered for claiming.
+	PoolMinLifetime time.Duration `env:"POOL_MIN_LIFETIME" sect:"pool" default:"6h"`
+
+	// WebhookTriggers is a YAML file of the runs osde2e-webhook starts for each event it receives.
+	WebhookTriggers string `env:"WEBHOOK_TRIGGERS" sect:"webhook" default:"webhooks.yaml"`
+
+	// WebhookSecret is shared with senders of webhooks to sign them. osde2e-webhook refuses to start without it unless
+	// run with -insecure, which accepts unsigned webhooks.
+	WebhookSecret []byte `env:"WEBHOOK_SECRET" sect:"webhook"`
+
+	// WebhookMaxRuns limits how many runs osde2e-webhook performs at once. There's no limit if it's 0.
+	WebhookMaxRuns int `env:"WEBHOOK_MAX_RUNS" sect:"webhook" default:"3"`
+
+	// WebhookAuditLog is the file osde2e-webhook appends a JSON record of each webhook and run to.
+	WebhookAuditLog string `env:"WEBHOOK_AUDIT_LOG" sect:"webhook" default:"webhook-audit.log"`
 }
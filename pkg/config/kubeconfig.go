@@ -0,0 +1,22 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+)
+
+// ReadKubeconfig replaces the path set as TEST_KUBECONFIG with the contents of the file, so it can be used to access
+// the cluster. It does nothing if TEST_KUBECONFIG isn't set.
+func (c *Config) ReadKubeconfig() error {
+	if len(c.Kubeconfig) == 0 {
+		return nil
+	}
+
+	filename := string(c.Kubeconfig)
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("failed reading '%s' which has been set as the TEST_KUBECONFIG: %v", filename, err)
+	}
+	c.Kubeconfig = data
+	return nil
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestReadKubeconfig(t *testing.T) {
+	f, err := ioutil.TempFile("", "kubeconfig")
+	if err != nil {
+		t.Fatalf("failed to create kubeconfig: %v", err)
+	}
+	defer os.Remove(f.Name())
+	if _, err = f.WriteString("apiVersion: v1\nkind: Config\n"); err != nil {
+		t.Fatalf("failed to write kubeconfig: %v", err)
+	}
+	f.Close()
+
+	cfg := &Config{Kubeconfig: []byte(f.Name())}
+	if err = cfg.ReadKubeconfig(); err != nil {
+		t.Fatalf("failed to read kubeconfig: %v", err)
+	}
+	if string(cfg.Kubeconfig) != "apiVersion: v1\nkind: Config\n" {
+		t.Errorf("expected kubeconfig contents, got '%s'", cfg.Kubeconfig)
+	}
+
+	cfg = &Config{Kubeconfig: []byte("/does/not/exist")}
+	if err = cfg.ReadKubeconfig(); err == nil {
+		t.Error("expected an error reading a missing kubeconfig")
+	}
+
+	cfg = new(Config)
+	if err = cfg.ReadKubeconfig(); err != nil || len(cfg.Kubeconfig) != 0 {
+		t.Errorf("expected nothing to be read when unset, got '%s': %v", cfg.Kubeconfig, err)
+	}
+}
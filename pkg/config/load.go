@@ -1,38 +1,195 @@
 package config
 
 import (
+	"fmt"
+	"log"
 	"os"
 	"reflect"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 )
 
+const (
+	// EnvPrefix may be used in front of any option's environment variable. Unknown variables with the prefix
+	// are reported and cause loading to fail when StrictEnv is set.
+	EnvPrefix = "OSDE2E_"
+
+	// listSep separates elements of slices and maps.
+	listSep = ","
+
+	// keyValueSep separates keys from values in maps.
+	keyValueSep = "="
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// LoadErr is set if Cfg couldn't be loaded from the environment. It should be checked before Cfg is used.
+var LoadErr error
+
 func init() {
-	Cfg.LoadFromEnv()
+	LoadErr = Cfg.LoadFromEnv()
 }
 
-// LoadFromEnv sets values from environment variables specified in `env` tags.
-func (c *Config) LoadFromEnv() {
-	v := reflect.ValueOf(c).Elem()
+// LoadFromEnv sets values from environment variables specified in `env` tags, using `default` tags for unset
+// variables. Fields of nested structs use the `env` tag of the struct as a prefix. Variables for older versions of
+// options are migrated first. Unknown variables starting with EnvPrefix result in an error if StrictEnv is set.
+func (c *Config) LoadFromEnv() error {
+	// migrating unsets deprecated variables, so they're warned about first
+	warnDeprecated()
+	known := map[string]bool{}
+	if err := migrateEnv(Migrations, known); err != nil {
+		return err
+	}
+
+	if err := loadStruct(reflect.ValueOf(c).Elem(), "", known); err != nil {
+		return err
+	}
+
+	if unknown := unknownEnv(known); len(unknown) != 0 {
+		if c.StrictEnv {
+			return fmt.Errorf("unknown environment variables: %s", strings.Join(unknown, ", "))
+		}
+		log.Printf("Ignoring unknown environment variables: %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// loadStruct sets the fields of v, recording the variables it checked in known.
+func loadStruct(v reflect.Value, prefix string, known map[string]bool) error {
 	for i := 0; i < v.Type().NumField(); i++ {
 		f := v.Type().Field(i)
-		if env, ok := f.Tag.Lookup(EnvVarTag); ok {
-			if envVal := os.Getenv(env); len(envVal) > 0 {
-				field := v.Field(i)
-				switch f.Type.Kind() {
-				case reflect.String:
-					field.SetString(envVal)
-				case reflect.Bool:
-					field.SetBool(true)
-				case reflect.Slice:
-					field.SetBytes([]byte(envVal))
-				case reflect.Int:
-					fallthrough
-				case reflect.Int64:
-					if num, err := strconv.ParseInt(envVal, 10, 0); err == nil {
-						field.SetInt(num)
-					}
-				}
+		env, ok := f.Tag.Lookup(EnvVarTag)
+		if !ok {
+			continue
+		}
+		name := prefix + env
+
+		// nested structs use their variable as a prefix
+		if f.Type.Kind() == reflect.Struct {
+			if err := loadStruct(v.Field(i), name+"_", known); err != nil {
+				return err
+			}
+			continue
+		}
+
+		known[name] = true
+		envVal, ok := lookupEnv(name)
+		if !ok {
+			if envVal, ok = f.Tag.Lookup(DefaultTag); !ok {
+				continue
+			}
+		} else if f.Type.Kind() == reflect.Bool {
+			// any value used to enable options, so values that aren't booleans still do
+			if _, err := strconv.ParseBool(envVal); err != nil {
+				log.Printf("%s is set to '%s', which enables it. Set it to 'true' or 'false' instead.", name, envVal)
+				envVal = "true"
+			}
+		}
+
+		if err := setField(v.Field(i), envVal); err != nil {
+			return fmt.Errorf("invalid value '%s' for %s: %v", envVal, name, err)
+		}
+	}
+	return nil
+}
+
+// lookupEnv returns the value of name, preferring the variable with EnvPrefix when set. Empty variables are unset.
+func lookupEnv(name string) (string, bool) {
+	for _, env := range []string{EnvPrefix + name, name} {
+		if envVal := os.Getenv(env); len(envVal) > 0 {
+			return envVal, true
+		}
+	}
+	return "", false
+}
+
+// unknownEnv returns variables with EnvPrefix that don't set an option.
+func unknownEnv(known map[string]bool) (unknown []string) {
+	for _, kv := range os.Environ() {
+		env := strings.SplitN(kv, "=", 2)[0]
+		if strings.HasPrefix(env, EnvPrefix) && !known[strings.TrimPrefix(env, EnvPrefix)] {
+			unknown = append(unknown, env)
+		}
+	}
+	sort.Strings(unknown)
+	return
+}
+
+// setField parses val into field based on its type.
+func setField(field reflect.Value, val string) error {
+	if field.Type() == durationType {
+		dur, err := time.ParseDuration(val)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(dur))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(val)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(val)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		num, err := strconv.ParseInt(val, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(num)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		num, err := strconv.ParseUint(val, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(num)
+	case reflect.Float32, reflect.Float64:
+		num, err := strconv.ParseFloat(val, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(num)
+	case reflect.Slice:
+		// byte slices are set directly
+		if field.Type().Elem().Kind() == reflect.Uint8 {
+			field.SetBytes([]byte(val))
+			return nil
+		}
+
+		elems := strings.Split(val, listSep)
+		slice := reflect.MakeSlice(field.Type(), len(elems), len(elems))
+		for i, elem := range elems {
+			if err := setField(slice.Index(i), strings.TrimSpace(elem)); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+	case reflect.Map:
+		m := reflect.MakeMap(field.Type())
+		for _, pair := range strings.Split(val, listSep) {
+			kv := strings.SplitN(pair, keyValueSep, 2)
+			if len(kv) != 2 {
+				return fmt.Errorf("'%s' should be in the form key%svalue", pair, keyValueSep)
+			}
+
+			key, elem := reflect.New(field.Type().Key()).Elem(), reflect.New(field.Type().Elem()).Elem()
+			if err := setField(key, strings.TrimSpace(kv[0])); err != nil {
+				return err
+			}
+			if err := setField(elem, strings.TrimSpace(kv[1])); err != nil {
+				return err
 			}
+			m.SetMapIndex(key, elem)
 		}
+		field.Set(m)
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
 	}
+	return nil
 }
@@ -0,0 +1,151 @@
+package config
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testNested struct {
+	Name string `env:"NAME"`
+	Port int    `env:"PORT" default:"8080"`
+}
+
+type testConfig struct {
+	Str      string            `env:"TEST_STR"`
+	Enabled  bool              `env:"TEST_ENABLED"`
+	Count    int64             `env:"TEST_COUNT"`
+	Timeout  time.Duration     `env:"TEST_TIMEOUT" default:"5m"`
+	Images   []string          `env:"TEST_IMAGES"`
+	Nums     []int             `env:"TEST_NUMS"`
+	Data     []byte            `env:"TEST_DATA"`
+	Labels   map[string]string `env:"TEST_LABELS"`
+	Server   testNested        `env:"TEST_SERVER"`
+	Untagged string
+}
+
+func TestLoadStruct(t *testing.T) {
+	defer setEnv(t, map[string]string{
+		"TEST_STR":             "unprefixed",
+		EnvPrefix + "TEST_STR": "prefixed",
+		"TEST_ENABLED":         "true",
+		"TEST_COUNT":           "42",
+		"TEST_IMAGES":          "a, b,c",
+		"TEST_NUMS":            "1,2",
+		"TEST_DATA":            "bytes",
+		"TEST_LABELS":          "env=int,region=us-east-1",
+		"TEST_SERVER_NAME":     "server",
+	})()
+
+	var cfg testConfig
+	known := map[string]bool{}
+	if err := loadStruct(reflect.ValueOf(&cfg).Elem(), "", known); err != nil {
+		t.Fatalf("Failed loading config: %v", err)
+	}
+
+	expected := testConfig{
+		Str:     "prefixed",
+		Enabled: true,
+		Count:   42,
+		Timeout: 5 * time.Minute,
+		Images:  []string{"a", "b", "c"},
+		Nums:    []int{1, 2},
+		Data:    []byte("bytes"),
+		Labels:  map[string]string{"env": "int", "region": "us-east-1"},
+		Server: testNested{
+			Name: "server",
+			Port: 8080,
+		},
+	}
+	if !reflect.DeepEqual(cfg, expected) {
+		t.Errorf("expected config %+v, got %+v", expected, cfg)
+	}
+
+	for _, env := range []string{"TEST_STR", "TEST_SERVER_NAME", "TEST_SERVER_PORT"} {
+		if !known[env] {
+			t.Errorf("'%s' should be a known variable", env)
+		}
+	}
+}
+
+func TestLoadStructInvalid(t *testing.T) {
+	invalid := map[string]string{
+		"TEST_COUNT":   "forty-two",
+		"TEST_TIMEOUT": "5",
+		"TEST_NUMS":    "1,two",
+		"TEST_LABELS":  "env",
+	}
+
+	for env, val := range invalid {
+		unset := setEnv(t, map[string]string{env: val})
+
+		var cfg testConfig
+		if err := loadStruct(reflect.ValueOf(&cfg).Elem(), "", map[string]bool{}); err == nil {
+			t.Errorf("'%s=%s' should fail to load", env, val)
+		}
+		unset()
+	}
+}
+
+func TestLoadStructLegacyBool(t *testing.T) {
+	for val, enabled := range map[string]bool{
+		"true":  true,
+		"false": false,
+		"0":     false,
+		"yes":   true,
+		"on":    true,
+	} {
+		unset := setEnv(t, map[string]string{"TEST_ENABLED": val})
+
+		var cfg testConfig
+		if err := loadStruct(reflect.ValueOf(&cfg).Elem(), "", map[string]bool{}); err != nil {
+			t.Errorf("'TEST_ENABLED=%s' should load: %v", val, err)
+		} else if cfg.Enabled != enabled {
+			t.Errorf("expected 'TEST_ENABLED=%s' to set %t, got %t", val, enabled, cfg.Enabled)
+		}
+		unset()
+	}
+}
+
+func TestLoadFromEnvStrict(t *testing.T) {
+	defer setEnv(t, map[string]string{
+		EnvPrefix + "CLUSTER_NAME": "known",
+	})()
+	unsetTypo := setEnv(t, map[string]string{
+		EnvPrefix + "CLUSTR_VERSION": "typo",
+	})
+
+	var cfg Config
+	if err := cfg.LoadFromEnv(); err != nil {
+		t.Fatalf("unknown variables should be ignored when not strict: %v", err)
+	}
+
+	defer setEnv(t, map[string]string{EnvPrefix + "STRICT_ENV": "true"})()
+	if err := cfg.LoadFromEnv(); err == nil {
+		t.Fatal("unknown variables should fail when strict")
+	}
+
+	unsetTypo()
+	if err := cfg.LoadFromEnv(); err != nil {
+		t.Fatalf("known variables should load when strict: %v", err)
+	}
+
+	if cfg.ClusterName != "known" {
+		t.Errorf("expected cluster name 'known', got '%s'", cfg.ClusterName)
+	}
+}
+
+// setEnv sets vars, returning a func that unsets them.
+func setEnv(t *testing.T, vars map[string]string) func() {
+	for env, val := range vars {
+		if err := os.Setenv(env, val); err != nil {
+			t.Fatalf("Failed setting '%s': %v", env, err)
+		}
+	}
+	return func() {
+		for env := range vars {
+			os.Unsetenv(env)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+)
+
+// CurrentVersion is the version of options in this release. It's incremented with each breaking change to options,
+// adding a migration from the previous version to Migrations.
+const CurrentVersion = 2
+
+// Migration upgrades the variables set by jobs written for one version of options to the next.
+type Migration struct {
+	// Renamed maps variables to their new names.
+	Renamed map[string]string
+
+	// Converted maps variables whose options were replaced to funcs returning the variables to set instead.
+	Converted map[string]func(val string) (map[string]string, error)
+}
+
+// Migrations upgrade options from each version to the next, with the first upgrading version 1 to 2.
+var Migrations = []Migration{
+	// 2: teardown policies replaced NO_DESTROY and OSD_ENV replaced USE_PROD
+	{
+		Converted: map[string]func(string) (map[string]string, error){
+			"NO_DESTROY": convertBool("TEARDOWN_POLICY", string(TeardownNeverDestroy)),
+			"USE_PROD":   convertBool("OSD_ENV", "prod"),
+		},
+	},
+}
+
+// deprecatedEnv maps variables which are still supported but will be removed to what should be set instead.
+var deprecatedEnv = map[string]string{
+	"NO_DESTROY": "TEARDOWN_POLICY=never-destroy",
+	"USE_PROD":   "OSD_ENV=prod",
+}
+
+// migrateEnv replaces variables set for the CONFIG_VERSION of the job with those of CurrentVersion, recording the
+// variables replaced in known. Variables already set for the current version aren't overwritten.
+func migrateEnv(migrations []Migration, known map[string]bool) error {
+	version := 1
+	if val, ok := lookupEnv("CONFIG_VERSION"); ok {
+		v, err := strconv.Atoi(val)
+		if err != nil {
+			return fmt.Errorf("invalid value '%s' for CONFIG_VERSION: %v", val, err)
+		}
+		version = v
+	}
+
+	latest := len(migrations) + 1
+	if version < 1 || version > latest {
+		return fmt.Errorf("CONFIG_VERSION %d isn't supported, must be between 1 and %d", version, latest)
+	}
+
+	for i, m := range migrations[version-1:] {
+		to := version + i + 1
+		for _, old := range sortedKeys(m.Renamed) {
+			known[old] = true
+			if val, ok := lookupEnv(old); ok {
+				replaceEnv(old, map[string]string{m.Renamed[old]: val}, to)
+			}
+		}
+
+		for old, convert := range m.Converted {
+			known[old] = true
+			if val, ok := lookupEnv(old); ok {
+				vars, err := convert(val)
+				if err != nil {
+					return fmt.Errorf("couldn't migrate %s to version %d: %v", old, to, err)
+				}
+				replaceEnv(old, vars, to)
+			}
+		}
+	}
+	return nil
+}
+
+// warnDeprecated logs the deprecated variables which are set and how to replace them.
+func warnDeprecated() {
+	for _, env := range sortedKeys(deprecatedEnv) {
+		if _, ok := lookupEnv(env); ok {
+			log.Printf("%s is deprecated and will be removed, set %s instead.", env, deprecatedEnv[env])
+		}
+	}
+}
+
+// replaceEnv unsets old, setting vars in its place unless they're already set.
+func replaceEnv(old string, vars map[string]string, version int) {
+	os.Unsetenv(old)
+	os.Unsetenv(EnvPrefix + old)
+
+	for _, env := range sortedKeys(vars) {
+		if _, ok := lookupEnv(env); ok {
+			log.Printf("Ignoring %s as %s is set, remove it when updating to CONFIG_VERSION %d.", old, env, version)
+			continue
+		}
+		os.Setenv(env, vars[env])
+		log.Printf("Migrated %s to %s=%s, set it instead when updating to CONFIG_VERSION %d.", old, env, vars[env], version)
+	}
+}
+
+// convertBool returns a conversion setting env to val when a boolean variable is true. As before options were
+// versioned, any value that isn't false counts as true.
+func convertBool(env, val string) func(string) (map[string]string, error) {
+	return func(b string) (map[string]string, error) {
+		if set, err := strconv.ParseBool(b); err == nil && !set {
+			return nil, nil
+		}
+		return map[string]string{env: val}, nil
+	}
+}
+
+func sortedKeys(m map[string]string) (keys []string) {
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return
+}
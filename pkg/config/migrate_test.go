@@ -0,0 +1,107 @@
+package config
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+var testMigrations = []Migration{
+	{
+		Renamed: map[string]string{"TEST_OLD_NAME": "TEST_NEW_NAME"},
+	},
+	{
+		Converted: map[string]func(string) (map[string]string, error){
+			"TEST_KEEP": convertBool("TEST_POLICY", "keep"),
+		},
+	},
+}
+
+func TestMigrateEnv(t *testing.T) {
+	defer setEnv(t, map[string]string{
+		EnvPrefix + "TEST_OLD_NAME": "renamed",
+		"TEST_KEEP":                 "yes",
+	})()
+	defer os.Unsetenv("TEST_NEW_NAME")
+	defer os.Unsetenv("TEST_POLICY")
+
+	known := map[string]bool{}
+	if err := migrateEnv(testMigrations, known); err != nil {
+		t.Fatalf("Failed migrating: %v", err)
+	}
+
+	for env, expected := range map[string]string{"TEST_NEW_NAME": "renamed", "TEST_POLICY": "keep"} {
+		if val, _ := lookupEnv(env); val != expected {
+			t.Errorf("expected %s to be migrated to '%s', got '%s'", env, expected, val)
+		}
+	}
+	for _, env := range []string{"TEST_OLD_NAME", "TEST_KEEP"} {
+		if _, ok := lookupEnv(env); ok {
+			t.Errorf("%s should be unset after migrating", env)
+		}
+		if !known[env] {
+			t.Errorf("%s should be a known variable", env)
+		}
+	}
+}
+
+func TestMigrateEnvVersion(t *testing.T) {
+	defer setEnv(t, map[string]string{
+		"CONFIG_VERSION": "2",
+		"TEST_OLD_NAME":  "old",
+		"TEST_NEW_NAME":  "new",
+		"TEST_KEEP":      "false",
+	})()
+	defer os.Unsetenv("TEST_POLICY")
+
+	// only migrations after the job's version are applied, and unset variables aren't overwritten
+	if err := migrateEnv(testMigrations, map[string]bool{}); err != nil {
+		t.Fatalf("Failed migrating: %v", err)
+	}
+	if val, _ := lookupEnv("TEST_OLD_NAME"); val != "old" {
+		t.Errorf("variables of the job's version shouldn't be migrated, got TEST_OLD_NAME='%s'", val)
+	}
+	if val, _ := lookupEnv("TEST_NEW_NAME"); val != "new" {
+		t.Errorf("expected TEST_NEW_NAME to be left as 'new', got '%s'", val)
+	}
+	if _, ok := lookupEnv("TEST_POLICY"); ok {
+		t.Error("false TEST_KEEP shouldn't set TEST_POLICY")
+	}
+
+	for _, version := range []string{"0", "4", "latest"} {
+		unset := setEnv(t, map[string]string{"CONFIG_VERSION": version})
+		if err := migrateEnv(testMigrations, map[string]bool{}); err == nil {
+			t.Errorf("CONFIG_VERSION '%s' should fail to migrate", version)
+		}
+		unset()
+	}
+}
+
+func TestCurrentVersion(t *testing.T) {
+	if latest := len(Migrations) + 1; latest != CurrentVersion {
+		t.Errorf("CurrentVersion is %d, but migrations upgrade to version %d", CurrentVersion, latest)
+	}
+}
+
+func TestLoadFromEnvWarnsDeprecated(t *testing.T) {
+	defer setEnv(t, map[string]string{"NO_DESTROY": "true"})()
+	defer os.Unsetenv("TEARDOWN_POLICY")
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	var cfg Config
+	if err := cfg.LoadFromEnv(); err != nil {
+		t.Fatalf("Failed loading: %v", err)
+	}
+
+	if !strings.Contains(buf.String(), "NO_DESTROY is deprecated") {
+		t.Errorf("expected deprecated NO_DESTROY to be warned about after migrating it, got:\n%s", buf.String())
+	}
+	if cfg.Teardown() != TeardownNeverDestroy {
+		t.Errorf("expected NO_DESTROY to be migrated to never-destroy, got %s", cfg.Teardown())
+	}
+}
@@ -0,0 +1,101 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Phase is a stage of an osde2e run which can be run on its own.
+type Phase string
+
+const (
+	// PhaseInstall creates a cluster, waits for it to be ready, and applies any configuration profile.
+	PhaseInstall Phase = "install"
+
+	// PhaseUpgrade upgrades the cluster.
+	PhaseUpgrade Phase = "upgrade"
+
+	// PhaseTests runs the test suite against the cluster.
+	PhaseTests Phase = "tests"
+
+	// PhaseTeardown collects logs and deletes the cluster.
+	PhaseTeardown Phase = "teardown"
+)
+
+// Phases are all phases in the order they run.
+var Phases = []Phase{PhaseInstall, PhaseUpgrade, PhaseTests, PhaseTeardown}
+
+// RunPhase returns true if p should be run. All phases are run when none are selected.
+func (c *Config) RunPhase(p Phase) bool {
+	if len(c.Phases) == 0 {
+		return true
+	}
+
+	for _, name := range c.Phases {
+		if Phase(strings.TrimSpace(name)) == p {
+			return true
+		}
+	}
+	return false
+}
+
+// PhaseTimeout returns how long p may run. Zero is returned if it isn't limited.
+func (c *Config) PhaseTimeout(p Phase) (time.Duration, error) {
+	timeout, ok := c.PhaseTimeouts[string(p)]
+	if !ok {
+		return 0, nil
+	}
+
+	d, err := time.ParseDuration(timeout)
+	if err != nil {
+		return 0, fmt.Errorf("couldn't parse timeout of phase '%s': %v", p, err)
+	} else if d < 0 {
+		return 0, fmt.Errorf("timeout of phase '%s' can't be negative", p)
+	}
+	return d, nil
+}
+
+// ValidatePhases returns an error if the selected phases are unknown or missing the options they require.
+func (c *Config) ValidatePhases() error {
+	for _, name := range c.Phases {
+		if !isPhase(Phase(strings.TrimSpace(name))) {
+			return fmt.Errorf("unknown phase '%s', must be one of %v", name, Phases)
+		}
+	}
+
+	for name := range c.PhaseTimeouts {
+		if !isPhase(Phase(name)) {
+			return fmt.Errorf("PHASE_TIMEOUTS has unknown phase '%s', must be one of %v", name, Phases)
+		} else if _, err := c.PhaseTimeout(Phase(name)); err != nil {
+			return err
+		}
+	}
+
+	if len(c.Phases) == 0 {
+		return nil
+	}
+
+	// phases run without installing need an existing cluster
+	if !c.RunPhase(PhaseInstall) {
+		if c.ClusterID == "" && len(c.Kubeconfig) == 0 {
+			return fmt.Errorf("CLUSTER_ID or TEST_KUBECONFIG must be set when the %s phase isn't run", PhaseInstall)
+		} else if c.RunPhase(PhaseTeardown) && c.ClusterID == "" {
+			return fmt.Errorf("CLUSTER_ID must be set to run the %s phase without the %s phase", PhaseTeardown, PhaseInstall)
+		}
+	}
+
+	if c.RunPhase(PhaseUpgrade) && c.UpgradeImage == "" && len(c.UpgradeImages) == 0 && c.UpgradeReleaseStream == "" {
+		return fmt.Errorf("UPGRADE_IMAGE, UPGRADE_IMAGES, or UPGRADE_RELEASE_STREAM must be set to run the %s phase", PhaseUpgrade)
+	}
+	return nil
+}
+
+func isPhase(p Phase) bool {
+	for _, phase := range Phases {
+		if p == phase {
+			return true
+		}
+	}
+	return false
+}
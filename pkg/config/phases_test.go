@@ -0,0 +1,93 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRunPhase(t *testing.T) {
+	cfg := &Config{}
+	for _, p := range Phases {
+		if !cfg.RunPhase(p) {
+			t.Errorf("phase '%s' should run when none are selected", p)
+		}
+	}
+
+	cfg.Phases = []string{"tests", " teardown"}
+	for p, expected := range map[Phase]bool{
+		PhaseInstall:  false,
+		PhaseUpgrade:  false,
+		PhaseTests:    true,
+		PhaseTeardown: true,
+	} {
+		if cfg.RunPhase(p) != expected {
+			t.Errorf("expected phase '%s' to run: %t", p, expected)
+		}
+	}
+}
+
+func TestValidatePhases(t *testing.T) {
+	for name, test := range map[string]struct {
+		cfg   Config
+		valid bool
+	}{
+		"all phases": {
+			cfg:   Config{},
+			valid: true,
+		},
+		"unknown phase": {
+			cfg: Config{Phases: []string{"tests", "deploy"}, ClusterID: "abc"},
+		},
+		"tests without cluster": {
+			cfg: Config{Phases: []string{"tests"}},
+		},
+		"tests with kubeconfig": {
+			cfg:   Config{Phases: []string{"tests"}, Kubeconfig: []byte("kubeconfig")},
+			valid: true,
+		},
+		"teardown with kubeconfig": {
+			cfg: Config{Phases: []string{"teardown"}, Kubeconfig: []byte("kubeconfig")},
+		},
+		"teardown with cluster": {
+			cfg:   Config{Phases: []string{"teardown"}, ClusterID: "abc"},
+			valid: true,
+		},
+		"upgrade without image": {
+			cfg: Config{Phases: []string{"upgrade"}, ClusterID: "abc"},
+		},
+		"upgrade with image": {
+			cfg:   Config{Phases: []string{"upgrade"}, ClusterID: "abc", UpgradeImage: "quay.io/openshift-release-dev/ocp-release:4.1.9"},
+			valid: true,
+		},
+		"timeout of unknown phase": {
+			cfg: Config{PhaseTimeouts: map[string]string{"deploy": "1h"}},
+		},
+		"invalid timeout": {
+			cfg: Config{PhaseTimeouts: map[string]string{"tests": "soon"}},
+		},
+		"phase timeouts": {
+			cfg:   Config{PhaseTimeouts: map[string]string{"install": "3h", "teardown": "30m"}},
+			valid: true,
+		},
+	} {
+		if err := test.cfg.ValidatePhases(); (err == nil) != test.valid {
+			t.Errorf("%s: expected valid %t, got error: %v", name, test.valid, err)
+		}
+	}
+}
+
+func TestPhaseTimeout(t *testing.T) {
+	cfg := &Config{PhaseTimeouts: map[string]string{"install": "3h", "tests": "-1m"}}
+
+	if timeout, err := cfg.PhaseTimeout(PhaseInstall); err != nil || timeout != 3*time.Hour {
+		t.Errorf("expected install timeout of 3h, got %v: %v", timeout, err)
+	}
+
+	if timeout, err := cfg.PhaseTimeout(PhaseUpgrade); err != nil || timeout != 0 {
+		t.Errorf("expected upgrade to not be limited, got %v: %v", timeout, err)
+	}
+
+	if _, err := cfg.PhaseTimeout(PhaseTests); err == nil {
+		t.Error("expected negative tests timeout to be invalid")
+	}
+}
@@ -0,0 +1,33 @@
+package config
+
+import "fmt"
+
+// ValidateSharing returns an error if a shared cluster is tested without one being given or with options changing
+// the whole cluster, which would disrupt the other runs testing it.
+func (c *Config) ValidateSharing() error {
+	if !c.SharedCluster {
+		return nil
+	}
+
+	if c.ClusterID == "" && len(c.Kubeconfig) == 0 {
+		return fmt.Errorf("CLUSTER_ID or TEST_KUBECONFIG must be set to test a shared cluster")
+	} else if c.SharedClusterTenants < 1 {
+		return fmt.Errorf("SHARED_CLUSTER_TENANTS must be at least 1, got %d", c.SharedClusterTenants)
+	}
+
+	if c.RunPhase(PhaseUpgrade) && (c.UpgradeImage != "" || len(c.UpgradeImages) != 0 || c.UpgradeReleaseStream != "") {
+		return fmt.Errorf("shared clusters can't be upgraded")
+	}
+
+	for option, set := range map[string]bool{
+		"CONFIG_PROFILE":    c.ConfigProfile != "",
+		"MACHINE_POOLS":     c.MachinePools != "",
+		"ADDON_BUNDLE":      c.AddonBundle != "",
+		"OPERATOR_VERSIONS": len(c.OperatorVersions) != 0,
+	} {
+		if set {
+			return fmt.Errorf("%s changes the whole cluster so can't be used with a shared cluster", option)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package config
+
+import "testing"
+
+func TestValidateSharing(t *testing.T) {
+	for name, test := range map[string]struct {
+		cfg   Config
+		valid bool
+	}{
+		"not shared": {
+			cfg:   Config{ConfigProfile: "profiles/customer.yaml"},
+			valid: true,
+		},
+		"shared": {
+			cfg:   Config{SharedCluster: true, SharedClusterTenants: 4, ClusterID: "abc"},
+			valid: true,
+		},
+		"no cluster": {
+			cfg: Config{SharedCluster: true, SharedClusterTenants: 4},
+		},
+		"no tenants": {
+			cfg: Config{SharedCluster: true, ClusterID: "abc"},
+		},
+		"upgraded": {
+			cfg: Config{SharedCluster: true, SharedClusterTenants: 4, ClusterID: "abc", UpgradeImage: "image"},
+		},
+		"upgrade phase not run": {
+			cfg: Config{SharedCluster: true, SharedClusterTenants: 4, ClusterID: "abc", UpgradeImage: "image",
+				Phases: []string{"tests"}},
+			valid: true,
+		},
+		"configured": {
+			cfg: Config{SharedCluster: true, SharedClusterTenants: 4, ClusterID: "abc",
+				OperatorVersions: map[string]string{"sub": "v1"}},
+		},
+	} {
+		if err := test.cfg.ValidateSharing(); (err == nil) != test.valid {
+			t.Errorf("%s: expected valid to be %t: %v", name, test.valid, err)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// TeardownPolicy decides whether the cluster is destroyed after a run.
+type TeardownPolicy string
+
+const (
+	// TeardownAlwaysDestroy destroys the cluster after every run.
+	TeardownAlwaysDestroy TeardownPolicy = "always-destroy"
+
+	// TeardownKeepOnFailure keeps the cluster when provisioning or tests failed.
+	TeardownKeepOnFailure TeardownPolicy = "keep-on-failure"
+
+	// TeardownKeepOnTestFailure keeps the cluster only when it was provisioned but tests failed.
+	TeardownKeepOnTestFailure TeardownPolicy = "keep-on-test-failure"
+
+	// TeardownNeverDestroy keeps the cluster after every run.
+	TeardownNeverDestroy TeardownPolicy = "never-destroy"
+)
+
+// TeardownPolicies are all teardown policies.
+var TeardownPolicies = []TeardownPolicy{TeardownAlwaysDestroy, TeardownKeepOnFailure, TeardownKeepOnTestFailure,
+	TeardownNeverDestroy}
+
+// Teardown returns the selected teardown policy, which is never-destroy if the deprecated NoDestroy is set.
+func (c *Config) Teardown() TeardownPolicy {
+	if c.NoDestroy {
+		return TeardownNeverDestroy
+	} else if c.TeardownPolicy == "" {
+		return TeardownAlwaysDestroy
+	}
+	return TeardownPolicy(c.TeardownPolicy)
+}
+
+// KeepCluster returns true if the cluster should be left running given whether setup or tests failed.
+func (c *Config) KeepCluster(setupFailed, testsFailed bool) bool {
+	switch c.Teardown() {
+	case TeardownKeepOnFailure:
+		return setupFailed || testsFailed
+	case TeardownKeepOnTestFailure:
+		return !setupFailed && testsFailed
+	case TeardownNeverDestroy:
+		return true
+	}
+	return false
+}
+
+// KeptExpiry returns how long a cluster kept by the teardown policy should live from the end of the run. Zero is
+// returned if its expiry shouldn't be changed.
+func (c *Config) KeptExpiry() (time.Duration, error) {
+	policy := c.Teardown()
+	expiry, ok := c.TeardownExpiry[string(policy)]
+	if !ok {
+		return 0, nil
+	}
+
+	d, err := time.ParseDuration(expiry)
+	if err != nil {
+		return 0, fmt.Errorf("couldn't parse expiry of teardown policy '%s': %v", policy, err)
+	} else if d < 0 {
+		return 0, fmt.Errorf("expiry of teardown policy '%s' can't be negative", policy)
+	}
+	return d, nil
+}
+
+// ValidateTeardown returns an error if the teardown policy or its expiries are unknown or invalid.
+func (c *Config) ValidateTeardown() error {
+	if !isTeardownPolicy(c.Teardown()) {
+		return fmt.Errorf("unknown teardown policy '%s', must be one of %v", c.TeardownPolicy, TeardownPolicies)
+	}
+
+	for name, expiry := range c.TeardownExpiry {
+		if !isTeardownPolicy(TeardownPolicy(name)) {
+			return fmt.Errorf("TEARDOWN_EXPIRY has unknown policy '%s', must be one of %v", name, TeardownPolicies)
+		} else if d, err := time.ParseDuration(expiry); err != nil || d < 0 {
+			return fmt.Errorf("TEARDOWN_EXPIRY of policy '%s' must not be negative, got '%s'", name, expiry)
+		}
+	}
+	return nil
+}
+
+func isTeardownPolicy(p TeardownPolicy) bool {
+	for _, policy := range TeardownPolicies {
+		if p == policy {
+			return true
+		}
+	}
+	return false
+}
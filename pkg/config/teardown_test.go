@@ -0,0 +1,73 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestKeepCluster(t *testing.T) {
+	for policy, expected := range map[TeardownPolicy][4]bool{
+		// kept when: nothing failed, tests failed, setup failed, both failed
+		"":                        {false, false, false, false},
+		TeardownAlwaysDestroy:     {false, false, false, false},
+		TeardownKeepOnFailure:     {false, true, true, true},
+		TeardownKeepOnTestFailure: {false, true, false, false},
+		TeardownNeverDestroy:      {true, true, true, true},
+	} {
+		cfg := &Config{TeardownPolicy: string(policy)}
+		for i, failed := range [][2]bool{{false, false}, {false, true}, {true, false}, {true, true}} {
+			if kept := cfg.KeepCluster(failed[0], failed[1]); kept != expected[i] {
+				t.Errorf("expected policy '%s' to keep cluster when setup failed is %t and tests failed is %t: %t",
+					policy, failed[0], failed[1], expected[i])
+			}
+		}
+	}
+
+	if cfg := (&Config{NoDestroy: true}); !cfg.KeepCluster(false, false) {
+		t.Error("deprecated NoDestroy should keep the cluster")
+	}
+}
+
+func TestKeptExpiry(t *testing.T) {
+	cfg := &Config{
+		TeardownPolicy: string(TeardownKeepOnFailure),
+		TeardownExpiry: map[string]string{"keep-on-failure": "36h"},
+	}
+	if d, err := cfg.KeptExpiry(); err != nil || d != 36*time.Hour {
+		t.Errorf("expected expiry of 36h, got %v: %v", d, err)
+	}
+
+	cfg.TeardownPolicy = string(TeardownNeverDestroy)
+	if d, err := cfg.KeptExpiry(); err != nil || d != 0 {
+		t.Errorf("expected no expiry for policy without one, got %v: %v", d, err)
+	}
+}
+
+func TestValidateTeardown(t *testing.T) {
+	for name, test := range map[string]struct {
+		cfg   Config
+		valid bool
+	}{
+		"default": {
+			cfg:   Config{},
+			valid: true,
+		},
+		"known policy": {
+			cfg:   Config{TeardownPolicy: "keep-on-test-failure", TeardownExpiry: map[string]string{"keep-on-test-failure": "12h"}},
+			valid: true,
+		},
+		"unknown policy": {
+			cfg: Config{TeardownPolicy: "keep-forever"},
+		},
+		"unknown expiry policy": {
+			cfg: Config{TeardownExpiry: map[string]string{"keep": "12h"}},
+		},
+		"invalid expiry": {
+			cfg: Config{TeardownExpiry: map[string]string{"never-destroy": "a day"}},
+		},
+	} {
+		if err := test.cfg.ValidateTeardown(); (err == nil) != test.valid {
+			t.Errorf("%s: expected valid to be %t, got error: %v", name, test.valid, err)
+		}
+	}
+}
@@ -10,8 +10,13 @@ var (
 	// sensitiveFields removed from output
 	sensitiveFields = []string{
 		"UHC_TOKEN",
+		"OCM_CLIENT_SECRET",
 		"TESTGRID_SERVICE_ACCOUNT",
 		"TEST_KUBECONFIG",
+		"MANAGEMENT_KUBECONFIG",
+		"SLACK_TOKEN",
+		"GCP_SERVICE_ACCOUNT",
+		"WEBHOOK_SECRET",
 	}
 )
 
@@ -42,3 +47,21 @@ func isSensitive(s string) bool {
 	}
 	return false
 }
+
+// Secrets returns the values of sensitive options which are set, so they can be masked in output.
+func (c *Config) Secrets() (secrets []string) {
+	v := reflect.ValueOf(c).Elem()
+	for i := 0; i < v.Type().NumField(); i++ {
+		f := v.Type().Field(i)
+		if env, ok := f.Tag.Lookup(EnvVarTag); ok && isSensitive(env) {
+			switch field := v.Field(i); {
+			case field.Kind() == reflect.String && field.String() != "":
+				secrets = append(secrets, field.String())
+			case field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.Uint8 && field.Len() > 0:
+				// kubeconfigs are stored as bytes
+				secrets = append(secrets, string(field.Bytes()))
+			}
+		}
+	}
+	return
+}
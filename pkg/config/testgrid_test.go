@@ -0,0 +1,19 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSecrets(t *testing.T) {
+	cfg := &Config{UHCToken: "ocm-token", SlackToken: "xoxb-token", Kubeconfig: []byte("kubeconfig-data"),
+		ClusterName: "not-secret"}
+	expected := []string{"ocm-token", "kubeconfig-data", "xoxb-token"}
+	if secrets := cfg.Secrets(); !reflect.DeepEqual(secrets, expected) {
+		t.Errorf("expected secrets %v, got %v", expected, secrets)
+	}
+
+	if _, ok := cfg.TestGrid()["UHC_TOKEN"]; ok {
+		t.Error("expected UHC_TOKEN to be removed from TestGrid metadata")
+	}
+}
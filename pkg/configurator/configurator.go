@@ -0,0 +1,323 @@
+package configurator
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	kubev1 "k8s.io/api/core/v1"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/osde2e/pkg/helper"
+)
+
+const (
+	// MonitoringNamespace contains the Alertmanager configuration.
+	MonitoringNamespace = "openshift-monitoring"
+
+	// AlertmanagerSecret holds the Alertmanager configuration in the key AlertmanagerKey.
+	AlertmanagerSecret = "alertmanager-main"
+
+	// AlertmanagerKey is the key of the Alertmanager configuration.
+	AlertmanagerKey = "alertmanager.yaml"
+
+	// ConfigNamespace contains configuration referenced by cluster-wide config resources.
+	ConfigNamespace = "openshift-config"
+
+	// TrustedCAConfigMap holds the additional trusted CA bundle.
+	TrustedCAConfigMap = "user-ca-bundle"
+
+	// TrustedCAKey is the key of the bundle in TrustedCAConfigMap.
+	TrustedCAKey = "ca-bundle.crt"
+
+	// MOTDPath is where the MOTD is written on nodes.
+	MOTDPath = "/etc/motd"
+
+	// roleLabel selects the MachineConfigPool a MachineConfig is applied to.
+	roleLabel = "machineconfiguration.openshift.io/role"
+)
+
+var (
+	// ProxyGVR is the resource of the cluster-wide proxy configuration.
+	ProxyGVR = schema.GroupVersionResource{Group: "config.openshift.io", Version: "v1", Resource: "proxies"}
+
+	// ConsoleNotificationGVR is the resource of web console banners.
+	ConsoleNotificationGVR = schema.GroupVersionResource{Group: "console.openshift.io", Version: "v1", Resource: "consolenotifications"}
+
+	// MachineConfigGVR is the resource of node configuration.
+	MachineConfigGVR = schema.GroupVersionResource{Group: "machineconfiguration.openshift.io", Version: "v1", Resource: "machineconfigs"}
+
+	// MachineConfigPoolGVR is the resource of groups of nodes sharing configuration.
+	MachineConfigPoolGVR = schema.GroupVersionResource{Group: "machineconfiguration.openshift.io", Version: "v1", Resource: "machineconfigpools"}
+
+	// NodeRoles have MachineConfigPools configured with the MOTD.
+	NodeRoles = []string{"master", "worker"}
+)
+
+// Apply configures the cluster using p, waiting up to timeout for nodes to be updated.
+func Apply(h *helper.H, p *Profile, timeout time.Duration) error {
+	log.Printf("Applying profile '%s'...", p.Name)
+
+	if len(p.AlertReceivers) != 0 {
+		if err := applyAlertReceivers(h, p.AlertReceivers); err != nil {
+			return err
+		}
+	}
+
+	if p.TrustedCA != "" || p.Proxy != nil {
+		if err := applyProxy(h, p.TrustedCA, p.Proxy); err != nil {
+			return err
+		}
+	}
+
+	for i, b := range p.Banners {
+		name := fmt.Sprintf("osde2e-%s-%d", p.Name, i)
+		if _, err := h.ApplyResource(ConsoleNotificationGVR, consoleNotification(name, b)); err != nil {
+			return err
+		}
+	}
+
+	if p.MOTD != "" {
+		if err := applyMOTD(h, p.Name, p.MOTD, timeout); err != nil {
+			return err
+		}
+	}
+
+	log.Printf("Applied profile '%s'.", p.Name)
+	return nil
+}
+
+// applyAlertReceivers adds receivers to the Alertmanager configuration.
+func applyAlertReceivers(h *helper.H, receivers []AlertReceiver) error {
+	secrets := h.Kube().CoreV1().Secrets(MonitoringNamespace)
+	secret, err := secrets.Get(AlertmanagerSecret, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("couldn't get Alertmanager config: %v", err)
+	}
+
+	if secret.Data[AlertmanagerKey], err = alertmanagerConfig(secret.Data[AlertmanagerKey], receivers); err != nil {
+		return err
+	}
+
+	if _, err = secrets.Update(secret); err != nil {
+		return fmt.Errorf("couldn't update Alertmanager config: %v", err)
+	}
+	return nil
+}
+
+// alertmanagerConfig returns the Alertmanager configuration data with receivers and routes to them added.
+func alertmanagerConfig(data []byte, receivers []AlertReceiver) ([]byte, error) {
+	cfg := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("couldn't decode Alertmanager config: %v", err)
+	}
+
+	route, _ := cfg["route"].(map[string]interface{})
+	if route == nil {
+		route = map[string]interface{}{}
+	}
+	routes, _ := route["routes"].([]interface{})
+	existing, _ := cfg["receivers"].([]interface{})
+
+	for _, r := range receivers {
+		receiver := map[string]interface{}{
+			"name": r.Name,
+		}
+		if r.WebhookURL != "" {
+			receiver["webhook_configs"] = []interface{}{
+				map[string]interface{}{"url": r.WebhookURL},
+			}
+		}
+		if r.SlackURL != "" {
+			receiver["slack_configs"] = []interface{}{
+				map[string]interface{}{"api_url": r.SlackURL, "channel": r.SlackChannel},
+			}
+		}
+		existing = append(existing, receiver)
+
+		// continue so alerts are still sent to existing receivers
+		rt := map[string]interface{}{
+			"receiver": r.Name,
+			"continue": true,
+		}
+		if len(r.Match) != 0 {
+			match := map[string]interface{}{}
+			for k, v := range r.Match {
+				match[k] = v
+			}
+			rt["match"] = match
+		}
+
+		// receivers are tried first so they aren't shadowed by existing routes
+		routes = append([]interface{}{rt}, routes...)
+	}
+
+	route["routes"] = routes
+	cfg["route"] = route
+	cfg["receivers"] = existing
+
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't encode Alertmanager config: %v", err)
+	}
+	return data, nil
+}
+
+// applyProxy configures the cluster-wide proxy and trusted CA bundle.
+func applyProxy(h *helper.H, trustedCA string, proxy *Proxy) error {
+	spec := map[string]interface{}{}
+	if trustedCA != "" {
+		configMaps := h.Kube().CoreV1().ConfigMaps(ConfigNamespace)
+		cm := &kubev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: TrustedCAConfigMap,
+			},
+			Data: map[string]string{
+				TrustedCAKey: trustedCA,
+			},
+		}
+
+		_, err := configMaps.Create(cm)
+		if kerror.IsAlreadyExists(err) {
+			_, err = configMaps.Update(cm)
+		}
+		if err != nil {
+			return fmt.Errorf("couldn't save trusted CA bundle: %v", err)
+		}
+
+		spec["trustedCA"] = map[string]interface{}{
+			"name": TrustedCAConfigMap,
+		}
+	}
+
+	if proxy != nil {
+		spec["httpProxy"] = proxy.HTTPProxy
+		spec["httpsProxy"] = proxy.HTTPSProxy
+		spec["noProxy"] = proxy.NoProxy
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{"spec": spec})
+	if err != nil {
+		return fmt.Errorf("couldn't encode proxy config: %v", err)
+	}
+
+	if _, err = h.Dynamic().Resource(ProxyGVR).Patch("cluster", types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		return fmt.Errorf("couldn't update proxy config: %v", err)
+	}
+	return nil
+}
+
+// consoleNotification returns a ConsoleNotification called name showing b.
+func consoleNotification(name string, b Banner) *unstructured.Unstructured {
+	location := b.Location
+	if location == "" {
+		location = "BannerTop"
+	}
+
+	spec := map[string]interface{}{
+		"text":     b.Text,
+		"location": location,
+	}
+	if b.Color != "" {
+		spec["color"] = b.Color
+	}
+	if b.BackgroundColor != "" {
+		spec["backgroundColor"] = b.BackgroundColor
+	}
+
+	obj := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": spec,
+		},
+	}
+	obj.SetAPIVersion("console.openshift.io/v1")
+	obj.SetKind("ConsoleNotification")
+	obj.SetName(name)
+	return obj
+}
+
+// applyMOTD writes motd to nodes of every role and waits for their pools to be updated.
+func applyMOTD(h *helper.H, profile, motd string, timeout time.Duration) error {
+	for _, role := range NodeRoles {
+		mc := motdMachineConfig(motdName(profile, role), role, motd)
+		if _, err := h.ApplyResource(MachineConfigGVR, mc); err != nil {
+			return err
+		}
+	}
+
+	for _, role := range NodeRoles {
+		if err := waitForPool(h, role, motdName(profile, role), timeout); err != nil {
+			return fmt.Errorf("nodes of role '%s' weren't updated with MOTD: %v", role, err)
+		}
+	}
+	return nil
+}
+
+// motdName is the name of the MachineConfig writing the MOTD of profile to nodes of role.
+func motdName(profile, role string) string {
+	return fmt.Sprintf("99-%s-osde2e-%s-motd", role, profile)
+}
+
+// motdMachineConfig returns a MachineConfig called name writing motd to nodes of role.
+func motdMachineConfig(name, role, motd string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"config": map[string]interface{}{
+					"ignition": map[string]interface{}{
+						"version": "2.2.0",
+					},
+					"storage": map[string]interface{}{
+						"files": []interface{}{
+							map[string]interface{}{
+								"filesystem": "root",
+								"path":       MOTDPath,
+								"mode":       int64(0644),
+								"contents": map[string]interface{}{
+									"source": "data:," + url.PathEscape(motd),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	obj.SetAPIVersion("machineconfiguration.openshift.io/v1")
+	obj.SetKind("MachineConfig")
+	obj.SetName(name)
+	obj.SetLabels(map[string]string{
+		roleLabel: role,
+	})
+	return obj
+}
+
+// waitForPool waits until the MachineConfigPool pool has rendered and rolled out the MachineConfig mc.
+func waitForPool(h *helper.H, pool, mc string, timeout time.Duration) error {
+	log.Printf("Waiting for MachineConfigPool '%s' to be updated with '%s'...", pool, mc)
+	return h.PollImmediate(30*time.Second, timeout, func() (bool, error) {
+		obj, err := h.GetResource(MachineConfigPoolGVR, "", pool)
+		if err != nil {
+			log.Print(err)
+			return false, nil
+		}
+
+		sources, _, _ := unstructured.NestedSlice(obj.Object, "status", "configuration", "source")
+		rendered := false
+		for _, s := range sources {
+			if source, ok := s.(map[string]interface{}); ok && source["name"] == mc {
+				rendered = true
+			}
+		}
+
+		status, _ := helper.ConditionStatus(obj, "Updated")
+		return rendered && status == string(kubev1.ConditionTrue), nil
+	})
+}
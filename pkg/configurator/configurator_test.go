@@ -0,0 +1,110 @@
+package configurator
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+func TestCustomerProfile(t *testing.T) {
+	p, err := LoadProfile("../../profiles/customer.yaml")
+	if err != nil {
+		t.Fatalf("Failed loading profile: %v", err)
+	}
+
+	if p.Name != "customer" || len(p.AlertReceivers) == 0 || p.TrustedCA == "" || p.MOTD == "" {
+		t.Errorf("customer profile is missing configuration: %+v", p)
+	}
+	if b := p.Budget; b == nil || b.ClusterLifetime() != 8*time.Hour || b.MaxComputeNodes != 9 || b.SuiteTime() != 3*time.Hour {
+		t.Errorf("expected customer profile to have a budget, got %+v", b)
+	}
+}
+
+func TestParseProfileInvalid(t *testing.T) {
+	for _, invalid := range []string{
+		"alertReceivers:\n- webhookURL: https://example.com\n",
+		"alertReceivers:\n- name: a\n",
+		"banners:\n- color: red\n",
+		"budget:\n  maxSuiteTime: 2 hours\n",
+		"budget:\n  maxClusterLifetime: -1h\n",
+		"budget:\n  maxComputeNodes: -1\n",
+	} {
+		if _, err := ParseProfile([]byte(invalid)); err == nil {
+			t.Errorf("profile should be invalid: %s", invalid)
+		}
+	}
+}
+
+func TestAlertmanagerConfig(t *testing.T) {
+	existing := `
+global:
+  resolve_timeout: 5m
+route:
+  receiver: default
+  routes:
+  - receiver: watchdog
+    match:
+      alertname: Watchdog
+receivers:
+- name: default
+- name: watchdog
+`
+	data, err := alertmanagerConfig([]byte(existing), []AlertReceiver{
+		{
+			Name:       "customer",
+			WebhookURL: "https://alerts.example.com",
+			Match:      map[string]string{"severity": "critical"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed updating config: %v", err)
+	}
+
+	var cfg struct {
+		Global    map[string]interface{}
+		Route     map[string]interface{}
+		Receivers []map[string]interface{}
+	}
+	if err = yaml.Unmarshal(data, &cfg); err != nil {
+		t.Fatalf("Failed decoding config: %v", err)
+	}
+
+	if cfg.Global["resolve_timeout"] != "5m" || cfg.Route["receiver"] != "default" {
+		t.Errorf("existing config wasn't kept: %s", data)
+	}
+
+	if len(cfg.Receivers) != 3 || cfg.Receivers[2]["name"] != "customer" {
+		t.Errorf("expected receiver to be added, got %v", cfg.Receivers)
+	}
+
+	routes, _ := cfg.Route["routes"].([]interface{})
+	expected := map[string]interface{}{
+		"receiver": "customer",
+		"continue": true,
+		"match":    map[string]interface{}{"severity": "critical"},
+	}
+	if len(routes) != 2 || !reflect.DeepEqual(routes[0], expected) {
+		t.Errorf("expected first route %v, got %v", expected, routes)
+	}
+}
+
+func TestMOTDMachineConfig(t *testing.T) {
+	mc := motdMachineConfig(motdName("customer", "worker"), "worker", "Authorized use only.\n")
+	if mc.GetName() != "99-worker-osde2e-customer-motd" || mc.GetLabels()[roleLabel] != "worker" {
+		t.Errorf("unexpected MachineConfig metadata: %v", mc.Object["metadata"])
+	}
+
+	files, _, _ := unstructured.NestedSlice(mc.Object, "spec", "config", "storage", "files")
+	if len(files) != 1 {
+		t.Fatalf("expected one file, got %v", files)
+	}
+
+	file := files[0].(map[string]interface{})
+	source := file["contents"].(map[string]interface{})["source"]
+	if file["path"] != MOTDPath || source != "data:,Authorized%20use%20only.%0A" {
+		t.Errorf("unexpected file %v", file)
+	}
+}
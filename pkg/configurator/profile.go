@@ -0,0 +1,133 @@
+// Package configurator applies day-2 configuration to clusters so they resemble those used by customers.
+package configurator
+
+import (
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Profile is day-2 configuration applied to a cluster after it's installed.
+type Profile struct {
+	// Name identifies the profile in logs.
+	Name string `json:"name"`
+
+	// AlertReceivers are added to Alertmanager.
+	AlertReceivers []AlertReceiver `json:"alertReceivers,omitempty"`
+
+	// TrustedCA is a PEM encoded bundle of additional certificate authorities trusted by the cluster.
+	TrustedCA string `json:"trustedCA,omitempty"`
+
+	// Proxy configures the cluster-wide proxy.
+	Proxy *Proxy `json:"proxy,omitempty"`
+
+	// Banners are shown in the web console.
+	Banners []Banner `json:"banners,omitempty"`
+
+	// MOTD is written to /etc/motd on all nodes.
+	MOTD string `json:"motd,omitempty"`
+
+	// Budget limits what runs configured with the profile may spend.
+	Budget *Budget `json:"budget,omitempty"`
+}
+
+// Budget limits what runs configured with a profile may spend. Limits which aren't set aren't enforced.
+type Budget struct {
+	// MaxClusterLifetime is the longest clusters may exist before expiring, such as '8h'.
+	MaxClusterLifetime string `json:"maxClusterLifetime,omitempty"`
+
+	// MaxComputeNodes is the most compute nodes clusters may have, including those of machine pools.
+	MaxComputeNodes int `json:"maxComputeNodes,omitempty"`
+
+	// MaxSuiteTime is the longest specs of all suites may run in total, such as '2h'. Specs are skipped once it's spent.
+	MaxSuiteTime string `json:"maxSuiteTime,omitempty"`
+}
+
+// ClusterLifetime returns the longest clusters may exist, which is 0 if it isn't limited.
+func (b *Budget) ClusterLifetime() time.Duration {
+	d, _ := time.ParseDuration(b.MaxClusterLifetime)
+	return d
+}
+
+// SuiteTime returns the longest suites may run in total, which is 0 if it isn't limited.
+func (b *Budget) SuiteTime() time.Duration {
+	d, _ := time.ParseDuration(b.MaxSuiteTime)
+	return d
+}
+
+// AlertReceiver receives alerts matching labels from Alertmanager.
+type AlertReceiver struct {
+	Name string `json:"name"`
+
+	// Match routes alerts with these labels to the receiver. All alerts are routed if empty.
+	Match map[string]string `json:"match,omitempty"`
+
+	// WebhookURL receives alerts as webhooks.
+	WebhookURL string `json:"webhookURL,omitempty"`
+
+	// SlackURL and SlackChannel send alerts to a Slack channel.
+	SlackURL     string `json:"slackURL,omitempty"`
+	SlackChannel string `json:"slackChannel,omitempty"`
+}
+
+// Proxy is the cluster-wide proxy configuration.
+type Proxy struct {
+	HTTPProxy  string `json:"httpProxy,omitempty"`
+	HTTPSProxy string `json:"httpsProxy,omitempty"`
+	NoProxy    string `json:"noProxy,omitempty"`
+}
+
+// Banner is a notification shown in the web console.
+type Banner struct {
+	Text string `json:"text"`
+
+	// Location is BannerTop, BannerBottom, or BannerTopBottom. Defaults to BannerTop.
+	Location        string `json:"location,omitempty"`
+	Color           string `json:"color,omitempty"`
+	BackgroundColor string `json:"backgroundColor,omitempty"`
+}
+
+// LoadProfile reads a YAML profile from file.
+func LoadProfile(file string) (*Profile, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read profile '%s': %v", file, err)
+	}
+	return ParseProfile(data)
+}
+
+// ParseProfile decodes and validates a YAML profile.
+func ParseProfile(data []byte) (*Profile, error) {
+	p := new(Profile)
+	if err := yaml.Unmarshal(data, p); err != nil {
+		return nil, fmt.Errorf("couldn't decode profile: %v", err)
+	}
+
+	for _, r := range p.AlertReceivers {
+		if r.Name == "" {
+			return nil, fmt.Errorf("alert receivers in profile '%s' must be named", p.Name)
+		} else if r.WebhookURL == "" && r.SlackURL == "" {
+			return nil, fmt.Errorf("alert receiver '%s' must have a webhook or Slack URL", r.Name)
+		}
+	}
+
+	for _, b := range p.Banners {
+		if b.Text == "" {
+			return nil, fmt.Errorf("banners in profile '%s' must have text", p.Name)
+		}
+	}
+
+	if b := p.Budget; b != nil {
+		for name, limit := range map[string]string{"maxClusterLifetime": b.MaxClusterLifetime, "maxSuiteTime": b.MaxSuiteTime} {
+			if d, err := time.ParseDuration(limit); limit != "" && (err != nil || d <= 0) {
+				return nil, fmt.Errorf("budget of profile '%s' has invalid %s '%s'", p.Name, name, limit)
+			}
+		}
+		if b.MaxComputeNodes < 0 {
+			return nil, fmt.Errorf("budget of profile '%s' has negative maxComputeNodes", p.Name)
+		}
+	}
+	return p, nil
+}
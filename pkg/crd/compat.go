@@ -0,0 +1,114 @@
+package crd
+
+import (
+	"fmt"
+	"sort"
+)
+
+// IncompatibilityType describes how a CRD changed incompatibly.
+type IncompatibilityType string
+
+const (
+	// RemovedCRD is a CRD that no longer exists.
+	RemovedCRD IncompatibilityType = "RemovedCRD"
+
+	// DroppedStoredVersion is a version used to store objects that is no longer defined.
+	DroppedStoredVersion IncompatibilityType = "DroppedStoredVersion"
+
+	// RemovedServedVersion is a version that is no longer served.
+	RemovedServedVersion IncompatibilityType = "RemovedServedVersion"
+
+	// RemovedField is a field no longer in the schema of a served version.
+	RemovedField IncompatibilityType = "RemovedField"
+)
+
+// Incompatibility is a change to a CRD that may break existing clients or objects.
+type Incompatibility struct {
+	Type    IncompatibilityType `json:"type"`
+	CRD     string              `json:"crd"`
+	Version string              `json:"version,omitempty"`
+	Field   string              `json:"field,omitempty"`
+}
+
+func (i Incompatibility) String() string {
+	switch i.Type {
+	case RemovedCRD:
+		return fmt.Sprintf("CRD '%s' was removed", i.CRD)
+	case DroppedStoredVersion:
+		return fmt.Sprintf("CRD '%s' dropped stored version '%s'", i.CRD, i.Version)
+	case RemovedServedVersion:
+		return fmt.Sprintf("CRD '%s' stopped serving version '%s'", i.CRD, i.Version)
+	default:
+		return fmt.Sprintf("CRD '%s' version '%s' removed field '%s'", i.CRD, i.Version, i.Field)
+	}
+}
+
+// Compare returns incompatible changes to platform CRDs from before to after.
+func Compare(before, after map[string]*CRD) (incompatible []Incompatibility) {
+	for name, old := range before {
+		if !old.Platform() {
+			continue
+		}
+
+		cur, ok := after[name]
+		if !ok {
+			incompatible = append(incompatible, Incompatibility{
+				Type: RemovedCRD,
+				CRD:  name,
+			})
+			continue
+		}
+
+		// objects stored in a version can't be read if it's removed
+		for _, stored := range old.StoredVersions {
+			if _, ok := cur.Version(stored); !ok {
+				incompatible = append(incompatible, Incompatibility{
+					Type:    DroppedStoredVersion,
+					CRD:     name,
+					Version: stored,
+				})
+			}
+		}
+
+		for _, oldVersion := range old.Versions {
+			if !oldVersion.Served {
+				continue
+			}
+
+			curVersion, ok := cur.Version(oldVersion.Name)
+			if !ok || !curVersion.Served {
+				incompatible = append(incompatible, Incompatibility{
+					Type:    RemovedServedVersion,
+					CRD:     name,
+					Version: oldVersion.Name,
+				})
+				continue
+			}
+
+			// schemas without fields aren't validated
+			if len(curVersion.Fields) == 0 {
+				continue
+			}
+
+			curFields := make(map[string]bool, len(curVersion.Fields))
+			for _, f := range curVersion.Fields {
+				curFields[f] = true
+			}
+			for _, f := range oldVersion.Fields {
+				if !curFields[f] {
+					incompatible = append(incompatible, Incompatibility{
+						Type:    RemovedField,
+						CRD:     name,
+						Version: oldVersion.Name,
+						Field:   f,
+					})
+				}
+			}
+		}
+	}
+
+	sort.Slice(incompatible, func(i, j int) bool {
+		return incompatible[i].String() < incompatible[j].String()
+	})
+	return
+}
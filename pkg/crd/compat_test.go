@@ -0,0 +1,103 @@
+package crd
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func testCRD(name, group string, stored []interface{}, versions ...interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"group":    group,
+				"versions": versions,
+			},
+			"status": map[string]interface{}{
+				"storedVersions": stored,
+			},
+		},
+	}
+	obj.SetName(name)
+	return obj
+}
+
+func testVersion(name string, served bool, fields ...string) map[string]interface{} {
+	props := map[string]interface{}{}
+	for _, f := range fields {
+		props[f] = map[string]interface{}{"type": "string"}
+	}
+	return map[string]interface{}{
+		"name":    name,
+		"served":  served,
+		"storage": true,
+		"schema": map[string]interface{}{
+			"openAPIV3Schema": map[string]interface{}{
+				"properties": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"properties": props,
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestParse(t *testing.T) {
+	crd, err := Parse(testCRD("widgets.config.openshift.io", "config.openshift.io", []interface{}{"v1"}, testVersion("v1", true, "size", "color")))
+	if err != nil {
+		t.Fatalf("Failed parsing CRD: %v", err)
+	}
+
+	if !crd.Platform() {
+		t.Error("config.openshift.io CRDs should be platform CRDs")
+	}
+
+	expected := []string{"spec", "spec.color", "spec.size"}
+	if v, ok := crd.Version("v1"); !ok || !reflect.DeepEqual(v.Fields, expected) {
+		t.Errorf("expected fields %v, got %v", expected, v.Fields)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	parse := func(obj *unstructured.Unstructured) *CRD {
+		crd, err := Parse(obj)
+		if err != nil {
+			t.Fatalf("Failed parsing CRD: %v", err)
+		}
+		return crd
+	}
+
+	before := map[string]*CRD{
+		"a.config.openshift.io": parse(testCRD("a.config.openshift.io", "config.openshift.io", []interface{}{"v1alpha1", "v1"},
+			testVersion("v1alpha1", true, "size"), testVersion("v1", true, "size", "color"))),
+		"b.config.openshift.io": parse(testCRD("b.config.openshift.io", "config.openshift.io", nil, testVersion("v1", true))),
+		"c.example.com":         parse(testCRD("c.example.com", "example.com", nil, testVersion("v1", true))),
+	}
+	after := map[string]*CRD{
+		"a.config.openshift.io": parse(testCRD("a.config.openshift.io", "config.openshift.io", []interface{}{"v1"},
+			testVersion("v1", true, "size", "shape"))),
+	}
+
+	expected := []Incompatibility{
+		{Type: RemovedCRD, CRD: "b.config.openshift.io"},
+		{Type: DroppedStoredVersion, CRD: "a.config.openshift.io", Version: "v1alpha1"},
+		{Type: RemovedServedVersion, CRD: "a.config.openshift.io", Version: "v1alpha1"},
+		{Type: RemovedField, CRD: "a.config.openshift.io", Version: "v1", Field: "spec.color"},
+	}
+
+	incompatible := Compare(before, after)
+	if len(incompatible) != len(expected) {
+		t.Fatalf("expected incompatibilities %v, got %v", expected, incompatible)
+	}
+	for _, e := range expected {
+		found := false
+		for _, i := range incompatible {
+			found = found || i == e
+		}
+		if !found {
+			t.Errorf("expected incompatibility %v in %v", e, incompatible)
+		}
+	}
+}
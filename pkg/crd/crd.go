@@ -0,0 +1,180 @@
+// Package crd records CustomResourceDefinition schemas and checks they remain compatible across upgrades.
+package crd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/openshift/osde2e/pkg/helper"
+)
+
+var (
+	// GVR is the resource of CustomResourceDefinitions.
+	GVR = schema.GroupVersionResource{
+		Group:    "apiextensions.k8s.io",
+		Version:  "v1beta1",
+		Resource: "customresourcedefinitions",
+	}
+
+	// PlatformGroups are suffixes of API groups for CRDs provided by the platform.
+	PlatformGroups = []string{
+		"openshift.io",
+		"coreos.com",
+		"k8s.io",
+		"metal3.io",
+	}
+)
+
+// CRD is the schema of a CustomResourceDefinition.
+type CRD struct {
+	Name  string `json:"name"`
+	Group string `json:"group"`
+
+	// StoredVersions have been used to persist objects.
+	StoredVersions []string `json:"storedVersions"`
+
+	// Versions are all versions defined.
+	Versions []Version `json:"versions"`
+}
+
+// Version is a version of a CRD and the fields of its schema.
+type Version struct {
+	Name    string `json:"name"`
+	Served  bool   `json:"served"`
+	Storage bool   `json:"storage"`
+
+	// Fields are paths of every property in the schema.
+	Fields []string `json:"fields"`
+}
+
+// Platform returns true if the CRD is provided by the platform.
+func (c *CRD) Platform() bool {
+	for _, suffix := range PlatformGroups {
+		if c.Group == suffix || strings.HasSuffix(c.Group, "."+suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Version returns the named version if it exists.
+func (c *CRD) Version(name string) (Version, bool) {
+	for _, v := range c.Versions {
+		if v.Name == name {
+			return v, true
+		}
+	}
+	return Version{}, false
+}
+
+// Snapshot returns the schemas of every CRD in the cluster by name.
+func Snapshot(h *helper.H) (map[string]*CRD, error) {
+	list, err := h.Dynamic().Resource(GVR).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("couldn't list CRDs: %v", err)
+	}
+
+	crds := make(map[string]*CRD, len(list.Items))
+	for i := range list.Items {
+		crd, err := Parse(&list.Items[i])
+		if err != nil {
+			return nil, err
+		}
+		crds[crd.Name] = crd
+	}
+	return crds, nil
+}
+
+// Parse returns the schema of a CRD object.
+func Parse(obj *unstructured.Unstructured) (*CRD, error) {
+	crd := &CRD{
+		Name: obj.GetName(),
+	}
+
+	var err error
+	if crd.Group, _, err = unstructured.NestedString(obj.Object, "spec", "group"); err != nil {
+		return nil, fmt.Errorf("couldn't get group of CRD '%s': %v", crd.Name, err)
+	}
+	if crd.StoredVersions, _, err = unstructured.NestedStringSlice(obj.Object, "status", "storedVersions"); err != nil {
+		return nil, fmt.Errorf("couldn't get stored versions of CRD '%s': %v", crd.Name, err)
+	}
+
+	// schema shared by all versions
+	shared, _, err := unstructured.NestedMap(obj.Object, "spec", "validation", "openAPIV3Schema")
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get schema of CRD '%s': %v", crd.Name, err)
+	}
+
+	versions, found, err := unstructured.NestedSlice(obj.Object, "spec", "versions")
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get versions of CRD '%s': %v", crd.Name, err)
+	}
+
+	// older CRDs only specify a single version
+	if !found {
+		version, _, err := unstructured.NestedString(obj.Object, "spec", "version")
+		if err != nil {
+			return nil, fmt.Errorf("couldn't get version of CRD '%s': %v", crd.Name, err)
+		}
+		versions = []interface{}{
+			map[string]interface{}{"name": version, "served": true, "storage": true},
+		}
+	}
+
+	for _, v := range versions {
+		vMap, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unexpected version of CRD '%s': %v", crd.Name, v)
+		}
+
+		version := Version{}
+		version.Name, _, _ = unstructured.NestedString(vMap, "name")
+		version.Served, _, _ = unstructured.NestedBool(vMap, "served")
+		version.Storage, _, _ = unstructured.NestedBool(vMap, "storage")
+
+		versionSchema, found, _ := unstructured.NestedMap(vMap, "schema", "openAPIV3Schema")
+		if !found {
+			versionSchema = shared
+		}
+		version.Fields = Fields(versionSchema)
+		crd.Versions = append(crd.Versions, version)
+	}
+	return crd, nil
+}
+
+// Fields returns the paths of every property in an OpenAPI v3 schema, sorted.
+func Fields(openAPISchema map[string]interface{}) []string {
+	fields := []string{}
+	collectFields(openAPISchema, "", &fields)
+	sort.Strings(fields)
+	return fields
+}
+
+func collectFields(s map[string]interface{}, path string, fields *[]string) {
+	if props, ok := s["properties"].(map[string]interface{}); ok {
+		for name, prop := range props {
+			fieldPath := name
+			if path != "" {
+				fieldPath = path + "." + name
+			}
+			*fields = append(*fields, fieldPath)
+
+			if propSchema, ok := prop.(map[string]interface{}); ok {
+				collectFields(propSchema, fieldPath, fields)
+			}
+		}
+	}
+
+	if items, ok := s["items"].(map[string]interface{}); ok {
+		collectFields(items, path+"[]", fields)
+	}
+
+	if additional, ok := s["additionalProperties"].(map[string]interface{}); ok {
+		collectFields(additional, path+".*", fields)
+	}
+}
@@ -0,0 +1,163 @@
+// Package debug pauses failed runs before their cluster is torn down so developers can inspect it in the state
+// it failed in.
+package debug
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	ginkgoconfig "github.com/onsi/ginkgo/config"
+	"github.com/onsi/ginkgo/types"
+
+	"github.com/openshift/osde2e/pkg/artifacts"
+	"github.com/openshift/osde2e/pkg/redact"
+)
+
+// Recorder is a Ginkgo reporter recording failures setting up and running specs.
+type Recorder struct {
+	mu          sync.Mutex
+	failures    []string
+	failedSpecs []string
+	setupFailed bool
+	testsFailed bool
+}
+
+// Failures returns a description of each failure recorded so far.
+func (r *Recorder) Failures() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.failures...)
+}
+
+// FailedSpecs returns the full names of the specs which failed so far.
+func (r *Recorder) FailedSpecs() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.failedSpecs...)
+}
+
+// SetupFailed returns true if setting up specs failed, such as when the cluster couldn't be provisioned.
+func (r *Recorder) SetupFailed() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.setupFailed
+}
+
+// TestsFailed returns true if any spec failed.
+func (r *Recorder) TestsFailed() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.testsFailed
+}
+
+func (r *Recorder) add(failure string, setup bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failures = append(r.failures, redact.Current.String(failure))
+	if setup {
+		r.setupFailed = true
+	} else {
+		r.testsFailed = true
+	}
+}
+
+// SpecSuiteWillBegin does nothing.
+func (r *Recorder) SpecSuiteWillBegin(config ginkgoconfig.GinkgoConfigType, summary *types.SuiteSummary) {
+}
+
+// BeforeSuiteDidRun records setup failing.
+func (r *Recorder) BeforeSuiteDidRun(summary *types.SetupSummary) {
+	if summary.State.IsFailure() {
+		r.add("setup: "+summary.Failure.Message, true)
+	}
+}
+
+// SpecWillRun does nothing.
+func (r *Recorder) SpecWillRun(summary *types.SpecSummary) {}
+
+// SpecDidComplete records the spec if it failed.
+func (r *Recorder) SpecDidComplete(summary *types.SpecSummary) {
+	if !summary.State.IsFailure() {
+		return
+	}
+
+	// the first component is the top level container
+	texts := summary.ComponentTexts
+	if len(texts) > 1 {
+		texts = texts[1:]
+	}
+	name := strings.Join(texts, " ")
+	r.add(name+": "+summary.Failure.Message, false)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failedSpecs = append(r.failedSpecs, name)
+}
+
+// AfterSuiteDidRun does nothing.
+func (r *Recorder) AfterSuiteDidRun(summary *types.SetupSummary) {}
+
+// SpecSuiteDidEnd does nothing.
+func (r *Recorder) SpecSuiteDidEnd(summary *types.SuiteSummary) {}
+
+// Info describes the cluster to developers inspecting it.
+type Info struct {
+	ClusterID   string
+	ClusterName string
+	Version     string
+
+	// Kubeconfig is the path of the encrypted kubeconfig artifact of the cluster. When it isn't set, the kubeconfig is
+	// looked up in OCM.
+	Kubeconfig string
+}
+
+// Pause writes info and failures to out then waits for a line to be read from in or until timeout passes.
+// It returns true if it was resumed by input.
+func Pause(out io.Writer, in io.Reader, info Info, failures []string, timeout time.Duration) bool {
+	fmt.Fprintf(out, "\n%d failures occurred, pausing before teardown:\n", len(failures))
+	for _, f := range failures {
+		fmt.Fprintf(out, "  - %s\n", f)
+	}
+
+	fmt.Fprintln(out, "\nThe cluster is available for inspection:")
+	for _, field := range []struct{ name, value string }{
+		{"Cluster ID", info.ClusterID},
+		{"Name", info.ClusterName},
+		{"Version", info.Version},
+	} {
+		if field.value != "" {
+			fmt.Fprintf(out, "  %-11s %s\n", field.name+":", field.value)
+		}
+	}
+	if info.Kubeconfig != "" {
+		decrypted := strings.TrimSuffix(strings.TrimSuffix(info.Kubeconfig, artifacts.EncryptedExt), artifacts.Ext)
+		fmt.Fprintf(out, "\n  osde2e-decrypt -key <private key> %s\n", info.Kubeconfig)
+		fmt.Fprintf(out, "  export KUBECONFIG=%s\n", decrypted)
+	} else if info.ClusterID != "" {
+		fmt.Fprintf(out, "\n  ocm get /api/clusters_mgmt/v1/clusters/%s/credentials | jq -r .kubeconfig > kubeconfig\n",
+			info.ClusterID)
+		fmt.Fprintln(out, "  export KUBECONFIG=$PWD/kubeconfig")
+	}
+
+	fmt.Fprintf(out, "\nPress enter to continue teardown, or wait %v...\n", timeout)
+
+	// the line is read in the background as readers can't be interrupted
+	resumed := make(chan struct{})
+	go func() {
+		if _, err := bufio.NewReader(in).ReadString('\n'); err == nil {
+			close(resumed)
+		}
+	}()
+
+	select {
+	case <-resumed:
+		return true
+	case <-time.After(timeout):
+		fmt.Fprintln(out, "Timed out waiting for input, continuing teardown.")
+		return false
+	}
+}
@@ -0,0 +1,65 @@
+package debug
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/onsi/ginkgo/types"
+)
+
+func TestRecorder(t *testing.T) {
+	r := new(Recorder)
+	r.BeforeSuiteDidRun(&types.SetupSummary{State: types.SpecStatePassed})
+	r.SpecDidComplete(&types.SpecSummary{
+		ComponentTexts: []string{"[Top Level]", "Pods", "should be Running"},
+		State:          types.SpecStatePassed,
+	})
+	r.SpecDidComplete(&types.SpecSummary{
+		ComponentTexts: []string{"[Top Level]", "Routes", "should be admitted"},
+		State:          types.SpecStateFailed,
+		Failure:        types.SpecFailure{Message: "route wasn't admitted"},
+	})
+
+	expected := []string{"Routes should be admitted: route wasn't admitted"}
+	if failures := r.Failures(); len(failures) != 1 || failures[0] != expected[0] {
+		t.Errorf("expected failures %v, got %v", expected, failures)
+	}
+
+	if specs := r.FailedSpecs(); len(specs) != 1 || specs[0] != "Routes should be admitted" {
+		t.Errorf("expected failed specs [Routes should be admitted], got %v", specs)
+	}
+
+	if r.SetupFailed() || !r.TestsFailed() {
+		t.Errorf("expected only tests to have failed, got setup %t and tests %t", r.SetupFailed(), r.TestsFailed())
+	}
+}
+
+func TestPause(t *testing.T) {
+	info := Info{ClusterID: "1a2b3c", Kubeconfig: "/tmp/report/kubeconfig.zst.enc"}
+	failures := []string{"setup: failed performing upgrade"}
+
+	var out bytes.Buffer
+	if !Pause(&out, strings.NewReader("\n"), info, failures, time.Minute) {
+		t.Error("expected input to resume")
+	}
+	for _, s := range []string{"1a2b3c", "osde2e-decrypt -key <private key> /tmp/report/kubeconfig.zst.enc",
+		"export KUBECONFIG=/tmp/report/kubeconfig\n", failures[0]} {
+		if !strings.Contains(out.String(), s) {
+			t.Errorf("expected output to contain '%s': %s", s, out.String())
+		}
+	}
+
+	// without an encrypted kubeconfig it's looked up in OCM
+	out.Reset()
+	Pause(&out, strings.NewReader("\n"), Info{ClusterID: "1a2b3c"}, failures, time.Minute)
+	if !strings.Contains(out.String(), "ocm get /api/clusters_mgmt/v1/clusters/1a2b3c/credentials") {
+		t.Errorf("expected output to explain getting the kubeconfig from OCM: %s", out.String())
+	}
+
+	// no input is available
+	if Pause(&out, new(bytes.Buffer), info, failures, 10*time.Millisecond) {
+		t.Error("expected timeout to resume")
+	}
+}
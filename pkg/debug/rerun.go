@@ -0,0 +1,62 @@
+package debug
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// RerunFile is the name of the file in the report directory recording what failed for the next run to rerun.
+const RerunFile = "rerun.json"
+
+// Rerun records the specs which failed in a run and the cluster they failed on, so they can be run again alone.
+type Rerun struct {
+	ClusterID string `json:"clusterID,omitempty"`
+
+	// Specs are the full names of the failed specs.
+	Specs []string `json:"specs"`
+}
+
+// Focus returns a Ginkgo focus string matching only the specs of r. Names are anchored at their end so specs named
+// with a prefix of another's aren't both run.
+func (r *Rerun) Focus() string {
+	quoted := make([]string, len(r.Specs))
+	for i, s := range r.Specs {
+		quoted[i] = regexp.QuoteMeta(s)
+	}
+	return "(" + strings.Join(quoted, "|") + ")$"
+}
+
+// Write stores r in dir as RerunFile.
+func (r *Rerun) Write(dir string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("couldn't encode failed specs: %v", err)
+	}
+
+	os.MkdirAll(dir, os.ModePerm)
+	filename := filepath.Join(dir, RerunFile)
+	if err = ioutil.WriteFile(filename, data, os.ModePerm); err != nil {
+		return fmt.Errorf("couldn't write failed specs to '%s': %v", filename, err)
+	}
+	return nil
+}
+
+// LoadRerun reads the specs which failed in the last run reporting to dir.
+func LoadRerun(dir string) (*Rerun, error) {
+	filename := filepath.Join(dir, RerunFile)
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read failed specs of the last run: %v", err)
+	}
+
+	r := new(Rerun)
+	if err = json.Unmarshal(data, r); err != nil {
+		return nil, fmt.Errorf("couldn't decode failed specs in '%s': %v", filename, err)
+	}
+	return r, nil
+}
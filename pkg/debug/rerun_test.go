@@ -0,0 +1,45 @@
+package debug
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestRerun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rerun")
+	if err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if _, err = LoadRerun(dir); err == nil {
+		t.Error("expected an error loading failed specs which weren't written")
+	}
+
+	r := &Rerun{ClusterID: "abc", Specs: []string{"Routes should be admitted", "Pods (privileged) should fail"}}
+	if err = r.Write(dir); err != nil {
+		t.Fatalf("failed to write failed specs: %v", err)
+	}
+
+	loaded, err := LoadRerun(dir)
+	if err != nil {
+		t.Fatalf("failed to load failed specs: %v", err)
+	} else if !reflect.DeepEqual(loaded, r) {
+		t.Errorf("expected %+v, got %+v", r, loaded)
+	}
+
+	focus := regexp.MustCompile(loaded.Focus())
+	for name, expected := range map[string]bool{
+		"[Top Level] Routes should be admitted":          true,
+		"[Top Level] Pods (privileged) should fail":      true,
+		"[Top Level] Routes should be admitted with TLS": false,
+		"[Top Level] Pods should be Running":             false,
+	} {
+		if focus.MatchString(name) != expected {
+			t.Errorf("expected focus %s to match '%s': %t", focus, name, expected)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// Package dns times lookups made from pods, so the latency of cluster DNS can be checked under light load.
+package dns
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Results of each lookup printed by LookupScript.
+const (
+	lookupOK     = "ok"
+	lookupFailed = "failed"
+)
+
+// LookupScript returns a shell script resolving name n times with getent, split between parallel loops. Each lookup
+// prints whether it succeeded and how many nanoseconds it took.
+func LookupScript(name string, n, parallel int) string {
+	if parallel < 1 {
+		parallel = 1
+	}
+	perLoop := (n + parallel - 1) / parallel
+	return fmt.Sprintf(`
+lookup() {
+  for i in $(seq 1 %d); do
+    start=$(date +%%s%%N)
+    if getent hosts %q >/dev/null; then result=%s; else result=%s; fi
+    echo "$result $(( $(date +%%s%%N) - start ))"
+  done
+}
+for p in $(seq 1 %d); do lookup & done
+wait
+`, perLoop, name, lookupOK, lookupFailed, parallel)
+}
+
+// Lookups are the outcome of lookups timed by LookupScript.
+type Lookups struct {
+	// Latencies are how long each lookup took, sorted.
+	Latencies []time.Duration
+
+	// Failed is how many lookups didn't resolve.
+	Failed int
+}
+
+// ParseLookups reads the output of LookupScript.
+func ParseLookups(out []byte) (*Lookups, error) {
+	l := new(Lookups)
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		} else if len(fields) != 2 {
+			return nil, fmt.Errorf("unexpected lookup result '%s'", scanner.Text())
+		}
+
+		ns, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid lookup latency '%s': %v", fields[1], err)
+		}
+		switch fields[0] {
+		case lookupOK:
+			l.Latencies = append(l.Latencies, time.Duration(ns))
+		case lookupFailed:
+			l.Failed++
+		default:
+			return nil, fmt.Errorf("unknown lookup result '%s'", fields[0])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(l.Latencies, func(i, j int) bool { return l.Latencies[i] < l.Latencies[j] })
+	return l, nil
+}
+
+// Total is how many lookups were made.
+func (l *Lookups) Total() int {
+	return len(l.Latencies) + l.Failed
+}
+
+// Percentile returns the latency p percent of successful lookups completed within, using the nearest rank.
+func (l *Lookups) Percentile(p float64) time.Duration {
+	if len(l.Latencies) == 0 {
+		return 0
+	}
+	rank := int(p/100*float64(len(l.Latencies))+0.5) - 1
+	if rank < 0 {
+		rank = 0
+	} else if rank >= len(l.Latencies) {
+		rank = len(l.Latencies) - 1
+	}
+	return l.Latencies[rank]
+}
@@ -0,0 +1,63 @@
+package dns
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseLookups(t *testing.T) {
+	out := []byte(`ok 3000000
+ok 1000000
+failed 5000000000
+
+ok 2000000
+ok 4000000
+`)
+	l, err := ParseLookups(out)
+	if err != nil {
+		t.Fatalf("failed to parse lookups: %v", err)
+	}
+	if l.Total() != 5 || l.Failed != 1 {
+		t.Errorf("expected 1 of 5 lookups to fail, got %d of %d", l.Failed, l.Total())
+	}
+	for p, expected := range map[float64]time.Duration{
+		50: 2 * time.Millisecond,
+		99: 4 * time.Millisecond,
+		0:  time.Millisecond,
+	} {
+		if actual := l.Percentile(p); actual != expected {
+			t.Errorf("expected p%v of %v, got %v", p, expected, actual)
+		}
+	}
+
+	for _, invalid := range []string{"ok", "ok 1ms", "timeout 1000"} {
+		if _, err = ParseLookups([]byte(invalid)); err == nil {
+			t.Errorf("expected '%s' to be invalid", invalid)
+		}
+	}
+
+	if p := new(Lookups).Percentile(99); p != 0 {
+		t.Errorf("expected no latency without lookups, got %v", p)
+	}
+}
+
+func TestLookupScript(t *testing.T) {
+	if _, err := exec.LookPath("getent"); err != nil {
+		t.Skip("getent isn't installed")
+	}
+
+	out, err := exec.Command("sh", "-c", LookupScript("localhost", 10, 3)).Output()
+	if err != nil {
+		t.Fatalf("failed to run lookups: %v", err)
+	}
+	l, err := ParseLookups(out)
+	if err != nil {
+		t.Fatalf("failed to parse lookups: %s: %v", out, err)
+	}
+	if l.Total() != 12 || l.Failed != 0 {
+		t.Errorf("expected 12 successful lookups, got %d of %d failing: %s", l.Failed, l.Total(),
+			strings.TrimSpace(string(out)))
+	}
+}
@@ -0,0 +1,18 @@
+//go:build !windows
+// +build !windows
+
+package doctor
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// freeSpace returns the bytes available to unprivileged users in dir.
+func freeSpace(dir string) (int64, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(dir, &stat); err != nil {
+		return 0, fmt.Errorf("couldn't get free space of '%s': %v", dir, err)
+	}
+	return int64(stat.Bavail) * int64(stat.Bsize), nil
+}
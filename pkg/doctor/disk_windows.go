@@ -0,0 +1,8 @@
+package doctor
+
+import "errors"
+
+// freeSpace isn't supported on Windows.
+func freeSpace(dir string) (int64, error) {
+	return 0, errors.New("free space can't be checked on Windows")
+}
@@ -0,0 +1,287 @@
+// Package doctor checks the local environment can run osde2e: OCM accepts its credentials, AWS credentials work when
+// they're needed, TEST_KUBECONFIG reaches its cluster, the binaries it's configured to run are installed, and there's
+// room for artifacts. osde2e-doctor reports the checks so problems are found before a run rather than during it.
+package doctor
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/openshift/osde2e/pkg/config"
+	"github.com/openshift/osde2e/pkg/imagescan"
+	"github.com/openshift/osde2e/pkg/osd"
+)
+
+// Checks of the environment.
+const (
+	CheckOCM        = "ocm"
+	CheckAWS        = "aws"
+	CheckKubeconfig = "kubeconfig"
+	CheckBinaries   = "binaries"
+	CheckDiskSpace  = "disk-space"
+)
+
+// Statuses of checks.
+const (
+	StatusPass = "pass"
+	StatusFail = "fail"
+
+	// StatusSkip checks aren't needed by the configuration, such as AWS credentials when nothing uses AWS.
+	StatusSkip = "skip"
+)
+
+// DefaultMinFreeSpace is the least free space for artifacts which passes.
+var DefaultMinFreeSpace = resource.MustParse("5Gi")
+
+// Result is the outcome of a check.
+type Result struct {
+	Check  string `json:"check"`
+	Status string `json:"status"`
+	Detail string `json:"detail"`
+}
+
+// Report is the outcome of all checks. It passes if no check failed.
+type Report struct {
+	Passed  bool     `json:"passed"`
+	Results []Result `json:"results"`
+}
+
+// Doctor checks the environment of Config. Its functions reach outside of osde2e and default to the real thing.
+type Doctor struct {
+	// Config has had its kubeconfig read from TEST_KUBECONFIG with ReadKubeconfig.
+	Config *config.Config
+
+	// KubeconfigErr is why the kubeconfig of Config couldn't be read, failing its check.
+	KubeconfigErr error
+
+	// MinFreeSpace is the least free space for artifacts, in bytes.
+	MinFreeSpace int64
+
+	// Identity returns who the credentials of cfg are in OCM.
+	Identity func(cfg *config.Config) (string, error)
+
+	// AWSIdentity returns who the AWS credentials of the environment are, and whether there are any.
+	AWSIdentity func(region string) (identity string, found bool, err error)
+
+	// ServerVersion returns the version of the cluster accessed with kubeconfig.
+	ServerVersion func(kubeconfig []byte) (string, error)
+
+	// LookPath finds binaries like exec.LookPath.
+	LookPath func(file string) (string, error)
+
+	// FreeSpace returns the bytes available in dir.
+	FreeSpace func(dir string) (int64, error)
+}
+
+// New returns a Doctor of the environment of cfg.
+func New(cfg *config.Config) *Doctor {
+	return &Doctor{
+		Config:        cfg,
+		MinFreeSpace:  DefaultMinFreeSpace.Value(),
+		Identity:      ocmIdentity,
+		AWSIdentity:   awsIdentity,
+		ServerVersion: serverVersion,
+		LookPath:      exec.LookPath,
+		FreeSpace:     freeSpace,
+	}
+}
+
+// Run performs every check.
+func (d *Doctor) Run() *Report {
+	r := &Report{Passed: true}
+	for _, check := range []func() Result{d.ocm, d.aws, d.kubeconfig, d.binaries, d.diskSpace} {
+		res := check()
+		if res.Status == StatusFail {
+			r.Passed = false
+		}
+		r.Results = append(r.Results, res)
+	}
+	return r
+}
+
+// ocm checks OCM accepts the credentials of the configuration.
+func (d *Doctor) ocm() Result {
+	res := Result{Check: CheckOCM}
+	cfg := d.Config
+	switch {
+	case cfg.Provider != config.ProviderOSD || cfg.ProviderPlugin != "":
+		res.Status, res.Detail = StatusSkip, "clusters aren't managed by OSD"
+	case !osd.IsFedRAMP(cfg.OSDEnv) && cfg.UHCToken == "":
+		res.Status, res.Detail = StatusFail, "UHC_TOKEN isn't set"
+	default:
+		identity, err := d.Identity(cfg)
+		if err != nil {
+			res.Status, res.Detail = StatusFail, err.Error()
+		} else {
+			res.Status, res.Detail = StatusPass, fmt.Sprintf("authenticated to %s as %s", osd.Environments.Choose(cfg.OSDEnv), identity)
+		}
+	}
+	return res
+}
+
+// aws checks the AWS credentials of the environment, which are required when verifying infrastructure on AWS.
+func (d *Doctor) aws() Result {
+	res := Result{Check: CheckAWS}
+	cfg := d.Config
+	needed := cfg.CloudVerification && (cfg.CloudProvider == "" || cfg.CloudProvider == config.DefaultCloudProvider)
+
+	identity, found, err := d.AWSIdentity(cfg.Region)
+	switch {
+	case !found && !needed:
+		res.Status, res.Detail = StatusSkip, "no credentials, which aren't needed without CLOUD_VERIFICATION on aws"
+	case !found:
+		res.Status, res.Detail = StatusFail, "CLOUD_VERIFICATION needs AWS credentials, such as AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY"
+	case err != nil:
+		res.Status, res.Detail = StatusFail, err.Error()
+	default:
+		res.Status, res.Detail = StatusPass, "authenticated as "+identity
+	}
+	return res
+}
+
+// kubeconfig checks TEST_KUBECONFIG reaches its cluster.
+func (d *Doctor) kubeconfig() Result {
+	res := Result{Check: CheckKubeconfig}
+	if d.KubeconfigErr != nil {
+		res.Status, res.Detail = StatusFail, d.KubeconfigErr.Error()
+		return res
+	} else if len(d.Config.Kubeconfig) == 0 {
+		res.Status, res.Detail = StatusSkip, "TEST_KUBECONFIG isn't set, clusters are accessed through their provider"
+		return res
+	}
+
+	version, err := d.ServerVersion(d.Config.Kubeconfig)
+	if err != nil {
+		res.Status, res.Detail = StatusFail, err.Error()
+	} else {
+		res.Status, res.Detail = StatusPass, "connected to Kubernetes "+version
+	}
+	return res
+}
+
+// binaries checks the binaries the configuration runs can be found.
+func (d *Doctor) binaries() Result {
+	res := Result{Check: CheckBinaries}
+	required := Binaries(d.Config)
+	if len(required) == 0 {
+		res.Status, res.Detail = StatusPass, "none required"
+		return res
+	}
+
+	var missing []string
+	for _, bin := range required {
+		if _, err := d.LookPath(bin); err != nil {
+			missing = append(missing, bin)
+		}
+	}
+	if len(missing) != 0 {
+		res.Status, res.Detail = StatusFail, "couldn't find "+strings.Join(missing, ", ")
+	} else {
+		res.Status, res.Detail = StatusPass, "found "+strings.Join(required, ", ")
+	}
+	return res
+}
+
+// diskSpace checks there's room for artifacts in the report directory.
+func (d *Doctor) diskSpace() Result {
+	res := Result{Check: CheckDiskSpace}
+	dir := d.Config.ReportDir
+	if dir == "" {
+		dir = os.TempDir()
+	}
+
+	// the report directory is created by runs, so check where it will be
+	for {
+		if _, err := os.Stat(dir); err == nil || filepath.Dir(dir) == dir {
+			break
+		}
+		dir = filepath.Dir(dir)
+	}
+
+	free, err := d.FreeSpace(dir)
+	if err != nil {
+		res.Status, res.Detail = StatusFail, err.Error()
+		return res
+	}
+	available := resource.NewQuantity(free, resource.BinarySI)
+	min := resource.NewQuantity(d.MinFreeSpace, resource.BinarySI)
+	if free < d.MinFreeSpace {
+		res.Status, res.Detail = StatusFail, fmt.Sprintf("%s free in '%s', less than the %s needed", available, dir, min)
+	} else {
+		res.Status, res.Detail = StatusPass, fmt.Sprintf("%s free in '%s'", available, dir)
+	}
+	return res
+}
+
+// Binaries returns the binaries cfg runs.
+func Binaries(cfg *config.Config) (bins []string) {
+	if cfg.ProviderPlugin != "" {
+		bins = append(bins, cfg.ProviderPlugin)
+	}
+	bins = append(bins, cfg.SuitePlugins...)
+	for _, hook := range cfg.MetricHooks {
+		if fields := strings.Fields(hook); len(fields) != 0 {
+			bins = append(bins, fields[0])
+		}
+	}
+	if cfg.ImageScanner == imagescan.ScannerTrivy {
+		bins = append(bins, imagescan.ScannerTrivy)
+	}
+	return
+}
+
+// ocmIdentity returns the user and organization of the credentials of cfg.
+func ocmIdentity(cfg *config.Config) (string, error) {
+	client, err := osd.NewForConfig(cfg)
+	if err != nil {
+		return "", err
+	}
+	account, err := client.CurrentAccount()
+	if err != nil {
+		return "", fmt.Errorf("couldn't get account: %v", err)
+	}
+	return fmt.Sprintf("'%s' of '%s'", account.Username(), account.Organization().Name()), nil
+}
+
+// awsIdentity returns the ARN of the AWS credentials of the environment.
+func awsIdentity(region string) (string, bool, error) {
+	sess, err := session.NewSession(&aws.Config{Region: aws.String(region)})
+	if err != nil {
+		return "", true, fmt.Errorf("couldn't create AWS session: %v", err)
+	}
+	if _, err = sess.Config.Credentials.Get(); err != nil {
+		return "", false, nil
+	}
+	identity, err := sts.New(sess).GetCallerIdentity(&sts.GetCallerIdentityInput{})
+	if err != nil {
+		return "", true, fmt.Errorf("AWS rejected credentials: %v", err)
+	}
+	return aws.StringValue(identity.Arn), true, nil
+}
+
+// serverVersion returns the Kubernetes version of the cluster accessed with kubeconfig.
+func serverVersion(kubeconfig []byte) (string, error) {
+	restConfig, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+	if err != nil {
+		return "", fmt.Errorf("couldn't load TEST_KUBECONFIG: %v", err)
+	}
+	client, err := discovery.NewDiscoveryClientForConfig(restConfig)
+	if err != nil {
+		return "", fmt.Errorf("couldn't configure client: %v", err)
+	}
+	version, err := client.ServerVersion()
+	if err != nil {
+		return "", fmt.Errorf("couldn't reach cluster at %s: %v", restConfig.Host, err)
+	}
+	return version.GitVersion, nil
+}
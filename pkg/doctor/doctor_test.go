@@ -0,0 +1,115 @@
+package doctor
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/openshift/osde2e/pkg/config"
+)
+
+// fakeDoctor returns a Doctor of cfg whose environment has everything working.
+func fakeDoctor(cfg *config.Config) *Doctor {
+	return &Doctor{
+		Config:       cfg,
+		MinFreeSpace: 1024,
+		Identity: func(*config.Config) (string, error) {
+			return "'osde2e' of 'Red Hat'", nil
+		},
+		AWSIdentity: func(string) (string, bool, error) {
+			return "arn:aws:iam::123456789012:user/osde2e", true, nil
+		},
+		ServerVersion: func(kubeconfig []byte) (string, error) {
+			if string(kubeconfig) != "kubeconfig" {
+				return "", errors.New("invalid kubeconfig")
+			}
+			return "v1.14.6", nil
+		},
+		LookPath: func(file string) (string, error) {
+			return "/usr/bin/" + file, nil
+		},
+		FreeSpace: func(string) (int64, error) {
+			return 2048, nil
+		},
+	}
+}
+
+func statuses(r *Report) map[string]string {
+	s := map[string]string{}
+	for _, res := range r.Results {
+		s[res.Check] = res.Status
+	}
+	return s
+}
+
+func TestRun(t *testing.T) {
+	cfg := &config.Config{Provider: config.ProviderOSD, UHCToken: "token", Kubeconfig: []byte("kubeconfig"),
+		ImageScanner: "trivy", ReportDir: "/nonexistent/report"}
+	r := fakeDoctor(cfg).Run()
+	if !r.Passed {
+		t.Errorf("expected working environment to pass, got %+v", r.Results)
+	}
+	if len(r.Results) != 5 || r.Results[3].Detail != "found trivy" || !strings.Contains(r.Results[4].Detail, "in '/'") {
+		t.Errorf("expected every check to run, got %+v", r.Results)
+	}
+
+	d := fakeDoctor(&config.Config{Provider: config.ProviderOSD, CloudVerification: true, CloudProvider: "aws",
+		SuitePlugins: []string{"suite-plugin"}})
+	d.AWSIdentity = func(string) (string, bool, error) { return "", false, nil }
+	d.LookPath = func(file string) (string, error) { return "", errors.New("not found") }
+	d.FreeSpace = func(string) (int64, error) { return 512, nil }
+	r = d.Run()
+	expected := map[string]string{
+		CheckOCM:        StatusFail,
+		CheckAWS:        StatusFail,
+		CheckKubeconfig: StatusSkip,
+		CheckBinaries:   StatusFail,
+		CheckDiskSpace:  StatusFail,
+	}
+	if r.Passed || !reflect.DeepEqual(statuses(r), expected) {
+		t.Errorf("expected broken environment to fail, got %+v", r.Results)
+	}
+	if r.Results[3].Detail != "couldn't find suite-plugin" || r.Results[4].Detail != "512 free in '"+os.TempDir()+"', less than the 1Ki needed" {
+		t.Errorf("expected failures to say what's wrong, got %+v", r.Results)
+	}
+}
+
+func TestOptionalChecks(t *testing.T) {
+	d := fakeDoctor(&config.Config{Provider: config.ProviderGeneric})
+	d.AWSIdentity = func(string) (string, bool, error) { return "", false, nil }
+	r := d.Run()
+	if s := statuses(r); !r.Passed || s[CheckOCM] != StatusSkip || s[CheckAWS] != StatusSkip {
+		t.Errorf("expected unneeded checks to be skipped, got %+v", r.Results)
+	}
+
+	d.AWSIdentity = func(string) (string, bool, error) { return "", true, errors.New("expired") }
+	if r = d.Run(); r.Passed || statuses(r)[CheckAWS] != StatusFail {
+		t.Errorf("expected rejected AWS credentials to fail, got %+v", r.Results)
+	}
+}
+
+func TestKubeconfig(t *testing.T) {
+	d := fakeDoctor(&config.Config{Kubeconfig: []byte("kubeconfig")})
+	if res := d.kubeconfig(); res.Status != StatusPass {
+		t.Errorf("expected kubeconfig read from TEST_KUBECONFIG to pass, got %+v", res)
+	}
+
+	d.KubeconfigErr = errors.New("failed reading '/tmp/kubeconfig': no such file")
+	if res := d.kubeconfig(); res.Status != StatusFail || !strings.Contains(res.Detail, "no such file") {
+		t.Errorf("expected unreadable TEST_KUBECONFIG to fail, got %+v", res)
+	}
+}
+
+func TestBinaries(t *testing.T) {
+	cfg := &config.Config{
+		ProviderPlugin: "provider",
+		SuitePlugins:   []string{"./suite"},
+		MetricHooks:    []string{"hook --flag", " "},
+		ImageScanner:   "clair",
+	}
+	if bins := Binaries(cfg); !reflect.DeepEqual(bins, []string{"provider", "./suite", "hook"}) {
+		t.Errorf("unexpected binaries %v", bins)
+	}
+}
@@ -0,0 +1,194 @@
+// Package drift guards long runs against the cluster's version changing while they test it, such as when a managed
+// upgrade policy upgrades it, so results of different versions aren't silently mixed.
+package drift
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	configclient "github.com/openshift/client-go/config/clientset/versioned"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/test-infra/testgrid/metadata/junit"
+
+	"github.com/openshift/osde2e/pkg/junitprops"
+	"github.com/openshift/osde2e/pkg/skips"
+)
+
+// SuiteName is the JUnit suite reporting version drift.
+const SuiteName = "Cluster version drift"
+
+// Current guards the run being tested. It is nil when the version isn't guarded.
+var Current *Guard
+
+// VersionFunc returns the version the cluster is running or upgrading to.
+type VersionFunc func() (string, error)
+
+// ClusterVersion returns a VersionFunc for the cluster accessed with kubeconfig, reporting the version desired by
+// its ClusterVersion, which changes as soon as an upgrade starts.
+func ClusterVersion(kubeconfig []byte) (VersionFunc, error) {
+	restConfig, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't configure client: %v", err)
+	}
+	cfg, err := configclient.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't create config client: %v", err)
+	}
+
+	return func() (string, error) {
+		cv, err := cfg.ConfigV1().ClusterVersions().Get("version", metav1.GetOptions{})
+		if err != nil {
+			return "", fmt.Errorf("couldn't get cluster version: %v", err)
+		}
+		return cv.Status.Desired.Version, nil
+	}, nil
+}
+
+// Change is the cluster's version changing.
+type Change struct {
+	Time     time.Time
+	From, To string
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("cluster version changed from %s to %s at %s", c.From, c.To, c.Time.UTC().Format(time.RFC3339))
+}
+
+// Guard watches for the cluster's version changing from what it was when the guard started.
+type Guard struct {
+	// Expected is the version the cluster should stay at.
+	Expected string
+
+	// Abort skips specs once the version has changed instead of only reporting it.
+	Abort bool
+
+	mu      sync.Mutex
+	current string
+	changes []Change
+	allowed map[string]bool
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewGuard returns a guard expecting the cluster to stay at version.
+func NewGuard(version string, abort bool) *Guard {
+	return &Guard{Expected: version, Abort: abort, current: version}
+}
+
+// Start checks the version returned by get every interval until stopped.
+func (g *Guard) Start(get VersionFunc, interval time.Duration) {
+	g.stop, g.done = make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(g.done)
+		wait.Until(func() {
+			version, err := get()
+			if err != nil {
+				log.Printf("Failed to check for cluster version drift: %v", err)
+				return
+			}
+			g.Observe(version, time.Now())
+		}, interval, g.stop)
+	}()
+}
+
+// Stop stops checking the version, returning how it changed.
+func (g *Guard) Stop() []Change {
+	if g.stop != nil {
+		close(g.stop)
+		<-g.done
+		g.stop = nil
+	}
+	return g.Changes()
+}
+
+// Observe records the cluster running version at t, returning the change if it differs from the last version seen.
+func (g *Guard) Observe(version string, t time.Time) (Change, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if version == "" || version == g.current {
+		return Change{}, false
+	} else if g.allowed[version] {
+		log.Printf("Cluster version changed from %s to %s as intended.", g.current, version)
+		g.current = version
+		return Change{}, false
+	}
+
+	c := Change{Time: t, From: g.current, To: version}
+	g.current, g.changes = version, append(g.changes, c)
+	log.Printf("Cluster version drifted during the run: %s.", c)
+	return c, true
+}
+
+// Allow lets the cluster change to version without it being drift, such as when a spec upgrades it on purpose.
+func (g *Guard) Allow(version string) {
+	if g == nil {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.allowed == nil {
+		g.allowed = map[string]bool{}
+	}
+	g.allowed[version] = true
+}
+
+// Changes returns how the version changed.
+func (g *Guard) Changes() []Change {
+	if g == nil {
+		return nil
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]Change(nil), g.changes...)
+}
+
+// SkipIfDrifted skips the running spec if the version has changed and the guard aborts.
+func (g *Guard) SkipIfDrifted() {
+	if g == nil || !g.Abort {
+		return
+	}
+	if changes := g.Changes(); len(changes) != 0 {
+		skips.Skip(skips.DependencyFailed, fmt.Sprintf("the run was aborted because the %s", changes[0]), 1)
+	}
+}
+
+// Metadata records how the version changed for TestGrid.
+func (g *Guard) Metadata() map[string]interface{} {
+	changes := g.Changes()
+	meta := map[string]interface{}{"cluster-version-drift": len(changes)}
+	if len(changes) != 0 {
+		meta["cluster-version-drifted-to"] = changes[len(changes)-1].To
+	}
+	return meta
+}
+
+// WriteJUnit records a testcase in dir failing if the version changed.
+func (g *Guard) WriteJUnit(dir, suffix string) error {
+	suite := junit.Suite{
+		Name:  SuiteName,
+		Tests: 1,
+	}
+	result := junit.Result{
+		Name:      fmt.Sprintf("[drift] cluster version should stay %s while testing", g.Expected),
+		ClassName: SuiteName,
+	}
+	if changes := g.Changes(); len(changes) != 0 {
+		lines := make([]string, len(changes))
+		for i, c := range changes {
+			lines[i] = c.String()
+		}
+		msg := fmt.Sprintf("cluster version drifted to %s, results may be of mixed versions", changes[len(changes)-1].To)
+		output := strings.Join(lines, "\n")
+		result.Failure, result.Output = &msg, &output
+		suite.Failures++
+	}
+	suite.Results = append(suite.Results, result)
+
+	return junitprops.WriteSuite(dir, "drift", suffix, suite)
+}
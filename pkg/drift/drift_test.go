@@ -0,0 +1,105 @@
+package drift
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestObserve(t *testing.T) {
+	g := NewGuard("4.3.8", true)
+	now := time.Date(2020, 4, 1, 12, 0, 0, 0, time.UTC)
+
+	if _, changed := g.Observe("4.3.8", now); changed {
+		t.Error("expected no change at the expected version")
+	}
+	if _, changed := g.Observe("", now); changed {
+		t.Error("expected an unknown version to be ignored")
+	}
+	if c, changed := g.Observe("4.3.10", now); !changed || c.From != "4.3.8" || c.To != "4.3.10" {
+		t.Errorf("expected change from 4.3.8 to 4.3.10, got %+v", c)
+	}
+	if _, changed := g.Observe("4.3.10", now.Add(time.Minute)); changed {
+		t.Error("expected the new version to only be recorded once")
+	}
+
+	expected := "cluster version changed from 4.3.8 to 4.3.10 at 2020-04-01T12:00:00Z"
+	if changes := g.Changes(); len(changes) != 1 || changes[0].String() != expected {
+		t.Errorf("expected '%s', got %v", expected, changes)
+	}
+
+	meta := g.Metadata()
+	if meta["cluster-version-drift"] != 1 || meta["cluster-version-drifted-to"] != "4.3.10" {
+		t.Errorf("unexpected metadata: %v", meta)
+	}
+
+	// specs upgrading the cluster on purpose aren't drift
+	g.Allow("4.4.0")
+	if _, changed := g.Observe("4.4.0", now.Add(2*time.Minute)); changed || len(g.Changes()) != 1 {
+		t.Errorf("expected allowed version not to be drift, got %v", g.Changes())
+	}
+	if c, changed := g.Observe("4.4.2", now.Add(3*time.Minute)); !changed || c.From != "4.4.0" {
+		t.Errorf("expected change from the allowed version to be drift, got %+v", c)
+	}
+
+	var unguarded *Guard
+	unguarded.Allow("4.4.0")
+	if meta = unguarded.Metadata(); meta["cluster-version-drift"] != 0 {
+		t.Errorf("expected no drift without a guard, got %v", meta)
+	}
+}
+
+func TestStart(t *testing.T) {
+	var mu sync.Mutex
+	versions := []string{"4.3.8", "4.3.8", "4.4.0"}
+	get := func() (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		v := versions[0]
+		if len(versions) > 1 {
+			versions = versions[1:]
+		}
+		return v, nil
+	}
+
+	g := NewGuard("4.3.8", false)
+	g.Start(get, time.Millisecond)
+	deadline := time.Now().Add(5 * time.Second)
+	for len(g.Changes()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	if changes := g.Stop(); len(changes) != 1 || changes[0].To != "4.4.0" {
+		t.Errorf("expected drift to 4.4.0, got %v", changes)
+	}
+}
+
+func TestWriteJUnit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "drift")
+	if err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	g := NewGuard("4.3.8", false)
+	if err = g.WriteJUnit(dir, "abc"); err != nil {
+		t.Fatalf("failed to write JUnit: %v", err)
+	}
+	data, _ := ioutil.ReadFile(filepath.Join(dir, "junit_drift_abc.xml"))
+	if !strings.Contains(string(data), `failures="0"`) {
+		t.Errorf("expected no failures without drift: %s", data)
+	}
+
+	g.Observe("4.4.0", time.Now())
+	if err = g.WriteJUnit(dir, "abc"); err != nil {
+		t.Fatalf("failed to write JUnit: %v", err)
+	}
+	data, _ = ioutil.ReadFile(filepath.Join(dir, "junit_drift_abc.xml"))
+	if !strings.Contains(string(data), "cluster version drifted to 4.4.0") {
+		t.Errorf("expected drift to be reported: %s", data)
+	}
+}
@@ -0,0 +1,254 @@
+// Package fingerprint captures what a cluster is made of, such as its versions, nodes, network, and feature gates, in
+// a single document which can be attached to bug reports.
+package fingerprint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	kubev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/openshift/osde2e/pkg/helper"
+	"github.com/openshift/osde2e/pkg/osd"
+	"github.com/openshift/osde2e/pkg/topology"
+)
+
+const (
+	// File is the name fingerprints are written as.
+	File = "fingerprint.json"
+
+	// clusterResource is the name of cluster-wide configuration resources.
+	clusterResource = "cluster"
+
+	// operatorVersion is the name of the version of a ClusterOperator's operator.
+	operatorVersion = "operator"
+
+	// roleLabelPrefix is the prefix of labels identifying the roles of nodes.
+	roleLabelPrefix = "node-role.kubernetes.io/"
+)
+
+var csvs = schema.GroupVersionResource{Group: "operators.coreos.com", Version: "v1alpha1", Resource: "clusterserviceversions"}
+
+// Fingerprint describes what a cluster is made of.
+type Fingerprint struct {
+	ClusterID string    `json:"clusterID,omitempty"`
+	Collected time.Time `json:"collected"`
+
+	// Version is the OpenShift version the cluster runs and Channel is where it gets upgrades from.
+	Version string `json:"version"`
+	Channel string `json:"channel,omitempty"`
+
+	// Operators are the versions of ClusterOperators by name.
+	Operators map[string]string `json:"operators"`
+
+	// OLMOperators are the versions of operators installed by OLM, by the namespace and name of their CSV.
+	OLMOperators map[string]string `json:"olmOperators,omitempty"`
+
+	// Addons are the states of add-ons installed through OCM by ID. They're only known for clusters in OCM.
+	Addons map[string]string `json:"addons,omitempty"`
+
+	Cloud         string              `json:"cloud,omitempty"`
+	Region        string              `json:"region,omitempty"`
+	Zones         []string            `json:"zones,omitempty"`
+	InstanceTypes map[string][]string `json:"instanceTypes"`
+	Nodes         []Node              `json:"nodes"`
+
+	Network      Network      `json:"network"`
+	FeatureGates FeatureGates `json:"featureGates"`
+}
+
+// Node is a node of the cluster.
+type Node struct {
+	Name           string   `json:"name"`
+	Roles          []string `json:"roles"`
+	InstanceType   string   `json:"instanceType,omitempty"`
+	Zone           string   `json:"zone,omitempty"`
+	Architecture   string   `json:"architecture"`
+	OSImage        string   `json:"osImage"`
+	KubeletVersion string   `json:"kubeletVersion"`
+	Ready          bool     `json:"ready"`
+}
+
+// Network is how pods and services are networked.
+type Network struct {
+	Type           string   `json:"type"`
+	ClusterNetwork []string `json:"clusterNetwork"`
+	ServiceNetwork []string `json:"serviceNetwork"`
+	MTU            int      `json:"mtu,omitempty"`
+}
+
+// FeatureGates are the features enabled and disabled by the cluster's feature set.
+type FeatureGates struct {
+	FeatureSet string   `json:"featureSet"`
+	Enabled    []string `json:"enabled,omitempty"`
+	Disabled   []string `json:"disabled,omitempty"`
+}
+
+// Collect fingerprints the cluster h is connected to. OLM operators are left out if OLM isn't installed.
+func Collect(h *helper.H) (*Fingerprint, error) {
+	f := &Fingerprint{
+		ClusterID: h.ClusterID,
+		Collected: time.Now().UTC(),
+	}
+
+	cv, err := h.Cfg().ConfigV1().ClusterVersions().Get("version", metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get cluster version: %v", err)
+	}
+	f.Version, f.Channel = cv.Status.Desired.Version, cv.Spec.Channel
+
+	cos, err := h.Cfg().ConfigV1().ClusterOperators().List(metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("couldn't list ClusterOperators: %v", err)
+	}
+	f.Operators = OperatorVersions(cos.Items)
+
+	if list, err := h.Dynamic().Resource(csvs).List(metav1.ListOptions{}); err == nil {
+		f.OLMOperators = CSVVersions(list.Items)
+	}
+
+	nodes, err := h.Kube().CoreV1().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("couldn't list nodes: %v", err)
+	}
+	f.SetNodes(nodes.Items)
+
+	network, err := h.Cfg().ConfigV1().Networks().Get(clusterResource, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get network configuration: %v", err)
+	}
+	f.Network = NetworkFrom(network)
+
+	gate, err := h.Cfg().ConfigV1().FeatureGates().Get(clusterResource, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get feature gates: %v", err)
+	}
+	f.FeatureGates = FeatureGatesFrom(gate)
+	return f, nil
+}
+
+// SetNodes describes nodes and where they run.
+func (f *Fingerprint) SetNodes(nodes []kubev1.Node) {
+	topo := topology.FromNodes(nodes)
+	f.Cloud, f.Region, f.Zones, f.InstanceTypes = topo.Cloud, topo.Region, topo.Zones, topo.InstanceTypes
+
+	f.Nodes = make([]Node, 0, len(nodes))
+	for _, n := range nodes {
+		node := Node{
+			Name:           n.Name,
+			Roles:          []string{},
+			InstanceType:   n.Labels[topology.InstanceTypeLabel],
+			Zone:           n.Labels[topology.ZoneLabel],
+			Architecture:   n.Status.NodeInfo.Architecture,
+			OSImage:        n.Status.NodeInfo.OSImage,
+			KubeletVersion: n.Status.NodeInfo.KubeletVersion,
+		}
+		for label := range n.Labels {
+			if strings.HasPrefix(label, roleLabelPrefix) {
+				node.Roles = append(node.Roles, strings.TrimPrefix(label, roleLabelPrefix))
+			}
+		}
+		sort.Strings(node.Roles)
+		for _, c := range n.Status.Conditions {
+			if c.Type == kubev1.NodeReady {
+				node.Ready = c.Status == kubev1.ConditionTrue
+			}
+		}
+		f.Nodes = append(f.Nodes, node)
+	}
+	sort.Slice(f.Nodes, func(i, j int) bool {
+		return f.Nodes[i].Name < f.Nodes[j].Name
+	})
+}
+
+// SetAddons records the states of the add-ons in snapshot.
+func (f *Fingerprint) SetAddons(snapshot *osd.ClusterSnapshot) {
+	f.Addons = make(map[string]string, len(snapshot.Addons))
+	for id, a := range snapshot.Addons {
+		f.Addons[id] = a.State
+	}
+}
+
+// OperatorVersions returns the version of the operator of each ClusterOperator by name.
+func OperatorVersions(cos []configv1.ClusterOperator) map[string]string {
+	versions := make(map[string]string, len(cos))
+	for _, co := range cos {
+		versions[co.Name] = ""
+		for _, v := range co.Status.Versions {
+			if v.Name == operatorVersion {
+				versions[co.Name] = v.Version
+			}
+		}
+	}
+	return versions
+}
+
+// CSVVersions returns the version of each ClusterServiceVersion by namespace and name. CSVs copied into other
+// namespaces by OLM are left out.
+func CSVVersions(csvs []unstructured.Unstructured) map[string]string {
+	versions := map[string]string{}
+	for _, csv := range csvs {
+		if _, copied := csv.GetLabels()["olm.copiedFrom"]; copied {
+			continue
+		}
+		version, _, _ := unstructured.NestedString(csv.Object, "spec", "version")
+		versions[csv.GetNamespace()+"/"+csv.GetName()] = version
+	}
+	return versions
+}
+
+// NetworkFrom returns how network configures pods and services to be networked.
+func NetworkFrom(network *configv1.Network) Network {
+	n := Network{
+		Type:           network.Status.NetworkType,
+		ClusterNetwork: []string{},
+		ServiceNetwork: append([]string{}, network.Status.ServiceNetwork...),
+		MTU:            network.Status.ClusterNetworkMTU,
+	}
+	for _, entry := range network.Status.ClusterNetwork {
+		n.ClusterNetwork = append(n.ClusterNetwork, entry.CIDR)
+	}
+	return n
+}
+
+// FeatureGatesFrom returns the features enabled and disabled by the feature set of gate.
+func FeatureGatesFrom(gate *configv1.FeatureGate) FeatureGates {
+	gates := FeatureGates{
+		FeatureSet: string(gate.Spec.FeatureSet),
+	}
+	if gates.FeatureSet == "" {
+		gates.FeatureSet = "Default"
+	}
+	if features, ok := configv1.FeatureSets[gate.Spec.FeatureSet]; ok {
+		gates.Enabled = append(gates.Enabled, features.Enabled...)
+		gates.Disabled = append(gates.Disabled, features.Disabled...)
+		sort.Strings(gates.Enabled)
+		sort.Strings(gates.Disabled)
+	}
+	return gates
+}
+
+// Write stores the fingerprint as File in dir.
+func (f *Fingerprint) Write(dir string) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("couldn't encode fingerprint: %v", err)
+	}
+
+	os.MkdirAll(dir, os.ModePerm)
+	filename := filepath.Join(dir, File)
+	if err = ioutil.WriteFile(filename, data, os.ModePerm); err != nil {
+		return fmt.Errorf("couldn't write fingerprint to '%s': %v", filename, err)
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package fingerprint
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	kubev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/openshift/osde2e/pkg/osd"
+	"github.com/openshift/osde2e/pkg/topology"
+)
+
+func TestSetNodes(t *testing.T) {
+	node := func(name, role, instanceType string, ready kubev1.ConditionStatus) kubev1.Node {
+		return kubev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+				Labels: map[string]string{
+					"node-role.kubernetes.io/" + role: "",
+					topology.InstanceTypeLabel:        instanceType,
+					topology.ZoneLabel:                "us-east-1a",
+					topology.RegionLabel:              "us-east-1",
+				},
+			},
+			Spec: kubev1.NodeSpec{ProviderID: "aws:///us-east-1a/i-0123"},
+			Status: kubev1.NodeStatus{
+				NodeInfo: kubev1.NodeSystemInfo{Architecture: "amd64", OSImage: "Red Hat Enterprise Linux CoreOS", KubeletVersion: "v1.14.6"},
+				Conditions: []kubev1.NodeCondition{
+					{Type: kubev1.NodeReady, Status: ready},
+				},
+			},
+		}
+	}
+
+	f := new(Fingerprint)
+	f.SetNodes([]kubev1.Node{
+		node("worker-1", "worker", "m5.xlarge", kubev1.ConditionFalse),
+		node("master-0", "master", "m5.2xlarge", kubev1.ConditionTrue),
+	})
+
+	if f.Cloud != "aws" || f.Region != "us-east-1" || !reflect.DeepEqual(f.Zones, []string{"us-east-1a"}) {
+		t.Errorf("expected cluster in us-east-1a on aws, got %s %s %v", f.Cloud, f.Region, f.Zones)
+	}
+	if types := f.InstanceTypes["worker"]; !reflect.DeepEqual(types, []string{"m5.xlarge"}) {
+		t.Errorf("expected worker instance types, got %v", f.InstanceTypes)
+	}
+
+	expected := Node{
+		Name:           "master-0",
+		Roles:          []string{"master"},
+		InstanceType:   "m5.2xlarge",
+		Zone:           "us-east-1a",
+		Architecture:   "amd64",
+		OSImage:        "Red Hat Enterprise Linux CoreOS",
+		KubeletVersion: "v1.14.6",
+		Ready:          true,
+	}
+	if len(f.Nodes) != 2 || !reflect.DeepEqual(f.Nodes[0], expected) || f.Nodes[1].Ready {
+		t.Errorf("expected nodes sorted by name starting with %+v, got %+v", expected, f.Nodes)
+	}
+}
+
+func TestVersions(t *testing.T) {
+	cos := []configv1.ClusterOperator{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "dns"},
+			Status: configv1.ClusterOperatorStatus{
+				Versions: []configv1.OperandVersion{
+					{Name: "coredns", Version: "4.2.0-0.nightly"},
+					{Name: "operator", Version: "4.2.0"},
+				},
+			},
+		},
+		{ObjectMeta: metav1.ObjectMeta{Name: "insights"}},
+	}
+	if versions := OperatorVersions(cos); !reflect.DeepEqual(versions, map[string]string{"dns": "4.2.0", "insights": ""}) {
+		t.Errorf("expected operator versions, got %v", versions)
+	}
+
+	csv := func(namespace string, labels map[string]interface{}) unstructured.Unstructured {
+		return unstructured.Unstructured{Object: map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"name":      "etcdoperator.v0.9.4",
+				"namespace": namespace,
+				"labels":    labels,
+			},
+			"spec": map[string]interface{}{"version": "0.9.4"},
+		}}
+	}
+	csvs := []unstructured.Unstructured{
+		csv("openshift-operators", nil),
+		csv("osde2e-abcde", map[string]interface{}{"olm.copiedFrom": "openshift-operators"}),
+	}
+	expected := map[string]string{"openshift-operators/etcdoperator.v0.9.4": "0.9.4"}
+	if versions := CSVVersions(csvs); !reflect.DeepEqual(versions, expected) {
+		t.Errorf("expected CSV versions %v, got %v", expected, versions)
+	}
+}
+
+func TestConfiguration(t *testing.T) {
+	network := &configv1.Network{
+		Status: configv1.NetworkStatus{
+			NetworkType:       "OpenShiftSDN",
+			ClusterNetwork:    []configv1.ClusterNetworkEntry{{CIDR: "10.128.0.0/14", HostPrefix: 23}},
+			ServiceNetwork:    []string{"172.30.0.0/16"},
+			ClusterNetworkMTU: 8951,
+		},
+	}
+	expectedNetwork := Network{
+		Type:           "OpenShiftSDN",
+		ClusterNetwork: []string{"10.128.0.0/14"},
+		ServiceNetwork: []string{"172.30.0.0/16"},
+		MTU:            8951,
+	}
+	if n := NetworkFrom(network); !reflect.DeepEqual(n, expectedNetwork) {
+		t.Errorf("expected network %+v, got %+v", expectedNetwork, n)
+	}
+
+	gates := FeatureGatesFrom(&configv1.FeatureGate{})
+	if gates.FeatureSet != "Default" || len(gates.Enabled) == 0 {
+		t.Errorf("expected features enabled by the default feature set, got %+v", gates)
+	}
+	gates = FeatureGatesFrom(&configv1.FeatureGate{Spec: configv1.FeatureGateSpec{FeatureSet: "Unknown"}})
+	if gates.FeatureSet != "Unknown" || len(gates.Enabled) != 0 {
+		t.Errorf("expected no features known for unknown feature set, got %+v", gates)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fingerprint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	f := &Fingerprint{ClusterID: "1a2b3c", Version: "4.2.0"}
+	f.SetAddons(&osd.ClusterSnapshot{
+		Addons: map[string]osd.AddonSnapshot{"descheduler": {State: osd.AddonReady}},
+	})
+	if err = f.Write(dir); err != nil {
+		t.Fatalf("failed to write fingerprint: %v", err)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, File))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var written Fingerprint
+	if err = json.Unmarshal(data, &written); err != nil {
+		t.Fatalf("failed to decode fingerprint: %v", err)
+	} else if !reflect.DeepEqual(&written, f) {
+		t.Errorf("expected fingerprint %+v, got %+v", f, written)
+	}
+}
@@ -0,0 +1,86 @@
+package formats
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// AllureDir is the directory of Allure results in the report directory.
+const AllureDir = "allure-results"
+
+// allureResult is a test result in the Allure results format.
+type allureResult struct {
+	UUID          string              `json:"uuid"`
+	HistoryID     string              `json:"historyId"`
+	Name          string              `json:"name"`
+	FullName      string              `json:"fullName"`
+	Status        Status              `json:"status"`
+	StatusDetails allureStatusDetails `json:"statusDetails"`
+	Stage         string              `json:"stage"`
+	Start         int64               `json:"start"`
+	Stop          int64               `json:"stop"`
+	Labels        []allureLabel       `json:"labels"`
+}
+
+type allureStatusDetails struct {
+	Message string `json:"message,omitempty"`
+	Trace   string `json:"trace,omitempty"`
+}
+
+type allureLabel struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// WriteAllure writes a result file for each of results to dir, which Allure can generate a report from.
+func WriteAllure(dir string, results []Result) error {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return fmt.Errorf("couldn't create '%s': %v", dir, err)
+	}
+
+	for i, res := range results {
+		// history is kept across runs by the spec's name
+		history := sha1.Sum([]byte(res.Name))
+		uuid := sha1.Sum([]byte(fmt.Sprintf("%s %d %d", res.Name, res.Start.UnixNano(), i)))
+
+		labels := []allureLabel{{"framework", "ginkgo"}, {"language", "go"}}
+		if res.Group != "" {
+			labels = append(labels, allureLabel{"parentSuite", string(res.Group)})
+		}
+		if res.Suite != "" {
+			labels = append(labels, allureLabel{"suite", res.Suite})
+		}
+
+		data, err := json.MarshalIndent(allureResult{
+			UUID:          hex.EncodeToString(uuid[:16]),
+			HistoryID:     hex.EncodeToString(history[:]),
+			Name:          res.Name,
+			FullName:      res.Name,
+			Status:        res.Status,
+			StatusDetails: allureStatusDetails{Message: res.Message, Trace: res.Trace},
+			Stage:         "finished",
+			Start:         millis(res.Start),
+			Stop:          millis(res.Stop),
+			Labels:        labels,
+		}, "", "  ")
+		if err != nil {
+			return fmt.Errorf("couldn't encode result of '%s': %v", res.Name, err)
+		}
+
+		filename := filepath.Join(dir, hex.EncodeToString(uuid[:16])+"-result.json")
+		if err = ioutil.WriteFile(filename, data, os.ModePerm); err != nil {
+			return fmt.Errorf("couldn't write result of '%s': %v", res.Name, err)
+		}
+	}
+	return nil
+}
+
+func millis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
@@ -0,0 +1,222 @@
+// Package formats writes results of specs in formats other than JUnit, such as Allure and SonarQube, so teams can
+// ingest results into their own dashboards. JUnit is always written for TestGrid.
+package formats
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	ginkgoconfig "github.com/onsi/ginkgo/config"
+	"github.com/onsi/ginkgo/types"
+
+	"github.com/openshift/osde2e/pkg/groups"
+	"github.com/openshift/osde2e/pkg/redact"
+)
+
+// Format is a way of reporting results.
+type Format string
+
+const (
+	// Allure writes a result JSON file for each spec to the allure-results directory.
+	Allure Format = "allure"
+
+	// SonarQube writes generic test execution data to sonarqube_<suffix>.xml.
+	SonarQube Format = "sonarqube"
+)
+
+// Formats are all formats which can be written.
+var Formats = []Format{Allure, SonarQube}
+
+// Status is the outcome of a spec.
+type Status string
+
+const (
+	// Passed specs met their expectations.
+	Passed Status = "passed"
+
+	// Failed specs didn't meet their expectations.
+	Failed Status = "failed"
+
+	// Broken specs panicked or timed out, so what they test is unknown.
+	Broken Status = "broken"
+
+	// Skipped specs didn't run.
+	Skipped Status = "skipped"
+)
+
+// Parse returns the formats named by names, returning an error for any that are unknown.
+func Parse(names []string) ([]Format, error) {
+	formats := make([]Format, 0, len(names))
+	for _, name := range names {
+		f := Format(strings.ToLower(strings.TrimSpace(name)))
+		if !known(f) {
+			return nil, fmt.Errorf("unknown report format '%s', must be one of %v", name, Formats)
+		}
+		formats = append(formats, f)
+	}
+	return formats, nil
+}
+
+func known(f Format) bool {
+	for _, k := range Formats {
+		if f == k {
+			return true
+		}
+	}
+	return false
+}
+
+// Result is the outcome of a spec.
+type Result struct {
+	// Suite is the text of the spec's top container.
+	Suite string
+
+	// Group of the spec's suite.
+	Group groups.Group
+
+	// Name is the full text of the spec, excluding the top level.
+	Name string
+
+	Status Status
+
+	// Message describes why the spec failed or was skipped.
+	Message string
+
+	// Trace is where the spec failed and its stack.
+	Trace string
+
+	// File is where the spec is defined.
+	File string
+
+	Start, Stop time.Time
+}
+
+// Recorder is a Ginkgo reporter recording the results of specs so they can be written in other formats.
+type Recorder struct {
+	mu      sync.Mutex
+	started map[string]time.Time
+	results []Result
+
+	// now returns the current time, and is replaced in tests.
+	now func() time.Time
+}
+
+// NewRecorder returns a Recorder without results.
+func NewRecorder() *Recorder {
+	return &Recorder{started: map[string]time.Time{}, now: time.Now}
+}
+
+// Results returns the results of specs completed so far.
+func (r *Recorder) Results() []Result {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Result(nil), r.results...)
+}
+
+// SpecSuiteWillBegin does nothing.
+func (r *Recorder) SpecSuiteWillBegin(config ginkgoconfig.GinkgoConfigType, summary *types.SuiteSummary) {
+}
+
+// BeforeSuiteDidRun does nothing.
+func (r *Recorder) BeforeSuiteDidRun(summary *types.SetupSummary) {}
+
+// SpecWillRun records when the spec started.
+func (r *Recorder) SpecWillRun(summary *types.SpecSummary) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.started[specName(summary)] = r.now()
+}
+
+// SpecDidComplete records the result of the spec.
+func (r *Recorder) SpecDidComplete(summary *types.SpecSummary) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	name := specName(summary)
+	stop := r.now()
+	start, ok := r.started[name]
+	if !ok {
+		start = stop.Add(-summary.RunTime)
+	}
+	delete(r.started, name)
+
+	res := Result{
+		Name:  name,
+		Start: start,
+		Stop:  stop,
+	}
+	if texts := summary.ComponentTexts; len(texts) > 1 {
+		res.Suite = texts[1]
+		res.Group = groups.Of(res.Suite)
+	}
+	if locs := summary.ComponentCodeLocations; len(locs) != 0 {
+		res.File = relativePath(locs[len(locs)-1].FileName)
+	}
+
+	switch {
+	case summary.State == types.SpecStateFailed:
+		res.Status = Failed
+	case summary.State.IsFailure():
+		// panics and timeouts are problems with the spec rather than what it tests
+		res.Status = Broken
+	case summary.State == types.SpecStateSkipped || summary.State == types.SpecStatePending:
+		res.Status = Skipped
+	default:
+		res.Status = Passed
+	}
+	if res.Status != Passed {
+		res.Message = redact.Current.String(summary.Failure.Message)
+		if loc := summary.Failure.Location; loc.FileName != "" {
+			res.Trace = redact.Current.String(loc.String() + "\n" + loc.FullStackTrace)
+		}
+	}
+	r.results = append(r.results, res)
+}
+
+// AfterSuiteDidRun does nothing.
+func (r *Recorder) AfterSuiteDidRun(summary *types.SetupSummary) {}
+
+// SpecSuiteDidEnd does nothing.
+func (r *Recorder) SpecSuiteDidEnd(summary *types.SuiteSummary) {}
+
+// Write stores the results recorded in each of formats in dir. suffix distinguishes files of the run.
+func (r *Recorder) Write(dir, suffix string, formats []Format) error {
+	results := r.Results()
+	for _, f := range formats {
+		var err error
+		switch f {
+		case Allure:
+			err = WriteAllure(filepath.Join(dir, AllureDir), results)
+		case SonarQube:
+			err = WriteSonarQube(filepath.Join(dir, fmt.Sprintf("sonarqube_%s.xml", suffix)), results)
+		}
+		if err != nil {
+			return fmt.Errorf("couldn't write %s results: %v", f, err)
+		}
+	}
+	return nil
+}
+
+func specName(summary *types.SpecSummary) string {
+	texts := summary.ComponentTexts
+	if len(texts) > 1 {
+		texts = texts[1:]
+	}
+	return strings.Join(texts, " ")
+}
+
+// relativePath returns file relative to the working directory, which is the root of the repository when testing.
+func relativePath(file string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return file
+	}
+	if rel, err := filepath.Rel(wd, file); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel)
+	}
+	return file
+}
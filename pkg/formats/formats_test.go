@@ -0,0 +1,131 @@
+package formats
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/onsi/ginkgo/types"
+)
+
+func record(t *testing.T) *Recorder {
+	now := time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)
+	r := NewRecorder()
+	r.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+
+	for _, s := range []*types.SpecSummary{
+		{
+			ComponentTexts:         []string{"[Top Level]", "Routes", "should be admitted"},
+			ComponentCodeLocations: []types.CodeLocation{{FileName: "/tmp/top.go"}, {FileName: "/tmp/routes.go"}},
+			State:                  types.SpecStatePassed,
+		},
+		{
+			ComponentTexts: []string{"[Top Level]", "Routes", "should serve traffic"},
+			State:          types.SpecStateFailed,
+			Failure: types.SpecFailure{
+				Message:  "route returned 503",
+				Location: types.CodeLocation{FileName: "/tmp/routes.go", LineNumber: 42, FullStackTrace: "stack"},
+			},
+		},
+		{
+			ComponentTexts: []string{"[Top Level]", "Storage", "should resize volumes"},
+			State:          types.SpecStateSkipped,
+			Failure:        types.SpecFailure{Message: "[capability-missing] volumes can't be resized"},
+		},
+		{
+			ComponentTexts: []string{"[Top Level]", "Storage", "should mount volumes"},
+			State:          types.SpecStateTimedOut,
+		},
+	} {
+		r.SpecWillRun(s)
+		r.SpecDidComplete(s)
+	}
+	return r
+}
+
+func TestRecorder(t *testing.T) {
+	results := record(t).Results()
+	if len(results) != 4 {
+		t.Fatalf("expected 4 results, got %d", len(results))
+	}
+
+	for i, expected := range []Status{Passed, Failed, Skipped, Broken} {
+		if results[i].Status != expected {
+			t.Errorf("expected '%s' to be %s, got %s", results[i].Name, expected, results[i].Status)
+		}
+	}
+
+	failed := results[1]
+	if failed.Suite != "Routes" || failed.Name != "Routes should serve traffic" || failed.Message != "route returned 503" ||
+		!strings.Contains(failed.Trace, "/tmp/routes.go:42") || failed.Stop.Sub(failed.Start) != time.Second {
+		t.Errorf("unexpected failed result: %+v", failed)
+	}
+	if results[0].File != "/tmp/routes.go" {
+		t.Errorf("expected spec to be located by its innermost component, got '%s'", results[0].File)
+	}
+}
+
+func TestParse(t *testing.T) {
+	if formats, err := Parse([]string{"Allure", " sonarqube"}); err != nil || len(formats) != 2 ||
+		formats[0] != Allure || formats[1] != SonarQube {
+		t.Errorf("expected allure and sonarqube, got %v: %v", formats, err)
+	}
+	if _, err := Parse([]string{"html"}); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestWrite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "formats")
+	if err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err = record(t).Write(dir, "abc", Formats); err != nil {
+		t.Fatalf("failed to write results: %v", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, AllureDir, "*-result.json"))
+	if len(files) != 4 {
+		t.Fatalf("expected an Allure result for each spec, got %v", files)
+	}
+	statuses := map[Status]int{}
+	for _, file := range files {
+		data, _ := ioutil.ReadFile(file)
+		var res allureResult
+		if err = json.Unmarshal(data, &res); err != nil {
+			t.Fatalf("invalid Allure result: %v", err)
+		} else if res.Stage != "finished" || res.Stop <= res.Start || len(res.HistoryID) == 0 {
+			t.Errorf("unexpected Allure result: %+v", res)
+		}
+		statuses[res.Status]++
+	}
+	if statuses[Passed] != 1 || statuses[Failed] != 1 || statuses[Skipped] != 1 || statuses[Broken] != 1 {
+		t.Errorf("expected one result of each status, got %v", statuses)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "sonarqube_abc.xml"))
+	if err != nil {
+		t.Fatalf("failed to read SonarQube results: %v", err)
+	}
+	for _, expected := range []string{
+		`<testExecutions version="1">`,
+		`<file path="/tmp/routes.go">`,
+		`<testCase name="Routes should be admitted" duration="1000"></testCase>`,
+		`<failure message="route returned 503">`,
+		`<skipped message="[capability-missing] volumes can&#39;t be resized">`,
+		`<error message="">`,
+	} {
+		if !strings.Contains(string(data), expected) {
+			t.Errorf("expected SonarQube results to contain '%s', got:\n%s", expected, data)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package formats
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// sonarTestExecutions is SonarQube's generic test execution format, grouping test cases by the file defining them.
+type sonarTestExecutions struct {
+	XMLName xml.Name    `xml:"testExecutions"`
+	Version int         `xml:"version,attr"`
+	Files   []sonarFile `xml:"file"`
+}
+
+type sonarFile struct {
+	Path      string          `xml:"path,attr"`
+	TestCases []sonarTestCase `xml:"testCase"`
+}
+
+type sonarTestCase struct {
+	Name     string        `xml:"name,attr"`
+	Duration int64         `xml:"duration,attr"`
+	Failure  *sonarMessage `xml:"failure,omitempty"`
+	Error    *sonarMessage `xml:"error,omitempty"`
+	Skipped  *sonarMessage `xml:"skipped,omitempty"`
+}
+
+type sonarMessage struct {
+	Message string `xml:"message,attr"`
+	Trace   string `xml:",chardata"`
+}
+
+// WriteSonarQube writes results to file as SonarQube generic test execution data. Results without a file are
+// reported as defined in an unknown file.
+func WriteSonarQube(file string, results []Result) error {
+	execs := sonarTestExecutions{Version: 1}
+	byPath := map[string]int{}
+	for _, res := range results {
+		path := res.File
+		if path == "" {
+			path = "unknown"
+		}
+		i, ok := byPath[path]
+		if !ok {
+			i = len(execs.Files)
+			byPath[path] = i
+			execs.Files = append(execs.Files, sonarFile{Path: path})
+		}
+
+		tc := sonarTestCase{
+			Name:     res.Name,
+			Duration: res.Stop.Sub(res.Start).Nanoseconds() / int64(time.Millisecond),
+		}
+		msg := &sonarMessage{Message: res.Message, Trace: res.Trace}
+		switch res.Status {
+		case Failed:
+			tc.Failure = msg
+		case Broken:
+			tc.Error = msg
+		case Skipped:
+			tc.Skipped = msg
+		}
+		execs.Files[i].TestCases = append(execs.Files[i].TestCases, tc)
+	}
+
+	data, err := xml.MarshalIndent(execs, "", "  ")
+	if err != nil {
+		return fmt.Errorf("couldn't encode test executions: %v", err)
+	}
+
+	os.MkdirAll(filepath.Dir(file), os.ModePerm)
+	if err = ioutil.WriteFile(file, append([]byte(xml.Header), data...), os.ModePerm); err != nil {
+		return fmt.Errorf("couldn't write test executions to '%s': %v", file, err)
+	}
+	return nil
+}
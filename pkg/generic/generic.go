@@ -0,0 +1,98 @@
+// Package generic tests any OpenShift cluster accessed with a kubeconfig. Clusters aren't created or managed through
+// OSD, so phases which need it are skipped.
+package generic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"time"
+
+	configclient "github.com/openshift/client-go/config/clientset/versioned"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/openshift/osde2e/pkg/config"
+	"github.com/openshift/osde2e/pkg/health"
+	"github.com/openshift/osde2e/pkg/upgrade"
+)
+
+// Provider tests the cluster accessed by a kubeconfig.
+type Provider struct {
+	kubeconfig []byte
+	config     configclient.Interface
+	health     *health.Checker
+}
+
+// New returns a provider for the cluster accessed with the kubeconfig at path.
+func New(path string) (*Provider, error) {
+	if path == "" {
+		return nil, errors.New("TEST_KUBECONFIG must be set to use the generic provider")
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read kubeconfig '%s': %v", path, err)
+	}
+
+	restConfig, err := clientcmd.RESTConfigFromKubeConfig(data)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't load kubeconfig '%s': %v", path, err)
+	}
+
+	client, err := configclient.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't configure Config client: %v", err)
+	}
+	kube, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't configure Kubernetes client: %v", err)
+	}
+
+	log.Printf("Using generic provider with TEST_KUBECONFIG of '%s'.", path)
+	return &Provider{
+		kubeconfig: data,
+		config:     client,
+		health:     &health.Checker{Kube: kube, Config: client},
+	}, nil
+}
+
+// Cluster returns the ID and current version of the cluster from its ClusterVersion.
+func (p *Provider) Cluster() (id, version string, err error) {
+	cv, err := p.config.ConfigV1().ClusterVersions().Get(upgrade.ClusterVersionName, metav1.GetOptions{})
+	if err != nil {
+		return "", "", fmt.Errorf("couldn't get ClusterVersion: %v", err)
+	}
+	return string(cv.Spec.ClusterID), cv.Status.Desired.Version, nil
+}
+
+// LaunchCluster returns an error as the generic provider can't create clusters.
+func (p *Provider) LaunchCluster(cfg *config.Config) (string, error) {
+	return "", errors.New("the generic provider can't create clusters, CLUSTER_ID must be set")
+}
+
+// WaitForClusterReady blocks until the cluster is healthy or timeout, checking every interval.
+func (p *Provider) WaitForClusterReady(clusterID string, timeout, interval time.Duration) error {
+	log.Printf("Waiting %v for cluster '%s' to be healthy...", timeout, clusterID)
+	_, err := p.health.Wait(context.Background(), interval, timeout)
+	return err
+}
+
+// ClusterKubeconfig returns the kubeconfig the provider was created with.
+func (p *Provider) ClusterKubeconfig(clusterID string) ([]byte, error) {
+	return p.kubeconfig, nil
+}
+
+// FullLogs returns no logs, as the cluster doesn't keep any outside of itself.
+func (p *Provider) FullLogs(clusterID string, ids ...string) (map[string][]byte, error) {
+	return map[string][]byte{}, nil
+}
+
+// DeleteCluster leaves the cluster running, as it isn't owned by the provider.
+func (p *Provider) DeleteCluster(clusterID string) error {
+	log.Printf("The generic provider doesn't delete clusters, leaving '%s' running.", clusterID)
+	return nil
+}
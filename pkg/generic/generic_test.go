@@ -0,0 +1,63 @@
+package generic
+
+import (
+	"testing"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/client-go/config/clientset/versioned/fake"
+	kubev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openshift/osde2e/pkg/health"
+	"github.com/openshift/osde2e/pkg/upgrade"
+)
+
+func TestProvider(t *testing.T) {
+	cv := &configv1.ClusterVersion{
+		ObjectMeta: metav1.ObjectMeta{Name: upgrade.ClusterVersionName},
+		Spec:       configv1.ClusterVersionSpec{ClusterID: "0f1e2d3c"},
+		Status: configv1.ClusterVersionStatus{
+			Desired: configv1.Update{Version: "4.2.0"},
+		},
+	}
+	degraded := &configv1.ClusterOperator{
+		ObjectMeta: metav1.ObjectMeta{Name: "ingress"},
+		Status: configv1.ClusterOperatorStatus{
+			Conditions: []configv1.ClusterOperatorStatusCondition{
+				{Type: configv1.OperatorAvailable, Status: configv1.ConditionTrue},
+				{Type: configv1.OperatorDegraded, Status: configv1.ConditionTrue},
+			},
+		},
+	}
+	node := &kubev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker"},
+		Status: kubev1.NodeStatus{
+			Conditions: []kubev1.NodeCondition{{Type: kubev1.NodeReady, Status: kubev1.ConditionTrue}},
+		},
+	}
+	config := fake.NewSimpleClientset(cv, degraded)
+	p := &Provider{
+		kubeconfig: []byte("kubeconfig"),
+		config:     config,
+		health:     &health.Checker{Kube: kubefake.NewSimpleClientset(node), Config: config},
+	}
+
+	if id, version, err := p.Cluster(); err != nil {
+		t.Errorf("failed to get cluster: %v", err)
+	} else if id != "0f1e2d3c" || version != "4.2.0" {
+		t.Errorf("expected cluster '0f1e2d3c' at 4.2.0, got '%s' at %s", id, version)
+	}
+
+	if err := p.WaitForClusterReady("0f1e2d3c", 10*time.Millisecond, time.Millisecond); err == nil {
+		t.Error("expected degraded operator to fail readiness")
+	}
+
+	if _, err := p.LaunchCluster(nil); err == nil {
+		t.Error("expected clusters not to be created")
+	}
+	if err := p.DeleteCluster("0f1e2d3c"); err != nil {
+		t.Errorf("expected clusters to be left running: %v", err)
+	}
+}
@@ -0,0 +1,185 @@
+package golden
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+)
+
+// Kinds of differences.
+const (
+	Added   = "added"
+	Removed = "removed"
+	Changed = "changed"
+)
+
+// Version keys of a baseline which are compared along with its objects.
+const (
+	clusterVersionKey = "clusterversion"
+	operatorPrefix    = "clusteroperator "
+	olmOperatorPrefix = "csv "
+)
+
+// Difference is a way a cluster differs from its baseline.
+type Difference struct {
+	Kind string `json:"kind"`
+
+	// Object is the Key of the object differing, or the operator whose version does.
+	Object string `json:"object"`
+
+	// Field is the path of the field changed, such as 'spec.template.spec.containers[0].image'. It's empty when the
+	// object itself was added or removed.
+	Field string `json:"field,omitempty"`
+
+	// Baseline and Current are the JSON encoded values of the field in the baseline and in the cluster.
+	Baseline string `json:"baseline,omitempty"`
+	Current  string `json:"current,omitempty"`
+}
+
+func (d Difference) String() string {
+	if d.Field == "" {
+		return d.Object
+	}
+	return d.Object + " " + d.Field
+}
+
+// Report is how a cluster differs from a baseline.
+type Report struct {
+	BaselineVersion string       `json:"baselineVersion"`
+	CurrentVersion  string       `json:"currentVersion"`
+	Differences     []Difference `json:"differences"`
+
+	// Ignored is how many differences matched an expression ignoring them.
+	Ignored int `json:"ignored,omitempty"`
+}
+
+// Compare returns how current differs from baseline, leaving out differences whose String matches any of ignore.
+// Differences are sorted by object and field.
+func Compare(baseline, current *Baseline, ignore []*regexp.Regexp) *Report {
+	r := &Report{
+		BaselineVersion: baseline.Version,
+		CurrentVersion:  current.Version,
+		Differences:     []Difference{},
+	}
+
+	var diffs []Difference
+	if baseline.Version != current.Version {
+		diffs = append(diffs, Difference{
+			Kind:     Changed,
+			Object:   clusterVersionKey,
+			Field:    "version",
+			Baseline: encode(baseline.Version),
+			Current:  encode(current.Version),
+		})
+	}
+	diffs = append(diffs, compareVersions(operatorPrefix, baseline.Operators, current.Operators)...)
+	diffs = append(diffs, compareVersions(olmOperatorPrefix, baseline.OLMOperators, current.OLMOperators)...)
+
+	for key, obj := range baseline.Objects {
+		if cur, ok := current.Objects[key]; !ok {
+			diffs = append(diffs, Difference{Kind: Removed, Object: key})
+		} else {
+			diffs = append(diffs, compareValues(key, "", obj, cur)...)
+		}
+	}
+	for key := range current.Objects {
+		if _, ok := baseline.Objects[key]; !ok {
+			diffs = append(diffs, Difference{Kind: Added, Object: key})
+		}
+	}
+
+	for _, d := range diffs {
+		if matchesAny(ignore, d.String()) {
+			r.Ignored++
+		} else {
+			r.Differences = append(r.Differences, d)
+		}
+	}
+	sort.Slice(r.Differences, func(i, j int) bool {
+		if r.Differences[i].Object != r.Differences[j].Object {
+			return r.Differences[i].Object < r.Differences[j].Object
+		}
+		return r.Differences[i].Field < r.Differences[j].Field
+	})
+	return r
+}
+
+// compareVersions returns how the versions of operators differ, naming them with prefix.
+func compareVersions(prefix string, baseline, current map[string]string) (diffs []Difference) {
+	for name, version := range baseline {
+		if cur, ok := current[name]; !ok {
+			diffs = append(diffs, Difference{Kind: Removed, Object: prefix + name})
+		} else if cur != version {
+			diffs = append(diffs, Difference{
+				Kind:     Changed,
+				Object:   prefix + name,
+				Field:    "version",
+				Baseline: encode(version),
+				Current:  encode(cur),
+			})
+		}
+	}
+	for name := range current {
+		if _, ok := baseline[name]; !ok {
+			diffs = append(diffs, Difference{Kind: Added, Object: prefix + name})
+		}
+	}
+	return
+}
+
+// compareValues returns how the field at path of object differs. Maps are compared by key and lists of the same
+// length by index, so changes are reported for the innermost field they're in.
+func compareValues(object, path string, baseline, current interface{}) (diffs []Difference) {
+	switch b := baseline.(type) {
+	case map[string]interface{}:
+		if c, ok := current.(map[string]interface{}); ok {
+			for k, v := range b {
+				diffs = append(diffs, compareValues(object, join(path, k), v, c[k])...)
+			}
+			for k, v := range c {
+				if _, ok := b[k]; !ok {
+					diffs = append(diffs, compareValues(object, join(path, k), nil, v)...)
+				}
+			}
+			return
+		}
+	case []interface{}:
+		if c, ok := current.([]interface{}); ok && len(c) == len(b) {
+			for i := range b {
+				diffs = append(diffs, compareValues(object, fmt.Sprintf("%s[%d]", path, i), b[i], c[i])...)
+			}
+			return
+		}
+	}
+
+	if reflect.DeepEqual(baseline, current) {
+		return nil
+	}
+	d := Difference{Kind: Changed, Object: object, Field: path}
+	if baseline != nil {
+		d.Baseline = encode(baseline)
+	}
+	if current != nil {
+		d.Current = encode(current)
+	}
+	return []Difference{d}
+}
+
+// join returns the path of field in the object at path.
+func join(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
+
+// encode returns v as JSON.
+func encode(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
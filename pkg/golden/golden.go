@@ -0,0 +1,297 @@
+// Package golden captures a normalized baseline of a known-good cluster, such as the resources in its managed
+// namespaces, its operator versions, and its cluster-wide configuration, and compares clusters under test to it so
+// unexpected changes stand out.
+package golden
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/openshift/osde2e/pkg/fingerprint"
+	"github.com/openshift/osde2e/pkg/helper"
+)
+
+// File is the name baselines are written as by default.
+const File = "golden.json"
+
+var (
+	namespaces = schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+	secrets    = schema.GroupVersionResource{Version: "v1", Resource: "secrets"}
+	services   = schema.GroupVersionResource{Version: "v1", Resource: "services"}
+	accounts   = schema.GroupVersionResource{Version: "v1", Resource: "serviceaccounts"}
+	csvs       = schema.GroupVersionResource{Group: "operators.coreos.com", Version: "v1alpha1", Resource: "clusterserviceversions"}
+)
+
+// Resources are the resources captured in managed namespaces.
+var Resources = []schema.GroupVersionResource{
+	{Version: "v1", Resource: "configmaps"},
+	secrets,
+	services,
+	accounts,
+	{Group: "apps", Version: "v1", Resource: "deployments"},
+	{Group: "apps", Version: "v1", Resource: "daemonsets"},
+	{Group: "apps", Version: "v1", Resource: "statefulsets"},
+	{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "roles"},
+	{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "rolebindings"},
+	{Group: "networking.k8s.io", Version: "v1", Resource: "networkpolicies"},
+}
+
+// ClusterResources are the cluster-wide configuration resources captured.
+var ClusterResources = []schema.GroupVersionResource{
+	{Group: "config.openshift.io", Version: "v1", Resource: "apiservers"},
+	{Group: "config.openshift.io", Version: "v1", Resource: "authentications"},
+	{Group: "config.openshift.io", Version: "v1", Resource: "consoles"},
+	{Group: "config.openshift.io", Version: "v1", Resource: "dnses"},
+	{Group: "config.openshift.io", Version: "v1", Resource: "featuregates"},
+	{Group: "config.openshift.io", Version: "v1", Resource: "images"},
+	{Group: "config.openshift.io", Version: "v1", Resource: "ingresses"},
+	{Group: "config.openshift.io", Version: "v1", Resource: "networks"},
+	{Group: "config.openshift.io", Version: "v1", Resource: "oauths"},
+	{Group: "config.openshift.io", Version: "v1", Resource: "proxies"},
+	{Group: "config.openshift.io", Version: "v1", Resource: "schedulers"},
+}
+
+// volatileAnnotations differ between clusters or change on their own, so they're left out of baselines.
+var volatileAnnotations = []string{
+	"kubectl.kubernetes.io/last-applied-configuration",
+	"deployment.kubernetes.io/revision",
+	"control-plane.alpha.kubernetes.io/leader",
+	"openshift.io/sa.scc.mcs",
+	"openshift.io/sa.scc.supplemental-groups",
+	"openshift.io/sa.scc.uid-range",
+}
+
+// generatedSecretTypes are secrets created with random names for service accounts, which can't be compared.
+var generatedSecretTypes = map[string]bool{
+	"kubernetes.io/service-account-token": true,
+	"kubernetes.io/dockercfg":             true,
+}
+
+// Baseline is the normalized state of a cluster.
+type Baseline struct {
+	ClusterID string    `json:"clusterID,omitempty"`
+	Collected time.Time `json:"collected"`
+	Version   string    `json:"version"`
+
+	// Operators are the versions of ClusterOperators by name.
+	Operators map[string]string `json:"operators"`
+
+	// OLMOperators are the versions of operators installed by OLM, by the namespace and name of their CSV.
+	OLMOperators map[string]string `json:"olmOperators,omitempty"`
+
+	// Objects are normalized resources by their Key.
+	Objects map[string]map[string]interface{} `json:"objects"`
+}
+
+// Key identifies the resource name of gvr in namespace, which is empty for cluster-wide resources.
+func Key(gvr schema.GroupVersionResource, namespace, name string) string {
+	resource := gvr.Resource
+	if gvr.Group != "" {
+		resource += "." + gvr.Group
+	}
+	if namespace != "" {
+		name = namespace + "/" + name
+	}
+	return resource + " " + name
+}
+
+// Capture returns the baseline of the cluster h is connected to, with resources in namespaces matching any of
+// managed. OLM operators are left out if OLM isn't installed.
+func Capture(h *helper.H, managed []*regexp.Regexp) (*Baseline, error) {
+	b := &Baseline{
+		ClusterID: h.ClusterID,
+		Collected: time.Now().UTC(),
+		Objects:   map[string]map[string]interface{}{},
+	}
+
+	cv, err := h.Cfg().ConfigV1().ClusterVersions().Get("version", metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get cluster version: %v", err)
+	}
+	b.Version = cv.Status.Desired.Version
+
+	cos, err := h.Cfg().ConfigV1().ClusterOperators().List(metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("couldn't list ClusterOperators: %v", err)
+	}
+	b.Operators = fingerprint.OperatorVersions(cos.Items)
+
+	if list, err := h.Dynamic().Resource(csvs).List(metav1.ListOptions{}); err == nil {
+		b.OLMOperators = fingerprint.CSVVersions(list.Items)
+	}
+
+	for _, gvr := range ClusterResources {
+		list, err := h.Dynamic().Resource(gvr).List(metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("couldn't list %s: %v", gvr.Resource, err)
+		}
+		b.Add(gvr, list.Items)
+	}
+
+	nsList, err := h.Dynamic().Resource(namespaces).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("couldn't list namespaces: %v", err)
+	}
+	for _, ns := range nsList.Items {
+		if !matchesAny(managed, ns.GetName()) {
+			continue
+		}
+		b.Add(namespaces, []unstructured.Unstructured{ns})
+
+		for _, gvr := range Resources {
+			list, err := h.Dynamic().Resource(gvr).Namespace(ns.GetName()).List(metav1.ListOptions{})
+			if err != nil {
+				return nil, fmt.Errorf("couldn't list %s in '%s': %v", gvr.Resource, ns.GetName(), err)
+			}
+			b.Add(gvr, list.Items)
+		}
+	}
+	return b, nil
+}
+
+// Add normalizes objects of gvr into the baseline. Secrets generated for service accounts are left out.
+func (b *Baseline) Add(gvr schema.GroupVersionResource, objs []unstructured.Unstructured) {
+	for i := range objs {
+		obj := &objs[i]
+		if gvr == secrets {
+			secretType, _, _ := unstructured.NestedString(obj.Object, "type")
+			if generatedSecretTypes[secretType] {
+				continue
+			}
+		}
+		b.Objects[Key(gvr, obj.GetNamespace(), obj.GetName())] = Normalize(gvr, obj)
+	}
+}
+
+// Normalize returns what's compared of obj: its labels, annotations which don't change on their own, and content
+// other than its status. Fields assigned differently to each cluster are dropped, and only the keys of secrets are
+// kept so baselines never hold credentials.
+func Normalize(gvr schema.GroupVersionResource, obj *unstructured.Unstructured) map[string]interface{} {
+	n := map[string]interface{}{}
+	for field, value := range obj.DeepCopy().Object {
+		switch field {
+		case "apiVersion", "kind", "metadata", "status":
+		default:
+			n[field] = value
+		}
+	}
+
+	meta := map[string]interface{}{}
+	if labels := obj.GetLabels(); len(labels) != 0 {
+		meta["labels"] = labels
+	}
+	if annotations := obj.GetAnnotations(); len(annotations) != 0 {
+		for _, a := range volatileAnnotations {
+			delete(annotations, a)
+		}
+		if len(annotations) != 0 {
+			meta["annotations"] = annotations
+		}
+	}
+	if len(meta) != 0 {
+		n["metadata"] = meta
+	}
+
+	switch gvr {
+	case secrets:
+		if data, ok := n["data"].(map[string]interface{}); ok {
+			keys := make([]string, 0, len(data))
+			for k := range data {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			n["data"] = keys
+		}
+	case services:
+		unstructured.RemoveNestedField(n, "spec", "clusterIP")
+		unstructured.RemoveNestedField(n, "spec", "clusterIPs")
+		unstructured.RemoveNestedField(n, "spec", "healthCheckNodePort")
+		if ports, ok, _ := unstructured.NestedSlice(n, "spec", "ports"); ok {
+			for _, p := range ports {
+				if port, ok := p.(map[string]interface{}); ok {
+					delete(port, "nodePort")
+				}
+			}
+			unstructured.SetNestedSlice(n, ports, "spec", "ports")
+		}
+	case accounts:
+		// tokens and pull secrets of service accounts are generated with random names
+		delete(n, "secrets")
+		delete(n, "imagePullSecrets")
+	}
+
+	// objects read back from baselines hold JSON types, so captured ones are made to as well
+	if data, err := json.Marshal(n); err == nil {
+		var decoded map[string]interface{}
+		if err = json.Unmarshal(data, &decoded); err == nil {
+			return decoded
+		}
+	}
+	return n
+}
+
+// Read loads a baseline written by Write.
+func Read(filename string) (*Baseline, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read baseline: %v", err)
+	}
+	b := new(Baseline)
+	if err = json.Unmarshal(data, b); err != nil {
+		return nil, fmt.Errorf("couldn't decode baseline '%s': %v", filename, err)
+	}
+	return b, nil
+}
+
+// Write stores the baseline as filename.
+func (b *Baseline) Write(filename string) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return fmt.Errorf("couldn't encode baseline: %v", err)
+	}
+
+	if dir := filepath.Dir(filename); dir != "" {
+		os.MkdirAll(dir, os.ModePerm)
+	}
+	if err = ioutil.WriteFile(filename, data, os.ModePerm); err != nil {
+		return fmt.Errorf("couldn't write baseline to '%s': %v", filename, err)
+	}
+	return nil
+}
+
+// matchesAny returns whether s matches any of exprs.
+func matchesAny(exprs []*regexp.Regexp, s string) bool {
+	for _, expr := range exprs {
+		if expr.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseExprs compiles a list of regular expressions.
+func ParseExprs(exprs []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(exprs))
+	for _, expr := range exprs {
+		if expr = strings.TrimSpace(expr); expr == "" {
+			continue
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid expression '%s': %v", expr, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
@@ -0,0 +1,160 @@
+package golden
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var deployments = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+
+func deployment(image string, replicas int64) unstructured.Unstructured {
+	return unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":            "console",
+			"namespace":       "openshift-console",
+			"uid":             "1234",
+			"resourceVersion": "5678",
+			"labels":          map[string]interface{}{"app": "console"},
+			"annotations": map[string]interface{}{
+				"deployment.kubernetes.io/revision": "3",
+				"operator.openshift.io/spec-hash":   "abc",
+			},
+		},
+		"spec": map[string]interface{}{
+			"replicas": replicas,
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{"name": "console", "image": image},
+					},
+				},
+			},
+		},
+		"status": map[string]interface{}{"readyReplicas": replicas},
+	}}
+}
+
+func TestNormalize(t *testing.T) {
+	d := deployment("console:v1", 2)
+	n := Normalize(deployments, &d)
+	expected := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"labels":      map[string]interface{}{"app": "console"},
+			"annotations": map[string]interface{}{"operator.openshift.io/spec-hash": "abc"},
+		},
+		"spec": map[string]interface{}{
+			"replicas": float64(2),
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{"name": "console", "image": "console:v1"},
+					},
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(n, expected) {
+		t.Errorf("expected deployment normalized to %v, got %v", expected, n)
+	}
+
+	secret := unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "pull-secret", "namespace": "openshift-config"},
+		"type":     "kubernetes.io/dockerconfigjson",
+		"data":     map[string]interface{}{"b": "c2VjcmV0", "a": "c2VjcmV0"},
+	}}
+	if n = Normalize(secrets, &secret); !reflect.DeepEqual(n["data"], []interface{}{"a", "b"}) {
+		t.Errorf("expected only keys of secret, got %v", n["data"])
+	}
+
+	svc := unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "router", "namespace": "openshift-ingress"},
+		"spec": map[string]interface{}{
+			"clusterIP": "172.30.0.10",
+			"ports":     []interface{}{map[string]interface{}{"port": int64(80), "nodePort": int64(31234)}},
+		},
+	}}
+	n = Normalize(services, &svc)
+	if expected := map[string]interface{}{
+		"spec": map[string]interface{}{"ports": []interface{}{map[string]interface{}{"port": float64(80)}}},
+	}; !reflect.DeepEqual(n, expected) {
+		t.Errorf("expected service normalized to %v, got %v", expected, n)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	baseline := &Baseline{
+		Version:   "4.1.0",
+		Operators: map[string]string{"console": "4.1.0", "dns": "4.1.0"},
+		Objects:   map[string]map[string]interface{}{},
+	}
+	baseline.Add(deployments, []unstructured.Unstructured{deployment("console:v1", 2)})
+	baseline.Objects["configmaps openshift-console/removed"] = map[string]interface{}{}
+
+	current := &Baseline{
+		Version:   "4.1.0",
+		Operators: map[string]string{"console": "4.1.1", "dns": "4.1.0", "ingress": "4.1.0"},
+		Objects:   map[string]map[string]interface{}{},
+	}
+	current.Add(deployments, []unstructured.Unstructured{deployment("console:v2", 3)})
+	current.Add(secrets, []unstructured.Unstructured{{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "builder-token-x7k2p", "namespace": "openshift-console"},
+		"type":     "kubernetes.io/service-account-token",
+	}}})
+
+	key := Key(deployments, "openshift-console", "console")
+	expected := []Difference{
+		{Kind: Changed, Object: operatorPrefix + "console", Field: "version", Baseline: `"4.1.0"`, Current: `"4.1.1"`},
+		{Kind: Added, Object: operatorPrefix + "ingress"},
+		{Kind: Removed, Object: "configmaps openshift-console/removed"},
+		{Kind: Changed, Object: key, Field: "spec.replicas", Baseline: "2", Current: "3"},
+		{Kind: Changed, Object: key, Field: "spec.template.spec.containers[0].image", Baseline: `"console:v1"`,
+			Current: `"console:v2"`},
+	}
+	r := Compare(baseline, current, nil)
+	if !reflect.DeepEqual(r.Differences, expected) {
+		t.Errorf("expected differences %+v, got %+v", expected, r.Differences)
+	}
+
+	ignore, err := ParseExprs([]string{`^clusteroperator `, `\.image$`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r = Compare(baseline, current, ignore); len(r.Differences) != 2 || r.Ignored != 3 {
+		t.Errorf("expected 3 differences ignored, got %d leaving %+v", r.Ignored, r.Differences)
+	}
+
+	if r = Compare(baseline, baseline, nil); len(r.Differences) != 0 {
+		t.Errorf("expected no differences from itself, got %+v", r.Differences)
+	}
+}
+
+func TestReadWrite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "golden")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	b := &Baseline{Version: "4.1.0", Operators: map[string]string{}, Objects: map[string]map[string]interface{}{}}
+	b.Add(deployments, []unstructured.Unstructured{deployment("console:v1", 2)})
+	filename := filepath.Join(dir, File)
+	if err = b.Write(filename); err != nil {
+		t.Fatalf("failed to write baseline: %v", err)
+	}
+
+	read, err := Read(filename)
+	if err != nil {
+		t.Fatalf("failed to read baseline: %v", err)
+	}
+	if r := Compare(b, read, nil); len(r.Differences) != 0 {
+		t.Errorf("expected baseline to be the same after being read, got %+v", r.Differences)
+	}
+}
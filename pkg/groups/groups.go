@@ -0,0 +1,329 @@
+// Package groups sorts suites into sig-style groups, such as networking and storage, which may each be given a time
+// budget. Once a group spends its budget its running spec is stopped and the rest are skipped, and each group is
+// summarized in results.
+package groups
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/onsi/ginkgo"
+	ginkgoconfig "github.com/onsi/ginkgo/config"
+	"github.com/onsi/ginkgo/types"
+
+	"github.com/openshift/osde2e/pkg/junitprops"
+	"github.com/openshift/osde2e/pkg/skips"
+)
+
+// Group is a set of suites testing the same area, like a Kubernetes SIG.
+type Group string
+
+const (
+	// Networking suites test routes, ingress, and traffic to the cluster.
+	Networking Group = "networking"
+
+	// Storage suites test volumes and what's stored on them.
+	Storage Group = "storage"
+
+	// Operators suites test the operators managing the cluster.
+	Operators Group = "operators"
+
+	// Security suites test what workloads and users are allowed to do.
+	Security Group = "security"
+
+	// Other is the group of suites which weren't declared with one.
+	Other Group = "other"
+)
+
+// Groups are all groups, in the order they're reported.
+var Groups = []Group{Networking, Storage, Operators, Security, Other}
+
+// PropertyGroup is set on testcases to the group of their suite.
+const PropertyGroup = "group"
+
+// suites are the groups of suites by their text.
+var suites = struct {
+	sync.Mutex
+	groups map[string]Group
+}{groups: map[string]Group{}}
+
+// Describe declares a suite belonging to group g, like ginkgo.Describe. Suites declared in several files must use
+// the same group.
+func Describe(g Group, text string, body func()) bool {
+	if err := register(g, text); err != nil {
+		panic(err.Error())
+	}
+	return ginkgo.Describe(text, body)
+}
+
+// register puts the suite with text in group g.
+func register(g Group, text string) error {
+	suites.Lock()
+	defer suites.Unlock()
+	if existing, ok := suites.groups[text]; ok && existing != g {
+		return fmt.Errorf("suite '%s' is in group '%s', it can't also be in '%s'", text, existing, g)
+	}
+	suites.groups[text] = g
+	return nil
+}
+
+// Of returns the group of the suite with text, which is Other if it wasn't declared with one.
+func Of(suite string) Group {
+	suites.Lock()
+	defer suites.Unlock()
+	if g, ok := suites.groups[suite]; ok {
+		return g
+	}
+	return Other
+}
+
+// ParseBudgets reads budgets from a map of group to duration, such as 'storage' to '45m'.
+func ParseBudgets(budgets map[string]string) (map[Group]time.Duration, error) {
+	parsed := make(map[Group]time.Duration, len(budgets))
+	for name, durStr := range budgets {
+		g := Group(name)
+		if !known(g) {
+			return nil, fmt.Errorf("unknown group '%s', must be one of %s", name, groupList())
+		}
+
+		d, err := time.ParseDuration(durStr)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("budget '%s' of group '%s' must be a positive duration", durStr, name)
+		}
+		parsed[g] = d
+	}
+	return parsed, nil
+}
+
+// Summary is how a group did in a run.
+type Summary struct {
+	Group Group `json:"group"`
+
+	// Budget is how long the group may run. It's 0 if the group isn't limited.
+	Budget time.Duration `json:"budget,omitempty"`
+
+	// Spent is how long the group's specs ran.
+	Spent time.Duration `json:"spent"`
+
+	Passed  int `json:"passed"`
+	Failed  int `json:"failed"`
+	Skipped int `json:"skipped"`
+
+	// OverBudget specs were skipped because the group spent its budget.
+	OverBudget int `json:"overBudget"`
+}
+
+// Exceeded returns true if the group has a budget and spent it.
+func (s Summary) Exceeded() bool {
+	return s.Budget > 0 && s.Spent >= s.Budget
+}
+
+func (s Summary) String() string {
+	str := fmt.Sprintf("%s: %d passed, %d failed, %d skipped in %v", s.Group, s.Passed, s.Failed, s.Skipped,
+		s.Spent.Round(time.Second))
+	if s.Budget > 0 {
+		str += fmt.Sprintf(" of %v budget", s.Budget)
+	}
+	if s.OverBudget > 0 {
+		str += fmt.Sprintf(", %d skipped over budget", s.OverBudget)
+	}
+	return str
+}
+
+// Budgets is the Tracker of the run.
+var Budgets = new(Tracker)
+
+// Tracker is a Ginkgo reporter recording how long each group has run, so groups which spend their budget can be
+// stopped, and summarizing them.
+type Tracker struct {
+	mu      sync.Mutex
+	budgets map[Group]time.Duration
+	total   time.Duration
+	groups  map[Group]*Summary
+	tests   map[string]Group
+}
+
+// SetBudgets limits how long each group in budgets may run. Groups not in budgets aren't limited.
+func (t *Tracker) SetBudgets(budgets map[Group]time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.budgets = budgets
+}
+
+// SetTotalBudget limits how long specs of all groups may run together. They aren't limited if total is 0.
+func (t *Tracker) SetTotalBudget(total time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.total = total
+}
+
+// Spent returns how long specs of all groups have run.
+func (t *Tracker) Spent() (spent time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, s := range t.groups {
+		spent += s.Spent
+	}
+	return
+}
+
+// Remaining returns how much of the budget of g is left, and false if g isn't limited.
+func (t *Tracker) Remaining(g Group) (time.Duration, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	budget, ok := t.budgets[g]
+	if !ok {
+		return 0, false
+	}
+	if s, ok := t.groups[g]; ok {
+		return budget - s.Spent, true
+	}
+	return budget, true
+}
+
+// StartSpec returns how long the current spec, of suite, may run: timeout or what's left of its group's budget or
+// the total budget, whichever is shortest. The spec is skipped if its group or all groups have spent their budget.
+func (t *Tracker) StartSpec(suite string, timeout time.Duration) time.Duration {
+	t.mu.Lock()
+	total := t.total
+	t.mu.Unlock()
+	if total > 0 {
+		remaining := total - t.Spent()
+		if remaining <= 0 {
+			skips.Skip(skips.OverBudget, fmt.Sprintf("suites spent their total time budget of %v", total), 1)
+		} else if remaining < timeout {
+			timeout = remaining
+		}
+	}
+
+	g := Of(suite)
+	remaining, ok := t.Remaining(g)
+	if !ok {
+		return timeout
+	} else if remaining <= 0 {
+		skips.Skip(skips.OverBudget, fmt.Sprintf("the %s group spent its time budget", g), 1)
+	}
+
+	if remaining < timeout {
+		return remaining
+	}
+	return timeout
+}
+
+// Summaries returns how each group which had specs did, in the order of Groups.
+func (t *Tracker) Summaries() (summaries []Summary) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, g := range Groups {
+		if s, ok := t.groups[g]; ok {
+			summaries = append(summaries, *s)
+		}
+	}
+	return
+}
+
+// Metadata records how long each group ran and how many of its specs were skipped over budget for TestGrid.
+func (t *Tracker) Metadata() map[string]interface{} {
+	meta := map[string]interface{}{}
+	var exceeded []string
+	for _, s := range t.Summaries() {
+		meta["group-"+string(s.Group)+"-seconds"] = int(s.Spent.Seconds())
+		meta["group-"+string(s.Group)+"-failed"] = s.Failed
+		if s.Budget > 0 {
+			meta["group-"+string(s.Group)+"-over-budget"] = s.OverBudget
+		}
+		if s.Exceeded() {
+			exceeded = append(exceeded, string(s.Group))
+		}
+	}
+	if len(exceeded) != 0 {
+		meta["groups-over-budget"] = strings.Join(exceeded, ",")
+	}
+	return meta
+}
+
+// AnnotateJUnit sets the group of each testcase in the JUnit file as a property.
+func (t *Tracker) AnnotateJUnit(file string) error {
+	t.mu.Lock()
+	props := make(map[string]map[string]string, len(t.tests))
+	for name, g := range t.tests {
+		props[name] = map[string]string{PropertyGroup: string(g)}
+	}
+	t.mu.Unlock()
+
+	if len(props) == 0 {
+		return nil
+	}
+	return junitprops.AnnotateTests(file, props)
+}
+
+// SpecSuiteWillBegin does nothing.
+func (t *Tracker) SpecSuiteWillBegin(config ginkgoconfig.GinkgoConfigType, summary *types.SuiteSummary) {
+}
+
+// BeforeSuiteDidRun does nothing.
+func (t *Tracker) BeforeSuiteDidRun(summary *types.SetupSummary) {}
+
+// SpecWillRun does nothing.
+func (t *Tracker) SpecWillRun(summary *types.SpecSummary) {}
+
+// SpecDidComplete adds how long the spec ran to its group and counts its outcome.
+func (t *Tracker) SpecDidComplete(summary *types.SpecSummary) {
+	// the first component is the top level container
+	texts := summary.ComponentTexts
+	if len(texts) > 1 {
+		texts = texts[1:]
+	}
+	g := Of(texts[0])
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.groups == nil {
+		t.groups, t.tests = map[Group]*Summary{}, map[string]Group{}
+	}
+	s, ok := t.groups[g]
+	if !ok {
+		s = &Summary{Group: g, Budget: t.budgets[g]}
+		t.groups[g] = s
+	}
+	t.tests[strings.Join(texts, " ")] = g
+
+	s.Spent += summary.RunTime
+	switch {
+	case summary.Skipped() || summary.Pending():
+		if reason, _ := skips.Parse(summary.Failure.Message); reason == skips.OverBudget {
+			s.OverBudget++
+		} else {
+			s.Skipped++
+		}
+	case summary.HasFailureState():
+		s.Failed++
+	default:
+		s.Passed++
+	}
+}
+
+// AfterSuiteDidRun does nothing.
+func (t *Tracker) AfterSuiteDidRun(summary *types.SetupSummary) {}
+
+// SpecSuiteDidEnd does nothing.
+func (t *Tracker) SpecSuiteDidEnd(summary *types.SuiteSummary) {}
+
+func known(g Group) bool {
+	for _, known := range Groups {
+		if g == known {
+			return true
+		}
+	}
+	return false
+}
+
+func groupList() string {
+	names := make([]string, len(Groups))
+	for i, g := range Groups {
+		names[i] = string(g)
+	}
+	return strings.Join(names, ", ")
+}
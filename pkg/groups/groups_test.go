@@ -0,0 +1,109 @@
+package groups
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/onsi/ginkgo/types"
+
+	"github.com/openshift/osde2e/pkg/skips"
+)
+
+func TestRegister(t *testing.T) {
+	if err := register(Storage, "Volumes"); err != nil {
+		t.Fatalf("failed to register suite: %v", err)
+	}
+	if err := register(Storage, "Volumes"); err != nil {
+		t.Errorf("registering a suite in the same group again should be allowed: %v", err)
+	}
+	if err := register(Networking, "Volumes"); err == nil {
+		t.Error("expected registering a suite in another group to fail")
+	}
+
+	if g := Of("Volumes"); g != Storage {
+		t.Errorf("expected suite to be in %s, got %s", Storage, g)
+	}
+	if g := Of("Unknown"); g != Other {
+		t.Errorf("expected unregistered suite to be in %s, got %s", Other, g)
+	}
+}
+
+func TestParseBudgets(t *testing.T) {
+	budgets, err := ParseBudgets(map[string]string{"storage": "45m", "other": "1h"})
+	if err != nil {
+		t.Fatalf("failed to parse budgets: %v", err)
+	}
+	expected := map[Group]time.Duration{Storage: 45 * time.Minute, Other: time.Hour}
+	if !reflect.DeepEqual(budgets, expected) {
+		t.Errorf("expected budgets %v, got %v", expected, budgets)
+	}
+
+	for _, invalid := range []map[string]string{{"sig-storage": "45m"}, {"storage": "soon"}, {"storage": "-1m"}} {
+		if _, err = ParseBudgets(invalid); err == nil {
+			t.Errorf("budgets should be invalid: %v", invalid)
+		}
+	}
+}
+
+func TestTracker(t *testing.T) {
+	register(Networking, "Routes")
+	register(Security, "Pod Security")
+
+	tracker := new(Tracker)
+	tracker.SetBudgets(map[Group]time.Duration{Networking: 10 * time.Minute})
+	if timeout := tracker.StartSpec("Routes", time.Hour); timeout != 10*time.Minute {
+		t.Errorf("expected spec to be limited to the group's budget, got %v", timeout)
+	}
+	if timeout := tracker.StartSpec("Pod Security", time.Hour); timeout != time.Hour {
+		t.Errorf("expected spec of a group without a budget to be unlimited, got %v", timeout)
+	}
+
+	for _, spec := range []types.SpecSummary{
+		{ComponentTexts: []string{"[Top Level]", "Routes", "should be admitted"}, State: types.SpecStatePassed, RunTime: 4 * time.Minute},
+		{ComponentTexts: []string{"[Top Level]", "Routes", "should serve"}, State: types.SpecStateTimedOut, RunTime: 6 * time.Minute},
+		{ComponentTexts: []string{"[Top Level]", "Routes", "should reencrypt"}, State: types.SpecStateSkipped,
+			Failure: types.SpecFailure{Message: skips.Message(skips.OverBudget, "the networking group spent its time budget")}},
+		{ComponentTexts: []string{"[Top Level]", "Pod Security", "should not be privileged"}, State: types.SpecStateFailed, RunTime: time.Minute},
+		{ComponentTexts: []string{"[Top Level]", "Pod Security", "should drop capabilities"}, State: types.SpecStateSkipped},
+	} {
+		spec := spec
+		tracker.SpecDidComplete(&spec)
+	}
+
+	if remaining, ok := tracker.Remaining(Networking); !ok || remaining != 0 {
+		t.Errorf("expected the networking budget to be spent, got %v", remaining)
+	}
+
+	expected := []Summary{
+		{Group: Networking, Budget: 10 * time.Minute, Spent: 10 * time.Minute, Passed: 1, Failed: 1, OverBudget: 1},
+		{Group: Security, Spent: time.Minute, Failed: 1, Skipped: 1},
+	}
+	summaries := tracker.Summaries()
+	if !reflect.DeepEqual(summaries, expected) {
+		t.Fatalf("expected summaries %+v, got %+v", expected, summaries)
+	}
+	if !summaries[0].Exceeded() || summaries[1].Exceeded() {
+		t.Error("expected only the networking group to exceed its budget")
+	}
+	if s := summaries[0].String(); s != "networking: 1 passed, 1 failed, 0 skipped in 10m0s of 10m0s budget, 1 skipped over budget" {
+		t.Errorf("unexpected summary '%s'", s)
+	}
+
+	meta := tracker.Metadata()
+	if meta["group-networking-seconds"] != 600 || meta["group-networking-over-budget"] != 1 ||
+		meta["groups-over-budget"] != "networking" || meta["group-security-failed"] != 1 {
+		t.Errorf("unexpected metadata %v", meta)
+	}
+	if _, ok := meta["group-security-over-budget"]; ok {
+		t.Errorf("groups without budgets shouldn't have over-budget metadata: %v", meta)
+	}
+
+	tracker.SetTotalBudget(20 * time.Minute)
+	if spent := tracker.Spent(); spent != 11*time.Minute {
+		t.Errorf("expected 11m spent by all groups, got %v", spent)
+	}
+	if timeout := tracker.StartSpec("Pod Security", time.Hour); timeout != 9*time.Minute {
+		t.Errorf("expected spec to be limited to what's left of the total budget, got %v", timeout)
+	}
+}
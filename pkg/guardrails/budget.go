@@ -0,0 +1,103 @@
+package guardrails
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/test-infra/testgrid/metadata/junit"
+
+	"github.com/openshift/osde2e/pkg/config"
+	"github.com/openshift/osde2e/pkg/configurator"
+	"github.com/openshift/osde2e/pkg/junitprops"
+)
+
+// BudgetSuiteName is the JUnit suite containing a testcase for each limit of the budget of a configuration profile.
+const BudgetSuiteName = "Profile budget"
+
+// Usage is what a run spent of the budget of its profile.
+type Usage struct {
+	ClusterLifetime time.Duration
+	ComputeNodes    int
+	SuiteTime       time.Duration
+}
+
+// LoadBudget returns the budget of the configuration profile of cfg and the profile's name. The budget is nil if there's
+// no profile or it doesn't have a budget.
+func LoadBudget(cfg *config.Config) (*configurator.Budget, string, error) {
+	if cfg.ConfigProfile == "" {
+		return nil, "", nil
+	}
+	profile, err := configurator.LoadProfile(cfg.ConfigProfile)
+	if err != nil {
+		return nil, "", err
+	}
+	return profile.Budget, profile.Name, nil
+}
+
+// budgetLimit is a limit of a budget and whether usage exceeds it.
+type budgetLimit struct {
+	name      string
+	violation string
+}
+
+// budgetLimits returns the limits set in budget of profile, with a violation for each exceeded by u.
+func budgetLimits(profile string, budget *configurator.Budget, u Usage) (limits []budgetLimit) {
+	if max := budget.ClusterLifetime(); max > 0 {
+		l := budgetLimit{name: "cluster lifetime"}
+		if u.ClusterLifetime > max {
+			l.violation = fmt.Sprintf("clusters existing for %v is longer than the %v budget of profile '%s'",
+				u.ClusterLifetime, max, profile)
+		}
+		limits = append(limits, l)
+	}
+	if max := budget.MaxComputeNodes; max > 0 {
+		l := budgetLimit{name: "compute nodes"}
+		if u.ComputeNodes > max {
+			l.violation = fmt.Sprintf("%d compute nodes is more than the budget of %d of profile '%s'",
+				u.ComputeNodes, max, profile)
+		}
+		limits = append(limits, l)
+	}
+	if max := budget.SuiteTime(); max > 0 {
+		l := budgetLimit{name: "suite time"}
+		if u.SuiteTime >= max {
+			l.violation = fmt.Sprintf("suites running for %v spent the %v budget of profile '%s'",
+				u.SuiteTime.Round(time.Second), max, profile)
+		}
+		limits = append(limits, l)
+	}
+	return
+}
+
+// BudgetViolations returns a description of each limit of the budget of profile exceeded by u.
+func BudgetViolations(profile string, budget *configurator.Budget, u Usage) (violations []string) {
+	for _, l := range budgetLimits(profile, budget, u) {
+		if l.violation != "" {
+			violations = append(violations, l.violation)
+		}
+	}
+	return
+}
+
+// WriteBudgetJUnit records a testcase in dir for each limit of the budget of profile, failing if u exceeds it.
+func WriteBudgetJUnit(dir, suffix, profile string, budget *configurator.Budget, u Usage) error {
+	limits := budgetLimits(profile, budget, u)
+	suite := junit.Suite{
+		Name:  BudgetSuiteName,
+		Tests: len(limits),
+	}
+	for _, l := range limits {
+		result := junit.Result{
+			Name:      fmt.Sprintf("[budget] %s should be within the budget of profile '%s'", l.name, profile),
+			ClassName: BudgetSuiteName,
+		}
+		if l.violation != "" {
+			msg := l.violation
+			result.Failure = &msg
+			suite.Failures++
+		}
+		suite.Results = append(suite.Results, result)
+	}
+
+	return junitprops.WriteSuite(dir, "budget", suffix, suite)
+}
@@ -0,0 +1,134 @@
+// Package guardrails refuses to create clusters larger or longer lived than configured limits, so a mistyped option
+// can't provision an expensive cluster.
+package guardrails
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/openshift/osde2e/pkg/config"
+	"github.com/openshift/osde2e/pkg/machinepool"
+	"github.com/openshift/osde2e/pkg/osd"
+)
+
+// Plan is the size and lifetime of the cluster a run creates.
+type Plan struct {
+	// ComputeNodes are the compute nodes of the cluster and its machine pools. It's 0 for the flavour's default.
+	ComputeNodes int
+
+	// MachineTypes are the instance types of compute nodes and machine pools. They're empty for the flavour's default.
+	MachineTypes []string
+
+	// Expiry is how long the cluster exists before it expires.
+	Expiry time.Duration
+}
+
+// Planned returns the cluster described by cfg. Nodes and machine types of machine pools are included when their
+// preset can be loaded, and the nodes, machine type, and expiry of clusters created from a template are taken from it
+// when it can be rendered.
+func Planned(cfg *config.Config) Plan {
+	p := Plan{ComputeNodes: cfg.ComputeNodes, MachineTypes: []string{cfg.ComputeMachineType}, Expiry: cfg.ClusterExpiry}
+	if cfg.ClusterTemplate != "" {
+		if t, err := fromTemplate(cfg); err == nil {
+			p.ComputeNodes, p.MachineTypes[0] = t.Nodes.Compute, t.Nodes.MachineType.ID
+			if t.Expiration != nil {
+				p.Expiry = time.Until(*t.Expiration).Round(time.Minute)
+			}
+		}
+	}
+	if cfg.MachinePools != "" {
+		if preset, err := machinepool.Load(cfg.MachinePools); err == nil {
+			for _, pool := range preset.Pools {
+				p.ComputeNodes += pool.Replicas
+				p.MachineTypes = append(p.MachineTypes, pool.InstanceType)
+			}
+		}
+	}
+	return p
+}
+
+// Check returns a description of each way the cluster described by cfg exceeds its limits, including the budget of
+// its configuration profile.
+func Check(cfg *config.Config) (violations []string) {
+	plan := Planned(cfg)
+	nodes, machineTypes, expiry := plan.ComputeNodes, plan.MachineTypes, plan.Expiry
+
+	if cfg.MaxComputeNodes > 0 && nodes > cfg.MaxComputeNodes {
+		violations = append(violations, fmt.Sprintf("%d compute nodes is more than the limit of %d",
+			nodes, cfg.MaxComputeNodes))
+	}
+
+	if cfg.MaxClusterExpiry > 0 && expiry > cfg.MaxClusterExpiry {
+		violations = append(violations, fmt.Sprintf("expiring after %v is longer than the limit of %v",
+			expiry, cfg.MaxClusterExpiry))
+	}
+
+	if expiry, err := cfg.KeptExpiry(); err == nil && cfg.MaxClusterExpiry > 0 && expiry > cfg.MaxClusterExpiry {
+		violations = append(violations, fmt.Sprintf("keeping clusters for %v with teardown policy %s is longer than the limit of %v",
+			expiry, cfg.Teardown(), cfg.MaxClusterExpiry))
+	}
+
+	for _, machineType := range machineTypes {
+		if machineType != "" && len(cfg.AllowedMachineTypes) != 0 && !contains(cfg.AllowedMachineTypes, machineType) {
+			violations = append(violations, fmt.Sprintf("machine type '%s' isn't one of the allowed types: %s",
+				machineType, strings.Join(cfg.AllowedMachineTypes, ", ")))
+		}
+	}
+
+	if budget, profile, err := LoadBudget(cfg); err == nil && budget != nil {
+		lifetime := expiry
+		if kept, err := cfg.KeptExpiry(); err == nil && kept > lifetime {
+			lifetime = kept
+		}
+		violations = append(violations, BudgetViolations(profile, budget, Usage{ComputeNodes: nodes, ClusterLifetime: lifetime})...)
+	}
+	return
+}
+
+// Enforce returns an error if the cluster described by cfg exceeds its limits, unless OverrideGuardrails is set.
+func Enforce(cfg *config.Config) error {
+	violations := Check(cfg)
+	if len(violations) == 0 {
+		return nil
+	}
+
+	if cfg.OverrideGuardrails {
+		for _, v := range violations {
+			log.Printf("OVERRIDE_GUARDRAILS is set, creating cluster with %s.", v)
+		}
+		return nil
+	}
+	return fmt.Errorf("cluster exceeds limits, set OVERRIDE_GUARDRAILS to create it anyway: %s", strings.Join(violations, "; "))
+}
+
+// templateCluster is the part of a cluster template which is limited.
+type templateCluster struct {
+	Nodes struct {
+		Compute     int `json:"compute"`
+		MachineType struct {
+			ID string `json:"id"`
+		} `json:"compute_machine_type"`
+	} `json:"nodes"`
+	Expiration *time.Time `json:"expiration_timestamp"`
+}
+
+// fromTemplate renders the cluster template of cfg.
+func fromTemplate(cfg *config.Config) (t templateCluster, err error) {
+	data, err := osd.RenderClusterTemplate(cfg.ClusterTemplate, cfg, time.Now().Add(cfg.ClusterExpiry))
+	if err != nil {
+		return t, err
+	}
+	return t, json.Unmarshal(data, &t)
+}
+
+func contains(strs []string, s string) bool {
+	for _, str := range strs {
+		if str == s {
+			return true
+		}
+	}
+	return false
+}
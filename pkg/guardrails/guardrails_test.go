@@ -0,0 +1,113 @@
+package guardrails
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"k8s.io/test-infra/testgrid/metadata/junit"
+
+	"github.com/openshift/osde2e/pkg/config"
+	"github.com/openshift/osde2e/pkg/configurator"
+)
+
+func TestEnforce(t *testing.T) {
+	limits := func() *config.Config {
+		return &config.Config{
+			ComputeNodes:        4,
+			ComputeMachineType:  "m5.xlarge",
+			ClusterExpiry:       8 * time.Hour,
+			MaxComputeNodes:     9,
+			MaxClusterExpiry:    24 * time.Hour,
+			AllowedMachineTypes: []string{"m5.xlarge", "m5.2xlarge"},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		change     func(*config.Config)
+		violations int
+	}{
+		{"within limits", func(cfg *config.Config) {}, 0},
+		{"flavour defaults", func(cfg *config.Config) { cfg.ComputeNodes, cfg.ComputeMachineType = 0, "" }, 0},
+		{"too many nodes", func(cfg *config.Config) { cfg.ComputeNodes = 50 }, 1},
+		{"expires too late", func(cfg *config.Config) { cfg.ClusterExpiry = 72 * time.Hour }, 1},
+		{"kept too long", func(cfg *config.Config) {
+			cfg.TeardownPolicy, cfg.TeardownExpiry = "keep-on-failure", map[string]string{"keep-on-failure": "48h"}
+		}, 1},
+		{"machine type not allowed", func(cfg *config.Config) { cfg.ComputeMachineType = "p3.16xlarge" }, 1},
+		{"machine pools too large", func(cfg *config.Config) {
+			cfg.MachinePools, cfg.ComputeNodes = "../../machinepools/dedicated.yaml", 6
+			cfg.AllowedMachineTypes = append(cfg.AllowedMachineTypes, "r5.xlarge")
+		}, 1},
+		{"machine pool type not allowed", func(cfg *config.Config) {
+			cfg.MachinePools, cfg.ComputeNodes = "../../machinepools/infra.yaml", 3
+		}, 1},
+		{"template within limits", func(cfg *config.Config) {
+			cfg.ClusterTemplate, cfg.ComputeNodes = "../../clustertemplates/private.yaml", 50
+		}, 0},
+		{"template too large", func(cfg *config.Config) {
+			cfg.ClusterTemplate, cfg.MaxComputeNodes = "../../clustertemplates/private.yaml", 2
+		}, 1},
+		{"template expires too late", func(cfg *config.Config) {
+			cfg.ClusterTemplate, cfg.ClusterExpiry = "../../clustertemplates/private.yaml", 72*time.Hour
+		}, 1},
+		{"within profile budget", func(cfg *config.Config) { cfg.ConfigProfile = "../../profiles/customer.yaml" }, 0},
+		{"profile budget exceeded", func(cfg *config.Config) {
+			cfg.ConfigProfile, cfg.ClusterExpiry = "../../profiles/customer.yaml", 12*time.Hour
+		}, 1},
+		{"no limits", func(cfg *config.Config) {
+			cfg.ComputeNodes, cfg.MaxComputeNodes, cfg.MaxClusterExpiry, cfg.AllowedMachineTypes = 50, 0, 0, nil
+		}, 0},
+	}
+
+	for _, test := range tests {
+		cfg := limits()
+		test.change(cfg)
+
+		if violations := Check(cfg); len(violations) != test.violations {
+			t.Errorf("%s: expected %d violations, got %v", test.name, test.violations, violations)
+		}
+		if err := Enforce(cfg); (err != nil) != (test.violations != 0) {
+			t.Errorf("%s: unexpected result enforcing: %v", test.name, err)
+		}
+
+		cfg.OverrideGuardrails = true
+		if err := Enforce(cfg); err != nil {
+			t.Errorf("%s: expected override to allow cluster: %v", test.name, err)
+		}
+	}
+}
+
+func TestWriteBudgetJUnit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "budget")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	budget := &configurator.Budget{MaxComputeNodes: 9, MaxSuiteTime: "2h"}
+	usage := Usage{ClusterLifetime: 72 * time.Hour, ComputeNodes: 4, SuiteTime: 150 * time.Minute}
+	if violations := BudgetViolations("customer", budget, usage); len(violations) != 1 ||
+		violations[0] != "suites running for 2h30m0s spent the 2h0m0s budget of profile 'customer'" {
+		t.Errorf("expected only suite time to exceed the budget, got %v", violations)
+	}
+
+	if err = WriteBudgetJUnit(dir, "abc", "customer", budget, usage); err != nil {
+		t.Fatalf("failed writing budget results: %v", err)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dir, "junit_budget_abc.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var suite junit.Suite
+	if err = xml.Unmarshal(data, &suite); err != nil {
+		t.Fatal(err)
+	}
+	if suite.Tests != 2 || suite.Failures != 1 || suite.Results[0].Failure != nil || suite.Results[1].Failure == nil {
+		t.Errorf("expected a testcase for each limit set with suite time failing, got %+v", suite)
+	}
+}
@@ -0,0 +1,132 @@
+// Package harness is used by test harness images to behave consistently when run by osde2e.
+//
+// A harness is a container image run by osde2e's runner. osde2e provides it with cluster credentials and the
+// environment variables below, which don't use config.EnvPrefix so harnesses built with osde2e's config aren't
+// refused by STRICT_ENV. Results written to the output directory, including JUnit files, are collected
+// by osde2e after the harness exits.
+package harness
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// OutputDirEnv is the directory results are written to. Its contents are collected by osde2e.
+	OutputDirEnv = "HARNESS_OUTPUT_DIR"
+
+	// TimeoutEnv is how long the harness may run, as a Go duration.
+	TimeoutEnv = "HARNESS_TIMEOUT"
+
+	// NameEnv identifies the harness in results.
+	NameEnv = "HARNESS_NAME"
+
+	// AddonsEnv lists the add-ons installed with the harness's add-on as a bundle, as comma separated id=namespace
+	// pairs. It's only set when harnesses are run for an add-on bundle.
+	AddonsEnv = "HARNESS_ADDONS"
+
+	// KubeconfigEnv is the path of a kubeconfig used to access the cluster under test.
+	KubeconfigEnv = "KUBECONFIG"
+
+	// KubeconfigSecretPath is where a kubeconfig is mounted from a secret when one is provided.
+	KubeconfigSecretPath = "/var/run/secrets/osde2e/kubeconfig"
+
+	// DefaultOutputDir is used when OutputDirEnv isn't set.
+	DefaultOutputDir = "./results"
+
+	// DefaultName is used when NameEnv isn't set.
+	DefaultName = "harness"
+)
+
+// Harness holds the settings provided to a harness by osde2e.
+type Harness struct {
+	// Name identifies the harness in results.
+	Name string
+
+	// OutputDir is the directory results are written to.
+	OutputDir string
+
+	// Timeout is how long the harness may run. It is 0 if there is no limit.
+	Timeout time.Duration
+
+	// Start is when the harness was created.
+	Start time.Time
+
+	// Addons are the namespaces of the add-ons installed with the harness's add-on as a bundle by ID. Harnesses use
+	// it to test how their add-on integrates with the others. It's empty when the add-on isn't part of a bundle.
+	Addons map[string]string
+
+	results results
+}
+
+// New returns a Harness configured from the environment and creates its output directory.
+func New() (*Harness, error) {
+	h := &Harness{
+		Name:      DefaultName,
+		OutputDir: DefaultOutputDir,
+		Start:     time.Now(),
+	}
+
+	if name := os.Getenv(NameEnv); name != "" {
+		h.Name = name
+	}
+
+	if dir := os.Getenv(OutputDirEnv); dir != "" {
+		h.OutputDir = dir
+	}
+
+	if timeout := os.Getenv(TimeoutEnv); timeout != "" {
+		var err error
+		if h.Timeout, err = time.ParseDuration(timeout); err != nil {
+			return nil, fmt.Errorf("invalid %s '%s': %v", TimeoutEnv, timeout, err)
+		}
+	}
+
+	if addons := os.Getenv(AddonsEnv); addons != "" {
+		h.Addons = map[string]string{}
+		for _, pair := range strings.Split(addons, ",") {
+			kv := strings.SplitN(pair, "=", 2)
+			if len(kv) != 2 || kv[0] == "" {
+				return nil, fmt.Errorf("invalid %s '%s': add-ons must be id=namespace", AddonsEnv, addons)
+			}
+			h.Addons[kv[0]] = kv[1]
+		}
+	}
+
+	if err := os.MkdirAll(h.OutputDir, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("couldn't create output dir '%s': %v", h.OutputDir, err)
+	}
+	return h, nil
+}
+
+// Deadline returns when the harness must finish. ok is false when there is no timeout.
+func (h *Harness) Deadline() (deadline time.Time, ok bool) {
+	if h.Timeout == 0 {
+		return time.Time{}, false
+	}
+	return h.Start.Add(h.Timeout), true
+}
+
+// Context returns a context cancelled when the harness times out.
+func (h *Harness) Context() (context.Context, context.CancelFunc) {
+	if deadline, ok := h.Deadline(); ok {
+		return context.WithDeadline(context.Background(), deadline)
+	}
+	return context.WithCancel(context.Background())
+}
+
+// Remaining returns how long the harness may continue running, or 0 if there is no timeout.
+func (h *Harness) Remaining() time.Duration {
+	deadline, ok := h.Deadline()
+	if !ok {
+		return 0
+	}
+
+	if remaining := time.Until(deadline); remaining > 0 {
+		return remaining
+	}
+	return time.Nanosecond
+}
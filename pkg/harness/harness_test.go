@@ -0,0 +1,116 @@
+package harness
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNew(t *testing.T) {
+	dir, err := ioutil.TempDir("", "harness")
+	if err != nil {
+		t.Fatalf("Failed creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	outputDir := filepath.Join(dir, "results")
+	os.Setenv(NameEnv, "addon")
+	os.Setenv(OutputDirEnv, outputDir)
+	os.Setenv(TimeoutEnv, "1h")
+	os.Setenv(AddonsEnv, "managed-api-service=redhat-rhoam-operator,managed-odh=redhat-ods-operator")
+	defer func() {
+		os.Unsetenv(NameEnv)
+		os.Unsetenv(OutputDirEnv)
+		os.Unsetenv(TimeoutEnv)
+		os.Unsetenv(AddonsEnv)
+	}()
+
+	h, err := New()
+	if err != nil {
+		t.Fatalf("Failed creating harness: %v", err)
+	}
+
+	if h.Name != "addon" || h.OutputDir != outputDir || h.Timeout != time.Hour {
+		t.Errorf("harness wasn't configured from environment: %+v", h)
+	}
+	if len(h.Addons) != 2 || h.Addons["managed-odh"] != "redhat-ods-operator" {
+		t.Errorf("expected the namespaces of 2 bundled add-ons, got %v", h.Addons)
+	}
+
+	if _, err = os.Stat(outputDir); err != nil {
+		t.Errorf("output dir should be created: %v", err)
+	}
+
+	ctx, cancel := h.Context()
+	defer cancel()
+	if _, ok := ctx.Deadline(); !ok {
+		t.Error("context should have a deadline when a timeout is set")
+	}
+
+	os.Setenv(AddonsEnv, "managed-odh")
+	if _, err = New(); err == nil {
+		t.Error("invalid add-ons should return an error")
+	}
+
+	os.Setenv(AddonsEnv, "")
+	os.Setenv(TimeoutEnv, "forever")
+	if _, err = New(); err == nil {
+		t.Error("an invalid timeout should return an error")
+	}
+}
+
+func TestWriteJUnit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "harness")
+	if err != nil {
+		t.Fatalf("Failed creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	h := &Harness{
+		Name:      "addon",
+		OutputDir: dir,
+	}
+
+	h.Run("passes", func() error { return nil })
+	if err = h.Run("fails", func() error { return errors.New("broken") }); err == nil {
+		t.Error("failures should be returned")
+	}
+
+	if !h.Failed() {
+		t.Error("harness should have failed")
+	}
+
+	if err = h.WriteJUnit(); err != nil {
+		t.Fatalf("Failed writing JUnit: %v", err)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "junit_addon.xml"))
+	if err != nil {
+		t.Fatalf("Failed reading JUnit: %v", err)
+	}
+
+	for _, expected := range []string{`tests="2"`, `failures="1"`, "broken"} {
+		if !strings.Contains(string(data), expected) {
+			t.Errorf("JUnit should contain '%s', got:\n%s", expected, data)
+		}
+	}
+}
+
+func TestKubeconfigPath(t *testing.T) {
+	f, err := ioutil.TempFile("", "kubeconfig")
+	if err != nil {
+		t.Fatalf("Failed creating kubeconfig: %v", err)
+	}
+	defer os.Remove(f.Name())
+
+	os.Setenv(KubeconfigEnv, f.Name())
+	defer os.Unsetenv(KubeconfigEnv)
+
+	if path, ok := kubeconfigPath(); !ok || path != f.Name() {
+		t.Errorf("expected kubeconfig '%s', got '%s'", f.Name(), path)
+	}
+}
@@ -0,0 +1,48 @@
+package harness
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// RESTConfig returns a config for the cluster under test. The kubeconfig in KubeconfigEnv is used first,
+// followed by one mounted at KubeconfigSecretPath, and finally the Pod's service account.
+func RESTConfig() (*rest.Config, error) {
+	if path, ok := kubeconfigPath(); ok {
+		cfg, err := clientcmd.BuildConfigFromFlags("", path)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't load kubeconfig '%s': %v", path, err)
+		}
+		return cfg, nil
+	}
+
+	cfg, err := rest.InClusterConfig()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't find a kubeconfig or service account: %v", err)
+	}
+	return cfg, nil
+}
+
+// kubeconfigPath returns the first kubeconfig that exists.
+func kubeconfigPath() (string, bool) {
+	for _, path := range []string{os.Getenv(KubeconfigEnv), KubeconfigSecretPath} {
+		if path == "" {
+			continue
+		}
+
+		// expand home directory as the runner sets KUBECONFIG relative to it
+		if strings.HasPrefix(path, "~/") {
+			path = filepath.Join(os.Getenv("HOME"), strings.TrimPrefix(path, "~/"))
+		}
+
+		if _, err := os.Stat(path); err == nil {
+			return path, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,77 @@
+package harness
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"k8s.io/test-infra/testgrid/metadata/junit"
+)
+
+// results are test cases recorded by a Harness.
+type results struct {
+	mu    sync.Mutex
+	suite junit.Suite
+}
+
+// WriteResult writes data to name in the output directory.
+func (h *Harness) WriteResult(name string, data []byte) error {
+	filename := filepath.Join(h.OutputDir, name)
+	if err := ioutil.WriteFile(filename, data, os.ModePerm); err != nil {
+		return fmt.Errorf("couldn't write result '%s': %v", filename, err)
+	}
+	return nil
+}
+
+// Run records the outcome of test as a JUnit test case named name. Failures are returned.
+func (h *Harness) Run(name string, test func() error) error {
+	start := time.Now()
+	err := test()
+
+	result := junit.Result{
+		Name:      name,
+		ClassName: h.Name,
+		Time:      time.Since(start).Seconds(),
+	}
+	if err != nil {
+		msg := err.Error()
+		result.Failure = &msg
+	}
+
+	h.results.mu.Lock()
+	defer h.results.mu.Unlock()
+	suite := &h.results.suite
+	suite.Tests++
+	suite.Time += result.Time
+	if err != nil {
+		suite.Failures++
+	}
+	suite.Results = append(suite.Results, result)
+	return err
+}
+
+// Failed returns true if any recorded test case failed.
+func (h *Harness) Failed() bool {
+	h.results.mu.Lock()
+	defer h.results.mu.Unlock()
+	return h.results.suite.Failures != 0
+}
+
+// WriteJUnit writes recorded test cases to a JUnit file in the output directory, where osde2e includes them in
+// its results.
+func (h *Harness) WriteJUnit() error {
+	h.results.mu.Lock()
+	suite := h.results.suite
+	h.results.mu.Unlock()
+
+	suite.Name = h.Name
+	data, err := xml.MarshalIndent(suite, "", "  ")
+	if err != nil {
+		return fmt.Errorf("couldn't encode JUnit results: %v", err)
+	}
+	return h.WriteResult(fmt.Sprintf("junit_%s.xml", h.Name), data)
+}
@@ -0,0 +1,60 @@
+package health
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// CustomCheck is a health check contributed by a suite or add-on harness. It returns why the cluster checked by c isn't
+// healthy, using the clients of c.
+type CustomCheck func(c *Checker) []string
+
+var (
+	customMu     sync.RWMutex
+	customChecks = map[string]CustomCheck{}
+)
+
+// Register adds check to every health check of clusters as name, including those after install and between upgrades.
+// Suites and add-on harnesses register checks in init functions, so it panics if name is empty or already used.
+func Register(name string, check CustomCheck) {
+	customMu.Lock()
+	defer customMu.Unlock()
+	if name == "" || check == nil {
+		panic("health: custom checks need a name and a check")
+	}
+	for _, builtin := range []string{CheckNodes, CheckClusterVersion, CheckOperators, CheckControlPlane} {
+		if name == builtin {
+			panic(fmt.Sprintf("health: '%s' is a built-in check", name))
+		}
+	}
+	if _, dup := customChecks[name]; dup {
+		panic(fmt.Sprintf("health: custom check '%s' is already registered", name))
+	}
+	customChecks[name] = check
+}
+
+// Registered returns the names of the custom checks registered, in order.
+func Registered() (names []string) {
+	customMu.RLock()
+	defer customMu.RUnlock()
+	for name := range customChecks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return
+}
+
+// custom runs the custom check name. Checks which panic are unhealthy rather than stopping the run.
+func (c *Checker) custom(name string) (problems []string) {
+	customMu.RLock()
+	check := customChecks[name]
+	customMu.RUnlock()
+
+	defer func() {
+		if r := recover(); r != nil {
+			problems = []string{fmt.Sprintf("check panicked: %v", r)}
+		}
+	}()
+	return check(c)
+}
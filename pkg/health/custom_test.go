@@ -0,0 +1,102 @@
+package health
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	kubev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/test-infra/testgrid/metadata/junit"
+)
+
+// unregister removes the custom checks names so tests don't leak them.
+func unregister(names ...string) {
+	customMu.Lock()
+	defer customMu.Unlock()
+	for _, name := range names {
+		delete(customChecks, name)
+	}
+}
+
+func TestCustomChecks(t *testing.T) {
+	defer unregister("addon-ready", "broken")
+	Register("addon-ready", func(c *Checker) []string {
+		if _, err := c.Kube.CoreV1().Namespaces().Get("addon", metav1.GetOptions{}); err != nil {
+			return []string{"addon namespace is missing"}
+		}
+		return nil
+	})
+	Register("broken", func(c *Checker) []string {
+		var problems []string
+		return problems[:1]
+	})
+	if names := Registered(); !reflect.DeepEqual(names, []string{"addon-ready", "broken"}) {
+		t.Errorf("unexpected registered checks %v", names)
+	}
+
+	node := &kubev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker"},
+		Status: kubev1.NodeStatus{
+			Conditions: []kubev1.NodeCondition{{Type: kubev1.NodeReady, Status: kubev1.ConditionTrue}},
+		},
+	}
+	c := &Checker{Kube: kubefake.NewSimpleClientset(node)}
+	r := c.Check()
+	if r.Healthy || len(r.Results) != 3 || r.Results[0].Custom || !r.Results[1].Custom || !r.Results[2].Custom {
+		t.Fatalf("expected custom checks after built-in ones, got %+v", r)
+	}
+	if r.Results[1].Problems[0] != "addon namespace is missing" || r.Results[2].Healthy {
+		t.Errorf("expected custom checks to fail, got %+v", r.Results)
+	}
+
+	for _, name := range []string{"", CheckNodes, "addon-ready"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected registering '%s' to panic", name)
+				}
+			}()
+			Register(name, func(*Checker) []string { return nil })
+		}()
+	}
+}
+
+func TestWriteJUnit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "health")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	r := &Report{Healthy: true}
+	r.Add(CheckNodes, nil)
+	r.AddCustom("addon-ready", []string{"addon namespace is missing"})
+	if err = WriteJUnit(dir, "abc", "upgrade-1", r); err != nil {
+		t.Fatalf("failed writing results: %v", err)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "junit_health_upgrade-1_abc.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var suite junit.Suite
+	if err = xml.Unmarshal(data, &suite); err != nil {
+		t.Fatal(err)
+	}
+	if suite.Tests != 2 || suite.Failures != 1 || len(suite.Results) != 2 {
+		t.Fatalf("expected 2 testcases with 1 failure, got %+v", suite)
+	}
+	if res := suite.Results[0]; res.ClassName != SuiteName || res.Failure != nil {
+		t.Errorf("expected healthy built-in check, got %+v", res)
+	}
+	if res := suite.Results[1]; res.ClassName != CustomClassName ||
+		res.Name != "[health] [custom] addon-ready should be healthy after upgrade-1" ||
+		res.Failure == nil || *res.Failure != "addon namespace is missing" {
+		t.Errorf("expected failed custom check, got %+v", res)
+	}
+}
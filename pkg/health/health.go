@@ -0,0 +1,241 @@
+// Package health decides whether a cluster is healthy: its nodes are ready, its ClusterVersion is available and not
+// failing, its ClusterOperators are available and not degraded or progressing, and checks registered by suites pass.
+// Runs wait for clusters to be healthy after install and each upgrade, and osde2e-healthcheck uses the same checks.
+package health
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	configclient "github.com/openshift/client-go/config/clientset/versioned"
+	kubev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/openshift/osde2e/pkg/conditions"
+)
+
+// Checks of the health of a cluster.
+const (
+	CheckNodes          = "nodes"
+	CheckClusterVersion = "cluster-version"
+	CheckOperators      = "operators"
+	CheckControlPlane   = "control-plane"
+)
+
+const (
+	// clusterVersionName is the name of the ClusterVersion of clusters.
+	clusterVersionName = "version"
+
+	// failingCondition is true while the cluster-version-operator can't reconcile the cluster.
+	failingCondition = "Failing"
+)
+
+// Result is the outcome of one check of a cluster.
+type Result struct {
+	Name    string `json:"name"`
+	Healthy bool   `json:"healthy"`
+
+	// Problems are why the check isn't healthy.
+	Problems []string `json:"problems,omitempty"`
+
+	// Custom is set for checks registered by suites and add-on harnesses.
+	Custom bool `json:"custom,omitempty"`
+}
+
+// Report is the outcome of checking a cluster. It's healthy if every check is.
+type Report struct {
+	Checked time.Time `json:"checked"`
+	Healthy bool      `json:"healthy"`
+	Results []Result  `json:"results"`
+}
+
+// Add records the result of the check name, which is healthy if there are no problems.
+func (r *Report) Add(name string, problems []string) {
+	r.add(Result{Name: name, Healthy: len(problems) == 0, Problems: problems})
+}
+
+// AddCustom records the result of the custom check name, which is healthy if there are no problems.
+func (r *Report) AddCustom(name string, problems []string) {
+	r.add(Result{Name: name, Healthy: len(problems) == 0, Problems: problems, Custom: true})
+}
+
+func (r *Report) add(res Result) {
+	r.Results = append(r.Results, res)
+	r.Healthy = r.Healthy && res.Healthy
+}
+
+// Problems returns why the cluster isn't healthy, prefixed by their check.
+func (r *Report) Problems() (problems []string) {
+	for _, res := range r.Results {
+		for _, p := range res.Problems {
+			problems = append(problems, fmt.Sprintf("%s: %s", res.Name, p))
+		}
+	}
+	return
+}
+
+// Checker checks the health of a cluster. Checks of APIs the cluster doesn't serve, such as ClusterOperators on
+// Kubernetes clusters, are left out. Config may be nil for clusters which aren't OpenShift. Custom checks added with
+// Register are checked after the built-in ones.
+type Checker struct {
+	Kube   kubernetes.Interface
+	Config configclient.Interface
+
+	// ControlPlane checks a control plane hosted outside of the cluster, returning why it isn't healthy. It's only
+	// checked when set.
+	ControlPlane func() []string
+
+	// IgnoredOperators are ClusterOperators which aren't checked, such as those run by a hosted control plane.
+	IgnoredOperators []string
+}
+
+// Check checks the cluster once.
+func (c *Checker) Check() *Report {
+	r := &Report{
+		Checked: time.Now().UTC(),
+		Healthy: true,
+	}
+	r.Add(CheckNodes, c.nodes())
+	if c.ControlPlane != nil {
+		r.Add(CheckControlPlane, c.ControlPlane())
+	}
+	if c.Config != nil {
+		if problems, served := c.clusterVersion(); served {
+			r.Add(CheckClusterVersion, problems)
+		}
+		if problems, served := c.operators(); served {
+			r.Add(CheckOperators, problems)
+		}
+	}
+	for _, name := range Registered() {
+		r.AddCustom(name, c.custom(name))
+	}
+	return r
+}
+
+// Wait checks the cluster every interval until it's healthy, timeout is reached, or ctx is done. The last report is
+// returned, with an error listing its problems if the cluster never became healthy.
+func (c *Checker) Wait(ctx context.Context, interval, timeout time.Duration) (*Report, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var r *Report
+	err := wait.PollImmediateUntil(interval, func() (bool, error) {
+		if r = c.Check(); !r.Healthy {
+			log.Printf("Waiting for cluster to be healthy: %v", r.Problems())
+		}
+		return r.Healthy, nil
+	}, ctx.Done())
+	if err != nil {
+		return r, fmt.Errorf("cluster not healthy: %v: %v", r.Problems(), err)
+	}
+	return r, nil
+}
+
+func (c *Checker) nodes() []string {
+	list, err := c.Kube.CoreV1().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		return []string{fmt.Sprintf("couldn't list nodes: %v", err)}
+	} else if len(list.Items) == 0 {
+		return []string{"there are no nodes"}
+	}
+	return NotReadyNodes(list.Items)
+}
+
+func (c *Checker) clusterVersion() (problems []string, served bool) {
+	cv, err := c.Config.ConfigV1().ClusterVersions().Get(clusterVersionName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, false
+	} else if err != nil {
+		return []string{fmt.Sprintf("couldn't get ClusterVersion: %v", err)}, true
+	}
+	return ClusterVersionProblems(cv), true
+}
+
+func (c *Checker) operators() (problems []string, served bool) {
+	list, err := c.Config.ConfigV1().ClusterOperators().List(metav1.ListOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, false
+	} else if err != nil {
+		return []string{fmt.Sprintf("couldn't list ClusterOperators: %v", err)}, true
+	}
+	operators := list.Items[:0]
+	for _, co := range list.Items {
+		if !contains(c.IgnoredOperators, co.Name) {
+			operators = append(operators, co)
+		}
+	}
+	conditions.Current.Observe(operators, time.Now())
+	return UnhealthyOperators(operators), true
+}
+
+func contains(strs []string, s string) bool {
+	for _, str := range strs {
+		if str == s {
+			return true
+		}
+	}
+	return false
+}
+
+// NotReadyNodes returns the names of nodes without a true Ready condition.
+func NotReadyNodes(nodes []kubev1.Node) (notReady []string) {
+	for _, node := range nodes {
+		ready := false
+		for _, cond := range node.Status.Conditions {
+			if cond.Type == kubev1.NodeReady {
+				ready = cond.Status == kubev1.ConditionTrue
+			}
+		}
+		if !ready {
+			notReady = append(notReady, node.Name)
+		}
+	}
+	return
+}
+
+// ClusterVersionProblems returns why cv isn't available or is failing.
+func ClusterVersionProblems(cv *configv1.ClusterVersion) (problems []string) {
+	available := false
+	for _, c := range cv.Status.Conditions {
+		switch {
+		case c.Type == configv1.OperatorAvailable:
+			available = c.Status == configv1.ConditionTrue
+		case c.Type == failingCondition && c.Status == configv1.ConditionTrue:
+			problems = append(problems, fmt.Sprintf("failing: %s", c.Message))
+		}
+	}
+	if !available {
+		problems = append(problems, "not available")
+	}
+	return
+}
+
+// UnhealthyOperators returns the names of operators that are unavailable, degraded, or progressing.
+func UnhealthyOperators(operators []configv1.ClusterOperator) (unhealthy []string) {
+	for _, co := range operators {
+		available, degraded, progressing := false, false, false
+		for _, c := range co.Status.Conditions {
+			isTrue := c.Status == configv1.ConditionTrue
+			switch c.Type {
+			case configv1.OperatorAvailable:
+				available = isTrue
+			case configv1.OperatorDegraded:
+				degraded = isTrue
+			case configv1.OperatorProgressing:
+				progressing = isTrue
+			}
+		}
+
+		if !available || degraded || progressing {
+			unhealthy = append(unhealthy, co.Name)
+		}
+	}
+	return
+}
@@ -0,0 +1,113 @@
+package health
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	configfake "github.com/openshift/client-go/config/clientset/versioned/fake"
+	kubev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestUnhealthyOperators(t *testing.T) {
+	operator := func(name string, available, degraded, progressing configv1.ConditionStatus) configv1.ClusterOperator {
+		return configv1.ClusterOperator{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+			},
+			Status: configv1.ClusterOperatorStatus{
+				Conditions: []configv1.ClusterOperatorStatusCondition{
+					{Type: configv1.OperatorDegraded, Status: degraded},
+					{Type: configv1.OperatorProgressing, Status: progressing},
+					{Type: configv1.OperatorAvailable, Status: available},
+				},
+			},
+		}
+	}
+
+	operators := []configv1.ClusterOperator{
+		operator("healthy", configv1.ConditionTrue, configv1.ConditionFalse, configv1.ConditionFalse),
+		operator("unavailable", configv1.ConditionFalse, configv1.ConditionFalse, configv1.ConditionFalse),
+		operator("degraded", configv1.ConditionTrue, configv1.ConditionTrue, configv1.ConditionFalse),
+		operator("progressing", configv1.ConditionTrue, configv1.ConditionFalse, configv1.ConditionTrue),
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "no-conditions",
+			},
+		},
+	}
+
+	expected := []string{"unavailable", "degraded", "progressing", "no-conditions"}
+	if unhealthy := UnhealthyOperators(operators); !reflect.DeepEqual(unhealthy, expected) {
+		t.Fatalf("expected unhealthy operators %v, got %v", expected, unhealthy)
+	}
+}
+
+func TestChecker(t *testing.T) {
+	node := &kubev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker"},
+		Status: kubev1.NodeStatus{
+			Conditions: []kubev1.NodeCondition{{Type: kubev1.NodeReady, Status: kubev1.ConditionFalse}},
+		},
+	}
+	cv := &configv1.ClusterVersion{
+		ObjectMeta: metav1.ObjectMeta{Name: clusterVersionName},
+		Status: configv1.ClusterVersionStatus{
+			Conditions: []configv1.ClusterOperatorStatusCondition{
+				{Type: configv1.OperatorAvailable, Status: configv1.ConditionTrue},
+				{Type: failingCondition, Status: configv1.ConditionTrue, Message: "payload couldn't be verified"},
+			},
+		},
+	}
+	co := &configv1.ClusterOperator{
+		ObjectMeta: metav1.ObjectMeta{Name: "ingress"},
+		Status: configv1.ClusterOperatorStatus{
+			Conditions: []configv1.ClusterOperatorStatusCondition{
+				{Type: configv1.OperatorAvailable, Status: configv1.ConditionFalse},
+			},
+		},
+	}
+	kube := kubefake.NewSimpleClientset(node)
+	c := &Checker{Kube: kube, Config: configfake.NewSimpleClientset(cv, co)}
+
+	r := c.Check()
+	expected := []string{
+		"nodes: worker",
+		"cluster-version: failing: payload couldn't be verified",
+		"operators: ingress",
+	}
+	if r.Healthy || !reflect.DeepEqual(r.Problems(), expected) {
+		t.Errorf("expected problems %v, got %v", expected, r.Problems())
+	}
+	if _, err := c.Wait(context.Background(), time.Millisecond, 10*time.Millisecond); err == nil {
+		t.Error("expected unhealthy cluster to time out")
+	}
+
+	// operators run by hosted control planes are checked through the control plane instead
+	c.ControlPlane = func() []string { return []string{"not available"} }
+	c.IgnoredOperators = []string{"ingress"}
+	expected = []string{
+		"nodes: worker",
+		"control-plane: not available",
+		"cluster-version: failing: payload couldn't be verified",
+	}
+	if r = c.Check(); !reflect.DeepEqual(r.Problems(), expected) {
+		t.Errorf("expected problems %v, got %v", expected, r.Problems())
+	}
+	c.ControlPlane, c.IgnoredOperators = nil, nil
+
+	// Kubernetes clusters only have nodes checked
+	node.Status.Conditions[0].Status = kubev1.ConditionTrue
+	if _, err := kube.CoreV1().Nodes().UpdateStatus(node); err != nil {
+		t.Fatal(err)
+	}
+	c.Config = nil
+	r, err := c.Wait(context.Background(), time.Millisecond, 10*time.Millisecond)
+	if err != nil || len(r.Results) != 1 || r.Results[0].Name != CheckNodes {
+		t.Errorf("expected only healthy nodes to be checked, got %+v: %v", r, err)
+	}
+}
@@ -0,0 +1,47 @@
+package health
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/test-infra/testgrid/metadata/junit"
+
+	"github.com/openshift/osde2e/pkg/junitprops"
+)
+
+const (
+	// SuiteName is the JUnit suite containing a testcase for each check of the cluster after a stage of the run.
+	SuiteName = "Cluster health"
+
+	// CustomClassName is the class of testcases of custom checks, distinguishing them from built-in checks.
+	CustomClassName = "Custom health checks"
+)
+
+// WriteJUnit records a testcase in dir for each check of r, the last health check after stage, such as 'install' or
+// 'upgrade-1'. Testcases of custom checks are named and classed apart from built-in ones.
+func WriteJUnit(dir, suffix, stage string, r *Report) error {
+	suite := junit.Suite{
+		Name: SuiteName,
+	}
+	if r != nil {
+		suite.Tests = len(r.Results)
+		for _, res := range r.Results {
+			result := junit.Result{
+				Name:      fmt.Sprintf("[health] %s should be healthy after %s", res.Name, stage),
+				ClassName: SuiteName,
+			}
+			if res.Custom {
+				result.Name = fmt.Sprintf("[health] [custom] %s should be healthy after %s", res.Name, stage)
+				result.ClassName = CustomClassName
+			}
+			if !res.Healthy {
+				msg := strings.Join(res.Problems, "\n")
+				result.Failure = &msg
+				suite.Failures++
+			}
+			suite.Results = append(suite.Results, result)
+		}
+	}
+
+	return junitprops.WriteSuite(dir, "health_"+stage, suffix, suite)
+}
@@ -1,15 +1,21 @@
 package helper
 
 import (
+	"net/http"
+
 	. "github.com/onsi/gomega"
 
 	config "github.com/openshift/client-go/config/clientset/versioned"
 	image "github.com/openshift/client-go/image/clientset/versioned"
 	project "github.com/openshift/client-go/project/clientset/versioned"
 	route "github.com/openshift/client-go/route/clientset/versioned"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
 )
 
 // Cfg return a client for the Config API.
@@ -26,6 +32,23 @@ func (h *H) Dynamic() dynamic.Interface {
 	return client
 }
 
+// Discovery returns a client for discovering the APIs supported by the cluster. Results are cached for the life of h.
+func (h *H) Discovery() discovery.CachedDiscoveryInterface {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.discovery == nil {
+		client, err := discovery.NewDiscoveryClientForConfig(h.restConfig)
+		Expect(err).ShouldNot(HaveOccurred(), "failed to configure Discovery client")
+		h.discovery = memory.NewMemCacheClient(client)
+	}
+	return h.discovery
+}
+
+// RESTMapper returns a mapper between Kinds and Resources using cached discovery information.
+func (h *H) RESTMapper() meta.RESTMapper {
+	return restmapper.NewDeferredDiscoveryRESTMapper(h.Discovery())
+}
+
 // Kube returns the clientset for Kubernetes upstream.
 func (h *H) Kube() kubernetes.Interface {
 	client, err := kubernetes.NewForConfig(h.restConfig)
@@ -54,6 +77,13 @@ func (h *H) Project() project.Interface {
 	return client
 }
 
+// HTTPClient returns a client for making requests to the API server directly.
+func (h *H) HTTPClient() *http.Client {
+	rt, err := rest.TransportFor(h.restConfig)
+	Expect(err).ShouldNot(HaveOccurred(), "failed to configure HTTP client")
+	return &http.Client{Transport: rt}
+}
+
 // REST returns a client for generic operations.
 func (h *H) REST() *rest.RESTClient {
 	client, err := rest.RESTClientFor(h.restConfig)
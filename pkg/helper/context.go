@@ -0,0 +1,105 @@
+package helper
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// phase holds the context of the phase being run, which contexts of helpers are derived from.
+var phase struct {
+	sync.Mutex
+	ctx context.Context
+}
+
+// SetPhaseContext makes contexts of helpers end once ctx is done, so nothing they start outlives the phase.
+func SetPhaseContext(ctx context.Context) {
+	phase.Lock()
+	defer phase.Unlock()
+	phase.ctx = ctx
+}
+
+// PhaseContext returns the context of the phase being run. It's never done if none was set.
+func PhaseContext() context.Context {
+	phase.Lock()
+	defer phase.Unlock()
+	if phase.ctx == nil {
+		return context.Background()
+	}
+	return phase.ctx
+}
+
+// Context returns the context of the current spec. It's done once the spec ends or exceeds SpecTimeout. Outside
+// of specs it's the context of the phase being run.
+func (h *H) Context() context.Context {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.ctx == nil {
+		return PhaseContext()
+	}
+	return h.ctx
+}
+
+// ExtendContext restarts the context of the current spec so it ends after timeout, for specs which wait on work known
+// to take longer than SpecTimeout, such as upgrades. It still ends with the phase.
+func (h *H) ExtendContext(timeout time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.cancel != nil {
+		h.cancel()
+	}
+	h.ctx, h.cancel = context.WithTimeout(PhaseContext(), timeout)
+}
+
+// endContext cancels the context of the spec so in-flight requests and polling stop. Later requests, such as
+// those cleaning up, use the context of the phase.
+func (h *H) endContext() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.cancel != nil {
+		h.cancel()
+	}
+	h.ctx, h.cancel = nil, nil
+}
+
+// Poll checks condition every interval until it returns true, an error, timeout is reached, or the context of h is
+// done. wait.ErrWaitTimeout is returned if condition wasn't met in time.
+func (h *H) Poll(interval, timeout time.Duration, condition wait.ConditionFunc) error {
+	ctx, cancel := context.WithTimeout(h.Context(), timeout)
+	defer cancel()
+	return wait.PollUntil(interval, condition, ctx.Done())
+}
+
+// PollImmediate is Poll, checking condition before waiting for the first interval.
+func (h *H) PollImmediate(interval, timeout time.Duration, condition wait.ConditionFunc) error {
+	ctx, cancel := context.WithTimeout(h.Context(), timeout)
+	defer cancel()
+	return wait.PollImmediateUntil(interval, condition, ctx.Done())
+}
+
+// sleep waits for d, returning false if the context of h is done first.
+func (h *H) sleep(d time.Duration) bool {
+	select {
+	case <-time.After(d):
+		return true
+	case <-h.Context().Done():
+		return false
+	}
+}
+
+// wrapTransport makes requests with the context of h at the time they're sent.
+func (h *H) wrapTransport(rt http.RoundTripper) http.RoundTripper {
+	return &contextTransport{h: h, rt: rt}
+}
+
+type contextTransport struct {
+	h  *H
+	rt http.RoundTripper
+}
+
+func (t *contextTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.rt.RoundTrip(req.WithContext(t.h.Context()))
+}
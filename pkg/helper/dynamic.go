@@ -0,0 +1,137 @@
+package helper
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	// FieldManager identifies osde2e as the owner of fields set using server-side apply.
+	FieldManager = "osde2e"
+)
+
+// GVR returns the GroupVersionResource for the given GroupVersionKind using discovery.
+func (h *H) GVR(gvk schema.GroupVersionKind) (schema.GroupVersionResource, error) {
+	mapping, err := h.RESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return schema.GroupVersionResource{}, fmt.Errorf("couldn't find resource for '%s': %v", gvk, err)
+	}
+	return mapping.Resource, nil
+}
+
+// GetResource retrieves the object name of resource gvr in namespace. Namespace should be empty for cluster-scoped resources.
+func (h *H) GetResource(gvr schema.GroupVersionResource, namespace, name string) (*unstructured.Unstructured, error) {
+	obj, err := h.resource(gvr, namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get %s '%s': %v", gvr.Resource, objName(namespace, name), err)
+	}
+	return obj, nil
+}
+
+// ApplyResource creates or updates obj as resource gvr. Server-side apply is used when supported by the cluster,
+// otherwise obj is created or replaces the existing object.
+func (h *H) ApplyResource(gvr schema.GroupVersionResource, obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	client := h.resource(gvr, obj.GetNamespace())
+	name := objName(obj.GetNamespace(), obj.GetName())
+
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't encode %s '%s': %v", gvr.Resource, name, err)
+	}
+
+	force := true
+	applied, err := client.Patch(obj.GetName(), types.ApplyPatchType, data, metav1.PatchOptions{
+		FieldManager: FieldManager,
+		Force:        &force,
+	})
+	if err == nil {
+		return applied, nil
+	} else if !kerror.IsUnsupportedMediaType(err) && !kerror.IsBadRequest(err) {
+		return nil, fmt.Errorf("couldn't apply %s '%s': %v", gvr.Resource, name, err)
+	}
+
+	// fallback to create or update when server-side apply isn't available
+	existing, err := client.Get(obj.GetName(), metav1.GetOptions{})
+	if kerror.IsNotFound(err) {
+		if applied, err = client.Create(obj, metav1.CreateOptions{}); err != nil {
+			return nil, fmt.Errorf("couldn't create %s '%s': %v", gvr.Resource, name, err)
+		}
+		return applied, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("couldn't get %s '%s': %v", gvr.Resource, name, err)
+	}
+
+	obj.SetResourceVersion(existing.GetResourceVersion())
+	if applied, err = client.Update(obj, metav1.UpdateOptions{}); err != nil {
+		return nil, fmt.Errorf("couldn't update %s '%s': %v", gvr.Resource, name, err)
+	}
+	return applied, nil
+}
+
+// DeleteResource deletes the object name of resource gvr in namespace. Objects that don't exist are ignored.
+func (h *H) DeleteResource(gvr schema.GroupVersionResource, namespace, name string) error {
+	err := h.resource(gvr, namespace).Delete(name, &metav1.DeleteOptions{})
+	if err != nil && !kerror.IsNotFound(err) {
+		return fmt.Errorf("couldn't delete %s '%s': %v", gvr.Resource, objName(namespace, name), err)
+	}
+	return nil
+}
+
+// WaitForResourceCondition until the object name of resource gvr has a status condition condType of status,
+// checking every interval until timeout or the context of h is done.
+func (h *H) WaitForResourceCondition(gvr schema.GroupVersionResource, namespace, name, condType, status string, interval, timeout time.Duration) error {
+	return h.PollImmediate(interval, timeout, func() (bool, error) {
+		obj, err := h.resource(gvr, namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			log.Printf("Error getting %s '%s': %v", gvr.Resource, objName(namespace, name), err)
+			return false, nil
+		}
+
+		if curStatus, found := ConditionStatus(obj, condType); found && curStatus == status {
+			return true, nil
+		}
+
+		log.Printf("Waiting for %s '%s' to have condition %s=%s...", gvr.Resource, objName(namespace, name), condType, status)
+		return false, nil
+	})
+}
+
+// ConditionStatus returns the status of the condition condType found in the status of obj.
+func ConditionStatus(obj *unstructured.Unstructured, condType string) (status string, found bool) {
+	conditions, ok, err := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	if err != nil || !ok {
+		return "", false
+	}
+
+	for _, c := range conditions {
+		if condition, ok := c.(map[string]interface{}); ok && condition["type"] == condType {
+			status, found = condition["status"].(string)
+			return
+		}
+	}
+	return "", false
+}
+
+func (h *H) resource(gvr schema.GroupVersionResource, namespace string) dynamic.ResourceInterface {
+	client := h.Dynamic().Resource(gvr)
+	if namespace == "" {
+		return client
+	}
+	return client.Namespace(namespace)
+}
+
+func objName(namespace, name string) string {
+	if namespace == "" {
+		return name
+	}
+	return namespace + "/" + name
+}
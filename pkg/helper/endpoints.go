@@ -10,7 +10,8 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-// WaitForEndpointReady until Endpoint for svc is ready, checking n times and sleeping dur between them.
+// WaitForEndpointReady until Endpoint for svc is ready, checking n times and sleeping dur between them, or until the
+// context of h is done.
 func (h *H) WaitForEndpointReady(svc *kubev1.Service, n int, dur time.Duration) error {
 	if svc == nil {
 		return errors.New("svc was nil")
@@ -28,7 +29,9 @@ func (h *H) WaitForEndpointReady(svc *kubev1.Service, n int, dur time.Duration)
 		}
 
 		log.Printf("Waiting for Endpoint '%s/%s' to be ready...", svc.Namespace, svc.Name)
-		time.Sleep(dur)
+		if !h.sleep(dur) {
+			break
+		}
 	}
 
 	return fmt.Errorf("timeout waiting for Endpoint '%s/%s' to be ready", svc.Namespace, svc.Name)
@@ -0,0 +1,112 @@
+package helper
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	kubev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
+	"k8s.io/client-go/util/exec"
+)
+
+var (
+	// ErrExecTimeout is returned when a command doesn't complete within ExecTimeout. It isn't retried.
+	ErrExecTimeout = errors.New("command timed out")
+
+	// ExecTimeout is how long a command run with Exec may take.
+	ExecTimeout = 2 * time.Minute
+
+	// ExecAttempts is the number of times a command is run with Exec when it fails to connect.
+	ExecAttempts = 3
+
+	// ExecRetryInterval is the delay before the first retry of Exec, doubling with each subsequent attempt.
+	ExecRetryInterval = 5 * time.Second
+)
+
+// ExecResult is the output of a command run in a container.
+type ExecResult struct {
+	Stdout []byte
+	Stderr []byte
+}
+
+// Exec runs cmd in container of pod in namespace, returning its output. Attempts which fail to connect are retried.
+// An error is returned if cmd exits with a non-zero status or doesn't complete within ExecTimeout.
+func (h *H) Exec(namespace, pod, container string, cmd ...string) (result ExecResult, err error) {
+	backoff := wait.Backoff{
+		Duration: ExecRetryInterval,
+		Factor:   2,
+		Steps:    ExecAttempts,
+	}
+
+	attempt := 0
+	retryErr := wait.ExponentialBackoff(backoff, func() (bool, error) {
+		attempt++
+		result, err = h.exec(namespace, pod, container, cmd)
+		if err == nil {
+			return true, nil
+		}
+
+		// the command ran, may still be running, or the spec has ended, so retrying wouldn't help
+		if _, ok := err.(exec.ExitError); ok || err == ErrExecTimeout || err == context.Canceled || err == context.DeadlineExceeded {
+			return false, err
+		}
+
+		log.Printf("Attempt %d of exec in '%s/%s' failed: %v", attempt, namespace, pod, err)
+		return false, nil
+	})
+
+	if retryErr == wait.ErrWaitTimeout {
+		return result, fmt.Errorf("couldn't exec in '%s/%s' after %d attempts: %v", namespace, pod, attempt, err)
+	} else if retryErr != nil {
+		return result, fmt.Errorf("command %v in '%s/%s' failed: %v", cmd, namespace, pod, retryErr)
+	}
+	return result, nil
+}
+
+// exec runs cmd once, returning when it completes, ExecTimeout passes, or the context of h is done.
+func (h *H) exec(namespace, pod, container string, cmd []string) (ExecResult, error) {
+	req := h.Kube().CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(namespace).
+		Name(pod).
+		SubResource("exec").
+		VersionedParams(&kubev1.PodExecOptions{
+			Container: container,
+			Command:   cmd,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+
+	executor, err := remotecommand.NewSPDYExecutor(h.restConfig, "POST", req.URL())
+	if err != nil {
+		return ExecResult{}, fmt.Errorf("couldn't setup exec: %v", err)
+	}
+
+	ctx := h.Context()
+	var stdout, stderr bytes.Buffer
+	done := make(chan error, 1)
+	go func() {
+		done <- executor.Stream(remotecommand.StreamOptions{
+			Stdout: &stdout,
+			Stderr: &stderr,
+		})
+	}()
+
+	select {
+	case err = <-done:
+		return ExecResult{
+			Stdout: stdout.Bytes(),
+			Stderr: stderr.Bytes(),
+		}, err
+	case <-time.After(ExecTimeout):
+		return ExecResult{}, ErrExecTimeout
+	case <-ctx.Done():
+		return ExecResult{}, ctx.Err()
+	}
+}
@@ -2,30 +2,53 @@
 package helper
 
 import (
-	"math/rand"
+	"context"
+	"fmt"
+	"sync"
 	"time"
 
 	"github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
 	projectv1 "github.com/openshift/api/project/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 
 	"github.com/openshift/osde2e/pkg/config"
+	"github.com/openshift/osde2e/pkg/drift"
+	"github.com/openshift/osde2e/pkg/groups"
+	"github.com/openshift/osde2e/pkg/naming"
+	"github.com/openshift/osde2e/pkg/osd"
+	"github.com/openshift/osde2e/pkg/seed"
+	"github.com/openshift/osde2e/pkg/skips"
+	"github.com/openshift/osde2e/pkg/usage"
 )
 
-func init() {
-	rand.Seed(time.Now().Unix())
-}
-
-// New creates H, a helper used to expose common testing functions.
+// New creates H, a helper used to expose common testing functions. Its state is reset before each spec, which is
+// given a context that's cancelled when the spec ends or exceeds SpecTimeout or the rest of its group's budget.
+// Specs of groups which spent their budget are skipped, as are all specs once the cluster's version drifts if the run
+// aborts on drift. The namespaces and time of specs are recorded so the usage of their suites can be measured. When
+// testing with least privilege, specs act as a ServiceAccount with only the permissions declared with Requires.
 func New() *H {
-	helper := &H{
-		Config: config.Cfg,
-	}
-	ginkgo.BeforeEach(helper.Setup)
-	ginkgo.AfterEach(helper.Cleanup)
+	helper := new(H)
+	ginkgo.BeforeEach(func() {
+		drift.Current.SkipIfDrifted()
+		desc := ginkgo.CurrentGinkgoTestDescription()
+		suite := desc.ComponentTexts[0]
+		helper.reset(config.Cfg, groups.Budgets.StartSpec(suite, config.Cfg.SpecTimeout))
+		helper.Setup()
+		if config.Cfg.LeastPrivilege {
+			err := helper.restrict(suite, desc.TestText)
+			Expect(err).ShouldNot(HaveOccurred(), "failed to restrict spec to the permissions of its suite")
+		}
+		usage.Current.Start(suite, helper.CurrentProject(), time.Now())
+	})
+	ginkgo.AfterEach(func() {
+		usage.Current.End(ginkgo.CurrentGinkgoTestDescription().ComponentTexts[0], time.Now())
+		helper.Cleanup()
+	})
 	return helper
 }
 
@@ -35,18 +58,34 @@ type H struct {
 	*config.Config
 
 	// internal
+	mu         sync.Mutex
+	ctx        context.Context
+	cancel     context.CancelFunc
 	restConfig *rest.Config
 	proj       *projectv1.Project
+	discovery  discovery.CachedDiscoveryInterface
+
+	// adminConfig is the cluster's admin while restConfig is restricted to the permissions declared in rules.
+	adminConfig *rest.Config
+	rules       []rbacv1.PolicyRule
+}
+
+// reset clears the state of the previous spec so none is shared between them, and starts a context for the next
+// which ends after timeout.
+func (h *H) reset(cfg *config.Config, timeout time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.Config = cfg
+	h.ctx, h.cancel = context.WithTimeout(PhaseContext(), timeout)
+	h.restConfig, h.adminConfig, h.proj, h.discovery = nil, nil, nil, nil
 }
 
 // Setup configures a *rest.Config using the embedded kubeconfig then sets up a Project for tests to run in.
 func (h *H) Setup() {
-	var err error
-	h.restConfig, err = clientcmd.RESTConfigFromKubeConfig(h.Kubeconfig)
-	Expect(err).ShouldNot(HaveOccurred(), "failed to configure client")
+	h.SetupClients()
 
 	// setup project to run tests
-	suffix := randomStr(5)
+	suffix := naming.New("", h.JobID, seed.String(seed.Namespaces, 5)).Suffix()
 	proj, err := h.createProject(suffix)
 	Expect(err).ShouldNot(HaveOccurred(), "failed to create project")
 	Expect(proj).ShouldNot(BeNil())
@@ -54,8 +93,20 @@ func (h *H) Setup() {
 	h.proj = proj
 }
 
-// Cleanup deletes a Project after tests have been ran.
+// SetupClients configures a *rest.Config using the embedded kubeconfig without creating a Project, for read-only use.
+// Requests made with the clients are cancelled along with the context of h.
+func (h *H) SetupClients() {
+	restConfig, err := clientcmd.RESTConfigFromKubeConfig(h.Kubeconfig)
+	Expect(err).ShouldNot(HaveOccurred(), "failed to configure client")
+	restConfig.Wrap(h.wrapTransport)
+	h.restConfig = restConfig
+}
+
+// Cleanup cancels the context of the spec then deletes its Project.
 func (h *H) Cleanup() {
+	h.endContext()
+	h.unrestrict()
+
 	err := h.cleanup(h.proj.Name)
 	Expect(err).ShouldNot(HaveOccurred(), "could not delete project '%s'", h.proj)
 
@@ -63,6 +114,42 @@ func (h *H) Cleanup() {
 	h.proj = nil
 }
 
+// SkipLocal skips the spec when testing a cluster with the local provider, which doesn't run the components OSD
+// manages.
+func (h *H) SkipLocal() {
+	if h.Provider == config.ProviderLocal {
+		skips.Skip(skips.CapabilityMissing, "local clusters aren't managed by OSD")
+	}
+}
+
+// SkipFedRAMP skips the spec when testing a FedRAMP environment, which doesn't provide what, such as services sending
+// data outside of its boundary.
+func (h *H) SkipFedRAMP(what string) {
+	if osd.IsFedRAMP(h.OSDEnv) {
+		skips.Skip(skips.CapabilityMissing, fmt.Sprintf("%s isn't available in %s", what, h.OSDEnv))
+	}
+}
+
+// SkipShared skips the spec when testing a cluster shared with other runs, which the spec would disrupt by changing
+// the whole cluster.
+func (h *H) SkipShared() {
+	if h.SharedCluster {
+		skips.Skip(skips.ConfigExcluded, "cluster is shared with other runs")
+	}
+}
+
+// ServiceLogger writes what specs did to the cluster to its service log in OSD. It's set by the run when service logs
+// are written.
+var ServiceLogger func(summary, format string, args ...interface{})
+
+// ServiceLog writes what the spec did to the cluster, such as hibernating it, to its service log so SREs coming across
+// the cluster can see what was done to it.
+func (h *H) ServiceLog(summary, format string, args ...interface{}) {
+	if ServiceLogger != nil {
+		ServiceLogger(summary, format, args...)
+	}
+}
+
 // CurrentProject returns the project being used for testing.
 func (h *H) CurrentProject() string {
 	Expect(h.proj).NotTo(BeNil(), "no project is currently set")
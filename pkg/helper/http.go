@@ -0,0 +1,239 @@
+package helper
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	routev1 "github.com/openshift/api/route/v1"
+)
+
+const (
+	// defaultHTTPInterval is how long HTTPChecker waits between requests by default.
+	defaultHTTPInterval = 5 * time.Second
+
+	// defaultHTTPTimeout is how long HTTPChecker retries by default.
+	defaultHTTPTimeout = 2 * time.Minute
+
+	// httpRequestTimeout is how long each request of an HTTPChecker may take.
+	httpRequestTimeout = 30 * time.Second
+)
+
+// HTTPChecker requests URLs served by the cluster, such as those of routes, retrying until responses meet its
+// expectations. It expects 200 OK by default, and doesn't verify certificates unless VerifyTLS is called since the
+// default certificates of clusters may not be trusted.
+type HTTPChecker struct {
+	h *H
+
+	statuses   []int
+	body       *regexp.Regexp
+	minTLS     uint16
+	certValid  time.Duration
+	verifyTLS  bool
+	maxLatency time.Duration
+	interval   time.Duration
+	timeout    time.Duration
+	successes  int
+
+	// err is an invalid expectation, returned by Check.
+	err error
+}
+
+// HTTPChecker returns a checker making requests with the context of h.
+func (h *H) HTTPChecker() *HTTPChecker {
+	return &HTTPChecker{
+		h:         h,
+		statuses:  []int{http.StatusOK},
+		interval:  defaultHTTPInterval,
+		timeout:   defaultHTTPTimeout,
+		successes: 1,
+	}
+}
+
+// ExpectStatus requires responses to have one of statuses.
+func (c *HTTPChecker) ExpectStatus(statuses ...int) *HTTPChecker {
+	c.statuses = statuses
+	return c
+}
+
+// ExpectBody requires the body of responses to match the regular expression expr.
+func (c *HTTPChecker) ExpectBody(expr string) *HTTPChecker {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		c.err = fmt.Errorf("invalid body expression '%s': %v", expr, err)
+	}
+	c.body = re
+	return c
+}
+
+// ExpectTLS requires URLs to be served over TLS of at least version, such as tls.VersionTLS12.
+func (c *HTTPChecker) ExpectTLS(version uint16) *HTTPChecker {
+	c.minTLS = version
+	return c
+}
+
+// ExpectCertValidFor requires the certificate served to remain valid for at least d.
+func (c *HTTPChecker) ExpectCertValidFor(d time.Duration) *HTTPChecker {
+	c.certValid = d
+	return c
+}
+
+// VerifyTLS requires certificates served to be trusted and match the host requested.
+func (c *HTTPChecker) VerifyTLS() *HTTPChecker {
+	c.verifyTLS = true
+	return c
+}
+
+// ExpectLatency requires responses to be read within max.
+func (c *HTTPChecker) ExpectLatency(max time.Duration) *HTTPChecker {
+	c.maxLatency = max
+	return c
+}
+
+// Retry requests every interval until expectations are met or timeout is reached. A timeout of 0 only requests once.
+func (c *HTTPChecker) Retry(interval, timeout time.Duration) *HTTPChecker {
+	c.interval, c.timeout = interval, timeout
+	return c
+}
+
+// Successes requires n consecutive responses to meet expectations, so URLs which are only briefly available fail.
+func (c *HTTPChecker) Successes(n int) *HTTPChecker {
+	c.successes = n
+	return c
+}
+
+// Client returns the client requests of c are made with, for callers sending requests of their own such as load.
+func (c *HTTPChecker) Client() *http.Client {
+	return &http.Client{
+		Timeout: httpRequestTimeout,
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: !c.verifyTLS},
+		},
+	}
+}
+
+// Check requests url until responses meet the expectations of c, returning why the last didn't if they never did.
+func (c *HTTPChecker) Check(url string) error {
+	_, err := c.Get(url)
+	return err
+}
+
+// Get checks url like Check, returning the body of the last response.
+func (c *HTTPChecker) Get(url string) (body []byte, err error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	client := c.Client()
+	var lastErr error
+	passed := 0
+	err = c.h.PollImmediate(c.interval, c.timeout, func() (bool, error) {
+		if body, lastErr = c.check(client, url); lastErr != nil {
+			log.Printf("Request to '%s' didn't meet expectations: %v", url, lastErr)
+			passed = 0
+			return c.timeout == 0, nil
+		}
+		passed++
+		return passed >= c.successes, nil
+	})
+	if lastErr != nil {
+		return nil, fmt.Errorf("'%s' didn't meet expectations: %v", url, lastErr)
+	} else if err != nil {
+		return nil, fmt.Errorf("'%s' didn't meet expectations %d times in a row: %v", url, c.successes, err)
+	}
+	return body, nil
+}
+
+// CheckRoute checks each host the route is admitted on, using HTTPS if the route terminates TLS.
+func (c *HTTPChecker) CheckRoute(route *routev1.Route) error {
+	if len(route.Status.Ingress) == 0 {
+		return fmt.Errorf("route '%s/%s' isn't admitted by any router", route.Namespace, route.Name)
+	}
+
+	scheme := "http"
+	if route.Spec.TLS != nil {
+		scheme = "https"
+	}
+	for _, ingress := range route.Status.Ingress {
+		if err := c.Check(fmt.Sprintf("%s://%s%s", scheme, ingress.Host, route.Spec.Path)); err != nil {
+			return fmt.Errorf("route '%s/%s': %v", route.Namespace, route.Name, err)
+		}
+	}
+	return nil
+}
+
+// check makes one request to url, returning its body and why its response doesn't meet the expectations of c.
+func (c *HTTPChecker) check(client *http.Client, url string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req.WithContext(c.h.Context()))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read body: %v", err)
+	}
+	latency := time.Since(start)
+
+	if !c.expectedStatus(resp.StatusCode) {
+		return body, fmt.Errorf("status was %d, expected one of %v", resp.StatusCode, c.statuses)
+	}
+	if c.body != nil && !c.body.Match(body) {
+		return body, fmt.Errorf("body didn't match '%s': %s", c.body, truncate(string(body), 200))
+	}
+	if c.maxLatency != 0 && latency > c.maxLatency {
+		return body, fmt.Errorf("response took %v, expected at most %v", latency, c.maxLatency)
+	}
+	return body, c.checkTLS(resp.TLS)
+}
+
+func (c *HTTPChecker) expectedStatus(status int) bool {
+	for _, s := range c.statuses {
+		if s == status {
+			return true
+		}
+	}
+	return len(c.statuses) == 0
+}
+
+// checkTLS returns why the TLS connection a response was served over doesn't meet the expectations of c.
+func (c *HTTPChecker) checkTLS(state *tls.ConnectionState) error {
+	if c.minTLS == 0 && c.certValid == 0 {
+		return nil
+	} else if state == nil {
+		return fmt.Errorf("response wasn't served over TLS")
+	}
+
+	if state.Version < c.minTLS {
+		return fmt.Errorf("TLS version was %#x, expected at least %#x", state.Version, c.minTLS)
+	}
+	if c.certValid != 0 && len(state.PeerCertificates) != 0 {
+		cert := state.PeerCertificates[0]
+		if until := time.Until(cert.NotAfter); until < c.certValid {
+			return fmt.Errorf("certificate for '%s' expires at %v, expected to be valid for at least %v",
+				cert.Subject.CommonName, cert.NotAfter, c.certValid)
+		}
+	}
+	return nil
+}
+
+// truncate shortens s to at most n bytes for messages.
+func truncate(s string, n int) string {
+	s = strings.TrimSpace(s)
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
@@ -0,0 +1,67 @@
+package helper
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHTTPChecker(t *testing.T) {
+	var requests int32
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/flaky":
+			// fails the first two requests
+			if atomic.AddInt32(&requests, 1) <= 2 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+		case "/slow":
+			time.Sleep(50 * time.Millisecond)
+		case "/missing":
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, "<title>Red Hat OpenShift</title>")
+	})
+	srv := httptest.NewTLSServer(handler)
+	defer srv.Close()
+	plain := httptest.NewServer(handler)
+	defer plain.Close()
+
+	h := &H{}
+	for name, test := range map[string]struct {
+		checker *HTTPChecker
+		url     string
+		fails   bool
+	}{
+		"ok":                {checker: h.HTTPChecker(), url: srv.URL},
+		"body":              {checker: h.HTTPChecker().ExpectBody("OpenShift"), url: srv.URL},
+		"body mismatch":     {checker: h.HTTPChecker().ExpectBody("^Kubernetes"), url: srv.URL, fails: true},
+		"invalid body":      {checker: h.HTTPChecker().ExpectBody("("), url: srv.URL, fails: true},
+		"status":            {checker: h.HTTPChecker().ExpectStatus(http.StatusNotFound), url: srv.URL + "/missing"},
+		"unexpected status": {checker: h.HTTPChecker(), url: srv.URL + "/missing", fails: true},
+		"retried":           {checker: h.HTTPChecker().Retry(time.Millisecond, time.Second), url: srv.URL + "/flaky"},
+		"tls":               {checker: h.HTTPChecker().ExpectTLS(tls.VersionTLS12), url: srv.URL},
+		"no tls":            {checker: h.HTTPChecker().ExpectTLS(tls.VersionTLS12), url: plain.URL, fails: true},
+		"untrusted":         {checker: h.HTTPChecker().VerifyTLS(), url: srv.URL, fails: true},
+		"cert valid":        {checker: h.HTTPChecker().ExpectCertValidFor(time.Hour), url: srv.URL},
+		"cert expires":      {checker: h.HTTPChecker().ExpectCertValidFor(100 * 365 * 24 * time.Hour), url: srv.URL, fails: true},
+		"latency":           {checker: h.HTTPChecker().ExpectLatency(time.Minute), url: srv.URL + "/slow"},
+		"latency exceeded":  {checker: h.HTTPChecker().ExpectLatency(time.Millisecond), url: srv.URL + "/slow", fails: true},
+	} {
+		if test.fails {
+			test.checker.Retry(time.Millisecond, 0)
+		}
+		err := test.checker.Check(test.url)
+		if test.fails && err == nil {
+			t.Errorf("%s: expected check to fail", name)
+		} else if !test.fails && err != nil {
+			t.Errorf("%s: expected check to pass, got: %v", name, err)
+		}
+	}
+}
@@ -10,7 +10,8 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-// WaitForPodPhase until in target, checking n times and sleeping dur between them. Last known phase is returned.
+// WaitForPodPhase until in target, checking n times and sleeping dur between them, or until the context of h is done.
+// Last known phase is returned.
 func (h *H) WaitForPodPhase(pod *kubev1.Pod, target kubev1.PodPhase, n int, dur time.Duration) (phase kubev1.PodPhase) {
 	var err error
 	for i := 0; i < n; i++ {
@@ -26,7 +27,9 @@ func (h *H) WaitForPodPhase(pod *kubev1.Pod, target kubev1.PodPhase, n int, dur
 		}
 
 		log.Printf("Waiting for Pod '%s/%s' to be %s, currently %s...", pod.Namespace, pod.Name, target, phase)
-		time.Sleep(dur)
+		if !h.sleep(dur) {
+			break
+		}
 	}
 
 	Expect(phase).NotTo(BeEmpty())
@@ -0,0 +1,121 @@
+package helper
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	kubev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+
+	"github.com/openshift/osde2e/pkg/privilege"
+	"github.com/openshift/osde2e/pkg/tenant"
+)
+
+// tokenTimeout is how long to wait for the token of the ServiceAccount specs are restricted to.
+const tokenTimeout = time.Minute
+
+// Requires declares the permissions specs of the suite need outside of their project. When testing with least
+// privilege, they're all specs are granted beyond administering their project.
+func (h *H) Requires(rules ...rbacv1.PolicyRule) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.rules = append(h.rules, rules...)
+}
+
+// restrict makes the clients of h act as a ServiceAccount which only administers the spec's project and has the
+// permissions declared with Requires, recording requests RBAC denies. Cleanup acts as the cluster's admin again.
+func (h *H) restrict(suite, spec string) error {
+	ns := h.CurrentProject()
+	projRef := h.projectRef()
+	if _, err := h.Kube().CoreV1().ServiceAccounts(ns).Create(&kubev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: privilege.ServiceAccount},
+	}); err != nil {
+		return fmt.Errorf("couldn't create ServiceAccount: %v", err)
+	}
+	subjects := []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: privilege.ServiceAccount, Namespace: ns}}
+
+	if _, err := h.Kube().RbacV1().RoleBindings(ns).Create(&rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: privilege.ServiceAccount},
+		Subjects:   subjects,
+		RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: privilege.ProjectRole},
+	}); err != nil {
+		return fmt.Errorf("couldn't grant ServiceAccount its project: %v", err)
+	}
+
+	h.mu.Lock()
+	rules := h.rules
+	h.mu.Unlock()
+	if len(rules) != 0 {
+		// cluster-wide permissions are removed along with the project
+		meta := metav1.ObjectMeta{
+			GenerateName:    tenant.Current.Prefix() + "restricted-",
+			Labels:          tenant.Current.Labels(),
+			OwnerReferences: []metav1.OwnerReference{projRef},
+		}
+		role, err := h.Kube().RbacV1().ClusterRoles().Create(&rbacv1.ClusterRole{ObjectMeta: meta, Rules: rules})
+		if err != nil {
+			return fmt.Errorf("couldn't create ClusterRole of declared permissions: %v", err)
+		}
+		if _, err = h.Kube().RbacV1().ClusterRoleBindings().Create(&rbacv1.ClusterRoleBinding{
+			ObjectMeta: meta,
+			Subjects:   subjects,
+			RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: role.Name},
+		}); err != nil {
+			return fmt.Errorf("couldn't grant declared permissions: %v", err)
+		}
+	}
+
+	token, err := h.ServiceAccountToken(ns, privilege.ServiceAccount)
+	if err != nil {
+		return err
+	}
+
+	restricted := rest.AnonymousClientConfig(h.restConfig)
+	restricted.BearerToken = token
+	restricted.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return privilege.Current.Transport(rt, suite, spec)
+	})
+	privilege.Current.Restricted(suite)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.adminConfig, h.restConfig, h.discovery = h.restConfig, restricted, nil
+	return nil
+}
+
+// unrestrict makes the clients of h act as the cluster's admin again.
+func (h *H) unrestrict() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.adminConfig != nil {
+		h.restConfig, h.adminConfig, h.discovery = h.adminConfig, nil, nil
+	}
+}
+
+// ServiceAccountToken waits for the token of the ServiceAccount name in ns to be created, then returns it.
+func (h *H) ServiceAccountToken(ns, name string) (token string, err error) {
+	err = h.PollImmediate(2*time.Second, tokenTimeout, func() (bool, error) {
+		sa, err := h.Kube().CoreV1().ServiceAccounts(ns).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		for _, ref := range sa.Secrets {
+			secret, err := h.Kube().CoreV1().Secrets(ns).Get(ref.Name, metav1.GetOptions{})
+			if err != nil {
+				return false, err
+			}
+			if secret.Type == kubev1.SecretTypeServiceAccountToken && len(secret.Data[kubev1.ServiceAccountTokenKey]) != 0 {
+				token = string(secret.Data[kubev1.ServiceAccountTokenKey])
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("couldn't get token of ServiceAccount '%s/%s': %v", ns, name, err)
+	}
+	return token, nil
+}
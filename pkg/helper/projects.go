@@ -2,27 +2,28 @@ package helper
 
 import (
 	"fmt"
-	"math/rand"
 
 	. "github.com/onsi/gomega"
 
 	projectv1 "github.com/openshift/api/project/v1"
+	kubev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/openshift/osde2e/pkg/tenant"
 )
 
 // GiveCurrentProjectClusterAdmin to default service account and ensure its removed after project deletion.
 func (h *H) GiveCurrentProjectClusterAdmin() {
 	// use OwnerReference of project to ensure deletion
-	Expect(h.proj).NotTo(BeNil())
-	gvk := schema.FromAPIVersionAndKind("project.openshift.io/v1", "Project")
-	projRef := *metav1.NewControllerRef(h.proj, gvk)
+	projRef := h.projectRef()
 
 	// create binding with OwnerReference
 	_, err := h.Kube().RbacV1().ClusterRoleBindings().Create(&rbacv1.ClusterRoleBinding{
 		ObjectMeta: metav1.ObjectMeta{
-			GenerateName: "osde2e-test-access-",
+			GenerateName: tenant.Current.Prefix() + "test-access-",
+			Labels:       tenant.Current.Labels(),
 			OwnerReferences: []metav1.OwnerReference{
 				projRef,
 			},
@@ -43,28 +44,85 @@ func (h *H) GiveCurrentProjectClusterAdmin() {
 	Expect(err).NotTo(HaveOccurred(), "couldn't set correct permissions for OpenShift E2E")
 }
 
+// projectRef returns a reference to the spec's project, which resources are owned by to be removed along with it.
+func (h *H) projectRef() metav1.OwnerReference {
+	Expect(h.proj).NotTo(BeNil())
+	gvk := schema.FromAPIVersionAndKind("project.openshift.io/v1", "Project")
+	if !h.projectsServed() {
+		gvk = kubev1.SchemeGroupVersion.WithKind("Namespace")
+	}
+	return *metav1.NewControllerRef(h.proj, gvk)
+}
+
+// createProject creates a Project for the spec, or a Namespace standing in for it on clusters without OpenShift. On
+// shared clusters it's named and labeled for the run's tenant and limited by its quota.
 func (h *H) createProject(suffix string) (*projectv1.Project, error) {
-	proj := &projectv1.Project{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: "osde2e-" + suffix,
-		},
+	meta := metav1.ObjectMeta{
+		Name: tenant.Current.Prefix() + suffix,
+	}
+
+	var proj *projectv1.Project
+	if !h.projectsServed() {
+		meta.Labels = tenant.Current.Labels()
+		ns, err := h.Kube().CoreV1().Namespaces().Create(&kubev1.Namespace{ObjectMeta: meta})
+		if err != nil {
+			return nil, err
+		}
+		proj = &projectv1.Project{ObjectMeta: ns.ObjectMeta}
+	} else {
+		var err error
+		if proj, err = h.Project().ProjectV1().Projects().Create(&projectv1.Project{ObjectMeta: meta}); err != nil {
+			return nil, err
+		}
+
+		// projects can't be created with labels, so they're added to the namespace of the project
+		if labels := tenant.Current.Labels(); len(labels) != 0 {
+			if err = h.labelNamespace(proj.Name, labels); err != nil {
+				return nil, err
+			}
+		}
 	}
-	return h.Project().ProjectV1().Projects().Create(proj)
+
+	if err := tenant.Current.Limit(proj.Name); err != nil {
+		return nil, err
+	}
+	return proj, nil
+}
+
+// labelNamespace adds labels to the namespace name.
+func (h *H) labelNamespace(name string, labels map[string]string) error {
+	ns, err := h.Kube().CoreV1().Namespaces().Get(name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("couldn't get namespace '%s': %v", name, err)
+	}
+	if ns.Labels == nil {
+		ns.Labels = map[string]string{}
+	}
+	for k, v := range labels {
+		ns.Labels[k] = v
+	}
+	if _, err = h.Kube().CoreV1().Namespaces().Update(ns); err != nil {
+		return fmt.Errorf("couldn't label namespace '%s': %v", name, err)
+	}
+	return nil
 }
 
 func (h *H) cleanup(projectName string) error {
-	err := h.Project().ProjectV1().Projects().Delete(projectName, &metav1.DeleteOptions{})
+	var err error
+	if h.projectsServed() {
+		err = h.Project().ProjectV1().Projects().Delete(projectName, &metav1.DeleteOptions{})
+	} else {
+		err = h.Kube().CoreV1().Namespaces().Delete(projectName, &metav1.DeleteOptions{})
+	}
 	if err != nil {
 		return fmt.Errorf("failed to cleanup project '%s': %v", projectName, err)
 	}
 	return nil
 }
 
-func randomStr(length int) (str string) {
-	chars := "0123456789abcdefghijklmnopqrstuvwxyz"
-	for i := 0; i < length; i++ {
-		c := string(chars[rand.Intn(len(chars))])
-		str += c
-	}
-	return
+// projectsServed returns true if the cluster serves the Project API, which clusters without OpenShift such as kind
+// don't.
+func (h *H) projectsServed() bool {
+	_, err := h.Discovery().ServerResourcesForGroupVersion(projectv1.GroupVersion.String())
+	return err == nil
 }
@@ -0,0 +1,30 @@
+package helper
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/openshift/osde2e/pkg/metrics"
+)
+
+const (
+	// location of the cluster's Prometheus, which is queried from inside its pod
+	promNamespace = "openshift-monitoring"
+	promPod       = "prometheus-k8s-0"
+	promContainer = "prometheus"
+	promQueryURL  = "http://localhost:9090/api/v1/query"
+)
+
+// QueryPrometheus evaluates query with the cluster's Prometheus at time at, or at the latest time if at is zero.
+func (h *H) QueryPrometheus(query string, at time.Time) ([]metrics.Sample, error) {
+	cmd := []string{"curl", "-s", "--data-urlencode", "query=" + query}
+	if !at.IsZero() {
+		cmd = append(cmd, "--data-urlencode", fmt.Sprintf("time=%d", at.Unix()))
+	}
+
+	result, err := h.Exec(promNamespace, promPod, promContainer, append(cmd, promQueryURL)...)
+	if err != nil {
+		return nil, err
+	}
+	return metrics.ParseQueryResponse(result.Stdout)
+}
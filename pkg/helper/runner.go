@@ -1,12 +1,11 @@
 package helper
 
 import (
-	"io/ioutil"
-	"os"
-	"path/filepath"
+	"time"
 
 	. "github.com/onsi/gomega"
 
+	"github.com/openshift/osde2e/pkg/artifacts"
 	"github.com/openshift/osde2e/pkg/runner"
 )
 
@@ -22,14 +21,34 @@ func (h *H) Runner(cmd string) *runner.Runner {
 	// setup tests
 	r.Namespace = h.CurrentProject()
 	r.Cmd = cmd
+
+	// recreate Pods failing because of the cluster rather than the tests
+	r.MaxRetries = h.HarnessRetries
+	r.RetryBackoff = h.HarnessRetryBackoff
+
+	// harnesses finish before the spec times out
+	if deadline, ok := h.Context().Deadline(); ok {
+		r.Timeout = time.Until(deadline)
+	}
 	return r
 }
 
 // WriteResults dumps runner results into the ReportDir.
 func (h *H) WriteResults(results map[string][]byte) {
-	for filename, data := range results {
-		dst := filepath.Join(h.ReportDir, filename)
-		err := ioutil.WriteFile(dst, data, os.ModePerm)
+	h.WriteArtifacts(artifacts.Results, results)
+}
+
+// WriteArtifacts stores data of category into the ReportDir, within the artifact budgets of the run.
+func (h *H) WriteArtifacts(category string, data map[string][]byte) {
+	store := artifacts.Current
+	if store == nil {
+		var err error
+		store, err = artifacts.New(h.ReportDir, nil)
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	for name, d := range data {
+		err := store.Write(category, name, d)
 		Expect(err).NotTo(HaveOccurred())
 	}
 }
@@ -0,0 +1,153 @@
+// Package hosted checks clusters with hosted control planes. Their control planes run on a management cluster, where
+// they're described by a HostedCluster, rather than on the cluster's own nodes, so their health is checked through the
+// management cluster and results are tagged so hosted and classic clusters can be compared.
+package hosted
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+
+	configclient "github.com/openshift/client-go/config/clientset/versioned"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/openshift/osde2e/pkg/config"
+	"github.com/openshift/osde2e/pkg/health"
+	"github.com/openshift/osde2e/pkg/osd"
+)
+
+const (
+	// ClusterIDLabel identifies the OSD cluster a HostedCluster is the control plane of.
+	ClusterIDLabel = "api.openshift.com/id"
+
+	// Hosted is the kind of control plane run on a management cluster.
+	Hosted = "hosted"
+
+	// Classic is the kind of control plane run on the cluster's own master nodes.
+	Classic = "classic"
+
+	availableCondition = "Available"
+	degradedCondition  = "Degraded"
+	conditionTrue      = "True"
+)
+
+// HostedClusters are the control planes hosted on a management cluster.
+var HostedClusters = schema.GroupVersionResource{
+	Group:    "hypershift.openshift.io",
+	Version:  "v1beta1",
+	Resource: "hostedclusters",
+}
+
+// ControlPlaneOperators are ClusterOperators run by hosted control planes, whose health is reported by the
+// HostedCluster rather than the cluster.
+var ControlPlaneOperators = []string{
+	"etcd",
+	"kube-apiserver",
+	"kube-controller-manager",
+	"kube-scheduler",
+	"openshift-apiserver",
+	"openshift-controller-manager",
+}
+
+// ControlPlane returns the kind of control plane tested with cfg.
+func ControlPlane(cfg *config.Config) string {
+	if cfg.Hypershift {
+		return Hosted
+	}
+	return Classic
+}
+
+// LoadKubeconfig replaces the path in cfg.ManagementKubeconfig with the kubeconfig it names, or looks up the
+// kubeconfig of the management cluster hosting the control plane of cfg.ClusterID through OSD if it isn't set. OSD
+// may be nil if the cluster isn't managed by OSD.
+func LoadKubeconfig(cfg *config.Config, OSD *osd.OSD) (err error) {
+	if len(cfg.ManagementKubeconfig) != 0 {
+		filename := string(cfg.ManagementKubeconfig)
+		if cfg.ManagementKubeconfig, err = ioutil.ReadFile(filename); err != nil {
+			return fmt.Errorf("failed reading '%s' which has been set as the MANAGEMENT_KUBECONFIG: %v", filename, err)
+		}
+		log.Printf("Using a set MANAGEMENT_KUBECONFIG of '%s' for the hosted control plane.", filename)
+		return nil
+	} else if OSD == nil {
+		return fmt.Errorf("MANAGEMENT_KUBECONFIG must be set for clusters which aren't managed by OSD")
+	}
+
+	if cfg.ManagementKubeconfig, err = OSD.ManagementKubeconfig(cfg.ClusterID); err != nil {
+		return fmt.Errorf("couldn't get kubeconfig of management cluster: %v", err)
+	}
+	return nil
+}
+
+// NewClient returns a client of the management cluster accessed with kubeconfig.
+func NewClient(kubeconfig []byte) (dynamic.Interface, error) {
+	restConfig, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't load management kubeconfig: %v", err)
+	}
+	return dynamic.NewForConfig(restConfig)
+}
+
+// Find returns the HostedCluster on the management cluster which is the control plane of clusterID.
+func Find(management dynamic.Interface, clusterID string) (*unstructured.Unstructured, error) {
+	list, err := management.Resource(HostedClusters).Namespace(metav1.NamespaceAll).List(metav1.ListOptions{
+		LabelSelector: ClusterIDLabel + "=" + clusterID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("couldn't list HostedClusters: %v", err)
+	} else if len(list.Items) != 1 {
+		return nil, fmt.Errorf("found %d HostedClusters for cluster '%s'", len(list.Items), clusterID)
+	}
+	return &list.Items[0], nil
+}
+
+// Problems returns why the control plane described by hc isn't available or is degraded.
+func Problems(hc *unstructured.Unstructured) (problems []string) {
+	conditions, _, _ := unstructured.NestedSlice(hc.Object, "status", "conditions")
+	available := false
+	for _, c := range conditions {
+		cond, _ := c.(map[string]interface{})
+		condType, _, _ := unstructured.NestedString(cond, "type")
+		status, _, _ := unstructured.NestedString(cond, "status")
+		message, _, _ := unstructured.NestedString(cond, "message")
+		switch {
+		case condType == availableCondition:
+			available = status == conditionTrue
+		case condType == degradedCondition && status == conditionTrue:
+			problems = append(problems, fmt.Sprintf("degraded: %s", message))
+		}
+	}
+	if !available {
+		problems = append(problems, "not available")
+	}
+	return
+}
+
+// Checker returns a checker of the health of the cluster tested with cfg. The control planes of hosted clusters are
+// checked through their HostedCluster on the management cluster instead of the ClusterOperators they run.
+func Checker(cfg *config.Config, kube kubernetes.Interface, client configclient.Interface) (*health.Checker, error) {
+	checker := &health.Checker{Kube: kube, Config: client}
+	if !cfg.Hypershift {
+		return checker, nil
+	} else if len(cfg.ManagementKubeconfig) == 0 {
+		return nil, fmt.Errorf("MANAGEMENT_KUBECONFIG must be set to check hosted control planes")
+	}
+
+	management, err := NewClient(cfg.ManagementKubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	checker.ControlPlane = func() []string {
+		hc, err := Find(management, cfg.ClusterID)
+		if err != nil {
+			return []string{err.Error()}
+		}
+		return Problems(hc)
+	}
+	checker.IgnoredOperators = ControlPlaneOperators
+	return checker, nil
+}
@@ -0,0 +1,51 @@
+package hosted
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/openshift/osde2e/pkg/config"
+)
+
+func hostedCluster(conditions ...interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"status": map[string]interface{}{"conditions": conditions},
+	}}
+}
+
+func condition(condType, status, message string) map[string]interface{} {
+	return map[string]interface{}{"type": condType, "status": status, "message": message}
+}
+
+func TestProblems(t *testing.T) {
+	hc := hostedCluster(condition("Available", "True", ""), condition("Degraded", "False", ""))
+	if problems := Problems(hc); len(problems) != 0 {
+		t.Errorf("expected healthy control plane, got %v", problems)
+	}
+
+	hc = hostedCluster(condition("Available", "False", "etcd quorum lost"), condition("Degraded", "True", "etcd-1 crashing"))
+	expected := []string{"degraded: etcd-1 crashing", "not available"}
+	if problems := Problems(hc); !reflect.DeepEqual(problems, expected) {
+		t.Errorf("expected problems %v, got %v", expected, problems)
+	}
+
+	if problems := Problems(hostedCluster()); !reflect.DeepEqual(problems, []string{"not available"}) {
+		t.Errorf("expected control plane without conditions to be unavailable, got %v", problems)
+	}
+}
+
+func TestChecker(t *testing.T) {
+	checker, err := Checker(&config.Config{}, nil, nil)
+	if err != nil || checker.ControlPlane != nil || len(checker.IgnoredOperators) != 0 {
+		t.Errorf("expected classic clusters to be checked as before, got %+v: %v", checker, err)
+	}
+	if ControlPlane(&config.Config{}) != Classic || ControlPlane(&config.Config{Hypershift: true}) != Hosted {
+		t.Error("expected control plane to be hosted only for Hypershift clusters")
+	}
+
+	if _, err = Checker(&config.Config{Hypershift: true}, nil, nil); err == nil {
+		t.Error("expected hosted clusters without a management kubeconfig to fail")
+	}
+}
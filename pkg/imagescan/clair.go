@@ -0,0 +1,88 @@
+package imagescan
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// clairTimeout is how long Quay is given to return the scan of an image.
+const clairTimeout = time.Minute
+
+// Clair retrieves what Clair found scanning images from the Quay registry storing them. Images must be referred to by
+// digest, such as 'quay.io/openshift-release-dev/ocp-v4.0-art-dev@sha256:...'.
+type Clair struct {
+	// URL is the Quay registry, such as 'https://quay.io'.
+	URL string
+
+	// Client makes requests to Quay. A client timing out after clairTimeout is used if it's nil.
+	Client *http.Client
+}
+
+// clairSecurity is the response of Quay's manifest security API.
+type clairSecurity struct {
+	Status string `json:"status"`
+	Data   struct {
+		Layer struct {
+			Features []struct {
+				Name            string `json:"Name"`
+				Version         string `json:"Version"`
+				Vulnerabilities []struct {
+					Name     string `json:"Name"`
+					Severity string `json:"Severity"`
+					FixedBy  string `json:"FixedBy"`
+				} `json:"Vulnerabilities"`
+			} `json:"Features"`
+		} `json:"Layer"`
+	} `json:"data"`
+}
+
+// Scan retrieves the vulnerabilities Clair found in image.
+func (c *Clair) Scan(image string) ([]Vulnerability, error) {
+	parts := strings.SplitN(image, "@", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("image must be referred to by digest to be scanned by Clair")
+	}
+	repo := parts[0]
+	if i := strings.Index(repo, "/"); i >= 0 {
+		repo = repo[i+1:]
+	}
+
+	client := c.Client
+	if client == nil {
+		client = &http.Client{Timeout: clairTimeout}
+	}
+	url := fmt.Sprintf("%s/api/v1/repository/%s/manifest/%s/security?vulnerabilities=true",
+		strings.TrimSuffix(c.URL, "/"), repo, parts[1])
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get scan from Quay: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("couldn't get scan from Quay: %s", resp.Status)
+	}
+
+	var security clairSecurity
+	if err = json.NewDecoder(resp.Body).Decode(&security); err != nil {
+		return nil, fmt.Errorf("couldn't decode scan from Quay: %v", err)
+	} else if security.Status != "scanned" {
+		return nil, fmt.Errorf("image hasn't been scanned by Clair, its status is '%s'", security.Status)
+	}
+
+	var vulns []Vulnerability
+	for _, f := range security.Data.Layer.Features {
+		for _, v := range f.Vulnerabilities {
+			vulns = append(vulns, Vulnerability{
+				ID:       v.Name,
+				Package:  f.Name,
+				Version:  f.Version,
+				Severity: ParseSeverity(v.Severity),
+				FixedIn:  v.FixedBy,
+			})
+		}
+	}
+	return vulns, nil
+}
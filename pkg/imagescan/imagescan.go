@@ -0,0 +1,265 @@
+// Package imagescan scans the images run by managed components for vulnerabilities, reporting critical ones as an
+// informing JUnit suite and the count of each severity as metrics.
+package imagescan
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+
+	kubev1 "k8s.io/api/core/v1"
+	"k8s.io/test-infra/testgrid/metadata/junit"
+
+	"github.com/openshift/osde2e/pkg/junitprops"
+)
+
+const (
+	// SuiteName is the JUnit suite containing results of scanning images.
+	SuiteName = "Image vulnerabilities"
+
+	// MetricName is the Prometheus metric counting vulnerabilities of each component by severity.
+	MetricName = "osde2e_image_vulnerabilities"
+
+	// imageIDPrefix begins the IDs of images in the status of containers, before their digest.
+	imageIDPrefix = "docker-pullable://"
+)
+
+// Severity is how serious a vulnerability is.
+type Severity string
+
+// Severities of vulnerabilities reported by scanners.
+const (
+	Critical Severity = "Critical"
+	High     Severity = "High"
+	Medium   Severity = "Medium"
+	Low      Severity = "Low"
+	Unknown  Severity = "Unknown"
+)
+
+// Severities are all severities, most serious first.
+var Severities = []Severity{Critical, High, Medium, Low, Unknown}
+
+// ParseSeverity returns the severity named s regardless of case, which is Unknown if it isn't recognized.
+func ParseSeverity(s string) Severity {
+	for _, severity := range Severities {
+		if strings.EqualFold(s, string(severity)) {
+			return severity
+		}
+	}
+	return Unknown
+}
+
+// Vulnerability is a known problem in a package of an image.
+type Vulnerability struct {
+	ID       string
+	Package  string
+	Version  string
+	Severity Severity
+
+	// FixedIn is the version of the package fixing the vulnerability. It's empty if there's no fix.
+	FixedIn string
+}
+
+func (v Vulnerability) String() string {
+	desc := fmt.Sprintf("%s in %s %s", v.ID, v.Package, v.Version)
+	if v.FixedIn != "" {
+		desc += fmt.Sprintf(", fixed in %s", v.FixedIn)
+	}
+	return desc
+}
+
+// Scanner finds the vulnerabilities of images.
+type Scanner interface {
+	Scan(image string) ([]Vulnerability, error)
+}
+
+// Scanners which can be selected by name.
+const (
+	ScannerTrivy = "trivy"
+	ScannerClair = "clair"
+)
+
+// New returns the scanner named name. Clair results are retrieved from the Quay registry at clairURL.
+func New(name, clairURL string) (Scanner, error) {
+	switch name {
+	case ScannerTrivy:
+		return &Trivy{Path: ScannerTrivy}, nil
+	case ScannerClair:
+		return &Clair{URL: clairURL}, nil
+	}
+	return nil, fmt.Errorf("unknown image scanner '%s', must be %s or %s", name, ScannerTrivy, ScannerClair)
+}
+
+// Images returns the images run by pods in namespaces matching any of namespaces, by namespace. Images are referred
+// to by digest when containers report the image they run.
+func Images(pods []kubev1.Pod, namespaces []*regexp.Regexp) map[string][]string {
+	seen := map[string]map[string]bool{}
+	for _, pod := range pods {
+		if !matchAny(namespaces, pod.Namespace) {
+			continue
+		}
+		if seen[pod.Namespace] == nil {
+			seen[pod.Namespace] = map[string]bool{}
+		}
+
+		statuses := append(append([]kubev1.ContainerStatus{}, pod.Status.InitContainerStatuses...),
+			pod.Status.ContainerStatuses...)
+		for _, status := range statuses {
+			image := status.Image
+			if strings.HasPrefix(status.ImageID, imageIDPrefix) {
+				image = strings.TrimPrefix(status.ImageID, imageIDPrefix)
+			}
+			seen[pod.Namespace][image] = true
+		}
+		if len(statuses) == 0 {
+			for _, c := range append(append([]kubev1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...) {
+				seen[pod.Namespace][c.Image] = true
+			}
+		}
+	}
+
+	images := make(map[string][]string, len(seen))
+	for ns, set := range seen {
+		for image := range set {
+			images[ns] = append(images[ns], image)
+		}
+		sort.Strings(images[ns])
+	}
+	return images
+}
+
+func matchAny(res []*regexp.Regexp, s string) bool {
+	for _, re := range res {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// ImageResult is what scanning an image found.
+type ImageResult struct {
+	Image           string
+	Vulnerabilities []Vulnerability
+
+	// Err is set when the image couldn't be scanned.
+	Err error
+}
+
+// Component is a managed component and what scanning its images found.
+type Component struct {
+	// Name is the namespace the component runs in.
+	Name string
+
+	Images []ImageResult
+}
+
+// Counts returns how many vulnerabilities of each severity the images of c have.
+func (c Component) Counts() map[Severity]int {
+	counts := make(map[Severity]int, len(Severities))
+	for _, severity := range Severities {
+		counts[severity] = 0
+	}
+	for _, img := range c.Images {
+		for _, v := range img.Vulnerabilities {
+			counts[v.Severity]++
+		}
+	}
+	return counts
+}
+
+// Critical returns the critical vulnerabilities of each image of c, one per line.
+func (c Component) Critical() (lines []string) {
+	for _, img := range c.Images {
+		for _, v := range img.Vulnerabilities {
+			if v.Severity == Critical {
+				lines = append(lines, fmt.Sprintf("%s: %s", img.Image, v))
+			}
+		}
+	}
+	return
+}
+
+// Scan scans the images of each component with s. Images shared by components are only scanned once.
+func Scan(s Scanner, images map[string][]string) []Component {
+	scanned := map[string]ImageResult{}
+	var components []Component
+	for name, refs := range images {
+		c := Component{Name: name}
+		for _, image := range refs {
+			res, ok := scanned[image]
+			if !ok {
+				res.Image = image
+				if res.Vulnerabilities, res.Err = s.Scan(image); res.Err != nil {
+					log.Printf("Failed to scan image '%s': %v", image, res.Err)
+				}
+				scanned[image] = res
+			}
+			c.Images = append(c.Images, res)
+		}
+		components = append(components, c)
+	}
+
+	sort.Slice(components, func(i, j int) bool { return components[i].Name < components[j].Name })
+	return components
+}
+
+// WriteJUnit records a testcase for each component in dir, failing those with critical vulnerabilities. Components
+// whose images couldn't be scanned are skipped.
+func WriteJUnit(dir, suffix string, components []Component) error {
+	suite := junit.Suite{
+		Name:  SuiteName,
+		Tests: len(components),
+	}
+	for _, c := range components {
+		result := junit.Result{
+			Name:      fmt.Sprintf("[image-scan] images of %s should not have critical vulnerabilities", c.Name),
+			ClassName: SuiteName,
+		}
+
+		var errs []string
+		for _, img := range c.Images {
+			if img.Err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %v", img.Image, img.Err))
+			}
+		}
+
+		if critical := c.Critical(); len(critical) != 0 {
+			msg := fmt.Sprintf("%d critical vulnerabilities found in images of %s", len(critical), c.Name)
+			output := strings.Join(critical, "\n")
+			result.Failure, result.Output = &msg, &output
+			suite.Failures++
+		} else if len(errs) == len(c.Images) && len(errs) != 0 {
+			msg := fmt.Sprintf("none of the %d images of %s could be scanned", len(c.Images), c.Name)
+			result.Skipped = &msg
+		}
+		if len(errs) != 0 {
+			scanErrs := strings.Join(errs, "\n")
+			result.Error = &scanErrs
+		}
+		suite.Results = append(suite.Results, result)
+	}
+
+	return junitprops.WriteSuite(dir, "image_scan", suffix, suite)
+}
+
+// WriteMetrics writes the count of vulnerabilities of each component by severity to w in the Prometheus text format.
+func WriteMetrics(w io.Writer, components []Component) error {
+	if _, err := fmt.Fprintf(w, "# HELP %s Vulnerabilities in images of managed components.\n# TYPE %s gauge\n",
+		MetricName, MetricName); err != nil {
+		return err
+	}
+	for _, c := range components {
+		counts := c.Counts()
+		for _, severity := range Severities {
+			if _, err := fmt.Fprintf(w, "%s{component=%q,severity=%q} %d\n", MetricName, c.Name,
+				strings.ToLower(string(severity)), counts[severity]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,222 @@
+package imagescan
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	kubev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/test-infra/testgrid/metadata/junit"
+)
+
+func TestImages(t *testing.T) {
+	pod := func(ns, image, imageID string) kubev1.Pod {
+		return kubev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: ns},
+			Spec:       kubev1.PodSpec{Containers: []kubev1.Container{{Image: image}}},
+			Status: kubev1.PodStatus{ContainerStatuses: []kubev1.ContainerStatus{
+				{Image: image, ImageID: imageID},
+			}},
+		}
+	}
+	pending := pod("openshift-monitoring", "quay.io/openshift/prometheus:v4.3", "")
+	pending.Status.ContainerStatuses = nil
+
+	pods := []kubev1.Pod{
+		pod("openshift-dns", "quay.io/openshift/dns:v4.3", "docker-pullable://quay.io/openshift/dns@sha256:1"),
+		pod("openshift-dns", "quay.io/openshift/dns:v4.3", "docker-pullable://quay.io/openshift/dns@sha256:1"),
+		pod("openshift-dns", "quay.io/openshift/proxy:v4.3", "docker://abc"),
+		pending,
+		pod("default", "docker.io/library/nginx:latest", ""),
+	}
+
+	images := Images(pods, []*regexp.Regexp{regexp.MustCompile("^openshift-")})
+	expected := map[string][]string{
+		"openshift-dns":        {"quay.io/openshift/dns@sha256:1", "quay.io/openshift/proxy:v4.3"},
+		"openshift-monitoring": {"quay.io/openshift/prometheus:v4.3"},
+	}
+	if !reflect.DeepEqual(images, expected) {
+		t.Errorf("expected images %v, got %v", expected, images)
+	}
+}
+
+type fakeScanner struct {
+	vulns   map[string][]Vulnerability
+	scanned map[string]int
+}
+
+func (s *fakeScanner) Scan(image string) ([]Vulnerability, error) {
+	s.scanned[image]++
+	vulns, ok := s.vulns[image]
+	if !ok {
+		return nil, errors.New("image not found")
+	}
+	return vulns, nil
+}
+
+func testComponents() ([]Component, *fakeScanner) {
+	s := &fakeScanner{
+		vulns: map[string][]Vulnerability{
+			"base": {
+				{ID: "CVE-1", Package: "openssl", Version: "1.0", Severity: Critical, FixedIn: "1.1"},
+				{ID: "CVE-2", Package: "bash", Version: "4.0", Severity: Low},
+			},
+			"operator": {{ID: "CVE-3", Package: "curl", Version: "7.0", Severity: High}},
+		},
+		scanned: map[string]int{},
+	}
+	return Scan(s, map[string][]string{
+		"openshift-b": {"base", "operator"},
+		"openshift-a": {"base"},
+		"openshift-c": {"missing"},
+	}), s
+}
+
+func TestScan(t *testing.T) {
+	components, s := testComponents()
+
+	var names []string
+	for _, c := range components {
+		names = append(names, c.Name)
+	}
+	if expected := []string{"openshift-a", "openshift-b", "openshift-c"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected components %v, got %v", expected, names)
+	}
+	if s.scanned["base"] != 1 {
+		t.Errorf("expected image shared by components to be scanned once, was scanned %d times", s.scanned["base"])
+	}
+
+	counts := components[1].Counts()
+	expected := map[Severity]int{Critical: 1, High: 1, Medium: 0, Low: 1, Unknown: 0}
+	if !reflect.DeepEqual(counts, expected) {
+		t.Errorf("expected counts %v, got %v", expected, counts)
+	}
+	if critical := components[0].Critical(); len(critical) != 1 || !strings.Contains(critical[0], "fixed in 1.1") {
+		t.Errorf("expected one critical vulnerability with its fix, got %v", critical)
+	}
+	if components[2].Images[0].Err == nil {
+		t.Errorf("expected error scanning missing image")
+	}
+}
+
+func TestParseTrivy(t *testing.T) {
+	results := `[{"Target": "quay.io/openshift/dns (rhel 8.1)", "Vulnerabilities": [
+		{"VulnerabilityID": "CVE-1", "PkgName": "openssl", "InstalledVersion": "1.0", "FixedVersion": "1.1",
+		 "Severity": "CRITICAL"},
+		{"VulnerabilityID": "CVE-2", "PkgName": "bash", "InstalledVersion": "4.0", "Severity": "whatever"}
+	]}, {"Target": "usr/bin/dns", "Vulnerabilities": null}]`
+	expected := []Vulnerability{
+		{ID: "CVE-1", Package: "openssl", Version: "1.0", Severity: Critical, FixedIn: "1.1"},
+		{ID: "CVE-2", Package: "bash", Version: "4.0", Severity: Unknown},
+	}
+
+	for _, report := range []string{results, fmt.Sprintf(`{"SchemaVersion": 2, "Results": %s}`, results)} {
+		vulns, err := ParseTrivy([]byte(report))
+		if err != nil {
+			t.Fatalf("failed to parse report: %v", err)
+		}
+		if !reflect.DeepEqual(vulns, expected) {
+			t.Errorf("expected vulnerabilities %v, got %v", expected, vulns)
+		}
+	}
+
+	if _, err := ParseTrivy([]byte("not json")); err == nil {
+		t.Errorf("expected error parsing invalid report")
+	}
+}
+
+func TestClair(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/repository/openshift/dns/manifest/sha256:1/security":
+			fmt.Fprint(w, `{"status": "scanned", "data": {"Layer": {"Features": [
+				{"Name": "openssl", "Version": "1.0", "Vulnerabilities": [
+					{"Name": "CVE-1", "Severity": "Critical", "FixedBy": "1.1"}
+				]},
+				{"Name": "bash", "Version": "4.0"}
+			]}}}`)
+		case "/api/v1/repository/openshift/dns/manifest/sha256:2/security":
+			fmt.Fprint(w, `{"status": "queued", "data": null}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	c := &Clair{URL: srv.URL + "/", Client: srv.Client()}
+	vulns, err := c.Scan("quay.io/openshift/dns@sha256:1")
+	if err != nil {
+		t.Fatalf("failed to scan image: %v", err)
+	}
+	expected := []Vulnerability{{ID: "CVE-1", Package: "openssl", Version: "1.0", Severity: Critical, FixedIn: "1.1"}}
+	if !reflect.DeepEqual(vulns, expected) {
+		t.Errorf("expected vulnerabilities %v, got %v", expected, vulns)
+	}
+
+	for _, image := range []string{
+		"quay.io/openshift/dns@sha256:2",
+		"quay.io/openshift/dns@sha256:3",
+		"quay.io/openshift/dns:v4.3",
+	} {
+		if _, err = c.Scan(image); err == nil {
+			t.Errorf("expected error scanning '%s'", image)
+		}
+	}
+}
+
+func TestWriteJUnit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "imagescan")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	components, _ := testComponents()
+	if err = WriteJUnit(dir, "test", components); err != nil {
+		t.Fatalf("failed to write results: %v", err)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "junit_image_scan_test.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var suite junit.Suite
+	if err = xml.Unmarshal(data, &suite); err != nil {
+		t.Fatal(err)
+	}
+	if suite.Name != SuiteName || suite.Tests != 3 || suite.Failures != 2 {
+		t.Errorf("expected 2 of 3 components to fail, got %d of %d", suite.Failures, suite.Tests)
+	}
+	if suite.Results[2].Skipped == nil || suite.Results[2].Error == nil {
+		t.Errorf("expected component whose images couldn't be scanned to be skipped")
+	}
+}
+
+func TestWriteMetrics(t *testing.T) {
+	components, _ := testComponents()
+
+	var buf bytes.Buffer
+	if err := WriteMetrics(&buf, components[:1]); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		`osde2e_image_vulnerabilities{component="openshift-a",severity="critical"} 1`,
+		`osde2e_image_vulnerabilities{component="openshift-a",severity="high"} 0`,
+		`osde2e_image_vulnerabilities{component="openshift-a",severity="low"} 1`,
+	} {
+		if !strings.Contains(buf.String(), line+"\n") {
+			t.Errorf("expected metrics to contain '%s', got:\n%s", line, buf.String())
+		}
+	}
+}
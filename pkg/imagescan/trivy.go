@@ -0,0 +1,71 @@
+package imagescan
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Trivy scans images by running the trivy CLI, which pulls them itself.
+type Trivy struct {
+	// Path is the trivy binary, looked up in PATH if it isn't absolute.
+	Path string
+}
+
+// trivyResult is the vulnerabilities trivy found in a target of an image, such as its OS packages.
+type trivyResult struct {
+	Target          string `json:"Target"`
+	Vulnerabilities []struct {
+		VulnerabilityID  string `json:"VulnerabilityID"`
+		PkgName          string `json:"PkgName"`
+		InstalledVersion string `json:"InstalledVersion"`
+		FixedVersion     string `json:"FixedVersion"`
+		Severity         string `json:"Severity"`
+	} `json:"Vulnerabilities"`
+}
+
+// Scan runs trivy on image.
+func (t *Trivy) Scan(image string) ([]Vulnerability, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(t.Path, "image", "--quiet", "--format", "json", image)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("trivy failed: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return ParseTrivy(stdout.Bytes())
+}
+
+// ParseTrivy reads the vulnerabilities of a JSON trivy report. Reports listing results alone, from older versions of
+// trivy, and those with results in a report object are both read.
+func ParseTrivy(data []byte) ([]Vulnerability, error) {
+	var results []trivyResult
+	if trimmed := bytes.TrimSpace(data); len(trimmed) != 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &results); err != nil {
+			return nil, fmt.Errorf("couldn't decode trivy report: %v", err)
+		}
+	} else {
+		var report struct {
+			Results []trivyResult `json:"Results"`
+		}
+		if err := json.Unmarshal(trimmed, &report); err != nil {
+			return nil, fmt.Errorf("couldn't decode trivy report: %v", err)
+		}
+		results = report.Results
+	}
+
+	var vulns []Vulnerability
+	for _, r := range results {
+		for _, v := range r.Vulnerabilities {
+			vulns = append(vulns, Vulnerability{
+				ID:       v.VulnerabilityID,
+				Package:  v.PkgName,
+				Version:  v.InstalledVersion,
+				Severity: ParseSeverity(v.Severity),
+				FixedIn:  v.FixedVersion,
+			})
+		}
+	}
+	return vulns, nil
+}
@@ -0,0 +1,211 @@
+package infra
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/elb"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+)
+
+// clusterTagPrefix is followed by the infrastructure ID in the tag of AWS resources owned by a cluster.
+const clusterTagPrefix = "kubernetes.io/cluster/"
+
+// AWS reads the infrastructure of clusters in an AWS region. Credentials are found from the AWS_ACCESS_KEY_ID and
+// AWS_SECRET_ACCESS_KEY environment variables or the shared credentials file.
+type AWS struct {
+	ec2   *ec2.EC2
+	elb   *elb.ELB
+	elbv2 *elbv2.ELBV2
+}
+
+// NewAWS returns a Provider for clusters in region.
+func NewAWS(region string) (*AWS, error) {
+	sess, err := session.NewSession(&aws.Config{Region: aws.String(region)})
+	if err != nil {
+		return nil, fmt.Errorf("couldn't create AWS session: %v", err)
+	}
+	return &AWS{
+		ec2:   ec2.New(sess),
+		elb:   elb.New(sess),
+		elbv2: elbv2.New(sess),
+	}, nil
+}
+
+// Inventory returns the instances and security groups tagged as owned by infraID, the network load balancers named
+// after it, and the classic load balancers serving lbHosts.
+func (a *AWS) Inventory(infraID string, lbHosts []string) (*Inventory, error) {
+	owned := []*ec2.Filter{{
+		Name:   aws.String("tag:" + clusterTagPrefix + infraID),
+		Values: aws.StringSlice([]string{"owned"}),
+	}}
+	inv := new(Inventory)
+
+	err := a.ec2.DescribeInstancesPages(&ec2.DescribeInstancesInput{Filters: owned},
+		func(page *ec2.DescribeInstancesOutput, last bool) bool {
+			for _, r := range page.Reservations {
+				for _, i := range r.Instances {
+					inv.Instances = append(inv.Instances, Instance{
+						ID:      aws.StringValue(i.InstanceId),
+						Name:    awsName(i.Tags),
+						Zone:    aws.StringValue(i.Placement.AvailabilityZone),
+						Running: i.State != nil && aws.StringValue(i.State.Name) == ec2.InstanceStateNameRunning,
+					})
+				}
+			}
+			return true
+		})
+	if err != nil {
+		return nil, fmt.Errorf("couldn't describe instances: %v", err)
+	}
+
+	groups, err := a.ec2.DescribeSecurityGroups(&ec2.DescribeSecurityGroupsInput{Filters: owned})
+	if err != nil {
+		return nil, fmt.Errorf("couldn't describe security groups: %v", err)
+	}
+	for _, g := range groups.SecurityGroups {
+		for _, p := range g.IpPermissions {
+			rule := Rule{
+				Name:     aws.StringValue(g.GroupName),
+				Protocol: aws.StringValue(p.IpProtocol),
+			}
+			if rule.Protocol == "-1" {
+				rule.Protocol = ProtocolAll
+			} else if rule.Protocol == "tcp" || rule.Protocol == "udp" {
+				rule.Ports = ports(aws.Int64Value(p.FromPort), aws.Int64Value(p.ToPort))
+			}
+			for _, r := range p.IpRanges {
+				rule.Sources = append(rule.Sources, aws.StringValue(r.CidrIp))
+			}
+			for _, pair := range p.UserIdGroupPairs {
+				rule.Sources = append(rule.Sources, aws.StringValue(pair.GroupId))
+			}
+			inv.Rules = append(inv.Rules, rule)
+		}
+	}
+
+	if err = a.networkLoadBalancers(infraID, inv); err != nil {
+		return nil, err
+	}
+	if err = a.classicLoadBalancers(lbHosts, inv); err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+// networkLoadBalancers adds the network load balancers named after infraID, which serve the API, to inv.
+func (a *AWS) networkLoadBalancers(infraID string, inv *Inventory) error {
+	var lbs []*elbv2.LoadBalancer
+	err := a.elbv2.DescribeLoadBalancersPages(&elbv2.DescribeLoadBalancersInput{},
+		func(page *elbv2.DescribeLoadBalancersOutput, last bool) bool {
+			for _, lb := range page.LoadBalancers {
+				if strings.HasPrefix(aws.StringValue(lb.LoadBalancerName), infraID) {
+					lbs = append(lbs, lb)
+				}
+			}
+			return true
+		})
+	if err != nil {
+		return fmt.Errorf("couldn't describe network load balancers: %v", err)
+	}
+
+	for _, lb := range lbs {
+		balancer := LoadBalancer{Name: aws.StringValue(lb.LoadBalancerName), Tags: map[string]string{}}
+		tags, err := a.elbv2.DescribeTags(&elbv2.DescribeTagsInput{ResourceArns: []*string{lb.LoadBalancerArn}})
+		if err != nil {
+			return fmt.Errorf("couldn't describe tags of load balancer '%s': %v", balancer.Name, err)
+		}
+		for _, d := range tags.TagDescriptions {
+			for _, t := range d.Tags {
+				balancer.Tags[aws.StringValue(t.Key)] = aws.StringValue(t.Value)
+			}
+		}
+
+		groups, err := a.elbv2.DescribeTargetGroups(&elbv2.DescribeTargetGroupsInput{LoadBalancerArn: lb.LoadBalancerArn})
+		if err != nil {
+			return fmt.Errorf("couldn't describe target groups of load balancer '%s': %v", balancer.Name, err)
+		}
+		for _, g := range groups.TargetGroups {
+			health, err := a.elbv2.DescribeTargetHealth(&elbv2.DescribeTargetHealthInput{TargetGroupArn: g.TargetGroupArn})
+			if err != nil {
+				return fmt.Errorf("couldn't describe target health of load balancer '%s': %v", balancer.Name, err)
+			}
+			for _, t := range health.TargetHealthDescriptions {
+				if t.TargetHealth != nil && aws.StringValue(t.TargetHealth.State) == elbv2.TargetHealthStateEnumHealthy {
+					balancer.Healthy++
+				} else {
+					balancer.Unhealthy++
+				}
+			}
+		}
+		inv.LoadBalancers = append(inv.LoadBalancers, balancer)
+	}
+	return nil
+}
+
+// classicLoadBalancers adds the classic load balancers with the DNS names of lbHosts, which serve routers, to inv.
+func (a *AWS) classicLoadBalancers(lbHosts []string, inv *Inventory) error {
+	var names []*string
+	err := a.elb.DescribeLoadBalancersPages(&elb.DescribeLoadBalancersInput{},
+		func(page *elb.DescribeLoadBalancersOutput, last bool) bool {
+			for _, lb := range page.LoadBalancerDescriptions {
+				if contains(lbHosts, aws.StringValue(lb.DNSName)) {
+					names = append(names, lb.LoadBalancerName)
+				}
+			}
+			return true
+		})
+	if err != nil {
+		return fmt.Errorf("couldn't describe classic load balancers: %v", err)
+	}
+
+	for _, name := range names {
+		balancer := LoadBalancer{Name: aws.StringValue(name), Tags: map[string]string{}}
+		tags, err := a.elb.DescribeTags(&elb.DescribeTagsInput{LoadBalancerNames: []*string{name}})
+		if err != nil {
+			return fmt.Errorf("couldn't describe tags of load balancer '%s': %v", balancer.Name, err)
+		}
+		for _, d := range tags.TagDescriptions {
+			for _, t := range d.Tags {
+				balancer.Tags[aws.StringValue(t.Key)] = aws.StringValue(t.Value)
+			}
+		}
+
+		health, err := a.elb.DescribeInstanceHealth(&elb.DescribeInstanceHealthInput{LoadBalancerName: name})
+		if err != nil {
+			return fmt.Errorf("couldn't describe instance health of load balancer '%s': %v", balancer.Name, err)
+		}
+		for _, s := range health.InstanceStates {
+			if aws.StringValue(s.State) == "InService" {
+				balancer.Healthy++
+			} else {
+				balancer.Unhealthy++
+			}
+		}
+		inv.LoadBalancers = append(inv.LoadBalancers, balancer)
+	}
+	return nil
+}
+
+// awsName returns the Name tag of an AWS resource.
+func awsName(tags []*ec2.Tag) string {
+	for _, t := range tags {
+		if aws.StringValue(t.Key) == "Name" {
+			return aws.StringValue(t.Value)
+		}
+	}
+	return ""
+}
+
+// ports describes the range from-to as in Rule, which is empty if it's every port.
+func ports(from, to int64) string {
+	if from <= 0 && (to <= 0 || to == 65535) {
+		return ""
+	} else if from == to {
+		return fmt.Sprintf("%d", from)
+	}
+	return fmt.Sprintf("%d-%d", from, to)
+}
@@ -0,0 +1,131 @@
+package infra
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"path"
+	"strings"
+
+	compute "google.golang.org/api/compute/v1"
+	"google.golang.org/api/option"
+)
+
+// GCP reads the infrastructure of clusters in a GCP project.
+type GCP struct {
+	project string
+	svc     *compute.Service
+}
+
+// NewGCP returns a Provider for clusters in project, authenticating with a Base64 encoded service account.
+func NewGCP(project string, b64ServiceAccount []byte) (*GCP, error) {
+	serviceAccount, err := base64.StdEncoding.DecodeString(string(b64ServiceAccount))
+	if err != nil {
+		return nil, fmt.Errorf("couldn't base64 decode service account JSON: %v", err)
+	}
+	svc, err := compute.NewService(context.Background(), option.WithCredentialsJSON(serviceAccount))
+	if err != nil {
+		return nil, fmt.Errorf("couldn't create GCP compute client: %v", err)
+	}
+	return &GCP{project: project, svc: svc}, nil
+}
+
+// Inventory returns the instances and target pools named after infraID, the target pools of forwarding rules with the
+// addresses of lbHosts, and the ingress firewall rules of the cluster's network.
+func (g *GCP) Inventory(infraID string, lbHosts []string) (*Inventory, error) {
+	ctx := context.Background()
+	named := fmt.Sprintf("name eq %s-.*", infraID)
+	inv := new(Inventory)
+
+	err := g.svc.Instances.AggregatedList(g.project).Filter(named).Pages(ctx, func(page *compute.InstanceAggregatedList) error {
+		for _, scoped := range page.Items {
+			for _, i := range scoped.Instances {
+				inv.Instances = append(inv.Instances, Instance{
+					ID:      fmt.Sprint(i.Id),
+					Name:    i.Name,
+					Zone:    path.Base(i.Zone),
+					Running: i.Status == "RUNNING",
+				})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("couldn't list instances: %v", err)
+	}
+
+	// routers are served by target pools of forwarding rules at their addresses
+	pools := map[string]bool{}
+	err = g.svc.ForwardingRules.AggregatedList(g.project).Pages(ctx, func(page *compute.ForwardingRuleAggregatedList) error {
+		for _, scoped := range page.Items {
+			for _, r := range scoped.ForwardingRules {
+				if contains(lbHosts, r.IPAddress) && strings.Contains(r.Target, "/targetPools/") {
+					pools[path.Base(r.Target)] = true
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("couldn't list forwarding rules: %v", err)
+	}
+
+	err = g.svc.TargetPools.AggregatedList(g.project).Pages(ctx, func(page *compute.TargetPoolAggregatedList) error {
+		for _, scoped := range page.Items {
+			for _, p := range scoped.TargetPools {
+				if !strings.HasPrefix(p.Name, infraID+"-") && !pools[p.Name] {
+					continue
+				}
+
+				lb := LoadBalancer{Name: p.Name}
+				for _, instance := range p.Instances {
+					health, err := g.svc.TargetPools.GetHealth(g.project, path.Base(p.Region), p.Name,
+						&compute.InstanceReference{Instance: instance}).Context(ctx).Do()
+					if err != nil {
+						return fmt.Errorf("couldn't get health of target pool '%s': %v", p.Name, err)
+					}
+					healthy := false
+					for _, s := range health.HealthStatus {
+						healthy = healthy || s.HealthState == "HEALTHY"
+					}
+					if healthy {
+						lb.Healthy++
+					} else {
+						lb.Unhealthy++
+					}
+				}
+				inv.LoadBalancers = append(inv.LoadBalancers, lb)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("couldn't list target pools: %v", err)
+	}
+
+	network := fmt.Sprintf("network eq .*/%s-network", infraID)
+	err = g.svc.Firewalls.List(g.project).Filter(network).Pages(ctx, func(page *compute.FirewallList) error {
+		for _, f := range page.Items {
+			if f.Direction != "INGRESS" {
+				continue
+			}
+			for _, allowed := range f.Allowed {
+				protocol := allowed.IPProtocol
+				if protocol == "all" {
+					protocol = ProtocolAll
+				}
+				if len(allowed.Ports) == 0 {
+					inv.Rules = append(inv.Rules, Rule{Name: f.Name, Protocol: protocol, Sources: f.SourceRanges})
+				}
+				for _, p := range allowed.Ports {
+					inv.Rules = append(inv.Rules, Rule{Name: f.Name, Protocol: protocol, Ports: p, Sources: f.SourceRanges})
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("couldn't list firewalls: %v", err)
+	}
+	return inv, nil
+}
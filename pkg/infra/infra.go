@@ -0,0 +1,293 @@
+// Package infra verifies the cloud infrastructure of clusters through the cloud's APIs, such as the instances in each
+// availability zone, the health and tags of load balancers, and firewall rules, catching drift Kubernetes can't see.
+package infra
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	kubev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/osde2e/pkg/topology"
+)
+
+const (
+	// ProtocolAll is the protocol of rules allowing every protocol.
+	ProtocolAll = "all"
+
+	// anywhere is the source of rules open to the internet.
+	anywhere = "0.0.0.0/0"
+)
+
+// Inventory is the cloud infrastructure of a cluster.
+type Inventory struct {
+	Instances     []Instance
+	LoadBalancers []LoadBalancer
+	Rules         []Rule
+}
+
+// Instance is a virtual machine of the cluster.
+type Instance struct {
+	ID, Name, Zone string
+
+	// Running is true if the instance is running rather than stopped or being created.
+	Running bool
+}
+
+// LoadBalancer balances traffic to the cluster, such as to its API or routers.
+type LoadBalancer struct {
+	Name string
+	Tags map[string]string
+
+	// Healthy and Unhealthy count the instances the load balancer sends traffic to by their health.
+	Healthy, Unhealthy int
+}
+
+// Rule is an ingress rule of a security group or firewall of the cluster.
+type Rule struct {
+	// Name of the security group or firewall of the rule.
+	Name string `json:"name,omitempty"`
+
+	// Protocol is 'tcp', 'udp', 'icmp', or 'all'.
+	Protocol string `json:"protocol"`
+
+	// Ports are a port or range of ports such as '30000-32767'. All ports are allowed if it's empty.
+	Ports string `json:"ports,omitempty"`
+
+	// Sources are the CIDRs traffic is allowed from.
+	Sources []string `json:"sources,omitempty"`
+}
+
+func (r Rule) String() string {
+	ports := r.Ports
+	if ports == "" {
+		ports = "all ports"
+	}
+	return fmt.Sprintf("%s %s from %s", r.Protocol, ports, strings.Join(r.Sources, ","))
+}
+
+// Provider reads the infrastructure of clusters from a cloud.
+type Provider interface {
+	// Inventory returns the infrastructure of the cluster with infraID. Load balancers are found by infraID or by the
+	// hostnames and addresses of lbHosts.
+	Inventory(infraID string, lbHosts []string) (*Inventory, error)
+}
+
+// Baseline is the infrastructure expected of clusters on a cloud.
+type Baseline struct {
+	// Cloud the baseline applies to, such as 'aws'.
+	Cloud string `json:"cloud"`
+
+	// Tags are required on every load balancer. '${INFRA_ID}' in keys and values is replaced by the cluster's.
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// Rules must be allowed by the cluster's rules.
+	Rules []Rule `json:"rules"`
+
+	// PublicPorts may be open to the internet. Any other rule allowing traffic from anywhere is a problem.
+	PublicPorts []string `json:"public_ports,omitempty"`
+}
+
+// LoadBaseline reads a YAML baseline from file.
+func LoadBaseline(file string) (*Baseline, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read infrastructure baseline '%s': %v", file, err)
+	}
+
+	b := new(Baseline)
+	if err = yaml.Unmarshal(data, b); err != nil {
+		return nil, fmt.Errorf("couldn't decode infrastructure baseline '%s': %v", file, err)
+	}
+	for _, r := range b.Rules {
+		if r.Protocol == "" {
+			return nil, fmt.Errorf("rules of infrastructure baseline '%s' must have a protocol", file)
+		} else if _, _, err = portRange(r.Ports); err != nil {
+			return nil, fmt.Errorf("rule %s of infrastructure baseline '%s' is invalid: %v", r, file, err)
+		}
+	}
+	return b, nil
+}
+
+// InstanceProblems describes zones where the running instances don't match the nodes of the cluster, such as
+// instances which never joined or were left behind.
+func InstanceProblems(inv *Inventory, nodes []kubev1.Node) (problems []string) {
+	expected, actual := map[string]int{}, map[string]int{}
+	for _, n := range nodes {
+		expected[n.Labels[topology.ZoneLabel]]++
+	}
+	for _, i := range inv.Instances {
+		if i.Running {
+			actual[i.Zone]++
+		}
+	}
+
+	for _, zone := range zones(expected, actual) {
+		if expected[zone] != actual[zone] {
+			problems = append(problems, fmt.Sprintf("zone '%s' has %d running instances, expected %d for its nodes",
+				zone, actual[zone], expected[zone]))
+		}
+	}
+	return
+}
+
+// LoadBalancerProblems describes load balancers without the tags of b, or without healthy instances.
+func LoadBalancerProblems(inv *Inventory, b *Baseline, infraID string) (problems []string) {
+	if len(inv.LoadBalancers) == 0 {
+		return []string{"no load balancers were found"}
+	}
+
+	expand := func(s string) string {
+		return os.Expand(s, func(key string) string {
+			if key == "INFRA_ID" {
+				return infraID
+			}
+			return "$" + key
+		})
+	}
+	for _, lb := range inv.LoadBalancers {
+		for k, v := range b.Tags {
+			if actual, ok := lb.Tags[expand(k)]; !ok || actual != expand(v) {
+				problems = append(problems, fmt.Sprintf("load balancer '%s' isn't tagged %s=%s", lb.Name, expand(k), expand(v)))
+			}
+		}
+		if lb.Healthy == 0 {
+			problems = append(problems, fmt.Sprintf("load balancer '%s' has no healthy instances of %d", lb.Name,
+				lb.Unhealthy))
+		}
+	}
+	sort.Strings(problems)
+	return
+}
+
+// RuleProblems describes rules of b which aren't allowed, and rules open to the internet on ports which aren't public.
+func RuleProblems(inv *Inventory, b *Baseline) (problems []string) {
+	for _, expected := range b.Rules {
+		allowed := false
+		for _, r := range inv.Rules {
+			if allows(r, expected) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			problems = append(problems, fmt.Sprintf("no rule allows %s", expected))
+		}
+	}
+
+	for _, r := range inv.Rules {
+		// ICMP from anywhere, such as for path MTU discovery, is expected
+		if !contains(r.Sources, anywhere) || r.Protocol == "icmp" {
+			continue
+		}
+		public := false
+		for _, port := range b.PublicPorts {
+			if samePorts(r.Ports, port) {
+				public = true
+			}
+		}
+		if !public {
+			problems = append(problems, fmt.Sprintf("'%s' allows %s, which isn't a public port", r.Name, r))
+		}
+	}
+	sort.Strings(problems)
+	return
+}
+
+// allows returns true if rule r allows all traffic expected allows.
+func allows(r, expected Rule) bool {
+	if r.Protocol != ProtocolAll && r.Protocol != expected.Protocol {
+		return false
+	}
+
+	from, to, err := portRange(r.Ports)
+	if err != nil {
+		return false
+	}
+	expectedFrom, expectedTo, _ := portRange(expected.Ports)
+	if expectedFrom < from || expectedTo > to {
+		return false
+	}
+
+	for _, src := range expected.Sources {
+		covered := false
+		for _, allowed := range r.Sources {
+			if cidrContains(allowed, src) {
+				covered = true
+			}
+		}
+		if !covered {
+			return false
+		}
+	}
+	return true
+}
+
+// portRange returns the first and last ports of ports, which are all ports if it's empty.
+func portRange(ports string) (from, to int, err error) {
+	if ports == "" {
+		return 0, 65535, nil
+	}
+	parts := strings.SplitN(ports, "-", 2)
+	if from, err = strconv.Atoi(parts[0]); err != nil {
+		return 0, 0, fmt.Errorf("invalid ports '%s'", ports)
+	}
+	to = from
+	if len(parts) == 2 {
+		if to, err = strconv.Atoi(parts[1]); err != nil || to < from {
+			return 0, 0, fmt.Errorf("invalid ports '%s'", ports)
+		}
+	}
+	return
+}
+
+// samePorts returns true if a and b are the same ports.
+func samePorts(a, b string) bool {
+	aFrom, aTo, aErr := portRange(a)
+	bFrom, bTo, bErr := portRange(b)
+	return aErr == nil && bErr == nil && aFrom == bFrom && aTo == bTo
+}
+
+// cidrContains returns true if network contains all addresses of cidr.
+func cidrContains(network, cidr string) bool {
+	_, outer, err := net.ParseCIDR(network)
+	if err != nil {
+		return false
+	}
+	_, inner, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return false
+	}
+	outerSize, _ := outer.Mask.Size()
+	innerSize, _ := inner.Mask.Size()
+	return outer.Contains(inner.IP) && outerSize <= innerSize
+}
+
+// zones returns the zones of expected and actual in order.
+func zones(expected, actual map[string]int) (names []string) {
+	for zone := range expected {
+		names = append(names, zone)
+	}
+	for zone := range actual {
+		if _, ok := expected[zone]; !ok {
+			names = append(names, zone)
+		}
+	}
+	sort.Strings(names)
+	return
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,132 @@
+package infra
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	kubev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openshift/osde2e/pkg/topology"
+)
+
+func TestLoadBaselines(t *testing.T) {
+	files, err := filepath.Glob("../../cloudbaselines/*.yaml")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("expected baselines, got %v: %v", files, err)
+	}
+	for _, file := range files {
+		b, err := LoadBaseline(file)
+		if err != nil {
+			t.Errorf("failed to load '%s': %v", file, err)
+		} else if len(b.Rules) == 0 {
+			t.Errorf("expected '%s' to have rules", file)
+		}
+	}
+}
+
+func TestInstanceProblems(t *testing.T) {
+	node := func(zone string) kubev1.Node {
+		return kubev1.Node{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{topology.ZoneLabel: zone}}}
+	}
+	nodes := []kubev1.Node{node("us-east-1a"), node("us-east-1a"), node("us-east-1b")}
+	inv := &Inventory{Instances: []Instance{
+		{ID: "i-1", Zone: "us-east-1a", Running: true},
+		{ID: "i-2", Zone: "us-east-1a", Running: true},
+		{ID: "i-3", Zone: "us-east-1b", Running: true},
+		{ID: "i-4", Zone: "us-east-1b"},
+	}}
+	if problems := InstanceProblems(inv, nodes); len(problems) != 0 {
+		t.Errorf("expected no problems, got %v", problems)
+	}
+
+	inv.Instances = append(inv.Instances, Instance{ID: "i-5", Zone: "us-east-1c", Running: true})
+	expected := []string{"zone 'us-east-1c' has 1 running instances, expected 0 for its nodes"}
+	if problems := InstanceProblems(inv, nodes); !reflect.DeepEqual(problems, expected) {
+		t.Errorf("expected %v, got %v", expected, problems)
+	}
+}
+
+func TestLoadBalancerProblems(t *testing.T) {
+	b := &Baseline{Tags: map[string]string{"kubernetes.io/cluster/${INFRA_ID}": "owned"}}
+	inv := &Inventory{LoadBalancers: []LoadBalancer{
+		{Name: "abc-ext", Tags: map[string]string{"kubernetes.io/cluster/abc": "owned"}, Healthy: 3},
+		{Name: "router", Tags: map[string]string{}, Unhealthy: 2},
+	}}
+
+	expected := []string{
+		"load balancer 'router' has no healthy instances of 2",
+		"load balancer 'router' isn't tagged kubernetes.io/cluster/abc=owned",
+	}
+	if problems := LoadBalancerProblems(inv, b, "abc"); !reflect.DeepEqual(problems, expected) {
+		t.Errorf("expected %v, got %v", expected, problems)
+	}
+
+	if problems := LoadBalancerProblems(&Inventory{}, b, "abc"); len(problems) != 1 {
+		t.Errorf("expected a problem without load balancers, got %v", problems)
+	}
+}
+
+func TestRuleProblems(t *testing.T) {
+	b := &Baseline{
+		Rules: []Rule{
+			{Protocol: "tcp", Ports: "6443", Sources: []string{"10.0.0.0/16"}},
+			{Protocol: "tcp", Ports: "443", Sources: []string{anywhere}},
+		},
+		PublicPorts: []string{"443"},
+	}
+	inv := &Inventory{Rules: []Rule{
+		{Name: "master", Protocol: ProtocolAll, Sources: []string{"10.0.0.0/8"}},
+		{Name: "router", Protocol: "tcp", Ports: "443", Sources: []string{anywhere}},
+		{Name: "router", Protocol: "icmp", Sources: []string{anywhere}},
+	}}
+	if problems := RuleProblems(inv, b); len(problems) != 0 {
+		t.Errorf("expected no problems, got %v", problems)
+	}
+
+	inv.Rules = append(inv.Rules, Rule{Name: "debug", Protocol: "tcp", Ports: "22", Sources: []string{anywhere}})
+	inv.Rules[0].Sources = []string{"10.0.1.0/24"}
+	expected := []string{
+		"'debug' allows tcp 22 from 0.0.0.0/0, which isn't a public port",
+		"no rule allows tcp 6443 from 10.0.0.0/16",
+	}
+	if problems := RuleProblems(inv, b); !reflect.DeepEqual(problems, expected) {
+		t.Errorf("expected %v, got %v", expected, problems)
+	}
+}
+
+func TestAllows(t *testing.T) {
+	expected := Rule{Protocol: "tcp", Ports: "30000-30100", Sources: []string{"10.0.0.0/16"}}
+	for _, test := range []struct {
+		rule    Rule
+		allowed bool
+	}{
+		{Rule{Protocol: "tcp", Ports: "30000-32767", Sources: []string{"10.0.0.0/16"}}, true},
+		{Rule{Protocol: ProtocolAll, Sources: []string{anywhere}}, true},
+		{Rule{Protocol: "udp", Ports: "30000-32767", Sources: []string{"10.0.0.0/16"}}, false},
+		{Rule{Protocol: "tcp", Ports: "30050-32767", Sources: []string{"10.0.0.0/16"}}, false},
+		{Rule{Protocol: "tcp", Ports: "30000-32767", Sources: []string{"10.0.0.0/24"}}, false},
+		{Rule{Protocol: "tcp", Ports: "30000-32767", Sources: []string{"sg-123"}}, false},
+	} {
+		if allowed := allows(test.rule, expected); allowed != test.allowed {
+			t.Errorf("expected allows(%s) to be %t", test.rule, test.allowed)
+		}
+	}
+}
+
+func TestPorts(t *testing.T) {
+	for _, test := range []struct {
+		from, to int64
+		expected string
+	}{
+		{0, 65535, ""},
+		{-1, -1, ""},
+		{443, 443, "443"},
+		{30000, 32767, "30000-32767"},
+	} {
+		if p := ports(test.from, test.to); p != test.expected {
+			t.Errorf("expected ports(%d, %d) to be '%s', got '%s'", test.from, test.to, test.expected, p)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+// Package junitprops adds properties describing the run, such as the cluster and versions tested, to every testcase
+// in JUnit results so they can be grouped without joining against metadata. It also writes the suites osde2e records
+// itself, so they're named and encoded the same way.
+package junitprops
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"k8s.io/test-infra/testgrid/metadata/junit"
+)
+
+// Property keys describing the run.
+const (
+	ClusterID      = "cluster-id"
+	InstallVersion = "install-version"
+	UpgradeVersion = "upgrade-version"
+	Cloud          = "cloud"
+	Region         = "region"
+	MultiAZ        = "multi-az"
+	Architecture   = "architecture"
+	NetworkType    = "network-type"
+	ControlPlane   = "control-plane"
+)
+
+// node is any XML element, so results written by any tool can be annotated without losing content.
+type node struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr `xml:",any,attr"`
+	Content  string     `xml:",chardata"`
+	Children []node     `xml:",any"`
+}
+
+// AnnotateDir adds props to every testcase in the JUnit files found in dir. Empty properties are skipped.
+func AnnotateDir(dir string, props map[string]string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "junit*.xml"))
+	if err != nil {
+		return fmt.Errorf("couldn't find JUnit files in '%s': %v", dir, err)
+	}
+	for _, file := range files {
+		if err = AnnotateFile(file, props); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AnnotateFile adds props to every testcase in the JUnit file. Properties already set on a testcase are kept.
+func AnnotateFile(file string, props map[string]string) error {
+	return annotateFile(file, func(string) map[string]string {
+		return props
+	})
+}
+
+// AnnotateTests adds props to testcases in the JUnit file by their name. Properties already set on a testcase are
+// kept.
+func AnnotateTests(file string, props map[string]map[string]string) error {
+	return annotateFile(file, func(name string) map[string]string {
+		return props[name]
+	})
+}
+
+// annotateFile adds the properties returned by propsFor the name of each testcase in the JUnit file.
+func annotateFile(file string, propsFor func(name string) map[string]string) error {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("couldn't read JUnit '%s': %v", file, err)
+	}
+
+	var root node
+	if err = xml.Unmarshal(data, &root); err != nil {
+		return fmt.Errorf("couldn't decode JUnit '%s': %v", file, err)
+	}
+	annotate(&root, propsFor)
+
+	if data, err = xml.MarshalIndent(root, "", "  "); err != nil {
+		return fmt.Errorf("couldn't encode JUnit '%s': %v", file, err)
+	}
+	return ioutil.WriteFile(file, append([]byte(xml.Header), data...), os.ModePerm)
+}
+
+func annotate(n *node, propsFor func(name string) map[string]string) {
+	// indentation is regenerated when encoding
+	if len(n.Children) != 0 && strings.TrimSpace(n.Content) == "" {
+		n.Content = ""
+	}
+
+	if n.XMLName.Local != "testcase" {
+		for i := range n.Children {
+			annotate(&n.Children[i], propsFor)
+		}
+		return
+	}
+
+	var props map[string]string
+	for _, attr := range n.Attrs {
+		if attr.Name.Local == "name" {
+			props = propsFor(attr.Value)
+		}
+	}
+	if len(props) == 0 {
+		return
+	}
+
+	// properties must come first in a testcase
+	if len(n.Children) == 0 || n.Children[0].XMLName.Local != "properties" {
+		n.Children = append([]node{{XMLName: xml.Name{Local: "properties"}}}, n.Children...)
+	}
+	properties := &n.Children[0]
+	properties.Content = ""
+
+	existing := map[string]bool{}
+	for _, p := range properties.Children {
+		for _, attr := range p.Attrs {
+			if attr.Name.Local == "name" {
+				existing[attr.Value] = true
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(props))
+	for k := range props {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if existing[k] || props[k] == "" {
+			continue
+		}
+		properties.Children = append(properties.Children, node{
+			XMLName: xml.Name{Local: "property"},
+			Attrs: []xml.Attr{
+				{Name: xml.Name{Local: "name"}, Value: k},
+				{Name: xml.Name{Local: "value"}, Value: props[k]},
+			},
+		})
+	}
+
+	// don't leave an empty properties element if nothing was added
+	if len(properties.Children) == 0 {
+		n.Children = n.Children[1:]
+	}
+}
+
+// WriteSuite stores suite in dir as 'junit_<name>_<suffix>.xml', where it's collected with the other results of the
+// run.
+func WriteSuite(dir, name, suffix string, suite junit.Suite) error {
+	filename := filepath.Join(dir, fmt.Sprintf("junit_%s_%s.xml", name, suffix))
+	data, err := xml.MarshalIndent(suite, "", "  ")
+	if err != nil {
+		return fmt.Errorf("couldn't encode JUnit '%s': %v", filename, err)
+	}
+
+	os.MkdirAll(dir, os.ModePerm)
+	if err = ioutil.WriteFile(filename, data, os.ModePerm); err != nil {
+		return fmt.Errorf("couldn't write JUnit '%s': %v", filename, err)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package junitprops
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s.io/test-infra/testgrid/metadata/junit"
+)
+
+const ginkgoJUnit = `<?xml version="1.0" encoding="UTF-8"?>
+<testsuite name="OSD e2e suite" tests="2" failures="1" errors="0" time="12.5">
+  <testcase name="[Suite: e2e] Pods should be Running" classname="OSD e2e suite" time="10">
+    <failure type="Failure">pods weren&#39;t running</failure>
+    <system-out>waiting &lt;1m</system-out>
+  </testcase>
+  <testcase name="[Suite: e2e] Routes should be admitted" classname="OSD e2e suite" time="2.5">
+    <properties>
+      <property name="quarantined" value="true"></property>
+      <property name="region" value="eu-west-1"></property>
+    </properties>
+    <skipped message="quarantined by OSD-1234"></skipped>
+  </testcase>
+</testsuite>`
+
+func TestAnnotateDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "junitprops")
+	if err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "junit_abc.xml")
+	if err = ioutil.WriteFile(file, []byte(ginkgoJUnit), 0644); err != nil {
+		t.Fatalf("failed to write JUnit: %v", err)
+	}
+
+	props := map[string]string{
+		ClusterID:      "1a2b3c",
+		InstallVersion: "openshift-v4.2.0",
+		UpgradeVersion: "",
+		Region:         "us-east-1",
+	}
+	if err = AnnotateDir(dir, props); err != nil {
+		t.Fatalf("failed to annotate: %v", err)
+	}
+
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatalf("failed to read JUnit: %v", err)
+	}
+	suites, err := junit.Parse(data)
+	if err != nil {
+		t.Fatalf("annotated JUnit is invalid: %v\n%s", err, data)
+	}
+
+	results := suites.Suites[0].Results
+	if len(results) != 2 || results[0].Failure == nil || *results[0].Failure != "pods weren't running" {
+		t.Fatalf("expected results to be kept: %s", data)
+	}
+	for _, r := range results {
+		props := map[string]string{}
+		for _, p := range r.Properties.PropertyList {
+			props[p.Name] = p.Value
+		}
+		if props[ClusterID] != "1a2b3c" || props[InstallVersion] != "openshift-v4.2.0" {
+			t.Errorf("expected '%s' to have run properties, got %v", r.Name, props)
+		}
+		if _, ok := props[UpgradeVersion]; ok {
+			t.Errorf("expected empty properties to be skipped, got %v", props)
+		}
+	}
+
+	// existing properties and attributes are kept
+	if out := string(data); strings.Count(out, `name="region"`) != 2 || !strings.Contains(out, `value="eu-west-1"`) ||
+		!strings.Contains(out, `<failure type="Failure">`) || !strings.Contains(out, `<skipped message="quarantined by OSD-1234">`) {
+		t.Errorf("expected existing content to be kept: %s", out)
+	}
+}
+
+func TestAnnotateTests(t *testing.T) {
+	dir, err := ioutil.TempDir("", "junitprops")
+	if err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "junit_abc.xml")
+	if err = ioutil.WriteFile(file, []byte(ginkgoJUnit), 0644); err != nil {
+		t.Fatalf("failed to write JUnit: %v", err)
+	}
+
+	props := map[string]map[string]string{
+		"[Suite: e2e] Routes should be admitted": {"skip-reason": "quarantined"},
+	}
+	if err = AnnotateTests(file, props); err != nil {
+		t.Fatalf("failed to annotate: %v", err)
+	}
+
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatalf("failed to read JUnit: %v", err)
+	}
+	suites, err := junit.Parse(data)
+	if err != nil {
+		t.Fatalf("annotated JUnit is invalid: %v\n%s", err, data)
+	}
+
+	results := suites.Suites[0].Results
+	if results[0].Properties != nil {
+		t.Errorf("expected '%s' not to be annotated, got %v", results[0].Name, results[0].Properties)
+	}
+	if props := results[1].Properties; props == nil || len(props.PropertyList) != 3 || props.PropertyList[2].Value != "quarantined" {
+		t.Errorf("expected '%s' to be annotated, got %v", results[1].Name, props)
+	}
+}
+
+func TestWriteSuite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "junitprops")
+	if err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	msg := "broken"
+	suite := junit.Suite{Name: "osde2e checks", Tests: 1, Failures: 1}
+	suite.Results = append(suite.Results, junit.Result{Name: "check", Failure: &msg})
+	if err = WriteSuite(filepath.Join(dir, "report"), "checks", "abc", suite); err != nil {
+		t.Fatalf("failed writing suite: %v", err)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "report", "junit_checks_abc.xml"))
+	if err != nil {
+		t.Fatalf("failed to read JUnit: %v", err)
+	}
+	suites, err := junit.Parse(data)
+	if err != nil {
+		t.Fatalf("failed to parse JUnit: %v", err)
+	}
+	if len(suites.Suites) != 1 || len(suites.Suites[0].Results) != 1 || *suites.Suites[0].Results[0].Failure != msg {
+		t.Errorf("expected suite to be written, got %+v", suites)
+	}
+}
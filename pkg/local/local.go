@@ -0,0 +1,160 @@
+// Package local tests a cluster running on the developer's machine, either CodeReady Containers or kind with the
+// OpenShift route API installed, so suites can be iterated on in minutes instead of creating OSD clusters. Clusters
+// aren't created or managed by OSD, so specs which need it are skipped.
+package local
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/openshift/osde2e/pkg/config"
+	"github.com/openshift/osde2e/pkg/generic"
+	"github.com/openshift/osde2e/pkg/health"
+)
+
+// Kinds of local clusters.
+const (
+	// FlavorCRC is a CodeReady Containers cluster, which runs OpenShift.
+	FlavorCRC = "crc"
+
+	// FlavorKind is a kind cluster, which runs Kubernetes without OpenShift's operators.
+	FlavorKind = "kind"
+)
+
+// crcKubeconfig is where CRC writes the kubeconfig of its cluster, relative to the home directory.
+var crcKubeconfig = filepath.Join(".crc", "machines", "crc", "kubeconfig")
+
+// Provider tests a cluster running locally.
+type Provider struct {
+	// Flavor is the kind of local cluster.
+	Flavor string
+
+	kubeconfig []byte
+	kube       kubernetes.Interface
+
+	// crc is used for CRC clusters, which are tested like any other OpenShift cluster.
+	crc *generic.Provider
+}
+
+// New returns a provider for the local cluster accessed with the kubeconfig at path. If path is empty the
+// kubeconfig of CRC is used when it exists, otherwise the current context of KUBECONFIG such as one set by kind.
+func New(path string) (*Provider, error) {
+	if path == "" {
+		path = KubeconfigPath()
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read kubeconfig '%s': %v", path, err)
+	}
+
+	restConfig, err := clientcmd.RESTConfigFromKubeConfig(data)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't load kubeconfig '%s': %v", path, err)
+	}
+
+	kube, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't configure Kubernetes client: %v", err)
+	}
+
+	p := &Provider{
+		Flavor:     FlavorKind,
+		kubeconfig: data,
+		kube:       kube,
+	}
+
+	// only OpenShift serves ClusterVersions
+	if _, err = kube.Discovery().ServerResourcesForGroupVersion(configv1.GroupVersion.String()); err == nil {
+		p.Flavor = FlavorCRC
+		if p.crc, err = generic.New(path); err != nil {
+			return nil, err
+		}
+	}
+
+	log.Printf("Using local %s cluster with kubeconfig '%s'.", p.Flavor, path)
+	return p, nil
+}
+
+// KubeconfigPath returns the kubeconfig of CRC if it exists, otherwise the first in KUBECONFIG or the default.
+func KubeconfigPath() string {
+	home := os.Getenv("HOME")
+	if crc := filepath.Join(home, crcKubeconfig); fileExists(crc) {
+		return crc
+	}
+
+	if env := os.Getenv(clientcmd.RecommendedConfigPathEnvVar); env != "" {
+		return filepath.SplitList(env)[0]
+	}
+	return filepath.Join(home, clientcmd.RecommendedHomeDir, clientcmd.RecommendedFileName)
+}
+
+// Cluster returns the ID and version of the cluster. kind clusters are identified by their kube-system Namespace
+// and versioned by Kubernetes.
+func (p *Provider) Cluster() (id, version string, err error) {
+	if p.crc != nil {
+		return p.crc.Cluster()
+	}
+
+	ns, err := p.kube.CoreV1().Namespaces().Get(metav1.NamespaceSystem, metav1.GetOptions{})
+	if err != nil {
+		return "", "", fmt.Errorf("couldn't get Namespace '%s': %v", metav1.NamespaceSystem, err)
+	}
+
+	info, err := p.kube.Discovery().ServerVersion()
+	if err != nil {
+		return "", "", fmt.Errorf("couldn't get Kubernetes version: %v", err)
+	}
+	return string(ns.UID), strings.TrimPrefix(info.GitVersion, "v"), nil
+}
+
+// LaunchCluster returns an error as local clusters are started by the developer.
+func (p *Provider) LaunchCluster(cfg *config.Config) (string, error) {
+	return "", errors.New("the local provider can't create clusters, start one with 'crc start' or 'kind create cluster'")
+}
+
+// WaitForClusterReady blocks until the cluster is healthy or timeout, checking every interval. CRC clusters are
+// healthy when every ClusterOperator is, and kind clusters when every node is ready.
+func (p *Provider) WaitForClusterReady(clusterID string, timeout, interval time.Duration) error {
+	if p.crc != nil {
+		return p.crc.WaitForClusterReady(clusterID, timeout, interval)
+	}
+
+	log.Printf("Waiting %v for nodes of cluster '%s' to be ready...", timeout, clusterID)
+	checker := &health.Checker{Kube: p.kube}
+	_, err := checker.Wait(context.Background(), interval, timeout)
+	return err
+}
+
+// ClusterKubeconfig returns the kubeconfig the provider was created with.
+func (p *Provider) ClusterKubeconfig(clusterID string) ([]byte, error) {
+	return p.kubeconfig, nil
+}
+
+// FullLogs returns no logs, as the cluster doesn't keep any outside of itself.
+func (p *Provider) FullLogs(clusterID string, ids ...string) (map[string][]byte, error) {
+	return map[string][]byte{}, nil
+}
+
+// DeleteCluster leaves the cluster running so it can be reused by the next run.
+func (p *Provider) DeleteCluster(clusterID string) error {
+	log.Printf("The local provider doesn't delete clusters, leaving '%s' running.", clusterID)
+	return nil
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
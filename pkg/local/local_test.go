@@ -0,0 +1,101 @@
+package local
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	kubev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestKindProvider(t *testing.T) {
+	ns := &kubev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: metav1.NamespaceSystem, UID: "0f1e2d3c"},
+	}
+	node := &kubev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "kind-control-plane"},
+		Status: kubev1.NodeStatus{
+			Conditions: []kubev1.NodeCondition{
+				{Type: kubev1.NodeReady, Status: kubev1.ConditionFalse},
+			},
+		},
+	}
+	kube := fake.NewSimpleClientset(ns, node)
+	kube.Discovery().(*fakediscovery.FakeDiscovery).FakedServerVersion = &version.Info{GitVersion: "v1.17.0"}
+
+	p := &Provider{
+		Flavor:     FlavorKind,
+		kubeconfig: []byte("kubeconfig"),
+		kube:       kube,
+	}
+
+	if id, version, err := p.Cluster(); err != nil {
+		t.Errorf("failed to get cluster: %v", err)
+	} else if id != "0f1e2d3c" || version != "1.17.0" {
+		t.Errorf("expected cluster '0f1e2d3c' at 1.17.0, got '%s' at %s", id, version)
+	}
+
+	if err := p.WaitForClusterReady("0f1e2d3c", 10*time.Millisecond, time.Millisecond); err == nil {
+		t.Error("expected node which isn't ready to fail readiness")
+	}
+
+	node.Status.Conditions[0].Status = kubev1.ConditionTrue
+	if _, err := kube.CoreV1().Nodes().UpdateStatus(node); err != nil {
+		t.Fatalf("failed updating node: %v", err)
+	}
+	if err := p.WaitForClusterReady("0f1e2d3c", 10*time.Millisecond, time.Millisecond); err != nil {
+		t.Errorf("expected ready node to pass readiness: %v", err)
+	}
+
+	if _, err := p.LaunchCluster(nil); err == nil {
+		t.Error("expected clusters not to be created")
+	}
+}
+
+func TestKubeconfigPath(t *testing.T) {
+	home, err := ioutil.TempDir("", "osde2e-local")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+	defer restoreEnv("HOME")()
+	defer restoreEnv("KUBECONFIG")()
+	os.Setenv("HOME", home)
+
+	os.Unsetenv("KUBECONFIG")
+	if path := KubeconfigPath(); path != filepath.Join(home, ".kube", "config") {
+		t.Errorf("expected default kubeconfig, got '%s'", path)
+	}
+
+	os.Setenv("KUBECONFIG", "/tmp/kind"+string(filepath.ListSeparator)+"/tmp/other")
+	if path := KubeconfigPath(); path != "/tmp/kind" {
+		t.Errorf("expected first kubeconfig of KUBECONFIG, got '%s'", path)
+	}
+
+	crc := filepath.Join(home, crcKubeconfig)
+	os.MkdirAll(filepath.Dir(crc), os.ModePerm)
+	if err = ioutil.WriteFile(crc, []byte("kubeconfig"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if path := KubeconfigPath(); path != crc {
+		t.Errorf("expected CRC kubeconfig to be preferred, got '%s'", path)
+	}
+}
+
+// restoreEnv returns a func setting env back to its current value.
+func restoreEnv(env string) func() {
+	val, ok := os.LookupEnv(env)
+	return func() {
+		if ok {
+			os.Setenv(env, val)
+		} else {
+			os.Unsetenv(env)
+		}
+	}
+}
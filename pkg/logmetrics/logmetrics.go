@@ -0,0 +1,312 @@
+// Package logmetrics counts occurrences of patterns in logs and checks them against thresholds. Patterns may match
+// single lines, span several lines, or match fields of structured JSON logs, and label what they match with the
+// values of their named capture groups.
+package logmetrics
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// MaxMatches is the number of matching lines kept for each result.
+const MaxMatches = 5
+
+// LogMetric counts lines of logs matching a pattern.
+type LogMetric struct {
+	// Name identifies the metric in results.
+	Name string `json:"name"`
+
+	// RegEx is a regular expression matched against each line, or against the whole log in MultiLine mode. Named
+	// capture groups, such as (?P<reason>\w+), label matches with what they captured. It may be empty if Fields is set.
+	RegEx string `json:"regex,omitempty"`
+
+	// MultiLine matches RegEx against the whole log so patterns can span lines, such as a panic and its stack trace.
+	// ^ and $ match at the start and end of each line, and . matches newlines. Each match is counted once.
+	MultiLine bool `json:"multiLine,omitempty"`
+
+	// Fields matches lines which are JSON objects by regular expressions against their fields, such as 'level' or
+	// 'error.code' for nested fields. All fields and RegEx must match. Named capture groups label matches like RegEx.
+	Fields map[string]string `json:"fields,omitempty"`
+
+	// HighThreshold is the most matches allowed before the metric fails.
+	HighThreshold int `json:"highThreshold"`
+
+	// LowThreshold is the fewest matches allowed before the metric fails.
+	LowThreshold int `json:"lowThreshold"`
+
+	regex  *regexp.Regexp
+	fields map[string]*regexp.Regexp
+}
+
+// Compile prepares the metric for matching.
+func (m *LogMetric) Compile() (err error) {
+	if m.Name == "" {
+		return fmt.Errorf("log metric matching '%s' must have a name", m.RegEx)
+	}
+	if m.RegEx == "" && len(m.Fields) == 0 {
+		return fmt.Errorf("log metric '%s' must have a regex or fields to match", m.Name)
+	} else if m.MultiLine && len(m.Fields) != 0 {
+		return fmt.Errorf("log metric '%s' can't match fields in multi-line mode", m.Name)
+	}
+
+	expr := m.RegEx
+	if m.MultiLine {
+		expr = "(?ms)" + expr
+	}
+	if m.regex, err = regexp.Compile(expr); err != nil {
+		return fmt.Errorf("invalid regex for log metric '%s': %v", m.Name, err)
+	}
+
+	m.fields = make(map[string]*regexp.Regexp, len(m.Fields))
+	for field, expr := range m.Fields {
+		if m.fields[field], err = regexp.Compile(expr); err != nil {
+			return fmt.Errorf("invalid regex for field '%s' of log metric '%s': %v", field, m.Name, err)
+		}
+	}
+
+	if m.HighThreshold < m.LowThreshold {
+		return fmt.Errorf("log metric '%s' has a high threshold below its low threshold", m.Name)
+	}
+	return nil
+}
+
+// matchLine returns the labels captured if line, and its fields when it's a JSON object, match the metric.
+func (m *LogMetric) matchLine(line []byte, fields map[string]interface{}) (map[string]string, bool) {
+	labels := map[string]string{}
+	if !capture(m.regex, line, labels) {
+		return nil, false
+	}
+
+	if len(m.fields) != 0 {
+		if fields == nil {
+			return nil, false
+		}
+		for name, regex := range m.fields {
+			value, ok := field(fields, name)
+			if !ok || !capture(regex, []byte(value), labels) {
+				return nil, false
+			}
+		}
+	}
+	return labels, true
+}
+
+// capture returns true if regex matches data, adding the values of its named capture groups to labels.
+func capture(regex *regexp.Regexp, data []byte, labels map[string]string) bool {
+	submatches := regex.FindSubmatch(data)
+	if submatches == nil {
+		return false
+	}
+	for i, name := range regex.SubexpNames() {
+		if name != "" {
+			labels[name] = string(submatches[i])
+		}
+	}
+	return true
+}
+
+// field returns the value at the dot separated path of fields, such as 'error.code', as a string.
+func field(fields map[string]interface{}, path string) (string, bool) {
+	var value interface{} = fields
+	for _, key := range strings.Split(path, ".") {
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+		if value, ok = obj[key]; !ok {
+			return "", false
+		}
+	}
+
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case map[string]interface{}, []interface{}:
+		data, err := json.Marshal(v)
+		return string(data), err == nil
+	default:
+		return fmt.Sprint(v), true
+	}
+}
+
+// Series counts the matches of a metric which captured the same labels.
+type Series struct {
+	Labels map[string]string
+	Count  int
+}
+
+// String formats the labels of the series like Prometheus, such as '{reason="OOMKilled"}'.
+func (s Series) String() string {
+	return "{" + formatLabels(s.Labels) + "}"
+}
+
+// Result is the number of times a metric matched logs.
+type Result struct {
+	Metric LogMetric
+
+	// Count is the number of matching lines.
+	Count int
+
+	// Matches are the first MaxMatches matching lines.
+	Matches []string
+
+	// Series count matches by the labels they captured, sorted by labels. Metrics without named capture groups
+	// have none.
+	Series []Series
+}
+
+// Passed returns true if the count is within the thresholds of the metric.
+func (r Result) Passed() bool {
+	return r.Count >= r.Metric.LowThreshold && r.Count <= r.Metric.HighThreshold
+}
+
+// add counts a match of text which captured labels.
+func (r *Result) add(text string, labels map[string]string) {
+	r.Count++
+	if len(r.Matches) < MaxMatches {
+		r.Matches = append(r.Matches, text)
+	}
+	if len(labels) == 0 {
+		return
+	}
+
+	key := formatLabels(labels)
+	for i := range r.Series {
+		if formatLabels(r.Series[i].Labels) == key {
+			r.Series[i].Count++
+			return
+		}
+	}
+	r.Series = append(r.Series, Series{Labels: labels, Count: 1})
+}
+
+// Engine matches a set of metrics against logs.
+type Engine struct {
+	Metrics []LogMetric `json:"metrics"`
+}
+
+// Load reads YAML log metrics from file. An empty engine is returned if file doesn't exist.
+func Load(file string) (*Engine, error) {
+	data, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return new(Engine), nil
+	} else if err != nil {
+		return nil, fmt.Errorf("couldn't read log metrics '%s': %v", file, err)
+	}
+	return Parse(data)
+}
+
+// Parse decodes YAML log metrics.
+func Parse(data []byte) (*Engine, error) {
+	e := new(Engine)
+	if err := yaml.Unmarshal(data, e); err != nil {
+		return nil, fmt.Errorf("couldn't decode log metrics: %v", err)
+	}
+	return e, e.compile()
+}
+
+// New returns an engine matching metrics.
+func New(metrics ...LogMetric) (*Engine, error) {
+	e := &Engine{Metrics: metrics}
+	return e, e.compile()
+}
+
+func (e *Engine) compile() error {
+	for i := range e.Metrics {
+		if err := e.Metrics[i].Compile(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Add includes metrics in those matched by the engine.
+func (e *Engine) Add(metrics ...LogMetric) error {
+	for _, m := range metrics {
+		if err := m.Compile(); err != nil {
+			return err
+		}
+		e.Metrics = append(e.Metrics, m)
+	}
+	return nil
+}
+
+// Analyze matches each metric against logs, line by line unless the metric is multi-line. Lines are parsed as JSON
+// only if a metric matches fields.
+func (e *Engine) Analyze(logs []byte) []Result {
+	results := make([]Result, len(e.Metrics))
+	structured := false
+	for i, m := range e.Metrics {
+		results[i].Metric = m
+		if m.MultiLine {
+			for _, loc := range m.regex.FindAllSubmatchIndex(logs, -1) {
+				labels := map[string]string{}
+				for j, name := range m.regex.SubexpNames() {
+					if name != "" && loc[2*j] >= 0 {
+						labels[name] = string(logs[loc[2*j]:loc[2*j+1]])
+					}
+				}
+				results[i].add(string(logs[loc[0]:loc[1]]), labels)
+			}
+		}
+		structured = structured || len(m.fields) != 0
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(logs))
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+
+		var fields map[string]interface{}
+		if structured && bytes.HasPrefix(bytes.TrimSpace(line), []byte("{")) {
+			if err := json.Unmarshal(line, &fields); err != nil {
+				fields = nil
+			}
+		}
+
+		for i := range e.Metrics {
+			m := &e.Metrics[i]
+			if m.MultiLine {
+				continue
+			}
+			if labels, ok := m.matchLine(line, fields); ok {
+				results[i].add(string(line), labels)
+			}
+		}
+	}
+
+	for i := range results {
+		series := results[i].Series
+		sort.Slice(series, func(a, b int) bool {
+			return formatLabels(series[a].Labels) < formatLabels(series[b].Labels)
+		})
+	}
+	return results
+}
+
+// labelEscaper escapes label values in the Prometheus text format.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// formatLabels formats labels as comma separated name="value" pairs sorted by name.
+func formatLabels(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = fmt.Sprintf(`%s="%s"`, name, labelEscaper.Replace(labels[name]))
+	}
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,190 @@
+package logmetrics
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testLogs = `starting
+error: connection refused
+retrying
+error: connection refused
+error: timeout
+done
+`
+
+func TestAnalyze(t *testing.T) {
+	e, err := Parse([]byte(`
+metrics:
+- name: errors
+  regex: "^error:"
+  highThreshold: 2
+- name: finished
+  regex: "^done$"
+  lowThreshold: 1
+  highThreshold: 1
+`))
+	if err != nil {
+		t.Fatalf("failed to parse metrics: %v", err)
+	}
+
+	results := e.Analyze([]byte(testLogs))
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+
+	errors := results[0]
+	if errors.Count != 3 || errors.Passed() {
+		t.Errorf("expected 3 errors to fail: %+v", errors)
+	} else if len(errors.Matches) != 3 || errors.Matches[2] != "error: timeout" {
+		t.Errorf("unexpected matches: %v", errors.Matches)
+	}
+
+	if finished := results[1]; finished.Count != 1 || !finished.Passed() {
+		t.Errorf("expected finished to pass: %+v", finished)
+	}
+}
+
+func TestLowThreshold(t *testing.T) {
+	e, err := New(LogMetric{Name: "ready", RegEx: "ready", LowThreshold: 1, HighThreshold: 5})
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+
+	if r := e.Analyze([]byte(testLogs))[0]; r.Passed() {
+		t.Errorf("expected metric without matches to fail: %+v", r)
+	}
+}
+
+func TestMaxMatches(t *testing.T) {
+	e, err := New(LogMetric{Name: "all", RegEx: ".", HighThreshold: 100})
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+
+	r := e.Analyze([]byte(testLogs + testLogs))[0]
+	if r.Count != 12 || len(r.Matches) != MaxMatches {
+		t.Errorf("expected 12 matches with %d kept: %+v", MaxMatches, r)
+	}
+}
+
+func TestMultiLine(t *testing.T) {
+	logs := `I0101 starting
+panic: runtime error: invalid memory address
+
+goroutine 1 [running]:
+main.main()
+I0101 restarted
+panic: close of closed channel
+
+goroutine 7 [running]:
+main.worker()
+`
+	e, err := New(LogMetric{
+		Name:          "panics",
+		RegEx:         `^panic: (?P<error>[^\n]+)\n\ngoroutine \d+ \[running\]:\n\S+\(\)$`,
+		MultiLine:     true,
+		HighThreshold: 1,
+	})
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+
+	r := e.Analyze([]byte(logs))[0]
+	if r.Count != 2 || r.Passed() {
+		t.Fatalf("expected 2 panics to fail: %+v", r)
+	} else if !strings.HasSuffix(r.Matches[1], "main.worker()") {
+		t.Errorf("expected matches to span lines, got %q", r.Matches)
+	}
+
+	expected := []Series{
+		{Labels: map[string]string{"error": "close of closed channel"}, Count: 1},
+		{Labels: map[string]string{"error": "runtime error: invalid memory address"}, Count: 1},
+	}
+	if !reflect.DeepEqual(r.Series, expected) {
+		t.Errorf("expected series %v, got %v", expected, r.Series)
+	}
+}
+
+func TestFields(t *testing.T) {
+	logs := `{"level":"error","msg":"sync failed","error":{"code":500,"reason":"Timeout"}}
+{"level":"info","msg":"synced"}
+{"level":"error","msg":"sync failed","error":{"code":409,"reason":"Conflict"}}
+{"level":"error","msg":"sync failed","error":{"code":500,"reason":"Timeout"}}
+level=error msg="sync failed"
+{"level":"error"
+`
+	e, err := Parse([]byte(`
+metrics:
+- name: sync-errors
+  regex: "sync failed"
+  fields:
+    level: "^error$"
+    error.code: "^(?P<code>5[0-9]{2})$"
+    error.reason: "(?P<reason>.+)"
+  highThreshold: 5
+- name: structured-errors
+  fields:
+    level: "^error$"
+  highThreshold: 5
+`))
+	if err != nil {
+		t.Fatalf("failed to parse metrics: %v", err)
+	}
+
+	results := e.Analyze([]byte(logs))
+	sync := results[0]
+	expected := []Series{{Labels: map[string]string{"code": "500", "reason": "Timeout"}, Count: 2}}
+	if sync.Count != 2 || !reflect.DeepEqual(sync.Series, expected) {
+		t.Errorf("expected 2 server errors labelled by code and reason, got %+v", sync)
+	}
+
+	if structured := results[1]; structured.Count != 3 || len(structured.Series) != 0 {
+		t.Errorf("expected only JSON lines to match fields, got %+v", structured)
+	}
+}
+
+func TestWritePrometheus(t *testing.T) {
+	e, err := New(
+		LogMetric{Name: "restarts", RegEx: `restarting (?P<container>\w+)`, HighThreshold: 10},
+		LogMetric{Name: "finished", RegEx: "^done$", HighThreshold: 1},
+	)
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+
+	var samples []Sample
+	for _, r := range e.Analyze([]byte("restarting kubelet\nrestarting \"crio\"\nrestarting kubelet\ndone\n")) {
+		samples = append(samples, r.Samples(map[string]string{"node": "worker-a"})...)
+	}
+
+	var b bytes.Buffer
+	if err = WritePrometheus(&b, samples); err != nil {
+		t.Fatalf("failed to write metrics: %v", err)
+	}
+	expected := `# HELP osde2e_log_metric_matches Matches of log metrics.
+# TYPE osde2e_log_metric_matches gauge
+osde2e_log_metric_matches{container="kubelet",metric="restarts",node="worker-a"} 2
+osde2e_log_metric_matches{metric="finished",node="worker-a"} 1
+`
+	if b.String() != expected {
+		t.Errorf("expected metrics:\n%s\ngot:\n%s", expected, b.String())
+	}
+}
+
+func TestInvalidMetrics(t *testing.T) {
+	for name, m := range map[string]LogMetric{
+		"no name":           {RegEx: "a"},
+		"nothing to match":  {Name: "empty"},
+		"bad regex":         {Name: "bad", RegEx: "("},
+		"bad field regex":   {Name: "bad", Fields: map[string]string{"level": "("}},
+		"multi-line fields": {Name: "both", RegEx: "a", MultiLine: true, Fields: map[string]string{"level": "error"}},
+		"thresholds":        {Name: "inverted", RegEx: "a", LowThreshold: 2, HighThreshold: 1},
+	} {
+		if _, err := New(m); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
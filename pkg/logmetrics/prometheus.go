@@ -0,0 +1,53 @@
+package logmetrics
+
+import (
+	"fmt"
+	"io"
+)
+
+// MetricName is the Prometheus metric counting matches of log metrics.
+const MetricName = "osde2e_log_metric_matches"
+
+// Sample is a count of matches of a log metric with the labels identifying it.
+type Sample struct {
+	Labels map[string]string
+	Value  int
+}
+
+// Samples returns the count of matches of r for each series, labelled with labels, the name of the metric, and the
+// labels captured by its matches. A single sample is returned if the metric captured no labels.
+func (r Result) Samples(labels map[string]string) []Sample {
+	sample := func(captured map[string]string, value int) Sample {
+		s := Sample{Labels: map[string]string{}, Value: value}
+		for k, v := range captured {
+			s.Labels[k] = v
+		}
+		for k, v := range labels {
+			s.Labels[k] = v
+		}
+		s.Labels["metric"] = r.Metric.Name
+		return s
+	}
+
+	if len(r.Series) == 0 {
+		return []Sample{sample(nil, r.Count)}
+	}
+	samples := make([]Sample, len(r.Series))
+	for i, s := range r.Series {
+		samples[i] = sample(s.Labels, s.Count)
+	}
+	return samples
+}
+
+// WritePrometheus writes samples to w in the Prometheus text format as MetricName.
+func WritePrometheus(w io.Writer, samples []Sample) error {
+	if _, err := fmt.Fprintf(w, "# HELP %s Matches of log metrics.\n# TYPE %s gauge\n", MetricName, MetricName); err != nil {
+		return err
+	}
+	for _, s := range samples {
+		if _, err := fmt.Fprintf(w, "%s{%s} %d\n", MetricName, formatLabels(s.Labels), s.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
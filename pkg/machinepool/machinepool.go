@@ -0,0 +1,179 @@
+// Package machinepool adds machine pools with labels and taints to clusters when they're provisioned, so suites can
+// test scheduling on topologies like those of customers, such as infra nodes or nodes dedicated to a workload.
+package machinepool
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"time"
+
+	kubev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/osde2e/pkg/helper"
+	"github.com/openshift/osde2e/pkg/osd"
+	"github.com/openshift/osde2e/pkg/topology"
+)
+
+// pollInterval is how often nodes are checked while waiting for machine pools.
+const pollInterval = 30 * time.Second
+
+// effects are the effects taints may have.
+var effects = map[kubev1.TaintEffect]bool{
+	kubev1.TaintEffectNoSchedule:       true,
+	kubev1.TaintEffectPreferNoSchedule: true,
+	kubev1.TaintEffectNoExecute:        true,
+}
+
+// Preset is a set of machine pools added to clusters after they're installed.
+type Preset struct {
+	// Name identifies the preset in logs.
+	Name string `json:"name"`
+
+	// Pools are created in order. Each needs labels so its nodes can be found.
+	Pools []osd.MachinePool `json:"pools"`
+}
+
+// Load reads a YAML preset from file.
+func Load(file string) (*Preset, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read machine pool preset '%s': %v", file, err)
+	}
+	return Parse(data)
+}
+
+// Parse decodes and validates a YAML preset.
+func Parse(data []byte) (*Preset, error) {
+	p := new(Preset)
+	if err := yaml.Unmarshal(data, p); err != nil {
+		return nil, fmt.Errorf("couldn't decode machine pool preset: %v", err)
+	}
+
+	if len(p.Pools) == 0 {
+		return nil, fmt.Errorf("machine pool preset '%s' has no pools", p.Name)
+	}
+	ids := map[string]bool{}
+	for _, pool := range p.Pools {
+		if pool.ID == "" {
+			return nil, fmt.Errorf("machine pools of preset '%s' must have an ID", p.Name)
+		} else if ids[pool.ID] {
+			return nil, fmt.Errorf("machine pool '%s' is in preset '%s' more than once", pool.ID, p.Name)
+		}
+		ids[pool.ID] = true
+
+		if pool.InstanceType == "" || pool.Replicas < 1 {
+			return nil, fmt.Errorf("machine pool '%s' must have an instance type and at least 1 replica", pool.ID)
+		} else if len(pool.Labels) == 0 {
+			return nil, fmt.Errorf("machine pool '%s' must have labels to find its nodes", pool.ID)
+		}
+		for _, t := range pool.Taints {
+			if t.Key == "" || !effects[kubev1.TaintEffect(t.Effect)] {
+				return nil, fmt.Errorf("taint '%s' of machine pool '%s' must have a key and an effect of %s, %s, or %s",
+					t.Key, pool.ID, kubev1.TaintEffectNoSchedule, kubev1.TaintEffectPreferNoSchedule, kubev1.TaintEffectNoExecute)
+			}
+		}
+	}
+	return p, nil
+}
+
+// Nodes returns the nodes with all labels of pool.
+func Nodes(pool osd.MachinePool, nodes []kubev1.Node) (matched []kubev1.Node) {
+	for _, n := range nodes {
+		if hasLabels(n, pool.Labels) {
+			matched = append(matched, n)
+		}
+	}
+	return
+}
+
+// Problems describes how the labels and taints of pool weren't propagated to its nodes.
+func Problems(pool osd.MachinePool, nodes []kubev1.Node) (problems []string) {
+	matched := Nodes(pool, nodes)
+	if len(matched) != pool.Replicas {
+		problems = append(problems, fmt.Sprintf("machine pool '%s' has %d nodes labeled %v, expected %d",
+			pool.ID, len(matched), pool.Labels, pool.Replicas))
+	}
+
+	for _, n := range matched {
+		if instanceType := n.Labels[topology.InstanceTypeLabel]; instanceType != pool.InstanceType {
+			problems = append(problems, fmt.Sprintf("node '%s' of machine pool '%s' is a '%s' instance, expected '%s'",
+				n.Name, pool.ID, instanceType, pool.InstanceType))
+		}
+		for _, t := range pool.Taints {
+			if !hasTaint(n, t) {
+				problems = append(problems, fmt.Sprintf("node '%s' of machine pool '%s' isn't tainted %s=%s:%s",
+					n.Name, pool.ID, t.Key, t.Value, t.Effect))
+			}
+		}
+	}
+	sort.Strings(problems)
+	return
+}
+
+// Untolerated returns the taints of node which keep pods from being scheduled or running on it that pod doesn't
+// tolerate.
+func Untolerated(pod *kubev1.Pod, node *kubev1.Node) (taints []kubev1.Taint) {
+	for i := range node.Spec.Taints {
+		taint := &node.Spec.Taints[i]
+		if taint.Effect == kubev1.TaintEffectPreferNoSchedule {
+			continue
+		}
+
+		tolerated := false
+		for j := range pod.Spec.Tolerations {
+			if pod.Spec.Tolerations[j].ToleratesTaint(taint) {
+				tolerated = true
+				break
+			}
+		}
+		if !tolerated {
+			taints = append(taints, *taint)
+		}
+	}
+	return
+}
+
+// Wait blocks until the nodes of each pool of preset have its labels and taints or timeout, returning the problems
+// remaining if they don't.
+func Wait(h *helper.H, preset *Preset, timeout time.Duration) error {
+	var problems []string
+	err := h.PollImmediate(pollInterval, timeout, func() (bool, error) {
+		nodes, err := h.Kube().CoreV1().Nodes().List(metav1.ListOptions{})
+		if err != nil {
+			return false, nil
+		}
+
+		problems = nil
+		for _, pool := range preset.Pools {
+			problems = append(problems, Problems(pool, nodes.Items)...)
+		}
+		return len(problems) == 0, nil
+	})
+	if err != nil && len(problems) != 0 {
+		return fmt.Errorf("machine pools of preset '%s' weren't ready: %v", preset.Name, problems)
+	}
+	return err
+}
+
+// hasLabels returns true if node has all labels.
+func hasLabels(node kubev1.Node, labels map[string]string) bool {
+	for k, v := range labels {
+		if actual, ok := node.Labels[k]; !ok || actual != v {
+			return false
+		}
+	}
+	return true
+}
+
+// hasTaint returns true if node has taint.
+func hasTaint(node kubev1.Node, taint osd.Taint) bool {
+	for _, t := range node.Spec.Taints {
+		if t.Key == taint.Key && t.Value == taint.Value && string(t.Effect) == taint.Effect {
+			return true
+		}
+	}
+	return false
+}
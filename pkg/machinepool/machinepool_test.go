@@ -0,0 +1,97 @@
+package machinepool
+
+import (
+	"reflect"
+	"testing"
+
+	kubev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openshift/osde2e/pkg/osd"
+	"github.com/openshift/osde2e/pkg/topology"
+)
+
+var infra = osd.MachinePool{
+	ID:           "infra",
+	InstanceType: "r5.xlarge",
+	Replicas:     2,
+	Labels:       map[string]string{"node-role.kubernetes.io/infra": ""},
+	Taints:       []osd.Taint{{Key: "node-role.kubernetes.io/infra", Effect: "NoSchedule"}},
+}
+
+func TestPresets(t *testing.T) {
+	for _, file := range []string{"../../machinepools/infra.yaml", "../../machinepools/dedicated.yaml"} {
+		p, err := Load(file)
+		if err != nil {
+			t.Errorf("Failed loading preset '%s': %v", file, err)
+			continue
+		}
+		if !reflect.DeepEqual(p.Pools[0], infra) {
+			t.Errorf("expected preset '%s' to start with pool %+v, got %+v", file, infra, p.Pools[0])
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, invalid := range []string{
+		"name: empty\n",
+		"pools:\n- instance_type: m5.xlarge\n  replicas: 1\n  labels: {a: b}\n",
+		"pools:\n- id: a\n  replicas: 1\n  labels: {a: b}\n",
+		"pools:\n- id: a\n  instance_type: m5.xlarge\n  labels: {a: b}\n",
+		"pools:\n- id: a\n  instance_type: m5.xlarge\n  replicas: 1\n",
+		"pools:\n- id: a\n  instance_type: m5.xlarge\n  replicas: 1\n  labels: {a: b}\n  taints:\n  - key: a\n    effect: Never\n",
+		"pools:\n- id: a\n  instance_type: m5.xlarge\n  replicas: 1\n  labels: {a: b}\n- id: a\n  instance_type: m5.xlarge\n  replicas: 1\n  labels: {a: b}\n",
+	} {
+		if _, err := Parse([]byte(invalid)); err == nil {
+			t.Errorf("preset should be invalid: %s", invalid)
+		}
+	}
+}
+
+func TestProblems(t *testing.T) {
+	good := node("infra-a", "r5.xlarge", infra.Labels, kubev1.Taint{Key: "node-role.kubernetes.io/infra", Effect: kubev1.TaintEffectNoSchedule})
+	untainted := node("infra-b", "r5.xlarge", infra.Labels)
+	worker := node("worker-a", "m5.xlarge", map[string]string{"node-role.kubernetes.io/worker": ""})
+
+	if problems := Problems(infra, []kubev1.Node{good, good, worker}); len(problems) != 0 {
+		t.Errorf("expected no problems, got %v", problems)
+	}
+
+	expected := []string{
+		"machine pool 'infra' has 1 nodes labeled map[node-role.kubernetes.io/infra:], expected 2",
+		"node 'infra-b' of machine pool 'infra' isn't tainted node-role.kubernetes.io/infra=:NoSchedule",
+	}
+	if problems := Problems(infra, []kubev1.Node{untainted, worker}); !reflect.DeepEqual(problems, expected) {
+		t.Errorf("expected problems %v, got %v", expected, problems)
+	}
+}
+
+func TestUntolerated(t *testing.T) {
+	n := node("dedicated-a", "m5.xlarge", nil,
+		kubev1.Taint{Key: "dedicated", Value: "workload", Effect: kubev1.TaintEffectNoExecute},
+		kubev1.Taint{Key: "preferred", Effect: kubev1.TaintEffectPreferNoSchedule})
+
+	pod := &kubev1.Pod{}
+	if taints := Untolerated(pod, &n); len(taints) != 1 || taints[0].Key != "dedicated" {
+		t.Errorf("expected only the NoExecute taint to be untolerated, got %v", taints)
+	}
+
+	pod.Spec.Tolerations = []kubev1.Toleration{{Key: "dedicated", Operator: kubev1.TolerationOpEqual, Value: "workload"}}
+	if taints := Untolerated(pod, &n); len(taints) != 0 {
+		t.Errorf("expected all taints to be tolerated, got %v", taints)
+	}
+}
+
+func node(name, instanceType string, labels map[string]string, taints ...kubev1.Taint) kubev1.Node {
+	n := kubev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{topology.InstanceTypeLabel: instanceType},
+		},
+		Spec: kubev1.NodeSpec{Taints: taints},
+	}
+	for k, v := range labels {
+		n.Labels[k] = v
+	}
+	return n
+}
@@ -0,0 +1,201 @@
+// Package metrichooks lets teams compute their own KPIs from the results of runs without changing how results are
+// uploaded. Hooks receive the merged JUnit results and metadata of a run before they're uploaded and emit metric
+// files which are uploaded along with them.
+//
+// Hooks are either Go functions registered with Register, such as from the init function of a package imported by
+// the test binary, or external commands listed in METRIC_HOOKS.
+package metrichooks
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/test-infra/testgrid/metadata/junit"
+)
+
+const (
+	// FilePrefix begins the names of metric files emitted by hooks, followed by the name of the hook.
+	FilePrefix = "metrics_"
+
+	// JUnitEnv is the path of the merged JUnit results given to command hooks.
+	JUnitEnv = "OSDE2E_JUNIT"
+
+	// MetadataEnv is the path of the JSON encoded metadata given to command hooks.
+	MetadataEnv = "OSDE2E_METADATA"
+
+	// OutputDirEnv is the directory command hooks write metric files to.
+	OutputDirEnv = "OSDE2E_METRICS_DIR"
+
+	// CommandTimeout is how long command hooks may run.
+	CommandTimeout = 5 * time.Minute
+)
+
+// Input is what hooks compute metrics from.
+type Input struct {
+	// Suites are the results of every JUnit file of the run.
+	Suites junit.Suites
+
+	// Metadata is what's recorded about the run in TestGrid.
+	Metadata map[string]interface{}
+}
+
+// Hook computes metrics from the results of a run, returning the contents of metric files by name.
+type Hook func(in *Input) (map[string][]byte, error)
+
+var (
+	mu    sync.Mutex
+	hooks = map[string]Hook{}
+)
+
+// Register adds a hook run for every run. It panics if a hook is already registered as name.
+func Register(name string, hook Hook) {
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := hooks[name]; ok {
+		panic(fmt.Sprintf("metric hook '%s' is already registered", name))
+	}
+	hooks[name] = hook
+}
+
+// Command returns a hook running command, a binary followed by arguments separated by spaces. The paths of the
+// merged JUnit results and metadata are given in JUnitEnv and MetadataEnv, and every file it writes to OutputDirEnv
+// is a metric file.
+func Command(command string) Hook {
+	return func(in *Input) (map[string][]byte, error) {
+		args := strings.Fields(command)
+		if len(args) == 0 {
+			return nil, fmt.Errorf("no command given")
+		}
+
+		dir, err := ioutil.TempDir("", "metrichooks")
+		if err != nil {
+			return nil, err
+		}
+		defer os.RemoveAll(dir)
+
+		junitFile, metadataFile, outDir := filepath.Join(dir, "junit.xml"), filepath.Join(dir, "metadata.json"),
+			filepath.Join(dir, "out")
+		if err = writeInput(in, junitFile, metadataFile); err != nil {
+			return nil, err
+		} else if err = os.Mkdir(outDir, os.ModePerm); err != nil {
+			return nil, err
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), CommandTimeout)
+		defer cancel()
+		cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+		cmd.Env = append(os.Environ(), JUnitEnv+"="+junitFile, MetadataEnv+"="+metadataFile, OutputDirEnv+"="+outDir)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return nil, fmt.Errorf("'%s' failed: %v: %s", command, err, strings.TrimSpace(string(out)))
+		}
+
+		infos, err := ioutil.ReadDir(outDir)
+		if err != nil {
+			return nil, err
+		}
+		files := map[string][]byte{}
+		for _, info := range infos {
+			if info.Mode().IsRegular() {
+				if files[info.Name()], err = ioutil.ReadFile(filepath.Join(outDir, info.Name())); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return files, nil
+	}
+}
+
+// writeInput stores in for command hooks.
+func writeInput(in *Input, junitFile, metadataFile string) error {
+	data, err := xml.MarshalIndent(in.Suites, "", "  ")
+	if err != nil {
+		return fmt.Errorf("couldn't encode JUnit results: %v", err)
+	} else if err = ioutil.WriteFile(junitFile, append([]byte(xml.Header), data...), os.ModePerm); err != nil {
+		return err
+	}
+
+	if data, err = json.MarshalIndent(in.Metadata, "", "  "); err != nil {
+		return fmt.Errorf("couldn't encode metadata: %v", err)
+	}
+	return ioutil.WriteFile(metadataFile, data, os.ModePerm)
+}
+
+// Merge returns the results of every JUnit file in dir.
+func Merge(dir string) (suites junit.Suites, err error) {
+	files, err := filepath.Glob(filepath.Join(dir, "junit*.xml"))
+	if err != nil {
+		return suites, fmt.Errorf("couldn't find JUnit files in '%s': %v", dir, err)
+	}
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return suites, fmt.Errorf("couldn't read JUnit '%s': %v", file, err)
+		}
+		parsed, err := junit.Parse(data)
+		if err != nil {
+			return suites, fmt.Errorf("couldn't decode JUnit '%s': %v", file, err)
+		}
+		suites.Suites = append(suites.Suites, parsed.Suites...)
+	}
+	return suites, nil
+}
+
+// Run runs registered hooks and commands on the results in dir and metadata, writing the metric files they emit to
+// dir. Commands are named by their binary and position in commands, such as 'count-1', so commands running the same
+// binary are kept apart. It returns the files written and why hooks failed, which doesn't stop
+// other hooks from running.
+func Run(dir string, metadata map[string]interface{}, commands []string) (written []string, errs []error) {
+	mu.Lock()
+	run := make(map[string]Hook, len(hooks)+len(commands))
+	for name, hook := range hooks {
+		run[name] = hook
+	}
+	mu.Unlock()
+	for i, command := range commands {
+		if fields := strings.Fields(command); len(fields) != 0 {
+			run[fmt.Sprintf("%s-%d", filepath.Base(fields[0]), i+1)] = Command(command)
+		}
+	}
+	if len(run) == 0 {
+		return nil, nil
+	}
+
+	suites, err := Merge(dir)
+	if err != nil {
+		return nil, []error{err}
+	}
+	in := &Input{Suites: suites, Metadata: metadata}
+
+	names := make([]string, 0, len(run))
+	for name := range run {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		files, err := run[name](in)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("metric hook '%s' failed: %v", name, err))
+			continue
+		}
+		for file, data := range files {
+			filename := filepath.Join(dir, FilePrefix+name+"_"+filepath.Base(file))
+			if err = ioutil.WriteFile(filename, data, os.ModePerm); err != nil {
+				errs = append(errs, fmt.Errorf("couldn't write metrics of hook '%s': %v", name, err))
+				continue
+			}
+			written = append(written, filename)
+		}
+	}
+	sort.Strings(written)
+	return written, errs
+}
@@ -0,0 +1,104 @@
+package metrichooks
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const results = `<?xml version="1.0" encoding="UTF-8"?>
+<testsuite name="OSD e2e" tests="2" failures="1" time="12">
+  <testcase name="[OSD] Routes should be admitted" classname="OSD e2e" time="10"></testcase>
+  <testcase name="[OSD] DNS should resolve" classname="OSD e2e" time="2"><failure>timeout</failure></testcase>
+</testsuite>
+`
+
+func TestRun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "metrichooks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	bin, err := ioutil.TempDir("", "metrichooks-bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(bin)
+
+	for _, name := range []string{"junit_abc.xml", "junit_upgrade_abc.xml"} {
+		if err = ioutil.WriteFile(filepath.Join(dir, name), []byte(results), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	Register("passrate", func(in *Input) (map[string][]byte, error) {
+		passed, total := 0, 0
+		for _, s := range in.Suites.Suites {
+			for _, r := range s.Results {
+				if total++; r.Failure == nil {
+					passed++
+				}
+			}
+		}
+		data := fmt.Sprintf("%s %d/%d", in.Metadata["verdict"], passed, total)
+		return map[string][]byte{"passrate.txt": []byte(data)}, nil
+	})
+	defer delete(hooks, "passrate")
+
+	// counts testcases of the merged results and passes on the metadata it was given
+	script := filepath.Join(bin, "count")
+	if err = ioutil.WriteFile(script, []byte(`#!/bin/sh
+grep -c "<testcase" "$OSDE2E_JUNIT" > "$OSDE2E_METRICS_DIR/$1"
+cp "$OSDE2E_METADATA" "$OSDE2E_METRICS_DIR/"
+`), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// commands running the same binary don't overwrite each other's metrics
+	commands := []string{script + " cases.txt", "false", script + " total.txt"}
+	written, errs := Run(dir, map[string]interface{}{"verdict": "test"}, commands)
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "'false'") {
+		t.Errorf("expected only the false command to fail, got %v", errs)
+	}
+
+	expected := map[string]string{
+		"metrics_passrate_passrate.txt": "test 2/4",
+		"metrics_count-1_cases.txt":     "4\n",
+		"metrics_count-1_metadata.json": "{\n  \"verdict\": \"test\"\n}",
+		"metrics_count-3_total.txt":     "4\n",
+		"metrics_count-3_metadata.json": "{\n  \"verdict\": \"test\"\n}",
+	}
+	if len(written) != len(expected) {
+		t.Errorf("expected %d metric files, got %v", len(expected), written)
+	}
+	for name, content := range expected {
+		data, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Errorf("expected metric file '%s': %v", name, err)
+		} else if string(data) != content {
+			t.Errorf("expected '%s' to be '%s', got '%s'", name, content, data)
+		}
+	}
+}
+
+func TestRegister(t *testing.T) {
+	hook := func(*Input) (map[string][]byte, error) { return nil, nil }
+	Register("twice", hook)
+	defer delete(hooks, "twice")
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected registering a hook twice to panic")
+		}
+	}()
+	Register("twice", hook)
+}
+
+func TestRunWithoutHooks(t *testing.T) {
+	if written, errs := Run("/nonexistent", nil, nil); len(written) != 0 || len(errs) != 0 {
+		t.Errorf("expected nothing to run, got %v and %v", written, errs)
+	}
+}
@@ -0,0 +1,102 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"text/tabwriter"
+)
+
+// Change is the difference in a series between two snapshots.
+type Change struct {
+	Metric string
+	Series string
+
+	// Before and After are NaN when the series is missing from a snapshot.
+	Before, After float64
+}
+
+// Percent returns the relative change from Before to After.
+func (c Change) Percent() float64 {
+	if c.Before == 0 {
+		if c.After == 0 {
+			return 0
+		}
+		return math.Inf(1)
+	}
+	return (c.After - c.Before) / math.Abs(c.Before) * 100
+}
+
+// Diff compares every series in before and after, returning those that changed by at least threshold percent.
+// Series found in only one snapshot are always included.
+func Diff(before, after *Snapshot, threshold float64) (changes []Change) {
+	values := map[string]map[string]*Change{}
+	add := func(s *Snapshot, isAfter bool) {
+		for metric, samples := range s.Metrics {
+			if values[metric] == nil {
+				values[metric] = map[string]*Change{}
+			}
+
+			for _, sample := range samples {
+				series := sample.Series()
+				c, ok := values[metric][series]
+				if !ok {
+					c = &Change{
+						Metric: metric,
+						Series: series,
+						Before: math.NaN(),
+						After:  math.NaN(),
+					}
+					values[metric][series] = c
+				}
+
+				if isAfter {
+					c.After = sample.Value
+				} else {
+					c.Before = sample.Value
+				}
+			}
+		}
+	}
+	add(before, false)
+	add(after, true)
+
+	for _, series := range values {
+		for _, c := range series {
+			missing := math.IsNaN(c.Before) || math.IsNaN(c.After)
+			if missing || math.Abs(c.Percent()) >= threshold {
+				changes = append(changes, *c)
+			}
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Metric != changes[j].Metric {
+			return changes[i].Metric < changes[j].Metric
+		}
+		return changes[i].Series < changes[j].Series
+	})
+	return
+}
+
+// WriteDiff writes changes as a table to w.
+func WriteDiff(w io.Writer, changes []Change) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "METRIC\tSERIES\tBEFORE\tAFTER\tCHANGE")
+	for _, c := range changes {
+		change := "missing"
+		if !math.IsNaN(c.Before) && !math.IsNaN(c.After) {
+			change = fmt.Sprintf("%+.1f%%", c.Percent())
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", c.Metric, c.Series, formatValue(c.Before), formatValue(c.After), change)
+	}
+	return tw.Flush()
+}
+
+func formatValue(v float64) string {
+	if math.IsNaN(v) {
+		return "-"
+	}
+	return fmt.Sprintf("%.4g", v)
+}
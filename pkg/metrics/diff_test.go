@@ -0,0 +1,79 @@
+package metrics
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+)
+
+const testQueryResponse = `{
+  "status": "success",
+  "data": {
+    "resultType": "vector",
+    "result": [
+      {"metric": {"__name__": "up", "verb": "GET"}, "value": [1565000000.1, "0.25"]},
+      {"metric": {"verb": "LIST"}, "value": [1565000000.1, "1.5"]}
+    ]
+  }
+}`
+
+func TestParseQueryResponse(t *testing.T) {
+	samples, err := ParseQueryResponse([]byte(testQueryResponse))
+	if err != nil {
+		t.Fatalf("Failed parsing response: %v", err)
+	}
+
+	if len(samples) != 2 {
+		t.Fatalf("expected 2 samples, got %v", samples)
+	}
+
+	if series := samples[0].Series(); series != `{verb="GET"}` || samples[0].Value != 0.25 {
+		t.Errorf("unexpected first sample %s %f", series, samples[0].Value)
+	}
+
+	if _, err = ParseQueryResponse([]byte(`{"status": "error", "error": "bad query"}`)); err == nil {
+		t.Error("failed queries should return an error")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	sample := func(verb string, val float64) Sample {
+		return Sample{Labels: map[string]string{"verb": verb}, Value: val}
+	}
+
+	before := &Snapshot{
+		Metrics: map[string][]Sample{
+			"latency": {sample("GET", 1), sample("LIST", 2), sample("DELETE", 1)},
+		},
+	}
+	after := &Snapshot{
+		Metrics: map[string][]Sample{
+			"latency": {sample("GET", 1.05), sample("LIST", 3), sample("POST", 1)},
+		},
+	}
+
+	changes := Diff(before, after, 10)
+	if len(changes) != 3 {
+		t.Fatalf("expected 3 changes, got %v", changes)
+	}
+
+	// sorted by series
+	if c := changes[0]; c.Series != `{verb="DELETE"}` || !math.IsNaN(c.After) {
+		t.Errorf("expected DELETE to be missing after, got %+v", c)
+	}
+	if c := changes[1]; c.Series != `{verb="LIST"}` || c.Percent() != 50 {
+		t.Errorf("expected LIST to increase 50%%, got %+v", c)
+	}
+	if c := changes[2]; c.Series != `{verb="POST"}` || !math.IsNaN(c.Before) {
+		t.Errorf("expected POST to be missing before, got %+v", c)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteDiff(&buf, changes); err != nil {
+		t.Fatalf("Failed writing diff: %v", err)
+	}
+	if !strings.Contains(buf.String(), "+50.0%") {
+		t.Errorf("diff should include change, got:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,116 @@
+// Package metrics captures key Prometheus metrics from a cluster and compares them between runs.
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// SnapshotFile is the name of the artifact containing a snapshot.
+	SnapshotFile = "metrics-snapshot.json"
+
+	// LoadSnapshotFile is the name of the artifact containing a snapshot of latencies measured by the load test.
+	LoadSnapshotFile = "load-snapshot.json"
+
+	// NetperfSnapshotFile is the name of the artifact containing a snapshot of network throughput and latency.
+	NetperfSnapshotFile = "netperf-snapshot.json"
+)
+
+// KeyQueries are PromQL queries for metrics compared between runs.
+var KeyQueries = map[string]string{
+	"apiserver_request_p99_seconds":     `histogram_quantile(0.99, sum(rate(apiserver_request_latencies_bucket{verb!~"WATCH|CONNECT"}[1h])) by (verb, le)) / 1e6`,
+	"apiserver_requests_per_second":     `sum(rate(apiserver_request_count[1h])) by (verb)`,
+	"etcd_disk_fsync_p99_seconds":       `histogram_quantile(0.99, sum(rate(etcd_disk_wal_fsync_duration_seconds_bucket[1h])) by (le))`,
+	"etcd_db_size_bytes":                `max(etcd_debugging_mvcc_db_total_size_in_bytes)`,
+	"scheduler_e2e_p99_seconds":         `histogram_quantile(0.99, sum(rate(scheduler_e2e_scheduling_latency_microseconds_bucket[1h])) by (le)) / 1e6`,
+	"namespace_cpu_cores":               `sum(rate(container_cpu_usage_seconds_total{container_name!="",namespace=~"openshift-.*"}[1h])) by (namespace)`,
+	"namespace_memory_bytes":            `sum(container_memory_working_set_bytes{container_name!="",namespace=~"openshift-.*"}) by (namespace)`,
+	"node_cpu_utilisation":              `1 - avg(rate(node_cpu_seconds_total{mode="idle"}[1h]))`,
+	"prometheus_tsdb_head_series":       `max(prometheus_tsdb_head_series)`,
+	"kubelet_pod_start_p99_seconds":     `histogram_quantile(0.99, sum(rate(kubelet_pod_start_latency_microseconds_bucket[1h])) by (le)) / 1e6`,
+	"cluster_operator_conditions_false": `count(cluster_operator_conditions{condition="Available"} == 0) or vector(0)`,
+}
+
+// Snapshot holds the values of metrics at a point in time.
+type Snapshot struct {
+	Time    time.Time           `json:"time"`
+	Metrics map[string][]Sample `json:"metrics"`
+
+	// Labels describe the cluster the snapshot was taken from, such as its region and instance types.
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// Sample is the value of a single series.
+type Sample struct {
+	Labels map[string]string `json:"labels,omitempty"`
+	Value  float64           `json:"value"`
+}
+
+// Series identifies the sample by its labels.
+func (s Sample) Series() string {
+	keys := make([]string, 0, len(s.Labels))
+	for k := range s.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = fmt.Sprintf("%s=%q", k, s.Labels[k])
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// queryResponse is the response of the Prometheus instant query API.
+type queryResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Data   struct {
+		ResultType string `json:"resultType"`
+		Result     []struct {
+			Metric map[string]string `json:"metric"`
+			Value  []interface{}     `json:"value"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// ParseQueryResponse returns the samples in the response of a Prometheus instant query returning a vector.
+func ParseQueryResponse(data []byte) ([]Sample, error) {
+	var resp queryResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("couldn't decode query response: %v", err)
+	} else if resp.Status != "success" {
+		return nil, fmt.Errorf("query failed: %s", resp.Error)
+	} else if resp.Data.ResultType != "vector" {
+		return nil, fmt.Errorf("expected vector result, got '%s'", resp.Data.ResultType)
+	}
+
+	samples := make([]Sample, 0, len(resp.Data.Result))
+	for _, r := range resp.Data.Result {
+		if len(r.Value) != 2 {
+			return nil, fmt.Errorf("expected value to have timestamp and value, got %v", r.Value)
+		}
+
+		str, ok := r.Value[1].(string)
+		if !ok {
+			return nil, fmt.Errorf("expected value to be a string, got %T", r.Value[1])
+		}
+
+		val, err := strconv.ParseFloat(str, 64)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't parse value '%s': %v", str, err)
+		}
+
+		delete(r.Metric, "__name__")
+		samples = append(samples, Sample{
+			Labels: r.Metric,
+			Value:  val,
+		})
+	}
+	return samples, nil
+}
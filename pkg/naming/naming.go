@@ -0,0 +1,126 @@
+// Package naming makes names of what runs create, such as clusters and namespaces, which are unique across runs and
+// can be traced back to the job that created them. Names end with the job's ID, when they were made, and a random
+// nonce, such as 'ci-cluster-4-1-0-1234567890-pzk3x0-a1b'.
+package naming
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// LocalJob is the job of names made outside of CI, when no job ID is known.
+	LocalJob = "local"
+
+	// maxJobLength is the longest job ID kept in names, leaving room for prefixes within DNS labels.
+	maxJobLength = 20
+
+	// MaxLabelLength is the longest a DNS label, such as the name of a cluster, may be.
+	MaxLabelLength = 63
+
+	// clusterPrefix begins the names of clusters made by runs.
+	clusterPrefix = "ci-cluster"
+
+	// sep separates the parts of names.
+	sep = "-"
+
+	// timeBase is the base times are encoded in.
+	timeBase = 36
+)
+
+// firstName is before any name was made, so words which happen to be valid numbers aren't taken as times.
+var firstName = time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Name is a name traceable to the job which made it.
+type Name struct {
+	// Prefix describes what's named, such as 'ci-cluster-4-1-0'. It may be empty.
+	Prefix string
+
+	// Job is the sanitized ID of the job which made the name.
+	Job string
+
+	// Created is when the name was made, to the second.
+	Created time.Time
+
+	// Nonce keeps names made by the same job in the same second apart.
+	Nonce string
+}
+
+// New returns a name beginning with prefix made now by job, ending with nonce.
+func New(prefix, job, nonce string) Name {
+	return Name{
+		Prefix:  prefix,
+		Job:     SanitizeJob(job),
+		Created: time.Now().UTC().Truncate(time.Second),
+		Nonce:   nonce,
+	}
+}
+
+// SanitizeJob returns the lowercase letters and digits of job, shortened to keep names valid DNS labels. It's
+// LocalJob if job has none.
+func SanitizeJob(job string) string {
+	var b strings.Builder
+	for _, c := range strings.ToLower(job) {
+		if (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') {
+			b.WriteRune(c)
+		}
+	}
+	sanitized := b.String()
+	if len(sanitized) > maxJobLength {
+		// the end of IDs changes between builds of the same job
+		sanitized = sanitized[len(sanitized)-maxJobLength:]
+	} else if sanitized == "" {
+		sanitized = LocalJob
+	}
+	return sanitized
+}
+
+// Suffix is the name without its prefix.
+func (n Name) Suffix() string {
+	return strings.Join([]string{n.Job, strconv.FormatInt(n.Created.Unix(), timeBase), n.Nonce}, sep)
+}
+
+func (n Name) String() string {
+	if n.Prefix == "" {
+		return n.Suffix()
+	}
+	return n.Prefix + sep + n.Suffix()
+}
+
+// ClusterName returns the name of a cluster of version made by the run with suffix, such as
+// 'ci-cluster-4-1-0-1234567890-pzk3x0-a1b'. The version is left out when it would make the name longer than a DNS
+// label, such as for nightlies, so the suffix tracing the cluster to its job is kept whole.
+func ClusterName(version, suffix string) string {
+	name := strings.Join([]string{clusterPrefix, strings.Replace(version, ".", sep, -1), suffix}, sep)
+	if version == "" || len(name) > MaxLabelLength {
+		name = clusterPrefix + sep + suffix
+	}
+	return name
+}
+
+// Parse returns the job and creation time of name, which must have been made by New, possibly with more prefixed to
+// it since. It's the reverse of String.
+func Parse(name string) (Name, error) {
+	parts := strings.Split(name, sep)
+	if len(parts) < 3 {
+		return Name{}, fmt.Errorf("'%s' doesn't end with a job, time, and nonce", name)
+	}
+	parts, n := parts[:len(parts)-3], parts[len(parts)-3:]
+
+	created, err := strconv.ParseInt(n[1], timeBase, 64)
+	if err != nil || created < firstName.Unix() || created > time.Now().Add(24*time.Hour).Unix() {
+		return Name{}, fmt.Errorf("'%s' of '%s' isn't a time names were made", n[1], name)
+	}
+	if n[0] == "" || n[2] == "" || SanitizeJob(n[0]) != n[0] {
+		return Name{}, fmt.Errorf("'%s' isn't a traceable name", name)
+	}
+
+	return Name{
+		Prefix:  strings.Join(parts, sep),
+		Job:     n[0],
+		Created: time.Unix(created, 0).UTC(),
+		Nonce:   n[2],
+	}, nil
+}
@@ -0,0 +1,88 @@
+package naming
+
+import (
+	"testing"
+	"time"
+)
+
+func TestName(t *testing.T) {
+	n := Name{
+		Prefix:  "ci-cluster-4-1-0",
+		Job:     "1234567890",
+		Created: time.Date(2019, 8, 1, 12, 0, 0, 0, time.UTC),
+		Nonce:   "a1b",
+	}
+	if s := n.String(); s != "ci-cluster-4-1-0-1234567890-pvk400-a1b" {
+		t.Errorf("unexpected name '%s'", s)
+	}
+
+	parsed, err := Parse(n.String())
+	if err != nil {
+		t.Fatalf("failed to parse name: %v", err)
+	}
+	if parsed != n {
+		t.Errorf("expected %+v, got %+v", n, parsed)
+	}
+
+	// names may be prefixed again, such as namespaces of tenants
+	if parsed, err = Parse("osde2e-team-" + n.Suffix()); err != nil || parsed.Prefix != "osde2e-team" ||
+		parsed.Job != n.Job {
+		t.Errorf("expected job of prefixed name to be %s, got %+v: %v", n.Job, parsed, err)
+	}
+
+	for _, invalid := range []string{"", "ci-cluster-abc", "ci-cluster-job-!!-a1b", "job--a1b", "ci-cluster-Job-pvk400-a1b"} {
+		if _, err = Parse(invalid); err == nil {
+			t.Errorf("expected '%s' not to parse", invalid)
+		}
+	}
+}
+
+func TestClusterName(t *testing.T) {
+	suffix := Name{Job: SanitizeJob("1234567890123456789"), Created: time.Date(2019, 8, 1, 12, 0, 0, 0, time.UTC),
+		Nonce: "a1b"}.Suffix()
+	for version, expected := range map[string]string{
+		"4-1-0":                             "ci-cluster-4-1-0-1234567890123456789-pvk400-a1b",
+		"4.2.0-0.nightly-2019-09-23-115152": "ci-cluster-1234567890123456789-pvk400-a1b",
+		"":                                  "ci-cluster-1234567890123456789-pvk400-a1b",
+	} {
+		name := ClusterName(version, suffix)
+		if name != expected {
+			t.Errorf("expected cluster name of %s to be '%s', got '%s'", version, expected, name)
+		} else if len(name) > MaxLabelLength {
+			t.Errorf("expected '%s' to fit in a DNS label", name)
+		}
+		if parsed, err := Parse(name); err != nil || parsed.Suffix() != suffix {
+			t.Errorf("expected '%s' to be traceable, got %+v: %v", name, parsed, err)
+		}
+	}
+
+	// the longest suffixes leave room for the prefix
+	longest := Name{Job: SanitizeJob("a123456789012345678901234567890"), Created: time.Now(), Nonce: "a1b"}.Suffix()
+	if name := ClusterName("4.2.0-0.nightly-2019-09-23-115152", longest); len(name) > MaxLabelLength {
+		t.Errorf("expected '%s' to fit in a DNS label", name)
+	}
+}
+
+func TestNew(t *testing.T) {
+	before := time.Now().Add(-time.Second)
+	n := New("osde2e", "", "xyz12")
+	if n.Job != LocalJob || n.Created.Before(before) || n.Created.After(time.Now()) {
+		t.Errorf("expected local name made now, got %+v", n)
+	}
+	if parsed, err := Parse(n.String()); err != nil || parsed != n {
+		t.Errorf("expected %+v, got %+v: %v", n, parsed, err)
+	}
+}
+
+func TestSanitizeJob(t *testing.T) {
+	for job, expected := range map[string]string{
+		"1157876497614360576":           "1157876497614360576",
+		"osde2e-prod-4.1/12":            "osde2eprod4112",
+		"jenkins-OSDE2E-periodic-12345": "sosde2eperiodic12345",
+		"---":                           LocalJob,
+	} {
+		if actual := SanitizeJob(job); actual != expected {
+			t.Errorf("expected job '%s' sanitized to '%s', got '%s'", job, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+// Package netperf measures throughput and latency between pods with iperf3 and compares them to the results of
+// previous runs on the same cloud and instance type, so network performance regressions are caught.
+package netperf
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/openshift/osde2e/pkg/metrics"
+)
+
+// Paths traffic is measured over.
+const (
+	// PodToPod is traffic sent directly between pods on nodes in the same zone.
+	PodToPod = "pod-to-pod"
+
+	// PodToService is traffic sent to a pod through the cluster IP of its service.
+	PodToService = "pod-to-service"
+
+	// CrossAZ is traffic sent directly between pods on nodes in different zones.
+	CrossAZ = "cross-az"
+)
+
+// Metrics recorded for each path.
+const (
+	ThroughputMetric = "netperf_throughput_bits_per_second"
+	RTTMetric        = "netperf_rtt_seconds"
+)
+
+// Labels of samples, which decide which previous results a sample is compared to.
+const (
+	PathLabel         = "path"
+	CloudLabel        = "cloud"
+	InstanceTypeLabel = "instance-type"
+)
+
+// Measurement is the performance of traffic over a path.
+type Measurement struct {
+	Path         string
+	Cloud        string
+	InstanceType string
+
+	// Throughput is the bits per second received.
+	Throughput float64
+
+	// RTT is the mean round trip time of the sender's TCP connections.
+	RTT time.Duration
+}
+
+// iperfResult is the part of iperf3's JSON output used for measurements.
+type iperfResult struct {
+	Error string `json:"error"`
+	End   struct {
+		Streams []struct {
+			Sender struct {
+				// MeanRTT is in microseconds.
+				MeanRTT int64 `json:"mean_rtt"`
+			} `json:"sender"`
+		} `json:"streams"`
+		SumReceived struct {
+			BitsPerSecond float64 `json:"bits_per_second"`
+		} `json:"sum_received"`
+	} `json:"end"`
+}
+
+// ParseIperf reads the throughput and mean round trip time from the JSON output of an iperf3 TCP client.
+func ParseIperf(data []byte) (throughput float64, rtt time.Duration, err error) {
+	var result iperfResult
+	if err = json.Unmarshal(data, &result); err != nil {
+		return 0, 0, fmt.Errorf("couldn't decode iperf3 output: %v", err)
+	} else if result.Error != "" {
+		return 0, 0, fmt.Errorf("iperf3 failed: %s", result.Error)
+	} else if len(result.End.Streams) == 0 {
+		return 0, 0, fmt.Errorf("iperf3 output has no streams")
+	}
+
+	var total int64
+	for _, s := range result.End.Streams {
+		total += s.Sender.MeanRTT
+	}
+	rtt = time.Duration(total/int64(len(result.End.Streams))) * time.Microsecond
+	return result.End.SumReceived.BitsPerSecond, rtt, nil
+}
+
+// Samples converts measurements into the series of a snapshot.
+func Samples(measurements []Measurement) map[string][]metrics.Sample {
+	samples := map[string][]metrics.Sample{}
+	for _, m := range measurements {
+		labels := map[string]string{
+			PathLabel:         m.Path,
+			CloudLabel:        m.Cloud,
+			InstanceTypeLabel: m.InstanceType,
+		}
+		samples[ThroughputMetric] = append(samples[ThroughputMetric], metrics.Sample{Labels: labels, Value: m.Throughput})
+		samples[RTTMetric] = append(samples[RTTMetric], metrics.Sample{Labels: labels, Value: m.RTT.Seconds()})
+	}
+	return samples
+}
+
+// History is the values of each series in previous snapshots, by metric.
+type History map[string]map[string][]float64
+
+// Add records the series of s.
+func (h History) Add(s *metrics.Snapshot) {
+	for metric, samples := range s.Metrics {
+		if h[metric] == nil {
+			h[metric] = map[string][]float64{}
+		}
+		for _, sample := range samples {
+			series := sample.Series()
+			h[metric][series] = append(h[metric][series], sample.Value)
+		}
+	}
+}
+
+// Baseline returns the median of previous values of series of metric and how many there were.
+func (h History) Baseline(metric, series string) (float64, int) {
+	values := append([]float64{}, h[metric][series]...)
+	if len(values) == 0 {
+		return 0, 0
+	}
+
+	sort.Float64s(values)
+	mid := len(values) / 2
+	if len(values)%2 == 0 {
+		return (values[mid-1] + values[mid]) / 2, len(values)
+	}
+	return values[mid], len(values)
+}
+
+// Regression is a series which performed worse than its baseline.
+type Regression struct {
+	Metric string
+	Series string
+
+	Baseline, Value float64
+
+	// Samples is the number of previous values the baseline is the median of.
+	Samples int
+}
+
+// Percent returns how much worse Value is than Baseline.
+func (r Regression) Percent() float64 {
+	return math.Abs(r.Value-r.Baseline) / r.Baseline * 100
+}
+
+func (r Regression) String() string {
+	return fmt.Sprintf("%s%s is %.4g, %.1f%% worse than the median of %.4g over %d previous runs",
+		r.Metric, r.Series, r.Value, r.Percent(), r.Baseline, r.Samples)
+}
+
+// Regressions returns the series of s which are more than maxPercent worse than their baseline in h: lower for
+// throughput, higher for round trip time. Series with fewer than minSamples previous values aren't compared.
+func (h History) Regressions(s *metrics.Snapshot, maxPercent float64, minSamples int) (regressions []Regression) {
+	for _, metric := range []string{ThroughputMetric, RTTMetric} {
+		for _, sample := range s.Metrics[metric] {
+			series := sample.Series()
+			baseline, n := h.Baseline(metric, series)
+			if n == 0 || n < minSamples || baseline <= 0 {
+				continue
+			}
+
+			r := Regression{Metric: metric, Series: series, Baseline: baseline, Value: sample.Value, Samples: n}
+			worse := sample.Value < baseline
+			if metric == RTTMetric {
+				worse = sample.Value > baseline
+			}
+			if worse && r.Percent() > maxPercent {
+				regressions = append(regressions, r)
+			}
+		}
+	}
+	return
+}
@@ -0,0 +1,89 @@
+package netperf
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift/osde2e/pkg/metrics"
+)
+
+const testIperfOutput = `{
+  "start": {"connected": [{"socket": 5}]},
+  "end": {
+    "streams": [
+      {"sender": {"bytes": 5000000000, "max_rtt": 900, "min_rtt": 100, "mean_rtt": 400}},
+      {"sender": {"bytes": 5000000000, "max_rtt": 1200, "min_rtt": 200, "mean_rtt": 600}}
+    ],
+    "sum_sent": {"bits_per_second": 4.1e9},
+    "sum_received": {"bits_per_second": 4.0e9}
+  }
+}`
+
+func TestParseIperf(t *testing.T) {
+	throughput, rtt, err := ParseIperf([]byte(testIperfOutput))
+	if err != nil {
+		t.Fatalf("failed to parse iperf3 output: %v", err)
+	}
+	if throughput != 4e9 || rtt != 500*time.Microsecond {
+		t.Errorf("expected 4e9 bits/s and 500µs, got %g and %v", throughput, rtt)
+	}
+
+	for _, output := range []string{
+		`{"error": "unable to connect to server: Connection refused"}`,
+		`{"end": {}}`,
+		`iperf3: error`,
+	} {
+		if _, _, err = ParseIperf([]byte(output)); err == nil {
+			t.Errorf("expected an error parsing '%s'", output)
+		}
+	}
+}
+
+func TestRegressions(t *testing.T) {
+	snapshot := func(throughput float64, rtt time.Duration, path string) *metrics.Snapshot {
+		return &metrics.Snapshot{Metrics: Samples([]Measurement{
+			{Path: path, Cloud: "aws", InstanceType: "m5.xlarge", Throughput: throughput, RTT: rtt},
+		})}
+	}
+
+	history := History{}
+	for _, s := range []*metrics.Snapshot{
+		snapshot(4e9, 400*time.Microsecond, PodToPod),
+		snapshot(5e9, 500*time.Microsecond, PodToPod),
+		snapshot(6e9, 900*time.Microsecond, PodToPod),
+		snapshot(1e9, time.Millisecond, CrossAZ),
+	} {
+		history.Add(s)
+	}
+
+	current := snapshot(3e9, time.Millisecond, PodToPod)
+	for metric, samples := range snapshot(1e8, time.Second, CrossAZ).Metrics {
+		current.Metrics[metric] = append(current.Metrics[metric], samples...)
+	}
+	for metric, samples := range snapshot(1e8, time.Second, PodToService).Metrics {
+		current.Metrics[metric] = append(current.Metrics[metric], samples...)
+	}
+
+	regressions := history.Regressions(current, 25, 3)
+	if len(regressions) != 2 {
+		t.Fatalf("expected throughput and latency of pod-to-pod to regress, got %v", regressions)
+	}
+	if r := regressions[0]; r.Metric != ThroughputMetric || r.Baseline != 5e9 || r.Samples != 3 || r.Percent() != 40 {
+		t.Errorf("unexpected throughput regression %v", r)
+	} else if !strings.Contains(r.Series, `path="pod-to-pod"`) || !strings.Contains(r.String(), "40.0% worse") {
+		t.Errorf("unexpected throughput regression %s", r)
+	}
+	if r := regressions[1]; r.Metric != RTTMetric || r.Percent() != 100 {
+		t.Errorf("unexpected latency regression %v", r)
+	}
+
+	// cross-az has too few previous results and pod-to-service has none
+	if regressions = history.Regressions(current, 25, 1); len(regressions) != 4 {
+		t.Errorf("expected cross-az to regress with fewer samples required, got %v", regressions)
+	}
+
+	if regressions = history.Regressions(snapshot(4.5e9, 450*time.Microsecond, PodToPod), 25, 3); len(regressions) != 0 {
+		t.Errorf("expected no regressions within 25%%, got %v", regressions)
+	}
+}
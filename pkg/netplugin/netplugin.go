@@ -0,0 +1,77 @@
+// Package netplugin describes the network plugins clusters can be installed with, such as OpenShiftSDN and
+// OVNKubernetes, and what a cluster running each is expected to serve so jobs for every plugin share checks.
+package netplugin
+
+import (
+	"fmt"
+	"sort"
+
+	appsv1 "k8s.io/api/apps/v1"
+
+	"github.com/openshift/osde2e/pkg/config"
+)
+
+// Plugin is what a cluster running a network plugin is expected to serve.
+type Plugin struct {
+	// Type is the network type of the plugin in the cluster's Network config.
+	Type string
+
+	// Namespace runs the plugin's components.
+	Namespace string
+
+	// DaemonSets run the plugin's components on nodes.
+	DaemonSets []string
+
+	// APIs are the resources served by the plugin, by group version.
+	APIs map[string][]string
+}
+
+// Plugins are the network plugins clusters can be installed with, by network type.
+var Plugins = map[string]Plugin{
+	config.NetworkOpenShiftSDN: {
+		Type:       config.NetworkOpenShiftSDN,
+		Namespace:  "openshift-sdn",
+		DaemonSets: []string{"sdn"},
+		APIs: map[string][]string{
+			"network.openshift.io/v1": {"clusternetworks", "hostsubnets", "netnamespaces", "egressnetworkpolicies"},
+		},
+	},
+	config.NetworkOVNKubernetes: {
+		Type:       config.NetworkOVNKubernetes,
+		Namespace:  "openshift-ovn-kubernetes",
+		DaemonSets: []string{"ovnkube-node"},
+		APIs: map[string][]string{
+			"k8s.ovn.org/v1": {"egressfirewalls", "egressips"},
+		},
+	},
+}
+
+// Get returns the plugin of networkType.
+func Get(networkType string) (Plugin, error) {
+	if p, ok := Plugins[networkType]; ok {
+		return p, nil
+	}
+
+	var types []string
+	for t := range Plugins {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return Plugin{}, fmt.Errorf("unknown network type '%s', must be one of %v", networkType, types)
+}
+
+// DaemonSetProblems returns why ds isn't running an up to date, ready pod on every node it's scheduled to.
+func DaemonSetProblems(ds *appsv1.DaemonSet) (problems []string) {
+	s := ds.Status
+	if s.DesiredNumberScheduled == 0 {
+		problems = append(problems, fmt.Sprintf("%s isn't scheduled to any nodes", ds.Name))
+	}
+	if s.NumberReady < s.DesiredNumberScheduled {
+		problems = append(problems, fmt.Sprintf("%s has %d of %d pods ready", ds.Name, s.NumberReady, s.DesiredNumberScheduled))
+	}
+	if s.UpdatedNumberScheduled < s.DesiredNumberScheduled {
+		problems = append(problems, fmt.Sprintf("%s has %d of %d pods updated", ds.Name, s.UpdatedNumberScheduled,
+			s.DesiredNumberScheduled))
+	}
+	return
+}
@@ -0,0 +1,51 @@
+package netplugin
+
+import (
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openshift/osde2e/pkg/config"
+)
+
+func TestGet(t *testing.T) {
+	for _, networkType := range []string{config.NetworkOpenShiftSDN, config.NetworkOVNKubernetes} {
+		if p, err := Get(networkType); err != nil {
+			t.Errorf("network type '%s' should be known: %v", networkType, err)
+		} else if p.Type != networkType || p.Namespace == "" || len(p.DaemonSets) == 0 {
+			t.Errorf("expected plugin of '%s' to be described, got %+v", networkType, p)
+		}
+	}
+
+	if _, err := Get("Calico"); err == nil {
+		t.Error("expected error for unknown network type")
+	}
+}
+
+func TestDaemonSetProblems(t *testing.T) {
+	ds := func(desired, ready, updated int32) *appsv1.DaemonSet {
+		return &appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "ovnkube-node"},
+			Status: appsv1.DaemonSetStatus{
+				DesiredNumberScheduled: desired,
+				NumberReady:            ready,
+				UpdatedNumberScheduled: updated,
+			},
+		}
+	}
+
+	for _, tc := range []struct {
+		ds       *appsv1.DaemonSet
+		expected []string
+	}{
+		{ds(6, 6, 6), nil},
+		{ds(0, 0, 0), []string{"ovnkube-node isn't scheduled to any nodes"}},
+		{ds(6, 5, 4), []string{"ovnkube-node has 5 of 6 pods ready", "ovnkube-node has 4 of 6 pods updated"}},
+	} {
+		if problems := DaemonSetProblems(tc.ds); !reflect.DeepEqual(problems, tc.expected) {
+			t.Errorf("expected problems %v, got %v", tc.expected, problems)
+		}
+	}
+}
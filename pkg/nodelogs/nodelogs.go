@@ -0,0 +1,149 @@
+// Package nodelogs collects kernel and kubelet logs from nodes and reports problems found in them.
+package nodelogs
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/test-infra/testgrid/metadata/junit"
+
+	"github.com/openshift/osde2e/pkg/helper"
+	"github.com/openshift/osde2e/pkg/junitprops"
+	"github.com/openshift/osde2e/pkg/logmetrics"
+)
+
+const (
+	// SuiteName is the JUnit suite containing node findings.
+	SuiteName = "OSD nodes"
+
+	// MaxLines is the most lines of journal collected from each node.
+	MaxLines = 20000
+
+	// namespace and selector of machine-config-daemon pods, which run on every node with its filesystem mounted
+	daemonNamespace = "openshift-machine-config-operator"
+	daemonSelector  = "k8s-app=machine-config-daemon"
+	daemonContainer = "machine-config-daemon"
+)
+
+// Patterns are problems found in the logs of nodes regardless of configured log metrics.
+var Patterns = []logmetrics.LogMetric{
+	{
+		Name:  "oom-kill",
+		RegEx: `Out of memory: Kill(ed)? process|oom-kill:|invoked oom-killer`,
+	},
+	{
+		Name:  "hung-task",
+		RegEx: `blocked for more than [0-9]+ seconds`,
+	},
+	{
+		Name:  "disk-pressure",
+		RegEx: `DiskPressure|No space left on device|eviction manager: .*(nodefs|imagefs|ephemeral-storage)`,
+	},
+}
+
+// Collect returns the kernel and kubelet journal of each node since start, by node name.
+func Collect(h *helper.H, start time.Time) (map[string][]byte, error) {
+	pods, err := h.Kube().CoreV1().Pods(daemonNamespace).List(metav1.ListOptions{
+		LabelSelector: daemonSelector,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("couldn't list machine-config-daemon pods: %v", err)
+	}
+
+	since := strconv.Itoa(int(time.Since(start).Minutes()) + 1)
+	cmd := []string{"chroot", "/rootfs", "journalctl", "--no-pager", "--quiet", "--output=short-iso",
+		"--since=-" + since + "min", "--lines=" + strconv.Itoa(MaxLines),
+		"_TRANSPORT=kernel", "+", "_SYSTEMD_UNIT=kubelet.service"}
+
+	logs := make(map[string][]byte, len(pods.Items))
+	for _, pod := range pods.Items {
+		if pod.Spec.NodeName == "" {
+			continue
+		}
+
+		result, err := h.Exec(pod.Namespace, pod.Name, daemonContainer, cmd...)
+		if err != nil {
+			log.Printf("Failed to collect journal of node '%s': %v", pod.Spec.NodeName, err)
+			continue
+		}
+		logs[pod.Spec.NodeName] = result.Stdout
+	}
+
+	if len(logs) == 0 && len(pods.Items) != 0 {
+		return nil, fmt.Errorf("couldn't collect journal of any of %d nodes", len(pods.Items))
+	}
+	return logs, nil
+}
+
+// Finding is the result of a metric for a node.
+type Finding struct {
+	Node string
+	logmetrics.Result
+}
+
+// Name identifies the finding in results.
+func (f Finding) Name() string {
+	return fmt.Sprintf("[node] %s %s", f.Node, f.Metric.Name)
+}
+
+// Analyze runs engine over the logs of each node, returning findings sorted by node.
+func Analyze(engine *logmetrics.Engine, logs map[string][]byte) (findings []Finding) {
+	nodes := make([]string, 0, len(logs))
+	for node := range logs {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+
+	for _, node := range nodes {
+		for _, r := range engine.Analyze(logs[node]) {
+			findings = append(findings, Finding{Node: node, Result: r})
+		}
+	}
+	return
+}
+
+// WriteMetrics writes the count of each finding to w in the Prometheus text format, labelled with its node.
+func WriteMetrics(w io.Writer, findings []Finding) error {
+	var samples []logmetrics.Sample
+	for _, f := range findings {
+		samples = append(samples, f.Samples(map[string]string{"node": f.Node})...)
+	}
+	return logmetrics.WritePrometheus(w, samples)
+}
+
+// WriteJUnit records a testcase for each finding in dir, failing those outside the thresholds of their metric.
+func WriteJUnit(dir, suffix string, findings []Finding) error {
+	suite := junit.Suite{
+		Name:  SuiteName,
+		Tests: len(findings),
+	}
+	for _, f := range findings {
+		result := junit.Result{
+			Name:      f.Name(),
+			ClassName: SuiteName,
+		}
+		if !f.Passed() {
+			msg := fmt.Sprintf("%d lines matched '%s', expected between %d and %d",
+				f.Count, f.Metric.RegEx, f.Metric.LowThreshold, f.Metric.HighThreshold)
+			result.Failure = &msg
+			if len(f.Matches) != 0 || len(f.Series) != 0 {
+				lines := append([]string{}, f.Matches...)
+				for _, series := range f.Series {
+					lines = append(lines, fmt.Sprintf("%d matches of %s", series.Count, series))
+				}
+				output := strings.Join(lines, "\n")
+				result.Output = &output
+			}
+			suite.Failures++
+		}
+		suite.Results = append(suite.Results, result)
+	}
+
+	return junitprops.WriteSuite(dir, "nodes", suffix, suite)
+}
@@ -0,0 +1,110 @@
+package nodelogs
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/openshift/osde2e/pkg/logmetrics"
+)
+
+var testLogs = map[string][]byte{
+	"worker-b": []byte(`2019-10-01T10:00:00+0000 worker-b kernel: Out of memory: Killed process 1234 (java)
+2019-10-01T10:01:00+0000 worker-b kernel: INFO: task jbd2/nvme0n1p4-:512 blocked for more than 120 seconds.
+`),
+	"worker-a": []byte(`2019-10-01T10:00:00+0000 worker-a hyperkube[1500]: I1001 eviction_manager.go:341] eviction manager: must evict pod(s) to reclaim ephemeral-storage
+2019-10-01T10:00:01+0000 worker-a hyperkube[1500]: I1001 kubelet_node_status.go:472] Recording NodeHasDiskPressure event message for node worker-a
+`),
+	"master-0": []byte(`2019-10-01T10:00:00+0000 master-0 kernel: Linux version 4.18.0
+`),
+}
+
+func TestAnalyze(t *testing.T) {
+	engine, err := logmetrics.New(Patterns...)
+	if err != nil {
+		t.Fatalf("failed to compile patterns: %v", err)
+	}
+
+	failed := map[string]int{}
+	findings := Analyze(engine, testLogs)
+	for _, f := range findings {
+		if !f.Passed() {
+			failed[f.Node+" "+f.Metric.Name] = f.Count
+		}
+	}
+
+	if len(findings) != len(testLogs)*len(Patterns) {
+		t.Errorf("expected a finding per node and pattern, got %d", len(findings))
+	} else if findings[0].Node != "master-0" {
+		t.Errorf("expected findings sorted by node, first is '%s'", findings[0].Node)
+	}
+
+	expected := map[string]int{
+		"worker-a disk-pressure": 2,
+		"worker-b oom-kill":      1,
+		"worker-b hung-task":     1,
+	}
+	for name, count := range expected {
+		if failed[name] != count {
+			t.Errorf("expected %d matches for '%s', got %d", count, name, failed[name])
+		}
+	}
+	if len(failed) != len(expected) {
+		t.Errorf("unexpected failures: %v", failed)
+	}
+}
+
+func TestWriteJUnit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nodelogs")
+	if err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	engine, err := logmetrics.New(Patterns...)
+	if err != nil {
+		t.Fatalf("failed to compile patterns: %v", err)
+	}
+
+	if err = WriteJUnit(dir, "abc", Analyze(engine, testLogs)); err != nil {
+		t.Fatalf("failed to write JUnit: %v", err)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "junit_nodes_abc.xml"))
+	if err != nil {
+		t.Fatalf("failed to read JUnit: %v", err)
+	}
+
+	junit := string(data)
+	for _, s := range []string{`failures="3"`, `name="[node] worker-b oom-kill"`, "Killed process 1234"} {
+		if !strings.Contains(junit, s) {
+			t.Errorf("expected JUnit to contain '%s':\n%s", s, junit)
+		}
+	}
+}
+
+func TestWriteMetrics(t *testing.T) {
+	engine, err := logmetrics.New(Patterns...)
+	if err != nil {
+		t.Fatalf("failed to compile patterns: %v", err)
+	}
+
+	var b bytes.Buffer
+	if err = WriteMetrics(&b, Analyze(engine, testLogs)); err != nil {
+		t.Fatalf("failed to write metrics: %v", err)
+	}
+
+	metrics := b.String()
+	for _, s := range []string{
+		`osde2e_log_metric_matches{metric="oom-kill",node="worker-b"} 1`,
+		`osde2e_log_metric_matches{metric="disk-pressure",node="worker-a"} 2`,
+		`osde2e_log_metric_matches{metric="oom-kill",node="master-0"} 0`,
+	} {
+		if !strings.Contains(metrics, s) {
+			t.Errorf("expected metrics to contain '%s':\n%s", s, metrics)
+		}
+	}
+}
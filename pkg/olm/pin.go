@@ -0,0 +1,198 @@
+// Package olm manages operators installed by the Operator Lifecycle Manager.
+package olm
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/openshift/osde2e/pkg/helper"
+)
+
+const (
+	// Group is the API group of OLM resources.
+	Group = "operators.coreos.com"
+
+	// Version is the API version of OLM resources.
+	Version = "v1alpha1"
+
+	// CSVSucceeded is the phase of a ClusterServiceVersion that has been installed.
+	CSVSucceeded = "Succeeded"
+
+	// ManualApproval requires InstallPlans to be approved before operators are installed or upgraded.
+	ManualApproval = "Manual"
+)
+
+var (
+	// SubscriptionGVR is the resource of Subscriptions.
+	SubscriptionGVR = schema.GroupVersionResource{Group: Group, Version: Version, Resource: "subscriptions"}
+
+	// CSVGVR is the resource of ClusterServiceVersions.
+	CSVGVR = schema.GroupVersionResource{Group: Group, Version: Version, Resource: "clusterserviceversions"}
+
+	// InstallPlanGVR is the resource of InstallPlans.
+	InstallPlanGVR = schema.GroupVersionResource{Group: Group, Version: Version, Resource: "installplans"}
+)
+
+// Pin is the version of operator to install for a Subscription.
+type Pin struct {
+	Namespace    string
+	Subscription string
+
+	// CSV is the name of the ClusterServiceVersion to install, such as 'my-operator.v0.1.2'.
+	CSV string
+}
+
+func (p Pin) String() string {
+	return fmt.Sprintf("%s/%s=%s", p.Namespace, p.Subscription, p.CSV)
+}
+
+// ParsePins returns pins from a map of 'namespace/subscription' to CSV names.
+func ParsePins(versions map[string]string) ([]Pin, error) {
+	pins := make([]Pin, 0, len(versions))
+	for sub, csv := range versions {
+		parts := strings.Split(sub, "/")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("subscription '%s' should be in the form namespace/name", sub)
+		} else if csv == "" {
+			return nil, fmt.Errorf("no CSV specified for subscription '%s'", sub)
+		}
+
+		pins = append(pins, Pin{
+			Namespace:    parts[0],
+			Subscription: parts[1],
+			CSV:          csv,
+		})
+	}
+
+	sort.Slice(pins, func(i, j int) bool {
+		return pins[i].String() < pins[j].String()
+	})
+	return pins, nil
+}
+
+// PinOperator reinstalls the operator of a Subscription at the pinned CSV, waiting up to timeout for it to install.
+// The Subscription is recreated with manual approval so the operator isn't upgraded afterwards.
+func PinOperator(h *helper.H, pin Pin, timeout time.Duration) error {
+	sub, err := h.GetResource(SubscriptionGVR, pin.Namespace, pin.Subscription)
+	if err != nil {
+		return err
+	}
+
+	installed, _, _ := unstructured.NestedString(sub.Object, "status", "installedCSV")
+	if installed == pin.CSV {
+		log.Printf("Subscription '%s/%s' already has '%s' installed.", pin.Namespace, pin.Subscription, pin.CSV)
+		return nil
+	}
+	log.Printf("Replacing '%s' with '%s' for subscription '%s/%s'...", installed, pin.CSV, pin.Namespace, pin.Subscription)
+
+	// remove current operator
+	if installed != "" {
+		if err = h.DeleteResource(CSVGVR, pin.Namespace, installed); err != nil {
+			return err
+		}
+	}
+	if err = h.DeleteResource(SubscriptionGVR, pin.Namespace, pin.Subscription); err != nil {
+		return err
+	}
+
+	// recreate subscription starting at pinned CSV
+	pinned, err := pinnedSubscription(sub, pin.CSV)
+	if err != nil {
+		return err
+	}
+	if _, err = h.Dynamic().Resource(SubscriptionGVR).Namespace(pin.Namespace).Create(pinned, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("couldn't create subscription '%s/%s': %v", pin.Namespace, pin.Subscription, err)
+	}
+
+	if err = approveInstallPlan(h, pin, timeout); err != nil {
+		return err
+	}
+
+	log.Printf("Waiting for '%s' to be installed...", pin.CSV)
+	return h.PollImmediate(10*time.Second, timeout, func() (bool, error) {
+		csv, err := h.Dynamic().Resource(CSVGVR).Namespace(pin.Namespace).Get(pin.CSV, metav1.GetOptions{})
+		if kerror.IsNotFound(err) {
+			return false, nil
+		} else if err != nil {
+			log.Printf("Error getting CSV '%s': %v", pin.CSV, err)
+			return false, nil
+		}
+
+		phase, _, _ := unstructured.NestedString(csv.Object, "status", "phase")
+		return phase == CSVSucceeded, nil
+	})
+}
+
+// pinnedSubscription returns a new Subscription based on sub that starts at csv and requires manual approval.
+func pinnedSubscription(sub *unstructured.Unstructured, csv string) (*unstructured.Unstructured, error) {
+	spec, _, err := unstructured.NestedMap(sub.Object, "spec")
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get spec of subscription '%s': %v", sub.GetName(), err)
+	}
+	spec["startingCSV"] = csv
+	spec["installPlanApproval"] = ManualApproval
+
+	pinned := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": spec,
+		},
+	}
+	pinned.SetAPIVersion(sub.GetAPIVersion())
+	pinned.SetKind(sub.GetKind())
+	pinned.SetNamespace(sub.GetNamespace())
+	pinned.SetName(sub.GetName())
+	pinned.SetLabels(sub.GetLabels())
+	return pinned, nil
+}
+
+// approveInstallPlan approves the InstallPlan created for the pinned CSV.
+func approveInstallPlan(h *helper.H, pin Pin, timeout time.Duration) error {
+	log.Printf("Waiting for InstallPlan of '%s'...", pin.CSV)
+	client := h.Dynamic().Resource(InstallPlanGVR).Namespace(pin.Namespace)
+	return h.PollImmediate(10*time.Second, timeout, func() (bool, error) {
+		list, err := client.List(metav1.ListOptions{})
+		if err != nil {
+			log.Printf("Error listing InstallPlans: %v", err)
+			return false, nil
+		}
+
+		for _, plan := range list.Items {
+			csvs, _, _ := unstructured.NestedStringSlice(plan.Object, "spec", "clusterServiceVersionNames")
+			if !contains(csvs, pin.CSV) {
+				continue
+			}
+
+			if approved, _, _ := unstructured.NestedBool(plan.Object, "spec", "approved"); approved {
+				return true, nil
+			}
+
+			if err = unstructured.SetNestedField(plan.Object, true, "spec", "approved"); err != nil {
+				return false, err
+			}
+			if _, err = client.Update(&plan, metav1.UpdateOptions{}); err != nil {
+				log.Printf("Error approving InstallPlan '%s': %v", plan.GetName(), err)
+				return false, nil
+			}
+			log.Printf("Approved InstallPlan '%s' for '%s'.", plan.GetName(), pin.CSV)
+			return true, nil
+		}
+		return false, nil
+	})
+}
+
+func contains(strs []string, s string) bool {
+	for _, str := range strs {
+		if str == s {
+			return true
+		}
+	}
+	return false
+}
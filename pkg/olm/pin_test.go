@@ -0,0 +1,76 @@
+package olm
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestParsePins(t *testing.T) {
+	pins, err := ParsePins(map[string]string{
+		"openshift-operators/b-operator": "b-operator.v1.0.0",
+		"openshift-operators/a-operator": "a-operator.v0.2.1",
+	})
+	if err != nil {
+		t.Fatalf("Failed parsing pins: %v", err)
+	}
+
+	expected := []Pin{
+		{Namespace: "openshift-operators", Subscription: "a-operator", CSV: "a-operator.v0.2.1"},
+		{Namespace: "openshift-operators", Subscription: "b-operator", CSV: "b-operator.v1.0.0"},
+	}
+	if !reflect.DeepEqual(pins, expected) {
+		t.Errorf("expected pins %v, got %v", expected, pins)
+	}
+
+	for _, invalid := range []map[string]string{
+		{"a-operator": "a-operator.v0.2.1"},
+		{"/a-operator": "a-operator.v0.2.1"},
+		{"openshift-operators/a-operator": ""},
+	} {
+		if _, err = ParsePins(invalid); err == nil {
+			t.Errorf("pins %v should be invalid", invalid)
+		}
+	}
+}
+
+func TestPinnedSubscription(t *testing.T) {
+	sub := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "operators.coreos.com/v1alpha1",
+			"kind":       "Subscription",
+			"metadata": map[string]interface{}{
+				"name":            "a-operator",
+				"namespace":       "openshift-operators",
+				"resourceVersion": "42",
+			},
+			"spec": map[string]interface{}{
+				"channel": "stable",
+				"name":    "a-operator",
+			},
+			"status": map[string]interface{}{
+				"installedCSV": "a-operator.v0.3.0",
+			},
+		},
+	}
+
+	pinned, err := pinnedSubscription(sub, "a-operator.v0.2.1")
+	if err != nil {
+		t.Fatalf("Failed pinning subscription: %v", err)
+	}
+
+	if pinned.GetResourceVersion() != "" || pinned.Object["status"] != nil {
+		t.Errorf("pinned subscription shouldn't include server set fields: %v", pinned.Object)
+	}
+
+	expected := map[string]interface{}{
+		"channel":             "stable",
+		"name":                "a-operator",
+		"startingCSV":         "a-operator.v0.2.1",
+		"installPlanApproval": ManualApproval,
+	}
+	if spec := pinned.Object["spec"]; !reflect.DeepEqual(spec, expected) {
+		t.Errorf("expected spec %v, got %v", expected, spec)
+	}
+}
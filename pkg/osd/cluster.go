@@ -1,66 +1,207 @@
 package osd
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/openshift-online/uhc-sdk-go/pkg/client/clustersmgmt/v1"
-	"k8s.io/apimachinery/pkg/util/wait"
 
 	"github.com/openshift/osde2e/pkg/config"
+	"github.com/openshift/osde2e/pkg/netplugin"
 )
 
 const (
 	// DefaultFlavour is used when no specialized configuration exists.
 	DefaultFlavour = "4"
+
+	// clustersPath is the OSD API collection of clusters.
+	clustersPath = "/api/clusters_mgmt/" + APIVersion + "/clusters"
+
+	// listPageSize is how many items are requested per page when listing.
+	listPageSize = 100
+
+	// DefaultARM64MachineType is the instance type of arm64 compute nodes when none is set.
+	DefaultARM64MachineType = "m6g.xlarge"
+
+	// multiArchSuffix ends the IDs of multi-architecture release versions.
+	multiArchSuffix = "-multi"
 )
 
 // LaunchCluster setups an new cluster using the OSD API and returns it's ID.
 func (u *OSD) LaunchCluster(cfg *config.Config) (string, error) {
 	log.Printf("Creating cluster '%s'...", cfg.ClusterName)
 
+	// Calculate an expiration date for the cluster so that it will be automatically deleted if
+	// we happen to forget to do it:
+	expiration := time.Now().Add(cfg.ClusterExpiry)
+
+	var data []byte
+	var err error
+	if cfg.ClusterTemplate != "" {
+		if data, err = RenderClusterTemplate(cfg.ClusterTemplate, cfg, expiration); err != nil {
+			return "", err
+		}
+		if err = u.ValidateCluster(data); err != nil {
+			return "", fmt.Errorf("invalid cluster template '%s': %v", cfg.ClusterTemplate, err)
+		}
+	} else if data, err = u.clusterBody(cfg, expiration); err != nil {
+		return "", err
+	}
+
+	var created struct {
+		ID string `json:"id"`
+	}
+	if _, err = u.send(u.conn.Post().Path(clustersPath).Bytes(data), &created); err != nil {
+		return "", fmt.Errorf("couldn't create cluster: %v", err)
+	}
+	u.launched = created.ID
+	return created.ID, nil
+}
+
+// clusterBody describes the cluster configured by cfg, expiring at expiration.
+func (u *OSD) clusterBody(cfg *config.Config, expiration time.Time) ([]byte, error) {
 	// choose flavour based on config
 	flavourID := u.Flavour(cfg)
 
-	// Calculate an expiration date for the cluster so that it will be automatically deleted if
-	// we happen to forget to do it:
-	expiration := time.Now().Add(8 * time.Hour)
+	// clusters which aren't amd64 are installed from multi-architecture releases
+	version, machineType, err := architectureOptions(cfg)
+	if err != nil {
+		return nil, err
+	}
 
-	cluster, err := v1.NewCluster().
+	if cfg.NetworkType != "" {
+		if _, err = netplugin.Get(cfg.NetworkType); err != nil {
+			return nil, err
+		}
+	}
+
+	builder := v1.NewCluster().
 		Name(cfg.ClusterName).
 		Flavour(v1.NewFlavour().
 			ID(flavourID)).
 		Region(v1.NewCloudRegion().
-			ID("us-east-1")).
+			ID(cfg.Region)).
 		MultiAZ(cfg.MultiAZ).
 		Version(v1.NewVersion().
-			ID(cfg.ClusterVersion)).
-		ExpirationTimestamp(expiration).
-		Build()
-	if err != nil {
-		return "", fmt.Errorf("couldn't build cluster description: %v", err)
+			ID(version)).
+		ExpirationTimestamp(expiration)
+
+	if len(cfg.ClusterProperties) != 0 {
+		builder = builder.Properties(cfg.ClusterProperties)
 	}
 
-	resp, err := u.clusters().Add().
-		Body(cluster).
-		Send()
+	// the flavour's node count is used unless one is set
+	if cfg.ComputeNodes > 0 {
+		builder = builder.Nodes(v1.NewClusterNodes().
+			Compute(cfg.ComputeNodes))
+	}
 
-	if resp != nil {
-		err = errResp(resp.Error())
+	cluster, err := builder.Build()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't build cluster description: %v", err)
 	}
 
+	data, err := encodeCluster(cluster, bodyOptions{
+		CloudProvider: cfg.CloudProvider,
+		MachineType:   machineType,
+		NetworkType:   cfg.NetworkType,
+		Hypershift:    cfg.Hypershift,
+	})
 	if err != nil {
-		return "", fmt.Errorf("couldn't create cluster: %v", err)
+		return nil, fmt.Errorf("couldn't encode cluster description: %v", err)
+	}
+	return data, nil
+}
+
+// architectureOptions returns the version and compute machine type to create a cluster of cfg's architecture with.
+func architectureOptions(cfg *config.Config) (version, machineType string, err error) {
+	version, machineType = cfg.ClusterVersion, cfg.ComputeMachineType
+	switch cfg.ComputeArchitecture {
+	case "", config.ArchitectureAMD64:
+		return
+	case config.ArchitectureARM64:
+		if machineType == "" {
+			machineType = DefaultARM64MachineType
+		}
+	case config.ArchitectureMulti:
+	default:
+		return "", "", fmt.Errorf("unknown architecture '%s', must be %s, %s, or %s", cfg.ComputeArchitecture,
+			config.ArchitectureAMD64, config.ArchitectureARM64, config.ArchitectureMulti)
 	}
-	return resp.Body().ID(), nil
+
+	if !strings.HasSuffix(version, multiArchSuffix) {
+		version += multiArchSuffix
+	}
+	return
+}
+
+// bodyOptions are set in cluster bodies when they aren't empty.
+// TODO: use uhc-sdk-go cloud provider ID, compute_machine_type, network type, and hypershift when available
+type bodyOptions struct {
+	// CloudProvider is the cloud the cluster is created in.
+	CloudProvider string
+
+	// MachineType is the instance type of compute nodes.
+	MachineType string
+
+	// NetworkType is the network plugin.
+	NetworkType string
+
+	// Hypershift hosts the control plane of the cluster on a management cluster.
+	Hypershift bool
+}
+
+// encodeCluster encodes cluster with opts set.
+func encodeCluster(cluster *v1.Cluster, opts bodyOptions) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := v1.MarshalCluster(cluster, &buf); err != nil {
+		return nil, err
+	} else if opts == (bodyOptions{}) {
+		return buf.Bytes(), nil
+	}
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &body); err != nil {
+		return nil, err
+	}
+	if opts.CloudProvider != "" {
+		provider := bodyObject(body, "cloud_provider")
+		provider["id"] = opts.CloudProvider
+	}
+	if opts.MachineType != "" {
+		nodes := bodyObject(body, "nodes")
+		nodes["compute_machine_type"] = map[string]interface{}{"id": opts.MachineType}
+	}
+	if opts.NetworkType != "" {
+		network := bodyObject(body, "network")
+		network["type"] = opts.NetworkType
+	}
+	if opts.Hypershift {
+		hypershift := bodyObject(body, "hypershift")
+		hypershift["enabled"] = true
+	}
+	return json.Marshal(body)
+}
+
+// bodyObject returns the object in body named name, adding it if it's missing.
+func bodyObject(body map[string]interface{}, name string) map[string]interface{} {
+	obj, _ := body[name].(map[string]interface{})
+	if obj == nil {
+		obj = map[string]interface{}{}
+		body[name] = obj
+	}
+	return obj
 }
 
 // GetCluster returns the information about clusterID.
 func (u *OSD) GetCluster(clusterID string) (*v1.Cluster, error) {
 	resp, err := u.cluster(clusterID).
 		Get().
-		Send()
+		SendContext(u.context())
 
 	if resp != nil {
 		err = errResp(resp.Error())
@@ -91,7 +232,7 @@ func (u *OSD) ClusterKubeconfig(clusterID string) (kubeconfig []byte, err error)
 	resp, err := u.cluster(clusterID).
 		Credentials().
 		Get().
-		Send()
+		SendContext(u.context())
 
 	if resp != nil {
 		err = errResp(resp.Error())
@@ -107,7 +248,7 @@ func (u *OSD) ClusterKubeconfig(clusterID string) (kubeconfig []byte, err error)
 func (u *OSD) DeleteCluster(clusterID string) error {
 	resp, err := u.cluster(clusterID).
 		Delete().
-		Send()
+		SendContext(u.context())
 
 	if resp != nil {
 		err = errResp(resp.Error())
@@ -119,19 +260,83 @@ func (u *OSD) DeleteCluster(clusterID string) error {
 	return nil
 }
 
-// WaitForClusterReady blocks until clusterID is ready or a number of retries has been attempted.
-func (u *OSD) WaitForClusterReady(clusterID string, timeout time.Duration) error {
+// ListClusters returns every cluster matching search, such as "name like 'osde2e-%'".
+func (u *OSD) ListClusters(search string) ([]*v1.Cluster, error) {
+	var clusters []*v1.Cluster
+	for page := 1; ; page++ {
+		resp, err := u.clusters().List().
+			Search(search).
+			Page(page).
+			Size(listPageSize).
+			SendContext(u.context())
+
+		if resp != nil {
+			err = errResp(resp.Error())
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("couldn't list clusters matching \"%s\": %v", search, err)
+		}
+
+		clusters = append(clusters, resp.Items().Slice()...)
+		if resp.Items().Len() < listPageSize || len(clusters) >= resp.Total() {
+			return clusters, nil
+		}
+	}
+}
+
+// SetClusterProperties replaces the properties of clusterID.
+func (u *OSD) SetClusterProperties(clusterID string, properties map[string]string) error {
+	data, err := json.Marshal(map[string]interface{}{
+		"properties": properties,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't encode properties: %v", err)
+	}
+
+	if _, err = u.send(u.conn.Patch().Path(clustersPath+"/"+clusterID).Bytes(data), nil); err != nil {
+		return fmt.Errorf("couldn't set properties of cluster '%s': %v", clusterID, err)
+	}
+	return nil
+}
+
+// SetClusterExpiry changes when clusterID is deleted by OSD to expiry.
+func (u *OSD) SetClusterExpiry(clusterID string, expiry time.Time) error {
+	data, err := json.Marshal(map[string]interface{}{
+		"expiration_timestamp": expiry.UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't encode expiry: %v", err)
+	}
+
+	if _, err = u.send(u.conn.Patch().Path(clustersPath+"/"+clusterID).Bytes(data), nil); err != nil {
+		return fmt.Errorf("couldn't set expiry of cluster '%s': %v", clusterID, err)
+	}
+	return nil
+}
+
+// WaitForClusterReady blocks until clusterID is ready or timeout, checking every interval. Progress through
+// install stages is tracked in Install while waiting.
+func (u *OSD) WaitForClusterReady(clusterID string, timeout, interval time.Duration) error {
 	log.Printf("Waiting %v for cluster '%s' to be ready...\n", timeout, clusterID)
 
-	return wait.PollImmediate(45*time.Second, timeout, func() (bool, error) {
+	start := time.Now()
+	u.Install = NewInstallProgress(start)
+	u.Install.Launched = clusterID == u.launched
+	return u.poll(interval, timeout, func() (bool, error) {
+		now := time.Now()
 		if state, err := u.ClusterState(clusterID); state == v1.ClusterStateReady {
+			u.Install.Observe(StageComplete, now)
 			return true, nil
 		} else if err != nil {
 			log.Print("Encountered error waiting for cluster:", err)
 		} else if state == v1.ClusterStateError {
 			return false, fmt.Errorf("the installation of cluster '%s' has errored", clusterID)
 		} else {
-			log.Printf("Cluster is not ready, current status '%s'.", state)
+			u.checkInstallProgress(clusterID, now)
+			stage, stageStarted := u.Install.Current()
+			log.Printf("Cluster is not ready, current status '%s', install stage '%s' for %v (%v elapsed).", state, stage,
+				now.Sub(stageStarted).Round(time.Second), now.Sub(start).Round(time.Second))
 		}
 		return false, nil
 	})
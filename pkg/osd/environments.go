@@ -1,5 +1,10 @@
 package osd
 
+import (
+	"fmt"
+	"strings"
+)
+
 // Environments are known instance of OSD.
 var Environments = environments{
 	// default to using integration environment
@@ -9,8 +14,24 @@ var Environments = environments{
 	"int":   "https://api-integration.6943.hive-integration.openshiftapps.com",
 	"stage": "https://api.stage.openshift.com",
 	"prod":  "https://api.openshift.com",
+
+	// FedRAMP environments, which run clusters in AWS GovCloud
+	"fedramp-int":   "https://api.int.openshiftusgov.com",
+	"fedramp-stage": "https://api.stage.openshiftusgov.com",
+	"fedramp-prod":  "https://api.openshiftusgov.com",
+}
+
+// FedRAMPTokenURLs are the endpoints used to create access tokens for each FedRAMP environment. They only accept client
+// credentials rather than offline tokens.
+var FedRAMPTokenURLs = map[string]string{
+	"fedramp-int":   "https://sso.int.openshiftusgov.com/realms/redhat-external/protocol/openid-connect/token",
+	"fedramp-stage": "https://sso.stage.openshiftusgov.com/realms/redhat-external/protocol/openid-connect/token",
+	"fedramp-prod":  "https://sso.openshiftusgov.com/realms/redhat-external/protocol/openid-connect/token",
 }
 
+// FedRAMPRegions are the only regions clusters can be created in by FedRAMP environments.
+var FedRAMPRegions = []string{"us-gov-west-1", "us-gov-east-1"}
+
 type environments map[string]string
 
 // Choose returns the endpoint for the desired OSD environment. If desired is URL, it will be returned as the endpoint.
@@ -21,3 +42,31 @@ func (e environments) Choose(desired string) string {
 		return e.Choose(val)
 	}
 }
+
+// IsFedRAMP returns true if env is a FedRAMP environment.
+func IsFedRAMP(env string) bool {
+	_, ok := FedRAMPTokenURLs[env]
+	return ok
+}
+
+// ChooseTokenURL returns the endpoint used to create access tokens for env.
+func ChooseTokenURL(env string) string {
+	if tokenURL, ok := FedRAMPTokenURLs[env]; ok {
+		return tokenURL
+	}
+	return TokenURL
+}
+
+// CheckRegion returns an error if clusters can't be created in region by env.
+func CheckRegion(env, region string) error {
+	if !IsFedRAMP(env) {
+		return nil
+	}
+	for _, allowed := range FedRAMPRegions {
+		if region == allowed {
+			return nil
+		}
+	}
+	return fmt.Errorf("region '%s' isn't available in %s, which only creates clusters in %s", region, env,
+		strings.Join(FedRAMPRegions, ", "))
+}
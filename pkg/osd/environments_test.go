@@ -0,0 +1,51 @@
+package osd
+
+import (
+	"testing"
+
+	"github.com/openshift/osde2e/pkg/config"
+)
+
+func TestEnvironments(t *testing.T) {
+	tests := []struct {
+		env, url, tokenURL string
+		fedRAMP            bool
+	}{
+		{"", "https://api-integration.6943.hive-integration.openshiftapps.com", TokenURL, false},
+		{"prod", "https://api.openshift.com", TokenURL, false},
+		{"fedramp-int", "https://api.int.openshiftusgov.com", FedRAMPTokenURLs["fedramp-int"], true},
+		{"https://gateway.example.com", "https://gateway.example.com", TokenURL, false},
+	}
+	for _, tt := range tests {
+		if url := Environments.Choose(tt.env); url != tt.url {
+			t.Errorf("expected '%s' to use %s, got %s", tt.env, tt.url, url)
+		}
+		if tokenURL := ChooseTokenURL(tt.env); tokenURL != tt.tokenURL {
+			t.Errorf("expected '%s' to request tokens from %s, got %s", tt.env, tt.tokenURL, tokenURL)
+		}
+		if fedRAMP := IsFedRAMP(tt.env); fedRAMP != tt.fedRAMP {
+			t.Errorf("expected '%s' being FedRAMP to be %t", tt.env, tt.fedRAMP)
+		}
+	}
+}
+
+func TestCheckRegion(t *testing.T) {
+	if err := CheckRegion("prod", "us-east-1"); err != nil {
+		t.Errorf("expected any region outside FedRAMP, got %v", err)
+	}
+	if err := CheckRegion("fedramp-stage", "us-gov-west-1"); err != nil {
+		t.Errorf("expected GovCloud region in FedRAMP, got %v", err)
+	}
+	if err := CheckRegion("fedramp-stage", "us-east-1"); err == nil {
+		t.Error("expected commercial region to be refused in FedRAMP")
+	}
+}
+
+func TestNewForConfigFedRAMP(t *testing.T) {
+	if _, err := NewForConfig(&config.Config{OSDEnv: "fedramp-int", UHCToken: "token"}); err == nil {
+		t.Error("expected FedRAMP to require client credentials")
+	}
+	if _, err := NewForConfig(&config.Config{OSDEnv: "fedramp-int", OCMClientID: "id", OCMClientSecret: "secret"}); err != nil {
+		t.Errorf("expected client credentials to connect to FedRAMP, got %v", err)
+	}
+}
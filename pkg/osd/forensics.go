@@ -0,0 +1,179 @@
+package osd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	// UninstallLogID is the ID of the log of removing a cluster's cloud resources.
+	UninstallLogID = "uninstall"
+
+	// InstallFailureFile is the name of the description of why a cluster failed to install in the report directory.
+	InstallFailureFile = "install-failure.json"
+)
+
+// FailureClass is the kind of problem a cluster failed to install because of.
+type FailureClass string
+
+const (
+	// ClassCapacity is when the cloud region didn't have capacity for the cluster's instances.
+	ClassCapacity FailureClass = "capacity"
+
+	// ClassQuota is when the cloud account or OSD organization doesn't have enough quota.
+	ClassQuota FailureClass = "quota"
+
+	// ClassDNS is when the cluster's domain couldn't be created or resolved.
+	ClassDNS FailureClass = "dns"
+
+	// ClassCloudCredentials is when the cloud credentials used to install were invalid or lacked permissions.
+	ClassCloudCredentials FailureClass = "cloud-credentials"
+
+	// ClassUnknown is when the cause of the failure isn't recognized.
+	ClassUnknown FailureClass = "unknown"
+)
+
+// failureClassifiers find the class of failures in their error and logs, checked in order.
+var failureClassifiers = []struct {
+	class FailureClass
+	re    *regexp.Regexp
+}{
+	{ClassCapacity, regexp.MustCompile(`(?i)InsufficientInstanceCapacity|InsufficientCapacity|insufficient capacity|` +
+		`ZONE_RESOURCE_POOL_EXHAUSTED|out of capacity|capacity not available`)},
+	{ClassQuota, regexp.MustCompile(`(?i)quota|LimitExceeded|limit exceeded|exceeded the maximum`)},
+	{ClassCloudCredentials, regexp.MustCompile(`(?i)AuthFailure|InvalidClientTokenId|UnauthorizedOperation|AccessDenied|` +
+		`SignatureDoesNotMatch|OptInRequired|invalid credentials|not authorized to perform|permission denied`)},
+	{ClassDNS, regexp.MustCompile(`(?i)\bdns\b|route53|hosted zone|no such host|NXDOMAIN|domain name`)},
+}
+
+// provisionStatus is the status of a cluster in OSD, including why its provisioning failed.
+type provisionStatus struct {
+	State        string `json:"state"`
+	Description  string `json:"description"`
+	ErrorCode    string `json:"provision_error_code"`
+	ErrorMessage string `json:"provision_error_message"`
+}
+
+// InstallFailure describes why a cluster failed to install.
+type InstallFailure struct {
+	ClusterID string `json:"clusterID"`
+	State     string `json:"state,omitempty"`
+
+	// Stage is the last install stage reached.
+	Stage InstallStage `json:"stage,omitempty"`
+
+	// Description, ErrorCode, and ErrorMessage are why OSD reports provisioning failed.
+	Description  string `json:"description,omitempty"`
+	ErrorCode    string `json:"errorCode,omitempty"`
+	ErrorMessage string `json:"errorMessage,omitempty"`
+
+	Class FailureClass `json:"class"`
+
+	// Evidence is what the failure was classified by, such as a line of the install log.
+	Evidence string `json:"evidence,omitempty"`
+
+	// Logs are the install and uninstall logs of the cluster by ID.
+	Logs map[string][]byte `json:"-"`
+}
+
+func (f *InstallFailure) String() string {
+	desc := fmt.Sprintf("cluster '%s' failed to install because of %s", f.ClusterID, f.Class)
+	if f.ErrorCode != "" {
+		desc += fmt.Sprintf(" (%s)", f.ErrorCode)
+	}
+	if f.Evidence != "" {
+		desc += ": " + f.Evidence
+	}
+	return desc
+}
+
+// Metadata returns the class and error code of the failure, suitable for reporting.
+func (f *InstallFailure) Metadata() map[string]interface{} {
+	return map[string]interface{}{
+		"install-failure-class": string(f.Class),
+		"install-failure-code":  f.ErrorCode,
+	}
+}
+
+// Write stores f in dir as InstallFailureFile.
+func (f *InstallFailure) Write(dir string) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("couldn't encode install failure: %v", err)
+	}
+
+	os.MkdirAll(dir, os.ModePerm)
+	filename := filepath.Join(dir, InstallFailureFile)
+	if err = ioutil.WriteFile(filename, data, os.ModePerm); err != nil {
+		return fmt.Errorf("couldn't write install failure to '%s': %v", filename, err)
+	}
+	return nil
+}
+
+// InstallForensics collects why clusterID failed to install from OSD, including its provision error and install and
+// uninstall logs, and classifies the failure. Logs which aren't available yet are skipped.
+func (u *OSD) InstallForensics(clusterID string) (*InstallFailure, error) {
+	var status provisionStatus
+	if _, err := u.send(u.conn.Get().Path(clustersPath+"/"+clusterID+"/status"), &status); err != nil {
+		return nil, fmt.Errorf("couldn't get status of cluster '%s': %v", clusterID, err)
+	}
+
+	f := &InstallFailure{
+		ClusterID:    clusterID,
+		State:        status.State,
+		Description:  status.Description,
+		ErrorCode:    status.ErrorCode,
+		ErrorMessage: status.ErrorMessage,
+		Logs:         map[string][]byte{},
+	}
+	if u.Install != nil {
+		f.Stage, _ = u.Install.Current()
+	}
+
+	for _, id := range []string{InstallLogID, UninstallLogID} {
+		logs, err := u.FullLogs(clusterID, id)
+		if err != nil {
+			continue
+		}
+		f.Logs[id] = logs[id]
+	}
+
+	f.Class, f.Evidence = ClassifyInstallFailure(f)
+	u.InstallFailure = f
+	return f, nil
+}
+
+// ClassifyInstallFailure returns the class of f and what it was recognized by. The provision error of f is checked
+// before the last lines of its install log reporting errors.
+func ClassifyInstallFailure(f *InstallFailure) (FailureClass, string) {
+	candidates := []string{f.ErrorCode + " " + f.ErrorMessage, f.Description}
+	lines := bytes.Split(f.Logs[InstallLogID], []byte("\n"))
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := string(bytes.TrimSpace(lines[i]))
+		if strings.Contains(line, "level=error") || strings.Contains(line, "level=fatal") {
+			candidates = append(candidates, line)
+		}
+	}
+
+	for _, c := range candidates {
+		for _, classifier := range failureClassifiers {
+			if classifier.re.MatchString(c) {
+				return classifier.class, strings.TrimSpace(c)
+			}
+		}
+	}
+
+	// the most specific description of the failure is still useful
+	for _, c := range candidates {
+		if c = strings.TrimSpace(c); c != "" {
+			return ClassUnknown, c
+		}
+	}
+	return ClassUnknown, ""
+}
@@ -0,0 +1,51 @@
+package osd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInstallForensics(t *testing.T) {
+	osd, done := replay(t, "forensics.yaml", nil)
+	defer done()
+
+	f, err := osd.InstallForensics("1a2b3c")
+	if err != nil {
+		t.Fatalf("failed to collect install forensics: %v", err)
+	}
+
+	if f.State != "error" || f.ErrorCode != "OCM3999" {
+		t.Errorf("expected provision error to be collected, got %+v", f)
+	}
+	if _, ok := f.Logs[InstallLogID]; !ok {
+		t.Error("expected install log to be collected")
+	} else if _, ok = f.Logs[UninstallLogID]; ok {
+		t.Error("expected missing uninstall log to be skipped")
+	}
+	if f.Class != ClassDNS || !strings.Contains(f.Evidence, "Route53 Hosted Zone") {
+		t.Errorf("expected failure to be classified by the install log, got %s: %s", f.Class, f.Evidence)
+	}
+	if osd.InstallFailure != f {
+		t.Error("expected install failure to be kept for reporting")
+	}
+}
+
+func TestClassifyInstallFailure(t *testing.T) {
+	for _, test := range []struct {
+		failure  InstallFailure
+		expected FailureClass
+	}{
+		{InstallFailure{ErrorMessage: "InsufficientInstanceCapacity: We currently do not have sufficient m5.xlarge capacity"}, ClassCapacity},
+		{InstallFailure{ErrorCode: "OCM3005", ErrorMessage: "vCPU quota exceeded in the AWS account"}, ClassQuota},
+		{InstallFailure{Logs: map[string][]byte{InstallLogID: []byte(
+			"level=info msg=\"Creating infrastructure resources...\"\n" +
+				"level=error msg=\"Error: InvalidClientTokenId: The security token included in the request is invalid\"\n")}},
+			ClassCloudCredentials},
+		{InstallFailure{Description: "lookup api.osde2e-abc.example.com: no such host"}, ClassDNS},
+		{InstallFailure{Description: "Cluster installation timed out"}, ClassUnknown},
+	} {
+		if class, evidence := ClassifyInstallFailure(&test.failure); class != test.expected {
+			t.Errorf("expected %+v to be classified as %s, got %s: %s", test.failure, test.expected, class, evidence)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package osd
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+)
+
+// Hypershift is how the control plane of a cluster is hosted.
+type Hypershift struct {
+	// Enabled is set when the control plane runs on a management cluster instead of the cluster's own nodes.
+	Enabled bool `json:"enabled"`
+
+	// ManagementCluster is the name of the cluster hosting the control plane.
+	ManagementCluster string `json:"management_cluster,omitempty"`
+}
+
+// ClusterHypershift returns how the control plane of clusterID is hosted. Clusters created before hosted control
+// planes were available aren't hosted.
+func (u *OSD) ClusterHypershift(clusterID string) (*Hypershift, error) {
+	hypershift := new(Hypershift)
+	status, err := u.send(u.conn.Get().Path(path.Join(clustersPath, clusterID, "hypershift")), hypershift)
+	if status == http.StatusNotFound {
+		return &Hypershift{}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("couldn't get hypershift configuration of cluster '%s': %v", clusterID, err)
+	}
+	return hypershift, nil
+}
+
+// ManagementKubeconfig retrieves the kubeconfig of the management cluster hosting the control plane of clusterID.
+func (u *OSD) ManagementKubeconfig(clusterID string) ([]byte, error) {
+	hypershift, err := u.ClusterHypershift(clusterID)
+	if err != nil {
+		return nil, err
+	} else if !hypershift.Enabled || hypershift.ManagementCluster == "" {
+		return nil, fmt.Errorf("cluster '%s' doesn't have a hosted control plane", clusterID)
+	}
+
+	clusters, err := u.ListClusters(fmt.Sprintf("name = '%s'", hypershift.ManagementCluster))
+	if err != nil {
+		return nil, err
+	} else if len(clusters) != 1 {
+		return nil, fmt.Errorf("found %d management clusters named '%s' hosting cluster '%s'", len(clusters),
+			hypershift.ManagementCluster, clusterID)
+	}
+	return u.ClusterKubeconfig(clusters[0].ID())
+}
@@ -0,0 +1,21 @@
+package osd
+
+import (
+	"testing"
+)
+
+func TestManagementKubeconfig(t *testing.T) {
+	osd, done := replay(t, "hypershift.yaml", nil)
+	defer done()
+
+	kubeconfig, err := osd.ManagementKubeconfig("1a2b3c")
+	if err != nil {
+		t.Fatalf("failed to get management kubeconfig: %v", err)
+	} else if string(kubeconfig) != "management" {
+		t.Errorf("expected kubeconfig of management cluster, got '%s'", kubeconfig)
+	}
+
+	if hypershift, err := osd.ClusterHypershift("4d5e6f"); err != nil || hypershift.Enabled {
+		t.Errorf("expected cluster without hypershift configuration not to be hosted, got %+v: %v", hypershift, err)
+	}
+}
@@ -0,0 +1,153 @@
+package osd
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"time"
+)
+
+const (
+	// InstallLogID is the ID of the installer's log.
+	InstallLogID = "install"
+
+	// installLogLength is the number of lines of the install log checked for progress.
+	installLogLength = 1000
+
+	// InstallResultKey is the metadata key of whether the install completed, 'SUCCESS' or 'FAILURE'.
+	InstallResultKey = "install-result"
+
+	// InstallSecondsKey is the metadata key of how long a completed install took in seconds.
+	InstallSecondsKey = "install-seconds"
+)
+
+// InstallStage is a step of cluster installation.
+type InstallStage string
+
+const (
+	// StageProvisioning is before the installer has started.
+	StageProvisioning InstallStage = "provisioning"
+
+	// StageInfrastructure is when cloud resources are created.
+	StageInfrastructure InstallStage = "infrastructure"
+
+	// StageBootstrap is when the control plane is bootstrapped.
+	StageBootstrap InstallStage = "bootstrap"
+
+	// StageOperators is when cluster operators are initializing.
+	StageOperators InstallStage = "operators"
+
+	// StageComplete is when the installer has finished.
+	StageComplete InstallStage = "complete"
+)
+
+// installMarkers are messages logged by the installer when a stage starts, in order.
+var installMarkers = []struct {
+	marker string
+	stage  InstallStage
+}{
+	{"Creating infrastructure resources", StageInfrastructure},
+	{"for the Kubernetes API", StageBootstrap},
+	{"for bootstrapping to complete", StageBootstrap},
+	{"to initialize...", StageOperators},
+	{"Install complete!", StageComplete},
+}
+
+// ParseInstallStage returns the latest stage reached in an install log.
+func ParseInstallStage(installLog []byte) InstallStage {
+	stage, pos := StageProvisioning, -1
+	for _, m := range installMarkers {
+		if i := bytes.LastIndex(installLog, []byte(m.marker)); i > pos {
+			stage, pos = m.stage, i
+		}
+	}
+	return stage
+}
+
+// StageDuration is how long an install stage took.
+type StageDuration struct {
+	Stage    InstallStage
+	Duration time.Duration
+}
+
+// InstallProgress tracks transitions between install stages.
+type InstallProgress struct {
+	// Stages are completed stages in order.
+	Stages []StageDuration
+
+	// Launched is true if the run launched the cluster. Clusters which already existed are ready when first checked,
+	// so their progress doesn't describe an install.
+	Launched bool
+
+	current      InstallStage
+	stageStarted time.Time
+}
+
+// NewInstallProgress starts tracking an install at now.
+func NewInstallProgress(now time.Time) *InstallProgress {
+	return &InstallProgress{
+		current:      StageProvisioning,
+		stageStarted: now,
+	}
+}
+
+// Current returns the stage the install is in and when it began.
+func (p *InstallProgress) Current() (InstallStage, time.Time) {
+	return p.current, p.stageStarted
+}
+
+// Observe records the stage seen at now, returning true if it is a transition.
+func (p *InstallProgress) Observe(stage InstallStage, now time.Time) bool {
+	if stage == p.current {
+		return false
+	}
+
+	p.Stages = append(p.Stages, StageDuration{
+		Stage:    p.current,
+		Duration: now.Sub(p.stageStarted),
+	})
+	p.current, p.stageStarted = stage, now
+	return true
+}
+
+// Metadata returns the duration of each completed stage in seconds and whether the install completed, suitable for
+// reporting. Installs which completed also include how long they took in total. It's empty unless the run launched
+// the cluster.
+func (p *InstallProgress) Metadata() map[string]interface{} {
+	meta := make(map[string]interface{}, len(p.Stages)+2)
+	if !p.Launched {
+		return meta
+	}
+	var total time.Duration
+	for _, s := range p.Stages {
+		key := fmt.Sprintf("install-%s-seconds", s.Stage)
+		if prev, ok := meta[key].(float64); ok {
+			meta[key] = prev + s.Duration.Seconds()
+		} else {
+			meta[key] = s.Duration.Seconds()
+		}
+		total += s.Duration
+	}
+
+	meta[InstallResultKey] = "FAILURE"
+	if p.current == StageComplete {
+		meta[InstallResultKey] = "SUCCESS"
+		meta[InstallSecondsKey] = total.Seconds()
+	}
+	return meta
+}
+
+// checkInstallProgress updates the install progress of clusterID from its install log, logging transitions.
+func (u *OSD) checkInstallProgress(clusterID string, now time.Time) {
+	logs, err := u.Logs(clusterID, installLogLength, InstallLogID)
+	if err != nil {
+		log.Printf("Couldn't check install progress: %v", err)
+		return
+	}
+
+	prev, prevStarted := u.Install.Current()
+	if u.Install.Observe(ParseInstallStage(logs[InstallLogID]), now) {
+		cur, _ := u.Install.Current()
+		log.Printf("Cluster install moved from stage '%s' to '%s' after %v.", prev, cur, now.Sub(prevStarted).Round(time.Second))
+	}
+}
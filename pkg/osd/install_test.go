@@ -0,0 +1,68 @@
+package osd
+
+import (
+	"testing"
+	"time"
+)
+
+const testInstallLog = `level=info msg="Consuming \"Install Config\" from target directory"
+level=info msg="Creating infrastructure resources..."
+level=info msg="Waiting up to 30m0s for the Kubernetes API at https://api.test.example.com:6443..."
+level=info msg="API v1.13.4+838b4fa up"
+level=info msg="Waiting up to 30m0s for bootstrapping to complete..."
+level=info msg="Destroying the bootstrap resources..."
+`
+
+func TestParseInstallStage(t *testing.T) {
+	tests := []struct {
+		log      string
+		expected InstallStage
+	}{
+		{"", StageProvisioning},
+		{testInstallLog, StageBootstrap},
+		{testInstallLog + `level=info msg="Waiting up to 30m0s for the cluster at https://api.test.example.com:6443 to initialize..."`, StageOperators},
+		{testInstallLog + `level=info msg="Install complete!"`, StageComplete},
+	}
+
+	for _, test := range tests {
+		if stage := ParseInstallStage([]byte(test.log)); stage != test.expected {
+			t.Errorf("expected stage '%s', got '%s' for log:\n%s", test.expected, stage, test.log)
+		}
+	}
+}
+
+func TestInstallProgress(t *testing.T) {
+	start := time.Now()
+	p := NewInstallProgress(start)
+	p.Launched = true
+
+	if p.Observe(StageProvisioning, start.Add(time.Minute)) {
+		t.Error("observing the current stage shouldn't be a transition")
+	}
+	if !p.Observe(StageInfrastructure, start.Add(2*time.Minute)) {
+		t.Error("observing a new stage should be a transition")
+	}
+	p.Observe(StageComplete, start.Add(12*time.Minute))
+
+	meta := p.Metadata()
+	if meta["install-provisioning-seconds"] != 120.0 || meta["install-infrastructure-seconds"] != 600.0 {
+		t.Errorf("unexpected stage durations: %v", meta)
+	}
+	if meta[InstallResultKey] != "SUCCESS" || meta[InstallSecondsKey] != 720.0 {
+		t.Errorf("expected completed install to take 720 seconds, got %v", meta)
+	}
+
+	p = NewInstallProgress(start)
+	p.Launched = true
+	p.Observe(StageBootstrap, start.Add(time.Minute))
+	if meta = p.Metadata(); meta[InstallResultKey] != "FAILURE" || meta[InstallSecondsKey] != nil {
+		t.Errorf("expected incomplete install to fail without a duration, got %v", meta)
+	}
+
+	// existing clusters are ready straight away, which isn't an install
+	p = NewInstallProgress(start)
+	p.Observe(StageComplete, start.Add(time.Second))
+	if meta = p.Metadata(); len(meta) != 0 {
+		t.Errorf("expected no install metadata for an existing cluster, got %v", meta)
+	}
+}
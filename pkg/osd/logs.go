@@ -21,7 +21,7 @@ func (u *OSD) Logs(clusterID string, length int, ids ...string) (logs map[string
 			Logs().
 			Log(logID).
 			Get().Parameter("tail", length).
-			Send()
+			SendContext(u.context())
 
 		if resp != nil {
 			err = errResp(resp.Error())
@@ -44,7 +44,7 @@ func (u *OSD) getLogList(clusterID string) ([]string, error) {
 	resp, err := u.cluster(clusterID).
 		Logs().
 		List().
-		Send()
+		SendContext(u.context())
 
 	if resp != nil {
 		err = errResp(resp.Error())
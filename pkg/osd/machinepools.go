@@ -0,0 +1,28 @@
+package osd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+)
+
+// Taint repels pods which don't tolerate it from the nodes of a machine pool.
+type Taint struct {
+	Key    string `json:"key"`
+	Value  string `json:"value,omitempty"`
+	Effect string `json:"effect"`
+}
+
+// CreateMachinePool adds pool to clusterID. Its nodes are created with the pool's labels and taints.
+func (u *OSD) CreateMachinePool(clusterID string, pool MachinePool) error {
+	data, err := json.Marshal(pool)
+	if err != nil {
+		return fmt.Errorf("couldn't encode machine pool '%s': %v", pool.ID, err)
+	}
+
+	log.Printf("Creating machine pool '%s' of %d %s nodes on cluster '%s'...", pool.ID, pool.Replicas, pool.InstanceType, clusterID)
+	if _, err = u.send(u.conn.Post().Path(machinePoolsPath(clusterID)).Bytes(data), nil); err != nil {
+		return fmt.Errorf("couldn't create machine pool '%s' on cluster '%s': %v", pool.ID, clusterID, err)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package osd
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestCreateMachinePool(t *testing.T) {
+	var created []MachinePool
+	osd, done := replay(t, "machinepools.yaml", func(req *http.Request) {
+		data, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			t.Errorf("failed to read request: %v", err)
+			return
+		}
+		var pool MachinePool
+		if err = json.Unmarshal(data, &pool); err != nil {
+			t.Errorf("failed to decode machine pool: %v", err)
+		}
+		created = append(created, pool)
+	})
+	defer done()
+
+	pool := MachinePool{
+		ID:           "infra",
+		InstanceType: "r5.xlarge",
+		Replicas:     2,
+		Labels:       map[string]string{"node-role.kubernetes.io/infra": ""},
+		Taints:       []Taint{{Key: "node-role.kubernetes.io/infra", Effect: "NoSchedule"}},
+	}
+	if err := osd.CreateMachinePool("1a2b3c", pool); err != nil {
+		t.Fatalf("failed to create machine pool: %v", err)
+	}
+	if err := osd.CreateMachinePool("1a2b3c", pool); err == nil {
+		t.Error("expected creating an existing machine pool to fail")
+	}
+
+	if len(created) != 2 || !reflect.DeepEqual(created[0], pool) {
+		t.Errorf("expected machine pool %+v to be sent, got %+v", pool, created)
+	}
+}
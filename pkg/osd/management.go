@@ -0,0 +1,292 @@
+package osd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"reflect"
+	"sort"
+	"time"
+
+	uhc "github.com/openshift-online/uhc-sdk-go/pkg/client"
+	osderrors "github.com/openshift-online/uhc-sdk-go/pkg/client/errors"
+)
+
+// TODO: use uhc-sdk-go autoscaler and add-on types once available
+
+const (
+	// DeschedulerAddon is the ID of the add-on which installs the descheduler.
+	DeschedulerAddon = "kube-descheduler-operator"
+
+	// AddonReady is the state of add-on installations which have completed.
+	AddonReady = "ready"
+
+	// AddonFailed is the state of add-on installations which couldn't complete.
+	AddonFailed = "failed"
+)
+
+// Autoscaler is the configuration of a cluster's autoscaler.
+type Autoscaler struct {
+	BalanceSimilarNodeGroups bool                      `json:"balance_similar_node_groups"`
+	MaxPodGracePeriod        int                       `json:"max_pod_grace_period,omitempty"`
+	PodPriorityThreshold     int                       `json:"pod_priority_threshold,omitempty"`
+	ResourceLimits           *AutoscalerResourceLimits `json:"resource_limits,omitempty"`
+	ScaleDown                *AutoscalerScaleDown      `json:"scale_down,omitempty"`
+}
+
+// AutoscalerResourceLimits limit how far a cluster is scaled up.
+type AutoscalerResourceLimits struct {
+	MaxNodesTotal int `json:"max_nodes_total,omitempty"`
+}
+
+// AutoscalerScaleDown configures when nodes are removed. Durations are strings such as '10m'.
+type AutoscalerScaleDown struct {
+	Enabled              bool   `json:"enabled"`
+	UnneededTime         string `json:"unneeded_time,omitempty"`
+	DelayAfterAdd        string `json:"delay_after_add,omitempty"`
+	UtilizationThreshold string `json:"utilization_threshold,omitempty"`
+}
+
+// ClusterAutoscaler returns the autoscaler configuration of clusterID, or nil if it isn't configured.
+func (u *OSD) ClusterAutoscaler(clusterID string) (*Autoscaler, error) {
+	autoscaler := new(Autoscaler)
+	status, err := u.send(u.conn.Get().Path(autoscalerPath(clusterID)), autoscaler)
+	if status == http.StatusNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("couldn't get autoscaler of cluster '%s': %v", clusterID, err)
+	}
+	return autoscaler, nil
+}
+
+// SetClusterAutoscaler configures the autoscaler of clusterID, replacing any existing configuration.
+func (u *OSD) SetClusterAutoscaler(clusterID string, autoscaler *Autoscaler) error {
+	existing, err := u.ClusterAutoscaler(clusterID)
+	if err != nil {
+		return err
+	}
+
+	req, data := u.conn.Post(), []byte(nil)
+	if existing == nil {
+		data, err = json.Marshal(autoscaler)
+	} else {
+		// options left out of autoscaler are cleared rather than kept
+		req = u.conn.Patch()
+		data, err = mergePatch(existing, autoscaler)
+	}
+	if err != nil {
+		return fmt.Errorf("couldn't encode autoscaler: %v", err)
+	}
+
+	if _, err = u.send(req.Path(autoscalerPath(clusterID)).Bytes(data), nil); err != nil {
+		return fmt.Errorf("couldn't configure autoscaler of cluster '%s': %v", clusterID, err)
+	}
+	return nil
+}
+
+// DeleteClusterAutoscaler removes the autoscaler configuration of clusterID.
+func (u *OSD) DeleteClusterAutoscaler(clusterID string) error {
+	status, err := u.send(u.conn.Delete().Path(autoscalerPath(clusterID)), nil)
+	if err != nil && status != http.StatusNotFound {
+		return fmt.Errorf("couldn't delete autoscaler of cluster '%s': %v", clusterID, err)
+	}
+	return nil
+}
+
+// AddonInstallation is an add-on installed on a cluster.
+type AddonInstallation struct {
+	ID         string           `json:"id,omitempty"`
+	Addon      addonRef         `json:"addon"`
+	State      string           `json:"state,omitempty"`
+	Parameters *addonParameters `json:"parameters,omitempty"`
+}
+
+// Params returns the parameters of the installation by ID.
+func (a *AddonInstallation) Params() map[string]string {
+	params := map[string]string{}
+	if a.Parameters != nil {
+		for _, p := range a.Parameters.Items {
+			params[p.ID] = p.Value
+		}
+	}
+	return params
+}
+
+type addonRef struct {
+	ID string `json:"id"`
+}
+
+type addonParameters struct {
+	Items []addonParameter `json:"items"`
+}
+
+type addonParameter struct {
+	ID    string `json:"id"`
+	Value string `json:"value"`
+}
+
+// Addon returns the installation of addonID on clusterID, or nil if it isn't installed.
+func (u *OSD) Addon(clusterID, addonID string) (*AddonInstallation, error) {
+	addon := new(AddonInstallation)
+	status, err := u.send(u.conn.Get().Path(path.Join(addonsPath(clusterID), addonID)), addon)
+	if status == http.StatusNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("couldn't get add-on '%s' of cluster '%s': %v", addonID, clusterID, err)
+	}
+	return addon, nil
+}
+
+// ConfigureAddon installs addonID on clusterID with params, or updates its parameters if it's already installed.
+func (u *OSD) ConfigureAddon(clusterID, addonID string, params map[string]string) error {
+	existing, err := u.Addon(clusterID, addonID)
+	if err != nil {
+		return err
+	}
+
+	addon := AddonInstallation{
+		Addon:      addonRef{ID: addonID},
+		Parameters: &addonParameters{Items: []addonParameter{}},
+	}
+	for id, value := range params {
+		addon.Parameters.Items = append(addon.Parameters.Items, addonParameter{ID: id, Value: value})
+	}
+	sort.Slice(addon.Parameters.Items, func(i, j int) bool {
+		return addon.Parameters.Items[i].ID < addon.Parameters.Items[j].ID
+	})
+
+	req, data := u.conn.Post().Path(addonsPath(clusterID)), []byte(nil)
+	if existing == nil {
+		data, err = json.Marshal(addon)
+	} else {
+		// only parameters are changed, with those left out of params removed
+		req = u.conn.Patch().Path(path.Join(addonsPath(clusterID), addonID))
+		data, err = mergePatch(AddonInstallation{Addon: existing.Addon, Parameters: existing.Parameters}, addon)
+	}
+	if err != nil {
+		return fmt.Errorf("couldn't encode add-on '%s': %v", addonID, err)
+	}
+
+	if _, err = u.send(req.Bytes(data), nil); err != nil {
+		return fmt.Errorf("couldn't configure add-on '%s' of cluster '%s': %v", addonID, clusterID, err)
+	}
+	return nil
+}
+
+// WaitForAddon waits until addonID is ready on clusterID, returning an error if its installation fails.
+func (u *OSD) WaitForAddon(clusterID, addonID string, interval, timeout time.Duration) error {
+	return u.poll(interval, timeout, func() (bool, error) {
+		addon, err := u.Addon(clusterID, addonID)
+		if err != nil {
+			return false, err
+		} else if addon == nil {
+			return false, fmt.Errorf("add-on '%s' isn't installed on cluster '%s'", addonID, clusterID)
+		} else if addon.State == AddonFailed {
+			return false, fmt.Errorf("add-on '%s' failed to install on cluster '%s'", addonID, clusterID)
+		}
+		return addon.State == AddonReady, nil
+	})
+}
+
+// DeleteAddon uninstalls addonID from clusterID.
+func (u *OSD) DeleteAddon(clusterID, addonID string) error {
+	status, err := u.send(u.conn.Delete().Path(path.Join(addonsPath(clusterID), addonID)), nil)
+	if err != nil && status != http.StatusNotFound {
+		return fmt.Errorf("couldn't delete add-on '%s' of cluster '%s': %v", addonID, clusterID, err)
+	}
+	return nil
+}
+
+// mergePatch returns a JSON merge patch changing original into modified. Fields set in original but left out of
+// modified are patched to null, so they're cleared instead of kept.
+func mergePatch(original, modified interface{}) ([]byte, error) {
+	from, err := toObject(original)
+	if err != nil {
+		return nil, err
+	}
+	to, err := toObject(modified)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(diffObjects(from, to))
+}
+
+// toObject converts v to the JSON object it's encoded as.
+func toObject(v interface{}) (obj map[string]interface{}, err error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(data, &obj)
+	return
+}
+
+// diffObjects returns the fields of to which differ from from, with fields only in from set to nil.
+func diffObjects(from, to map[string]interface{}) map[string]interface{} {
+	patch := map[string]interface{}{}
+	for k := range from {
+		if _, ok := to[k]; !ok {
+			patch[k] = nil
+		}
+	}
+	for k, v := range to {
+		old, ok := from[k]
+		if ok && reflect.DeepEqual(old, v) {
+			continue
+		}
+
+		// nested objects are merged, while other values including lists are replaced
+		oldObj, oldIsObj := old.(map[string]interface{})
+		obj, isObj := v.(map[string]interface{})
+		if oldIsObj && isObj {
+			patch[k] = diffObjects(oldObj, obj)
+		} else {
+			patch[k] = v
+		}
+	}
+	return patch
+}
+
+// send performs req, decoding the response body into out if it's set. The status is returned with API errors.
+func (u *OSD) send(req *uhc.Request, out interface{}) (int, error) {
+	resp, err := u.do(req)
+	if err != nil {
+		return 0, err
+	}
+
+	if resp.Status() >= http.StatusBadRequest {
+		apiErr, err := osderrors.UnmarshalError(resp.Bytes())
+		if err != nil {
+			return resp.Status(), fmt.Errorf("status %d: %s", resp.Status(), resp.String())
+		}
+		return resp.Status(), errResp(apiErr)
+	}
+
+	if out != nil && len(resp.Bytes()) != 0 {
+		if err = json.Unmarshal(resp.Bytes(), out); err != nil {
+			return resp.Status(), fmt.Errorf("couldn't decode response: %v", err)
+		}
+	}
+	return resp.Status(), nil
+}
+
+// do performs req, recording it in Stats and retrying transient failures.
+func (u *OSD) do(req *uhc.Request) (resp *uhc.Response, err error) {
+	err = Stats.attempt(u.context(), req.GetMethod(), req.GetPath(), func() (int, error) {
+		var sendErr error
+		if resp, sendErr = req.SendContext(u.context()); resp != nil {
+			return resp.Status(), sendErr
+		}
+		return 0, sendErr
+	})
+	return
+}
+
+func autoscalerPath(clusterID string) string {
+	return path.Join("/api/clusters_mgmt", APIVersion, "clusters", clusterID, "autoscaler")
+}
+
+func addonsPath(clusterID string) string {
+	return path.Join("/api/clusters_mgmt", APIVersion, "clusters", clusterID, "addons")
+}
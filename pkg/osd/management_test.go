@@ -0,0 +1,96 @@
+package osd
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestManagement(t *testing.T) {
+	bodies := map[string]string{}
+	osd, done := replay(t, "management.yaml", func(req *http.Request) {
+		if req.Method == http.MethodPost {
+			data, _ := ioutil.ReadAll(req.Body)
+			bodies[req.URL.Path] = string(data)
+		}
+	})
+	defer done()
+
+	autoscaler := &Autoscaler{
+		BalanceSimilarNodeGroups: true,
+		ResourceLimits:           &AutoscalerResourceLimits{MaxNodesTotal: 20},
+		ScaleDown:                &AutoscalerScaleDown{Enabled: true, UnneededTime: "7m"},
+	}
+	if err := osd.SetClusterAutoscaler("1a2b3c", autoscaler); err != nil {
+		t.Fatalf("failed to configure autoscaler: %v", err)
+	}
+	if expected := string(mustMarshal(t, autoscaler)); bodies[autoscalerPath("1a2b3c")] != expected {
+		t.Errorf("expected autoscaler to be created with %s, got %s", expected, bodies[autoscalerPath("1a2b3c")])
+	}
+
+	if actual, err := osd.ClusterAutoscaler("1a2b3c"); err != nil {
+		t.Errorf("failed to get autoscaler: %v", err)
+	} else if !reflect.DeepEqual(actual, autoscaler) {
+		t.Errorf("expected autoscaler %+v, got %+v", autoscaler, actual)
+	}
+
+	if err := osd.DeleteClusterAutoscaler("1a2b3c"); err != nil {
+		t.Errorf("failed to delete autoscaler: %v", err)
+	}
+
+	params := map[string]string{"descheduling-interval-seconds": "3600"}
+	if err := osd.ConfigureAddon("1a2b3c", DeschedulerAddon, params); err != nil {
+		t.Fatalf("failed to install descheduler: %v", err)
+	}
+	var installed AddonInstallation
+	if err := json.Unmarshal([]byte(bodies[addonsPath("1a2b3c")]), &installed); err != nil {
+		t.Errorf("invalid add-on body: %v", err)
+	} else if installed.Addon.ID != DeschedulerAddon || !reflect.DeepEqual(installed.Params(), params) {
+		t.Errorf("expected descheduler to be installed with %v, got %+v", params, installed)
+	}
+
+	if err := osd.WaitForAddon("1a2b3c", DeschedulerAddon, time.Millisecond, time.Second); err != nil {
+		t.Errorf("failed waiting for descheduler: %v", err)
+	}
+
+	if err := osd.DeleteAddon("1a2b3c", DeschedulerAddon); err != nil {
+		t.Errorf("failed to delete descheduler: %v", err)
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	original := &Autoscaler{
+		MaxPodGracePeriod: 600,
+		ResourceLimits:    &AutoscalerResourceLimits{MaxNodesTotal: 20},
+		ScaleDown:         &AutoscalerScaleDown{Enabled: true, UnneededTime: "7m", DelayAfterAdd: "10m"},
+	}
+	modified := &Autoscaler{
+		BalanceSimilarNodeGroups: true,
+		ScaleDown:                &AutoscalerScaleDown{Enabled: true, UnneededTime: "5m"},
+	}
+
+	data, err := mergePatch(original, modified)
+	if err != nil {
+		t.Fatalf("failed to create patch: %v", err)
+	}
+	var patch map[string]interface{}
+	if err = json.Unmarshal(data, &patch); err != nil {
+		t.Fatalf("invalid patch: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"balance_similar_node_groups": true,
+		"max_pod_grace_period":        nil,
+		"resource_limits":             nil,
+		"scale_down": map[string]interface{}{
+			"unneeded_time":   "5m",
+			"delay_after_add": nil,
+		},
+	}
+	if !reflect.DeepEqual(patch, expected) {
+		t.Errorf("expected patch %v, got %v", expected, patch)
+	}
+}
@@ -2,12 +2,21 @@
 package osd
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"sync"
+	"time"
+
 	uhc "github.com/openshift-online/uhc-sdk-go/pkg/client"
+	"github.com/openshift-online/uhc-sdk-go/pkg/client/accountsmgmt"
 	accounts "github.com/openshift-online/uhc-sdk-go/pkg/client/accountsmgmt/v1"
+	"github.com/openshift-online/uhc-sdk-go/pkg/client/clustersmgmt"
 	clusters "github.com/openshift-online/uhc-sdk-go/pkg/client/clustersmgmt/v1"
 	uhcerr "github.com/openshift-online/uhc-sdk-go/pkg/client/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/openshift/osde2e/pkg/config"
 )
 
 const (
@@ -23,6 +32,39 @@ const (
 
 // New setups a client to connect to OSD.
 func New(token, env string, debug bool) (*OSD, error) {
+	return Connect(token, Environments.Choose(env), ChooseTokenURL(env), debug)
+}
+
+// NewForConfig setups a client to connect to the OSD environment of cfg. FedRAMP environments are authenticated with
+// the client credentials of cfg, others with its token.
+func NewForConfig(cfg *config.Config) (*OSD, error) {
+	if !IsFedRAMP(cfg.OSDEnv) {
+		return New(cfg.UHCToken, cfg.OSDEnv, cfg.DebugOSD)
+	}
+	if cfg.OCMClientID == "" || cfg.OCMClientSecret == "" {
+		return nil, fmt.Errorf("OCM_CLIENT_ID and OCM_CLIENT_SECRET must be set to use %s", cfg.OSDEnv)
+	}
+	return ConnectClient(cfg.OCMClientID, cfg.OCMClientSecret, Environments.Choose(cfg.OSDEnv),
+		ChooseTokenURL(cfg.OSDEnv), cfg.DebugOSD)
+}
+
+// Connect setups a client to connect to OSD at url, requesting access tokens from tokenURL.
+func Connect(token, url, tokenURL string, debug bool) (*OSD, error) {
+	return connect(url, tokenURL, debug, func(b *uhc.ConnectionBuilder) *uhc.ConnectionBuilder {
+		return b.Client(ClientID, "").Tokens(token)
+	})
+}
+
+// ConnectClient setups a client to connect to OSD at url, requesting access tokens for the client credentials
+// clientID and clientSecret from tokenURL.
+func ConnectClient(clientID, clientSecret, url, tokenURL string, debug bool) (*OSD, error) {
+	return connect(url, tokenURL, debug, func(b *uhc.ConnectionBuilder) *uhc.ConnectionBuilder {
+		return b.Client(clientID, clientSecret)
+	})
+}
+
+// connect setups a client to connect to OSD at url, authenticated by auth.
+func connect(url, tokenURL string, debug bool, auth func(*uhc.ConnectionBuilder) *uhc.ConnectionBuilder) (*OSD, error) {
 	logger, err := uhc.NewGoLoggerBuilder().
 		Debug(debug).
 		Build()
@@ -30,15 +72,10 @@ func New(token, env string, debug bool) (*OSD, error) {
 		return nil, fmt.Errorf("couldn't build logger: %v", err)
 	}
 
-	// select correct environment
-	url := Environments.Choose(env)
-
-	builder := uhc.NewConnectionBuilder().
+	builder := auth(uhc.NewConnectionBuilder().
 		URL(url).
-		TokenURL(TokenURL).
-		Client(ClientID, "").
-		Logger(logger).
-		Tokens(token)
+		TokenURL(tokenURL).
+		Logger(logger))
 
 	conn, err := builder.Build()
 	if err != nil {
@@ -53,11 +90,51 @@ func New(token, env string, debug bool) (*OSD, error) {
 // OSD acts as a client to manage an instance.
 type OSD struct {
 	conn *uhc.Connection
+
+	// Install is the progress of the last cluster waited on to be ready.
+	Install *InstallProgress
+
+	// InstallFailure is why the last cluster investigated with InstallForensics failed to install.
+	InstallFailure *InstallFailure
+
+	mu           sync.Mutex
+	ctx          context.Context
+	versionCache *versionCache
+
+	// launched is the ID of the last cluster launched.
+	launched string
+}
+
+// SetContext makes later requests and waits use ctx, stopping them once it's done. The context previously set is
+// returned so it can be restored.
+func (u *OSD) SetContext(ctx context.Context) (previous context.Context) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	previous, u.ctx = u.ctx, ctx
+	return
+}
+
+// context returns the context requests are made with. It's never done unless one was set.
+func (u *OSD) context() context.Context {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.ctx == nil {
+		return context.Background()
+	}
+	return u.ctx
+}
+
+// poll checks condition every interval until it returns true, an error, timeout is reached, or the context of u is
+// done. wait.ErrWaitTimeout is returned if condition wasn't met in time.
+func (u *OSD) poll(interval, timeout time.Duration, condition wait.ConditionFunc) error {
+	ctx, cancel := context.WithTimeout(u.context(), timeout)
+	defer cancel()
+	return wait.PollImmediateUntil(interval, condition, ctx.Done())
 }
 
 // CurrentAccount returns the current account being used.
 func (u *OSD) CurrentAccount() (*accounts.Account, error) {
-	act, err := u.conn.AccountsMgmt().V1().CurrentAccount().Get().Send()
+	act, err := u.accountsMgmt().CurrentAccount().Get().SendContext(u.context())
 	if err == nil && act != nil {
 		err = errResp(act.Error())
 	} else if act == nil {
@@ -66,9 +143,19 @@ func (u *OSD) CurrentAccount() (*accounts.Account, error) {
 	return act.Body(), err
 }
 
+// accountsMgmt returns a client for the accounts management API which records requests in Stats.
+func (u *OSD) accountsMgmt() *accounts.RootClient {
+	return accountsmgmt.NewClient(&statsTransport{rt: u.conn}, "/api/accounts_mgmt", "/api/accounts_mgmt").V1()
+}
+
+// clustersMgmt returns a client for the clusters management API which records requests in Stats.
+func (u *OSD) clustersMgmt() *clusters.RootClient {
+	return clustersmgmt.NewClient(&statsTransport{rt: u.conn}, "/api/clusters_mgmt", "/api/clusters_mgmt").V1()
+}
+
 // clusters returns a client used to perform cluster operations.
 func (u *OSD) clusters() *clusters.ClustersClient {
-	return u.conn.ClustersMgmt().V1().Clusters()
+	return u.clustersMgmt().Clusters()
 }
 
 // cluster returns the client for a specific cluster
@@ -78,7 +165,7 @@ func (u *OSD) cluster(clusterID string) *clusters.ClusterClient {
 
 // versions returns a client used to retrieve versions currently offered by OSD.
 func (u *OSD) versions() *clusters.VersionsClient {
-	return u.conn.ClustersMgmt().V1().Versions()
+	return u.clustersMgmt().Versions()
 }
 
 func errResp(resp *uhcerr.Error) error {
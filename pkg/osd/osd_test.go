@@ -0,0 +1,211 @@
+package osd
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/openshift/osde2e/pkg/cassette"
+	"github.com/openshift/osde2e/pkg/config"
+)
+
+// replay connects to an OSD API serving the recording in testdata/name, passing requests to inspect if it's set.
+func replay(t *testing.T, name string, inspect func(*http.Request)) (*OSD, func()) {
+	c, err := cassette.Load(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("failed to load cassette: %v", err)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if inspect != nil {
+			inspect(req)
+		}
+		c.ServeHTTP(w, req)
+	}))
+
+	osd, err := Connect(cassette.FakeToken("access"), srv.URL, srv.URL+"/token", false)
+	if err != nil {
+		srv.Close()
+		t.Fatalf("failed to connect: %v", err)
+	}
+
+	return osd, func() {
+		srv.Close()
+		for _, req := range c.Missed() {
+			t.Errorf("request wasn't recorded: %+v", req)
+		}
+	}
+}
+
+func TestVersionSelection(t *testing.T) {
+	osd, done := replay(t, "versions.yaml", nil)
+	defer done()
+
+	if v, err := osd.DefaultVersion(); err != nil {
+		t.Errorf("failed getting default version: %v", err)
+	} else if v != "openshift-v4.1.14" {
+		t.Errorf("expected default 'openshift-v4.1.14', got '%s'", v)
+	}
+
+	if v, err := osd.LatestPrerelease(4, 2, "nightly"); err != nil {
+		t.Errorf("failed getting latest nightly: %v", err)
+	} else if v != "openshift-v4.2.0-0.nightly-2019-09-23-115152" {
+		t.Errorf("unexpected latest 4.2 nightly '%s'", v)
+	}
+
+	if v, err := osd.PreviousVersion("openshift-v4.1.14"); err != nil {
+		t.Errorf("failed getting previous version: %v", err)
+	} else if v != "openshift-v4.1.13" {
+		t.Errorf("expected previous 'openshift-v4.1.13', got '%s'", v)
+	}
+
+	if _, err := osd.LatestPrerelease(5, -1, "nightly"); err == nil {
+		t.Error("expected error without any 5.x nightlies")
+	}
+}
+
+func TestLaunchCluster(t *testing.T) {
+	var body map[string]interface{}
+	osd, done := replay(t, "cluster.yaml", func(req *http.Request) {
+		if req.Method == http.MethodPost {
+			data, _ := ioutil.ReadAll(req.Body)
+			if err := json.Unmarshal(data, &body); err != nil {
+				t.Errorf("invalid cluster body: %v", err)
+			}
+		}
+	})
+	defer done()
+
+	cfg := &config.Config{
+		ClusterName:        "osde2e-abc",
+		ClusterVersion:     "openshift-v4.1.14",
+		CloudProvider:      "aws",
+		Region:             "us-west-2",
+		MultiAZ:            true,
+		ComputeNodes:       4,
+		ComputeMachineType: "m5.2xlarge",
+		NetworkType:        config.NetworkOVNKubernetes,
+		Hypershift:         true,
+		ClusterExpiry:      8 * time.Hour,
+	}
+	clusterID, err := osd.LaunchCluster(cfg)
+	if err != nil {
+		t.Fatalf("failed to launch cluster: %v", err)
+	} else if clusterID != "1a2b3c" {
+		t.Errorf("expected cluster '1a2b3c', got '%s'", clusterID)
+	}
+
+	for field, expected := range map[string]interface{}{
+		"name":           "osde2e-abc",
+		"multi_az":       true,
+		"region":         map[string]interface{}{"kind": "CloudRegion", "id": "us-west-2"},
+		"cloud_provider": map[string]interface{}{"id": "aws"},
+		"version":        map[string]interface{}{"kind": "Version", "id": "openshift-v4.1.14"},
+		"flavour":        map[string]interface{}{"kind": "Flavour", "id": DefaultFlavour},
+		"nodes":          map[string]interface{}{"compute": 4, "compute_machine_type": map[string]interface{}{"id": "m5.2xlarge"}},
+		"network":        map[string]interface{}{"type": config.NetworkOVNKubernetes},
+		"hypershift":     map[string]interface{}{"enabled": true},
+	} {
+		if actual, _ := json.Marshal(body[field]); string(actual) != string(mustMarshal(t, expected)) {
+			t.Errorf("expected cluster %s to be %s, got %s", field, mustMarshal(t, expected), actual)
+		}
+	}
+
+	if expiry, ok := body["expiration_timestamp"].(string); !ok {
+		t.Error("expected cluster to have an expiration")
+	} else if ts, err := time.Parse(time.RFC3339, expiry); err != nil || ts.Before(time.Now()) {
+		t.Errorf("expected future expiration, got '%s'", expiry)
+	}
+
+	cfg.NetworkType = "Calico"
+	if _, err = osd.LaunchCluster(cfg); err == nil {
+		t.Error("expected error for unknown network type")
+	}
+}
+
+func TestSetClusterExpiry(t *testing.T) {
+	var body map[string]string
+	osd, done := replay(t, "expiry.yaml", func(req *http.Request) {
+		data, _ := ioutil.ReadAll(req.Body)
+		if err := json.Unmarshal(data, &body); err != nil {
+			t.Errorf("invalid cluster body: %v", err)
+		}
+	})
+	defer done()
+
+	expiry := time.Date(2019, 10, 2, 12, 0, 0, 0, time.UTC)
+	if err := osd.SetClusterExpiry("1a2b3c", expiry); err != nil {
+		t.Fatalf("failed to set expiry: %v", err)
+	} else if body["expiration_timestamp"] != "2019-10-02T12:00:00Z" {
+		t.Errorf("expected expiration to be set, got %v", body)
+	}
+}
+
+func TestArchitectureOptions(t *testing.T) {
+	for _, tc := range []struct {
+		arch, version, machineType string
+		expectedVersion            string
+		expectedMachineType        string
+	}{
+		{"", "openshift-v4.1.14", "", "openshift-v4.1.14", ""},
+		{config.ArchitectureAMD64, "openshift-v4.1.14", "m5.xlarge", "openshift-v4.1.14", "m5.xlarge"},
+		{config.ArchitectureARM64, "openshift-v4.1.14", "", "openshift-v4.1.14-multi", DefaultARM64MachineType},
+		{config.ArchitectureARM64, "openshift-v4.1.14-multi", "m6g.2xlarge", "openshift-v4.1.14-multi", "m6g.2xlarge"},
+		{config.ArchitectureMulti, "openshift-v4.1.14", "m5.xlarge", "openshift-v4.1.14-multi", "m5.xlarge"},
+	} {
+		cfg := &config.Config{ComputeArchitecture: tc.arch, ClusterVersion: tc.version, ComputeMachineType: tc.machineType}
+		version, machineType, err := architectureOptions(cfg)
+		if err != nil {
+			t.Errorf("architecture '%s' should be valid: %v", tc.arch, err)
+		} else if version != tc.expectedVersion || machineType != tc.expectedMachineType {
+			t.Errorf("expected '%s' on '%s' for architecture '%s', got '%s' on '%s'", tc.expectedVersion,
+				tc.expectedMachineType, tc.arch, version, machineType)
+		}
+	}
+
+	if _, _, err := architectureOptions(&config.Config{ComputeArchitecture: "s390x"}); err == nil {
+		t.Error("expected error for unknown architecture")
+	}
+}
+
+func TestWaitForClusterReady(t *testing.T) {
+	osd, done := replay(t, "cluster.yaml", nil)
+	defer done()
+
+	osd.launched = "1a2b3c"
+	if err := osd.WaitForClusterReady("1a2b3c", time.Second, time.Millisecond); err != nil {
+		t.Fatalf("failed waiting for cluster: %v", err)
+	}
+
+	if stage, _ := osd.Install.Current(); stage != StageComplete {
+		t.Errorf("expected install to be complete, got '%s'", stage)
+	}
+	if _, ok := osd.Install.Metadata()["install-"+string(StageBootstrap)+"-seconds"]; !ok {
+		t.Errorf("expected bootstrap stage to be observed: %v", osd.Install.Metadata())
+	}
+	if result := osd.Install.Metadata()[InstallResultKey]; result != "SUCCESS" {
+		t.Errorf("expected launched cluster to be installed, got %v", result)
+	}
+
+	if kubeconfig, err := osd.ClusterKubeconfig("1a2b3c"); err != nil {
+		t.Errorf("failed getting kubeconfig: %v", err)
+	} else if string(kubeconfig) != cassette.Redacted {
+		t.Errorf("expected redacted kubeconfig, got '%s'", kubeconfig)
+	}
+
+	if err := osd.DeleteCluster("1a2b3c"); err == nil {
+		t.Error("expected error deleting missing cluster")
+	}
+}
+
+func mustMarshal(t *testing.T, v interface{}) []byte {
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("failed to encode: %v", err)
+	}
+	return data
+}
@@ -0,0 +1,36 @@
+package osd
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestPoolClusters(t *testing.T) {
+	var patched map[string]map[string]string
+	osd, done := replay(t, "pool.yaml", func(req *http.Request) {
+		if req.Method == http.MethodPatch {
+			data, _ := ioutil.ReadAll(req.Body)
+			if err := json.Unmarshal(data, &patched); err != nil {
+				t.Errorf("invalid cluster body: %v", err)
+			}
+		}
+	})
+	defer done()
+
+	clusters, err := osd.ListClusters("name like 'osde2e-pool-%'")
+	if err != nil {
+		t.Fatalf("failed to list clusters: %v", err)
+	} else if len(clusters) != 2 || clusters[0].ID() != "1a2b3c" || clusters[0].Properties()["osde2e-pool-profile"] != "default" {
+		t.Errorf("expected both pool clusters with properties, got %v", clusters)
+	}
+
+	props := map[string]string{"osde2e-pool-profile": "default", "osde2e-pool-claimed": "ci"}
+	if err = osd.SetClusterProperties("1a2b3c", props); err != nil {
+		t.Fatalf("failed to set properties: %v", err)
+	} else if !reflect.DeepEqual(patched["properties"], props) {
+		t.Errorf("expected properties %v to be set, got %v", props, patched)
+	}
+}
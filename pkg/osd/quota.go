@@ -23,7 +23,7 @@ const (
 func (u *OSD) CheckQuota(cfg *config.Config) (bool, error) {
 	// get flavour being deployed
 	flavourId := u.Flavour(cfg)
-	flavourReq, err := u.conn.ClustersMgmt().V1().Flavours().Flavour(flavourId).Get().Send()
+	flavourReq, err := u.clustersMgmt().Flavours().Flavour(flavourId).Get().SendContext(u.context())
 	if err == nil && flavourReq != nil {
 		err = errResp(flavourReq.Error())
 	} else if flavourReq == nil || flavourReq.Body().Empty() {
@@ -93,7 +93,7 @@ func HasQuotaFor(q *accounts.ResourceQuota, cfg *config.Config, resourceType, ma
 func (u *OSD) getQuotaSummary(orgId string) (*resourceSummaryListResponse, error) {
 	resp := new(resourceSummaryListResponse)
 	summaryPath := path.Join("/api/accounts_mgmt", APIVersion, "organizations", orgId, "quota_summary")
-	rawResp, err := u.conn.Get().Path(summaryPath).Send()
+	rawResp, err := u.do(u.conn.Get().Path(summaryPath))
 	if err == nil && rawResp.Status() != http.StatusOK {
 		resp.err, err = osderrors.UnmarshalError(rawResp.Bytes())
 	} else if rawResp != nil {
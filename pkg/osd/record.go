@@ -0,0 +1,47 @@
+package osd
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/openshift/osde2e/pkg/cassette"
+	"github.com/openshift/osde2e/pkg/config"
+)
+
+// Record setups a client to connect to the OSD environment of cfg which records sanitized interactions in c. Recording
+// stops when the returned function is called.
+func Record(c *cassette.Cassette, cfg *config.Config) (*OSD, func(), error) {
+	api, err := cassette.NewProxy(c, Environments.Choose(cfg.OSDEnv))
+	if err != nil {
+		return nil, nil, fmt.Errorf("couldn't record OSD API: %v", err)
+	}
+
+	tokenURL, err := url.Parse(ChooseTokenURL(cfg.OSDEnv))
+	if err != nil {
+		api.Close()
+		return nil, nil, fmt.Errorf("invalid token URL: %v", err)
+	}
+
+	sso, err := cassette.NewProxy(c, tokenURL.Scheme+"://"+tokenURL.Host)
+	if err != nil {
+		api.Close()
+		return nil, nil, fmt.Errorf("couldn't record token requests: %v", err)
+	}
+
+	stop := func() {
+		api.Close()
+		sso.Close()
+	}
+
+	var osd *OSD
+	if IsFedRAMP(cfg.OSDEnv) {
+		osd, err = ConnectClient(cfg.OCMClientID, cfg.OCMClientSecret, api.URL, sso.URL+tokenURL.Path, cfg.DebugOSD)
+	} else {
+		osd, err = Connect(cfg.UHCToken, api.URL, sso.URL+tokenURL.Path, cfg.DebugOSD)
+	}
+	if err != nil {
+		stop()
+		return nil, nil, err
+	}
+	return osd, stop, nil
+}
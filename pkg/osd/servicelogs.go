@@ -0,0 +1,57 @@
+package osd
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+)
+
+const (
+	// serviceLogsPath is where entries of cluster service logs are written.
+	serviceLogsPath = "/api/service_logs/v1/cluster_logs"
+
+	// ServiceLogName is the service entries written by osde2e are attributed to.
+	ServiceLogName = "osde2e"
+
+	// ServiceLogInfo is the severity of entries describing what osde2e did to a cluster.
+	ServiceLogInfo = "Info"
+)
+
+// ServiceLog is an entry of a cluster's service log, which is shown to anyone looking at the cluster in OCM.
+type ServiceLog struct {
+	ClusterID    string `json:"cluster_id"`
+	ClusterUUID  string `json:"cluster_uuid,omitempty"`
+	Severity     string `json:"severity"`
+	ServiceName  string `json:"service_name"`
+	Summary      string `json:"summary"`
+	Description  string `json:"description"`
+	InternalOnly bool   `json:"internal_only"`
+}
+
+// WriteServiceLog adds an internal entry to the service log of clusterID saying what osde2e did to it.
+func (u *OSD) WriteServiceLog(clusterID, summary, description string) error {
+	var cluster struct {
+		ExternalID string `json:"external_id"`
+	}
+	if _, err := u.send(u.conn.Get().Path(path.Join(clustersPath, clusterID)), &cluster); err != nil {
+		return fmt.Errorf("couldn't get cluster '%s': %v", clusterID, err)
+	}
+
+	data, err := json.Marshal(ServiceLog{
+		ClusterID:    clusterID,
+		ClusterUUID:  cluster.ExternalID,
+		Severity:     ServiceLogInfo,
+		ServiceName:  ServiceLogName,
+		Summary:      summary,
+		Description:  description,
+		InternalOnly: true,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't encode service log entry: %v", err)
+	}
+
+	if _, err = u.send(u.conn.Post().Path(serviceLogsPath).Bytes(data), nil); err != nil {
+		return fmt.Errorf("couldn't write service log of cluster '%s': %v", clusterID, err)
+	}
+	return nil
+}
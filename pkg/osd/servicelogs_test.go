@@ -0,0 +1,48 @@
+package osd
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+func TestWriteServiceLog(t *testing.T) {
+	var written []ServiceLog
+	osd, done := replay(t, "servicelogs.yaml", func(req *http.Request) {
+		if req.Method != http.MethodPost {
+			return
+		}
+		data, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			t.Errorf("failed to read request: %v", err)
+			return
+		}
+		var entry ServiceLog
+		if err = json.Unmarshal(data, &entry); err != nil {
+			t.Errorf("failed to decode service log entry: %v", err)
+		}
+		written = append(written, entry)
+	})
+	defer done()
+
+	if err := osd.WriteServiceLog("1a2b3c", "osde2e upgraded the cluster", "Upgraded to 4.3.1 by job 123."); err != nil {
+		t.Fatalf("failed to write service log: %v", err)
+	}
+	expected := ServiceLog{
+		ClusterID:    "1a2b3c",
+		ClusterUUID:  "de9b4a0c-7a3e-4c5e-9c8e-2f1b6a0d3e4f",
+		Severity:     ServiceLogInfo,
+		ServiceName:  ServiceLogName,
+		Summary:      "osde2e upgraded the cluster",
+		Description:  "Upgraded to 4.3.1 by job 123.",
+		InternalOnly: true,
+	}
+	if len(written) != 1 || written[0] != expected {
+		t.Errorf("expected service log entry %+v, got %+v", expected, written)
+	}
+
+	if err := osd.WriteServiceLog("4d5e6f", "osde2e upgraded the cluster", ""); err == nil {
+		t.Error("expected writing the service log of a missing cluster to fail")
+	}
+}
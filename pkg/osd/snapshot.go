@@ -0,0 +1,227 @@
+package osd
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openshift-online/uhc-sdk-go/pkg/client/clustersmgmt/v1"
+	"k8s.io/test-infra/testgrid/metadata/junit"
+
+	"github.com/openshift/osde2e/pkg/junitprops"
+)
+
+// TODO: use uhc-sdk-go hibernation and machine pool types once available
+
+const (
+	// ClusterStateHibernating is the state of clusters which have been hibernated.
+	ClusterStateHibernating v1.ClusterState = "hibernating"
+
+	// ClusterStateResuming is the state of clusters being woken from hibernation.
+	ClusterStateResuming v1.ClusterState = "resuming"
+
+	// SnapshotSuiteName is the JUnit suite containing checks that OCM configuration survived operations.
+	SnapshotSuiteName = "OCM configuration"
+)
+
+// ClusterSnapshot is the configuration of a cluster kept by OCM, which should survive operations on the cluster such
+// as hibernation and upgrades.
+type ClusterSnapshot struct {
+	Properties map[string]string `json:"properties"`
+	Expiry     string            `json:"expiration_timestamp"`
+
+	// Addons are the add-ons installed on the cluster by ID.
+	Addons map[string]AddonSnapshot `json:"addons"`
+
+	// MachinePools are the additional machine pools of the cluster by ID.
+	MachinePools map[string]MachinePool `json:"machine_pools"`
+}
+
+// AddonSnapshot is the state of an add-on installation.
+type AddonSnapshot struct {
+	State  string            `json:"state"`
+	Params map[string]string `json:"params"`
+}
+
+// MachinePool is a group of compute nodes of a cluster.
+type MachinePool struct {
+	ID                string            `json:"id"`
+	InstanceType      string            `json:"instance_type"`
+	Replicas          int               `json:"replicas"`
+	AvailabilityZones []string          `json:"availability_zones,omitempty"`
+	Labels            map[string]string `json:"labels,omitempty"`
+	Taints            []Taint           `json:"taints,omitempty"`
+}
+
+// ClusterSnapshot fetches the configuration of clusterID kept by OCM. Machine pools are left empty if OCM doesn't
+// serve them.
+func (u *OSD) ClusterSnapshot(clusterID string) (*ClusterSnapshot, error) {
+	snapshot := &ClusterSnapshot{
+		Addons:       map[string]AddonSnapshot{},
+		MachinePools: map[string]MachinePool{},
+	}
+	if _, err := u.send(u.conn.Get().Path(path.Join(clustersPath, clusterID)), snapshot); err != nil {
+		return nil, fmt.Errorf("couldn't get cluster '%s': %v", clusterID, err)
+	}
+
+	var addons struct {
+		Items []AddonInstallation `json:"items"`
+	}
+	if _, err := u.send(u.conn.Get().Path(addonsPath(clusterID)), &addons); err != nil {
+		return nil, fmt.Errorf("couldn't list add-ons of cluster '%s': %v", clusterID, err)
+	}
+	for _, a := range addons.Items {
+		snapshot.Addons[a.Addon.ID] = AddonSnapshot{State: a.State, Params: a.Params()}
+	}
+
+	var pools struct {
+		Items []MachinePool `json:"items"`
+	}
+	status, err := u.send(u.conn.Get().Path(machinePoolsPath(clusterID)), &pools)
+	if err != nil && status != http.StatusNotFound {
+		return nil, fmt.Errorf("couldn't list machine pools of cluster '%s': %v", clusterID, err)
+	}
+	for _, p := range pools.Items {
+		sort.Strings(p.AvailabilityZones)
+		snapshot.MachinePools[p.ID] = p
+	}
+	return snapshot, nil
+}
+
+// Drift describes how s changed to become after, sorted by what changed. Nothing is returned if they're the same.
+func (s *ClusterSnapshot) Drift(after *ClusterSnapshot) (drift []string) {
+	for _, k := range unionKeys(s.Properties, after.Properties) {
+		if before, now := s.Properties[k], after.Properties[k]; before != now {
+			drift = append(drift, fmt.Sprintf("property '%s' changed from '%s' to '%s'", k, before, now))
+		}
+	}
+
+	if !sameTime(s.Expiry, after.Expiry) {
+		drift = append(drift, fmt.Sprintf("expiry changed from '%s' to '%s'", s.Expiry, after.Expiry))
+	}
+
+	for id, before := range s.Addons {
+		if now, ok := after.Addons[id]; !ok {
+			drift = append(drift, fmt.Sprintf("add-on '%s' was removed", id))
+		} else if before.State != now.State {
+			drift = append(drift, fmt.Sprintf("add-on '%s' changed state from '%s' to '%s'", id, before.State, now.State))
+		} else if !reflect.DeepEqual(before.Params, now.Params) {
+			drift = append(drift, fmt.Sprintf("add-on '%s' changed parameters from %v to %v", id, before.Params, now.Params))
+		}
+	}
+	for id := range after.Addons {
+		if _, ok := s.Addons[id]; !ok {
+			drift = append(drift, fmt.Sprintf("add-on '%s' was added", id))
+		}
+	}
+
+	for id, before := range s.MachinePools {
+		if now, ok := after.MachinePools[id]; !ok {
+			drift = append(drift, fmt.Sprintf("machine pool '%s' was removed", id))
+		} else if !reflect.DeepEqual(before, now) {
+			drift = append(drift, fmt.Sprintf("machine pool '%s' changed from %+v to %+v", id, before, now))
+		}
+	}
+	for id := range after.MachinePools {
+		if _, ok := s.MachinePools[id]; !ok {
+			drift = append(drift, fmt.Sprintf("machine pool '%s' was added", id))
+		}
+	}
+
+	sort.Strings(drift)
+	return
+}
+
+// HibernateCluster stops the nodes of clusterID until it's resumed.
+func (u *OSD) HibernateCluster(clusterID string) error {
+	if err := u.clusterAction(clusterID, "hibernate"); err != nil {
+		return fmt.Errorf("couldn't hibernate cluster '%s': %v", clusterID, err)
+	}
+	return nil
+}
+
+// ResumeCluster starts the nodes of clusterID after hibernation.
+func (u *OSD) ResumeCluster(clusterID string) error {
+	if err := u.clusterAction(clusterID, "resume"); err != nil {
+		return fmt.Errorf("couldn't resume cluster '%s': %v", clusterID, err)
+	}
+	return nil
+}
+
+// clusterAction requests OCM perform action on clusterID, which takes no parameters.
+func (u *OSD) clusterAction(clusterID, action string) error {
+	_, err := u.send(u.conn.Post().Path(path.Join(clustersPath, clusterID, action)).Bytes([]byte("{}")), nil)
+	return err
+}
+
+// WaitForClusterState blocks until clusterID reaches state or timeout, checking every interval.
+func (u *OSD) WaitForClusterState(clusterID string, state v1.ClusterState, interval, timeout time.Duration) error {
+	log.Printf("Waiting %v for cluster '%s' to be %s...", timeout, clusterID, state)
+	return u.poll(interval, timeout, func() (bool, error) {
+		current, err := u.ClusterState(clusterID)
+		if err != nil {
+			log.Printf("Error getting state of cluster '%s': %v", clusterID, err)
+			return false, nil
+		} else if current == v1.ClusterStateError {
+			return false, fmt.Errorf("cluster '%s' errored waiting to be %s", clusterID, state)
+		}
+		return current == state, nil
+	})
+}
+
+// WriteDriftJUnit records whether OCM configuration survived operation as a testcase in dir, failing it if there was
+// any drift.
+func WriteDriftJUnit(dir, suffix, operation string, drift []string) error {
+	result := junit.Result{
+		Name:      fmt.Sprintf("should be unchanged by %s", operation),
+		ClassName: SnapshotSuiteName,
+	}
+	suite := junit.Suite{
+		Name:  SnapshotSuiteName,
+		Tests: 1,
+	}
+
+	if len(drift) != 0 {
+		msg := fmt.Sprintf("%d changes to OCM configuration after %s", len(drift), operation)
+		output := strings.Join(drift, "\n")
+		result.Failure, result.Output = &msg, &output
+		suite.Failures++
+	}
+	suite.Results = append(suite.Results, result)
+
+	return junitprops.WriteSuite(dir, "ocm_config_"+operation, suffix, suite)
+}
+
+func machinePoolsPath(clusterID string) string {
+	return path.Join(clustersPath, clusterID, "machine_pools")
+}
+
+// unionKeys returns the keys of a and b, sorted.
+func unionKeys(a, b map[string]string) (keys []string) {
+	seen := map[string]bool{}
+	for _, m := range []map[string]string{a, b} {
+		for k := range m {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return
+}
+
+// sameTime returns true if timestamps a and b are the same instant, comparing them as strings if they can't be parsed.
+func sameTime(a, b string) bool {
+	ta, errA := time.Parse(time.RFC3339, a)
+	tb, errB := time.Parse(time.RFC3339, b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	return ta.Equal(tb)
+}
@@ -0,0 +1,88 @@
+package osd
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestClusterSnapshot(t *testing.T) {
+	osd, done := replay(t, "snapshot.yaml", nil)
+	defer done()
+
+	snapshot, err := osd.ClusterSnapshot("1a2b3c")
+	if err != nil {
+		t.Fatalf("failed to get snapshot: %v", err)
+	}
+
+	expected := &ClusterSnapshot{
+		Properties: map[string]string{"osde2e-pool-profile": "default"},
+		Expiry:     "2019-10-02T12:00:00Z",
+		Addons: map[string]AddonSnapshot{
+			DeschedulerAddon: {State: AddonReady, Params: map[string]string{"interval": "3600"}},
+		},
+		MachinePools: map[string]MachinePool{
+			"infra": {
+				ID:                "infra",
+				InstanceType:      "r5.xlarge",
+				Replicas:          3,
+				AvailabilityZones: []string{"us-east-1a", "us-east-1b"},
+				Labels:            map[string]string{"node-role": "infra"},
+			},
+		},
+	}
+	if !reflect.DeepEqual(snapshot, expected) {
+		t.Errorf("expected snapshot %+v, got %+v", expected, snapshot)
+	}
+
+	if err = osd.HibernateCluster("1a2b3c"); err != nil {
+		t.Fatalf("failed to hibernate: %v", err)
+	}
+	if err = osd.WaitForClusterState("1a2b3c", ClusterStateHibernating, time.Millisecond, time.Second); err != nil {
+		t.Errorf("failed waiting for hibernation: %v", err)
+	}
+}
+
+func TestClusterSnapshotDrift(t *testing.T) {
+	before := &ClusterSnapshot{
+		Properties: map[string]string{"owner": "osde2e", "claimed": "true"},
+		Expiry:     "2019-10-02T12:00:00Z",
+		Addons: map[string]AddonSnapshot{
+			"descheduler": {State: AddonReady, Params: map[string]string{"interval": "3600"}},
+			"logging":     {State: AddonReady},
+		},
+		MachinePools: map[string]MachinePool{
+			"infra": {ID: "infra", InstanceType: "r5.xlarge", Replicas: 3},
+		},
+	}
+
+	same := *before
+	same.Expiry = "2019-10-02T14:00:00+02:00"
+	if drift := before.Drift(&same); len(drift) != 0 {
+		t.Errorf("expected no drift, got %v", drift)
+	}
+
+	after := &ClusterSnapshot{
+		Properties: map[string]string{"owner": "osde2e", "region": "us-east-1"},
+		Expiry:     "2019-10-03T12:00:00Z",
+		Addons: map[string]AddonSnapshot{
+			"descheduler": {State: AddonReady, Params: map[string]string{"interval": "60"}},
+			"monitoring":  {State: AddonReady},
+		},
+		MachinePools: map[string]MachinePool{
+			"infra": {ID: "infra", InstanceType: "r5.xlarge", Replicas: 2},
+		},
+	}
+	expected := []string{
+		"add-on 'descheduler' changed parameters from map[interval:3600] to map[interval:60]",
+		"add-on 'logging' was removed",
+		"add-on 'monitoring' was added",
+		"expiry changed from '2019-10-02T12:00:00Z' to '2019-10-03T12:00:00Z'",
+		"machine pool 'infra' changed from {ID:infra InstanceType:r5.xlarge Replicas:3 AvailabilityZones:[] Labels:map[] Taints:[]} to {ID:infra InstanceType:r5.xlarge Replicas:2 AvailabilityZones:[] Labels:map[] Taints:[]}",
+		"property 'claimed' changed from 'true' to ''",
+		"property 'region' changed from '' to 'us-east-1'",
+	}
+	if drift := before.Drift(after); !reflect.DeepEqual(drift, expected) {
+		t.Errorf("expected drift %q, got %q", expected, drift)
+	}
+}
@@ -0,0 +1,85 @@
+package osd
+
+import (
+	"fmt"
+	"path"
+)
+
+// ClusterSpec is what OCM expects a cluster to be, which the cluster itself should agree with.
+type ClusterSpec struct {
+	Version string `json:"openshift_version"`
+	MultiAZ bool   `json:"multi_az"`
+	Region  Ref    `json:"region"`
+	Cloud   Ref    `json:"cloud_provider"`
+
+	Nodes   SpecNodes   `json:"nodes"`
+	Network SpecNetwork `json:"network"`
+
+	// Hypershift is set for clusters whose control plane is hosted on a management cluster.
+	Hypershift Hypershift `json:"hypershift"`
+
+	// Addons and MachinePools are what OCM installed on the cluster.
+	Addons       map[string]AddonSnapshot `json:"-"`
+	MachinePools map[string]MachinePool   `json:"-"`
+
+	// AddonNamespaces are the namespaces add-ons install their operators in by ID.
+	AddonNamespaces map[string]string `json:"-"`
+}
+
+// SpecNodes are the nodes OCM expects a cluster to have. Compute nodes are those of the default machine pool.
+type SpecNodes struct {
+	Master            int      `json:"master"`
+	Infra             int      `json:"infra"`
+	Compute           int      `json:"compute"`
+	ComputeType       Ref      `json:"compute_machine_type"`
+	AvailabilityZones []string `json:"availability_zones"`
+
+	// Autoscale is set when the number of compute nodes is scaled by the cluster.
+	Autoscale *SpecAutoscale `json:"autoscale_compute,omitempty"`
+}
+
+// SpecAutoscale is how many compute nodes the cluster may scale between.
+type SpecAutoscale struct {
+	Min int `json:"min_replicas"`
+	Max int `json:"max_replicas"`
+}
+
+// SpecNetwork is how OCM expects a cluster to be networked.
+type SpecNetwork struct {
+	Type        string `json:"type"`
+	MachineCIDR string `json:"machine_cidr"`
+	ServiceCIDR string `json:"service_cidr"`
+	PodCIDR     string `json:"pod_cidr"`
+	HostPrefix  int    `json:"host_prefix"`
+}
+
+// Ref refers to an OCM resource, such as a region, by ID.
+type Ref struct {
+	ID string `json:"id"`
+}
+
+// ClusterSpec fetches what OCM expects clusterID to be, including its add-ons and the namespaces they're installed in.
+func (u *OSD) ClusterSpec(clusterID string) (*ClusterSpec, error) {
+	spec := new(ClusterSpec)
+	if _, err := u.send(u.conn.Get().Path(path.Join(clustersPath, clusterID)), spec); err != nil {
+		return nil, fmt.Errorf("couldn't get cluster '%s': %v", clusterID, err)
+	}
+
+	snapshot, err := u.ClusterSnapshot(clusterID)
+	if err != nil {
+		return nil, err
+	}
+	spec.Addons, spec.MachinePools = snapshot.Addons, snapshot.MachinePools
+
+	spec.AddonNamespaces = make(map[string]string, len(spec.Addons))
+	for id := range spec.Addons {
+		var addon struct {
+			TargetNamespace string `json:"target_namespace"`
+		}
+		if _, err = u.send(u.conn.Get().Path(path.Join("/api/clusters_mgmt", APIVersion, "addons", id)), &addon); err != nil {
+			return nil, fmt.Errorf("couldn't get add-on '%s': %v", id, err)
+		}
+		spec.AddonNamespaces[id] = addon.TargetNamespace
+	}
+	return spec, nil
+}
@@ -0,0 +1,45 @@
+package osd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestClusterSpec(t *testing.T) {
+	osd, done := replay(t, "spec.yaml", nil)
+	defer done()
+
+	spec, err := osd.ClusterSpec("1a2b3c")
+	if err != nil {
+		t.Fatalf("failed to get spec: %v", err)
+	}
+
+	expected := &ClusterSpec{
+		Version: "4.3.1",
+		MultiAZ: true,
+		Region:  Ref{ID: "us-east-1"},
+		Cloud:   Ref{ID: "aws"},
+		Nodes: SpecNodes{
+			Master:            3,
+			Infra:             2,
+			Compute:           9,
+			ComputeType:       Ref{ID: "m5.xlarge"},
+			AvailabilityZones: []string{"us-east-1a", "us-east-1b", "us-east-1c"},
+		},
+		Network: SpecNetwork{
+			Type:        "OpenShiftSDN",
+			MachineCIDR: "10.0.0.0/16",
+			ServiceCIDR: "172.30.0.0/16",
+			PodCIDR:     "10.128.0.0/14",
+			HostPrefix:  23,
+		},
+		Addons: map[string]AddonSnapshot{
+			DeschedulerAddon: {State: AddonReady, Params: map[string]string{}},
+		},
+		MachinePools:    map[string]MachinePool{},
+		AddonNamespaces: map[string]string{DeschedulerAddon: "openshift-kube-descheduler-operator"},
+	}
+	if !reflect.DeepEqual(spec, expected) {
+		t.Errorf("expected spec %+v, got %+v", expected, spec)
+	}
+}
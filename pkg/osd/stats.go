@@ -0,0 +1,290 @@
+package osd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/test-infra/testgrid/metadata/junit"
+
+	"github.com/openshift/osde2e/pkg/junitprops"
+)
+
+const (
+	// StatsSuiteName is the JUnit suite containing the OCM API error budget.
+	StatsSuiteName = "OCM API"
+
+	// StatsFile is the name of the artifact containing the requests made to the OCM API.
+	StatsFile = "ocm-api.json"
+
+	// maxRetries is how many times GET requests which failed transiently are retried.
+	maxRetries = 2
+)
+
+// retryBackoff is how long to wait before the first retry, increasing with each one.
+var retryBackoff = 2 * time.Second
+
+// Classes of errors returned by the OCM API.
+const (
+	// ErrorNetwork is a request which failed without a response, such as a timeout.
+	ErrorNetwork = "network"
+
+	// ErrorThrottled is a request rejected for exceeding rate limits.
+	ErrorThrottled = "throttled"
+
+	// ErrorServer is a request which OCM failed to handle.
+	ErrorServer = "server"
+
+	// ErrorClient is a request OCM rejected, such as for a missing resource. They're expected in normal use so
+	// don't count against the error budget.
+	ErrorClient = "client"
+)
+
+// Stats records every request made to the OCM API by osde2e.
+var Stats = new(APIStats)
+
+// APIStats counts requests made to the OCM API by endpoint, along with their retries and errors.
+type APIStats struct {
+	mu        sync.Mutex
+	endpoints map[string]*EndpointStats
+}
+
+// EndpointStats are the requests made to an endpoint of the OCM API.
+type EndpointStats struct {
+	Method  string `json:"method"`
+	Path    string `json:"path"`
+	Calls   int    `json:"calls"`
+	Retries int    `json:"retries"`
+
+	// Errors counts failed calls by their class.
+	Errors map[string]int `json:"errors,omitempty"`
+}
+
+// APISummary totals the requests made to the OCM API.
+type APISummary struct {
+	Calls   int `json:"calls"`
+	Retries int `json:"retries"`
+
+	// Errors counts calls which failed because of OCM, excluding client errors.
+	Errors int `json:"errors"`
+
+	// ErrorRate is the ratio of calls which failed because of OCM.
+	ErrorRate float64 `json:"errorRate"`
+
+	Endpoints []EndpointStats `json:"endpoints"`
+}
+
+// Summary totals the requests recorded so far, listing endpoints by the most errors then calls.
+func (s *APIStats) Summary() (summary APISummary) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, e := range s.endpoints {
+		stats := *e
+		stats.Errors = make(map[string]int, len(e.Errors))
+		for class, count := range e.Errors {
+			stats.Errors[class] = count
+			if class != ErrorClient {
+				summary.Errors += count
+			}
+		}
+		summary.Calls += e.Calls
+		summary.Retries += e.Retries
+		summary.Endpoints = append(summary.Endpoints, stats)
+	}
+
+	if summary.Calls != 0 {
+		summary.ErrorRate = float64(summary.Errors) / float64(summary.Calls)
+	}
+
+	sort.Slice(summary.Endpoints, func(i, j int) bool {
+		a, b := summary.Endpoints[i], summary.Endpoints[j]
+		if ea, eb := a.budgetErrors(), b.budgetErrors(); ea != eb {
+			return ea > eb
+		} else if a.Calls != b.Calls {
+			return a.Calls > b.Calls
+		}
+		return a.Method+a.Path < b.Method+b.Path
+	})
+	return
+}
+
+// record counts a call to endpoint which returned status or err.
+func (s *APIStats) record(method, endpoint string, status int, err error, retry bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := method + " " + endpoint
+	if s.endpoints == nil {
+		s.endpoints = map[string]*EndpointStats{}
+	}
+	e, ok := s.endpoints[key]
+	if !ok {
+		e = &EndpointStats{Method: method, Path: endpoint}
+		s.endpoints[key] = e
+	}
+
+	e.Calls++
+	if retry {
+		e.Retries++
+	}
+	if class := errorClass(status, err); class != "" {
+		if e.Errors == nil {
+			e.Errors = map[string]int{}
+		}
+		e.Errors[class]++
+	}
+}
+
+// attempt calls send until it succeeds or fails permanently, retrying GET requests which failed transiently.
+func (s *APIStats) attempt(ctx context.Context, method, path string, send func() (int, error)) error {
+	endpoint := endpointPath(path)
+	for i := 0; ; i++ {
+		status, err := send()
+		s.record(method, endpoint, status, err, i > 0)
+
+		class := errorClass(status, err)
+		if method != http.MethodGet || i >= maxRetries || class == "" || class == ErrorClient {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(i+1) * retryBackoff):
+		}
+	}
+}
+
+// Metadata describes the requests made to the OCM API for TestGrid.
+func (s APISummary) Metadata() map[string]interface{} {
+	meta := map[string]interface{}{
+		"ocm-calls":      s.Calls,
+		"ocm-retries":    s.Retries,
+		"ocm-errors":     s.Errors,
+		"ocm-error-rate": s.ErrorRate,
+	}
+	for _, e := range s.Endpoints {
+		for class, count := range e.Errors {
+			key := "ocm-errors-" + class
+			total, _ := meta[key].(int)
+			meta[key] = total + count
+		}
+	}
+	return meta
+}
+
+// WriteJUnit records the OCM API error budget as a testcase in dir, failing it if more than budget of calls failed
+// because of OCM. It's only checked when budget is set.
+func (s APISummary) WriteJUnit(dir, suffix string, budget float64) error {
+	result := junit.Result{
+		Name:      "should stay within its error budget",
+		ClassName: StatsSuiteName,
+	}
+	suite := junit.Suite{
+		Name:  StatsSuiteName,
+		Tests: 1,
+	}
+
+	if budget <= 0 {
+		msg := "OCM_ERROR_BUDGET is not set"
+		result.Skipped = &msg
+	} else if s.ErrorRate > budget {
+		var failing []string
+		for _, e := range s.Endpoints {
+			if n := e.budgetErrors(); n != 0 {
+				failing = append(failing, fmt.Sprintf("%s %s: %d of %d calls failed %v", e.Method, e.Path, n, e.Calls, e.Errors))
+			}
+		}
+		msg := fmt.Sprintf("%d of %d OCM API calls failed (%.2f%%), over the budget of %.2f%%", s.Errors, s.Calls,
+			s.ErrorRate*100, budget*100)
+		output := strings.Join(failing, "\n")
+		result.Failure, result.Output = &msg, &output
+		suite.Failures++
+	}
+	suite.Results = append(suite.Results, result)
+
+	return junitprops.WriteSuite(dir, "ocm", suffix, suite)
+}
+
+// budgetErrors counts calls to e which failed because of OCM.
+func (e EndpointStats) budgetErrors() (n int) {
+	for class, count := range e.Errors {
+		if class != ErrorClient {
+			n += count
+		}
+	}
+	return
+}
+
+// statsTransport records requests sent by OCM clients in Stats, retrying those which failed transiently.
+type statsTransport struct {
+	rt http.RoundTripper
+}
+
+func (t *statsTransport) RoundTrip(req *http.Request) (resp *http.Response, err error) {
+	err = Stats.attempt(req.Context(), req.Method, req.URL.Path, func() (int, error) {
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		var sendErr error
+		if resp, sendErr = t.rt.RoundTrip(copyRequest(req)); resp != nil {
+			return resp.StatusCode, sendErr
+		}
+		return 0, sendErr
+	})
+	if err != nil && resp != nil {
+		resp.Body.Close()
+		resp = nil
+	}
+	return
+}
+
+// copyRequest returns a copy of req which can be sent again, as the OCM connection changes the URL and headers of
+// requests it sends. Only requests without bodies are retried.
+func copyRequest(req *http.Request) *http.Request {
+	r := req.WithContext(req.Context())
+	u := *req.URL
+	r.URL = &u
+	r.Header = make(http.Header, len(req.Header))
+	for k, v := range req.Header {
+		r.Header[k] = append([]string(nil), v...)
+	}
+	return r
+}
+
+// errorClass returns the class of error a request returning status or err failed with, if any.
+func errorClass(status int, err error) string {
+	switch {
+	case status == 0 && err != nil:
+		return ErrorNetwork
+	case status == http.StatusTooManyRequests:
+		return ErrorThrottled
+	case status >= http.StatusInternalServerError:
+		return ErrorServer
+	case status >= http.StatusBadRequest:
+		return ErrorClient
+	}
+	return ""
+}
+
+// endpointPath replaces the IDs in path with '-', so requests for different resources are counted together. Paths
+// after the API version alternate between collections and IDs.
+func endpointPath(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, s := range segments {
+		if s != APIVersion {
+			continue
+		}
+		for j := i + 2; j < len(segments); j += 2 {
+			segments[j] = "-"
+		}
+		break
+	}
+	return "/" + strings.Join(segments, "/")
+}
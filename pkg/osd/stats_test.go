@@ -0,0 +1,105 @@
+package osd
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStatsRetries(t *testing.T) {
+	retryBackoff = time.Millisecond
+	defer func() { retryBackoff = 2 * time.Second }()
+
+	osd, done := replay(t, "retry.yaml", nil)
+	defer done()
+
+	endpoint := func(method, path string) (stats EndpointStats) {
+		for _, e := range Stats.Summary().Endpoints {
+			if e.Method == method && e.Path == path {
+				stats = e
+			}
+		}
+		return
+	}
+	autoscaler, cluster := endpoint("GET", "/api/clusters_mgmt/v1/clusters/-/autoscaler"), endpoint("GET", "/api/clusters_mgmt/v1/clusters/-")
+
+	// raw requests
+	if a, err := osd.ClusterAutoscaler("4d5e6f"); err != nil || a == nil || !a.BalanceSimilarNodeGroups {
+		t.Errorf("expected autoscaler after retrying, got %+v: %v", a, err)
+	}
+	if e := endpoint("GET", "/api/clusters_mgmt/v1/clusters/-/autoscaler"); e.Calls-autoscaler.Calls != 2 ||
+		e.Retries-autoscaler.Retries != 1 || e.Errors[ErrorServer]-autoscaler.Errors[ErrorServer] != 1 {
+		t.Errorf("expected autoscaler to be requested twice after a server error, got %+v", e)
+	}
+
+	// requests of typed clients
+	if state, err := osd.ClusterState("4d5e6f"); err != nil || state != "ready" {
+		t.Errorf("expected ready cluster after retrying, got '%s': %v", state, err)
+	}
+	if e := endpoint("GET", "/api/clusters_mgmt/v1/clusters/-"); e.Calls-cluster.Calls != 2 || e.Retries-cluster.Retries != 1 {
+		t.Errorf("expected cluster to be requested twice after a server error, got %+v", e)
+	}
+}
+
+func TestStatsSummary(t *testing.T) {
+	stats := new(APIStats)
+	stats.record("GET", "/api/clusters_mgmt/v1/clusters/-", 200, nil, false)
+	stats.record("GET", "/api/clusters_mgmt/v1/clusters/-", 0, errors.New("timeout"), false)
+	stats.record("GET", "/api/clusters_mgmt/v1/clusters/-", 200, nil, true)
+	stats.record("DELETE", "/api/clusters_mgmt/v1/clusters/-/addons/-", 404, nil, false)
+	stats.record("POST", "/api/clusters_mgmt/v1/clusters", 429, nil, false)
+
+	summary := stats.Summary()
+	if summary.Calls != 5 || summary.Retries != 1 || summary.Errors != 2 || summary.ErrorRate != 0.4 {
+		t.Errorf("unexpected totals %+v", summary)
+	}
+	if first := summary.Endpoints[0]; first.Method != "GET" || first.Errors[ErrorNetwork] != 1 {
+		t.Errorf("expected endpoint with the most errors first, got %+v", first)
+	}
+
+	meta := summary.Metadata()
+	if meta["ocm-errors-throttled"] != 1 || meta["ocm-errors-client"] != 1 || meta["ocm-calls"] != 5 {
+		t.Errorf("unexpected metadata %v", meta)
+	}
+
+	dir, err := ioutil.TempDir("", "ocm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for budget, expected := range map[float64]string{
+		0:   "<skipped",
+		0.5: "",
+		0.1: "2 of 5 OCM API calls failed (40.00%)",
+	} {
+		if err = summary.WriteJUnit(dir, "abc", budget); err != nil {
+			t.Fatalf("failed writing JUnit: %v", err)
+		}
+		data, err := ioutil.ReadFile(filepath.Join(dir, "junit_ocm_abc.xml"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if failed := strings.Contains(string(data), "<failure"); (expected != "" && !strings.Contains(string(data), expected)) ||
+			failed != (budget == 0.1) {
+			t.Errorf("unexpected JUnit with budget %v: %s", budget, data)
+		}
+	}
+}
+
+func TestEndpointPath(t *testing.T) {
+	for path, expected := range map[string]string{
+		"/api/clusters_mgmt/v1/clusters":                        "/api/clusters_mgmt/v1/clusters",
+		"/api/clusters_mgmt/v1/clusters/1a2b3c/autoscaler":      "/api/clusters_mgmt/v1/clusters/-/autoscaler",
+		"/api/clusters_mgmt/v1/clusters/1a2b3c/addons/logging":  "/api/clusters_mgmt/v1/clusters/-/addons/-",
+		"/api/accounts_mgmt/v1/organizations/xyz/quota_summary": "/api/accounts_mgmt/v1/organizations/-/quota_summary",
+	} {
+		if actual := endpointPath(path); actual != expected {
+			t.Errorf("expected '%s' for '%s', got '%s'", expected, path, actual)
+		}
+	}
+}
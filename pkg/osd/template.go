@@ -0,0 +1,192 @@
+package osd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/osde2e/pkg/config"
+)
+
+const (
+	// openAPIPath is the OpenAPI description of the OSD clusters management API.
+	openAPIPath = "/api/clusters_mgmt/" + APIVersion + "/openapi"
+
+	// clusterSchema is the name of the schema describing clusters in the OpenAPI description.
+	clusterSchema = "Cluster"
+
+	// schemaRefPrefix starts references to other schemas in the OpenAPI description.
+	schemaRefPrefix = "#/components/schemas/"
+)
+
+// TemplateData is what cluster templates are executed with. Options of the run are available by their field name,
+// such as {{.ClusterName}} or {{.Region}}.
+type TemplateData struct {
+	*config.Config
+
+	// Expiration is when the cluster will be deleted by OSD in RFC3339, from ClusterExpiry.
+	Expiration string
+}
+
+// RenderClusterTemplate executes the Go template of a YAML cluster body in file with cfg, returning the body as JSON.
+func RenderClusterTemplate(file string, cfg *config.Config, expiration time.Time) ([]byte, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read cluster template '%s': %v", file, err)
+	}
+
+	tmpl, err := template.New(file).Option("missingkey=error").Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse cluster template '%s': %v", file, err)
+	}
+
+	var out bytes.Buffer
+	if err = tmpl.Execute(&out, TemplateData{Config: cfg, Expiration: expiration.UTC().Format(time.RFC3339)}); err != nil {
+		return nil, fmt.Errorf("couldn't execute cluster template '%s': %v", file, err)
+	}
+
+	body, err := yaml.YAMLToJSON(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("cluster template '%s' isn't valid YAML: %v", file, err)
+	}
+	if _, ok := decodeJSON(body).(map[string]interface{}); !ok {
+		return nil, fmt.Errorf("cluster template '%s' must be a YAML object", file)
+	}
+	return body, nil
+}
+
+// ValidateCluster checks the JSON cluster body against the schema of clusters described by the OSD API, returning
+// every property which isn't in the schema or has the wrong type.
+func (u *OSD) ValidateCluster(body []byte) error {
+	var spec struct {
+		Components struct {
+			Schemas map[string]map[string]interface{} `json:"schemas"`
+		} `json:"components"`
+	}
+	if _, err := u.send(u.conn.Get().Path(openAPIPath), &spec); err != nil {
+		return fmt.Errorf("couldn't get OSD API schema: %v", err)
+	}
+
+	schema, ok := spec.Components.Schemas[clusterSchema]
+	if !ok {
+		return fmt.Errorf("OSD API schema doesn't describe clusters")
+	}
+
+	var problems []string
+	validateSchema(decodeJSON(body), schema, spec.Components.Schemas, "", &problems)
+	if len(problems) != 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("cluster doesn't match the OSD API schema: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// validateSchema appends to problems how value at path doesn't match the OpenAPI schema. References are resolved
+// from schemas.
+func validateSchema(value interface{}, schema map[string]interface{}, schemas map[string]map[string]interface{},
+	path string, problems *[]string) {
+	for depth := 0; depth < 10; depth++ {
+		ref, _ := schema["$ref"].(string)
+		if ref == "" {
+			break
+		}
+		resolved, ok := schemas[strings.TrimPrefix(ref, schemaRefPrefix)]
+		if !ok {
+			*problems = append(*problems, fmt.Sprintf("%s refers to unknown schema '%s'", describePath(path), ref))
+			return
+		}
+		schema = resolved
+	}
+
+	typ, _ := schema["type"].(string)
+	props, _ := schema["properties"].(map[string]interface{})
+	if typ == "" && props != nil {
+		typ = "object"
+	}
+
+	switch typ {
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			*problems = append(*problems, fmt.Sprintf("%s must be an object", describePath(path)))
+			return
+		}
+		if required, ok := schema["required"].([]interface{}); ok {
+			for _, name := range required {
+				if name, ok := name.(string); ok {
+					if _, found := obj[name]; !found {
+						*problems = append(*problems, fmt.Sprintf("%s is required", describePath(join(path, name))))
+					}
+				}
+			}
+		}
+
+		additional, _ := schema["additionalProperties"].(map[string]interface{})
+		for name, v := range obj {
+			if propSchema, ok := props[name].(map[string]interface{}); ok {
+				validateSchema(v, propSchema, schemas, join(path, name), problems)
+			} else if additional != nil {
+				validateSchema(v, additional, schemas, join(path, name), problems)
+			} else if props != nil {
+				*problems = append(*problems, fmt.Sprintf("%s isn't a property of the schema", describePath(join(path, name))))
+			}
+		}
+	case "array":
+		arr, ok := value.([]interface{})
+		if !ok {
+			*problems = append(*problems, fmt.Sprintf("%s must be an array", describePath(path)))
+			return
+		}
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, v := range arr {
+				validateSchema(v, items, schemas, fmt.Sprintf("%s[%d]", path, i), problems)
+			}
+		}
+	case "string":
+		if _, ok := value.(string); !ok {
+			*problems = append(*problems, fmt.Sprintf("%s must be a string", describePath(path)))
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			*problems = append(*problems, fmt.Sprintf("%s must be a boolean", describePath(path)))
+		}
+	case "integer":
+		if n, ok := value.(float64); !ok || n != math.Trunc(n) {
+			*problems = append(*problems, fmt.Sprintf("%s must be an integer", describePath(path)))
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			*problems = append(*problems, fmt.Sprintf("%s must be a number", describePath(path)))
+		}
+	}
+}
+
+// decodeJSON returns the value encoded in data, or nil if it isn't valid JSON.
+func decodeJSON(data []byte) (value interface{}) {
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil
+	}
+	return
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func describePath(path string) string {
+	if path == "" {
+		return "cluster"
+	}
+	return "'" + path + "'"
+}
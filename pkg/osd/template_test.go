@@ -0,0 +1,118 @@
+package osd
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift/osde2e/pkg/config"
+)
+
+func TestLaunchClusterFromTemplate(t *testing.T) {
+	var body map[string]interface{}
+	osd, done := replay(t, "clustertemplate.yaml", func(req *http.Request) {
+		if req.Method == http.MethodPost {
+			data, _ := ioutil.ReadAll(req.Body)
+			if err := json.Unmarshal(data, &body); err != nil {
+				t.Errorf("invalid cluster body: %v", err)
+			}
+		}
+	})
+	defer done()
+
+	cfg := &config.Config{
+		ClusterName:       "osde2e-private",
+		ClusterVersion:    "openshift-v4.1.14",
+		Region:            "us-east-1",
+		ClusterExpiry:     8 * time.Hour,
+		ClusterProperties: map[string]string{"owner": "osde2e"},
+		ClusterTemplate:   filepath.Join("..", "..", "clustertemplates", "private.yaml"),
+	}
+	clusterID, err := osd.LaunchCluster(cfg)
+	if err != nil {
+		t.Fatalf("failed to launch cluster: %v", err)
+	} else if clusterID != "4d5e6f" {
+		t.Errorf("expected cluster '4d5e6f', got '%s'", clusterID)
+	}
+
+	for field, expected := range map[string]interface{}{
+		"name":       "osde2e-private",
+		"multi_az":   false,
+		"region":     map[string]interface{}{"id": "us-east-1"},
+		"version":    map[string]interface{}{"id": "openshift-v4.1.14"},
+		"api":        map[string]interface{}{"listening": "internal"},
+		"properties": map[string]interface{}{"owner": "osde2e"},
+	} {
+		if actual, _ := json.Marshal(body[field]); string(actual) != string(mustMarshal(t, expected)) {
+			t.Errorf("expected cluster %s to be %s, got %s", field, mustMarshal(t, expected), actual)
+		}
+	}
+	if expiry, ok := body["expiration_timestamp"].(string); !ok {
+		t.Error("expected cluster to have an expiration")
+	} else if ts, err := time.Parse(time.RFC3339, expiry); err != nil || ts.Before(time.Now()) {
+		t.Errorf("expected future expiration, got '%s'", expiry)
+	}
+
+	// the second schema doesn't describe the API or machine types
+	err = osd.ValidateCluster([]byte(`{"name":"osde2e-abc","nodes":{"compute":2.5,"compute_machine_type":{"id":"m5.xlarge"}},"api":{}}`))
+	if err == nil {
+		t.Fatal("expected cluster not matching the schema to be invalid")
+	}
+	for _, problem := range []string{
+		"'api' isn't a property of the schema",
+		"'nodes.compute' must be an integer",
+		"'nodes.compute_machine_type' isn't a property of the schema",
+	} {
+		if !strings.Contains(err.Error(), problem) {
+			t.Errorf("expected '%s' in validation error: %v", problem, err)
+		}
+	}
+}
+
+func TestRenderClusterTemplate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "clustertemplate")
+	if err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	cfg := &config.Config{ClusterName: "osde2e-abc", ComputeNodes: 3}
+	expiration := time.Date(2019, 10, 2, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		template string
+		expected string
+	}{
+		{"options", "name: {{.ClusterName}}\nnodes:\n  compute: {{.ComputeNodes}}\n", `{"name":"osde2e-abc","nodes":{"compute":3}}`},
+		{"expiration", `expiration_timestamp: "{{.Expiration}}"`, `{"expiration_timestamp":"2019-10-02T12:00:00Z"}`},
+		{"unknown option", "name: {{.ClusterNmae}}", ""},
+		{"bad template", "name: {{.ClusterName", ""},
+		{"not an object", "- {{.ClusterName}}", ""},
+	}
+	for _, test := range tests {
+		file := filepath.Join(dir, "template.yaml")
+		if err = ioutil.WriteFile(file, []byte(test.template), 0600); err != nil {
+			t.Fatalf("failed to write template: %v", err)
+		}
+
+		body, err := RenderClusterTemplate(file, cfg, expiration)
+		if test.expected == "" {
+			if err == nil {
+				t.Errorf("%s: expected an error, got %s", test.name, body)
+			}
+		} else if err != nil {
+			t.Errorf("%s: failed to render: %v", test.name, err)
+		} else if string(body) != test.expected {
+			t.Errorf("%s: expected %s, got %s", test.name, test.expected, body)
+		}
+	}
+
+	if _, err = RenderClusterTemplate(filepath.Join(dir, "missing.yaml"), cfg, expiration); err == nil {
+		t.Error("expected an error rendering a missing template")
+	}
+}
@@ -0,0 +1,116 @@
+package osd
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// versionCache stores the versions offered by OSD in a file, so runs and commands started soon after each other
+// don't list them again.
+type versionCache struct {
+	file    string
+	ttl     time.Duration
+	refresh bool
+
+	mu sync.Mutex
+
+	// refreshed are the queries listed since refresh was set, which are cached again.
+	refreshed map[string]bool
+}
+
+// versionCacheEntry is the versions returned by a query of an OSD environment.
+type versionCacheEntry struct {
+	Fetched  time.Time `json:"fetched"`
+	Versions []string  `json:"versions"`
+}
+
+// CacheVersions stores versions listed in file for ttl, or in the user's cache directory if file is empty. If refresh is
+// set, each query is listed again once before the cache is used. Versions aren't cached if ttl is 0.
+func (u *OSD) CacheVersions(file string, ttl time.Duration, refresh bool) {
+	if file == "" {
+		if dir, err := os.UserCacheDir(); err == nil {
+			file = filepath.Join(dir, "osde2e", "versions.json")
+		}
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if file == "" || ttl <= 0 {
+		u.versionCache = nil
+		return
+	}
+	u.versionCache = &versionCache{file: file, ttl: ttl, refresh: refresh, refreshed: map[string]bool{}}
+}
+
+// cachedVersions returns the versions of query, listing them with list if they weren't cached within the TTL.
+func (u *OSD) cachedVersions(query string, list func() ([]string, error)) ([]string, error) {
+	u.mu.Lock()
+	c := u.versionCache
+	u.mu.Unlock()
+	if c == nil {
+		return list()
+	}
+
+	// environments offer different versions
+	key := u.conn.URL() + " " + query
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entries := c.read()
+	if entry, ok := entries[key]; ok && (!c.refresh || c.refreshed[key]) && time.Since(entry.Fetched) < c.ttl {
+		return entry.Versions, nil
+	}
+
+	versions, err := list()
+	if err != nil {
+		return nil, err
+	}
+	entries[key] = versionCacheEntry{Fetched: time.Now().UTC(), Versions: versions}
+	c.refreshed[key] = true
+	if err = c.write(entries); err != nil {
+		log.Printf("Failed to cache versions in '%s': %v", c.file, err)
+	}
+	return versions, nil
+}
+
+// read returns the cached entries, which are empty if the file is missing or can't be parsed.
+func (c *versionCache) read() map[string]versionCacheEntry {
+	entries := map[string]versionCacheEntry{}
+	if data, err := ioutil.ReadFile(c.file); err == nil {
+		if err = json.Unmarshal(data, &entries); err != nil {
+			log.Printf("Ignoring invalid version cache '%s': %v", c.file, err)
+			entries = map[string]versionCacheEntry{}
+		}
+	}
+	return entries
+}
+
+// write replaces the cached entries, renaming a temporary file over the cache so it's never partially written.
+func (c *versionCache) write(entries map[string]versionCacheEntry) error {
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(c.file), os.ModePerm); err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(c.file), filepath.Base(c.file))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), c.file)
+}
@@ -0,0 +1,80 @@
+package osd
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestVersionCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "versions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "cache", "versions.json")
+
+	var requests int
+	osd, done := replay(t, "versions.yaml", func(req *http.Request) {
+		if req.URL.Path == "/api/clusters_mgmt/v1/versions" {
+			requests++
+		}
+	})
+	defer done()
+
+	selectVersions := func() {
+		if v, err := osd.DefaultVersion(); err != nil || v != "openshift-v4.1.14" {
+			t.Errorf("expected default 'openshift-v4.1.14', got '%s': %v", v, err)
+		}
+		if v, err := osd.LatestPrerelease(4, 2, "nightly"); err != nil || v != "openshift-v4.2.0-0.nightly-2019-09-23-115152" {
+			t.Errorf("unexpected latest 4.2 nightly '%s': %v", v, err)
+		}
+		if v, err := osd.PreviousVersion("openshift-v4.1.14"); err != nil || v != "openshift-v4.1.13" {
+			t.Errorf("expected previous 'openshift-v4.1.13', got '%s': %v", v, err)
+		}
+	}
+
+	osd.CacheVersions(file, time.Hour, false)
+	selectVersions()
+	if requests != 2 {
+		t.Errorf("expected default and all versions to be listed once, got %d requests", requests)
+	}
+
+	// versions are listed from the cache until it expires
+	selectVersions()
+	if requests != 2 {
+		t.Errorf("expected cached versions to be used, got %d requests", requests)
+	}
+
+	osd.CacheVersions(file, time.Hour, true)
+	selectVersions()
+	if requests != 4 {
+		t.Errorf("expected refresh to list versions again once, got %d requests", requests)
+	}
+
+	osd.CacheVersions(file, time.Nanosecond, false)
+	time.Sleep(time.Millisecond)
+	selectVersions()
+	if requests != 7 {
+		t.Errorf("expected expired versions to be listed again, got %d requests", requests)
+	}
+
+	// invalid caches are replaced
+	if err = ioutil.WriteFile(file, []byte("{"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	osd.CacheVersions(file, time.Hour, false)
+	selectVersions()
+	if requests != 9 {
+		t.Errorf("expected invalid cache to be replaced, got %d requests", requests)
+	}
+
+	osd.CacheVersions(file, 0, false)
+	selectVersions()
+	if requests != 12 {
+		t.Errorf("expected versions not to be cached, got %d requests", requests)
+	}
+}
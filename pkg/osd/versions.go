@@ -15,30 +15,38 @@ const (
 	// query used to retrieve the current default version.
 	defaultVersionSearch = "default = 't'"
 
+	// allVersions is the cache key of every version offered.
+	allVersions = "all"
+
 	// VersionPrefix is the string that every OSD version begins with.
 	VersionPrefix = "openshift-"
 )
 
 // DefaultVersion returns the default version currently offered by OSD.
 func (u *OSD) DefaultVersion() (string, error) {
-	resp, err := u.versions().List().
-		Search(defaultVersionSearch).
-		Size(1).
-		Send()
-	if err == nil && resp != nil {
-		err = errResp(resp.Error())
-	}
+	versions, err := u.cachedVersions(defaultVersionSearch, func() ([]string, error) {
+		resp, err := u.versions().List().
+			Search(defaultVersionSearch).
+			Size(1).
+			SendContext(u.context())
+		if err == nil && resp != nil {
+			err = errResp(resp.Error())
+		}
 
-	if err != nil {
-		return "", fmt.Errorf("couldn't retrieve available versions: %v", err)
-	}
+		if err != nil {
+			return nil, fmt.Errorf("couldn't retrieve available versions: %v", err)
+		}
 
-	version := resp.Items().Get(0)
-	if version == nil {
-		return "", errors.New("version returned was nil")
+		version := resp.Items().Get(0)
+		if version == nil {
+			return nil, errors.New("version returned was nil")
+		}
+		return []string{version.ID()}, nil
+	})
+	if err != nil {
+		return "", err
 	}
-
-	return version.ID(), nil
+	return versions[0], nil
 }
 
 // PreviousVersion returns the first available previous version for the given version.
@@ -81,33 +89,46 @@ func (u *OSD) LatestPrerelease(major, minor int64, str string) (string, error) {
 
 // getSemverList as sorted semvers containing str for major and minor versions. Negative versions match all.
 func (u *OSD) getSemverList(major, minor int64, str string) (versions []*semver.Version, err error) {
-	var resp *v1.VersionsListResponse
-	resp, err = u.versions().List().Send()
+	ids, err := u.cachedVersions(allVersions, u.listVersions)
 	if err != nil {
-		err = fmt.Errorf("failed getting list of OSD versions: %v", err)
-	} else if resp != nil {
-		err = errResp(resp.Error())
-	}
-
-	if err != nil {
-		return versions, fmt.Errorf("couldn't retrieve available versions: %v", err)
+		return versions, err
 	}
 
 	// parse versions, filter for major+minor nightlies, then sort
-	resp.Items().Each(func(v *v1.Version) bool {
-		name := strings.TrimPrefix(v.ID(), VersionPrefix)
+	for _, id := range ids {
+		name := strings.TrimPrefix(id, VersionPrefix)
 		if version, err := semver.NewVersion(name); err != nil {
-			log.Printf("could not parse version '%s': %v", v.ID(), err)
+			log.Printf("could not parse version '%s': %v", id, err)
 		} else if version.Major() != major && major >= 0 {
-			return true
+			continue
 		} else if version.Minor() != minor && minor >= 0 {
-			return true
+			continue
 		} else if strings.Contains(version.Prerelease(), str) {
 			versions = append(versions, version)
 		}
-		return true
-	})
+	}
 
 	sort.Sort(semver.Collection(versions))
 	return versions, nil
 }
+
+// listVersions returns the IDs of every version offered by OSD.
+func (u *OSD) listVersions() (ids []string, err error) {
+	var resp *v1.VersionsListResponse
+	resp, err = u.versions().List().SendContext(u.context())
+	if err != nil {
+		err = fmt.Errorf("failed getting list of OSD versions: %v", err)
+	} else if resp != nil {
+		err = errResp(resp.Error())
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("couldn't retrieve available versions: %v", err)
+	}
+
+	resp.Items().Each(func(v *v1.Version) bool {
+		ids = append(ids, v.ID())
+		return true
+	})
+	return ids, nil
+}
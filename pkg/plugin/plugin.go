@@ -0,0 +1,277 @@
+// Package plugin runs cluster providers and test suites maintained outside of osde2e as external binaries.
+//
+// Plugins are started by osde2e and serve JSON-RPC on a unix socket in the private directory given by SocketDirEnv.
+// Before serving, a plugin checks CookieEnv to confirm it was started by osde2e, chooses a protocol version from
+// those listed in VersionsEnv, and writes a handshake line to stdout in the form:
+//
+//	CORE-VERSION|PROTOCOL-VERSION|unix|SOCKET-PATH
+//
+// Go plugins should use Serve, which performs the handshake.
+package plugin
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// CoreProtocolVersion is the version of the handshake. It changes only when the handshake itself changes.
+	CoreProtocolVersion = 1
+
+	// CookieEnv is set to CookieValue when starting plugins.
+	CookieEnv = "OSDE2E_PLUGIN_COOKIE"
+
+	// CookieValue lets plugins detect they weren't run directly by a user.
+	CookieValue = "d5b1c6d2a6c34a0f8f4c1a0b6e1f3c9e"
+
+	// VersionsEnv is a comma separated list of protocol versions supported by osde2e.
+	VersionsEnv = "OSDE2E_PLUGIN_PROTOCOL_VERSIONS"
+
+	// SocketDirEnv is a directory only accessible to the user running osde2e, which plugins create their socket in.
+	SocketDirEnv = "OSDE2E_PLUGIN_SOCKET_DIR"
+
+	// HandshakeTimeout is how long a plugin has to write its handshake after starting.
+	HandshakeTimeout = 30 * time.Second
+
+	// Network is the only network plugins may serve on. Unlike TCP, access to unix sockets is limited by file permissions.
+	Network = "unix"
+)
+
+// ProtocolVersions are the versions of the plugin protocol supported, most preferred first.
+var ProtocolVersions = []int{1}
+
+// Handshake is written by plugins once they're ready to serve.
+type Handshake struct {
+	CoreVersion     int
+	ProtocolVersion int
+	Network         string
+	Address         string
+}
+
+func (h Handshake) String() string {
+	return fmt.Sprintf("%d|%d|%s|%s", h.CoreVersion, h.ProtocolVersion, h.Network, h.Address)
+}
+
+// ParseHandshake decodes a handshake line written by a plugin and checks its versions are supported.
+func ParseHandshake(line string) (h Handshake, err error) {
+	parts := strings.Split(strings.TrimSpace(line), "|")
+	if len(parts) != 4 {
+		return h, fmt.Errorf("handshake '%s' should have 4 parts", line)
+	}
+
+	if h.CoreVersion, err = strconv.Atoi(parts[0]); err != nil {
+		return h, fmt.Errorf("invalid core version in handshake '%s': %v", line, err)
+	} else if h.CoreVersion != CoreProtocolVersion {
+		return h, fmt.Errorf("plugin uses core version %d, only %d is supported", h.CoreVersion, CoreProtocolVersion)
+	}
+
+	if h.ProtocolVersion, err = strconv.Atoi(parts[1]); err != nil {
+		return h, fmt.Errorf("invalid protocol version in handshake '%s': %v", line, err)
+	} else if !supported(h.ProtocolVersion, ProtocolVersions) {
+		return h, fmt.Errorf("plugin uses protocol version %d, supported versions are %v", h.ProtocolVersion, ProtocolVersions)
+	}
+
+	if h.Network, h.Address = parts[2], parts[3]; h.Network != Network {
+		return h, fmt.Errorf("plugin serves on network '%s', only '%s' is supported", h.Network, Network)
+	}
+	return h, nil
+}
+
+// Client is a connection to a running plugin.
+type Client struct {
+	Handshake Handshake
+
+	cmd *exec.Cmd
+	rpc *rpc.Client
+	dir string
+}
+
+// Start runs the plugin cmd, waiting for it to complete the handshake before connecting to it.
+func Start(cmd *exec.Cmd) (*Client, error) {
+	versions := make([]string, len(ProtocolVersions))
+	for i, v := range ProtocolVersions {
+		versions[i] = strconv.Itoa(v)
+	}
+
+	// other users can't connect to plugins as they can't reach sockets in the directory
+	dir, err := ioutil.TempDir("", "osde2e-plugin")
+	if err != nil {
+		return nil, fmt.Errorf("couldn't create socket directory for plugin '%s': %v", cmd.Path, err)
+	}
+
+	if cmd.Env == nil {
+		cmd.Env = os.Environ()
+	}
+	cmd.Env = append(cmd.Env,
+		CookieEnv+"="+CookieValue,
+		VersionsEnv+"="+strings.Join(versions, ","),
+		SocketDirEnv+"="+dir)
+	if cmd.Stderr == nil {
+		cmd.Stderr = os.Stderr
+	}
+
+	c := &Client{cmd: cmd, dir: dir}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		c.Close()
+		return nil, fmt.Errorf("couldn't read output of plugin '%s': %v", cmd.Path, err)
+	}
+
+	if err = cmd.Start(); err != nil {
+		c.Close()
+		return nil, fmt.Errorf("couldn't start plugin '%s': %v", cmd.Path, err)
+	}
+
+	if c.Handshake, err = readHandshake(stdout); err != nil {
+		c.Close()
+		return nil, fmt.Errorf("plugin '%s' failed handshake: %v", cmd.Path, err)
+	} else if filepath.Dir(c.Handshake.Address) != dir {
+		c.Close()
+		return nil, fmt.Errorf("plugin '%s' serves on '%s', outside of '%s'", cmd.Path, c.Handshake.Address, dir)
+	}
+
+	conn, err := net.Dial(c.Handshake.Network, c.Handshake.Address)
+	if err != nil {
+		c.Close()
+		return nil, fmt.Errorf("couldn't connect to plugin '%s': %v", cmd.Path, err)
+	}
+	c.rpc = jsonrpc.NewClient(conn)
+	return c, nil
+}
+
+// readHandshake returns the first line written by a plugin, then logs everything written after it.
+func readHandshake(stdout io.Reader) (Handshake, error) {
+	lines := make(chan string, 1)
+	go func() {
+		scanner := bufio.NewScanner(stdout)
+		first := true
+		for scanner.Scan() {
+			if first {
+				lines <- scanner.Text()
+				first = false
+			} else {
+				log.Printf("plugin: %s", scanner.Text())
+			}
+		}
+		close(lines)
+	}()
+
+	select {
+	case line, ok := <-lines:
+		if !ok {
+			return Handshake{}, fmt.Errorf("plugin exited without writing handshake")
+		}
+		return ParseHandshake(line)
+	case <-time.After(HandshakeTimeout):
+		return Handshake{}, fmt.Errorf("no handshake within %v", HandshakeTimeout)
+	}
+}
+
+// Call invokes method of the plugin with args, decoding the result into reply.
+func (c *Client) Call(method string, args, reply interface{}) error {
+	if err := c.rpc.Call(method, args, reply); err != nil {
+		return fmt.Errorf("plugin '%s' failed calling %s: %v", c.cmd.Path, method, err)
+	}
+	return nil
+}
+
+// Close disconnects from and stops the plugin.
+func (c *Client) Close() {
+	if c.rpc != nil {
+		c.rpc.Close()
+	}
+	if c.cmd.Process != nil {
+		c.cmd.Process.Kill()
+		c.cmd.Wait()
+	}
+	os.RemoveAll(c.dir)
+}
+
+// Serve performs the handshake with osde2e and serves services over JSON-RPC until the process is killed.
+// Services are registered by name, such as ProviderService or SuiteService.
+func Serve(services map[string]interface{}) error {
+	if os.Getenv(CookieEnv) != CookieValue {
+		return fmt.Errorf("this binary is an osde2e plugin and must be run by osde2e")
+	}
+
+	version, err := negotiate(os.Getenv(VersionsEnv))
+	if err != nil {
+		return err
+	}
+
+	srv := rpc.NewServer()
+	for name, service := range services {
+		if err = srv.RegisterName(name, service); err != nil {
+			return fmt.Errorf("couldn't register service '%s': %v", name, err)
+		}
+	}
+
+	dir := os.Getenv(SocketDirEnv)
+	if dir == "" {
+		return fmt.Errorf("%s must be set to the directory to serve in", SocketDirEnv)
+	}
+
+	socket := filepath.Join(dir, "plugin.sock")
+	l, err := net.Listen(Network, socket)
+	if err != nil {
+		return fmt.Errorf("couldn't listen for osde2e: %v", err)
+	}
+	defer l.Close()
+
+	if err = os.Chmod(socket, 0600); err != nil {
+		return fmt.Errorf("couldn't restrict access to socket: %v", err)
+	}
+
+	fmt.Println(Handshake{
+		CoreVersion:     CoreProtocolVersion,
+		ProtocolVersion: version,
+		Network:         Network,
+		Address:         socket,
+	})
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return fmt.Errorf("couldn't accept connection: %v", err)
+		}
+		go srv.ServeCodec(jsonrpc.NewServerCodec(conn))
+	}
+}
+
+// negotiate returns the most preferred protocol version offered by osde2e that is supported.
+func negotiate(offered string) (int, error) {
+	var versions []int
+	for _, v := range strings.Split(offered, ",") {
+		if version, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
+			versions = append(versions, version)
+		}
+	}
+
+	for _, v := range ProtocolVersions {
+		if supported(v, versions) {
+			return v, nil
+		}
+	}
+	return 0, fmt.Errorf("osde2e offered protocol versions '%s', plugin supports %v", offered, ProtocolVersions)
+}
+
+func supported(version int, versions []int) bool {
+	for _, v := range versions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,171 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/openshift/osde2e/pkg/config"
+)
+
+// helperEnv makes the test binary serve fake plugins instead of running tests.
+const helperEnv = "OSDE2E_TEST_HELPER_PLUGIN"
+
+type fakeProvider struct{}
+
+func (fakeProvider) LaunchCluster(args LaunchArgs) (string, error) {
+	return args.Name + "-" + args.Region, nil
+}
+
+func (fakeProvider) ClusterReady(clusterID string) (bool, error) {
+	return true, nil
+}
+
+func (fakeProvider) ClusterKubeconfig(clusterID string) ([]byte, error) {
+	return []byte("kubeconfig for " + clusterID), nil
+}
+
+func (fakeProvider) Logs(clusterID string) (map[string][]byte, error) {
+	return map[string][]byte{"install": []byte("installed " + clusterID)}, nil
+}
+
+func (fakeProvider) DeleteCluster(clusterID string) error {
+	return errors.New("cluster is protected")
+}
+
+type fakeSuite struct{}
+
+func (fakeSuite) Info() (SuiteInfo, error) {
+	return SuiteInfo{Name: "fake", Tests: []string{"passes", "fails"}}, nil
+}
+
+func (fakeSuite) Run(args RunArgs) (TestResult, error) {
+	if args.Test == "fails" {
+		return TestResult{Message: "expected failure"}, nil
+	}
+	return TestResult{Passed: true, Output: string(args.Kubeconfig)}, nil
+}
+
+// TestHelperPlugin isn't a real test. It serves fake plugins when run by startHelper.
+func TestHelperPlugin(t *testing.T) {
+	if os.Getenv(helperEnv) != "1" {
+		return
+	}
+
+	err := Serve(map[string]interface{}{
+		ProviderService: &ProviderRPC{Impl: fakeProvider{}},
+		SuiteService:    &SuiteRPC{Impl: fakeSuite{}},
+	})
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
+
+func startHelper(t *testing.T) *Client {
+	cmd := exec.Command(os.Args[0], "-test.run=TestHelperPlugin")
+	cmd.Env = append(os.Environ(), helperEnv+"=1")
+
+	client, err := Start(cmd)
+	if err != nil {
+		t.Fatalf("failed to start plugin: %v", err)
+	}
+	return client
+}
+
+func TestProviderPlugin(t *testing.T) {
+	client := startHelper(t)
+	defer client.Close()
+	provider := &ProviderClient{Client: client}
+
+	cfg := &config.Config{ClusterName: "test", Region: "us-west-2"}
+	clusterID, err := provider.LaunchCluster(cfg)
+	if err != nil {
+		t.Fatalf("failed to launch cluster: %v", err)
+	} else if clusterID != "test-us-west-2" {
+		t.Errorf("expected cluster 'test-us-west-2', got '%s'", clusterID)
+	}
+
+	if err = provider.WaitForClusterReady(clusterID, time.Second, 10*time.Millisecond); err != nil {
+		t.Errorf("failed waiting for cluster: %v", err)
+	}
+
+	if kubeconfig, err := provider.ClusterKubeconfig(clusterID); err != nil {
+		t.Errorf("failed to get kubeconfig: %v", err)
+	} else if string(kubeconfig) != "kubeconfig for test-us-west-2" {
+		t.Errorf("unexpected kubeconfig '%s'", kubeconfig)
+	}
+
+	if logs, err := provider.FullLogs(clusterID); err != nil {
+		t.Errorf("failed to get logs: %v", err)
+	} else if string(logs["install"]) != "installed test-us-west-2" {
+		t.Errorf("unexpected logs: %v", logs)
+	}
+
+	if err = provider.DeleteCluster(clusterID); err == nil {
+		t.Error("expected error from plugin deleting cluster")
+	}
+}
+
+func TestSuitePlugin(t *testing.T) {
+	client := startHelper(t)
+	defer client.Close()
+	suite := &SuiteClient{Client: client}
+
+	info, err := suite.Info()
+	if err != nil {
+		t.Fatalf("failed to describe suite: %v", err)
+	} else if info.Name != "fake" || len(info.Tests) != 2 {
+		t.Fatalf("unexpected suite info: %+v", info)
+	}
+
+	if result, err := suite.Run("passes", []byte("kubeconfig")); err != nil {
+		t.Errorf("failed to run test: %v", err)
+	} else if !result.Passed || result.Output != "kubeconfig" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	if result, err := suite.Run("fails", nil); err != nil {
+		t.Errorf("failed to run test: %v", err)
+	} else if result.Passed || result.Message != "expected failure" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+}
+
+func TestStartWithoutHandshake(t *testing.T) {
+	if _, err := Start(exec.Command("true")); err == nil {
+		t.Error("expected error starting plugin that exits without handshake")
+	}
+}
+
+func TestParseHandshake(t *testing.T) {
+	h, err := ParseHandshake("1|1|unix|/tmp/osde2e-plugin/plugin.sock\n")
+	if err != nil {
+		t.Fatalf("failed to parse handshake: %v", err)
+	} else if h.Network != "unix" || h.Address != "/tmp/osde2e-plugin/plugin.sock" {
+		t.Errorf("unexpected handshake: %+v", h)
+	}
+
+	for _, line := range []string{
+		"1|1|unix",
+		"2|1|unix|/tmp/osde2e-plugin/plugin.sock",
+		"1|99|unix|/tmp/osde2e-plugin/plugin.sock",
+		"a|1|unix|/tmp/osde2e-plugin/plugin.sock",
+		"1|1|tcp|127.0.0.1:1234",
+	} {
+		if _, err := ParseHandshake(line); err == nil {
+			t.Errorf("expected error parsing handshake '%s'", line)
+		}
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	if v, err := negotiate("3, 1,2"); err != nil || v != 1 {
+		t.Errorf("expected version 1, got %d: %v", v, err)
+	}
+
+	if _, err := negotiate("2,3"); err == nil {
+		t.Error("expected error negotiating unsupported versions")
+	}
+}
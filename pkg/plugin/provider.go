@@ -0,0 +1,124 @@
+package plugin
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/openshift/osde2e/pkg/config"
+)
+
+// ProviderService is the name provider plugins are served as.
+const ProviderService = "Provider"
+
+// Provider creates and manages clusters. It is implemented by provider plugins.
+type Provider interface {
+	// LaunchCluster starts creating a cluster, returning its ID.
+	LaunchCluster(args LaunchArgs) (string, error)
+
+	// ClusterReady returns true once the cluster is ready for testing.
+	ClusterReady(clusterID string) (bool, error)
+
+	// ClusterKubeconfig returns credentials for the cluster.
+	ClusterKubeconfig(clusterID string) ([]byte, error)
+
+	// Logs returns logs of the cluster by name.
+	Logs(clusterID string) (map[string][]byte, error)
+
+	// DeleteCluster starts deleting the cluster.
+	DeleteCluster(clusterID string) error
+}
+
+// LaunchArgs describes the cluster to launch.
+type LaunchArgs struct {
+	Name    string
+	Version string
+	Region  string
+	MultiAZ bool
+}
+
+// ProviderRPC serves a Provider over RPC.
+type ProviderRPC struct {
+	Impl Provider
+}
+
+// LaunchCluster calls LaunchCluster of the provider.
+func (p *ProviderRPC) LaunchCluster(args LaunchArgs, clusterID *string) (err error) {
+	*clusterID, err = p.Impl.LaunchCluster(args)
+	return
+}
+
+// ClusterReady calls ClusterReady of the provider.
+func (p *ProviderRPC) ClusterReady(clusterID string, ready *bool) (err error) {
+	*ready, err = p.Impl.ClusterReady(clusterID)
+	return
+}
+
+// ClusterKubeconfig calls ClusterKubeconfig of the provider.
+func (p *ProviderRPC) ClusterKubeconfig(clusterID string, kubeconfig *[]byte) (err error) {
+	*kubeconfig, err = p.Impl.ClusterKubeconfig(clusterID)
+	return
+}
+
+// Logs calls Logs of the provider.
+func (p *ProviderRPC) Logs(clusterID string, logs *map[string][]byte) (err error) {
+	*logs, err = p.Impl.Logs(clusterID)
+	return
+}
+
+// DeleteCluster calls DeleteCluster of the provider.
+func (p *ProviderRPC) DeleteCluster(clusterID string, _ *struct{}) error {
+	return p.Impl.DeleteCluster(clusterID)
+}
+
+// ProviderClient manages clusters using a provider plugin. Its methods match those of osd.OSD used to manage clusters.
+type ProviderClient struct {
+	*Client
+}
+
+// LaunchCluster creates a cluster described by cfg, returning its ID.
+func (p *ProviderClient) LaunchCluster(cfg *config.Config) (clusterID string, err error) {
+	log.Printf("Creating cluster '%s' using provider plugin...", cfg.ClusterName)
+	err = p.Call(ProviderService+".LaunchCluster", LaunchArgs{
+		Name:    cfg.ClusterName,
+		Version: cfg.ClusterVersion,
+		Region:  cfg.Region,
+		MultiAZ: cfg.MultiAZ,
+	}, &clusterID)
+	return
+}
+
+// WaitForClusterReady blocks until clusterID is ready or timeout, checking every interval.
+func (p *ProviderClient) WaitForClusterReady(clusterID string, timeout, interval time.Duration) error {
+	log.Printf("Waiting %v for cluster '%s' to be ready...", timeout, clusterID)
+	return wait.PollImmediate(interval, timeout, func() (bool, error) {
+		var ready bool
+		if err := p.Call(ProviderService+".ClusterReady", clusterID, &ready); err != nil {
+			log.Printf("Encountered error waiting for cluster: %v", err)
+			return false, nil
+		}
+		return ready, nil
+	})
+}
+
+// ClusterKubeconfig retrieves the kubeconfig of clusterID.
+func (p *ProviderClient) ClusterKubeconfig(clusterID string) (kubeconfig []byte, err error) {
+	err = p.Call(ProviderService+".ClusterKubeconfig", clusterID, &kubeconfig)
+	return
+}
+
+// FullLogs retrieves the logs of clusterID. Specific logs can't be requested from plugins, so ids are ignored.
+func (p *ProviderClient) FullLogs(clusterID string, ids ...string) (logs map[string][]byte, err error) {
+	err = p.Call(ProviderService+".Logs", clusterID, &logs)
+	return
+}
+
+// DeleteCluster requests the deletion of clusterID.
+func (p *ProviderClient) DeleteCluster(clusterID string) error {
+	if err := p.Call(ProviderService+".DeleteCluster", clusterID, &struct{}{}); err != nil {
+		return fmt.Errorf("couldn't delete cluster '%s': %v", clusterID, err)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package plugin
+
+import (
+	"fmt"
+)
+
+// SuiteService is the name suite plugins are served as.
+const SuiteService = "Suite"
+
+// Suite is a set of tests run against a cluster. It is implemented by suite plugins.
+type Suite interface {
+	// Info describes the suite and the tests it contains.
+	Info() (SuiteInfo, error)
+
+	// Run performs a single test against the cluster accessed with the kubeconfig in args.
+	Run(args RunArgs) (TestResult, error)
+}
+
+// SuiteInfo describes a suite.
+type SuiteInfo struct {
+	Name  string
+	Tests []string
+}
+
+// RunArgs select the test to run and the cluster it's run against.
+type RunArgs struct {
+	Test       string
+	Kubeconfig []byte
+}
+
+// TestResult is the outcome of a test.
+type TestResult struct {
+	Passed bool
+
+	// Message explains why the test failed.
+	Message string
+
+	// Output is logged by the test.
+	Output string
+}
+
+// SuiteRPC serves a Suite over RPC.
+type SuiteRPC struct {
+	Impl Suite
+}
+
+// Info calls Info of the suite.
+func (s *SuiteRPC) Info(_ struct{}, info *SuiteInfo) (err error) {
+	*info, err = s.Impl.Info()
+	return
+}
+
+// Run calls Run of the suite.
+func (s *SuiteRPC) Run(args RunArgs, result *TestResult) (err error) {
+	*result, err = s.Impl.Run(args)
+	return
+}
+
+// SuiteClient runs tests of a suite plugin.
+type SuiteClient struct {
+	*Client
+}
+
+// Info describes the suite.
+func (s *SuiteClient) Info() (info SuiteInfo, err error) {
+	err = s.Call(SuiteService+".Info", struct{}{}, &info)
+	return
+}
+
+// Run performs test against the cluster accessed using kubeconfig.
+func (s *SuiteClient) Run(test string, kubeconfig []byte) (result TestResult, err error) {
+	if err = s.Call(SuiteService+".Run", RunArgs{Test: test, Kubeconfig: kubeconfig}, &result); err != nil {
+		return result, fmt.Errorf("couldn't run test '%s': %v", test, err)
+	}
+	return
+}
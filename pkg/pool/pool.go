@@ -0,0 +1,291 @@
+// Package pool keeps clusters installed ahead of time for each configuration profile, so runs can claim one instead
+// of waiting for an install.
+package pool
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openshift-online/uhc-sdk-go/pkg/client/clustersmgmt/v1"
+
+	"github.com/openshift/osde2e/pkg/config"
+	"github.com/openshift/osde2e/pkg/naming"
+	"github.com/openshift/osde2e/pkg/seed"
+)
+
+const (
+	// DefaultProfile is the profile of clusters left unconfigured.
+	DefaultProfile = "default"
+
+	// NamePrefix begins the names of pool clusters.
+	NamePrefix = "osde2e-pool-"
+
+	// PropertyProfile is the cluster property holding the profile of a pool cluster.
+	PropertyProfile = "osde2e-pool-profile"
+
+	// PropertyPrepared is set on pool clusters once their profile has been applied.
+	PropertyPrepared = "osde2e-pool-prepared"
+
+	// PropertyClaimed is set to who claimed a pool cluster. Claimed clusters are no longer managed by the pool.
+	PropertyClaimed = "osde2e-pool-claimed"
+)
+
+// ErrNoneAvailable is returned when claiming from a profile without ready clusters.
+var ErrNoneAvailable = errors.New("no clusters are available")
+
+// Clusters creates and manages the clusters of a pool.
+type Clusters interface {
+	LaunchCluster(cfg *config.Config) (string, error)
+	ListClusters(search string) ([]*v1.Cluster, error)
+	SetClusterProperties(clusterID string, properties map[string]string) error
+	DeleteCluster(clusterID string) error
+}
+
+// Pool keeps a number of ready, unclaimed clusters for each profile.
+type Pool struct {
+	// Clusters is used to create and manage clusters.
+	Clusters Clusters
+
+	// Config describes the clusters created.
+	Config *config.Config
+
+	// Sizes are how many ready clusters to keep for each profile.
+	Sizes map[string]int
+
+	// MinLifetime is how long clusters must have left before expiring to be claimed. Clusters expiring sooner are
+	// replaced.
+	MinLifetime time.Duration
+
+	// Prepare applies profile to a ready cluster before it's offered. Clusters are offered as installed if it's nil.
+	Prepare func(clusterID, profile string) error
+
+	mu     sync.RWMutex
+	status Status
+}
+
+// Status is the state of a pool's clusters when it was last checked.
+type Status struct {
+	Updated  time.Time       `json:"updated"`
+	Profiles []ProfileStatus `json:"profiles"`
+}
+
+// ProfileStatus lists the IDs of clusters of a profile by their state.
+type ProfileStatus struct {
+	Profile string `json:"profile"`
+	Size    int    `json:"size"`
+
+	Ready      []string `json:"ready"`
+	Installing []string `json:"installing"`
+	Expiring   []string `json:"expiring"`
+	Claimed    []string `json:"claimed"`
+}
+
+// ParseSizes converts counts of clusters for each profile, such as those in POOL_SIZES.
+func ParseSizes(sizes map[string]string) (map[string]int, error) {
+	parsed := make(map[string]int, len(sizes))
+	for profile, sizeStr := range sizes {
+		size, err := strconv.Atoi(sizeStr)
+		if err != nil || size < 0 {
+			return nil, fmt.Errorf("size of pool '%s' must not be negative, got '%s'", profile, sizeStr)
+		}
+		parsed[profile] = size
+	}
+	return parsed, nil
+}
+
+// Maintain checks the pool every interval until stop is closed.
+func (p *Pool) Maintain(interval time.Duration, stop <-chan struct{}) {
+	for {
+		if err := p.Reconcile(); err != nil {
+			log.Printf("Failed to maintain pool: %v", err)
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// Reconcile prepares newly ready clusters, deletes failed ones, and launches replacements for clusters which were
+// claimed or will soon expire.
+func (p *Pool) Reconcile() error {
+	clusters, err := p.Clusters.ListClusters(fmt.Sprintf("name like '%s%%'", NamePrefix))
+	if err != nil {
+		return err
+	}
+
+	profiles := map[string]*ProfileStatus{}
+	for profile, size := range p.Sizes {
+		profiles[profile] = &ProfileStatus{
+			Profile: profile,
+			Size:    size,
+		}
+	}
+
+	now := time.Now()
+	for _, c := range clusters {
+		props := properties(c)
+		profile, ok := props[PropertyProfile]
+		if !ok {
+			continue
+		}
+
+		s, ok := profiles[profile]
+		switch {
+		case props[PropertyClaimed] != "":
+			if ok {
+				s.Claimed = append(s.Claimed, c.ID())
+			}
+		case !ok:
+			log.Printf("Deleting cluster '%s' of profile '%s', which is no longer pooled.", c.ID(), profile)
+			p.delete(c.ID())
+		case c.State() == v1.ClusterStateError:
+			log.Printf("Deleting cluster '%s' of profile '%s', which failed to install.", c.ID(), profile)
+			p.delete(c.ID())
+		case expiresBefore(c, now.Add(p.MinLifetime)):
+			s.Expiring = append(s.Expiring, c.ID())
+		case c.State() != v1.ClusterStateReady:
+			s.Installing = append(s.Installing, c.ID())
+		case props[PropertyPrepared] == "":
+			if err := p.prepare(c.ID(), profile, props); err != nil {
+				log.Printf("Failed to prepare cluster '%s' with profile '%s': %v", c.ID(), profile, err)
+				s.Installing = append(s.Installing, c.ID())
+			} else {
+				s.Ready = append(s.Ready, c.ID())
+			}
+		default:
+			s.Ready = append(s.Ready, c.ID())
+		}
+	}
+
+	status := Status{Updated: now}
+	for _, s := range profiles {
+		for i := len(s.Ready) + len(s.Installing); i < s.Size; i++ {
+			id, err := p.launch(s.Profile)
+			if err != nil {
+				log.Printf("Failed to launch cluster for profile '%s': %v", s.Profile, err)
+				break
+			}
+			s.Installing = append(s.Installing, id)
+		}
+		status.Profiles = append(status.Profiles, *s)
+	}
+	sort.Slice(status.Profiles, func(i, j int) bool {
+		return status.Profiles[i].Profile < status.Profiles[j].Profile
+	})
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.status = status
+	return nil
+}
+
+// Claim marks a ready cluster of profile as claimed by claimer, returning its ID. ErrNoneAvailable is returned if
+// none are ready.
+func (p *Pool) Claim(profile, claimer string) (string, error) {
+	clusters, err := p.Clusters.ListClusters(fmt.Sprintf("name like '%s%%'", NamePrefix))
+	if err != nil {
+		return "", err
+	}
+
+	minExpiry := time.Now().Add(p.MinLifetime)
+	for _, c := range clusters {
+		props := properties(c)
+		if props[PropertyProfile] != profile || props[PropertyClaimed] != "" || props[PropertyPrepared] == "" ||
+			c.State() != v1.ClusterStateReady || expiresBefore(c, minExpiry) {
+			continue
+		}
+
+		props[PropertyClaimed] = claimer
+		if err = p.Clusters.SetClusterProperties(c.ID(), props); err != nil {
+			return "", err
+		}
+		log.Printf("Cluster '%s' of profile '%s' was claimed by '%s'.", c.ID(), profile, claimer)
+		return c.ID(), nil
+	}
+	return "", ErrNoneAvailable
+}
+
+// Status returns the state of the pool when it was last checked.
+func (p *Pool) Status() Status {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.status
+}
+
+// ServeHTTP writes the status of the pool as JSON.
+func (p *Pool) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(w, "only GET is supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	status := p.Status()
+	if status.Updated.IsZero() {
+		http.Error(w, "the pool has not been checked yet", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Last-Modified", status.Updated.Format(http.TimeFormat))
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		log.Printf("Failed writing pool status to %s: %v", req.RemoteAddr, err)
+	}
+}
+
+// launch creates a cluster for profile.
+func (p *Pool) launch(profile string) (string, error) {
+	cfg := *p.Config
+	cfg.ClusterName = naming.New(strings.TrimSuffix(NamePrefix, "-"), cfg.JobID, seed.String(seed.Suffix, 5)).String()
+	cfg.ClusterProperties = map[string]string{
+		PropertyProfile: profile,
+	}
+	for k, v := range p.Config.ClusterProperties {
+		cfg.ClusterProperties[k] = v
+	}
+	return p.Clusters.LaunchCluster(&cfg)
+}
+
+// prepare applies profile to clusterID, then marks it as prepared.
+func (p *Pool) prepare(clusterID, profile string, props map[string]string) error {
+	if p.Prepare != nil && profile != DefaultProfile {
+		if err := p.Prepare(clusterID, profile); err != nil {
+			return err
+		}
+	}
+
+	props[PropertyPrepared] = "true"
+	return p.Clusters.SetClusterProperties(clusterID, props)
+}
+
+// delete removes clusterID, logging failures so they're retried the next time the pool is checked.
+func (p *Pool) delete(clusterID string) {
+	if err := p.Clusters.DeleteCluster(clusterID); err != nil {
+		log.Printf("Failed to delete cluster '%s': %v", clusterID, err)
+	}
+}
+
+// properties returns a copy of the properties of c which can be changed.
+func properties(c *v1.Cluster) map[string]string {
+	props := make(map[string]string, len(c.Properties()))
+	for k, v := range c.Properties() {
+		props[k] = v
+	}
+	return props
+}
+
+// expiresBefore returns true if c will be deleted by OSD before t.
+func expiresBefore(c *v1.Cluster, t time.Time) bool {
+	expiry := c.ExpirationTimestamp()
+	return !expiry.IsZero() && expiry.Before(t)
+}
@@ -0,0 +1,180 @@
+package pool
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/openshift-online/uhc-sdk-go/pkg/client/clustersmgmt/v1"
+
+	"github.com/openshift/osde2e/pkg/config"
+)
+
+// fakeClusters keeps clusters in memory.
+type fakeClusters struct {
+	clusters map[string]*v1.ClusterBuilder
+	props    map[string]map[string]string
+	launched []*config.Config
+	deleted  []string
+}
+
+func (f *fakeClusters) add(id string, state v1.ClusterState, expiry time.Duration, props map[string]string) {
+	if f.clusters == nil {
+		f.clusters, f.props = map[string]*v1.ClusterBuilder{}, map[string]map[string]string{}
+	}
+	f.clusters[id] = v1.NewCluster().ID(id).State(state).ExpirationTimestamp(time.Now().Add(expiry))
+	f.props[id] = props
+}
+
+func (f *fakeClusters) LaunchCluster(cfg *config.Config) (string, error) {
+	f.launched = append(f.launched, cfg)
+	id := cfg.ClusterName
+	f.add(id, v1.ClusterStateInstalling, cfg.ClusterExpiry, cfg.ClusterProperties)
+	return id, nil
+}
+
+func (f *fakeClusters) ListClusters(search string) (clusters []*v1.Cluster, err error) {
+	for id, b := range f.clusters {
+		c, err := b.Properties(f.props[id]).Build()
+		if err != nil {
+			return nil, err
+		}
+		clusters = append(clusters, c)
+	}
+	return
+}
+
+func (f *fakeClusters) SetClusterProperties(clusterID string, properties map[string]string) error {
+	f.props[clusterID] = properties
+	return nil
+}
+
+func (f *fakeClusters) DeleteCluster(clusterID string) error {
+	f.deleted = append(f.deleted, clusterID)
+	delete(f.clusters, clusterID)
+	return nil
+}
+
+func TestReconcile(t *testing.T) {
+	clusters := new(fakeClusters)
+	clusters.add("ready", v1.ClusterStateReady, 20*time.Hour, map[string]string{PropertyProfile: "proxy", PropertyPrepared: "true"})
+	clusters.add("new", v1.ClusterStateReady, 20*time.Hour, map[string]string{PropertyProfile: "proxy"})
+	clusters.add("expiring", v1.ClusterStateReady, time.Hour, map[string]string{PropertyProfile: "proxy", PropertyPrepared: "true"})
+	clusters.add("claimed", v1.ClusterStateReady, 20*time.Hour, map[string]string{PropertyProfile: "proxy", PropertyClaimed: "ci"})
+	clusters.add("failed", v1.ClusterStateError, 20*time.Hour, map[string]string{PropertyProfile: "proxy"})
+	clusters.add("removed", v1.ClusterStateReady, 20*time.Hour, map[string]string{PropertyProfile: "motd"})
+	clusters.add("other", v1.ClusterStateReady, 20*time.Hour, nil)
+
+	var prepared []string
+	p := &Pool{
+		Clusters:    clusters,
+		Config:      &config.Config{ClusterExpiry: 24 * time.Hour},
+		Sizes:       map[string]int{"proxy": 3, DefaultProfile: 1},
+		MinLifetime: 6 * time.Hour,
+		Prepare: func(clusterID, profile string) error {
+			prepared = append(prepared, clusterID+"/"+profile)
+			return nil
+		},
+	}
+	if err := p.Reconcile(); err != nil {
+		t.Fatalf("Failed to reconcile pool: %v", err)
+	}
+
+	if !reflect.DeepEqual(prepared, []string{"new/proxy"}) || clusters.props["new"][PropertyPrepared] == "" {
+		t.Errorf("expected only the newly ready cluster to be prepared, got %v", prepared)
+	}
+
+	if len(clusters.deleted) != 2 {
+		t.Errorf("expected failed and no longer pooled clusters to be deleted, got %v", clusters.deleted)
+	}
+
+	// one cluster replaces the expiring one, another fills the default profile
+	if len(clusters.launched) != 2 {
+		t.Fatalf("expected 2 clusters to be launched, got %d", len(clusters.launched))
+	}
+	profiles := map[string]bool{}
+	for _, cfg := range clusters.launched {
+		profiles[cfg.ClusterProperties[PropertyProfile]] = true
+	}
+	if !profiles["proxy"] || !profiles[DefaultProfile] {
+		t.Errorf("expected a cluster to be launched for each profile, got %v", profiles)
+	}
+
+	status := p.Status()
+	if len(status.Profiles) != 2 || status.Profiles[1].Profile != "proxy" {
+		t.Fatalf("expected status of both profiles, got %+v", status)
+	}
+	proxy := status.Profiles[1]
+	if len(proxy.Ready) != 2 || len(proxy.Installing) != 1 || !reflect.DeepEqual(proxy.Expiring, []string{"expiring"}) ||
+		!reflect.DeepEqual(proxy.Claimed, []string{"claimed"}) {
+		t.Errorf("unexpected status of proxy profile: %+v", proxy)
+	}
+
+	// clusters are only launched to replace missing ones
+	if err := p.Reconcile(); err != nil {
+		t.Fatalf("Failed to reconcile pool: %v", err)
+	}
+	if len(clusters.launched) != 2 {
+		t.Errorf("expected no more clusters to be launched, got %d", len(clusters.launched))
+	}
+}
+
+func TestClaim(t *testing.T) {
+	clusters := new(fakeClusters)
+	clusters.add("ready", v1.ClusterStateReady, 20*time.Hour, map[string]string{PropertyProfile: "proxy", PropertyPrepared: "true"})
+	clusters.add("installing", v1.ClusterStateInstalling, 20*time.Hour, map[string]string{PropertyProfile: "proxy"})
+
+	p := &Pool{Clusters: clusters, MinLifetime: 6 * time.Hour}
+	if id, err := p.Claim("proxy", "ci"); err != nil || id != "ready" {
+		t.Fatalf("expected to claim ready cluster, got '%s': %v", id, err)
+	} else if clusters.props["ready"][PropertyClaimed] != "ci" {
+		t.Errorf("expected cluster to be marked claimed, got %v", clusters.props["ready"])
+	}
+
+	if _, err := p.Claim("proxy", "ci"); err != ErrNoneAvailable {
+		t.Errorf("expected no more clusters to be available, got %v", err)
+	}
+}
+
+func TestServeStatus(t *testing.T) {
+	p := &Pool{Clusters: new(fakeClusters), Sizes: map[string]int{}}
+	srv := httptest.NewServer(p)
+	defer srv.Close()
+
+	if resp, err := http.Get(srv.URL); err != nil {
+		t.Fatalf("Failed requesting status: %v", err)
+	} else if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected status %d before the pool was checked, got %d", http.StatusServiceUnavailable, resp.StatusCode)
+	}
+
+	if err := p.Reconcile(); err != nil {
+		t.Fatalf("Failed to reconcile pool: %v", err)
+	}
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("Failed requesting status: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var status Status
+	if err = json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		t.Fatalf("Failed decoding status: %v", err)
+	} else if status.Updated.IsZero() {
+		t.Errorf("expected status to be updated, got %+v", status)
+	}
+}
+
+func TestParseSizes(t *testing.T) {
+	if sizes, err := ParseSizes(map[string]string{"default": "2", "proxy": "1"}); err != nil ||
+		!reflect.DeepEqual(sizes, map[string]int{"default": 2, "proxy": 1}) {
+		t.Errorf("unexpected sizes %v: %v", sizes, err)
+	}
+
+	if _, err := ParseSizes(map[string]string{"default": "-1"}); err == nil {
+		t.Error("negative size should be invalid")
+	}
+}
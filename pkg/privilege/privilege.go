@@ -0,0 +1,186 @@
+// Package privilege checks suites only need the permissions they declare. When testing with least privilege, specs are
+// run as a ServiceAccount granted only what their suite declares, and requests denied by RBAC are reported as failures
+// of the suite, so tests and their docs don't secretly depend on cluster-admin.
+package privilege
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"k8s.io/test-infra/testgrid/metadata/junit"
+
+	"github.com/openshift/osde2e/pkg/junitprops"
+)
+
+const (
+	// SuiteName is the JUnit suite containing a testcase for each suite run with least privilege.
+	SuiteName = "Least privilege"
+
+	// ServiceAccount is the name of the ServiceAccount specs are run as in their project.
+	ServiceAccount = "osde2e-restricted"
+
+	// ProjectRole is the ClusterRole granted to the ServiceAccount in its project.
+	ProjectRole = "admin"
+
+	// maxDenialBody is the most of a denied response read for its message.
+	maxDenialBody = 64 * 1024
+)
+
+// Denial is a request of a spec which RBAC refused.
+type Denial struct {
+	Suite   string `json:"suite"`
+	Spec    string `json:"spec"`
+	Method  string `json:"method"`
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// String describes the request and why it was denied.
+func (d Denial) String() string {
+	return fmt.Sprintf("%s %s: %s", d.Method, d.Path, d.Message)
+}
+
+// Current records the denials of this run. It is nil when not testing with least privilege.
+var Current *Recorder
+
+// Recorder records which suites were run with least privilege and the requests they were denied. A nil Recorder
+// records nothing.
+type Recorder struct {
+	mu      sync.Mutex
+	suites  map[string]bool
+	denials []Denial
+}
+
+// New returns a Recorder without suites or denials.
+func New() *Recorder {
+	return &Recorder{suites: map[string]bool{}}
+}
+
+// Restricted records that specs of suite were run with least privilege.
+func (r *Recorder) Restricted(suite string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.suites[suite] = true
+}
+
+// Deny records that d was refused.
+func (r *Recorder) Deny(d Denial) {
+	if r == nil {
+		return
+	}
+	log.Printf("RBAC denied a request of '%s' in %s: %s", d.Spec, d.Suite, d)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.suites[d.Suite] = true
+	r.denials = append(r.denials, d)
+}
+
+// Denials returns the requests refused so far.
+func (r *Recorder) Denials() []Denial {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Denial(nil), r.denials...)
+}
+
+// Transport records requests of spec in suite made with rt which are forbidden.
+func (r *Recorder) Transport(rt http.RoundTripper, suite, spec string) http.RoundTripper {
+	return &denialTransport{r: r, rt: rt, suite: suite, spec: spec}
+}
+
+type denialTransport struct {
+	r           *Recorder
+	rt          http.RoundTripper
+	suite, spec string
+}
+
+func (t *denialTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.rt.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusForbidden {
+		return resp, err
+	}
+
+	// the body is read for its message and replaced for the caller
+	data, readErr := ioutil.ReadAll(io.LimitReader(resp.Body, maxDenialBody))
+	resp.Body.Close()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(data))
+	if readErr != nil {
+		return resp, nil
+	}
+
+	var status struct {
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(data, &status) != nil || status.Message == "" {
+		status.Message = strings.TrimSpace(string(data))
+	}
+	t.r.Deny(Denial{
+		Suite:   t.suite,
+		Spec:    t.spec,
+		Method:  req.Method,
+		Path:    req.URL.Path,
+		Message: status.Message,
+	})
+	return resp, nil
+}
+
+// WriteJUnit records a testcase in dir for each suite run with least privilege, failing if it was denied requests.
+func (r *Recorder) WriteJUnit(dir, suffix string) error {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	var suites []string
+	for suite := range r.suites {
+		suites = append(suites, suite)
+	}
+	denials := map[string][]string{}
+	for _, d := range r.denials {
+		denials[d.Suite] = appendUnique(denials[d.Suite], d.String())
+	}
+	r.mu.Unlock()
+	sort.Strings(suites)
+
+	results := junit.Suite{
+		Name:  SuiteName,
+		Tests: len(suites),
+	}
+	for _, suite := range suites {
+		result := junit.Result{
+			Name:      fmt.Sprintf("[least privilege] %s should only need the permissions it declares", suite),
+			ClassName: SuiteName,
+		}
+		if len(denials[suite]) != 0 {
+			msg := fmt.Sprintf("%d requests were denied:\n%s", len(denials[suite]), strings.Join(denials[suite], "\n"))
+			result.Failure = &msg
+			results.Failures++
+		}
+		results.Results = append(results.Results, result)
+	}
+
+	return junitprops.WriteSuite(dir, "privilege", suffix, results)
+}
+
+// appendUnique appends s to list if it isn't in it.
+func appendUnique(list []string, s string) []string {
+	for _, existing := range list {
+		if existing == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
@@ -0,0 +1,90 @@
+package privilege
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s.io/test-infra/testgrid/metadata/junit"
+)
+
+func TestTransport(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if strings.HasPrefix(req.URL.Path, "/api/v1/pods") {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"kind":"Status","status":"Failure","message":"pods is forbidden: User \"system:serviceaccount:osde2e-abc:osde2e-restricted\" cannot list resource \"pods\" in API group \"\" at the cluster scope","reason":"Forbidden","code":403}`))
+			return
+		}
+		w.Write([]byte(`{"kind":"PodList"}`))
+	}))
+	defer srv.Close()
+
+	r := New()
+	client := &http.Client{Transport: r.Transport(http.DefaultTransport, "Pods", "should be Running")}
+	for _, path := range []string{"/api/v1/pods", "/api/v1/namespaces/osde2e-abc/pods", "/api/v1/pods"} {
+		resp, err := client.Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if !strings.Contains(strings.ToLower(string(body)), "pod") {
+			t.Errorf("expected response body to be passed on, got '%s'", body)
+		}
+	}
+
+	denials := r.Denials()
+	if len(denials) != 2 || denials[0].Method != http.MethodGet || denials[0].Path != "/api/v1/pods" ||
+		!strings.HasPrefix(denials[0].Message, "pods is forbidden") || denials[0].Suite != "Pods" {
+		t.Errorf("expected forbidden requests to be denials, got %+v", denials)
+	}
+
+	var nilRecorder *Recorder
+	nilRecorder.Restricted("Pods")
+	nilRecorder.Deny(Denial{Suite: "Pods"})
+	if len(nilRecorder.Denials()) != 0 {
+		t.Error("expected nil recorder to record nothing")
+	}
+}
+
+func TestWriteJUnit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "privilege")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	r := New()
+	r.Restricted("Routes")
+	r.Restricted("Pods")
+	denial := Denial{Suite: "Pods", Spec: "should be Running", Method: http.MethodGet, Path: "/api/v1/pods", Message: "forbidden"}
+	r.Deny(denial)
+	r.Deny(denial)
+	if err = r.WriteJUnit(dir, "abc"); err != nil {
+		t.Fatalf("failed writing results: %v", err)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "junit_privilege_abc.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var suite junit.Suite
+	if err = xml.Unmarshal(data, &suite); err != nil {
+		t.Fatal(err)
+	}
+	if suite.Tests != 2 || suite.Failures != 1 || len(suite.Results) != 2 {
+		t.Fatalf("expected 2 testcases with 1 failure, got %+v", suite)
+	}
+	if failure := suite.Results[0].Failure; failure == nil ||
+		*failure != "1 requests were denied:\nGET /api/v1/pods: forbidden" {
+		t.Errorf("expected Pods to fail with its denial, got %v", failure)
+	}
+	if suite.Results[1].Failure != nil {
+		t.Error("expected Routes to pass without denials")
+	}
+}
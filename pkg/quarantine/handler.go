@@ -0,0 +1,32 @@
+package quarantine
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/onsi/ginkgo"
+
+	"github.com/openshift/osde2e/pkg/skips"
+)
+
+// FailHandler wraps fail so failures of quarantined tests skip the test instead of failing it.
+func (l *List) FailHandler(fail func(message string, callerSkip ...int)) func(message string, callerSkip ...int) {
+	return func(message string, callerSkip ...int) {
+		skip := 1
+		if len(callerSkip) > 0 {
+			skip += callerSkip[0]
+		}
+
+		test := ginkgo.CurrentGinkgoTestDescription().FullTestText
+		if e, ok := l.Active(test, time.Now()); ok {
+			log.Printf("Test '%s' is quarantined by %s, ignoring failure: %s", test, e.Issue, message)
+			l.record(test, Failure{
+				Entry:   e,
+				Message: message,
+			})
+			skips.Skip(skips.Quarantined, fmt.Sprintf("quarantined by %s: %s", e.Issue, message), skip)
+		}
+		fail(message, skip)
+	}
+}
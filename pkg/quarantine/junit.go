@@ -0,0 +1,102 @@
+package quarantine
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+const (
+	// PropertyQuarantined is set to true on test cases whose failures were quarantined.
+	PropertyQuarantined = "quarantined"
+
+	// PropertyIssue is the issue tracking a quarantined test.
+	PropertyIssue = "quarantine-issue"
+
+	// PropertyExpires is when the quarantine of a test expires.
+	PropertyExpires = "quarantine-expires"
+)
+
+// junitSuite is the JUnit format written by Ginkgo, with properties for test cases.
+type junitSuite struct {
+	XMLName   xml.Name        `xml:"testsuite"`
+	TestCases []junitTestCase `xml:"testcase"`
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Time      float64         `xml:"time,attr"`
+}
+
+type junitTestCase struct {
+	Name           string           `xml:"name,attr"`
+	ClassName      string           `xml:"classname,attr"`
+	Properties     *junitProperties `xml:"properties,omitempty"`
+	FailureMessage *junitFailure    `xml:"failure,omitempty"`
+	Skipped        *junitSkipped    `xml:"skipped,omitempty"`
+	Time           float64          `xml:"time,attr"`
+	SystemOut      string           `xml:"system-out,omitempty"`
+}
+
+type junitProperties struct {
+	Properties []junitProperty `xml:"property"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitFailure struct {
+	Type    string `xml:"type,attr"`
+	Message string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr,omitempty"`
+}
+
+// AnnotateJUnit marks quarantined failures in the JUnit file with properties and includes their failure messages.
+func (l *List) AnnotateJUnit(file string) error {
+	quarantined := l.Quarantined()
+	if len(quarantined) == 0 {
+		return nil
+	}
+
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("couldn't read JUnit '%s': %v", file, err)
+	}
+
+	var suite junitSuite
+	if err = xml.Unmarshal(data, &suite); err != nil {
+		return fmt.Errorf("couldn't decode JUnit '%s': %v", file, err)
+	}
+
+	for i := range suite.TestCases {
+		tc := &suite.TestCases[i]
+		f, ok := quarantined[tc.Name]
+		if !ok {
+			continue
+		}
+
+		if tc.Properties == nil {
+			tc.Properties = new(junitProperties)
+		}
+		tc.Properties.Properties = append(tc.Properties.Properties,
+			junitProperty{Name: PropertyQuarantined, Value: "true"},
+			junitProperty{Name: PropertyIssue, Value: f.Entry.Issue},
+			junitProperty{Name: PropertyExpires, Value: f.Entry.Expires},
+		)
+		tc.Skipped = &junitSkipped{
+			Message: fmt.Sprintf("quarantined by %s", f.Entry.Issue),
+		}
+		tc.SystemOut = f.Message + "\n" + tc.SystemOut
+	}
+
+	if data, err = xml.MarshalIndent(suite, "", "  "); err != nil {
+		return fmt.Errorf("couldn't encode JUnit '%s': %v", file, err)
+	}
+	return ioutil.WriteFile(file, append([]byte(xml.Header), data...), os.ModePerm)
+}
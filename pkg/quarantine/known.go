@@ -0,0 +1,209 @@
+package quarantine
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/Masterminds/semver"
+)
+
+const (
+	// PropertyKnownIssue is the issue a test case's failure is known to be caused by.
+	PropertyKnownIssue = "known-issue"
+
+	// PropertyStatus is set to StatusKnownIssue on test cases failing from a known issue.
+	PropertyStatus = "status"
+
+	// StatusKnownIssue marks failures caused by a known issue.
+	StatusKnownIssue = "known issue"
+)
+
+// KnownIssue links failures matching a pattern to the issue tracking them. Unlike quarantined tests, tests failing
+// from known issues still fail.
+type KnownIssue struct {
+	// Failure is a regular expression matching the failure messages caused by the issue.
+	Failure string `json:"failure"`
+
+	// Test is an optional regular expression limiting the issue to tests with matching full names.
+	Test string `json:"test,omitempty"`
+
+	// Issue tracks fixing the failure.
+	Issue string `json:"issue"`
+
+	// Versions is an optional range of affected OpenShift versions, such as '>= 4.2, < 4.3'.
+	Versions string `json:"versions,omitempty"`
+
+	failure  *regexp.Regexp
+	test     *regexp.Regexp
+	versions *semver.Constraints
+}
+
+// KnownIssueMatches is how many failures a known issue has matched. Known issues which stop matching have likely
+// been fixed and can be removed.
+type KnownIssueMatches struct {
+	KnownIssue
+	Count int
+}
+
+// parse compiles the patterns and version range of k.
+func (k *KnownIssue) parse() (err error) {
+	if k.Issue == "" {
+		return fmt.Errorf("known issue '%s' must link an issue", k.Failure)
+	}
+
+	if k.failure, err = regexp.Compile(k.Failure); err != nil {
+		return fmt.Errorf("invalid failure pattern '%s': %v", k.Failure, err)
+	}
+	if k.Test != "" {
+		if k.test, err = regexp.Compile(k.Test); err != nil {
+			return fmt.Errorf("invalid test pattern '%s': %v", k.Test, err)
+		}
+	}
+	if k.Versions != "" {
+		if k.versions, err = semver.NewConstraint(k.Versions); err != nil {
+			return fmt.Errorf("invalid versions '%s' of known issue %s: %v", k.Versions, k.Issue, err)
+		}
+	}
+	return nil
+}
+
+// matches returns true if k caused test to fail with message on a cluster at any of versions.
+func (k KnownIssue) matches(test, message string, versions []string) bool {
+	if !k.failure.MatchString(message) || (k.test != nil && !k.test.MatchString(test)) {
+		return false
+	} else if k.versions == nil {
+		return true
+	}
+
+	for _, v := range versions {
+		if release, ok := releaseVersion(v); ok && k.versions.Check(release) {
+			return true
+		}
+	}
+	return false
+}
+
+// releaseVersion parses the release of an OpenShift version such as 'openshift-v4.2.0-0.nightly-2019-09-26-192831',
+// ignoring any pre-release so nightlies are in the range of the release they lead up to.
+func releaseVersion(version string) (*semver.Version, bool) {
+	version = strings.TrimPrefix(strings.TrimPrefix(version, "openshift-"), "v")
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return nil, false
+	}
+
+	release, err := semver.NewVersion(fmt.Sprintf("%d.%d.%d", v.Major(), v.Minor(), v.Patch()))
+	return release, err == nil
+}
+
+// Known returns the known issue that caused test to fail with message on a cluster at any of versions, counting
+// the match.
+func (l *List) Known(test, message string, versions ...string) (KnownIssue, bool) {
+	i := l.match(test, message, versions)
+	if i < 0 {
+		return KnownIssue{}, false
+	}
+
+	l.mu.Lock()
+	if l.knownMatches == nil {
+		l.knownMatches = map[int]int{}
+	}
+	l.knownMatches[i]++
+	l.mu.Unlock()
+	return l.KnownIssues[i], true
+}
+
+// Match returns the known issue that caused test to fail with message on a cluster at any of versions, without
+// counting the match.
+func (l *List) Match(test, message string, versions ...string) (KnownIssue, bool) {
+	if i := l.match(test, message, versions); i >= 0 {
+		return l.KnownIssues[i], true
+	}
+	return KnownIssue{}, false
+}
+
+// match returns the index of the first known issue matching the failure, or -1 if none do.
+func (l *List) match(test, message string, versions []string) int {
+	if l == nil {
+		return -1
+	}
+	for i, k := range l.KnownIssues {
+		if k.matches(test, message, versions) {
+			return i
+		}
+	}
+	return -1
+}
+
+// KnownMatches returns every known issue with how many failures it has matched, in the order they're listed.
+func (l *List) KnownMatches() []KnownIssueMatches {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	matches := make([]KnownIssueMatches, len(l.KnownIssues))
+	for i, k := range l.KnownIssues {
+		matches[i] = KnownIssueMatches{
+			KnownIssue: k,
+			Count:      l.knownMatches[i],
+		}
+	}
+	return matches
+}
+
+// AnnotateKnownIssues marks failures in the JUnit file caused by known issues with properties, prefixing their
+// failure messages with the issue. Versions are those the cluster ran during the tests.
+func (l *List) AnnotateKnownIssues(file string, versions ...string) error {
+	if l == nil || len(l.KnownIssues) == 0 {
+		return nil
+	}
+
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("couldn't read JUnit '%s': %v", file, err)
+	}
+
+	var suite junitSuite
+	if err = xml.Unmarshal(data, &suite); err != nil {
+		return fmt.Errorf("couldn't decode JUnit '%s': %v", file, err)
+	}
+
+	var annotated bool
+	for i := range suite.TestCases {
+		tc := &suite.TestCases[i]
+		if tc.FailureMessage == nil {
+			continue
+		}
+
+		k, ok := l.Known(tc.Name, tc.FailureMessage.Message, versions...)
+		if !ok {
+			continue
+		}
+
+		if tc.Properties == nil {
+			tc.Properties = new(junitProperties)
+		}
+		tc.Properties.Properties = append(tc.Properties.Properties,
+			junitProperty{Name: PropertyKnownIssue, Value: k.Issue},
+			junitProperty{Name: PropertyStatus, Value: StatusKnownIssue},
+		)
+		tc.FailureMessage.Message = fmt.Sprintf("Known issue %s: %s", k.Issue, tc.FailureMessage.Message)
+		annotated = true
+	}
+
+	if !annotated {
+		return nil
+	}
+
+	if data, err = xml.MarshalIndent(suite, "", "  "); err != nil {
+		return fmt.Errorf("couldn't encode JUnit '%s': %v", file, err)
+	}
+	return ioutil.WriteFile(file, append([]byte(xml.Header), data...), os.ModePerm)
+}
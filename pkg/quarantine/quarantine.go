@@ -0,0 +1,157 @@
+// Package quarantine prevents failures of known-broken tests from failing runs and links failures to known issues.
+package quarantine
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+// DateLayout is the format of expiry dates.
+const DateLayout = "2006-01-02"
+
+// Entry quarantines tests matching a pattern until it expires.
+type Entry struct {
+	// Test is a regular expression matching the full names of quarantined tests.
+	Test string `json:"test"`
+
+	// Issue tracks fixing the test.
+	Issue string `json:"issue"`
+
+	// Reason describes why the test is broken.
+	Reason string `json:"reason,omitempty"`
+
+	// Expires is the date, in DateLayout, after which failures are no longer quarantined.
+	Expires string `json:"expires"`
+
+	test    *regexp.Regexp
+	expires time.Time
+}
+
+// Expired returns true if the entry no longer applies at now.
+func (e Entry) Expired(now time.Time) bool {
+	return !now.Before(e.expires)
+}
+
+// ExpiresAt returns the time the entry expires.
+func (e Entry) ExpiresAt() time.Time {
+	return e.expires
+}
+
+// List is a set of quarantined tests and the failures that have been quarantined, along with known issues
+// failures are linked to.
+type List struct {
+	Entries     []Entry      `json:"quarantine"`
+	KnownIssues []KnownIssue `json:"knownIssues,omitempty"`
+
+	mu           sync.Mutex
+	quarantined  map[string]Failure
+	knownMatches map[int]int
+}
+
+// Failure is a failure of a quarantined test.
+type Failure struct {
+	Entry   Entry
+	Message string
+}
+
+// Load reads a YAML quarantine list from file. An empty list is returned if file doesn't exist.
+func Load(file string) (*List, error) {
+	data, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return new(List), nil
+	} else if err != nil {
+		return nil, fmt.Errorf("couldn't read quarantine list '%s': %v", file, err)
+	}
+	return Parse(data)
+}
+
+// Parse decodes a YAML quarantine list.
+func Parse(data []byte) (*List, error) {
+	l := new(List)
+	if err := yaml.Unmarshal(data, l); err != nil {
+		return nil, fmt.Errorf("couldn't decode quarantine list: %v", err)
+	}
+
+	for i := range l.Entries {
+		e := &l.Entries[i]
+		if e.Issue == "" {
+			return nil, fmt.Errorf("quarantine of '%s' must link an issue", e.Test)
+		}
+
+		var err error
+		if e.test, err = regexp.Compile(e.Test); err != nil {
+			return nil, fmt.Errorf("invalid test pattern '%s': %v", e.Test, err)
+		}
+		if e.expires, err = time.Parse(DateLayout, e.Expires); err != nil {
+			return nil, fmt.Errorf("invalid expiry of quarantine for '%s': %v", e.Test, err)
+		}
+	}
+
+	for i := range l.KnownIssues {
+		if err := l.KnownIssues[i].parse(); err != nil {
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+// Active returns the unexpired entry quarantining test at now.
+func (l *List) Active(test string, now time.Time) (Entry, bool) {
+	if l == nil {
+		return Entry{}, false
+	}
+
+	for _, e := range l.Entries {
+		if !e.Expired(now) && e.test.MatchString(test) {
+			return e, true
+		}
+	}
+	return Entry{}, false
+}
+
+// Expiring returns entries that have expired or will expire within d of now, soonest first.
+func (l *List) Expiring(now time.Time, d time.Duration) (expiring []Entry) {
+	if l == nil {
+		return
+	}
+
+	for _, e := range l.Entries {
+		if e.Expired(now.Add(d)) {
+			expiring = append(expiring, e)
+		}
+	}
+	sort.Slice(expiring, func(i, j int) bool {
+		return expiring[i].expires.Before(expiring[j].expires)
+	})
+	return
+}
+
+// Quarantined returns the failures that have been quarantined by test name.
+func (l *List) Quarantined() map[string]Failure {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	failures := make(map[string]Failure, len(l.quarantined))
+	for k, v := range l.quarantined {
+		failures[k] = v
+	}
+	return failures
+}
+
+// record notes the failure of a quarantined test.
+func (l *List) record(test string, f Failure) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.quarantined == nil {
+		l.quarantined = map[string]Failure{}
+	}
+	l.quarantined[test] = f
+}
@@ -0,0 +1,233 @@
+package quarantine
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testList = `
+quarantine:
+- test: "should have no alerts$"
+  issue: https://github.com/openshift/osde2e/issues/1
+  expires: "2019-10-01"
+- test: "^\\[Suite: operators\\]"
+  issue: https://github.com/openshift/osde2e/issues/2
+  reason: operator is being replaced
+  expires: "2019-09-01"
+`
+
+func TestActive(t *testing.T) {
+	l, err := Parse([]byte(testList))
+	if err != nil {
+		t.Fatalf("Failed parsing list: %v", err)
+	}
+
+	now := time.Date(2019, 9, 15, 0, 0, 0, 0, time.UTC)
+	if e, ok := l.Active("[Suite: e2e] Cluster state should have no alerts", now); !ok {
+		t.Error("expected test to be quarantined")
+	} else if e.Issue != "https://github.com/openshift/osde2e/issues/1" {
+		t.Errorf("test quarantined by unexpected issue '%s'", e.Issue)
+	}
+
+	if _, ok := l.Active("[Suite: operators] should run", now); ok {
+		t.Error("expired quarantine shouldn't be active")
+	}
+
+	if _, ok := l.Active("[Suite: e2e] should have no alerts firing", now); ok {
+		t.Error("unmatched test shouldn't be quarantined")
+	}
+
+	var nilList *List
+	if _, ok := nilList.Active("[Suite: e2e] Cluster state should have no alerts", now); ok {
+		t.Error("nil list shouldn't quarantine tests")
+	}
+}
+
+func TestExpiring(t *testing.T) {
+	l, err := Parse([]byte(testList))
+	if err != nil {
+		t.Fatalf("Failed parsing list: %v", err)
+	}
+
+	now := time.Date(2019, 9, 15, 0, 0, 0, 0, time.UTC)
+	if expiring := l.Expiring(now, 7*24*time.Hour); len(expiring) != 1 || expiring[0].Expires != "2019-09-01" {
+		t.Errorf("expected only expired entry, got %v", expiring)
+	}
+
+	expiring := l.Expiring(now, 30*24*time.Hour)
+	if len(expiring) != 2 || expiring[0].Expires != "2019-09-01" {
+		t.Errorf("expected both entries soonest first, got %v", expiring)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, invalid := range []string{
+		"quarantine:\n- test: a\n  expires: \"2019-10-01\"\n",
+		"quarantine:\n- test: \"(\"\n  issue: b\n  expires: \"2019-10-01\"\n",
+		"quarantine:\n- test: a\n  issue: b\n  expires: October\n",
+		"knownIssues:\n- failure: a\n",
+		"knownIssues:\n- failure: \"(\"\n  issue: b\n",
+		"knownIssues:\n- failure: a\n  issue: b\n  versions: newest\n",
+	} {
+		if _, err := Parse([]byte(invalid)); err == nil {
+			t.Errorf("list should be invalid: %s", invalid)
+		}
+	}
+}
+
+func TestAnnotateJUnit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "quarantine")
+	if err != nil {
+		t.Fatalf("Failed creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "junit.xml")
+	junit := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuite name="OSD e2e suite" tests="2" failures="0" errors="0" time="10">
+  <testcase name="[Suite: e2e] Cluster state should have no alerts" classname="OSD e2e suite" time="4">
+    <skipped></skipped>
+  </testcase>
+  <testcase name="[Suite: e2e] Cluster state should be healthy" classname="OSD e2e suite" time="6"></testcase>
+</testsuite>`
+	if err = ioutil.WriteFile(file, []byte(junit), os.ModePerm); err != nil {
+		t.Fatalf("Failed writing JUnit: %v", err)
+	}
+
+	l, err := Parse([]byte(testList))
+	if err != nil {
+		t.Fatalf("Failed parsing list: %v", err)
+	}
+	e, _ := l.Active("[Suite: e2e] Cluster state should have no alerts", time.Date(2019, 9, 15, 0, 0, 0, 0, time.UTC))
+	l.record("[Suite: e2e] Cluster state should have no alerts", Failure{
+		Entry:   e,
+		Message: "alert KubeAPIDown firing",
+	})
+
+	if err = l.AnnotateJUnit(file); err != nil {
+		t.Fatalf("Failed annotating JUnit: %v", err)
+	}
+
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatalf("Failed reading JUnit: %v", err)
+	}
+
+	for _, expected := range []string{
+		`<property name="quarantined" value="true"></property>`,
+		`<property name="quarantine-issue" value="https://github.com/openshift/osde2e/issues/1"></property>`,
+		`<skipped message="quarantined by https://github.com/openshift/osde2e/issues/1"></skipped>`,
+		`alert KubeAPIDown firing`,
+	} {
+		if !strings.Contains(string(data), expected) {
+			t.Errorf("expected JUnit to contain '%s', got:\n%s", expected, data)
+		}
+	}
+
+	if strings.Count(string(data), "<properties>") != 1 {
+		t.Errorf("only the quarantined test should have properties:\n%s", data)
+	}
+}
+
+const knownList = `
+knownIssues:
+- failure: "etcd leader changed"
+  issue: https://issues.redhat.com/browse/OSD-1
+  versions: ">= 4.2, < 4.3"
+- failure: "timed out"
+  test: "^\\[Suite: operators\\]"
+  issue: https://issues.redhat.com/browse/OSD-2
+- failure: "never happens"
+  issue: https://issues.redhat.com/browse/OSD-3
+`
+
+func TestKnown(t *testing.T) {
+	l, err := Parse([]byte(knownList))
+	if err != nil {
+		t.Fatalf("Failed parsing list: %v", err)
+	}
+
+	for _, test := range []struct {
+		name, message string
+		versions      []string
+		issue         string
+	}{
+		{name: "nightly in range", message: "etcd leader changed", versions: []string{"openshift-v4.2.0-0.nightly-2019-09-26-192831"},
+			issue: "https://issues.redhat.com/browse/OSD-1"},
+		{name: "upgraded into range", message: "etcd leader changed", versions: []string{"openshift-v4.1.9", "4.2.2"},
+			issue: "https://issues.redhat.com/browse/OSD-1"},
+		{name: "out of range", message: "etcd leader changed", versions: []string{"openshift-v4.3.0"}},
+		{name: "unknown version", message: "etcd leader changed"},
+		{name: "[Suite: operators] should install", message: "timed out waiting", issue: "https://issues.redhat.com/browse/OSD-2"},
+		{name: "[Suite: e2e] should install", message: "timed out waiting"},
+	} {
+		k, ok := l.Known(test.name, test.message, test.versions...)
+		if ok != (test.issue != "") || k.Issue != test.issue {
+			t.Errorf("%s: expected known issue '%s', got '%s'", test.name, test.issue, k.Issue)
+		}
+	}
+
+	// matching without counting, such as for hints, leaves the counts unchanged
+	if k, ok := l.Match("[Suite: operators] should install", "timed out waiting"); !ok || k.Issue != "https://issues.redhat.com/browse/OSD-2" {
+		t.Errorf("expected failure to match OSD-2, got '%s'", k.Issue)
+	}
+
+	matches := l.KnownMatches()
+	if len(matches) != 3 || matches[0].Count != 2 || matches[1].Count != 1 || matches[2].Count != 0 {
+		t.Errorf("expected 2, 1, and 0 matches, got %v", matches)
+	}
+}
+
+func TestAnnotateKnownIssues(t *testing.T) {
+	dir, err := ioutil.TempDir("", "quarantine")
+	if err != nil {
+		t.Fatalf("Failed creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "junit.xml")
+	junit := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuite name="OSD e2e suite" tests="2" failures="2" errors="0" time="10">
+  <testcase name="[Suite: e2e] Cluster state should be healthy" classname="OSD e2e suite" time="4">
+    <failure type="Failure">etcd leader changed</failure>
+  </testcase>
+  <testcase name="[Suite: e2e] Cluster state should have no alerts" classname="OSD e2e suite" time="6">
+    <failure type="Failure">alert KubeAPIDown firing</failure>
+  </testcase>
+</testsuite>`
+	if err = ioutil.WriteFile(file, []byte(junit), os.ModePerm); err != nil {
+		t.Fatalf("Failed writing JUnit: %v", err)
+	}
+
+	l, err := Parse([]byte(knownList))
+	if err != nil {
+		t.Fatalf("Failed parsing list: %v", err)
+	}
+
+	if err = l.AnnotateKnownIssues(file, "openshift-v4.2.4"); err != nil {
+		t.Fatalf("Failed annotating JUnit: %v", err)
+	}
+
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatalf("Failed reading JUnit: %v", err)
+	}
+
+	for _, expected := range []string{
+		`<property name="known-issue" value="https://issues.redhat.com/browse/OSD-1"></property>`,
+		`<property name="status" value="known issue"></property>`,
+		`Known issue https://issues.redhat.com/browse/OSD-1: etcd leader changed`,
+	} {
+		if !strings.Contains(string(data), expected) {
+			t.Errorf("expected JUnit to contain '%s', got:\n%s", expected, data)
+		}
+	}
+
+	if strings.Count(string(data), "<properties>") != 1 {
+		t.Errorf("only the failure from a known issue should have properties:\n%s", data)
+	}
+}
@@ -0,0 +1,218 @@
+// Package redact masks secrets in output of the run, such as logs, artifacts, and JUnit results, before they're
+// written. Harness and installer output occasionally includes credentials which would otherwise be published.
+package redact
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// Redacted replaces secrets in output.
+	Redacted = "REDACTED"
+
+	// minSecretLen is the shortest secret value masked. Shorter values would mask unrelated output.
+	minSecretLen = 6
+)
+
+// wellKnown are patterns of secrets masked regardless of configuration. When a pattern has a group, only what
+// follows it is masked so the kind of secret is still visible.
+var wellKnown = []*regexp.Regexp{
+	// AWS access key IDs and secret access keys
+	regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`),
+	regexp.MustCompile(`(?i)(aws_secret_access_key["']?[ \t]*[=:][ \t]*["']?)[A-Za-z0-9/+=]{40}`),
+
+	// bearer tokens in headers, OpenShift tokens, and JWTs such as OCM offline tokens
+	regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9._~+/-]+=*`),
+	regexp.MustCompile(`sha256~[A-Za-z0-9_-]{43}`),
+	regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`),
+
+	// Slack tokens and webhooks
+	regexp.MustCompile(`xox[abposr]-[A-Za-z0-9-]+`),
+	regexp.MustCompile(`(https://hooks\.slack\.com/services/)[A-Za-z0-9/]+`),
+
+	// PEM private keys, such as those of service accounts
+	regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`),
+
+	// values of passwords, secrets, and tokens in key/value pairs
+	regexp.MustCompile(`(?i)((?:password|passwd|secret|token)["']?[ \t]*[=:][ \t]*["']?)[^\s"',;]+`),
+}
+
+// Current masks secrets of the run in progress. Until it's configured only well-known secrets are masked.
+var Current = new(Redactor)
+
+// Redactor masks secret values and patterns.
+type Redactor struct {
+	values   []string
+	patterns []*regexp.Regexp
+}
+
+// New returns a Redactor masking values and the regular expressions patterns along with well-known secrets.
+func New(values, patterns []string) (*Redactor, error) {
+	r := new(Redactor)
+	for _, v := range values {
+		if len(v) >= minSecretLen {
+			r.values = append(r.values, v)
+		}
+	}
+	// longer values first so values containing others are fully masked
+	sort.Slice(r.values, func(i, j int) bool {
+		return len(r.values[i]) > len(r.values[j])
+	})
+
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern '%s': %v", p, err)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+	return r, nil
+}
+
+// String returns s with secrets masked.
+func (r *Redactor) String(s string) string {
+	for _, v := range r.values {
+		s = strings.Replace(s, v, Redacted, -1)
+	}
+	for _, re := range r.patterns {
+		s = re.ReplaceAllString(s, Redacted)
+	}
+	for _, re := range wellKnown {
+		if re.NumSubexp() == 0 {
+			s = re.ReplaceAllString(s, Redacted)
+		} else {
+			s = re.ReplaceAllString(s, "${1}"+Redacted)
+		}
+	}
+	return s
+}
+
+// Bytes returns data with secrets masked.
+func (r *Redactor) Bytes(data []byte) []byte {
+	return []byte(r.String(string(data)))
+}
+
+// Writer returns a writer masking secrets before writing to w. Each write is masked on its own, so secrets split
+// across writes aren't masked; the log package writes whole lines at once.
+func (r *Redactor) Writer(w io.Writer) io.Writer {
+	return &writer{r: r, w: w}
+}
+
+type writer struct {
+	r *Redactor
+	w io.Writer
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	if _, err := w.w.Write(w.r.Bytes(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// File masks secrets in file, rewriting it if any were found.
+func (r *Redactor) File(file string) error {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("couldn't read '%s' to redact it: %v", file, err)
+	}
+
+	redacted := r.String(string(data))
+	if redacted == string(data) {
+		return nil
+	}
+	if err = ioutil.WriteFile(file, []byte(redacted), os.ModePerm); err != nil {
+		return fmt.Errorf("couldn't write redacted '%s': %v", file, err)
+	}
+	return nil
+}
+
+// junitText are the elements of JUnit results whose text may include secrets.
+var junitText = map[string]bool{
+	"failure":    true,
+	"error":      true,
+	"skipped":    true,
+	"system-out": true,
+	"system-err": true,
+}
+
+// JUnitDir masks secrets in the JUnit results in dir, such as failure messages and output.
+func (r *Redactor) JUnitDir(dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "junit*.xml"))
+	if err != nil {
+		return fmt.Errorf("couldn't find JUnit files in '%s': %v", dir, err)
+	}
+	for _, file := range files {
+		if err = r.JUnitFile(file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// JUnitFile masks secrets in the JUnit results in file. The XML is decoded so secrets are masked in the unescaped
+// text of failures and output, rather than in its escaped form, and re-encoded so the result is still valid.
+func (r *Redactor) JUnitFile(file string) error {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("couldn't read '%s' to redact it: %v", file, err)
+	}
+
+	var buf bytes.Buffer
+	dec, enc := xml.NewDecoder(bytes.NewReader(data)), xml.NewEncoder(&buf)
+	var open []string
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return fmt.Errorf("couldn't decode JUnit '%s' to redact it: %v", file, err)
+		}
+
+		switch t := xml.CopyToken(tok).(type) {
+		case xml.StartElement:
+			open = append(open, t.Name.Local)
+			if junitText[t.Name.Local] {
+				for i, attr := range t.Attr {
+					if attr.Name.Local == "message" {
+						t.Attr[i].Value = r.String(attr.Value)
+					}
+				}
+			}
+			tok = t
+		case xml.EndElement:
+			open = open[:len(open)-1]
+			tok = t
+		case xml.CharData:
+			if len(open) > 0 && junitText[open[len(open)-1]] {
+				t = xml.CharData(r.String(string(t)))
+			}
+			tok = t
+		default:
+			tok = t
+		}
+		if err = enc.EncodeToken(tok); err != nil {
+			return fmt.Errorf("couldn't encode redacted JUnit '%s': %v", file, err)
+		}
+	}
+	if err = enc.Flush(); err != nil {
+		return fmt.Errorf("couldn't encode redacted JUnit '%s': %v", file, err)
+	}
+
+	if bytes.Equal(buf.Bytes(), data) {
+		return nil
+	}
+	if err = ioutil.WriteFile(file, buf.Bytes(), os.ModePerm); err != nil {
+		return fmt.Errorf("couldn't write redacted '%s': %v", file, err)
+	}
+	return nil
+}
@@ -0,0 +1,228 @@
+// Package regions rotates the cloud regions clusters are created in and skips regions which recently failed to install
+// a cluster because the cloud was out of capacity, so runs move away from regions during cloud incidents instead of
+// failing the same way repeatedly.
+//
+// What's remembered between runs, the region each cloud provider used last and the regions which are blocked, is
+// stored in a file every run shares, such as one on a shared volume. It's locked while runs update it.
+package regions
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// lockSuffix names the file held while the state is updated.
+	lockSuffix = ".lock"
+
+	// lockTimeout is how long to wait for other runs to finish updating the state.
+	lockTimeout = time.Minute
+
+	// lockRetry is how often a held lock is checked.
+	lockRetry = 100 * time.Millisecond
+
+	// staleLock is how old locks are when they're assumed to have been left behind by a run which died holding them.
+	staleLock = 30 * time.Second
+)
+
+// Block is why a region is skipped and until when.
+type Block struct {
+	Until  time.Time `json:"until"`
+	Reason string    `json:"reason"`
+}
+
+// State is what's remembered between runs.
+type State struct {
+	// Last is the region each cloud provider used last.
+	Last map[string]string `json:"last"`
+
+	// Blocked are the regions skipped, by cloud provider and region such as 'aws/us-east-1'.
+	Blocked map[string]Block `json:"blocked"`
+}
+
+// Parse returns the regions rotated through by each cloud provider from a map of cloud provider to regions separated by
+// '|', such as 'aws=us-east-1|us-west-2'.
+func Parse(rotation map[string]string) (map[string][]string, error) {
+	regions := map[string][]string{}
+	for cloud, list := range rotation {
+		for _, region := range strings.Split(list, "|") {
+			if region = strings.TrimSpace(region); region != "" {
+				regions[cloud] = append(regions[cloud], region)
+			}
+		}
+		if len(regions[cloud]) == 0 {
+			return nil, fmt.Errorf("no regions to rotate through for cloud provider '%s'", cloud)
+		}
+	}
+	return regions, nil
+}
+
+// Key returns how region of cloud is referred to in the blocklist.
+func Key(cloud, region string) string {
+	return cloud + "/" + region
+}
+
+// Choose returns the first region of cloud after the one used last which isn't blocked at now, recording it as used.
+// If every region is blocked, the region unblocked soonest is returned with an error describing why each is blocked.
+func (s *State) Choose(cloud string, regions []string, now time.Time) (string, error) {
+	if len(regions) == 0 {
+		return "", fmt.Errorf("no regions to rotate through for cloud provider '%s'", cloud)
+	}
+	s.init()
+	s.expire(now)
+
+	start := 0
+	for i, region := range regions {
+		if region == s.Last[cloud] {
+			start = i + 1
+			break
+		}
+	}
+
+	var chosen string
+	var blocked []string
+	for i := range regions {
+		region := regions[(start+i)%len(regions)]
+		block, ok := s.Blocked[Key(cloud, region)]
+		if !ok {
+			chosen = region
+			break
+		}
+		blocked = append(blocked, fmt.Sprintf("%s until %s: %s", region, block.Until.UTC().Format(time.RFC3339), block.Reason))
+		if chosen == "" || block.Until.Before(s.Blocked[Key(cloud, chosen)].Until) {
+			chosen = region
+		}
+	}
+	s.Last[cloud] = chosen
+
+	if len(blocked) == len(regions) {
+		return chosen, fmt.Errorf("every region of cloud provider '%s' is blocked (%s)", cloud, strings.Join(blocked, "; "))
+	}
+	return chosen, nil
+}
+
+// Block skips region of cloud for d from now because of reason.
+func (s *State) Block(cloud, region, reason string, now time.Time, d time.Duration) {
+	s.init()
+	s.Blocked[Key(cloud, region)] = Block{Until: now.Add(d).UTC(), Reason: reason}
+}
+
+// BlockedAt returns the regions which are blocked at now in order.
+func (s *State) BlockedAt(now time.Time) []string {
+	var keys []string
+	for key, block := range s.Blocked {
+		if now.Before(block.Until) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// init creates the maps of s which are missing.
+func (s *State) init() {
+	if s.Last == nil {
+		s.Last = map[string]string{}
+	}
+	if s.Blocked == nil {
+		s.Blocked = map[string]Block{}
+	}
+}
+
+// expire removes blocks which ended by now.
+func (s *State) expire(now time.Time) {
+	for key, block := range s.Blocked {
+		if !now.Before(block.Until) {
+			delete(s.Blocked, key)
+		}
+	}
+}
+
+// Update reads the state stored in file, changes it with fn, and writes it back, locking the file so runs updating it
+// at the same time don't lose each other's changes.
+func Update(file string, fn func(*State)) error {
+	unlock, err := lock(file)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	s := Read(file)
+	fn(s)
+	return s.Write(file)
+}
+
+// lock creates the lock file of file, waiting for runs holding it. Locks left behind longer than staleLock are
+// taken over. The returned func removes the lock.
+func lock(file string) (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(file), os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	name := file + lockSuffix
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		f, err := os.OpenFile(name, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(name) }, nil
+		} else if !os.IsExist(err) {
+			return nil, fmt.Errorf("couldn't lock region blocklist '%s': %v", file, err)
+		}
+
+		if info, err := os.Stat(name); err == nil && time.Since(info.ModTime()) > staleLock {
+			log.Printf("Removing stale lock of region blocklist '%s' from %s", file, info.ModTime().Format(time.RFC3339))
+			os.Remove(name)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out after %v waiting for the lock of region blocklist '%s'", lockTimeout, file)
+		}
+		time.Sleep(lockRetry)
+	}
+}
+
+// Read returns the state stored in file, which is empty if the file is missing or can't be parsed.
+func Read(file string) *State {
+	s := &State{}
+	if data, err := ioutil.ReadFile(file); err == nil {
+		if err = json.Unmarshal(data, s); err != nil {
+			log.Printf("Ignoring invalid region blocklist '%s': %v", file, err)
+			s = &State{}
+		}
+	}
+	s.init()
+	return s
+}
+
+// Write replaces the state stored in file, renaming a temporary file over it so it's never partially written.
+func (s *State) Write(file string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(file), os.ModePerm); err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(file), filepath.Base(file))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), file)
+}
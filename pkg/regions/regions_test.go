@@ -0,0 +1,143 @@
+package regions
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	regions, err := Parse(map[string]string{"aws": "us-east-1| us-west-2", "gcp": "us-east1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string][]string{"aws": {"us-east-1", "us-west-2"}, "gcp": {"us-east1"}}
+	if !reflect.DeepEqual(regions, expected) {
+		t.Errorf("expected regions %v, got %v", expected, regions)
+	}
+
+	if _, err = Parse(map[string]string{"aws": "|"}); err == nil {
+		t.Error("expected a cloud provider without regions to be invalid")
+	}
+}
+
+func TestChoose(t *testing.T) {
+	now := time.Date(2019, 8, 1, 0, 0, 0, 0, time.UTC)
+	regions := []string{"us-east-1", "us-west-2", "eu-west-1"}
+	s := &State{}
+
+	var chosen []string
+	for i := 0; i < 4; i++ {
+		region, err := s.Choose("aws", regions, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		chosen = append(chosen, region)
+	}
+	if expected := []string{"us-east-1", "us-west-2", "eu-west-1", "us-east-1"}; !reflect.DeepEqual(chosen, expected) {
+		t.Errorf("expected regions to be rotated through as %v, got %v", expected, chosen)
+	}
+
+	s.Block("aws", "us-west-2", "capacity", now, 6*time.Hour)
+	if region, err := s.Choose("aws", regions, now.Add(time.Hour)); err != nil || region != "eu-west-1" {
+		t.Errorf("expected blocked region to be skipped, got %s: %v", region, err)
+	}
+	if region, _ := s.Choose("gcp", []string{"us-east1"}, now); region != "us-east1" || s.Last["aws"] != "eu-west-1" {
+		t.Errorf("expected cloud providers to be rotated separately, got %s and %v", region, s.Last)
+	}
+
+	s.Block("aws", "us-east-1", "capacity", now, 2*time.Hour)
+	s.Block("aws", "eu-west-1", "capacity", now, 4*time.Hour)
+	if blocked := s.BlockedAt(now.Add(time.Hour)); len(blocked) != 3 || blocked[0] != "aws/eu-west-1" {
+		t.Errorf("expected 3 regions blocked in order, got %v", blocked)
+	}
+	if region, err := s.Choose("aws", regions, now.Add(time.Hour)); err == nil || region != "us-east-1" {
+		t.Errorf("expected the region unblocked soonest with an error when all are blocked, got %s: %v", region, err)
+	}
+	if region, err := s.Choose("aws", regions, now.Add(3*time.Hour)); err != nil || region != "us-east-1" {
+		t.Errorf("expected region to be used again once its block ended, got %s: %v", region, err)
+	}
+	if _, ok := s.Blocked[Key("aws", "us-east-1")]; ok {
+		t.Error("expected ended blocks to be removed")
+	}
+}
+
+func TestReadWrite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "regions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "osde2e", "regions.json")
+
+	if s := Read(file); len(s.Last) != 0 || s.Blocked == nil {
+		t.Errorf("expected missing state to be empty, got %+v", s)
+	}
+
+	now := time.Date(2019, 8, 1, 0, 0, 0, 0, time.UTC)
+	s := &State{}
+	s.Choose("aws", []string{"us-east-1"}, now)
+	s.Block("aws", "us-west-2", "InsufficientInstanceCapacity", now, time.Hour)
+	if err = s.Write(file); err != nil {
+		t.Fatalf("failed writing state: %v", err)
+	}
+	if read := Read(file); !reflect.DeepEqual(read, s) {
+		t.Errorf("expected state %+v to be read back, got %+v", s, read)
+	}
+
+	if err = ioutil.WriteFile(file, []byte("{"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if s = Read(file); len(s.Blocked) != 0 {
+		t.Errorf("expected invalid state to be ignored, got %+v", s)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "regions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "osde2e", "regions.json")
+
+	// runs updating at once all keep their changes
+	now := time.Date(2019, 8, 1, 0, 0, 0, 0, time.UTC)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			err := Update(file, func(s *State) {
+				s.Block("aws", fmt.Sprintf("region-%d", i), "capacity", now, time.Hour)
+			})
+			if err != nil {
+				t.Errorf("failed updating state: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if blocked := Read(file).BlockedAt(now); len(blocked) != 10 {
+		t.Errorf("expected every update to be kept, got %v", blocked)
+	}
+	if _, err = os.Stat(file + lockSuffix); !os.IsNotExist(err) {
+		t.Errorf("expected lock to be removed, got %v", err)
+	}
+
+	// locks left behind by runs which died are taken over
+	if err = ioutil.WriteFile(file+lockSuffix, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	stale := time.Now().Add(-2 * staleLock)
+	if err = os.Chtimes(file+lockSuffix, stale, stale); err != nil {
+		t.Fatal(err)
+	}
+	if err = Update(file, func(s *State) { s.Block("aws", "stale", "capacity", now, time.Hour) }); err != nil {
+		t.Errorf("expected stale lock to be taken over, got %v", err)
+	}
+}
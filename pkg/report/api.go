@@ -0,0 +1,155 @@
+package report
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// APIPrefix is the path all API endpoints are served under.
+	APIPrefix = "/api/v1/"
+)
+
+// APIServer makes the latest job results available as JSON under APIPrefix, serving lists of jobs, their runs,
+// individual runs, a weather summary of each job which may be grouped by run metadata, the most skipped tests, and how
+// each group of suites did.
+type APIServer struct {
+	mu      sync.RWMutex
+	results []JobResults
+	updated time.Time
+}
+
+// Set replaces the results being served.
+func (s *APIServer) Set(results []JobResults) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.results = results
+	s.updated = time.Now().UTC()
+}
+
+// jobSummary lists a job in the API.
+type jobSummary struct {
+	Env  string `json:"env"`
+	Name string `json:"name"`
+	Runs int    `json:"runs"`
+}
+
+// ServeHTTP routes requests to API endpoints.
+func (s *APIServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if req.Method != http.MethodGet {
+		http.Error(w, "only GET is supported", http.StatusMethodNotAllowed)
+		return
+	} else if s.updated.IsZero() {
+		http.Error(w, "results have not been retrieved yet", http.StatusServiceUnavailable)
+		return
+	}
+
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(req.URL.Path, APIPrefix), "/"), "/")
+	switch {
+	case len(parts) == 1 && parts[0] == "jobs":
+		jobs := make([]jobSummary, len(s.results))
+		for i, j := range s.results {
+			jobs[i] = jobSummary{Env: j.Env, Name: j.Name, Runs: len(j.Runs)}
+		}
+		s.write(w, req, jobs)
+	case len(parts) == 1 && parts[0] == "weather":
+		// optionally break down each job by metadata, such as 'region' or 'az-layout'
+		by := req.URL.Query().Get("by")
+		weather := make([]Weather, 0, len(s.results))
+		for _, j := range s.results {
+			if by == "" {
+				weather = append(weather, j.Weather())
+			} else {
+				weather = append(weather, j.WeatherBy(by)...)
+			}
+		}
+
+		// leave out jobs with too few runs to judge if requested, listing the worst first
+		if minRunsStr := req.URL.Query().Get("minRuns"); minRunsStr != "" {
+			minRuns, err := strconv.Atoi(minRunsStr)
+			if err != nil || minRuns < 0 {
+				http.Error(w, "minRuns must not be negative", http.StatusBadRequest)
+				return
+			}
+			weather, _ = FilterWeather(weather, minRuns)
+		}
+		SortWeather(weather)
+		s.write(w, req, weather)
+	case len(parts) == 1 && parts[0] == "skips":
+		// optionally only count skips for a reason, such as 'capability-missing'
+		skipped := SkipWeather(s.results, req.URL.Query().Get("reason"))
+		if limitStr := req.URL.Query().Get("limit"); limitStr != "" {
+			limit, err := strconv.Atoi(limitStr)
+			if err != nil || limit < 0 {
+				http.Error(w, "limit must not be negative", http.StatusBadRequest)
+				return
+			} else if limit < len(skipped) {
+				skipped = skipped[:limit]
+			}
+		}
+		s.write(w, req, skipped)
+	case len(parts) == 1 && parts[0] == "groups":
+		s.write(w, req, GroupWeather(s.results))
+	case len(parts) == 3 && parts[0] == "jobs" && parts[2] == "runs":
+		job, ok := s.job(parts[1])
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+
+		runs := job.Runs
+		if limitStr := req.URL.Query().Get("limit"); limitStr != "" {
+			limit, err := strconv.Atoi(limitStr)
+			if err != nil || limit < 0 {
+				http.Error(w, "limit must not be negative", http.StatusBadRequest)
+				return
+			} else if limit < len(runs) {
+				runs = runs[:limit]
+			}
+		}
+		s.write(w, req, runs)
+	case len(parts) == 4 && parts[0] == "jobs" && parts[2] == "runs":
+		job, ok := s.job(parts[1])
+		buildNum, err := strconv.Atoi(parts[3])
+		if !ok || err != nil {
+			http.NotFound(w, req)
+			return
+		}
+
+		for _, run := range job.Runs {
+			if run.BuildNum == buildNum {
+				s.write(w, req, run)
+				return
+			}
+		}
+		http.NotFound(w, req)
+	default:
+		http.NotFound(w, req)
+	}
+}
+
+// job returns the results of the job called name.
+func (s *APIServer) job(name string) (JobResults, bool) {
+	for _, j := range s.results {
+		if j.Name == name {
+			return j, true
+		}
+	}
+	return JobResults{}, false
+}
+
+func (s *APIServer) write(w http.ResponseWriter, req *http.Request, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Last-Modified", s.updated.Format(http.TimeFormat))
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Failed writing results to %s: %v", req.RemoteAddr, err)
+	}
+}
@@ -0,0 +1,124 @@
+package report
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	testgrid "k8s.io/test-infra/testgrid/metadata"
+)
+
+func TestServeAPI(t *testing.T) {
+	srv := new(APIServer)
+	httpSrv := httptest.NewServer(srv)
+	defer httpSrv.Close()
+
+	// nothing should be served before results are set
+	if resp, err := http.Get(httpSrv.URL + "/api/v1/jobs"); err != nil {
+		t.Fatalf("Failed requesting jobs: %v", err)
+	} else if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected status %d before results were set, got %d", http.StatusServiceUnavailable, resp.StatusCode)
+	}
+
+	now := time.Now().UTC()
+	srv.Set([]JobResults{
+		{
+			Env:  "int",
+			Name: "osd-int-4.1",
+			Runs: []RunResult{
+				{BuildNum: 14, Started: now},
+				{BuildNum: 13, Started: now.Add(-time.Hour), Finished: &now, Result: "FAILURE", FailedTests: []string{"BeforeSuite"},
+					Metadata: testgrid.Metadata{"region": "us-east-1"}, SkippedTests: map[string]string{"Load should be fast": "config-excluded"},
+					Groups: map[string]GroupResult{"networking": {Tests: 2, Failed: 1, Seconds: 60}}},
+				{BuildNum: 12, Started: now.Add(-2 * time.Hour), Finished: &now, Passed: true, Result: "SUCCESS",
+					Metadata: testgrid.Metadata{"region": "eu-west-1"}},
+			},
+		},
+	})
+
+	var weather []Weather
+	getJSON(t, httpSrv.URL+"/api/v1/weather", http.StatusOK, &weather)
+	expected := withInterval(Weather{Env: "int", Name: "osd-int-4.1", Runs: 2, Passed: 1, PassRate: 0.5, LastResult: "FAILURE",
+		Flakiness: 1})
+	if len(weather) != 1 || weather[0] != expected {
+		t.Errorf("expected weather %v, got %v", expected, weather)
+	}
+
+	getJSON(t, httpSrv.URL+"/api/v1/weather?by=region", http.StatusOK, &weather)
+	expectedByRegion := []Weather{
+		withInterval(Weather{Env: "int", Name: "osd-int-4.1", Group: "us-east-1", Runs: 1, PassRate: 0, LastResult: "FAILURE"}),
+		withInterval(Weather{Env: "int", Name: "osd-int-4.1", Group: "eu-west-1", Runs: 1, Passed: 1, PassRate: 1, LastResult: "SUCCESS"}),
+	}
+	if !reflect.DeepEqual(weather, expectedByRegion) {
+		t.Errorf("expected weather by region worst first %v, got %v", expectedByRegion, weather)
+	}
+
+	getJSON(t, httpSrv.URL+"/api/v1/weather?minRuns=3", http.StatusOK, &weather)
+	if len(weather) != 0 {
+		t.Errorf("expected jobs with fewer than 3 runs to be left out, got %v", weather)
+	}
+
+	var runs []RunResult
+	getJSON(t, httpSrv.URL+"/api/v1/jobs/osd-int-4.1/runs?limit=2", http.StatusOK, &runs)
+	if len(runs) != 2 || runs[0].BuildNum != 14 {
+		t.Errorf("expected 2 most recent runs, got %v", runs)
+	}
+
+	var run RunResult
+	getJSON(t, httpSrv.URL+"/api/v1/jobs/osd-int-4.1/runs/13", http.StatusOK, &run)
+	if len(run.FailedTests) != 1 || run.FailedTests[0] != "BeforeSuite" {
+		t.Errorf("expected failed tests of build 13, got %v", run)
+	}
+
+	var skipped []SkippedTest
+	getJSON(t, httpSrv.URL+"/api/v1/skips?reason=config-excluded", http.StatusOK, &skipped)
+	if len(skipped) != 1 || skipped[0].Name != "Load should be fast" || skipped[0].Skips != 1 {
+		t.Errorf("expected skipped test of build 13, got %v", skipped)
+	}
+	getJSON(t, httpSrv.URL+"/api/v1/skips?reason=quarantined", http.StatusOK, &skipped)
+	if len(skipped) != 0 {
+		t.Errorf("expected no quarantined tests, got %v", skipped)
+	}
+
+	var groupWeather []GroupSummary
+	getJSON(t, httpSrv.URL+"/api/v1/groups", http.StatusOK, &groupWeather)
+	if len(groupWeather) != 1 || groupWeather[0].Group != "networking" || groupWeather[0].Failed != 1 {
+		t.Errorf("expected groups of build 13, got %v", groupWeather)
+	}
+
+	for _, path := range []string{
+		"/api/v1/jobs/osd-prod-4.1/runs",
+		"/api/v1/jobs/osd-int-4.1/runs/11",
+		"/api/v1/unknown",
+	} {
+		getJSON(t, httpSrv.URL+path, http.StatusNotFound, nil)
+	}
+}
+
+func getJSON(t *testing.T, url string, status int, v interface{}) {
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("Failed requesting '%s': %v", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != status {
+		t.Fatalf("expected status %d for '%s', got %d", status, url, resp.StatusCode)
+	} else if v == nil {
+		return
+	}
+
+	if err = json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatalf("Failed decoding '%s': %v", url, err)
+	}
+}
+
+// withInterval sets the confidence interval and badness expected of w.
+func withInterval(w Weather) Weather {
+	w.PassRateLower, w.PassRateUpper = wilson(w.Passed, w.Runs)
+	w.Badness = 1 - w.PassRateUpper
+	return w
+}
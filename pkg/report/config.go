@@ -1,16 +1,57 @@
 package report
 
+import "time"
+
 // DefaultConfig has initial values which are reasonable for most failure reports.
 var DefaultConfig = &Config{
-	Tests:      DefaultTests,
-	DateLayout: DefaultDateLayout,
+	Envs:              DefaultEnvs,
+	Jobs:              DefaultJobs,
+	Tests:             DefaultTests,
+	DateLayout:        DefaultDateLayout,
+	QuarantineWarning: DefaultQuarantineWarning,
 }
 
 const (
 	// DefaultDateLayout is the time layout used to print dates.
 	DefaultDateLayout = "January 2, 2006"
+
+	// DefaultQuarantineWarning is how long before expiring quarantine entries are warned about.
+	DefaultQuarantineWarning = 7 * 24 * time.Hour
 )
 
+// locator returns the configured Locator or the Prow Locator for bucket if one isn't set.
+func (c Config) locator(bucket string) Locator {
+	if c.Locator != nil {
+		return c.Locator
+	}
+	return ProwLocator{Bucket: bucket}
+}
+
+// DefaultEnvs are the environments osde2e runs against.
+var DefaultEnvs = []EnvConfig{
+	{
+		Name: "int",
+	},
+	{
+		Name: "stage",
+	},
+	{
+		Name: "prod",
+	},
+}
+
+// DefaultJobs are run for each environment.
+var DefaultJobs = []JobConfig{
+	{
+		Name:    "osd",
+		Version: "4.1",
+	},
+	{
+		Name:    "osd-upgrade",
+		Version: "4.1-4.1",
+	},
+}
+
 // DefaultTests are included in reports.
 var DefaultTests = []string{
 	"BeforeSuite",
@@ -30,6 +71,12 @@ type Config struct {
 
 	// DateLayout defines the format of dates within the report.
 	DateLayout string
+
+	// QuarantineWarning is how long before expiring quarantine entries are warned about.
+	QuarantineWarning time.Duration
+
+	// Locator resolves links to builds and their artifacts. Prow is used if not set.
+	Locator Locator `json:"-"`
 }
 
 // EnvConfig for environment being reported.
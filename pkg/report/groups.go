@@ -0,0 +1,148 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/test-infra/testgrid/metadata/junit"
+
+	"github.com/openshift/osde2e/pkg/groups"
+	"github.com/openshift/osde2e/pkg/skips"
+)
+
+// GroupResult is how a group of suites, such as 'storage', did in a run.
+type GroupResult struct {
+	Tests   int `json:"tests"`
+	Failed  int `json:"failed"`
+	Skipped int `json:"skipped"`
+
+	// OverBudget tests were skipped because the group spent its time budget.
+	OverBudget int `json:"overBudget"`
+
+	// Seconds is how long the group's tests ran.
+	Seconds float64 `json:"seconds"`
+}
+
+// addGroupResult counts result in its group. Results without a group, such as those of runs from before tests were
+// grouped, aren't counted.
+func (r *RunResult) addGroupResult(result junit.Result) {
+	name := property(result, groups.PropertyGroup)
+	if name == "" {
+		return
+	}
+	if r.Groups == nil {
+		r.Groups = map[string]GroupResult{}
+	}
+
+	g := r.Groups[name]
+	g.Tests++
+	g.Seconds += result.Time
+	if result.Failure != nil {
+		g.Failed++
+	} else if reason, ok := skipReason(result); ok && reason == string(skips.OverBudget) {
+		g.OverBudget++
+	} else if ok {
+		g.Skipped++
+	}
+	r.Groups[name] = g
+}
+
+// GroupSummary summarizes how a group of suites did in the finished runs of jobs.
+type GroupSummary struct {
+	Group string `json:"group"`
+
+	// Runs are the runs which had tests of the group.
+	Runs int `json:"runs"`
+
+	Tests      int `json:"tests"`
+	Failed     int `json:"failed"`
+	Skipped    int `json:"skipped"`
+	OverBudget int `json:"overBudget"`
+
+	// OverBudgetRuns are the runs in which the group spent its time budget before all its tests ran.
+	OverBudgetRuns int `json:"overBudgetRuns"`
+
+	// FailRate is the ratio of tests of the group which ran that failed.
+	FailRate float64 `json:"failRate"`
+
+	// MeanDuration is how long the group ran on average.
+	MeanDuration time.Duration `json:"meanDuration"`
+}
+
+// GroupWeather returns how each group of suites did in finished runs of jobs, in the order groups are reported.
+func GroupWeather(results []JobResults) []GroupSummary {
+	summaries := map[string]*GroupSummary{}
+	seconds := map[string]float64{}
+	for _, j := range results {
+		for _, r := range j.Runs {
+			if r.Finished == nil {
+				continue
+			}
+
+			for name, g := range r.Groups {
+				s, ok := summaries[name]
+				if !ok {
+					s = &GroupSummary{Group: name}
+					summaries[name] = s
+				}
+				s.Runs++
+				s.Tests += g.Tests
+				s.Failed += g.Failed
+				s.Skipped += g.Skipped
+				s.OverBudget += g.OverBudget
+				if g.OverBudget > 0 {
+					s.OverBudgetRuns++
+				}
+				seconds[name] += g.Seconds
+			}
+		}
+	}
+
+	weather := make([]GroupSummary, 0, len(summaries))
+	for name, s := range summaries {
+		if ran := s.Tests - s.Skipped - s.OverBudget; ran > 0 {
+			s.FailRate = float64(s.Failed) / float64(ran)
+		}
+		s.MeanDuration = time.Duration(seconds[name] / float64(s.Runs) * float64(time.Second)).Round(time.Second)
+		weather = append(weather, *s)
+	}
+	sort.Slice(weather, func(i, j int) bool {
+		oi, oj := groupOrder(weather[i].Group), groupOrder(weather[j].Group)
+		if oi != oj {
+			return oi < oj
+		}
+		return weather[i].Group < weather[j].Group
+	})
+	return weather
+}
+
+// groupOrder returns the position of group in the order groups are reported, with unknown groups last.
+func groupOrder(group string) int {
+	for i, g := range groups.Groups {
+		if string(g) == group {
+			return i
+		}
+	}
+	return len(groups.Groups)
+}
+
+// SlackGroups formats how each group of suites did as a Slack message.
+func SlackGroups(weather []GroupSummary) string {
+	if len(weather) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("*Test groups*\n")
+	for _, g := range weather {
+		fmt.Fprintf(&b, "- %s: %.1f%% of %d tests failed in %d runs, taking %v on average", g.Group, g.FailRate*100,
+			g.Tests, g.Runs, g.MeanDuration)
+		if g.OverBudgetRuns > 0 {
+			fmt.Fprintf(&b, ", over budget in %d runs", g.OverBudgetRuns)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,80 @@
+package report
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/test-infra/testgrid/metadata/junit"
+)
+
+func TestAddGroupResult(t *testing.T) {
+	group := func(name string) *junit.Properties {
+		return &junit.Properties{PropertyList: []junit.Property{{Name: "group", Value: name}}}
+	}
+	failure, skipped := "timed out", ""
+	overBudget := &junit.Properties{PropertyList: []junit.Property{
+		{Name: "group", Value: "storage"},
+		{Name: "skip-reason", Value: "over-budget"},
+	}}
+
+	var run RunResult
+	for _, result := range []junit.Result{
+		{Name: "Storage should provision", Time: 30, Properties: group("storage")},
+		{Name: "Storage should expand", Time: 90, Failure: &failure, Properties: group("storage")},
+		{Name: "Storage should snapshot", Skipped: &skipped, Properties: overBudget},
+		{Name: "Storage should clone", Skipped: &skipped, Properties: group("storage")},
+		{Name: "OCM configuration should be unchanged"},
+	} {
+		run.addGroupResult(result)
+	}
+
+	expected := map[string]GroupResult{"storage": {Tests: 4, Failed: 1, Skipped: 1, OverBudget: 1, Seconds: 120}}
+	if !reflect.DeepEqual(run.Groups, expected) {
+		t.Errorf("expected groups %v, got %v", expected, run.Groups)
+	}
+}
+
+func TestGroupWeather(t *testing.T) {
+	now := time.Now().UTC()
+	results := []JobResults{
+		{
+			Name: "osd-int-4.1",
+			Runs: []RunResult{
+				// runs in progress aren't counted
+				{BuildNum: 3, Groups: map[string]GroupResult{"storage": {Tests: 4, Failed: 4}}},
+				{BuildNum: 2, Finished: &now, Groups: map[string]GroupResult{
+					"storage":    {Tests: 4, Failed: 1, OverBudget: 1, Seconds: 600},
+					"networking": {Tests: 2, Seconds: 60},
+				}},
+				{BuildNum: 1, Finished: &now, Groups: map[string]GroupResult{"storage": {Tests: 4, Skipped: 2, Seconds: 300}}},
+			},
+		},
+		{
+			Name: "osd-stage-4.1",
+			Runs: []RunResult{
+				{BuildNum: 1, Finished: &now, Groups: map[string]GroupResult{"sig-custom": {Tests: 1, Seconds: 10}}},
+			},
+		},
+	}
+
+	expected := []GroupSummary{
+		{Group: "networking", Runs: 1, Tests: 2, MeanDuration: time.Minute},
+		{Group: "storage", Runs: 2, Tests: 8, Failed: 1, Skipped: 2, OverBudget: 1, OverBudgetRuns: 1, FailRate: 0.2,
+			MeanDuration: 450 * time.Second},
+		{Group: "sig-custom", Runs: 1, Tests: 1, MeanDuration: 10 * time.Second},
+	}
+	weather := GroupWeather(results)
+	if !reflect.DeepEqual(weather, expected) {
+		t.Errorf("expected group weather %+v, got %+v", expected, weather)
+	}
+
+	msg := SlackGroups(weather)
+	if !strings.Contains(msg, "- storage: 20.0% of 8 tests failed in 2 runs, taking 7m30s on average, over budget in 1 runs") {
+		t.Errorf("expected storage group to be summarized, got:\n%s", msg)
+	}
+	if SlackGroups(nil) != "" {
+		t.Error("expected no message without groups")
+	}
+}
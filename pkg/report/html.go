@@ -0,0 +1,98 @@
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+)
+
+const (
+	htmlTmplText = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<h2>Updated {{date .Range.Start .Config.DateLayout}}</h2>
+{{- if .Quarantine}}
+<h3>Expiring quarantines</h3>
+<ul>
+	{{- range $qk, $q := .Quarantine}}
+<li><strong>{{$q.Test}}</strong> ({{$q.Issue}}) expires {{$q.Expires}}</li>
+	{{- end}}
+</ul>
+{{- end}}
+{{- if .KnownIssues}}
+<h3>Known issues</h3>
+<ul>
+	{{- range $kk, $k := .KnownIssues}}
+<li><strong>{{$k.Issue}}</strong> (<code>{{$k.Failure}}</code>) caused {{$k.Count}} failures
+		{{- if eq $k.Count 0}}, consider removing it{{end}}</li>
+	{{- end}}
+</ul>
+{{- end}}
+{{- with .Installs}}
+<h3>Cluster installs</h3>
+<ul>
+	{{- range $ik, $i := .}}
+<li><strong>{{$i.Version}}</strong> on {{$i.Provider}}: {{$i.Passed}}/{{$i.Installs}} installed, likely {{percent $i.PassRateLower}}-{{percent $i.PassRateUpper}}
+		{{- if $i.Passed}}, taking {{$i.MedianDuration}} (p90 {{$i.P90Duration}}){{end}}</li>
+	{{- end}}
+</ul>
+{{- end}}
+{{- range $ek, $e := .Envs}}
+<h3>{{$e.Name}}</h3>
+<ul>
+	{{- range $jk, $j := $e.Jobs}}
+<li><a href="https://testgrid.k8s.io/redhat-osd-{{$e.Name}}#{{$j.Name}}">{{$j.Name}}</a>
+<ul>
+		{{- range $rn, $r := $j.Runs}}
+<li><a href="{{buildURL $j $r}}">#{{$r.BuildNum}}</a>
+<ul>
+			{{- range $k, $v := $r.Finished.Metadata}}
+				{{- if eq $k "cluster-id"}}
+<li>Cluster ID: {{$v}}</li>
+				{{- end}}
+			{{- end}}
+<li>Hive logs: {{hiveLogs $r}}</li>
+<li><strong>Failures</strong>:
+<ul>
+			{{- range $fn, $f := $r.Failures}}
+<li>Test Name: {{$f.Name}}
+				{{- if $f.KnownIssue}} <strong>known issue</strong>: <a href="{{$f.KnownIssue}}">{{$f.KnownIssue}}</a>{{end}}<pre>{{$f.Message 0}}</pre></li>
+			{{- end}}
+</ul>
+</li>
+</ul>
+</li>
+		{{- end}}
+</ul>
+</li>
+	{{- end}}
+</ul>
+{{- end}}
+</body>
+</html>
+`
+)
+
+var (
+	htmlReportTmpl = template.Must(template.New("htmlReport").
+		Funcs(template.FuncMap{
+			"date":     printDate,
+			"percent":  percent,
+			"hiveLogs": hiveLogs,
+			"buildURL": buildURL,
+		}).Parse(htmlTmplText))
+)
+
+// HTML formatted version of the report is written to w.
+func (r *Report) HTML(w io.Writer) error {
+	err := htmlReportTmpl.Execute(w, r)
+	if err != nil {
+		return fmt.Errorf("couldn't render HTML report: %v", err)
+	}
+	return nil
+}
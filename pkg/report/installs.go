@@ -0,0 +1,140 @@
+package report
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	testgrid "k8s.io/test-infra/testgrid/metadata"
+)
+
+// Metadata recorded by runs about the cluster they installed.
+const (
+	installVersionKey  = "CLUSTER_VERSION"
+	installProviderKey = "CLOUD_PROVIDER"
+
+	// installResultKey and installSecondsKey are recorded by osd.InstallProgress for runs which installed a cluster.
+	installResultKey  = "install-result"
+	installSecondsKey = "install-seconds"
+)
+
+// InstallSummary summarizes the cluster installs of finished runs of one version in one provider, so a cluster image
+// set which starts failing to install stands out from the others.
+type InstallSummary struct {
+	Version  string `json:"version"`
+	Provider string `json:"provider"`
+
+	Installs int     `json:"installs"`
+	Passed   int     `json:"passed"`
+	PassRate float64 `json:"passRate"`
+
+	// PassRateLower and PassRateUpper bound the true install success rate with 95% confidence.
+	PassRateLower float64 `json:"passRateLower"`
+	PassRateUpper float64 `json:"passRateUpper"`
+
+	// MedianDuration and P90Duration are how long successful installs took.
+	MedianDuration time.Duration `json:"medianDuration"`
+	P90Duration    time.Duration `json:"p90Duration"`
+}
+
+// InstallWeather returns how cluster installs did in finished runs of jobs by version and provider, worst first.
+// Runs which didn't install a cluster, such as those testing an existing one, aren't counted.
+func InstallWeather(results []JobResults) []InstallSummary {
+	var keys []string
+	summaries := map[string]*InstallSummary{}
+	durations := map[string][]time.Duration{}
+	for _, j := range results {
+		for _, r := range j.Runs {
+			if r.Finished == nil {
+				continue
+			}
+			result, ok := r.Metadata.String(installResultKey)
+			if !ok || *result == "" {
+				continue
+			}
+
+			version, provider := metadataOr(r.Metadata, installVersionKey), metadataOr(r.Metadata, installProviderKey)
+			key := version + "/" + provider
+			s, ok := summaries[key]
+			if !ok {
+				s = &InstallSummary{Version: version, Provider: provider}
+				summaries[key] = s
+				keys = append(keys, key)
+			}
+
+			s.Installs++
+			if *result == "SUCCESS" {
+				s.Passed++
+				if seconds, ok := r.Metadata[installSecondsKey].(float64); ok {
+					durations[key] = append(durations[key], time.Duration(seconds*float64(time.Second)))
+				}
+			}
+		}
+	}
+
+	weather := make([]InstallSummary, 0, len(keys))
+	for _, key := range keys {
+		s := summaries[key]
+		s.PassRate = float64(s.Passed) / float64(s.Installs)
+		s.PassRateLower, s.PassRateUpper = wilson(s.Passed, s.Installs)
+		s.MedianDuration = percentile(durations[key], 0.5)
+		s.P90Duration = percentile(durations[key], 0.9)
+		weather = append(weather, *s)
+	}
+	sort.Slice(weather, func(i, j int) bool {
+		if weather[i].PassRateUpper != weather[j].PassRateUpper {
+			return weather[i].PassRateUpper < weather[j].PassRateUpper
+		} else if weather[i].Version != weather[j].Version {
+			return weather[i].Version > weather[j].Version
+		}
+		return weather[i].Provider < weather[j].Provider
+	})
+	return weather
+}
+
+// metadataOr returns the value of key in meta, or unknownGroup for runs without it.
+func metadataOr(meta testgrid.Metadata, key string) string {
+	if v, ok := meta.String(key); ok && *v != "" {
+		return *v
+	}
+	return unknownGroup
+}
+
+// percentile returns the nearest-rank percentile p of durations rounded to the second, or zero if there are none.
+func percentile(durations []time.Duration, p float64) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+
+	sorted := append([]time.Duration(nil), durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank].Round(time.Second)
+}
+
+// SlackInstalls formats how cluster installs did by version and provider as a Slack message, worst first. Versions
+// with fewer than minRuns installs are left out.
+func SlackInstalls(weather []InstallSummary, minRuns int) string {
+	var b strings.Builder
+	for _, s := range weather {
+		if s.Installs < minRuns {
+			continue
+		}
+		fmt.Fprintf(&b, "%s %s on %s: %d/%d installed, likely %.0f%%-%.0f%%", passRateIcon(s.PassRateUpper), s.Version,
+			s.Provider, s.Passed, s.Installs, 100*s.PassRateLower, 100*s.PassRateUpper)
+		if s.Passed != 0 {
+			fmt.Fprintf(&b, ", taking %v (p90 %v)", s.MedianDuration, s.P90Duration)
+		}
+		b.WriteString("\n")
+	}
+
+	if b.Len() == 0 {
+		return ""
+	}
+	return "*Cluster installs* (by version and provider, worst first)\n" + b.String()
+}
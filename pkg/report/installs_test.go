@@ -0,0 +1,72 @@
+package report
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	testgrid "k8s.io/test-infra/testgrid/metadata"
+)
+
+func TestInstallWeather(t *testing.T) {
+	now := time.Now().UTC()
+	install := func(version, provider, result string, seconds float64) RunResult {
+		meta := testgrid.Metadata{"CLUSTER_VERSION": version, "CLOUD_PROVIDER": provider, "install-result": result}
+		if seconds != 0 {
+			meta["install-seconds"] = seconds
+		}
+		return RunResult{Finished: &now, Metadata: meta}
+	}
+	results := []JobResults{
+		{
+			Name: "osd-int-4.2",
+			Runs: []RunResult{
+				// runs in progress and runs which didn't install a cluster aren't counted
+				{Metadata: testgrid.Metadata{"CLUSTER_VERSION": "openshift-v4.2.0", "install-result": "FAILURE"}},
+				{Finished: &now, Metadata: testgrid.Metadata{"CLUSTER_VERSION": "openshift-v4.2.0"}},
+				install("openshift-v4.2.0", "aws", "FAILURE", 0),
+				install("openshift-v4.2.0", "aws", "FAILURE", 0),
+				install("openshift-v4.2.0", "aws", "SUCCESS", 3600),
+			},
+		},
+		{
+			Name: "osd-stage-4.1",
+			Runs: []RunResult{
+				install("openshift-v4.1.0", "aws", "SUCCESS", 1800),
+				install("openshift-v4.1.0", "aws", "SUCCESS", 2400),
+				install("openshift-v4.1.0", "aws", "SUCCESS", 3000),
+				install("openshift-v4.1.0", "", "SUCCESS", 1200),
+			},
+		},
+	}
+
+	weather := InstallWeather(results)
+	if len(weather) != 3 {
+		t.Fatalf("expected 3 versions and providers, got %+v", weather)
+	}
+	worst := weather[0]
+	if worst.Version != "openshift-v4.2.0" || worst.Provider != "aws" || worst.Installs != 3 || worst.Passed != 1 ||
+		worst.MedianDuration != time.Hour || worst.P90Duration != time.Hour {
+		t.Errorf("expected failing installs of 4.2 first, got %+v", worst)
+	}
+	expected := InstallSummary{Version: "openshift-v4.1.0", Provider: "aws", Installs: 3, Passed: 3, PassRate: 1,
+		MedianDuration: 40 * time.Minute, P90Duration: 50 * time.Minute}
+	expected.PassRateLower, expected.PassRateUpper = wilson(3, 3)
+	if !reflect.DeepEqual(weather[1], expected) {
+		t.Errorf("expected %+v, got %+v", expected, weather[1])
+	}
+	if weather[2].Provider != unknownGroup {
+		t.Errorf("expected installs without a provider to be grouped as unknown, got %+v", weather[2])
+	}
+
+	msg := SlackInstalls(weather, 2)
+	if !strings.Contains(msg, "openshift-v4.2.0 on aws: 1/3 installed") ||
+		!strings.Contains(msg, "openshift-v4.1.0 on aws: 3/3 installed, likely 44%-100%, taking 40m0s (p90 50m0s)") ||
+		strings.Contains(msg, unknownGroup) {
+		t.Errorf("unexpected installs message:\n%s", msg)
+	}
+	if SlackInstalls(weather, 10) != "" {
+		t.Error("expected no message without enough installs")
+	}
+}
@@ -0,0 +1,110 @@
+package report
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/openshift/osde2e/pkg/testgrid"
+)
+
+const (
+	// ProwLocatorName identifies the Locator for results stored in GCS by Prow.
+	ProwLocatorName = "prow"
+
+	// JenkinsLocatorName identifies the Locator for results archived by Jenkins.
+	JenkinsLocatorName = "jenkins"
+
+	// LocalLocatorName identifies the Locator for results stored in a local directory.
+	LocalLocatorName = "local"
+
+	prowViewURL   = "https://prow.k8s.io/view/gcs"
+	gcsStorageURL = "https://storage.googleapis.com"
+)
+
+// Locator resolves where the results and artifacts of a build can be found.
+type Locator interface {
+	// BuildURL is where the results of buildNum for the job stored under prefix can be viewed.
+	BuildURL(prefix string, buildNum int) string
+
+	// ArtifactURL is where the artifact name of buildNum for the job stored under prefix can be retrieved.
+	ArtifactURL(prefix string, buildNum int, name string) string
+}
+
+// NewLocator returns the Locator with the given name. The meaning of location depends on the Locator.
+func NewLocator(name, location string) (Locator, error) {
+	switch name {
+	case "", ProwLocatorName:
+		return ProwLocator{Bucket: location}, nil
+	case JenkinsLocatorName:
+		if location == "" {
+			return nil, fmt.Errorf("a URL must be given for the '%s' locator", name)
+		}
+		return JenkinsLocator{URL: strings.TrimSuffix(location, "/")}, nil
+	case LocalLocatorName:
+		if location == "" {
+			return nil, fmt.Errorf("a directory must be given for the '%s' locator", name)
+		}
+		return LocalLocator{Dir: location}, nil
+	}
+	return nil, fmt.Errorf("unknown locator '%s'", name)
+}
+
+// ProwLocator finds results stored in a GCS bucket using the layout used by Prow.
+type ProwLocator struct {
+	// Bucket is the GCS bucket containing results.
+	Bucket string
+}
+
+// BuildURL returns the Prow view of the build.
+func (l ProwLocator) BuildURL(prefix string, buildNum int) string {
+	return fmt.Sprintf("%s/%s", prowViewURL, path.Join(prefix, strconv.Itoa(buildNum)))
+}
+
+// ArtifactURL returns the public GCS URL of the artifact.
+func (l ProwLocator) ArtifactURL(prefix string, buildNum int, name string) string {
+	return fmt.Sprintf("%s/%s", gcsStorageURL, path.Join(l.Bucket, prefix, strconv.Itoa(buildNum), testgrid.ArtifactsDir, name))
+}
+
+// JenkinsLocator finds results archived by a Jenkins instance. The last element of a prefix is used as the job name.
+type JenkinsLocator struct {
+	// URL is the base URL of the Jenkins instance.
+	URL string
+}
+
+// BuildURL returns the Jenkins page of the build.
+func (l JenkinsLocator) BuildURL(prefix string, buildNum int) string {
+	return fmt.Sprintf("%s/job/%s/%d/", l.URL, url.PathEscape(path.Base(prefix)), buildNum)
+}
+
+// ArtifactURL returns the URL of the artifact archived by Jenkins.
+func (l JenkinsLocator) ArtifactURL(prefix string, buildNum int, name string) string {
+	return fmt.Sprintf("%s/job/%s/%d/artifact/%s/%s", l.URL, url.PathEscape(path.Base(prefix)), buildNum, testgrid.ArtifactsDir, name)
+}
+
+// LocalLocator finds results stored on the local filesystem using the same layout as TestGrid.
+type LocalLocator struct {
+	// Dir is the directory containing results.
+	Dir string
+}
+
+// BuildURL returns a file URL for the directory of the build.
+func (l LocalLocator) BuildURL(prefix string, buildNum int) string {
+	return l.fileURL(prefix, strconv.Itoa(buildNum))
+}
+
+// ArtifactURL returns a file URL for the artifact.
+func (l LocalLocator) ArtifactURL(prefix string, buildNum int, name string) string {
+	return l.fileURL(prefix, strconv.Itoa(buildNum), testgrid.ArtifactsDir, name)
+}
+
+func (l LocalLocator) fileURL(elem ...string) string {
+	u := url.URL{
+		Scheme: "file",
+		Path:   filepath.ToSlash(filepath.Join(append([]string{l.Dir}, elem...)...)),
+	}
+	return u.String()
+}
@@ -0,0 +1,53 @@
+package report
+
+import (
+	"testing"
+)
+
+func TestLocators(t *testing.T) {
+	prefix, buildNum, artifact := "osde2e-logs/osd-int-4.1", 42, "hive-log.txt"
+
+	tests := []struct {
+		name, location string
+
+		buildURL, artifactURL string
+	}{
+		{
+			name:        ProwLocatorName,
+			location:    "origin-ci-test",
+			buildURL:    "https://prow.k8s.io/view/gcs/osde2e-logs/osd-int-4.1/42",
+			artifactURL: "https://storage.googleapis.com/origin-ci-test/osde2e-logs/osd-int-4.1/42/artifacts/hive-log.txt",
+		},
+		{
+			name:        JenkinsLocatorName,
+			location:    "https://jenkins.example.com/",
+			buildURL:    "https://jenkins.example.com/job/osd-int-4.1/42/",
+			artifactURL: "https://jenkins.example.com/job/osd-int-4.1/42/artifact/artifacts/hive-log.txt",
+		},
+		{
+			name:        LocalLocatorName,
+			location:    "/tmp/results",
+			buildURL:    "file:///tmp/results/osde2e-logs/osd-int-4.1/42",
+			artifactURL: "file:///tmp/results/osde2e-logs/osd-int-4.1/42/artifacts/hive-log.txt",
+		},
+	}
+
+	for _, test := range tests {
+		l, err := NewLocator(test.name, test.location)
+		if err != nil {
+			t.Fatalf("Failed creating '%s' locator: %v", test.name, err)
+		}
+
+		if buildURL := l.BuildURL(prefix, buildNum); buildURL != test.buildURL {
+			t.Errorf("'%s' build URL should be '%s', got '%s'", test.name, test.buildURL, buildURL)
+		}
+
+		if artifactURL := l.ArtifactURL(prefix, buildNum, artifact); artifactURL != test.artifactURL {
+			t.Errorf("'%s' artifact URL should be '%s', got '%s'", test.name, test.artifactURL, artifactURL)
+		}
+	}
+
+	if _, err := NewLocator("unknown", ""); err == nil {
+		t.Error("an unknown locator should return an error")
+	}
+}
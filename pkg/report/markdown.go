@@ -13,12 +13,32 @@ const (
 # {{.Title}}
 
 ## Updated {{date .Range.Start .Config.DateLayout}}
+{{- if .Quarantine}}
+### Expiring quarantines
+	{{- range $qk, $q := .Quarantine}}
+- **{{$q.Test}}** ({{$q.Issue}}) expires {{$q.Expires}}
+	{{- end}}
+{{- end}}
+{{- if .KnownIssues}}
+### Known issues
+	{{- range $kk, $k := .KnownIssues}}
+- **{{$k.Issue}}** (` + "`{{$k.Failure}}`" + `) caused {{$k.Count}} failures
+		{{- if eq $k.Count 0}}, consider removing it{{end}}
+	{{- end}}
+{{- end}}
+{{- with .Installs}}
+### Cluster installs
+	{{- range $ik, $i := .}}
+- **{{$i.Version}}** on {{$i.Provider}}: {{$i.Passed}}/{{$i.Installs}} installed, likely {{percent $i.PassRateLower}}-{{percent $i.PassRateUpper}}
+		{{- if $i.Passed}}, taking {{$i.MedianDuration}} (p90 {{$i.P90Duration}}){{end}}
+	{{- end}}
+{{- end}}
 {{- range $ek, $e := .Envs}}
 ### {{$e.Name}}
 	{{- range $ek, $j := $e.Jobs}}
 - [{{$j.Name}}](https://testgrid.k8s.io/redhat-osd-{{$e.Name}}#{{$j.Name}})
 		{{- range $rn, $r := $j.Runs}}
-   * [#{{$r.BuildNum}}](https://prow.k8s.io/view/gcs/{{$j.Prefix}}/{{$r.BuildNum}})
+   * [#{{$r.BuildNum}}]({{buildURL $j $r}})
 			{{- range $k, $v := $r.Finished.Metadata}}
 				{{- if eq $k "cluster-id"}}
       + Cluster ID: {{$v}}
@@ -28,6 +48,9 @@ const (
       + **Failures**:
 			{{- range $fn, $f := $r.Failures}}
          - Test Name: {{$f.Name}}
+				{{- if $f.KnownIssue}}
+           Known issue: {{$f.KnownIssue}}
+				{{- end}}
 {{failureTxt $f | indent 11}}
 			{{- end}}
 		{{- end}}
@@ -41,7 +64,9 @@ var (
 		Funcs(template.FuncMap{
 			"indent":     indent,
 			"date":       printDate,
+			"percent":    percent,
 			"hiveLogs":   hiveLogs,
+			"buildURL":   buildURL,
 			"failureTxt": failureTxt,
 		}).Parse(markdownTmplText))
 )
@@ -62,10 +87,23 @@ func hiveLogs(run Run) string {
 	return "Could not be found!!!"
 }
 
+// buildURL returns the link to run, defaulting to Prow for runs recorded without one.
+func buildURL(job Job, run Run) string {
+	if run.BuildURL != "" {
+		return run.BuildURL
+	}
+	return ProwLocator{}.BuildURL(job.Prefix, run.BuildNum)
+}
+
 func printDate(t time.Time, layout string) string {
 	return t.Format(layout)
 }
 
+// percent formats a ratio as a whole percentage.
+func percent(ratio float64) string {
+	return fmt.Sprintf("%.0f%%", 100*ratio)
+}
+
 func failureTxt(f Failure) string {
 	failTxt := f.Message(0)
 	return fmt.Sprintf("```%s\n```", failTxt)
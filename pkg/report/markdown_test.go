@@ -2,6 +2,7 @@ package report
 
 import (
 	"bytes"
+	"strings"
 	"testing"
 	"time"
 
@@ -32,7 +33,7 @@ func TestTemplateReport(t *testing.T) {
 								BuildNum: 331,
 								Failures: []Failure{
 									{
-										junit.Result{
+										Result: junit.Result{
 											Name: "BeforeSuite",
 										},
 									},
@@ -42,7 +43,7 @@ func TestTemplateReport(t *testing.T) {
 								BuildNum: 334,
 								Failures: []Failure{
 									{
-										junit.Result{
+										Result: junit.Result{
 											Name: "BeforeSuite",
 										},
 									},
@@ -52,7 +53,7 @@ func TestTemplateReport(t *testing.T) {
 								BuildNum: 335,
 								Failures: []Failure{
 									{
-										junit.Result{
+										Result: junit.Result{
 											Name: "BeforeSuite",
 										},
 									},
@@ -70,7 +71,7 @@ func TestTemplateReport(t *testing.T) {
 								BuildNum: 3,
 								Failures: []Failure{
 									{
-										junit.Result{
+										Result: junit.Result{
 											Name: "BeforeSuite",
 										},
 									},
@@ -80,7 +81,7 @@ func TestTemplateReport(t *testing.T) {
 								BuildNum: 4,
 								Failures: []Failure{
 									{
-										junit.Result{
+										Result: junit.Result{
 											Name: "BeforeSuite",
 										},
 									},
@@ -90,7 +91,7 @@ func TestTemplateReport(t *testing.T) {
 								BuildNum: 6,
 								Failures: []Failure{
 									{
-										junit.Result{
+										Result: junit.Result{
 											Name: "BeforeSuite",
 										},
 									},
@@ -100,7 +101,7 @@ func TestTemplateReport(t *testing.T) {
 								BuildNum: 7,
 								Failures: []Failure{
 									{
-										junit.Result{
+										Result: junit.Result{
 											Name: "BeforeSuite",
 										},
 									},
@@ -114,6 +115,10 @@ func TestTemplateReport(t *testing.T) {
 				Name: "prod",
 			},
 		},
+		Installs: []InstallSummary{
+			{Version: "openshift-v4.2.0", Provider: "aws", Installs: 4, Passed: 1, PassRateLower: 0.05, PassRateUpper: 0.7,
+				MedianDuration: time.Hour, P90Duration: time.Hour},
+		},
 	}
 
 	var buf bytes.Buffer
@@ -121,4 +126,9 @@ func TestTemplateReport(t *testing.T) {
 		t.Fatal(err)
 	}
 	t.Log(buf.String())
+
+	installs := "- **openshift-v4.2.0** on aws: 1/4 installed, likely 5%-70%, taking 1h0m0s (p90 1h0m0s)"
+	if !strings.Contains(buf.String(), installs) {
+		t.Errorf("expected report to contain %q", installs)
+	}
 }
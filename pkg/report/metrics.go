@@ -0,0 +1,129 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MetricsPath is where weather is served in the Prometheus text format.
+const MetricsPath = "/metrics"
+
+// labelEscaper escapes label values in the Prometheus text format.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// gauge is a metric exported from weather with a sample for each job or group.
+type gauge struct {
+	name, help string
+	samples    []sample
+}
+
+type sample struct {
+	labels string
+	value  float64
+}
+
+func (g *gauge) add(labels string, value float64) {
+	g.samples = append(g.samples, sample{labels, value})
+}
+
+// WriteMetrics writes the weather of each job, group of suites, and version and provider of clusters installed in
+// results to w in the Prometheus text format, so dashboards and alerts can use them without deriving them from runs.
+// updated is when results were retrieved.
+func WriteMetrics(w io.Writer, results []JobResults, updated time.Time) error {
+	runs := &gauge{name: "osde2e_job_runs", help: "Finished runs of the job."}
+	passed := &gauge{name: "osde2e_job_passed_runs", help: "Passed runs of the job."}
+	passRate := &gauge{name: "osde2e_job_pass_rate", help: "Ratio of finished runs of the job which passed."}
+	lower := &gauge{name: "osde2e_job_pass_rate_lower", help: "Lower bound of the pass rate of the job with 95% confidence."}
+	upper := &gauge{name: "osde2e_job_pass_rate_upper", help: "Upper bound of the pass rate of the job with 95% confidence."}
+	badness := &gauge{name: "osde2e_job_badness", help: "Failure rate the job is confidently known to have."}
+	flakiness := &gauge{name: "osde2e_job_flakiness", help: "Ratio of consecutive finished runs of the job with different outcomes."}
+	lastPassed := &gauge{name: "osde2e_job_last_passed", help: "Whether the most recent finished run of the job passed."}
+	for _, j := range results {
+		labels := fmt.Sprintf(`env="%s",job="%s"`, labelEscaper.Replace(j.Env), labelEscaper.Replace(j.Name))
+		weather := j.Weather()
+		runs.add(labels, float64(weather.Runs))
+		passed.add(labels, float64(weather.Passed))
+		passRate.add(labels, weather.PassRate)
+		lower.add(labels, weather.PassRateLower)
+		upper.add(labels, weather.PassRateUpper)
+		badness.add(labels, weather.Badness)
+		flakiness.add(labels, weather.Flakiness)
+		lastPassed.add(labels, boolValue(weather.LastResult == "SUCCESS"))
+	}
+
+	groupRuns := &gauge{name: "osde2e_group_runs", help: "Finished runs with tests of the group."}
+	failRate := &gauge{name: "osde2e_group_fail_rate", help: "Ratio of tests of the group which ran that failed."}
+	overBudget := &gauge{name: "osde2e_group_over_budget_runs", help: "Finished runs in which the group spent its time budget."}
+	duration := &gauge{name: "osde2e_group_mean_duration_seconds", help: "How long the group ran on average."}
+	for _, g := range GroupWeather(results) {
+		labels := fmt.Sprintf(`group="%s"`, labelEscaper.Replace(g.Group))
+		groupRuns.add(labels, float64(g.Runs))
+		failRate.add(labels, g.FailRate)
+		overBudget.add(labels, float64(g.OverBudgetRuns))
+		duration.add(labels, g.MeanDuration.Seconds())
+	}
+
+	installs := &gauge{name: "osde2e_install_runs", help: "Finished runs which installed a cluster of the version."}
+	installPassRate := &gauge{name: "osde2e_install_pass_rate", help: "Ratio of cluster installs of the version which succeeded."}
+	installLower := &gauge{name: "osde2e_install_pass_rate_lower", help: "Lower bound of the install success rate of the version with 95% confidence."}
+	installUpper := &gauge{name: "osde2e_install_pass_rate_upper", help: "Upper bound of the install success rate of the version with 95% confidence."}
+	installMedian := &gauge{name: "osde2e_install_median_duration_seconds", help: "How long successful installs of the version took at the median."}
+	installP90 := &gauge{name: "osde2e_install_p90_duration_seconds", help: "How long successful installs of the version took at the 90th percentile."}
+	for _, i := range InstallWeather(results) {
+		labels := fmt.Sprintf(`version="%s",provider="%s"`, labelEscaper.Replace(i.Version), labelEscaper.Replace(i.Provider))
+		installs.add(labels, float64(i.Installs))
+		installPassRate.add(labels, i.PassRate)
+		installLower.add(labels, i.PassRateLower)
+		installUpper.add(labels, i.PassRateUpper)
+		installMedian.add(labels, i.MedianDuration.Seconds())
+		installP90.add(labels, i.P90Duration.Seconds())
+	}
+
+	updatedAt := &gauge{name: "osde2e_results_updated_timestamp_seconds", help: "When results were last retrieved."}
+	updatedAt.add("", float64(updated.Unix()))
+
+	for _, g := range []*gauge{runs, passed, passRate, lower, upper, badness, flakiness, lastPassed, groupRuns, failRate,
+		overBudget, duration, installs, installPassRate, installLower, installUpper, installMedian, installP90, updatedAt} {
+		if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", g.name, g.help, g.name); err != nil {
+			return err
+		}
+		for _, s := range g.samples {
+			value := strconv.FormatFloat(s.value, 'f', -1, 64)
+			if _, err := fmt.Fprintf(w, "%s{%s} %s\n", g.name, s.labels, value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ServeMetrics writes the weather of the latest results in the Prometheus text format.
+func (s *APIServer) ServeMetrics(w http.ResponseWriter, req *http.Request) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if req.Method != http.MethodGet {
+		http.Error(w, "only GET is supported", http.StatusMethodNotAllowed)
+		return
+	} else if s.updated.IsZero() {
+		http.Error(w, "results have not been retrieved yet", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	if err := WriteMetrics(w, s.results, s.updated); err != nil {
+		log.Printf("Failed writing metrics to %s: %v", req.RemoteAddr, err)
+	}
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
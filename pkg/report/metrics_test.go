@@ -0,0 +1,64 @@
+package report
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	testgrid "k8s.io/test-infra/testgrid/metadata"
+)
+
+func TestWriteMetrics(t *testing.T) {
+	now := time.Date(2020, 4, 1, 12, 0, 0, 0, time.UTC)
+	results := []JobResults{{
+		Env:  "int",
+		Name: "osd-int-4.1",
+		Runs: []RunResult{
+			{BuildNum: 13, Finished: &now, Result: "FAILURE",
+				Groups: map[string]GroupResult{"networking": {Tests: 2, Failed: 1, Seconds: 60}}},
+			{BuildNum: 12, Finished: &now, Passed: true, Result: "SUCCESS", Metadata: testgrid.Metadata{
+				"CLUSTER_VERSION": "openshift-v4.1.0", "CLOUD_PROVIDER": "aws", "install-result": "SUCCESS",
+				"install-seconds": 2400.0}},
+		},
+	}}
+
+	var buf bytes.Buffer
+	if err := WriteMetrics(&buf, results, now); err != nil {
+		t.Fatalf("failed to write metrics: %v", err)
+	}
+	for _, expected := range []string{
+		"# TYPE osde2e_job_pass_rate gauge\n",
+		`osde2e_job_runs{env="int",job="osd-int-4.1"} 2` + "\n",
+		`osde2e_job_pass_rate{env="int",job="osd-int-4.1"} 0.5` + "\n",
+		`osde2e_job_flakiness{env="int",job="osd-int-4.1"} 1` + "\n",
+		`osde2e_job_last_passed{env="int",job="osd-int-4.1"} 0` + "\n",
+		`osde2e_group_fail_rate{group="networking"} 0.5` + "\n",
+		`osde2e_group_mean_duration_seconds{group="networking"} 60` + "\n",
+		`osde2e_install_runs{version="openshift-v4.1.0",provider="aws"} 1` + "\n",
+		`osde2e_install_median_duration_seconds{version="openshift-v4.1.0",provider="aws"} 2400` + "\n",
+		"osde2e_results_updated_timestamp_seconds{} 1585742400\n",
+	} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("expected metrics to contain %q, got:\n%s", expected, buf.String())
+		}
+	}
+}
+
+func TestServeMetrics(t *testing.T) {
+	srv := new(APIServer)
+	w := httptest.NewRecorder()
+	srv.ServeMetrics(w, httptest.NewRequest(http.MethodGet, MetricsPath, nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected status %d before results were set, got %d", http.StatusServiceUnavailable, w.Code)
+	}
+
+	srv.Set([]JobResults{{Env: "int", Name: "osd-int-4.1"}})
+	w = httptest.NewRecorder()
+	srv.ServeMetrics(w, httptest.NewRequest(http.MethodGet, MetricsPath, nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `osde2e_job_runs{env="int",job="osd-int-4.1"} 0`) {
+		t.Errorf("expected metrics to be served, got %d:\n%s", w.Code, w.Body)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"k8s.io/test-infra/testgrid/metadata/junit"
 
 	"github.com/openshift/osde2e/pkg/config"
+	"github.com/openshift/osde2e/pkg/quarantine"
 )
 
 // Report shows the results of jobs across multiple environments.
@@ -17,6 +18,38 @@ type Report struct {
 	Title  string
 	Range  TimeRange
 	Envs   []Env
+
+	// Quarantine lists quarantined tests which have expired or will soon expire.
+	Quarantine []quarantine.Entry
+
+	// KnownIssues lists how many failures in the report each known issue caused.
+	KnownIssues []quarantine.KnownIssueMatches
+
+	// Installs are how cluster installs did by version and provider across all finished runs, not only failed ones.
+	Installs []InstallSummary
+}
+
+// CheckQuarantine warns about entries of list expiring within the configured period of now.
+func (r *Report) CheckQuarantine(list *quarantine.List, now time.Time) {
+	r.Quarantine = list.Expiring(now, r.Config.QuarantineWarning)
+}
+
+// MatchKnownIssues links failures in the report to the known issues of list causing them, counting the failures
+// each caused so ones which no longer match can be pruned.
+func (r *Report) MatchKnownIssues(list *quarantine.List) {
+	for e := range r.Envs {
+		for j := range r.Envs[e].Jobs {
+			for _, run := range r.Envs[e].Jobs[j].Runs {
+				versions := run.Versions()
+				for i := range run.Failures {
+					f := &run.Failures[i]
+					k, _ := list.Known(f.Name, f.Message(0), versions...)
+					f.KnownIssue = k.Issue
+				}
+			}
+		}
+	}
+	r.KnownIssues = list.KnownMatches()
 }
 
 // Update refreshes the data of a report within rng. It
@@ -116,6 +149,7 @@ type Job struct {
 // Run contains the results for a run within a specific job.
 type Run struct {
 	BuildNum   int
+	BuildURL   string
 	HiveLogURL string
 
 	Started  testgrid.Started
@@ -124,7 +158,20 @@ type Run struct {
 	Failures []Failure
 }
 
+// Versions returns the versions the cluster of the run was installed at and upgraded to.
+func (r Run) Versions() (versions []string) {
+	for _, key := range []string{"CLUSTER_VERSION", "UPGRADE_RELEASE_NAME"} {
+		if v, ok := r.Finished.Metadata.String(key); ok && *v != "" {
+			versions = append(versions, *v)
+		}
+	}
+	return
+}
+
 // Failure contains an individual failing test.
 type Failure struct {
 	junit.Result
+
+	// KnownIssue is the issue known to cause the failure, if any.
+	KnownIssue string
 }
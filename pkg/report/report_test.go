@@ -0,0 +1,74 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	testgrid "k8s.io/test-infra/testgrid/metadata"
+	"k8s.io/test-infra/testgrid/metadata/junit"
+
+	"github.com/openshift/osde2e/pkg/quarantine"
+)
+
+func TestMatchKnownIssues(t *testing.T) {
+	list, err := quarantine.Parse([]byte(`
+knownIssues:
+- failure: "etcd leader changed"
+  issue: https://issues.redhat.com/browse/OSD-1
+  versions: ">= 4.2"
+- failure: "never happens"
+  issue: https://issues.redhat.com/browse/OSD-2
+`))
+	if err != nil {
+		t.Fatalf("Failed parsing list: %v", err)
+	}
+
+	msg := "etcd leader changed"
+	report := &Report{
+		Envs: []Env{
+			{
+				Name: "int",
+				Jobs: []Job{
+					{
+						Name: "osd-int-4.2",
+						Runs: []Run{
+							{
+								BuildNum: 12,
+								Finished: testgrid.Finished{Metadata: testgrid.Metadata{"CLUSTER_VERSION": "openshift-v4.2.0"}},
+								Failures: []Failure{{Result: junit.Result{Name: "BeforeSuite", Failure: &msg}}},
+							},
+							{
+								BuildNum: 11,
+								Finished: testgrid.Finished{Metadata: testgrid.Metadata{"CLUSTER_VERSION": "openshift-v4.1.9"}},
+								Failures: []Failure{{Result: junit.Result{Name: "BeforeSuite", Failure: &msg}}},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	report.MatchKnownIssues(list)
+
+	runs := report.Envs[0].Jobs[0].Runs
+	if runs[0].Failures[0].KnownIssue != "https://issues.redhat.com/browse/OSD-1" {
+		t.Errorf("expected failure on 4.2 to be a known issue, got '%s'", runs[0].Failures[0].KnownIssue)
+	}
+	if runs[1].Failures[0].KnownIssue != "" {
+		t.Errorf("expected failure on 4.1 not to be a known issue, got '%s'", runs[1].Failures[0].KnownIssue)
+	}
+
+	if len(report.KnownIssues) != 2 || report.KnownIssues[0].Count != 1 || report.KnownIssues[1].Count != 0 {
+		t.Errorf("expected 1 and 0 matches, got %v", report.KnownIssues)
+	}
+
+	var buf bytes.Buffer
+	if err = report.Markdown(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "Known issue: https://issues.redhat.com/browse/OSD-1") ||
+		!strings.Contains(buf.String(), "consider removing it") {
+		t.Errorf("expected known issues in report, got:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,228 @@
+package report
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+	"sort"
+	"time"
+
+	testgrid "k8s.io/test-infra/testgrid/metadata"
+
+	"github.com/openshift/osde2e/pkg/config"
+	osdtestgrid "github.com/openshift/osde2e/pkg/testgrid"
+)
+
+// JobResults are the recent runs of a job in an environment.
+type JobResults struct {
+	Env  string      `json:"env"`
+	Name string      `json:"name"`
+	Runs []RunResult `json:"runs"`
+}
+
+// RunResult is the outcome of a single run of a job.
+type RunResult struct {
+	BuildNum int       `json:"build"`
+	BuildURL string    `json:"buildURL"`
+	Started  time.Time `json:"started"`
+
+	// Finished is nil for runs still in progress.
+	Finished *time.Time `json:"finished,omitempty"`
+	Passed   bool       `json:"passed"`
+	Result   string     `json:"result,omitempty"`
+
+	Metadata    testgrid.Metadata `json:"metadata,omitempty"`
+	FailedTests []string          `json:"failedTests,omitempty"`
+
+	// SkippedTests are why each test skipped by the run was skipped, by the name of the test.
+	SkippedTests map[string]string `json:"skippedTests,omitempty"`
+
+	// Groups are how each group of suites did in the run, by the name of the group.
+	Groups map[string]GroupResult `json:"groups,omitempty"`
+}
+
+// Weather summarizes how a job has been doing.
+type Weather struct {
+	Env  string `json:"env"`
+	Name string `json:"name"`
+
+	// Group is the metadata value shared by the runs summarized, such as a region, when grouped.
+	Group string `json:"group,omitempty"`
+
+	Runs     int     `json:"runs"`
+	Passed   int     `json:"passed"`
+	PassRate float64 `json:"passRate"`
+
+	// PassRateLower and PassRateUpper bound the true pass rate with 95% confidence, narrowing as more runs finish.
+	PassRateLower float64 `json:"passRateLower"`
+	PassRateUpper float64 `json:"passRateUpper"`
+
+	// Badness is the failure rate the job is confidently known to have, used to rank jobs worst first.
+	Badness float64 `json:"badness"`
+
+	// Flakiness is the ratio of consecutive finished runs with different outcomes, so a job alternating between
+	// passing and failing is flakier than one which broke and stayed broken.
+	Flakiness float64 `json:"flakiness"`
+
+	// LastResult is the result of the most recent finished run.
+	LastResult string `json:"lastResult,omitempty"`
+}
+
+// unknownGroup contains runs without the metadata being grouped by.
+const unknownGroup = "unknown"
+
+// Weather summarizes the finished runs of j.
+func (j JobResults) Weather() Weather {
+	return j.weather("", j.Runs)
+}
+
+// WeatherBy summarizes the finished runs of j grouped by the value of their metadata key, such as 'region'.
+func (j JobResults) WeatherBy(key string) []Weather {
+	var groups []string
+	runs := map[string][]RunResult{}
+	for _, r := range j.Runs {
+		if r.Finished == nil {
+			continue
+		}
+
+		group := unknownGroup
+		if v, ok := r.Metadata.String(key); ok && *v != "" {
+			group = *v
+		}
+
+		if _, ok := runs[group]; !ok {
+			groups = append(groups, group)
+		}
+		runs[group] = append(runs[group], r)
+	}
+
+	sort.Strings(groups)
+	weather := make([]Weather, len(groups))
+	for i, group := range groups {
+		weather[i] = j.weather(group, runs[group])
+	}
+	return weather
+}
+
+func (j JobResults) weather(group string, runs []RunResult) Weather {
+	w := Weather{
+		Env:   j.Env,
+		Name:  j.Name,
+		Group: group,
+	}
+	flips, previous := 0, false
+	for _, r := range runs {
+		if r.Finished == nil {
+			continue
+		}
+
+		if w.Runs == 0 {
+			w.LastResult = r.Result
+		} else if r.Passed != previous {
+			flips++
+		}
+		previous = r.Passed
+		w.Runs++
+		if r.Passed {
+			w.Passed++
+		}
+	}
+
+	if w.Runs != 0 {
+		w.PassRate = float64(w.Passed) / float64(w.Runs)
+		w.PassRateLower, w.PassRateUpper = wilson(w.Passed, w.Runs)
+		w.Badness = 1 - w.PassRateUpper
+	}
+	if w.Runs > 1 {
+		w.Flakiness = float64(flips) / float64(w.Runs-1)
+	}
+	return w
+}
+
+// Results retrieves runs of each configured job that started after since, most recent first.
+func (c Config) Results(cfg *config.Config, since time.Time) (results []JobResults, err error) {
+	locator := c.locator(cfg.TestGridBucket)
+	for _, envCfg := range c.Envs {
+		for _, jobCfg := range c.Jobs {
+			if envCfg.SkipJob(jobCfg.Name) {
+				continue
+			}
+
+			job := JobResults{
+				Env:  envCfg.Name,
+				Name: fmt.Sprintf("%s-%s-%s", jobCfg.Name, envCfg.Name, jobCfg.Version),
+			}
+
+			prefix := filepath.Join(cfg.TestGridPrefix, job.Name)
+			if job.Runs, err = jobRuns(cfg, locator, prefix, since); err != nil {
+				return nil, fmt.Errorf("couldn't get runs of '%s': %v", job.Name, err)
+			}
+			results = append(results, job)
+		}
+	}
+	return
+}
+
+// jobRuns returns runs stored in TestGrid under prefix that started after since.
+func jobRuns(cfg *config.Config, locator Locator, prefix string, since time.Time) (runs []RunResult, err error) {
+	tg, err := osdtestgrid.NewTestGrid(cfg.TestGridBucket, prefix, cfg.TestGridServiceAccount)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't setup TestGrid: %v", err)
+	}
+
+	ctx := context.Background()
+	started, latestBuildNum, err := tg.LatestStarted(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get latest build: %v", err)
+	}
+
+	for i := latestBuildNum; i > 0; i-- {
+		if i != latestBuildNum {
+			if started, err = tg.Started(ctx, i); err != nil {
+				log.Printf("Error getting started for build %d: %v", i, err)
+				continue
+			}
+		}
+
+		startTime := time.Unix(started.Timestamp, 0).UTC()
+		if startTime.Before(since) {
+			break
+		}
+
+		run := RunResult{
+			BuildNum: i,
+			BuildURL: locator.BuildURL(prefix, i),
+			Started:  startTime,
+		}
+
+		// runs without a finished record are still in progress
+		if finished, err := tg.Finished(ctx, i); err == nil && finished.Timestamp != nil {
+			end := time.Unix(*finished.Timestamp, 0).UTC()
+			run.Finished = &end
+			run.Passed = finished.Passed != nil && *finished.Passed
+			run.Result = finished.Result
+			run.Metadata = finished.Metadata
+
+			if suites, err := tg.Suites(ctx, i); err != nil {
+				log.Printf("Couldn't get suites for build %d: %v", i, err)
+			} else {
+				for _, suite := range suites.Suites {
+					for _, result := range suite.Results {
+						run.addGroupResult(result)
+						if result.Failure != nil {
+							run.FailedTests = append(run.FailedTests, result.Name)
+						} else if reason, ok := skipReason(result); ok {
+							if run.SkippedTests == nil {
+								run.SkippedTests = map[string]string{}
+							}
+							run.SkippedTests[result.Name] = reason
+						}
+					}
+				}
+			}
+		}
+		runs = append(runs, run)
+	}
+	return
+}
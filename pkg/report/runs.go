@@ -11,8 +11,7 @@ import (
 )
 
 const (
-	hiveLogName     = "hive-log.txt"
-	artifactURLTmpl = "https://storage.googleapis.com/%s/%s/%d/%s/%s"
+	hiveLogName = "hive-log.txt"
 )
 
 // GetRuns returns TestGrid build runs starting with prefix that are after earliest.
@@ -22,6 +21,8 @@ func (r Report) GetRuns(prefix string, earliest time.Time, cfg *config.Config) (
 		log.Fatalf("Failed to setup TestGrid support: %v", err)
 	}
 
+	locator := r.Config.locator(cfg.TestGridBucket)
+
 	ctx := context.Background()
 	started, latestBuildNum, err := tg.LatestStarted(ctx)
 	if err != nil {
@@ -74,6 +75,7 @@ func (r Report) GetRuns(prefix string, earliest time.Time, cfg *config.Config) (
 		if len(failures) != 0 {
 			run := Run{
 				BuildNum: i,
+				BuildURL: locator.BuildURL(prefix, i),
 
 				Started:  started,
 				Finished: finished,
@@ -87,7 +89,7 @@ func (r Report) GetRuns(prefix string, earliest time.Time, cfg *config.Config) (
 			if err != nil {
 				log.Printf("Encountered error checking for '%s' on build %d: %v", hiveLogPrefix, i, err)
 			} else if len(paths) != 0 {
-				run.HiveLogURL = fmt.Sprintf(artifactURLTmpl, cfg.TestGridBucket, prefix, i, testgrid.ArtifactsDir, hiveLogName)
+				run.HiveLogURL = locator.ArtifactURL(prefix, i, hiveLogName)
 			}
 
 			// add run
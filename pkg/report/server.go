@@ -0,0 +1,67 @@
+package report
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Server makes the latest version of a report available over HTTP.
+type Server struct {
+	mu       sync.RWMutex
+	html     []byte
+	markdown []byte
+	updated  time.Time
+}
+
+// Set replaces the report being served with r.
+func (s *Server) Set(r *Report) error {
+	var htmlBuf, mdBuf bytes.Buffer
+	if err := r.HTML(&htmlBuf); err != nil {
+		return err
+	} else if err = r.Markdown(&mdBuf); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.html, s.markdown = htmlBuf.Bytes(), mdBuf.Bytes()
+	s.updated = time.Now().UTC()
+	return nil
+}
+
+// Updated returns when the served report was last set.
+func (s *Server) Updated() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.updated
+}
+
+// ServeHTTP writes the latest report as HTML, or as Markdown when '/report.md' is requested.
+func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.html == nil {
+		http.Error(w, "report has not been generated yet", http.StatusServiceUnavailable)
+		return
+	}
+
+	data, contentType := s.html, "text/html; charset=utf-8"
+	switch req.URL.Path {
+	case "/", "/index.html":
+	case "/report.md":
+		data, contentType = s.markdown, "text/markdown; charset=utf-8"
+	default:
+		http.NotFound(w, req)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Last-Modified", s.updated.Format(http.TimeFormat))
+	if _, err := w.Write(data); err != nil {
+		log.Printf("Failed writing report to %s: %v", req.RemoteAddr, err)
+	}
+}
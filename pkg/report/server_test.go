@@ -0,0 +1,71 @@
+package report
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServeReport(t *testing.T) {
+	srv := new(Server)
+	httpSrv := httptest.NewServer(srv)
+	defer httpSrv.Close()
+
+	// nothing should be served before a report is set
+	if resp, err := http.Get(httpSrv.URL); err != nil {
+		t.Fatalf("Failed requesting report: %v", err)
+	} else if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected status %d before report was set, got %d", http.StatusServiceUnavailable, resp.StatusCode)
+	}
+
+	now := time.Now().UTC()
+	report := &Report{
+		Title: "osde2e Served Report",
+		Range: TimeRange{
+			Start: now.Add(-5 * time.Minute),
+			End:   now,
+		},
+		Envs: []Env{
+			{
+				Name: "int",
+				Jobs: []Job{
+					{
+						Name: "osd-int-4.1",
+						Runs: []Run{
+							{
+								BuildNum: 12,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	if err := srv.Set(report); err != nil {
+		t.Fatalf("Failed setting report: %v", err)
+	}
+
+	// check both formats are served
+	for path, expected := range map[string]string{
+		"/":          "<h1>osde2e Served Report</h1>",
+		"/report.md": "# osde2e Served Report",
+	} {
+		resp, err := http.Get(httpSrv.URL + path)
+		if err != nil {
+			t.Fatalf("Failed requesting '%s': %v", path, err)
+		}
+
+		data, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatalf("Failed reading '%s': %v", path, err)
+		} else if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected status %d for '%s', got %d", http.StatusOK, path, resp.StatusCode)
+		} else if !strings.Contains(string(data), expected) {
+			t.Fatalf("'%s' should contain '%s', got: %s", path, expected, data)
+		}
+	}
+}
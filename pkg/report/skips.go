@@ -0,0 +1,116 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/test-infra/testgrid/metadata/junit"
+
+	"github.com/openshift/osde2e/pkg/skips"
+)
+
+// DefaultWeatherSkips is how many of the most skipped tests are included in the weather.
+const DefaultWeatherSkips = 5
+
+// SkippedTest summarizes how often a test was skipped in the finished runs of jobs.
+type SkippedTest struct {
+	Name  string `json:"name"`
+	Skips int    `json:"skips"`
+
+	// Reasons counts skips of the test by why it was skipped, such as 'capability-missing'.
+	Reasons map[string]int `json:"reasons"`
+
+	// Jobs are the jobs which skipped the test, sorted.
+	Jobs []string `json:"jobs"`
+}
+
+// skipReason returns why result was skipped, if it was.
+func skipReason(result junit.Result) (string, bool) {
+	if result.Skipped == nil {
+		return "", false
+	}
+	if reason := property(result, skips.PropertyReason); reason != "" {
+		return reason, true
+	}
+	return string(skips.Unclassified), true
+}
+
+// property returns the value of the property of result with name, or an empty string if it isn't set.
+func property(result junit.Result, name string) string {
+	if result.Properties != nil {
+		for _, p := range result.Properties.PropertyList {
+			if p.Name == name {
+				return p.Value
+			}
+		}
+	}
+	return ""
+}
+
+// SkipWeather returns the tests skipped in finished runs of jobs, most skipped first. Only skips for reason are
+// counted unless it's empty.
+func SkipWeather(results []JobResults, reason string) []SkippedTest {
+	tests := map[string]*SkippedTest{}
+	jobs := map[string]map[string]bool{}
+	for _, j := range results {
+		for _, r := range j.Runs {
+			if r.Finished == nil {
+				continue
+			}
+
+			for name, why := range r.SkippedTests {
+				if reason != "" && why != reason {
+					continue
+				}
+
+				t, ok := tests[name]
+				if !ok {
+					t = &SkippedTest{Name: name, Reasons: map[string]int{}}
+					tests[name], jobs[name] = t, map[string]bool{}
+				}
+				t.Skips++
+				t.Reasons[why]++
+				if !jobs[name][j.Name] {
+					jobs[name][j.Name] = true
+					t.Jobs = append(t.Jobs, j.Name)
+				}
+			}
+		}
+	}
+
+	skipped := make([]SkippedTest, 0, len(tests))
+	for _, t := range tests {
+		sort.Strings(t.Jobs)
+		skipped = append(skipped, *t)
+	}
+	sort.Slice(skipped, func(i, j int) bool {
+		if skipped[i].Skips != skipped[j].Skips {
+			return skipped[i].Skips > skipped[j].Skips
+		}
+		return skipped[i].Name < skipped[j].Name
+	})
+	return skipped
+}
+
+// SlackSkips formats the limit most skipped tests as a Slack message listing why they were skipped.
+func SlackSkips(skipped []SkippedTest, limit int) string {
+	if len(skipped) == 0 {
+		return ""
+	} else if limit < len(skipped) {
+		skipped = skipped[:limit]
+	}
+
+	var b strings.Builder
+	b.WriteString("*Most skipped tests*\n")
+	for _, t := range skipped {
+		reasons := make([]string, 0, len(t.Reasons))
+		for reason, count := range t.Reasons {
+			reasons = append(reasons, fmt.Sprintf("%d %s", count, reason))
+		}
+		sort.Strings(reasons)
+		fmt.Fprintf(&b, "- %s: skipped %d times in %d jobs (%s)\n", t.Name, t.Skips, len(t.Jobs),
+			strings.Join(reasons, ", "))
+	}
+	return b.String()
+}
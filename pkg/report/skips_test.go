@@ -0,0 +1,75 @@
+package report
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/test-infra/testgrid/metadata/junit"
+)
+
+func TestSkipWeather(t *testing.T) {
+	now := time.Now().UTC()
+	results := []JobResults{
+		{
+			Name: "osd-int-4.1",
+			Runs: []RunResult{
+				// runs in progress aren't counted
+				{BuildNum: 3, SkippedTests: map[string]string{"Storage should expand volumes": "capability-missing"}},
+				{BuildNum: 2, Finished: &now, SkippedTests: map[string]string{
+					"Storage should expand volumes": "capability-missing",
+					"Routes should be admitted":     "quarantined",
+				}},
+				{BuildNum: 1, Finished: &now, SkippedTests: map[string]string{"Storage should expand volumes": "capability-missing"}},
+			},
+		},
+		{
+			Name: "osd-stage-4.1",
+			Runs: []RunResult{
+				{BuildNum: 1, Finished: &now, SkippedTests: map[string]string{"Routes should be admitted": "unclassified"}},
+			},
+		},
+	}
+
+	expected := []SkippedTest{
+		{Name: "Routes should be admitted", Skips: 2, Reasons: map[string]int{"quarantined": 1, "unclassified": 1},
+			Jobs: []string{"osd-int-4.1", "osd-stage-4.1"}},
+		{Name: "Storage should expand volumes", Skips: 2, Reasons: map[string]int{"capability-missing": 2},
+			Jobs: []string{"osd-int-4.1"}},
+	}
+	skipped := SkipWeather(results, "")
+	if !reflect.DeepEqual(skipped, expected) {
+		t.Errorf("expected skipped tests %v, got %v", expected, skipped)
+	}
+
+	if quarantined := SkipWeather(results, "quarantined"); len(quarantined) != 1 || quarantined[0].Skips != 1 {
+		t.Errorf("expected only quarantined skips to be counted, got %v", quarantined)
+	}
+
+	msg := SlackSkips(skipped, 1)
+	if lines := strings.Split(strings.TrimSpace(msg), "\n"); len(lines) != 2 ||
+		!strings.Contains(lines[1], "Routes should be admitted: skipped 2 times in 2 jobs (1 quarantined, 1 unclassified)") {
+		t.Errorf("expected most skipped test to be listed, got:\n%s", msg)
+	}
+}
+
+func TestSkipReason(t *testing.T) {
+	skipped := ""
+	if _, ok := skipReason(junit.Result{}); ok {
+		t.Error("expected results which weren't skipped to have no reason")
+	}
+	if reason, _ := skipReason(junit.Result{Skipped: &skipped}); reason != "unclassified" {
+		t.Errorf("expected skips without a reason to be unclassified, got '%s'", reason)
+	}
+
+	result := junit.Result{
+		Skipped: &skipped,
+		Properties: &junit.Properties{
+			PropertyList: []junit.Property{{Name: "skip-reason", Value: "config-excluded"}},
+		},
+	}
+	if reason, _ := skipReason(result); reason != "config-excluded" {
+		t.Errorf("expected reason from properties, got '%s'", reason)
+	}
+}
@@ -0,0 +1,76 @@
+package report
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+
+	"sigs.k8s.io/yaml"
+)
+
+// teamName matches names of teams, which are used in URLs.
+var teamName = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// Team is reported on a subset of jobs, such as those testing what it owns.
+type Team struct {
+	// Name identifies the team. Its report is served under '/teams/<name>/'.
+	Name string `json:"name"`
+
+	// Channel is the Slack channel the weather of the team's jobs is posted to. It's not posted if empty.
+	Channel string `json:"channel,omitempty"`
+
+	// Jobs are the names of the configured jobs reported to the team, such as 'osd-upgrade'.
+	Jobs []string `json:"jobs"`
+}
+
+// LoadTeams reads teams from a YAML file.
+func LoadTeams(file string) ([]Team, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read teams '%s': %v", file, err)
+	}
+	return ParseTeams(data)
+}
+
+// ParseTeams decodes YAML teams, returning an error if any are invalid.
+func ParseTeams(data []byte) ([]Team, error) {
+	var file struct {
+		Teams []Team `json:"teams"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("couldn't decode teams: %v", err)
+	}
+
+	seen := map[string]bool{}
+	for _, t := range file.Teams {
+		if !teamName.MatchString(t.Name) {
+			return nil, fmt.Errorf("team name '%s' must be lowercase letters, numbers, and '-'", t.Name)
+		} else if seen[t.Name] {
+			return nil, fmt.Errorf("team '%s' is listed more than once", t.Name)
+		} else if len(t.Jobs) == 0 {
+			return nil, fmt.Errorf("team '%s' must list jobs", t.Name)
+		}
+		seen[t.Name] = true
+	}
+	return file.Teams, nil
+}
+
+// Config returns c reporting only the jobs of t, with an error if none of them are configured.
+func (t Team) Config(c Config) (Config, error) {
+	jobs := map[string]bool{}
+	for _, name := range t.Jobs {
+		jobs[name] = true
+	}
+
+	var kept []JobConfig
+	for _, job := range c.Jobs {
+		if jobs[job.Name] {
+			kept = append(kept, job)
+		}
+	}
+	if len(kept) == 0 {
+		return c, fmt.Errorf("none of the jobs of team '%s' are reported: %v", t.Name, t.Jobs)
+	}
+	c.Jobs = kept
+	return c, nil
+}
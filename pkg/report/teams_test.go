@@ -0,0 +1,51 @@
+package report
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTeams(t *testing.T) {
+	teams, err := ParseTeams([]byte(`
+teams:
+- name: upgrades
+  channel: '#upgrades'
+  jobs: [osd-upgrade]
+- name: installs
+  jobs: [osd]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Team{
+		{Name: "upgrades", Channel: "#upgrades", Jobs: []string{"osd-upgrade"}},
+		{Name: "installs", Jobs: []string{"osd"}},
+	}
+	if !reflect.DeepEqual(teams, expected) {
+		t.Errorf("expected teams %+v, got %+v", expected, teams)
+	}
+
+	for _, invalid := range []string{
+		"teams: [{name: Upgrades, jobs: [osd]}]",
+		"teams: [{name: upgrades}]",
+		"teams: [{name: upgrades, jobs: [osd]}, {name: upgrades, jobs: [osd]}]",
+	} {
+		if _, err = ParseTeams([]byte(invalid)); err == nil {
+			t.Errorf("expected '%s' to be invalid", invalid)
+		}
+	}
+}
+
+func TestTeamConfig(t *testing.T) {
+	cfg, err := Team{Name: "upgrades", Jobs: []string{"osd-upgrade"}}.Config(*DefaultConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Jobs) != 1 || cfg.Jobs[0].Name != "osd-upgrade" || len(DefaultConfig.Jobs) != 2 {
+		t.Errorf("expected only the team's job to be reported, got %+v", cfg.Jobs)
+	}
+
+	if _, err = (Team{Name: "missing", Jobs: []string{"nope"}}).Config(*DefaultConfig); err == nil {
+		t.Error("expected a team without configured jobs to fail")
+	}
+}
@@ -0,0 +1,104 @@
+package report
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+const (
+	// DefaultWeatherMinRuns is the fewest finished runs a job needs before its weather is reported.
+	DefaultWeatherMinRuns = 5
+
+	// confidenceZ is the standard score for the 95% confidence of pass rate intervals.
+	confidenceZ = 1.96
+)
+
+// wilson returns the Wilson score interval of the pass rate of runs with passed successes. Unlike the pass rate
+// alone it's wide for few runs, so 2/3 and 200/300 aren't treated the same.
+func wilson(passed, runs int) (lower, upper float64) {
+	if runs == 0 {
+		return 0, 1
+	}
+
+	n := float64(runs)
+	p := float64(passed) / n
+	z2 := confidenceZ * confidenceZ
+
+	denom := 1 + z2/n
+	center := (p + z2/(2*n)) / denom
+	margin := confidenceZ * math.Sqrt(p*(1-p)/n+z2/(4*n*n)) / denom
+	return math.Max(0, center-margin), math.Min(1, center+margin)
+}
+
+// SortWeather orders weather worst first by Badness, putting jobs with more runs first when tied.
+func SortWeather(weather []Weather) {
+	sort.SliceStable(weather, func(i, j int) bool {
+		if weather[i].Badness != weather[j].Badness {
+			return weather[i].Badness > weather[j].Badness
+		}
+		return weather[i].Runs > weather[j].Runs
+	})
+}
+
+// FilterWeather returns the weather of jobs with at least minRuns finished runs and how many were left out.
+func FilterWeather(weather []Weather, minRuns int) (kept []Weather, suppressed int) {
+	kept = make([]Weather, 0, len(weather))
+	for _, w := range weather {
+		if w.Runs < minRuns {
+			suppressed++
+			continue
+		}
+		kept = append(kept, w)
+	}
+	return kept, suppressed
+}
+
+// SlackWeather formats the weather of jobs with at least minRuns finished runs as a Slack message, worst first.
+func SlackWeather(weather []Weather, minRuns int) string {
+	weather, suppressed := FilterWeather(weather, minRuns)
+	SortWeather(weather)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "*osde2e weather* (jobs with at least %d runs, worst first)\n", minRuns)
+	for _, w := range weather {
+		name := w.Name
+		if w.Group != "" {
+			name += " (" + w.Group + ")"
+		}
+		fmt.Fprintf(&b, "%s *%s*: %d/%d passed, likely %.0f%%-%.0f%%", weatherIcon(w), name, w.Passed, w.Runs,
+			100*w.PassRateLower, 100*w.PassRateUpper)
+		if w.LastResult != "" {
+			fmt.Fprintf(&b, ", last %s", w.LastResult)
+		}
+		b.WriteString("\n")
+	}
+
+	if len(weather) == 0 {
+		b.WriteString("No jobs have enough runs to report on.\n")
+	}
+	if suppressed != 0 {
+		fmt.Fprintf(&b, "_%d with too few runs to judge left out._\n", suppressed)
+	}
+	return b.String()
+}
+
+// weatherIcon is a Slack emoji for how confidently w is failing.
+func weatherIcon(w Weather) string {
+	return passRateIcon(w.PassRateUpper)
+}
+
+// passRateIcon is a Slack emoji for how confidently something with a pass rate of at most upper is failing.
+func passRateIcon(upper float64) string {
+	switch {
+	case upper < 0.5:
+		return ":thunder_cloud_and_rain:"
+	case upper < 0.8:
+		return ":rain_cloud:"
+	case upper < 0.95:
+		return ":partly_sunny:"
+	default:
+		return ":sunny:"
+	}
+}
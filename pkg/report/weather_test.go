@@ -0,0 +1,74 @@
+package report
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWilson(t *testing.T) {
+	for _, test := range []struct {
+		passed, runs int
+		lower, upper float64
+	}{
+		{passed: 2, runs: 3, lower: 0.2077, upper: 0.9385},
+		{passed: 200, runs: 300, lower: 0.6115, upper: 0.7176},
+		{passed: 0, runs: 10, lower: 0, upper: 0.2775},
+		{passed: 10, runs: 10, lower: 0.7225, upper: 1},
+		{passed: 0, runs: 0, lower: 0, upper: 1},
+	} {
+		lower, upper := wilson(test.passed, test.runs)
+		if math.Abs(lower-test.lower) > 0.0001 || math.Abs(upper-test.upper) > 0.0001 {
+			t.Errorf("%d/%d: expected interval [%.4f, %.4f], got [%.4f, %.4f]", test.passed, test.runs,
+				test.lower, test.upper, lower, upper)
+		}
+	}
+}
+
+func TestSlackWeather(t *testing.T) {
+	weather := []Weather{
+		withInterval(Weather{Name: "osd-int-4.1", Runs: 3, Passed: 2, LastResult: "SUCCESS"}),
+		withInterval(Weather{Name: "osd-stage-4.1", Runs: 300, Passed: 200, LastResult: "FAILURE"}),
+		withInterval(Weather{Name: "osd-prod-4.1", Runs: 10, Passed: 10, LastResult: "SUCCESS"}),
+	}
+
+	msg := SlackWeather(weather, 5)
+	lines := strings.Split(strings.TrimSpace(msg), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("expected header, 2 jobs, and suppressed count, got:\n%s", msg)
+	}
+
+	// the job confidently failing a third of runs is worse than one with too few runs to judge
+	if !strings.Contains(lines[1], "osd-stage-4.1") || !strings.Contains(lines[2], "osd-prod-4.1") {
+		t.Errorf("expected osd-stage-4.1 to be listed before osd-prod-4.1, got:\n%s", msg)
+	}
+	if strings.Contains(msg, "osd-int-4.1") || !strings.Contains(lines[3], "1 with too few runs") {
+		t.Errorf("expected osd-int-4.1 to be left out for having too few runs, got:\n%s", msg)
+	}
+}
+
+func TestFlakiness(t *testing.T) {
+	now := time.Now()
+	results := func(passed ...bool) JobResults {
+		j := JobResults{Name: "osd-int-4.1", Runs: []RunResult{{BuildNum: 0}}}
+		for i, p := range passed {
+			j.Runs = append(j.Runs, RunResult{BuildNum: i + 1, Finished: &now, Passed: p})
+		}
+		return j
+	}
+
+	for _, test := range []struct {
+		passed    []bool
+		flakiness float64
+	}{
+		{passed: []bool{true, false, true, false, true}, flakiness: 1},
+		{passed: []bool{false, false, true, true, true}, flakiness: 0.25},
+		{passed: []bool{false, false, false}, flakiness: 0},
+		{passed: []bool{true}, flakiness: 0},
+	} {
+		if w := results(test.passed...).Weather(); w.Flakiness != test.flakiness {
+			t.Errorf("%v: expected flakiness %v, got %v", test.passed, test.flakiness, w.Flakiness)
+		}
+	}
+}
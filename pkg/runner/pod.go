@@ -11,10 +11,13 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/openshift/osde2e/pkg/harness"
 )
 
 const (
 	podCreateTimeout = 90 * time.Second
+	podPollInterval  = 10 * time.Second
 
 	resultsPort     = 8000
 	resultsPortName = "results"
@@ -59,6 +62,7 @@ func (r *Runner) createPod() (pod *kubev1.Pod, err error) {
 		if container.Name == "" || container.Name == r.Name {
 			pod.Spec.Containers[i].Name = r.Name
 			pod.Spec.Containers[i].Image = r.ImageName
+			pod.Spec.Containers[i].Env = append(pod.Spec.Containers[i].Env, r.harnessEnv()...)
 
 			// run command in pod if, present
 			if len(r.Cmd) != 0 {
@@ -92,19 +96,57 @@ func (r *Runner) createPod() (pod *kubev1.Pod, err error) {
 
 func (r *Runner) waitForPodRunning(pod *kubev1.Pod) error {
 	runningCondition := func() (done bool, err error) {
-		pod, err = r.Kube.CoreV1().Pods(pod.Namespace).Get(pod.Name, metav1.GetOptions{})
-		if err != nil && !kerror.IsNotFound(err) {
+		current, err := r.Kube.CoreV1().Pods(pod.Namespace).Get(pod.Name, metav1.GetOptions{})
+		if kerror.IsNotFound(err) {
+			err = &InfraError{Reason: fmt.Sprintf("Pod '%s/%s' was deleted", pod.Namespace, pod.Name)}
+		} else if err != nil {
 			return
-		} else if pod == nil {
+		} else if current == nil {
 			err = errors.New("pod can't be nil")
-		} else if pod.Status.Phase == kubev1.PodFailed {
+		} else if reason, ok := infraFailure(current); ok {
+			err = &InfraError{Reason: reason}
+		} else if current.Status.Phase == kubev1.PodFailed {
 			err = errors.New("failed waiting for Pod: the Pod has failed")
-		} else if pod.Status.Phase == kubev1.PodRunning {
+		} else if current.Status.Phase == kubev1.PodRunning {
 			done = true
 		} else {
 			r.Printf("Waiting for Pod '%s/%s' to start Running...", pod.Namespace, pod.Name)
 		}
 		return
 	}
-	return wait.PollImmediateUntil(10*time.Second, runningCondition, r.stopCh)
+	return wait.PollImmediateUntil(podPollInterval, runningCondition, r.stopCh)
+}
+
+// checkPod returns an *InfraError if pod has failed because of the cluster rather than what it ran.
+func (r *Runner) checkPod(pod *kubev1.Pod) error {
+	current, err := r.Kube.CoreV1().Pods(pod.Namespace).Get(pod.Name, metav1.GetOptions{})
+	if kerror.IsNotFound(err) {
+		return &InfraError{Reason: fmt.Sprintf("Pod '%s/%s' was deleted", pod.Namespace, pod.Name)}
+	} else if err != nil {
+		r.Printf("Encountered error getting Pod '%s/%s': %v", pod.Namespace, pod.Name, err)
+	} else if reason, ok := infraFailure(current); ok {
+		return &InfraError{Reason: reason}
+	}
+	return nil
+}
+
+// harnessEnv is the environment expected by harnesses using the harness package.
+func (r *Runner) harnessEnv() []kubev1.EnvVar {
+	env := []kubev1.EnvVar{
+		{
+			Name:  harness.NameEnv,
+			Value: r.Name,
+		},
+		{
+			Name:  harness.OutputDirEnv,
+			Value: r.OutputDir,
+		},
+	}
+	if r.Timeout > 0 {
+		env = append(env, kubev1.EnvVar{
+			Name:  harness.TimeoutEnv,
+			Value: r.Timeout.String(),
+		})
+	}
+	return env
 }
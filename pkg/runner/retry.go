@@ -0,0 +1,177 @@
+package runner
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	kubev1 "k8s.io/api/core/v1"
+	"k8s.io/test-infra/testgrid/metadata/junit"
+
+	"github.com/openshift/osde2e/pkg/junitprops"
+)
+
+// InfraSuiteName is the JUnit suite recording runners retried because of infrastructure failures.
+const InfraSuiteName = "Runner infrastructure"
+
+// podInfraReasons are reasons Pods fail which are caused by the cluster rather than what they run.
+var podInfraReasons = map[string]bool{
+	"Evicted":                  true,
+	"Preempting":               true,
+	"NodeLost":                 true,
+	"NodeShutdown":             true,
+	"Shutdown":                 true,
+	"UnexpectedAdmissionError": true,
+	"OutOfcpu":                 true,
+	"OutOfmemory":              true,
+}
+
+// containerInfraReasons are reasons containers can't start which are caused by the cluster rather than what they run.
+var containerInfraReasons = map[string]bool{
+	"ImagePullBackOff":    true,
+	"RegistryUnavailable": true,
+}
+
+// InfraError is a runner Pod failing because of the cluster rather than what it ran, such as its image failing to
+// pull or its node being preempted.
+type InfraError struct {
+	Reason string
+}
+
+func (e *InfraError) Error() string {
+	return "infrastructure failure: " + e.Reason
+}
+
+// infraFailure returns why pod failed or can't start because of the cluster, if it did.
+func infraFailure(pod *kubev1.Pod) (reason string, ok bool) {
+	if pod.Status.Phase == kubev1.PodFailed && podInfraReasons[pod.Status.Reason] {
+		return fmt.Sprintf("Pod '%s/%s' failed with reason %s: %s", pod.Namespace, pod.Name, pod.Status.Reason,
+			pod.Status.Message), true
+	}
+
+	statuses := append(append([]kubev1.ContainerStatus(nil), pod.Status.InitContainerStatuses...),
+		pod.Status.ContainerStatuses...)
+	for _, status := range statuses {
+		if waiting := status.State.Waiting; waiting != nil && containerInfraReasons[waiting.Reason] {
+			return fmt.Sprintf("container '%s' of Pod '%s/%s' is waiting with reason %s: %s", status.Name,
+				pod.Namespace, pod.Name, waiting.Reason, waiting.Message), true
+		}
+	}
+	return "", false
+}
+
+// InfraRetries records runners retried because of infrastructure failures.
+var InfraRetries = new(RetryLog)
+
+// RetryLog records the infrastructure failures of runners by name.
+type RetryLog struct {
+	mu      sync.Mutex
+	runners map[string]*RunnerRetries
+}
+
+// RunnerRetries are the infrastructure failures of a runner.
+type RunnerRetries struct {
+	Name string `json:"name"`
+
+	// Reasons are why each attempt failed.
+	Reasons []string `json:"reasons"`
+
+	// Exhausted is true if the runner failed after using all of its retries.
+	Exhausted bool `json:"exhausted"`
+}
+
+// record adds an infrastructure failure of the runner name.
+func (l *RetryLog) record(name, reason string, exhausted bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.runners == nil {
+		l.runners = map[string]*RunnerRetries{}
+	}
+	r, ok := l.runners[name]
+	if !ok {
+		r = &RunnerRetries{Name: name}
+		l.runners[name] = r
+	}
+	r.Reasons = append(r.Reasons, reason)
+	r.Exhausted = r.Exhausted || exhausted
+}
+
+// Runners returns the runners with infrastructure failures, sorted by name.
+func (l *RetryLog) Runners() (runners []RunnerRetries) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, r := range l.runners {
+		runners = append(runners, RunnerRetries{
+			Name:      r.Name,
+			Reasons:   append([]string(nil), r.Reasons...),
+			Exhausted: r.Exhausted,
+		})
+	}
+	sort.Slice(runners, func(i, j int) bool {
+		return runners[i].Name < runners[j].Name
+	})
+	return
+}
+
+// Metadata counts infrastructure failures of runners for TestGrid.
+func (l *RetryLog) Metadata() map[string]interface{} {
+	failures, exhausted := 0, 0
+	for _, r := range l.Runners() {
+		failures += len(r.Reasons)
+		if r.Exhausted {
+			exhausted++
+		}
+	}
+	return map[string]interface{}{
+		"runner-infra-failures":  failures,
+		"runner-infra-exhausted": exhausted,
+	}
+}
+
+// WriteJUnit records a testcase in dir for each runner with infrastructure failures, so they're reported separately
+// from failures of what the runners ran. Testcases only fail if the runner used all of its retries. Nothing is
+// written if no runner failed.
+func (l *RetryLog) WriteJUnit(dir, suffix string) error {
+	runners := l.Runners()
+	if len(runners) == 0 {
+		return nil
+	}
+
+	suite := junit.Suite{
+		Name: InfraSuiteName,
+	}
+	for _, r := range runners {
+		result := junit.Result{
+			Name:      fmt.Sprintf("%s should run despite infrastructure failures", r.Name),
+			ClassName: InfraSuiteName,
+		}
+		output := strings.Join(r.Reasons, "\n")
+		result.Output = &output
+		if r.Exhausted {
+			msg := fmt.Sprintf("%s failed for infrastructure reasons on all %d attempts", r.Name, len(r.Reasons))
+			result.Failure = &msg
+			suite.Failures++
+		}
+		suite.Tests++
+		suite.Results = append(suite.Results, result)
+	}
+
+	return junitprops.WriteSuite(dir, "runner_infra", suffix, suite)
+}
+
+// backoff waits before attempt, doubling RetryBackoff with each attempt. false is returned if the runner is stopped
+// first.
+func (r *Runner) backoff(attempt int) bool {
+	d := r.RetryBackoff * time.Duration(1<<uint(attempt-1))
+	r.Printf("Retrying %s runner in %v...", r.Name, d)
+	select {
+	case <-r.stopCh:
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
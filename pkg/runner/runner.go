@@ -2,11 +2,14 @@
 package runner
 
 import (
+	"errors"
 	"log"
 	"os"
+	"time"
 
 	image "github.com/openshift/client-go/image/clientset/versioned"
 	kubev1 "k8s.io/api/core/v1"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	kube "k8s.io/client-go/kubernetes"
 )
@@ -81,6 +84,16 @@ type Runner struct {
 	// Auth defines how to connect to a cluster.
 	AuthConfig
 
+	// MaxRetries is how many times the runner Pod is recreated after failing for infrastructure reasons, such as its
+	// image failing to pull or its node being preempted.
+	MaxRetries int
+
+	// RetryBackoff is how long to wait before recreating the runner Pod, doubling with each retry.
+	RetryBackoff time.Duration
+
+	// Timeout is how long what's run may take, given to harnesses in harness.TimeoutEnv. It's unlimited if 0.
+	Timeout time.Duration
+
 	// Logger receives all messages.
 	*log.Logger
 
@@ -103,8 +116,47 @@ func (r *Runner) Run(stopCh <-chan struct{}) (err error) {
 	}
 	log.Printf("Using '%s' as image for runner", r.ImageName)
 
-	log.Printf("Creating %s runner Pod...", r.Name)
 	var pod *kubev1.Pod
+
+	// don't leave the runner behind if it's stopped before finishing
+	defer func() {
+		if err != nil && r.stopped() && pod != nil {
+			r.cleanup(pod)
+		}
+	}()
+
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && !r.backoff(attempt) {
+			return errors.New("runner was stopped before it could be retried")
+		}
+
+		if pod, err = r.start(); err == nil {
+			break
+		}
+
+		infraErr, ok := err.(*InfraError)
+		if !ok {
+			return
+		}
+		exhausted := attempt >= r.MaxRetries
+		InfraRetries.record(r.Name, infraErr.Reason, exhausted)
+		if exhausted {
+			r.Printf("%s runner failed for infrastructure reasons %d times, giving up: %v", r.Name, attempt+1, err)
+			return
+		}
+		r.Printf("%s runner failed for infrastructure reasons: %v", r.Name, err)
+		r.deletePod(pod)
+	}
+
+	log.Printf("%s runner is done", r.Name)
+	r.status = StatusDone
+	return nil
+}
+
+// start creates the runner Pod and waits for its results to be served. An *InfraError is returned if the Pod fails
+// because of the cluster rather than what it ran.
+func (r *Runner) start() (pod *kubev1.Pod, err error) {
+	log.Printf("Creating %s runner Pod...", r.Name)
 	if pod, err = r.createPod(); err != nil {
 		return
 	}
@@ -115,19 +167,45 @@ func (r *Runner) Run(stopCh <-chan struct{}) (err error) {
 	}
 	r.status = StatusRunning
 
-	log.Printf("Creating service for %s runner Pod...", r.Name)
-	if r.svc, err = r.createService(pod); err != nil {
-		return
+	if r.svc == nil {
+		log.Printf("Creating service for %s runner Pod...", r.Name)
+		if r.svc, err = r.createService(pod); err != nil {
+			return
+		}
 	}
 
 	log.Printf("Waiting for endpoints of %s runner Pod...", r.Name)
-	if err = r.waitForEndpoints(); err != nil {
-		return
+	err = r.waitForEndpoints(pod)
+	return
+}
+
+// stopped returns true if the stop channel of the runner has been closed.
+func (r *Runner) stopped() bool {
+	select {
+	case <-r.stopCh:
+		return true
+	default:
+		return false
 	}
+}
 
-	log.Printf("%s runner is done", r.Name)
-	r.status = StatusDone
-	return nil
+// cleanup deletes the Pod and Service of the runner.
+func (r *Runner) cleanup(pod *kubev1.Pod) {
+	log.Printf("Runner %s was stopped, deleting its Pod and Service...", r.Name)
+	r.deletePod(pod)
+
+	if r.svc != nil {
+		if err := r.Kube.CoreV1().Services(r.svc.Namespace).Delete(r.svc.Name, &metav1.DeleteOptions{}); err != nil && !kerror.IsNotFound(err) {
+			log.Printf("Failed to delete %s runner Service '%s/%s': %v", r.Name, r.svc.Namespace, r.svc.Name, err)
+		}
+	}
+}
+
+// deletePod removes a Pod of the runner.
+func (r *Runner) deletePod(pod *kubev1.Pod) {
+	if err := r.Kube.CoreV1().Pods(pod.Namespace).Delete(pod.Name, &metav1.DeleteOptions{}); err != nil && !kerror.IsNotFound(err) {
+		log.Printf("Failed to delete %s runner Pod '%s/%s': %v", r.Name, pod.Namespace, pod.Name, err)
+	}
 }
 
 // Status returns the current state of the runner.
@@ -1,17 +1,26 @@
 package runner
 
 import (
+	"encoding/xml"
 	"fmt"
+	"io/ioutil"
 	"math/rand"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	. "github.com/onsi/gomega"
 
 	image "github.com/openshift/client-go/image/clientset/versioned"
+	kubev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	ktesting "k8s.io/client-go/testing"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/test-infra/testgrid/metadata/junit"
 )
 
 const (
@@ -86,3 +95,119 @@ func randomStr(length int) (str string) {
 	}
 	return
 }
+
+func TestRunStopped(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	// setup runner with mock client
+	client := fake.NewSimpleClientset()
+	r := DefaultRunner.DeepCopy()
+	r.Kube = client
+	r.Name = "runner-stopped"
+	r.Namespace = "default"
+	r.ImageName = "runner-image"
+
+	// stop before the Pod can start
+	stopCh := make(chan struct{})
+	close(stopCh)
+	err := r.Run(stopCh)
+	g.Expect(err).To(HaveOccurred())
+
+	// the Pod should be removed
+	pods, err := client.CoreV1().Pods(r.Namespace).List(metav1.ListOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(pods.Items).To(BeEmpty())
+}
+
+func TestRunInfraRetries(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	// every Pod created is unable to pull its image
+	client := fake.NewSimpleClientset()
+	created := 0
+	client.PrependReactor("create", "pods", func(action ktesting.Action) (bool, runtime.Object, error) {
+		pod := action.(ktesting.CreateAction).GetObject().(*kubev1.Pod)
+		created++
+		pod.Name = fmt.Sprintf("%s%d", pod.GenerateName, created)
+		pod.Status.ContainerStatuses = []kubev1.ContainerStatus{
+			{
+				Name: "runner-infra",
+				State: kubev1.ContainerState{
+					Waiting: &kubev1.ContainerStateWaiting{Reason: "ImagePullBackOff"},
+				},
+			},
+		}
+		return false, nil, nil
+	})
+
+	r := DefaultRunner.DeepCopy()
+	r.Kube = client
+	r.Name = "runner-infra"
+	r.Namespace = "default"
+	r.ImageName = "runner-image"
+	r.MaxRetries = 1
+	r.RetryBackoff = time.Millisecond
+
+	err := r.Run(make(chan struct{}))
+	g.Expect(err).To(BeAssignableToTypeOf(&InfraError{}))
+	g.Expect(created).To(Equal(2), "runner should be retried once")
+
+	retries := InfraRetries.Runners()
+	g.Expect(retries).To(HaveLen(1))
+	g.Expect(retries[0].Name).To(Equal("runner-infra"))
+	g.Expect(retries[0].Reasons).To(HaveLen(2))
+	g.Expect(retries[0].Exhausted).To(BeTrue())
+
+	// retried Pods should be removed
+	pods, err := client.CoreV1().Pods(r.Namespace).List(metav1.ListOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(pods.Items).To(HaveLen(1))
+}
+
+func TestInfraFailure(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	pod := &kubev1.Pod{}
+	pod.Status.Phase = kubev1.PodFailed
+	pod.Status.Reason = "Evicted"
+	_, ok := infraFailure(pod)
+	g.Expect(ok).To(BeTrue(), "evicted Pods failed because of the cluster")
+
+	pod.Status.Reason = ""
+	pod.Status.ContainerStatuses = []kubev1.ContainerStatus{
+		{State: kubev1.ContainerState{Terminated: &kubev1.ContainerStateTerminated{ExitCode: 1}}},
+	}
+	_, ok = infraFailure(pod)
+	g.Expect(ok).To(BeFalse(), "Pods exiting with errors failed because of what they ran")
+}
+
+func TestRetryLogWriteJUnit(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	dir, err := ioutil.TempDir("", "runner-retries")
+	g.Expect(err).NotTo(HaveOccurred())
+	defer os.RemoveAll(dir)
+
+	l := new(RetryLog)
+	g.Expect(l.WriteJUnit(dir, "test")).To(Succeed())
+	files, err := ioutil.ReadDir(dir)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(files).To(BeEmpty(), "nothing should be written without retries")
+
+	l.record("recovered", "node lost", false)
+	l.record("exhausted", "image pull backoff", false)
+	l.record("exhausted", "image pull backoff", true)
+	g.Expect(l.WriteJUnit(dir, "test")).To(Succeed())
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "junit_runner_infra_test.xml"))
+	g.Expect(err).NotTo(HaveOccurred())
+	var suite junit.Suite
+	g.Expect(xml.Unmarshal(data, &suite)).To(Succeed())
+	g.Expect(suite.Tests).To(Equal(2))
+	g.Expect(suite.Failures).To(Equal(1))
+
+	g.Expect(l.Metadata()).To(Equal(map[string]interface{}{
+		"runner-infra-failures":  3,
+		"runner-infra-exhausted": 1,
+	}))
+}
@@ -9,6 +9,8 @@ import (
 	"k8s.io/apimachinery/pkg/util/wait"
 )
 
+const endpointsPollInterval = 15 * time.Second
+
 func (r *Runner) createService(pod *kubev1.Pod) (svc *kubev1.Service, err error) {
 	var ports []kubev1.ServicePort
 	for _, c := range pod.Spec.Containers {
@@ -30,9 +32,13 @@ func (r *Runner) createService(pod *kubev1.Pod) (svc *kubev1.Service, err error)
 	})
 }
 
-func (r *Runner) waitForEndpoints() error {
+func (r *Runner) waitForEndpoints(pod *kubev1.Pod) error {
 	var endpoints *kubev1.Endpoints
 	endpointsReadyCondition := func() (done bool, err error) {
+		if err = r.checkPod(pod); err != nil {
+			return
+		}
+
 		endpoints, err = r.Kube.CoreV1().Endpoints(r.svc.Namespace).Get(r.svc.Name, metav1.GetOptions{})
 		if err != nil && !kerror.IsNotFound(err) {
 			r.Printf("Encountered error getting endpoint '%s/%s': %v", r.svc.Namespace, r.svc.Name, err)
@@ -43,8 +49,8 @@ func (r *Runner) waitForEndpoints() error {
 				}
 			}
 		}
-		r.Printf("Waiting for test results using Endpoint '%s/%s'...", endpoints.Namespace, endpoints.Name)
+		r.Printf("Waiting for test results using Endpoint '%s/%s'...", r.svc.Namespace, r.svc.Name)
 		return false, nil
 	}
-	return wait.PollImmediateUntil(15*time.Second, endpointsReadyCondition, r.stopCh)
+	return wait.PollImmediateUntil(endpointsPollInterval, endpointsReadyCondition, r.stopCh)
 }
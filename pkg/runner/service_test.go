@@ -18,9 +18,18 @@ func TestResultsService(t *testing.T) {
 	r := &def
 	r.Kube = client
 
+	// create runner Pod
+	pod, err := client.CoreV1().Pods(r.Namespace).Create(&kubev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "runner",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create example pod: %v", err)
+	}
+
 	// create results service
-	var err error
-	r.svc, err = r.createService(new(kubev1.Pod))
+	r.svc, err = r.createService(pod)
 	if err != nil {
 		t.Fatalf("Failed to create example service: %v", err)
 	}
@@ -28,9 +37,9 @@ func TestResultsService(t *testing.T) {
 	// start waiting for endpoint Ready
 	done := make(chan struct{})
 	go func() {
-		err := r.waitForEndpoints()
+		err := r.waitForEndpoints(pod)
 		if err != nil {
-			t.Fatalf("Failed waiting for endpoints: %v", err)
+			t.Errorf("Failed waiting for endpoints: %v", err)
 		}
 		done <- struct{}{}
 	}()
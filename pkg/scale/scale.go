@@ -0,0 +1,171 @@
+// Package scale reads the KPIs of scale runs from kube-burner results, such as pod startup and API latencies, and checks
+// them against thresholds so scale jobs can gate.
+package scale
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/test-infra/testgrid/metadata/junit"
+
+	"github.com/openshift/osde2e/pkg/junitprops"
+)
+
+// SuiteName is the JUnit suite containing a testcase for each KPI of a scale run.
+const SuiteName = "Scale KPIs"
+
+// quantileStats are the statistics of kube-burner quantile measurements, which are in milliseconds.
+var quantileStats = []string{"P99", "P95", "P50", "max", "avg"}
+
+// KPIs are the values of a scale run by name. Quantile measurements of kube-burner, such as pod latencies, are named
+// '<metricName>.<quantileName>.<stat>' like 'podLatencyQuantilesMeasurement.Ready.P99' and are in milliseconds.
+// Prometheus metrics collected by kube-burner are named by their metricName, like 'API99thLatency', and are the highest
+// value of any of their series in the metric's units.
+type KPIs map[string]float64
+
+// Load reads the KPIs of every kube-burner JSON result in dir. Files which aren't kube-burner results are ignored.
+func Load(dir string) (KPIs, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	kpis := KPIs{}
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't read scale results '%s': %v", file, err)
+		}
+		if err = kpis.Add(data); err != nil {
+			return nil, fmt.Errorf("couldn't parse scale results '%s': %v", file, err)
+		}
+	}
+	return kpis, nil
+}
+
+// Add records the KPIs of a kube-burner result, which is a list of documents. Results which aren't lists are ignored.
+func (k KPIs) Add(data []byte) error {
+	if trimmed := strings.TrimSpace(string(data)); !strings.HasPrefix(trimmed, "[") {
+		return nil
+	}
+
+	// documents are either quantile measurements or samples of Prometheus metrics
+	var docs []map[string]interface{}
+	if err := json.Unmarshal(data, &docs); err != nil {
+		return err
+	}
+	for _, doc := range docs {
+		metric, _ := doc["metricName"].(string)
+		if metric == "" {
+			continue
+		}
+
+		if quantile, _ := doc["quantileName"].(string); quantile != "" {
+			for _, stat := range quantileStats {
+				if v, ok := doc[stat].(float64); ok {
+					k.max(fmt.Sprintf("%s.%s.%s", metric, quantile, stat), v)
+				}
+			}
+		} else if v, ok := doc["value"].(float64); ok {
+			k.max(metric, v)
+		}
+	}
+	return nil
+}
+
+// max records v as name if it's higher than what was recorded.
+func (k KPIs) max(name string, v float64) {
+	if current, ok := k[name]; !ok || v > current {
+		k[name] = v
+	}
+}
+
+// ParseThresholds reads the highest acceptable value of KPIs by name.
+func ParseThresholds(thresholds map[string]string) (map[string]float64, error) {
+	parsed := make(map[string]float64, len(thresholds))
+	for name, s := range thresholds {
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid threshold '%s' for KPI '%s': %v", s, name, err)
+		}
+		parsed[name] = v
+	}
+	return parsed, nil
+}
+
+// Result is a KPI checked against its threshold.
+type Result struct {
+	KPI   string
+	Value float64
+	Found bool
+
+	// Threshold is the highest acceptable value. KPIs without one always pass.
+	Threshold *float64
+}
+
+// Passed returns whether the KPI was found and is within its threshold.
+func (r Result) Passed() bool {
+	if r.Threshold == nil {
+		return true
+	}
+	return r.Found && r.Value <= *r.Threshold
+}
+
+// Check returns a result for every KPI and every threshold, sorted by KPI. KPIs with thresholds which weren't found
+// fail.
+func Check(kpis KPIs, thresholds map[string]float64) []Result {
+	var results []Result
+	for name, v := range kpis {
+		results = append(results, Result{KPI: name, Value: v, Found: true})
+	}
+	for name := range thresholds {
+		if _, ok := kpis[name]; !ok {
+			results = append(results, Result{KPI: name})
+		}
+	}
+	for i := range results {
+		if t, ok := thresholds[results[i].KPI]; ok {
+			results[i].Threshold = &t
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool { return results[i].KPI < results[j].KPI })
+	return results
+}
+
+// WriteJUnit records a testcase for each KPI in dir, failing those which exceed their threshold or weren't found.
+func WriteJUnit(dir, suffix string, results []Result) error {
+	suite := junit.Suite{
+		Name:  SuiteName,
+		Tests: len(results),
+	}
+	for _, r := range results {
+		result := junit.Result{
+			Name:      fmt.Sprintf("[scale] %s should be within its threshold", r.KPI),
+			ClassName: SuiteName,
+		}
+
+		output := fmt.Sprintf("%s is %g", r.KPI, r.Value)
+		if r.Threshold != nil {
+			output += fmt.Sprintf(", threshold is %g", *r.Threshold)
+		}
+		result.Output = &output
+
+		if !r.Passed() {
+			msg := fmt.Sprintf("%s is %g, above its threshold of %g", r.KPI, r.Value, *r.Threshold)
+			if !r.Found {
+				msg = fmt.Sprintf("%s wasn't found in the scale results", r.KPI)
+			}
+			result.Failure = &msg
+			suite.Failures++
+		}
+		suite.Results = append(suite.Results, result)
+	}
+
+	return junitprops.WriteSuite(dir, "scale", suffix, suite)
+}
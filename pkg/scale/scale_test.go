@@ -0,0 +1,132 @@
+package scale
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"k8s.io/test-infra/testgrid/metadata/junit"
+)
+
+const (
+	podLatency = `[
+  {"metricName": "podLatencyQuantilesMeasurement", "quantileName": "Ready", "P99": 3000, "P95": 2500, "P50": 1000, "max": 3200, "avg": 1200},
+  {"metricName": "podLatencyQuantilesMeasurement", "quantileName": "Scheduled", "P99": 20, "P50": 5}
+]`
+
+	apiLatency = `[
+  {"metricName": "API99thLatency", "value": 0.5, "labels": {"verb": "GET"}},
+  {"metricName": "API99thLatency", "value": 0.8, "labels": {"verb": "LIST"}},
+  {"metricName": "API99thLatency", "value": 0.2, "labels": {"verb": "POST"}}
+]`
+
+	jobSummary = `{"jobConfig": {"name": "cluster-density"}, "elapsedTime": 120}`
+)
+
+func TestLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "scale")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"podLatency.json":   podLatency,
+		"apiLatency.json":   apiLatency,
+		"jobSummary.json":   jobSummary,
+		"kube-burner.log":   "not a result",
+		"nonJSONResult.txt": "[",
+	}
+	for name, data := range files {
+		if err = ioutil.WriteFile(filepath.Join(dir, name), []byte(data), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	kpis, err := Load(dir)
+	if err != nil {
+		t.Fatalf("failed to load results: %v", err)
+	}
+	expected := KPIs{
+		"podLatencyQuantilesMeasurement.Ready.P99":     3000,
+		"podLatencyQuantilesMeasurement.Ready.P95":     2500,
+		"podLatencyQuantilesMeasurement.Ready.P50":     1000,
+		"podLatencyQuantilesMeasurement.Ready.max":     3200,
+		"podLatencyQuantilesMeasurement.Ready.avg":     1200,
+		"podLatencyQuantilesMeasurement.Scheduled.P99": 20,
+		"podLatencyQuantilesMeasurement.Scheduled.P50": 5,
+		"API99thLatency": 0.8,
+	}
+	if !reflect.DeepEqual(kpis, expected) {
+		t.Errorf("expected KPIs %v, got %v", expected, kpis)
+	}
+
+	if err = ioutil.WriteFile(filepath.Join(dir, "broken.json"), []byte("[{"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = Load(dir); err == nil {
+		t.Error("expected broken results to fail loading")
+	}
+}
+
+func TestParseThresholds(t *testing.T) {
+	thresholds, err := ParseThresholds(map[string]string{"API99thLatency": "1", "podLatencyQuantilesMeasurement.Ready.P99": "5000"})
+	if err != nil {
+		t.Fatalf("failed to parse thresholds: %v", err)
+	}
+	expected := map[string]float64{"API99thLatency": 1, "podLatencyQuantilesMeasurement.Ready.P99": 5000}
+	if !reflect.DeepEqual(thresholds, expected) {
+		t.Errorf("expected thresholds %v, got %v", expected, thresholds)
+	}
+
+	if _, err = ParseThresholds(map[string]string{"API99thLatency": "1s"}); err == nil {
+		t.Error("expected invalid threshold to fail parsing")
+	}
+}
+
+func TestCheck(t *testing.T) {
+	kpis := KPIs{"API99thLatency": 1.5, "podLatencyQuantilesMeasurement.Ready.P99": 3000, "unchecked": 10}
+	thresholds := map[string]float64{"API99thLatency": 1, "podLatencyQuantilesMeasurement.Ready.P99": 5000, "missing": 1}
+
+	results := Check(kpis, thresholds)
+	passed := map[string]bool{}
+	for _, r := range results {
+		passed[r.KPI] = r.Passed()
+	}
+	expected := map[string]bool{
+		"API99thLatency": false,
+		"missing":        false,
+		"podLatencyQuantilesMeasurement.Ready.P99": true,
+		"unchecked": true,
+	}
+	if !reflect.DeepEqual(passed, expected) {
+		t.Errorf("expected results %v, got %v", expected, passed)
+	}
+	if results[0].KPI != "API99thLatency" || results[len(results)-1].KPI != "unchecked" {
+		t.Errorf("expected results sorted by KPI, got %+v", results)
+	}
+
+	dir, err := ioutil.TempDir("", "scale")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err = WriteJUnit(dir, "test", results); err != nil {
+		t.Fatalf("failed to write results: %v", err)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dir, "junit_scale_test.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var suite junit.Suite
+	if err = xml.Unmarshal(data, &suite); err != nil {
+		t.Fatal(err)
+	}
+	if suite.Tests != 4 || suite.Failures != 2 {
+		t.Errorf("expected 2 of 4 KPIs to fail, got %+v", suite)
+	}
+}
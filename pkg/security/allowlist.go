@@ -0,0 +1,104 @@
+// Package security evaluates workloads against the privileges they are expected to use.
+package security
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Allowlist specifies which managed namespaces are checked and the privileges their workloads may use.
+type Allowlist struct {
+	// Namespaces are regular expressions matching managed namespaces.
+	Namespaces []string `json:"namespaces"`
+
+	// SCCs may be used by any pod in a managed namespace.
+	SCCs []string `json:"sccs"`
+
+	// Allowed are workloads permitted to use additional privileges.
+	Allowed []Allowed `json:"allowed"`
+
+	namespaces []*regexp.Regexp
+}
+
+// Allowed permits pods matching a namespace and name to use features and SCCs.
+type Allowed struct {
+	// Namespace is a regular expression matching the namespace of pods.
+	Namespace string `json:"namespace"`
+
+	// Pod is a regular expression matching the name of pods.
+	Pod string `json:"pod"`
+
+	// Features are the privileged features the pods may use.
+	Features []Feature `json:"features"`
+
+	// SCCs are the SecurityContextConstraints the pods may be admitted by.
+	SCCs []string `json:"sccs"`
+
+	namespace, pod *regexp.Regexp
+}
+
+// LoadAllowlist reads the YAML allowlist from file.
+func LoadAllowlist(file string) (*Allowlist, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read allowlist '%s': %v", file, err)
+	}
+	return ParseAllowlist(data)
+}
+
+// ParseAllowlist decodes a YAML allowlist.
+func ParseAllowlist(data []byte) (*Allowlist, error) {
+	a := new(Allowlist)
+	if err := yaml.Unmarshal(data, a); err != nil {
+		return nil, fmt.Errorf("couldn't decode allowlist: %v", err)
+	}
+
+	for _, ns := range a.Namespaces {
+		re, err := regexp.Compile(ns)
+		if err != nil {
+			return nil, fmt.Errorf("invalid namespace pattern '%s': %v", ns, err)
+		}
+		a.namespaces = append(a.namespaces, re)
+	}
+
+	for i := range a.Allowed {
+		allowed := &a.Allowed[i]
+		for _, f := range allowed.Features {
+			if !f.Valid() {
+				return nil, fmt.Errorf("unknown feature '%s' allowed for '%s/%s'", f, allowed.Namespace, allowed.Pod)
+			}
+		}
+
+		var err error
+		if allowed.namespace, err = regexp.Compile(allowed.Namespace); err != nil {
+			return nil, fmt.Errorf("invalid namespace pattern '%s': %v", allowed.Namespace, err)
+		}
+		if allowed.pod, err = regexp.Compile(allowed.Pod); err != nil {
+			return nil, fmt.Errorf("invalid pod pattern '%s': %v", allowed.Pod, err)
+		}
+	}
+	return a, nil
+}
+
+// Managed returns true if the namespace is checked.
+func (a *Allowlist) Managed(namespace string) bool {
+	for _, re := range a.namespaces {
+		if re.MatchString(namespace) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowed returns the entries matching the pod.
+func (a *Allowlist) allowed(namespace, pod string) (matches []Allowed) {
+	for _, allowed := range a.Allowed {
+		if allowed.namespace.MatchString(namespace) && allowed.pod.MatchString(pod) {
+			matches = append(matches, allowed)
+		}
+	}
+	return
+}
@@ -0,0 +1,145 @@
+package security
+
+import (
+	"fmt"
+
+	kubev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// SCCAnnotation is set on pods to the SecurityContextConstraints that admitted them.
+	SCCAnnotation = "openshift.io/scc"
+)
+
+// Feature is a privilege used by a workload.
+type Feature string
+
+const (
+	// Privileged containers run with host privileges.
+	Privileged Feature = "privileged"
+
+	// HostPath volumes mount directories from the host.
+	HostPath Feature = "hostPath"
+
+	// HostNetwork pods use the host's network namespace.
+	HostNetwork Feature = "hostNetwork"
+
+	// HostPID pods use the host's process namespace.
+	HostPID Feature = "hostPID"
+
+	// HostIPC pods use the host's IPC namespace.
+	HostIPC Feature = "hostIPC"
+)
+
+// Features are all known privileged features.
+var Features = []Feature{Privileged, HostPath, HostNetwork, HostPID, HostIPC}
+
+// Valid returns true if the feature is known.
+func (f Feature) Valid() bool {
+	for _, known := range Features {
+		if f == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Violation is use of a privilege not on the allowlist.
+type Violation struct {
+	Namespace string
+	Pod       string
+
+	// Container is empty for privileges used by the whole pod.
+	Container string
+
+	// Feature is set when a privileged feature is used.
+	Feature Feature
+
+	// SCC is set when the pod was admitted by an unexpected SecurityContextConstraints.
+	SCC string
+}
+
+func (v Violation) String() string {
+	name := v.Namespace + "/" + v.Pod
+	if v.Container != "" {
+		name += "/" + v.Container
+	}
+
+	if v.SCC != "" {
+		return fmt.Sprintf("%s: admitted by SCC '%s'", name, v.SCC)
+	}
+	return fmt.Sprintf("%s: uses %s", name, v.Feature)
+}
+
+// Check returns the privileges used by pod that aren't allowed. Pods outside of managed namespaces aren't checked.
+func (a *Allowlist) Check(pod kubev1.Pod) (violations []Violation) {
+	if !a.Managed(pod.Namespace) {
+		return
+	}
+
+	features, sccs := map[Feature]bool{}, map[string]bool{}
+	for _, scc := range a.SCCs {
+		sccs[scc] = true
+	}
+	for _, allowed := range a.allowed(pod.Namespace, pod.Name) {
+		for _, f := range allowed.Features {
+			features[f] = true
+		}
+		for _, scc := range allowed.SCCs {
+			sccs[scc] = true
+		}
+	}
+
+	violation := func(container string, f Feature) {
+		if !features[f] {
+			violations = append(violations, Violation{
+				Namespace: pod.Namespace,
+				Pod:       pod.Name,
+				Container: container,
+				Feature:   f,
+			})
+		}
+	}
+
+	// mirror pods for static pods aren't admitted by an SCC
+	if scc, ok := pod.Annotations[SCCAnnotation]; ok && !sccs[scc] {
+		violations = append(violations, Violation{
+			Namespace: pod.Namespace,
+			Pod:       pod.Name,
+			SCC:       scc,
+		})
+	}
+
+	spec := pod.Spec
+	if spec.HostNetwork {
+		violation("", HostNetwork)
+	}
+	if spec.HostPID {
+		violation("", HostPID)
+	}
+	if spec.HostIPC {
+		violation("", HostIPC)
+	}
+
+	hostPaths := map[string]bool{}
+	for _, vol := range spec.Volumes {
+		if vol.HostPath != nil {
+			hostPaths[vol.Name] = true
+		}
+	}
+
+	containers := append(append([]kubev1.Container{}, spec.InitContainers...), spec.Containers...)
+	for _, c := range containers {
+		if sc := c.SecurityContext; sc != nil && sc.Privileged != nil && *sc.Privileged {
+			violation(c.Name, Privileged)
+		}
+
+		for _, mount := range c.VolumeMounts {
+			if hostPaths[mount.Name] {
+				violation(c.Name, HostPath)
+				break
+			}
+		}
+	}
+	return
+}
@@ -0,0 +1,111 @@
+package security
+
+import (
+	"reflect"
+	"testing"
+
+	kubev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const testAllowlist = `
+namespaces:
+- ^openshift-
+sccs:
+- restricted
+allowed:
+- namespace: ^openshift-sdn$
+  pod: ^sdn-
+  features: [privileged, hostNetwork]
+  sccs: [privileged]
+`
+
+func TestCheck(t *testing.T) {
+	allowlist, err := ParseAllowlist([]byte(testAllowlist))
+	if err != nil {
+		t.Fatalf("Failed parsing allowlist: %v", err)
+	}
+
+	privileged := true
+	pod := func(namespace, name, scc string) kubev1.Pod {
+		return kubev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      name,
+				Annotations: map[string]string{
+					SCCAnnotation: scc,
+				},
+			},
+			Spec: kubev1.PodSpec{
+				HostNetwork: true,
+				Volumes: []kubev1.Volume{
+					{
+						Name: "host",
+						VolumeSource: kubev1.VolumeSource{
+							HostPath: &kubev1.HostPathVolumeSource{Path: "/etc"},
+						},
+					},
+				},
+				Containers: []kubev1.Container{
+					{
+						Name: "main",
+						SecurityContext: &kubev1.SecurityContext{
+							Privileged: &privileged,
+						},
+						VolumeMounts: []kubev1.VolumeMount{
+							{Name: "host", MountPath: "/host"},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		pod      kubev1.Pod
+		expected []Violation
+	}{
+		{
+			name: "unmanaged namespace",
+			pod:  pod("customer", "app", "privileged"),
+		},
+		{
+			name: "allowed pod",
+			pod:  pod("openshift-sdn", "sdn-abcde", "privileged"),
+			expected: []Violation{
+				{Namespace: "openshift-sdn", Pod: "sdn-abcde", Container: "main", Feature: HostPath},
+			},
+		},
+		{
+			name: "not allowed pod",
+			pod:  pod("openshift-console", "console-abcde", "anyuid"),
+			expected: []Violation{
+				{Namespace: "openshift-console", Pod: "console-abcde", SCC: "anyuid"},
+				{Namespace: "openshift-console", Pod: "console-abcde", Feature: HostNetwork},
+				{Namespace: "openshift-console", Pod: "console-abcde", Container: "main", Feature: Privileged},
+				{Namespace: "openshift-console", Pod: "console-abcde", Container: "main", Feature: HostPath},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		if violations := allowlist.Check(test.pod); !reflect.DeepEqual(violations, test.expected) {
+			t.Errorf("%s: expected violations %v, got %v", test.name, test.expected, violations)
+		}
+	}
+}
+
+func TestParseAllowlistInvalid(t *testing.T) {
+	invalid := []string{
+		"namespaces: ['(']",
+		"allowed: [{namespace: a, pod: b, features: [root]}]",
+		"allowed: [{namespace: a, pod: '['}]",
+	}
+
+	for _, data := range invalid {
+		if _, err := ParseAllowlist([]byte(data)); err == nil {
+			t.Errorf("allowlist '%s' should be invalid", data)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+// Package seed derives the random choices of a run, such as its suffix and the names of test namespaces, from a
+// single seed so a failed run can be repeated with the same choices by setting RUN_SEED.
+//
+// Each kind of choice is made from its own stream, so making more choices of one kind doesn't change the others.
+package seed
+
+import (
+	"hash/fnv"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+const (
+	// Suffix is the stream the suffix of runs is chosen from.
+	Suffix = "suffix"
+
+	// Namespaces is the stream the names of test namespaces are chosen from.
+	Namespaces = "namespaces"
+
+	// Tenants is the stream the names of runs sharing a cluster are chosen from.
+	Tenants = "tenants"
+
+	// Workloads is the stream the data of upgrade workloads is chosen from.
+	Workloads = "workloads"
+
+	// chars are used in random strings.
+	chars = "0123456789abcdefghijklmnopqrstuvwxyz"
+)
+
+var (
+	mu      sync.Mutex
+	current = Generate()
+	streams = map[string]*rand.Rand{}
+)
+
+// Generate returns a new seed from the current time.
+func Generate() int64 {
+	return time.Now().UnixNano()
+}
+
+// Set makes all further choices from seed, restarting every stream.
+func Set(seed int64) {
+	mu.Lock()
+	defer mu.Unlock()
+	current, streams = seed, map[string]*rand.Rand{}
+}
+
+// Get returns the seed choices are made from.
+func Get() int64 {
+	mu.Lock()
+	defer mu.Unlock()
+	return current
+}
+
+// String returns a random string of length lowercase letters and digits from stream.
+func String(stream string, length int) string {
+	mu.Lock()
+	defer mu.Unlock()
+
+	r, str := source(stream), make([]byte, length)
+	for i := range str {
+		str[i] = chars[r.Intn(len(chars))]
+	}
+	return string(str)
+}
+
+// Int63 returns a random non-negative int64 from stream.
+func Int63(stream string) int64 {
+	mu.Lock()
+	defer mu.Unlock()
+	return source(stream).Int63()
+}
+
+// source returns the generator of stream, seeded from the current seed and its name. mu must be held.
+func source(stream string) *rand.Rand {
+	r, ok := streams[stream]
+	if !ok {
+		h := fnv.New64a()
+		h.Write([]byte(stream))
+		r = rand.New(rand.NewSource(current ^ int64(h.Sum64())))
+		streams[stream] = r
+	}
+	return r
+}
@@ -0,0 +1,49 @@
+package seed
+
+import (
+	"testing"
+)
+
+func TestRepeatable(t *testing.T) {
+	Set(42)
+	suffix, ns, marker := String(Suffix, 3), String(Namespaces, 5), Int63(Workloads)
+	if len(suffix) != 3 || len(ns) != 5 {
+		t.Fatalf("expected strings of the requested length, got '%s' and '%s'", suffix, ns)
+	}
+
+	// choices are the same when the seed is reused, even if streams are consumed in a different order
+	Set(42)
+	if m := Int63(Workloads); m != marker {
+		t.Errorf("expected workload marker %d, got %d", marker, m)
+	}
+	if n := String(Namespaces, 5); n != ns {
+		t.Errorf("expected namespace '%s', got '%s'", ns, n)
+	}
+	if s := String(Suffix, 3); s != suffix {
+		t.Errorf("expected suffix '%s', got '%s'", suffix, s)
+	}
+	if Get() != 42 {
+		t.Errorf("expected seed 42, got %d", Get())
+	}
+
+	Set(43)
+	if String(Suffix, 3) == suffix && String(Namespaces, 5) == ns {
+		t.Error("expected a different seed to make different choices")
+	}
+}
+
+func TestStreams(t *testing.T) {
+	Set(7)
+	first := String(Namespaces, 8)
+	second := String(Namespaces, 8)
+	if first == second {
+		t.Errorf("expected a stream to continue, got '%s' twice", first)
+	}
+
+	// consuming one stream doesn't change another
+	Set(7)
+	String(Suffix, 100)
+	if ns := String(Namespaces, 8); ns != first {
+		t.Errorf("expected namespace '%s' regardless of the suffix stream, got '%s'", first, ns)
+	}
+}
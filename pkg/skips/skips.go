@@ -0,0 +1,191 @@
+// Package skips classifies why specs were skipped, so skips can be reported and tracked across runs instead of
+// hiding what wasn't tested.
+package skips
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/onsi/ginkgo"
+	ginkgoconfig "github.com/onsi/ginkgo/config"
+	"github.com/onsi/ginkgo/types"
+
+	"github.com/openshift/osde2e/pkg/junitprops"
+)
+
+// Reason is why a spec was skipped.
+type Reason string
+
+const (
+	// CapabilityMissing specs test something the cluster doesn't provide, such as a storage feature of its cloud.
+	CapabilityMissing Reason = "capability-missing"
+
+	// Quarantined specs failed but are known to be broken.
+	Quarantined Reason = "quarantined"
+
+	// ConfigExcluded specs weren't selected by the configuration of the run.
+	ConfigExcluded Reason = "config-excluded"
+
+	// DependencyFailed specs couldn't run because something they rely on failed first.
+	DependencyFailed Reason = "dependency-failed"
+
+	// OverBudget specs didn't run because their group had spent its time budget.
+	OverBudget Reason = "over-budget"
+
+	// Unclassified specs were skipped without a reason.
+	Unclassified Reason = "unclassified"
+)
+
+// Reasons are all known reasons, in the order they're reported.
+var Reasons = []Reason{CapabilityMissing, Quarantined, ConfigExcluded, DependencyFailed, OverBudget, Unclassified}
+
+const (
+	// PropertyReason is set on skipped testcases to why they were skipped.
+	PropertyReason = "skip-reason"
+
+	// PropertyMessage is set on skipped testcases to the message they were skipped with.
+	PropertyMessage = "skip-message"
+)
+
+// messageRe matches skip messages created by Message.
+var messageRe = regexp.MustCompile(`^\[([a-z-]+)\] (.*)$`)
+
+// Skip skips the current spec for reason, describing why with message. callerSkip is passed to Ginkgo.
+func Skip(reason Reason, message string, callerSkip ...int) {
+	skip := 1
+	if len(callerSkip) > 0 {
+		skip += callerSkip[0]
+	}
+	ginkgo.Skip(Message(reason, message), skip)
+}
+
+// Message prefixes message with reason so it can be parsed from the spec's summary.
+func Message(reason Reason, message string) string {
+	return fmt.Sprintf("[%s] %s", reason, message)
+}
+
+// Parse returns the reason and message of a skip message. Messages not created by Message are Unclassified.
+func Parse(skipMessage string) (Reason, string) {
+	if m := messageRe.FindStringSubmatch(skipMessage); m != nil {
+		for _, r := range Reasons {
+			if Reason(m[1]) == r {
+				return r, m[2]
+			}
+		}
+	}
+	return Unclassified, skipMessage
+}
+
+// Skipped is a spec that was skipped.
+type Skipped struct {
+	Reason  Reason
+	Message string
+}
+
+// Recorder is a Ginkgo reporter recording why specs were skipped by their name in JUnit.
+type Recorder struct {
+	mu      sync.Mutex
+	skipped map[string]Skipped
+}
+
+// Skipped returns the specs skipped so far by name.
+func (r *Recorder) Skipped() map[string]Skipped {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	skipped := make(map[string]Skipped, len(r.skipped))
+	for name, s := range r.skipped {
+		skipped[name] = s
+	}
+	return skipped
+}
+
+// Counts returns how many specs were skipped for each reason.
+func (r *Recorder) Counts() map[Reason]int {
+	counts := map[Reason]int{}
+	for _, s := range r.Skipped() {
+		counts[s.Reason]++
+	}
+	return counts
+}
+
+// Metadata counts skipped specs by reason for TestGrid.
+func (r *Recorder) Metadata() map[string]interface{} {
+	counts := r.Counts()
+	meta := make(map[string]interface{}, len(Reasons))
+	for _, reason := range Reasons {
+		meta["skipped-"+string(reason)] = counts[reason]
+	}
+	return meta
+}
+
+// Summary describes how many specs were skipped for each reason, such as '3 capability-missing, 1 quarantined'.
+func (r *Recorder) Summary() string {
+	counts := r.Counts()
+	var parts []string
+	for _, reason := range Reasons {
+		if counts[reason] != 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[reason], reason))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// AnnotateJUnit sets why each skipped testcase in the JUnit file was skipped as properties.
+func (r *Recorder) AnnotateJUnit(file string) error {
+	props := map[string]map[string]string{}
+	for name, s := range r.Skipped() {
+		props[name] = map[string]string{
+			PropertyReason:  string(s.Reason),
+			PropertyMessage: s.Message,
+		}
+	}
+	if len(props) == 0 {
+		return nil
+	}
+	return junitprops.AnnotateTests(file, props)
+}
+
+// SpecSuiteWillBegin does nothing.
+func (r *Recorder) SpecSuiteWillBegin(config ginkgoconfig.GinkgoConfigType, summary *types.SuiteSummary) {
+}
+
+// BeforeSuiteDidRun does nothing.
+func (r *Recorder) BeforeSuiteDidRun(summary *types.SetupSummary) {}
+
+// SpecWillRun does nothing.
+func (r *Recorder) SpecWillRun(summary *types.SpecSummary) {}
+
+// SpecDidComplete records why the spec was skipped, if it was. Specs skipped or pending without a message weren't
+// selected to run.
+func (r *Recorder) SpecDidComplete(summary *types.SpecSummary) {
+	if !summary.Skipped() && !summary.Pending() {
+		return
+	}
+
+	s := Skipped{Reason: ConfigExcluded, Message: "not selected to run"}
+	if summary.Failure.Message != "" {
+		s.Reason, s.Message = Parse(summary.Failure.Message)
+	}
+
+	// the first component is the top level container
+	texts := summary.ComponentTexts
+	if len(texts) > 1 {
+		texts = texts[1:]
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.skipped == nil {
+		r.skipped = map[string]Skipped{}
+	}
+	r.skipped[strings.Join(texts, " ")] = s
+}
+
+// AfterSuiteDidRun does nothing.
+func (r *Recorder) AfterSuiteDidRun(summary *types.SetupSummary) {}
+
+// SpecSuiteDidEnd does nothing.
+func (r *Recorder) SpecSuiteDidEnd(summary *types.SuiteSummary) {}
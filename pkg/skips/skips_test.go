@@ -0,0 +1,56 @@
+package skips
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/onsi/ginkgo/types"
+)
+
+func TestParse(t *testing.T) {
+	for _, test := range []struct {
+		message, expectedMessage string
+		expected                 Reason
+	}{
+		{Message(CapabilityMissing, "no snapshots"), "no snapshots", CapabilityMissing},
+		{Message(Reason("unknown"), "no snapshots"), "[unknown] no snapshots", Unclassified},
+		{"LOAD_TEST is not set", "LOAD_TEST is not set", Unclassified},
+	} {
+		if reason, msg := Parse(test.message); reason != test.expected || msg != test.expectedMessage {
+			t.Errorf("'%s': expected %s '%s', got %s '%s'", test.message, test.expected, test.expectedMessage,
+				reason, msg)
+		}
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	r := new(Recorder)
+	r.SpecDidComplete(&types.SpecSummary{
+		ComponentTexts: []string{"[Top Level]", "Pods", "should be Running"},
+		State:          types.SpecStatePassed,
+	})
+	r.SpecDidComplete(&types.SpecSummary{
+		ComponentTexts: []string{"[Top Level]", "Storage", "should expand volumes"},
+		State:          types.SpecStateSkipped,
+		Failure:        types.SpecFailure{Message: Message(CapabilityMissing, "expansion isn't allowed")},
+	})
+	r.SpecDidComplete(&types.SpecSummary{
+		ComponentTexts: []string{"[Top Level]", "Load", "should be fast"},
+		State:          types.SpecStateSkipped,
+	})
+
+	expected := map[string]Skipped{
+		"Storage should expand volumes": {Reason: CapabilityMissing, Message: "expansion isn't allowed"},
+		"Load should be fast":           {Reason: ConfigExcluded, Message: "not selected to run"},
+	}
+	if skipped := r.Skipped(); !reflect.DeepEqual(skipped, expected) {
+		t.Errorf("expected skipped %v, got %v", expected, skipped)
+	}
+
+	if summary := r.Summary(); summary != "1 capability-missing, 1 config-excluded" {
+		t.Errorf("expected summary of skips, got '%s'", summary)
+	}
+	if meta := r.Metadata(); meta["skipped-capability-missing"] != 1 || meta["skipped-quarantined"] != 0 {
+		t.Errorf("expected skips counted by reason, got %v", meta)
+	}
+}
@@ -0,0 +1,134 @@
+package slack
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	ginkgoconfig "github.com/onsi/ginkgo/config"
+	"github.com/onsi/ginkgo/types"
+)
+
+// ProgressStep is the percentage of specs completed between progress updates.
+const ProgressStep = 20
+
+// Progress posts updates about a run to a Slack thread. Nothing is posted until the run has lasted longer than the
+// threshold, then updates made before it are posted together. A nil Progress ignores updates.
+type Progress struct {
+	client    *Client
+	title     string
+	threshold time.Duration
+	start     time.Time
+
+	mu      sync.Mutex
+	ts      string
+	pending []string
+}
+
+// NewProgress starts tracking a run identified by title.
+func NewProgress(client *Client, title string, threshold time.Duration) *Progress {
+	return &Progress{
+		client:    client,
+		title:     title,
+		threshold: threshold,
+		start:     time.Now(),
+	}
+}
+
+// Update reports a transition in the run. Failures posting are logged.
+func (p *Progress) Update(format string, args ...interface{}) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	elapsed := time.Since(p.start)
+	p.pending = append(p.pending, fmt.Sprintf("[%s] %s", elapsed.Round(time.Second), fmt.Sprintf(format, args...)))
+	if elapsed < p.threshold {
+		return
+	}
+
+	if p.ts == "" {
+		ts, err := p.client.PostMessage(fmt.Sprintf("%s has been running for %s, following along in this thread.", p.title, elapsed.Round(time.Minute)), "")
+		if err != nil {
+			log.Printf("Failed to post progress to Slack: %v", err)
+			return
+		}
+		p.ts = ts
+	}
+
+	for len(p.pending) != 0 {
+		if _, err := p.client.PostMessage(p.pending[0], p.ts); err != nil {
+			log.Printf("Failed to post progress to Slack: %v", err)
+			return
+		}
+		p.pending = p.pending[1:]
+	}
+}
+
+// Reporter updates progress as specs complete.
+type Reporter struct {
+	Progress *Progress
+
+	total, completed, failed int
+	nextStep                 int
+}
+
+// SpecSuiteWillBegin records the number of specs to be run.
+func (r *Reporter) SpecSuiteWillBegin(config ginkgoconfig.GinkgoConfigType, summary *types.SuiteSummary) {
+	r.total = summary.NumberOfSpecsThatWillBeRun
+	r.nextStep = ProgressStep
+}
+
+// BeforeSuiteDidRun reports failures setting up.
+func (r *Reporter) BeforeSuiteDidRun(summary *types.SetupSummary) {
+	if summary.State.IsFailure() {
+		r.Progress.Update("Setup failed: %s", summary.Failure.Message)
+	}
+}
+
+// SpecWillRun does nothing.
+func (r *Reporter) SpecWillRun(summary *types.SpecSummary) {}
+
+// SpecDidComplete reports progress each time another ProgressStep percent of specs have completed.
+func (r *Reporter) SpecDidComplete(summary *types.SpecSummary) {
+	if summary.Skipped() || summary.Pending() {
+		return
+	}
+
+	r.completed++
+	if summary.State.IsFailure() {
+		r.failed++
+	}
+
+	if r.total == 0 {
+		return
+	}
+
+	percent := r.completed * 100 / r.total
+	if percent >= r.nextStep && percent < 100 {
+		r.Progress.Update("e2e %d%% complete (%d/%d specs, %d failed)", percent, r.completed, r.total, r.failed)
+		for r.nextStep <= percent {
+			r.nextStep += ProgressStep
+		}
+	}
+}
+
+// AfterSuiteDidRun reports failures tearing down.
+func (r *Reporter) AfterSuiteDidRun(summary *types.SetupSummary) {
+	if summary.State.IsFailure() {
+		r.Progress.Update("Teardown failed: %s", summary.Failure.Message)
+	}
+}
+
+// SpecSuiteDidEnd reports the result of the suite.
+func (r *Reporter) SpecSuiteDidEnd(summary *types.SuiteSummary) {
+	result := "passed"
+	if !summary.SuiteSucceeded {
+		result = "failed"
+	}
+	r.Progress.Update("e2e %s (%d specs, %d failed)", result, summary.NumberOfSpecsThatWillBeRun, summary.NumberOfFailedSpecs)
+}
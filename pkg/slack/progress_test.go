@@ -0,0 +1,64 @@
+package slack
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestProgress(t *testing.T) {
+	var posted []postMessage
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/"+postMessageMethod {
+			t.Errorf("unexpected request path '%s'", r.URL.Path)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer token" {
+			t.Errorf("unexpected Authorization header '%s'", auth)
+		}
+
+		var msg postMessage
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			t.Fatalf("Failed decoding message: %v", err)
+		}
+		posted = append(posted, msg)
+
+		json.NewEncoder(w).Encode(postMessageResponse{
+			OK: true,
+			TS: strconv.Itoa(len(posted)),
+		})
+	}))
+	defer srv.Close()
+
+	client := NewClient("token", "#osde2e")
+	client.APIURL = srv.URL
+
+	// short runs shouldn't post
+	p := NewProgress(client, "test run", time.Hour)
+	p.Update("provisioned")
+	if len(posted) != 0 {
+		t.Fatalf("nothing should be posted before the threshold, got %v", posted)
+	}
+
+	// pending updates are posted once the threshold is passed
+	p.threshold = 0
+	p.Update("healthy")
+	if len(posted) != 3 {
+		t.Fatalf("expected start message and 2 updates, got %v", posted)
+	}
+
+	if posted[0].ThreadTS != "" || posted[0].Channel != "#osde2e" {
+		t.Errorf("start message should be posted to channel, got %+v", posted[0])
+	}
+	for _, msg := range posted[1:] {
+		if msg.ThreadTS != "1" {
+			t.Errorf("updates should be posted in thread '1', got %+v", msg)
+		}
+	}
+
+	// a nil Progress ignores updates
+	var nilProgress *Progress
+	nilProgress.Update("ignored")
+}
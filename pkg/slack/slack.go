@@ -0,0 +1,93 @@
+// Package slack posts messages to Slack using the Web API.
+package slack
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	// DefaultAPIURL is the Slack Web API.
+	DefaultAPIURL = "https://slack.com/api"
+
+	postMessageMethod = "chat.postMessage"
+)
+
+// Client posts messages to a channel.
+type Client struct {
+	// APIURL is the base URL of the Slack Web API.
+	APIURL string
+
+	// Token is a bot token with the chat:write scope.
+	Token string
+
+	// Channel is the ID or name of the channel messages are posted to.
+	Channel string
+
+	http *http.Client
+}
+
+// NewClient returns a client posting to channel using token.
+func NewClient(token, channel string) *Client {
+	return &Client{
+		APIURL:  DefaultAPIURL,
+		Token:   token,
+		Channel: channel,
+		http: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+type postMessage struct {
+	Channel  string `json:"channel"`
+	Text     string `json:"text"`
+	ThreadTS string `json:"thread_ts,omitempty"`
+}
+
+type postMessageResponse struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+	TS    string `json:"ts"`
+}
+
+// PostMessage posts text to the channel, replying in the thread of threadTS if set.
+// The timestamp identifying the posted message is returned.
+func (c *Client) PostMessage(text, threadTS string) (string, error) {
+	data, err := json.Marshal(postMessage{
+		Channel:  c.Channel,
+		Text:     text,
+		ThreadTS: threadTS,
+	})
+	if err != nil {
+		return "", fmt.Errorf("couldn't encode message: %v", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.APIURL+"/"+postMessageMethod, bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("couldn't create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", "Bearer "+c.Token)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("couldn't post message: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("posting message returned status '%s'", resp.Status)
+	}
+
+	var msgResp postMessageResponse
+	if err = json.NewDecoder(resp.Body).Decode(&msgResp); err != nil {
+		return "", fmt.Errorf("couldn't decode response: %v", err)
+	} else if !msgResp.OK {
+		return "", fmt.Errorf("posting message failed: %s", msgResp.Error)
+	}
+	return msgResp.TS, nil
+}
@@ -0,0 +1,50 @@
+package sts
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// discoveryPath is where OIDC providers serve their configuration, relative to the issuer.
+const discoveryPath = "/.well-known/openid-configuration"
+
+// CheckIssuer verifies issuer publicly serves the OIDC discovery document and signing keys AWS uses to trust service
+// account tokens, requesting them with get. Operator roles can't be assumed if it doesn't.
+func CheckIssuer(get func(url string) ([]byte, error), issuer string) error {
+	if !strings.HasPrefix(issuer, "https://") {
+		issuer = "https://" + issuer
+	}
+	issuer = strings.TrimSuffix(issuer, "/")
+
+	var discovery struct {
+		Issuer  string `json:"issuer"`
+		JWKSURI string `json:"jwks_uri"`
+	}
+	if err := getJSON(get, issuer+discoveryPath, &discovery); err != nil {
+		return fmt.Errorf("couldn't get OIDC configuration of issuer '%s': %v", issuer, err)
+	} else if strings.TrimSuffix(discovery.Issuer, "/") != issuer {
+		return fmt.Errorf("OIDC configuration is for issuer '%s' instead of '%s'", discovery.Issuer, issuer)
+	} else if discovery.JWKSURI == "" {
+		return fmt.Errorf("OIDC configuration of issuer '%s' has no signing keys", issuer)
+	}
+
+	var keys struct {
+		Keys []json.RawMessage `json:"keys"`
+	}
+	if err := getJSON(get, discovery.JWKSURI, &keys); err != nil {
+		return fmt.Errorf("couldn't get signing keys of issuer '%s': %v", issuer, err)
+	} else if len(keys.Keys) == 0 {
+		return fmt.Errorf("issuer '%s' serves no signing keys", issuer)
+	}
+	return nil
+}
+
+// getJSON decodes the body of url, requested with get, into out.
+func getJSON(get func(url string) ([]byte, error), url string, out interface{}) error {
+	body, err := get(url)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, out)
+}
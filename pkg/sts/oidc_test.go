@@ -0,0 +1,50 @@
+package sts
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCheckIssuer(t *testing.T) {
+	keys := `{"keys": [{"kty": "RSA", "kid": "1"}]}`
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		issuer := "https://" + r.Host
+		switch r.URL.Path {
+		case discoveryPath:
+			fmt.Fprintf(w, `{"issuer": "%s", "jwks_uri": "%s/keys.json"}`, issuer, issuer)
+		case "/keys.json":
+			fmt.Fprint(w, keys)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	get := func(url string) ([]byte, error) {
+		resp, err := srv.Client().Get(url)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("got status %s", resp.Status)
+		}
+		return ioutil.ReadAll(resp.Body)
+	}
+
+	if err := CheckIssuer(get, srv.URL); err != nil {
+		t.Errorf("expected issuer to pass: %v", err)
+	}
+
+	keys = `{"keys": []}`
+	if err := CheckIssuer(get, srv.URL); err == nil {
+		t.Error("expected issuer without signing keys to fail")
+	}
+
+	if err := CheckIssuer(get, srv.URL+"/other"); err == nil {
+		t.Error("expected issuer without OIDC configuration to fail")
+	}
+}
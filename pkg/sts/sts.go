@@ -0,0 +1,143 @@
+// Package sts checks clusters using AWS Security Token Service (STS) give workloads short-lived credentials by
+// assuming IAM roles, rather than static access keys.
+package sts
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	kubev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// CredentialsKey is the key of credentials secrets containing an AWS shared credentials file.
+	CredentialsKey = "credentials"
+
+	// roleARNKey is the role assumed by an AWS shared credentials file.
+	roleARNKey = "role_arn"
+
+	// tokenFileKey is the service account token exchanged for credentials of the role.
+	tokenFileKey = "web_identity_token_file"
+)
+
+// staticKeys are keys of secrets and AWS shared credentials files which hold long-lived access keys.
+var staticKeys = []string{"aws_access_key_id", "aws_secret_access_key"}
+
+// roleARN matches the ARNs of IAM roles.
+var roleARN = regexp.MustCompile(`^arn:aws[a-z-]*:iam::[0-9]{12}:role/.+$`)
+
+// Credentials are the keys of the default profile of an AWS shared credentials file.
+type Credentials map[string]string
+
+// ParseCredentials reads the default profile of an AWS shared credentials file.
+func ParseCredentials(data []byte) (Credentials, error) {
+	creds := Credentials{}
+	profile := "default"
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") || strings.HasPrefix(text, ";") {
+			continue
+		} else if strings.HasPrefix(text, "[") && strings.HasSuffix(text, "]") {
+			profile = strings.TrimSpace(text[1 : len(text)-1])
+			continue
+		}
+
+		parts := strings.SplitN(text, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("line %d isn't a key = value pair", line)
+		} else if profile == "default" {
+			creds[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+		}
+	}
+	return creds, scanner.Err()
+}
+
+// Problems describes why the credentials don't assume a role using a service account token.
+func (c Credentials) Problems() (problems []string) {
+	if arn := c[roleARNKey]; arn == "" {
+		problems = append(problems, "no role is assumed")
+	} else if !roleARN.MatchString(arn) {
+		problems = append(problems, fmt.Sprintf("'%s' isn't the ARN of an IAM role", arn))
+	}
+	if c[tokenFileKey] == "" {
+		problems = append(problems, "no service account token is used to assume the role")
+	}
+	for _, k := range staticKeys {
+		if c[k] != "" {
+			problems = append(problems, fmt.Sprintf("static key %s is set", k))
+		}
+	}
+	return
+}
+
+// StaticKeys returns the keys of secret holding long-lived AWS access keys, either directly or in an AWS shared
+// credentials file.
+func StaticKeys(secret *kubev1.Secret) (keys []string) {
+	for _, k := range staticKeys {
+		if len(secret.Data[k]) != 0 {
+			keys = append(keys, k)
+		}
+	}
+
+	if data, ok := secret.Data[CredentialsKey]; ok {
+		// files which can't be parsed aren't AWS credentials
+		if creds, err := ParseCredentials(data); err == nil {
+			for _, k := range staticKeys {
+				if creds[k] != "" {
+					keys = append(keys, CredentialsKey+"/"+k)
+				}
+			}
+		}
+	}
+	return
+}
+
+// MountsSecret returns true if pod reads secret from a volume or its environment.
+func MountsSecret(pod *kubev1.Pod, secret string) bool {
+	for _, v := range pod.Spec.Volumes {
+		if v.Secret != nil && v.Secret.SecretName == secret {
+			return true
+		} else if v.Projected != nil {
+			for _, source := range v.Projected.Sources {
+				if source.Secret != nil && source.Secret.Name == secret {
+					return true
+				}
+			}
+		}
+	}
+
+	containers := append(append([]kubev1.Container(nil), pod.Spec.InitContainers...), pod.Spec.Containers...)
+	for _, c := range containers {
+		for _, from := range c.EnvFrom {
+			if from.SecretRef != nil && from.SecretRef.Name == secret {
+				return true
+			}
+		}
+		for _, env := range c.Env {
+			if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil && env.ValueFrom.SecretKeyRef.Name == secret {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// TokenAudiences returns the audiences of service account tokens projected into pod.
+func TokenAudiences(pod *kubev1.Pod) (audiences []string) {
+	for _, v := range pod.Spec.Volumes {
+		if v.Projected == nil {
+			continue
+		}
+		for _, source := range v.Projected.Sources {
+			if source.ServiceAccountToken != nil {
+				audiences = append(audiences, source.ServiceAccountToken.Audience)
+			}
+		}
+	}
+	return
+}
@@ -0,0 +1,102 @@
+package sts
+
+import (
+	"reflect"
+	"testing"
+
+	kubev1 "k8s.io/api/core/v1"
+)
+
+const stsCredentials = `[default]
+role_arn = arn:aws:iam::123456789012:role/osde2e-openshift-image-registry
+web_identity_token_file = /var/run/secrets/openshift/serviceaccount/token
+`
+
+const staticCredentials = `# created by the installer
+[default]
+aws_access_key_id = AKIAEXAMPLE
+aws_secret_access_key = secret
+
+[other]
+role_arn = arn:aws:iam::123456789012:role/other
+`
+
+func TestCredentials(t *testing.T) {
+	creds, err := ParseCredentials([]byte(stsCredentials))
+	if err != nil {
+		t.Fatalf("failed parsing credentials: %v", err)
+	} else if problems := creds.Problems(); len(problems) != 0 {
+		t.Errorf("expected no problems with STS credentials, got %q", problems)
+	}
+
+	if creds, err = ParseCredentials([]byte(staticCredentials)); err != nil {
+		t.Fatalf("failed parsing credentials: %v", err)
+	}
+	expected := []string{
+		"no role is assumed",
+		"no service account token is used to assume the role",
+		"static key aws_access_key_id is set",
+		"static key aws_secret_access_key is set",
+	}
+	if problems := creds.Problems(); !reflect.DeepEqual(problems, expected) {
+		t.Errorf("expected problems %q, got %q", expected, problems)
+	}
+
+	if _, err = ParseCredentials([]byte("[default]\nrole_arn\n")); err == nil {
+		t.Error("expected lines without values to fail parsing")
+	}
+}
+
+func TestStaticKeys(t *testing.T) {
+	secret := &kubev1.Secret{
+		Data: map[string][]byte{
+			"aws_access_key_id": []byte("AKIAEXAMPLE"),
+			CredentialsKey:      []byte(staticCredentials),
+		},
+	}
+	expected := []string{"aws_access_key_id", "credentials/aws_access_key_id", "credentials/aws_secret_access_key"}
+	if keys := StaticKeys(secret); !reflect.DeepEqual(keys, expected) {
+		t.Errorf("expected static keys %q, got %q", expected, keys)
+	}
+
+	secret.Data = map[string][]byte{CredentialsKey: []byte(stsCredentials)}
+	if keys := StaticKeys(secret); len(keys) != 0 {
+		t.Errorf("expected STS credentials to have no static keys, got %q", keys)
+	}
+}
+
+func TestMountsSecret(t *testing.T) {
+	pod := &kubev1.Pod{
+		Spec: kubev1.PodSpec{
+			Volumes: []kubev1.Volume{
+				{
+					Name: "bound-sa-token",
+					VolumeSource: kubev1.VolumeSource{
+						Projected: &kubev1.ProjectedVolumeSource{
+							Sources: []kubev1.VolumeProjection{
+								{ServiceAccountToken: &kubev1.ServiceAccountTokenProjection{Audience: "openshift"}},
+							},
+						},
+					},
+				},
+			},
+			Containers: []kubev1.Container{
+				{
+					EnvFrom: []kubev1.EnvFromSource{
+						{SecretRef: &kubev1.SecretEnvSource{LocalObjectReference: kubev1.LocalObjectReference{Name: "installer-cloud-credentials"}}},
+					},
+				},
+			},
+		},
+	}
+
+	if !MountsSecret(pod, "installer-cloud-credentials") {
+		t.Error("expected secret in environment to be mounted")
+	} else if MountsSecret(pod, "other") {
+		t.Error("expected other secrets not to be mounted")
+	}
+
+	if audiences := TokenAudiences(pod); !reflect.DeepEqual(audiences, []string{"openshift"}) {
+		t.Errorf("expected token for audience openshift, got %q", audiences)
+	}
+}
@@ -0,0 +1,173 @@
+package synthetics
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/test-infra/testgrid/metadata/junit"
+
+	"github.com/openshift/osde2e/pkg/junitprops"
+)
+
+const (
+	// SuiteName is the JUnit suite containing probe results.
+	SuiteName = "OSD synthetics"
+
+	// DNSCheck resolves the target service.
+	DNSCheck = "dns"
+
+	// PodCheck requests the target service from the prober pod.
+	PodCheck = "pod"
+
+	// RouteCheck requests the target through its route.
+	RouteCheck = "route"
+
+	// missedIntervals is how many intervals may pass without a result before it's considered a gap.
+	missedIntervals = 3
+)
+
+// Checks are performed by the prober every interval.
+var Checks = []string{DNSCheck, PodCheck, RouteCheck}
+
+// Result is the outcome of a single check.
+type Result struct {
+	Time   time.Time
+	Check  string
+	OK     bool
+	Detail string
+}
+
+// ParseResult decodes a line printed by the prober of the form '<time> <check> ok|fail [detail]'.
+func ParseResult(line string) (r Result, ok bool) {
+	parts := strings.SplitN(strings.TrimSpace(line), " ", 4)
+	if len(parts) < 3 || (parts[2] != "ok" && parts[2] != "fail") {
+		return r, false
+	}
+
+	var err error
+	if r.Time, err = time.Parse(time.RFC3339, parts[0]); err != nil {
+		return r, false
+	}
+	r.Check, r.OK = parts[1], parts[2] == "ok"
+	if len(parts) == 4 {
+		r.Detail = parts[3]
+	}
+	return r, true
+}
+
+// Gap is a period a check was failing or not being performed.
+type Gap struct {
+	Check      string
+	Start, End time.Time
+
+	// Reason describes why the check was unavailable, such as the first failure.
+	Reason string
+}
+
+// Duration is how long the gap lasted.
+func (g Gap) Duration() time.Duration {
+	return g.End.Sub(g.Start)
+}
+
+// Gaps finds periods in results where each check failed or wasn't reported for several intervals, in order of start.
+func Gaps(results []Result, interval time.Duration) (gaps []Gap) {
+	byCheck := map[string][]Result{}
+	for _, r := range results {
+		byCheck[r.Check] = append(byCheck[r.Check], r)
+	}
+
+	for check, rs := range byCheck {
+		sort.SliceStable(rs, func(i, j int) bool {
+			return rs[i].Time.Before(rs[j].Time)
+		})
+
+		var failing *Gap
+		for i, r := range rs {
+			if i > 0 && failing == nil && r.Time.Sub(rs[i-1].Time) > missedIntervals*interval {
+				gaps = append(gaps, Gap{
+					Check:  check,
+					Start:  rs[i-1].Time,
+					End:    r.Time,
+					Reason: "no results from prober",
+				})
+			}
+
+			if !r.OK && failing == nil {
+				failing = &Gap{Check: check, Start: r.Time, End: r.Time, Reason: r.Detail}
+			} else if failing != nil {
+				failing.End = r.Time
+				if r.OK {
+					gaps = append(gaps, *failing)
+					failing = nil
+				}
+			}
+		}
+		if failing != nil {
+			gaps = append(gaps, *failing)
+		}
+	}
+
+	sort.SliceStable(gaps, func(i, j int) bool {
+		if gaps[i].Start.Equal(gaps[j].Start) {
+			return gaps[i].Check < gaps[j].Check
+		}
+		return gaps[i].Start.Before(gaps[j].Start)
+	})
+	return
+}
+
+// Event is something that happened during the run which may explain a gap, such as a failed test or an upgrade.
+type Event struct {
+	Name       string
+	Start, End time.Time
+}
+
+// Correlate returns the events overlapping gap.
+func Correlate(gap Gap, events []Event) (overlapping []Event) {
+	for _, e := range events {
+		if !e.Start.After(gap.End) && !e.End.Before(gap.Start) {
+			overlapping = append(overlapping, e)
+		}
+	}
+	return
+}
+
+// WriteJUnit records a testcase for each check in dir, failing those with gaps. Failures list the events each gap
+// overlaps.
+func WriteJUnit(dir, suffix string, gaps []Gap, events []Event) error {
+	suite := junit.Suite{
+		Name:  SuiteName,
+		Tests: len(Checks),
+	}
+	for _, check := range Checks {
+		result := junit.Result{
+			Name:      fmt.Sprintf("[synthetics] %s", check),
+			ClassName: SuiteName,
+		}
+
+		var lines []string
+		for _, g := range gaps {
+			if g.Check != check {
+				continue
+			}
+
+			line := fmt.Sprintf("unavailable for %v from %s: %s", g.Duration(), g.Start.UTC().Format(time.RFC3339), g.Reason)
+			for _, e := range Correlate(g, events) {
+				line += fmt.Sprintf("\n  during %s", e.Name)
+			}
+			lines = append(lines, line)
+		}
+
+		if len(lines) != 0 {
+			msg := fmt.Sprintf("%d gaps in availability", len(lines))
+			output := strings.Join(lines, "\n")
+			result.Failure, result.Output = &msg, &output
+			suite.Failures++
+		}
+		suite.Results = append(suite.Results, result)
+	}
+
+	return junitprops.WriteSuite(dir, "synthetics", suffix, suite)
+}
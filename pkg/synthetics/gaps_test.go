@@ -0,0 +1,87 @@
+package synthetics
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var start = time.Date(2019, 10, 5, 2, 0, 0, 0, time.UTC)
+
+// at returns a result for check n intervals of 10 seconds after start.
+func at(n int, check string, ok bool) Result {
+	return Result{Time: start.Add(time.Duration(n) * 10 * time.Second), Check: check, OK: ok, Detail: "refused"}
+}
+
+func TestParseResult(t *testing.T) {
+	r, ok := ParseResult("2019-10-05T02:00:00Z route fail curl: (7) Failed to connect")
+	if !ok || !r.Time.Equal(start) || r.Check != RouteCheck || r.OK || r.Detail != "curl: (7) Failed to connect" {
+		t.Errorf("unexpected result: %+v", r)
+	}
+
+	for _, line := range []string{"", "2019-10-05T02:00:00Z dns", "2019-10-05T02:00:00Z dns maybe", "yesterday dns ok"} {
+		if _, ok = ParseResult(line); ok {
+			t.Errorf("expected '%s' not to parse", line)
+		}
+	}
+}
+
+func TestGaps(t *testing.T) {
+	results := []Result{
+		at(0, DNSCheck, true), at(0, RouteCheck, true),
+		at(1, DNSCheck, true), at(1, RouteCheck, false),
+		at(2, DNSCheck, true), at(2, RouteCheck, false),
+		at(3, DNSCheck, true), at(3, RouteCheck, true),
+		// prober was rescheduled
+		at(10, DNSCheck, true), at(10, RouteCheck, false),
+	}
+
+	gaps := Gaps(results, 10*time.Second)
+	if len(gaps) != 4 {
+		t.Fatalf("expected 4 gaps, got %+v", gaps)
+	}
+
+	if g := gaps[0]; g.Check != RouteCheck || g.Duration() != 20*time.Second || g.Reason != "refused" {
+		t.Errorf("expected route to fail for 20s, got %+v", g)
+	}
+	for _, g := range gaps[1:3] {
+		if !g.Start.Equal(at(3, "", true).Time) || g.Duration() != 70*time.Second {
+			t.Errorf("expected gap without results, got %+v", g)
+		}
+	}
+	if g := gaps[3]; g.Check != RouteCheck || g.Duration() != 0 {
+		t.Errorf("expected trailing failure, got %+v", g)
+	}
+}
+
+func TestWriteJUnit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "synthetics")
+	if err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	gaps := Gaps([]Result{at(0, PodCheck, true), at(1, PodCheck, false), at(2, PodCheck, true)}, 10*time.Second)
+	events := []Event{
+		{Name: "[upgrade] hop 1 to 4.2.2", Start: start, End: start.Add(time.Hour)},
+		{Name: "failed test 'Routes should be reachable'", Start: start.Add(time.Hour), End: start.Add(2 * time.Hour)},
+	}
+	if err = WriteJUnit(dir, "abc", gaps, events); err != nil {
+		t.Fatalf("failed to write JUnit: %v", err)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "junit_synthetics_abc.xml"))
+	if err != nil {
+		t.Fatalf("failed to read JUnit: %v", err)
+	}
+	junit := string(data)
+	if !strings.Contains(junit, `failures="1"`) || !strings.Contains(junit, "during [upgrade] hop 1 to 4.2.2") {
+		t.Errorf("expected pod gap during upgrade, got: %s", junit)
+	}
+	if strings.Contains(junit, "Routes should be reachable") {
+		t.Errorf("expected events outside gaps to be ignored, got: %s", junit)
+	}
+}
@@ -0,0 +1,263 @@
+// Package synthetics runs a workload throughout a run that continuously probes DNS, routes, and pod-to-pod traffic,
+// reporting gaps in availability alongside the test failures and upgrades they overlap.
+package synthetics
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	routev1 "github.com/openshift/api/route/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	kubev1 "k8s.io/api/core/v1"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/openshift/osde2e/pkg/helper"
+)
+
+const (
+	// Namespace is where the prober and its target run.
+	Namespace = "osde2e-synthetics"
+
+	// TargetImage serves HTTP on targetPort for the prober to reach.
+	TargetImage = "docker.io/openshift/hello-openshift"
+
+	// ProberImage runs the probe script. It must include bash, curl, and getent.
+	ProberImage = "registry.access.redhat.com/ubi8/ubi-minimal"
+
+	// CollectInterval is how often results are read from the prober, limiting how many are lost if it's rescheduled.
+	CollectInterval = time.Minute
+
+	// deployTimeout is how long to wait for the prober and its target to become available.
+	deployTimeout = 5 * time.Minute
+
+	targetName = "osde2e-target"
+	proberName = "osde2e-prober"
+	targetPort = 8080
+)
+
+// probeScript checks each target every INTERVAL seconds, printing a line per check of the form
+// '<time> <check> ok|fail [detail]'.
+const probeScript = `
+check() {
+  if out=$(eval "$2" 2>&1); then echo "$now $1 ok"; else echo "$now $1 fail $(echo $out | head -c 200)"; fi
+}
+while true; do
+  now=$(date -u +%Y-%m-%dT%H:%M:%SZ)
+  check ` + DNSCheck + ` 'getent hosts "$TARGET_SERVICE"'
+  check ` + PodCheck + ` 'curl -sSf -o /dev/null -m 5 "http://$TARGET_SERVICE:$TARGET_PORT/"'
+  check ` + RouteCheck + ` 'curl -sSf -o /dev/null -m 5 "http://$ROUTE_HOST/"'
+  sleep "$INTERVAL"
+done
+`
+
+// Prober deploys the probe workload and collects its results.
+type Prober struct {
+	h        *helper.H
+	interval time.Duration
+
+	mu      sync.Mutex
+	results []Result
+	seen    map[string]int
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// Start deploys the probe workload to the cluster accessed by h, checking every interval, and collects its results
+// until stopped.
+func Start(h *helper.H, interval time.Duration) (*Prober, error) {
+	p := &Prober{
+		h:        h,
+		interval: interval,
+		seen:     map[string]int{},
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	if err := p.deploy(); err != nil {
+		p.h.Kube().CoreV1().Namespaces().Delete(Namespace, &metav1.DeleteOptions{})
+		return nil, err
+	}
+
+	go func() {
+		defer close(p.done)
+		wait.Until(p.collect, CollectInterval, p.stop)
+	}()
+	return p, nil
+}
+
+// Stop collects the remaining results and removes the probe workload. All results collected are returned.
+func (p *Prober) Stop() ([]Result, error) {
+	close(p.stop)
+	<-p.done
+	p.collect()
+
+	err := p.h.Kube().CoreV1().Namespaces().Delete(Namespace, &metav1.DeleteOptions{})
+	if err != nil && !kerror.IsNotFound(err) {
+		err = fmt.Errorf("couldn't delete namespace '%s': %v", Namespace, err)
+	} else {
+		err = nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]Result(nil), p.results...), err
+}
+
+func (p *Prober) deploy() error {
+	kube := p.h.Kube()
+	_, err := kube.CoreV1().Namespaces().Create(&kubev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: Namespace},
+	})
+	if err != nil && !kerror.IsAlreadyExists(err) {
+		return fmt.Errorf("couldn't create namespace '%s': %v", Namespace, err)
+	}
+
+	if _, err = kube.AppsV1().Deployments(Namespace).Create(targetDeployment()); err != nil {
+		return fmt.Errorf("couldn't create deployment '%s': %v", targetName, err)
+	}
+
+	svc := &kubev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: targetName},
+		Spec: kubev1.ServiceSpec{
+			Selector: map[string]string{"app": targetName},
+			Ports: []kubev1.ServicePort{
+				{Port: targetPort, TargetPort: intstr.FromInt(targetPort)},
+			},
+		},
+	}
+	if _, err = kube.CoreV1().Services(Namespace).Create(svc); err != nil {
+		return fmt.Errorf("couldn't create service '%s': %v", targetName, err)
+	}
+
+	route, err := p.h.Route().RouteV1().Routes(Namespace).Create(&routev1.Route{
+		ObjectMeta: metav1.ObjectMeta{Name: targetName},
+		Spec: routev1.RouteSpec{
+			To: routev1.RouteTargetReference{Kind: "Service", Name: targetName},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't create route '%s': %v", targetName, err)
+	}
+
+	if _, err = kube.AppsV1().Deployments(Namespace).Create(p.proberDeployment(route.Spec.Host)); err != nil {
+		return fmt.Errorf("couldn't create deployment '%s': %v", proberName, err)
+	}
+
+	log.Printf("Waiting for synthetic probes to start against '%s'...", route.Spec.Host)
+	return p.h.PollImmediate(5*time.Second, deployTimeout, func() (bool, error) {
+		for _, name := range []string{targetName, proberName} {
+			d, err := kube.AppsV1().Deployments(Namespace).Get(name, metav1.GetOptions{})
+			if err != nil {
+				log.Printf("Error getting deployment '%s': %v", name, err)
+				return false, nil
+			} else if d.Status.AvailableReplicas == 0 {
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+}
+
+// collect reads results the prober has printed since it was last collected.
+func (p *Prober) collect() {
+	pods, err := p.h.Kube().CoreV1().Pods(Namespace).List(metav1.ListOptions{
+		LabelSelector: "app=" + proberName,
+	})
+	if err != nil {
+		log.Printf("Failed to list synthetic probe pods: %v", err)
+		return
+	}
+
+	for _, pod := range pods.Items {
+		// restarted containers start a new log
+		key := pod.Name
+		for _, status := range pod.Status.ContainerStatuses {
+			key += "/" + strconv.Itoa(int(status.RestartCount))
+		}
+
+		data, err := p.h.Kube().CoreV1().Pods(Namespace).GetLogs(pod.Name, &kubev1.PodLogOptions{
+			Container: proberName,
+		}).DoRaw()
+		if err != nil {
+			log.Printf("Failed to get logs of synthetic probe '%s': %v", pod.Name, err)
+			continue
+		}
+
+		var results []Result
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		for scanner.Scan() {
+			if r, ok := ParseResult(scanner.Text()); ok {
+				results = append(results, r)
+			}
+		}
+
+		p.mu.Lock()
+		if seen := p.seen[key]; seen < len(results) {
+			p.results = append(p.results, results[seen:]...)
+			p.seen[key] = len(results)
+		}
+		p.mu.Unlock()
+	}
+}
+
+func targetDeployment() *appsv1.Deployment {
+	replicas := int32(2)
+	labels := map[string]string{"app": targetName}
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: targetName},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: kubev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: kubev1.PodSpec{
+					Containers: []kubev1.Container{
+						{
+							Name:  targetName,
+							Image: TargetImage,
+							Ports: []kubev1.ContainerPort{{ContainerPort: targetPort}},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (p *Prober) proberDeployment(routeHost string) *appsv1.Deployment {
+	replicas := int32(1)
+	labels := map[string]string{"app": proberName}
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: proberName},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: kubev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: kubev1.PodSpec{
+					Containers: []kubev1.Container{
+						{
+							Name:    proberName,
+							Image:   ProberImage,
+							Command: []string{"/bin/bash", "-c", probeScript},
+							Env: []kubev1.EnvVar{
+								{Name: "TARGET_SERVICE", Value: targetName + "." + Namespace + ".svc.cluster.local"},
+								{Name: "TARGET_PORT", Value: strconv.Itoa(targetPort)},
+								{Name: "ROUTE_HOST", Value: routeHost},
+								{Name: "INTERVAL", Value: strconv.Itoa(int(p.interval.Seconds()))},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,314 @@
+// Package tenant lets concurrent runs share one cluster. Each run is a tenant holding a lease in a lock ConfigMap
+// while it tests, limiting how many test at once. Tenants prefix their namespaces and cluster-scoped resources with
+// their name so they don't overlap, and their namespaces are limited by a quota.
+package tenant
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	kubev1 "k8s.io/api/core/v1"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	// LockNamespace holds the lock ConfigMap.
+	LockNamespace = "osde2e-tenants"
+
+	// LockName is the ConfigMap holding the lease of each tenant, keyed by tenant with when the lease expires.
+	LockName = "osde2e-tenants"
+
+	// Label is set on namespaces to the tenant which created them.
+	Label = "osde2e.openshift.io/tenant"
+
+	// NameLength is how long the names of tenants are, enough that concurrent tenants don't pick the same one.
+	NameLength = 8
+
+	// LeaseDuration is how long a lease lasts without being renewed, so tenants of runs which stopped without leaving
+	// free their room.
+	LeaseDuration = 10 * time.Minute
+
+	// limitsName is the ResourceQuota and LimitRange created in namespaces of a tenant.
+	limitsName = "osde2e-tenant"
+
+	// joinInterval is how often to try joining a cluster without room.
+	joinInterval = 30 * time.Second
+)
+
+// defaultRequests are given to containers without requests so they can run in namespaces with a quota on requests.
+var defaultRequests = kubev1.ResourceList{
+	kubev1.ResourceCPU:    resource.MustParse("100m"),
+	kubev1.ResourceMemory: resource.MustParse("128Mi"),
+}
+
+// ErrFull is returned when joining a cluster which already has as many tenants as it allows.
+var ErrFull = errors.New("the cluster has no room for more tenants")
+
+// Current is the tenant of the run. It is nil when the cluster isn't shared.
+var Current *Tenant
+
+// Tenant is a run sharing a cluster with others.
+type Tenant struct {
+	// Name distinguishes the tenant's resources from those of other tenants.
+	Name string
+
+	// Max is how many tenants may test the cluster at once.
+	Max int
+
+	// Quota limits what each namespace of the tenant may use.
+	Quota kubev1.ResourceList
+
+	kube kubernetes.Interface
+
+	// now returns the current time, and is replaced in tests.
+	now func() time.Time
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// New returns a tenant named name of the cluster accessed with kube.
+func New(kube kubernetes.Interface, name string, max int, quota kubev1.ResourceList) *Tenant {
+	return &Tenant{
+		Name:  name,
+		Max:   max,
+		Quota: quota,
+		kube:  kube,
+		now:   time.Now,
+	}
+}
+
+// ParseQuota reads a quota from a map of resource to quantity, such as 'requests.cpu' to '2'.
+func ParseQuota(quota map[string]string) (kubev1.ResourceList, error) {
+	parsed := make(kubev1.ResourceList, len(quota))
+	for name, quantity := range quota {
+		q, err := resource.ParseQuantity(quantity)
+		if err != nil {
+			return nil, fmt.Errorf("quota of '%s' must be a quantity, got '%s': %v", name, quantity, err)
+		}
+		parsed[kubev1.ResourceName(name)] = q
+	}
+	return parsed, nil
+}
+
+// Prefix begins the names of the tenant's resources. It's 'osde2e-' when the cluster isn't shared.
+func (t *Tenant) Prefix() string {
+	if t == nil {
+		return "osde2e-"
+	}
+	return "osde2e-" + t.Name + "-"
+}
+
+// Labels are set on the tenant's resources. There are none when the cluster isn't shared.
+func (t *Tenant) Labels() map[string]string {
+	if t == nil {
+		return nil
+	}
+	return map[string]string{Label: t.Name}
+}
+
+// Limit creates the tenant's quota in namespace, along with default requests for containers which have none. Nothing
+// is done when the cluster isn't shared.
+func (t *Tenant) Limit(namespace string) error {
+	if t == nil || len(t.Quota) == 0 {
+		return nil
+	}
+
+	_, err := t.kube.CoreV1().ResourceQuotas(namespace).Create(&kubev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: limitsName, Labels: t.Labels()},
+		Spec:       kubev1.ResourceQuotaSpec{Hard: t.Quota},
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't create quota in '%s': %v", namespace, err)
+	}
+
+	_, err = t.kube.CoreV1().LimitRanges(namespace).Create(&kubev1.LimitRange{
+		ObjectMeta: metav1.ObjectMeta{Name: limitsName, Labels: t.Labels()},
+		Spec: kubev1.LimitRangeSpec{
+			Limits: []kubev1.LimitRangeItem{{
+				Type:           kubev1.LimitTypeContainer,
+				DefaultRequest: defaultRequests,
+			}},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't create default requests in '%s': %v", namespace, err)
+	}
+	return nil
+}
+
+// Join waits up to timeout for room on the cluster then takes a lease, which is renewed until the tenant leaves.
+func (t *Tenant) Join(timeout time.Duration) error {
+	err := wait.PollImmediate(joinInterval, timeout, func() (bool, error) {
+		if err := t.update(t.join); err == ErrFull {
+			log.Printf("Waiting for room on the shared cluster for tenant '%s'...", t.Name)
+			return false, nil
+		} else if err != nil {
+			return false, err
+		}
+		return true, nil
+	})
+	if err == wait.ErrWaitTimeout {
+		return fmt.Errorf("%v after waiting %v", ErrFull, timeout)
+	} else if err != nil {
+		return fmt.Errorf("couldn't join the shared cluster as '%s': %v", t.Name, err)
+	}
+
+	t.stop, t.done = make(chan struct{}), make(chan struct{})
+	go t.renew()
+	return nil
+}
+
+// Leave gives up the tenant's lease then deletes any of its namespaces which remain.
+func (t *Tenant) Leave() error {
+	if t.stop != nil {
+		close(t.stop)
+		<-t.done
+		t.stop = nil
+	}
+
+	err := t.update(func(leases map[string]time.Time) error {
+		delete(leases, t.Name)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't give up lease of '%s': %v", t.Name, err)
+	}
+
+	namespaces, err := t.kube.CoreV1().Namespaces().List(metav1.ListOptions{LabelSelector: Label + "=" + t.Name})
+	if err != nil {
+		return fmt.Errorf("couldn't list namespaces of '%s': %v", t.Name, err)
+	}
+	for _, ns := range namespaces.Items {
+		err = t.kube.CoreV1().Namespaces().Delete(ns.Name, &metav1.DeleteOptions{})
+		if err != nil && !kerror.IsNotFound(err) {
+			return fmt.Errorf("couldn't delete namespace '%s' of '%s': %v", ns.Name, t.Name, err)
+		}
+	}
+	return nil
+}
+
+// Tenants returns the names of tenants with unexpired leases.
+func (t *Tenant) Tenants() ([]string, error) {
+	cm, err := t.kube.CoreV1().ConfigMaps(LockNamespace).Get(LockName, metav1.GetOptions{})
+	if kerror.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for name, expires := range leasesOf(cm) {
+		if t.now().Before(expires) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// join takes a lease for the tenant if there's room, first dropping leases which expired.
+func (t *Tenant) join(leases map[string]time.Time) error {
+	now := t.now()
+	for name, expires := range leases {
+		if !now.Before(expires) {
+			log.Printf("Lease of tenant '%s' expired at %s, dropping it.", name, expires.Format(time.RFC3339))
+			delete(leases, name)
+		}
+	}
+
+	if _, ok := leases[t.Name]; ok {
+		return fmt.Errorf("tenant '%s' is already testing the cluster", t.Name)
+	} else if len(leases) >= t.Max {
+		return ErrFull
+	}
+	leases[t.Name] = now.Add(LeaseDuration)
+	return nil
+}
+
+// renew extends the tenant's lease until it leaves.
+func (t *Tenant) renew() {
+	defer close(t.done)
+	ticker := time.NewTicker(LeaseDuration / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-t.stop:
+			return
+		case <-ticker.C:
+			err := t.update(func(leases map[string]time.Time) error {
+				if _, ok := leases[t.Name]; !ok {
+					return fmt.Errorf("lease was lost")
+				}
+				leases[t.Name] = t.now().Add(LeaseDuration)
+				return nil
+			})
+			if err != nil {
+				log.Printf("Failed to renew lease of tenant '%s': %v", t.Name, err)
+			}
+		}
+	}
+}
+
+// update changes the leases of the lock with fn, creating the lock if it doesn't exist. Changes made concurrently
+// by other tenants cause fn to be retried with their leases.
+func (t *Tenant) update(fn func(leases map[string]time.Time) error) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cm, err := t.lock()
+		if err != nil {
+			return err
+		}
+
+		leases := leasesOf(cm)
+		if err = fn(leases); err != nil {
+			return err
+		}
+
+		cm.Data = make(map[string]string, len(leases))
+		for name, expires := range leases {
+			cm.Data[name] = expires.UTC().Format(time.RFC3339)
+		}
+		_, err = t.kube.CoreV1().ConfigMaps(LockNamespace).Update(cm)
+		return err
+	})
+}
+
+// lock returns the lock ConfigMap, creating it and its namespace if they don't exist.
+func (t *Tenant) lock() (*kubev1.ConfigMap, error) {
+	cm, err := t.kube.CoreV1().ConfigMaps(LockNamespace).Get(LockName, metav1.GetOptions{})
+	if !kerror.IsNotFound(err) {
+		return cm, err
+	}
+
+	_, err = t.kube.CoreV1().Namespaces().Create(&kubev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: LockNamespace},
+	})
+	if err != nil && !kerror.IsAlreadyExists(err) {
+		return nil, fmt.Errorf("couldn't create namespace '%s': %v", LockNamespace, err)
+	}
+
+	cm, err = t.kube.CoreV1().ConfigMaps(LockNamespace).Create(&kubev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: LockName},
+	})
+	if kerror.IsAlreadyExists(err) {
+		return t.kube.CoreV1().ConfigMaps(LockNamespace).Get(LockName, metav1.GetOptions{})
+	}
+	return cm, err
+}
+
+// leasesOf returns when the lease of each tenant in the lock expires. Leases which can't be read have expired.
+func leasesOf(cm *kubev1.ConfigMap) map[string]time.Time {
+	leases := make(map[string]time.Time, len(cm.Data))
+	for name, expires := range cm.Data {
+		leases[name], _ = time.Parse(time.RFC3339, expires)
+	}
+	return leases
+}
@@ -0,0 +1,114 @@
+package tenant
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	kubev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newTenant(kube *fake.Clientset, name string, now time.Time) *Tenant {
+	t := New(kube, name, 2, nil)
+	t.now = func() time.Time { return now }
+	return t
+}
+
+func TestJoin(t *testing.T) {
+	kube := fake.NewSimpleClientset()
+	now := time.Now()
+
+	for _, name := range []string{"abc", "def"} {
+		if err := newTenant(kube, name, now).update(newTenant(kube, name, now).join); err != nil {
+			t.Fatalf("expected '%s' to join: %v", name, err)
+		}
+	}
+
+	ghi := newTenant(kube, "ghi", now)
+	if err := ghi.update(ghi.join); err != ErrFull {
+		t.Errorf("expected cluster to be full, got: %v", err)
+	}
+	if err := newTenant(kube, "abc", now).update(newTenant(kube, "abc", now).join); err == nil {
+		t.Error("expected name of tenant already testing to be refused")
+	}
+
+	// leases of stopped runs expire
+	later := newTenant(kube, "ghi", now.Add(LeaseDuration))
+	if err := later.update(later.join); err != nil {
+		t.Fatalf("expected expired leases to make room: %v", err)
+	}
+	if names, err := later.Tenants(); err != nil || !reflect.DeepEqual(names, []string{"ghi"}) {
+		t.Errorf("expected only 'ghi' to be a tenant, got %v: %v", names, err)
+	}
+}
+
+func TestLeave(t *testing.T) {
+	kube := fake.NewSimpleClientset(
+		&kubev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "osde2e-abc-1", Labels: map[string]string{Label: "abc"}}},
+		&kubev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "osde2e-def-1", Labels: map[string]string{Label: "def"}}},
+	)
+	now := time.Now()
+	abc, def := newTenant(kube, "abc", now), newTenant(kube, "def", now)
+	for _, tenant := range []*Tenant{abc, def} {
+		if err := tenant.update(tenant.join); err != nil {
+			t.Fatalf("expected '%s' to join: %v", tenant.Name, err)
+		}
+	}
+
+	if err := abc.Leave(); err != nil {
+		t.Fatalf("failed to leave: %v", err)
+	}
+	if names, err := abc.Tenants(); err != nil || !reflect.DeepEqual(names, []string{"def"}) {
+		t.Errorf("expected only 'def' to be a tenant, got %v: %v", names, err)
+	}
+
+	namespaces, err := kube.CoreV1().Namespaces().List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var remaining []string
+	for _, ns := range namespaces.Items {
+		remaining = append(remaining, ns.Name)
+	}
+	for _, name := range remaining {
+		if name == "osde2e-abc-1" {
+			t.Errorf("expected namespaces of the tenant to be deleted, got %v", remaining)
+		}
+	}
+}
+
+func TestLimit(t *testing.T) {
+	quota, err := ParseQuota(map[string]string{"pods": "20", "requests.cpu": "2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	kube := fake.NewSimpleClientset()
+	if err = New(kube, "abc", 1, quota).Limit("osde2e-abc-1"); err != nil {
+		t.Fatalf("failed to limit namespace: %v", err)
+	}
+
+	rq, err := kube.CoreV1().ResourceQuotas("osde2e-abc-1").Get(limitsName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("expected quota to be created: %v", err)
+	}
+	if pods := rq.Spec.Hard[kubev1.ResourcePods]; pods.Value() != 20 {
+		t.Errorf("expected quota of 20 pods, got %s", pods.String())
+	}
+	if _, err = kube.CoreV1().LimitRanges("osde2e-abc-1").Get(limitsName, metav1.GetOptions{}); err != nil {
+		t.Errorf("expected default requests to be created: %v", err)
+	}
+
+	// nothing is limited when the cluster isn't shared
+	var unshared *Tenant
+	if err = unshared.Limit("osde2e-abc-1"); err != nil || unshared.Prefix() != "osde2e-" {
+		t.Errorf("expected tenant of unshared cluster to do nothing: %v", err)
+	}
+}
+
+func TestParseQuota(t *testing.T) {
+	if _, err := ParseQuota(map[string]string{"pods": "lots"}); err == nil {
+		t.Error("expected invalid quantity to be refused")
+	}
+}
@@ -0,0 +1,98 @@
+package testcases
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/openshift/osde2e/pkg/groups"
+	"github.com/openshift/osde2e/pkg/helper"
+)
+
+// waitInterval is how often conditions are checked.
+const waitInterval = 5 * time.Second
+
+// Describe defines a spec for each test case of suites. It must be called before specs are run.
+func Describe(suites []Suite) {
+	for _, s := range suites {
+		s := s
+		groups.Describe(s.Group, s.Name, func() {
+			h := helper.New()
+			for _, tc := range s.Tests {
+				tc := tc
+				ginkgo.It(tc.Name, func() {
+					run(h, tc)
+				})
+			}
+		})
+	}
+}
+
+// run performs tc against the cluster of h. Cluster-scoped objects it created are deleted afterwards, namespaced
+// objects are removed with the spec's project.
+func run(h *helper.H, tc TestCase) {
+	for i := range tc.Create {
+		obj := tc.Create[i].DeepCopy()
+		gvr, namespaced, err := resourceOf(h, obj.GroupVersionKind())
+		Expect(err).NotTo(HaveOccurred())
+		if namespaced && obj.GetNamespace() == "" {
+			obj.SetNamespace(h.CurrentProject())
+		} else if !namespaced {
+			defer func() {
+				if err := h.DeleteResource(gvr, "", obj.GetName()); err != nil {
+					log.Printf("Failed to delete %s created by test case: %v", obj.GetName(), err)
+				}
+			}()
+		}
+
+		_, err = h.ApplyResource(gvr, obj)
+		Expect(err).NotTo(HaveOccurred(), "failed creating %s '%s'", obj.GetKind(), obj.GetName())
+	}
+
+	if w := tc.Wait; w != nil {
+		gvr, ref, err := locate(h, w.Ref)
+		Expect(err).NotTo(HaveOccurred())
+		err = h.WaitForResourceCondition(gvr, ref.Namespace, ref.Name, w.Condition, w.WaitStatus(), waitInterval,
+			w.WaitTimeout())
+		Expect(err).NotTo(HaveOccurred(), "%s never had condition %s=%s", ref, w.Condition, w.WaitStatus())
+	}
+
+	gvr, ref, err := locate(h, tc.Assert.Ref)
+	Expect(err).NotTo(HaveOccurred())
+	obj, err := h.GetResource(gvr, ref.Namespace, ref.Name)
+	Expect(err).NotTo(HaveOccurred())
+
+	assertion := tc.Assert
+	assertion.Ref = ref
+	Expect(assertion.Check(obj.Object)).To(Succeed())
+}
+
+// locate returns the resource of the object r refers to, defaulting the namespace of namespaced objects to the
+// project of h.
+func locate(h *helper.H, r Ref) (schema.GroupVersionResource, Ref, error) {
+	gvr, namespaced, err := resourceOf(h, r.GroupVersionKind())
+	if err != nil {
+		return gvr, r, err
+	}
+	if !namespaced {
+		r.Namespace = ""
+	} else if r.Namespace == "" {
+		r.Namespace = h.CurrentProject()
+	}
+	return gvr, r, nil
+}
+
+// resourceOf returns the resource of gvk and whether its objects are namespaced.
+func resourceOf(h *helper.H, gvk schema.GroupVersionKind) (schema.GroupVersionResource, bool, error) {
+	mapping, err := h.RESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return schema.GroupVersionResource{}, false, fmt.Errorf("couldn't find resource for '%s': %v", gvk, err)
+	}
+	return mapping.Resource, mapping.Scope.Name() == meta.RESTScopeNameNamespace, nil
+}
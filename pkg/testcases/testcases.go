@@ -0,0 +1,266 @@
+// Package testcases converts test cases defined in YAML into specs, so simple checks of a cluster can be added without
+// writing Go or rebuilding osde2e. Each test case creates resources, waits for a condition, then asserts on a
+// field of a resource.
+package testcases
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/jsonpath"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/osde2e/pkg/groups"
+)
+
+const (
+	// DefaultTimeout is how long test cases wait for their condition if they don't set a timeout.
+	DefaultTimeout = 5 * time.Minute
+
+	// conditionTrue is the status waited for if a condition doesn't set one.
+	conditionTrue = "True"
+)
+
+// Suite is a set of test cases defined in a file.
+type Suite struct {
+	// Name of the suite, used as the text of its specs' container.
+	Name string `json:"suite"`
+
+	// Group the suite belongs to, which is other if unset.
+	Group groups.Group `json:"group,omitempty"`
+
+	Tests []TestCase `json:"tests"`
+
+	// File the suite was loaded from.
+	File string `json:"-"`
+}
+
+// TestCase is a spec defined in YAML.
+type TestCase struct {
+	Name string `json:"name"`
+
+	// Create are manifests of objects created before waiting. Namespaced objects without a namespace are created in
+	// the spec's project.
+	Create []unstructured.Unstructured `json:"create,omitempty"`
+
+	// Wait is a condition waited for before asserting.
+	Wait *Wait `json:"wait,omitempty"`
+
+	Assert Assert `json:"assert"`
+}
+
+// Ref identifies an object. Namespaced objects without a namespace are found in the spec's project.
+type Ref struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace,omitempty"`
+}
+
+// GroupVersionKind returns the GVK of the object r refers to.
+func (r Ref) GroupVersionKind() schema.GroupVersionKind {
+	return schema.FromAPIVersionAndKind(r.APIVersion, r.Kind)
+}
+
+func (r Ref) String() string {
+	if r.Namespace == "" {
+		return fmt.Sprintf("%s '%s'", r.Kind, r.Name)
+	}
+	return fmt.Sprintf("%s '%s/%s'", r.Kind, r.Namespace, r.Name)
+}
+
+// Wait is a status condition of an object to wait for.
+type Wait struct {
+	Ref `json:",inline"`
+
+	// Condition is the type of the status condition, such as 'Available'.
+	Condition string `json:"condition"`
+
+	// Status of the condition waited for, which is 'True' if unset.
+	Status string `json:"status,omitempty"`
+
+	// Timeout is how long to wait, such as '10m'. DefaultTimeout is used if unset.
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// WaitStatus returns the condition status waited for.
+func (w *Wait) WaitStatus() string {
+	if w.Status == "" {
+		return conditionTrue
+	}
+	return w.Status
+}
+
+// WaitTimeout returns how long to wait for the condition.
+func (w *Wait) WaitTimeout() time.Duration {
+	if d, err := time.ParseDuration(w.Timeout); err == nil && w.Timeout != "" {
+		return d
+	}
+	return DefaultTimeout
+}
+
+// Assert checks the result of a JSONPath query of an object.
+type Assert struct {
+	Ref `json:",inline"`
+
+	// Query is a JSONPath template evaluated against the object, such as '{.status.readyReplicas}'.
+	Query string `json:"query"`
+
+	// Equals is the expected result of the query.
+	Equals *string `json:"equals,omitempty"`
+
+	// Matches is a regular expression the result of the query must match. The result must be non-empty if neither
+	// Equals nor Matches are set.
+	Matches string `json:"matches,omitempty"`
+}
+
+// Check evaluates the query of a against obj, returning an error if the result isn't as expected.
+func (a *Assert) Check(obj map[string]interface{}) error {
+	result, err := Query(a.Query, obj)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case a.Equals != nil && result != *a.Equals:
+		return fmt.Errorf("expected %s of %s to be '%s', got '%s'", a.Query, a.Ref, *a.Equals, result)
+	case a.Matches != "":
+		if re, err := regexp.Compile(a.Matches); err != nil {
+			return fmt.Errorf("invalid pattern '%s': %v", a.Matches, err)
+		} else if !re.MatchString(result) {
+			return fmt.Errorf("expected %s of %s to match '%s', got '%s'", a.Query, a.Ref, a.Matches, result)
+		}
+	case a.Equals == nil && result == "":
+		return fmt.Errorf("expected %s of %s to be set", a.Query, a.Ref)
+	}
+	return nil
+}
+
+// Query evaluates the JSONPath template query against obj. Missing fields result in nothing.
+func Query(query string, obj map[string]interface{}) (string, error) {
+	jp := jsonpath.New("query").AllowMissingKeys(true)
+	if err := jp.Parse(query); err != nil {
+		return "", fmt.Errorf("invalid query '%s': %v", query, err)
+	}
+
+	var buf bytes.Buffer
+	if err := jp.Execute(&buf, obj); err != nil {
+		return "", fmt.Errorf("couldn't evaluate query '%s': %v", query, err)
+	}
+	return buf.String(), nil
+}
+
+// Load reads the suites defined in the YAML files of dir, ordered by file name.
+func Load(dir string) ([]Suite, error) {
+	var files []string
+	for _, pattern := range []string{"*.yaml", "*.yml"} {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return nil, fmt.Errorf("couldn't find test cases in '%s': %v", dir, err)
+		}
+		files = append(files, matches...)
+	}
+	sort.Strings(files)
+
+	suites := make([]Suite, 0, len(files))
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't read test cases '%s': %v", file, err)
+		}
+
+		var s Suite
+		if err = yaml.UnmarshalStrict(data, &s); err != nil {
+			return nil, fmt.Errorf("couldn't parse test cases '%s': %v", file, err)
+		}
+		s.File = file
+		if err = s.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid test cases '%s': %v", file, err)
+		}
+		suites = append(suites, s)
+	}
+	return suites, nil
+}
+
+// Validate checks s can be converted into specs.
+func (s *Suite) Validate() error {
+	if s.Name == "" {
+		return fmt.Errorf("suite must be named")
+	} else if len(s.Tests) == 0 {
+		return fmt.Errorf("suite '%s' has no tests", s.Name)
+	}
+
+	if s.Group == "" {
+		s.Group = groups.Other
+	} else if !validGroup(s.Group) {
+		return fmt.Errorf("unknown group '%s'", s.Group)
+	}
+
+	names := map[string]bool{}
+	for i, tc := range s.Tests {
+		if tc.Name == "" {
+			return fmt.Errorf("test %d must be named", i+1)
+		} else if names[tc.Name] {
+			return fmt.Errorf("test '%s' is defined more than once", tc.Name)
+		}
+		names[tc.Name] = true
+
+		for _, obj := range tc.Create {
+			if obj.GetAPIVersion() == "" || obj.GetKind() == "" || obj.GetName() == "" {
+				return fmt.Errorf("objects created by test '%s' need an apiVersion, kind, and name", tc.Name)
+			}
+		}
+
+		if w := tc.Wait; w != nil {
+			if err := validRef(w.Ref); err != nil {
+				return fmt.Errorf("test '%s' waits for %v", tc.Name, err)
+			} else if w.Condition == "" {
+				return fmt.Errorf("test '%s' waits for no condition", tc.Name)
+			} else if _, err = time.ParseDuration(w.Timeout); err != nil && w.Timeout != "" {
+				return fmt.Errorf("test '%s' has invalid timeout '%s': %v", tc.Name, w.Timeout, err)
+			}
+		}
+
+		if err := validRef(tc.Assert.Ref); err != nil {
+			return fmt.Errorf("test '%s' asserts on %v", tc.Name, err)
+		} else if tc.Assert.Query == "" {
+			return fmt.Errorf("test '%s' has no query", tc.Name)
+		} else if err = jsonpath.New("query").Parse(tc.Assert.Query); err != nil {
+			return fmt.Errorf("test '%s' has invalid query '%s': %v", tc.Name, tc.Assert.Query, err)
+		} else if _, err = regexp.Compile(tc.Assert.Matches); err != nil {
+			return fmt.Errorf("test '%s' has invalid pattern '%s': %v", tc.Name, tc.Assert.Matches, err)
+		}
+	}
+	return nil
+}
+
+func validRef(r Ref) error {
+	var missing []string
+	for field, value := range map[string]string{"apiVersion": r.APIVersion, "kind": r.Kind, "name": r.Name} {
+		if value == "" {
+			missing = append(missing, field)
+		}
+	}
+	if len(missing) != 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("an object without %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+func validGroup(g groups.Group) bool {
+	for _, known := range groups.Groups {
+		if g == known {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,131 @@
+package testcases
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift/osde2e/pkg/groups"
+)
+
+func TestLoadTestCases(t *testing.T) {
+	suites, err := Load(filepath.Join("..", "..", "testcases"))
+	if err != nil {
+		t.Fatalf("failed to load test cases: %v", err)
+	} else if len(suites) == 0 {
+		t.Fatal("expected test cases to be defined")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "testcases")
+	if err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, test := range []struct {
+		name, yaml, expectedErr string
+	}{
+		{"valid", `
+suite: Pods
+tests:
+- name: should be running
+  wait: {apiVersion: v1, kind: Pod, name: p, condition: Ready, timeout: 2m}
+  assert: {apiVersion: v1, kind: Pod, name: p, query: '{.status.phase}', equals: Running}
+`, ""},
+		{"unnamed suite", `
+tests:
+- name: t
+  assert: {apiVersion: v1, kind: Pod, name: p, query: '{.status.phase}'}
+`, "suite must be named"},
+		{"unknown group", `
+suite: Pods
+group: compute
+tests:
+- name: t
+  assert: {apiVersion: v1, kind: Pod, name: p, query: '{.status.phase}'}
+`, "unknown group"},
+		{"duplicate test", `
+suite: Pods
+tests:
+- name: t
+  assert: {apiVersion: v1, kind: Pod, name: p, query: '{.status.phase}'}
+- name: t
+  assert: {apiVersion: v1, kind: Pod, name: p, query: '{.status.phase}'}
+`, "defined more than once"},
+		{"missing kind", `
+suite: Pods
+tests:
+- name: t
+  assert: {apiVersion: v1, name: p, query: '{.status.phase}'}
+`, "without kind"},
+		{"invalid query", `
+suite: Pods
+tests:
+- name: t
+  assert: {apiVersion: v1, kind: Pod, name: p, query: '{.status.phase'}
+`, "invalid query"},
+		{"invalid timeout", `
+suite: Pods
+tests:
+- name: t
+  wait: {apiVersion: v1, kind: Pod, name: p, condition: Ready, timeout: soon}
+  assert: {apiVersion: v1, kind: Pod, name: p, query: '{.status.phase}'}
+`, "invalid timeout"},
+		{"unknown field", `
+suite: Pods
+tests:
+- name: t
+  assert: {apiVersion: v1, kind: Pod, name: p, query: '{.status.phase}', equal: Running}
+`, "unknown field"},
+	} {
+		file := filepath.Join(dir, "case.yaml")
+		if err = ioutil.WriteFile(file, []byte(test.yaml), os.ModePerm); err != nil {
+			t.Fatalf("failed to write test cases: %v", err)
+		}
+
+		suites, err := Load(dir)
+		if test.expectedErr == "" {
+			if err != nil {
+				t.Errorf("%s: expected test cases to load: %v", test.name, err)
+			} else if s := suites[0]; s.Group != groups.Other || s.Tests[0].Wait.WaitTimeout() != 2*time.Minute ||
+				s.Tests[0].Wait.WaitStatus() != "True" {
+				t.Errorf("%s: expected defaults to be set, got %+v", test.name, s)
+			}
+		} else if err == nil || !strings.Contains(err.Error(), test.expectedErr) {
+			t.Errorf("%s: expected error containing '%s', got %v", test.name, test.expectedErr, err)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	obj := map[string]interface{}{
+		"status": map[string]interface{}{
+			"readyReplicas": int64(3),
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Available", "status": "True"},
+			},
+		},
+	}
+
+	three, empty := "3", ""
+	for _, test := range []struct {
+		assertion Assert
+		passes    bool
+	}{
+		{Assert{Query: "{.status.readyReplicas}", Equals: &three}, true},
+		{Assert{Query: `{.status.conditions[?(@.type=="Available")].status}`, Matches: "^True$"}, true},
+		{Assert{Query: "{.status.readyReplicas}"}, true},
+		{Assert{Query: "{.status.replicas}", Equals: &empty}, true},
+		{Assert{Query: "{.status.replicas}"}, false},
+		{Assert{Query: "{.status.readyReplicas}", Matches: "^[0-2]$"}, false},
+	} {
+		if err := test.assertion.Check(obj); (err == nil) != test.passes {
+			t.Errorf("expected %s to pass: %t, got %v", test.assertion.Query, test.passes, err)
+		}
+	}
+}
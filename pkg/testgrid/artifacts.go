@@ -13,6 +13,11 @@ import (
 	"cloud.google.com/go/storage"
 )
 
+// Artifact returns the artifact with name stored by build buildNum.
+func (t *TestGrid) Artifact(ctx context.Context, buildNum int, name string) ([]byte, error) {
+	return t.getBuildFile(ctx, buildNum, ArtifactsDir, name)
+}
+
 func (t *TestGrid) writeArtifactDir(ctx context.Context, buildNum int, dir string) error {
 	dirInfo, err := ioutil.ReadDir(dir)
 	if err != nil {
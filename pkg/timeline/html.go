@@ -0,0 +1,131 @@
+package timeline
+
+import (
+	"html/template"
+	"io"
+	"time"
+)
+
+// minBarWidth is the narrowest bar drawn, in percent of the timeline, so instants remain visible.
+const minBarWidth = 0.2
+
+const htmlTmplText = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; font-size: 13px; margin: 1em 2em; }
+.lane { display: flex; align-items: center; border-bottom: 1px solid #ddd; }
+.label { width: 6em; flex: none; font-weight: bold; }
+.track { position: relative; flex: auto; height: 22px; }
+.bar { position: absolute; top: 4px; height: 14px; background: #7aa6da; opacity: 0.8; }
+.bar.failed { background: #d54e53; }
+.bar:hover { outline: 2px solid #333; }
+table { border-collapse: collapse; margin-top: 2em; width: 100%; }
+th, td { text-align: left; padding: 2px 8px; border-bottom: 1px solid #eee; vertical-align: top; }
+tr.failed td { color: #b00; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p>{{date .Start}} to {{date .End}} ({{.Duration}})</p>
+{{- range .Lanes}}
+<div class="lane"><div class="label">{{.Source}}</div><div class="track">
+	{{- range .Bars}}
+<a href="#entry-{{.Index}}"><div class="bar{{if .Entry.Failed}} failed{{end}}" style="left: {{.Left}}%; width: {{.Width}}%" title="{{.Entry.Name}}&#10;{{date .Entry.Start}}{{if .Entry.Detail}}&#10;{{.Entry.Detail}}{{end}}"></div></a>
+	{{- end}}
+</div></div>
+{{- end}}
+<table>
+<tr><th>Start</th><th>Duration</th><th>Source</th><th>Entry</th><th>Detail</th></tr>
+{{- range $i, $e := .Entries}}
+<tr id="entry-{{$i}}"{{if $e.Failed}} class="failed"{{end}}><td>{{date $e.Start}}</td><td>{{duration $e}}</td><td>{{$e.Source}}</td><td>{{$e.Name}}</td><td>{{$e.Detail}}</td></tr>
+{{- end}}
+</table>
+</body>
+</html>
+`
+
+var htmlTmpl = template.Must(template.New("timeline").
+	Funcs(template.FuncMap{
+		"date": func(t time.Time) string {
+			return t.UTC().Format(time.RFC3339)
+		},
+		"duration": func(e Entry) string {
+			if d := e.End.Sub(e.Start); d > 0 {
+				return d.Round(time.Second).String()
+			}
+			return ""
+		},
+	}).Parse(htmlTmplText))
+
+// view is what the viewer shows.
+type view struct {
+	Title      string
+	Start, End time.Time
+	Duration   time.Duration
+	Lanes      []lane
+	Entries    []Entry
+}
+
+// lane shows the entries of a source.
+type lane struct {
+	Source string
+	Bars   []bar
+}
+
+// bar places an entry along the timeline, in percent of its width.
+type bar struct {
+	Index       int
+	Left, Width float64
+	Entry       Entry
+}
+
+// writeHTML writes a viewer of entries, which are ordered by when they started, to w.
+func writeHTML(w io.Writer, title string, entries []Entry) error {
+	v := view{Title: title, Entries: entries}
+	for i, e := range entries {
+		if i == 0 || e.Start.Before(v.Start) {
+			v.Start = e.Start
+		}
+		if e.End.After(v.End) {
+			v.End = e.End
+		}
+	}
+	v.Duration = v.End.Sub(v.Start).Round(time.Second)
+
+	span := float64(v.End.Sub(v.Start))
+	lanes := map[string]*lane{}
+	for i, e := range entries {
+		l, ok := lanes[e.Source]
+		if !ok {
+			l = &lane{Source: e.Source}
+			lanes[e.Source] = l
+		}
+
+		b := bar{Index: i, Entry: e, Width: minBarWidth}
+		if span > 0 {
+			b.Left = 100 * float64(e.Start.Sub(v.Start)) / span
+			if width := 100 * float64(e.End.Sub(e.Start)) / span; width > b.Width {
+				b.Width = width
+			}
+		}
+		l.Bars = append(l.Bars, b)
+	}
+
+	// known sources are shown in order, followed by any others
+	for _, source := range Sources {
+		if l, ok := lanes[source]; ok {
+			v.Lanes = append(v.Lanes, *l)
+			delete(lanes, source)
+		}
+	}
+	for _, e := range entries {
+		if l, ok := lanes[e.Source]; ok {
+			v.Lanes = append(v.Lanes, *l)
+			delete(lanes, e.Source)
+		}
+	}
+	return htmlTmpl.Execute(w, v)
+}
@@ -0,0 +1,300 @@
+// Package timeline places what a run did alongside what happened to the cluster. Phases, specs, health check
+// transitions, upgrade milestones, gaps in availability, warning events, and firing alerts are merged into one timeline
+// which is written as JSON with an HTML viewer, so failures can be put in the context of the cluster at a glance.
+package timeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	ginkgoconfig "github.com/onsi/ginkgo/config"
+	"github.com/onsi/ginkgo/types"
+	kubev1 "k8s.io/api/core/v1"
+
+	"github.com/openshift/osde2e/pkg/metrics"
+)
+
+const (
+	// JSONFile is the name of the timeline written to the report directory.
+	JSONFile = "timeline.json"
+
+	// HTMLFile is the name of the viewer of the timeline written to the report directory.
+	HTMLFile = "timeline.html"
+
+	// AlertsQuery returns when alerts which fired during a window of the run became active, formatted with the window.
+	AlertsQuery = "max_over_time(ALERTS_FOR_STATE[%s])"
+
+	// MaxEvents is the most warning events added, the latest being kept.
+	MaxEvents = 500
+)
+
+// Sources of entries, each shown in its own lane.
+const (
+	SourcePhase   = "phase"
+	SourceSpec    = "spec"
+	SourceHealth  = "health"
+	SourceUpgrade = "upgrade"
+	SourceProbe   = "probe"
+	SourceAlert   = "alert"
+	SourceEvent   = "event"
+)
+
+// Sources are the sources of entries in the order they're shown.
+var Sources = []string{SourcePhase, SourceSpec, SourceHealth, SourceUpgrade, SourceProbe, SourceAlert, SourceEvent}
+
+// Entry is something which happened during the run. Entries which happened at an instant start and end at once.
+type Entry struct {
+	Source string    `json:"source"`
+	Name   string    `json:"name"`
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+
+	// Detail describes the entry further, such as why a spec failed.
+	Detail string `json:"detail,omitempty"`
+
+	// Failed is set for entries which are problems, such as failed specs and unhealthy checks.
+	Failed bool `json:"failed,omitempty"`
+}
+
+// Current records the timeline of this run. It's always recorded so gaps in availability can be correlated with it,
+// but only written when TIMELINE is set.
+var Current = New()
+
+// Timeline records the entries of a run. It's a Ginkgo reporter, adding an entry for each spec run. A nil Timeline
+// records nothing.
+type Timeline struct {
+	mu        sync.Mutex
+	entries   []Entry
+	phase     int
+	health    map[string]bool
+	specStart time.Time
+
+	// now returns the current time, and is replaced in tests.
+	now func() time.Time
+}
+
+// New returns an empty timeline.
+func New() *Timeline {
+	return &Timeline{
+		phase:  -1,
+		health: map[string]bool{},
+		now:    time.Now,
+	}
+}
+
+// Add records entries.
+func (t *Timeline) Add(entries ...Entry) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, e := range entries {
+		if e.End.IsZero() {
+			e.End = e.Start
+		}
+		t.entries = append(t.entries, e)
+	}
+}
+
+// StartPhase ends the previous phase and starts the phase name at now.
+func (t *Timeline) StartPhase(name string, now time.Time) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.phase >= 0 {
+		t.entries[t.phase].End = now
+	}
+	t.entries = append(t.entries, Entry{Source: SourcePhase, Name: name, Start: now})
+	t.phase = len(t.entries) - 1
+}
+
+// Health records the outcome of the health check name at now when it changes. The first outcome is only recorded
+// if it's unhealthy.
+func (t *Timeline) Health(name string, healthy bool, problems []string, now time.Time) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	last, checked := t.health[name]
+	t.health[name] = healthy
+	if (checked && last == healthy) || (!checked && healthy) {
+		return
+	}
+
+	e := Entry{Source: SourceHealth, Start: now, End: now, Failed: !healthy}
+	if healthy {
+		e.Name = name + " became healthy"
+	} else {
+		e.Name = name + " became unhealthy"
+		e.Detail = strings.Join(problems, ", ")
+	}
+	t.entries = append(t.entries, e)
+}
+
+// Entries returns the entries recorded so far ordered by when they started. Entries which haven't ended, such as
+// the current phase, end at now.
+func (t *Timeline) Entries(now time.Time) []Entry {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	entries := append([]Entry(nil), t.entries...)
+	t.mu.Unlock()
+
+	for i := range entries {
+		if !entries[i].End.After(entries[i].Start) && entries[i].Source == SourcePhase {
+			entries[i].End = now
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Start.Before(entries[j].Start)
+	})
+	return entries
+}
+
+// Write stores the timeline in dir as JSON and its HTML viewer, ending entries which haven't ended at now.
+func (t *Timeline) Write(dir, title string, now time.Time) error {
+	entries := t.Entries(now)
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("couldn't encode timeline: %v", err)
+	} else if err = ioutil.WriteFile(filepath.Join(dir, JSONFile), data, os.ModePerm); err != nil {
+		return fmt.Errorf("couldn't write timeline: %v", err)
+	}
+
+	f, err := os.Create(filepath.Join(dir, HTMLFile))
+	if err != nil {
+		return fmt.Errorf("couldn't create timeline viewer: %v", err)
+	}
+	defer f.Close()
+	if err = writeHTML(f, title, entries); err != nil {
+		return fmt.Errorf("couldn't write timeline viewer: %v", err)
+	}
+	return nil
+}
+
+// SpecSuiteWillBegin does nothing.
+func (t *Timeline) SpecSuiteWillBegin(config ginkgoconfig.GinkgoConfigType, summary *types.SuiteSummary) {
+}
+
+// BeforeSuiteDidRun does nothing.
+func (t *Timeline) BeforeSuiteDidRun(summary *types.SetupSummary) {}
+
+// SpecWillRun records when the spec started.
+func (t *Timeline) SpecWillRun(summary *types.SpecSummary) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.specStart = t.now()
+}
+
+// SpecDidComplete adds an entry for the spec unless it didn't run.
+func (t *Timeline) SpecDidComplete(summary *types.SpecSummary) {
+	if t == nil || summary.Skipped() || summary.Pending() {
+		return
+	}
+
+	// the first component is the top level container
+	texts := summary.ComponentTexts
+	if len(texts) > 1 {
+		texts = texts[1:]
+	}
+
+	t.mu.Lock()
+	e := Entry{
+		Source: SourceSpec,
+		Name:   strings.Join(texts, " "),
+		Start:  t.specStart,
+		End:    t.now(),
+		Failed: summary.State.IsFailure(),
+	}
+	if e.Failed {
+		e.Detail = summary.Failure.Message
+	}
+	t.mu.Unlock()
+	t.Add(e)
+}
+
+// AfterSuiteDidRun does nothing.
+func (t *Timeline) AfterSuiteDidRun(summary *types.SetupSummary) {}
+
+// SpecSuiteDidEnd does nothing.
+func (t *Timeline) SpecSuiteDidEnd(summary *types.SuiteSummary) {}
+
+// Events returns entries for the latest MaxEvents warning events which happened between start and end.
+func Events(events []kubev1.Event, start, end time.Time) (entries []Entry) {
+	for _, e := range events {
+		at := eventTime(e)
+		if e.Type != kubev1.EventTypeWarning || at.Before(start) || at.After(end) {
+			continue
+		}
+		obj := e.InvolvedObject
+		name := fmt.Sprintf("%s %s '%s'", e.Reason, strings.ToLower(obj.Kind), obj.Name)
+		if obj.Namespace != "" {
+			name = fmt.Sprintf("%s %s '%s/%s'", e.Reason, strings.ToLower(obj.Kind), obj.Namespace, obj.Name)
+		}
+		if e.Count > 1 {
+			name = fmt.Sprintf("%s (x%d)", name, e.Count)
+		}
+		entries = append(entries, Entry{Source: SourceEvent, Name: name, Start: at, End: at, Detail: e.Message})
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Start.Before(entries[j].Start)
+	})
+	if len(entries) > MaxEvents {
+		entries = entries[len(entries)-MaxEvents:]
+	}
+	return
+}
+
+// eventTime returns when e last happened.
+func eventTime(e kubev1.Event) time.Time {
+	if !e.LastTimestamp.IsZero() {
+		return e.LastTimestamp.Time
+	} else if !e.EventTime.IsZero() {
+		return e.EventTime.Time
+	}
+	return e.FirstTimestamp.Time
+}
+
+// Alerts returns entries for alerts which became active between start and end, from the samples of AlertsQuery.
+func Alerts(samples []metrics.Sample, start, end time.Time) (entries []Entry) {
+	for _, s := range samples {
+		at := time.Unix(int64(s.Value), 0)
+		if at.Before(start) || at.After(end) {
+			continue
+		}
+
+		var detail []string
+		for _, label := range []string{"severity", "namespace"} {
+			if v := s.Labels[label]; v != "" {
+				detail = append(detail, label+"="+v)
+			}
+		}
+		entries = append(entries, Entry{
+			Source: SourceAlert,
+			Name:   s.Labels["alertname"] + " became active",
+			Start:  at,
+			End:    at,
+			Detail: strings.Join(detail, ", "),
+			Failed: s.Labels["severity"] == "critical",
+		})
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Start.Before(entries[j].Start)
+	})
+	return
+}
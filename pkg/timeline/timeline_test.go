@@ -0,0 +1,145 @@
+package timeline
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/onsi/ginkgo/types"
+	kubev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openshift/osde2e/pkg/metrics"
+)
+
+func TestTimeline(t *testing.T) {
+	start := time.Date(2019, 8, 1, 0, 0, 0, 0, time.UTC)
+	tl := New()
+	tl.StartPhase("install", start)
+	tl.Health("nodes", true, nil, start.Add(time.Minute))
+	tl.StartPhase("tests", start.Add(10*time.Minute))
+	tl.Health("nodes", false, []string{"worker-0"}, start.Add(12*time.Minute))
+	tl.Health("nodes", false, []string{"worker-0"}, start.Add(13*time.Minute))
+	tl.Health("nodes", true, nil, start.Add(14*time.Minute))
+	tl.Add(Entry{Source: SourceUpgrade, Name: "hop 1", Start: start.Add(5 * time.Minute), End: start.Add(8 * time.Minute)})
+
+	// specs which ran are recorded as the timeline reports them, without their top level container
+	at := func(d time.Duration) func() time.Time {
+		return func() time.Time { return start.Add(d) }
+	}
+	tl.now = at(15 * time.Minute)
+	tl.SpecWillRun(&types.SpecSummary{})
+	tl.now = at(16 * time.Minute)
+	tl.SpecDidComplete(&types.SpecSummary{
+		ComponentTexts: []string{"[Suite: e2e]", "Routes", "should serve"},
+		State:          types.SpecStateFailed,
+		Failure:        types.SpecFailure{Message: "timed out"},
+	})
+	tl.SpecDidComplete(&types.SpecSummary{ComponentTexts: []string{"[Suite: e2e]", "Skipped"}, State: types.SpecStateSkipped})
+
+	end := start.Add(20 * time.Minute)
+	var names []string
+	for _, e := range tl.Entries(end) {
+		names = append(names, e.Source+": "+e.Name)
+	}
+	expected := []string{
+		"phase: install",
+		"upgrade: hop 1",
+		"phase: tests",
+		"health: nodes became unhealthy",
+		"health: nodes became healthy",
+		"spec: Routes should serve",
+	}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected entries %q, got %q", expected, names)
+	}
+
+	entries := tl.Entries(end)
+	if !entries[0].End.Equal(start.Add(10*time.Minute)) || !entries[2].End.Equal(end) {
+		t.Errorf("expected phases to end when the next starts or at the end, got %v and %v", entries[0].End, entries[2].End)
+	}
+	if !entries[3].Failed || entries[3].Detail != "worker-0" || !entries[5].Failed || entries[5].Detail != "timed out" {
+		t.Errorf("expected unhealthy checks and failed specs to be failures with details, got %+v", entries)
+	}
+
+	var nilTimeline *Timeline
+	nilTimeline.Add(Entry{Name: "ignored"})
+	nilTimeline.StartPhase("ignored", start)
+	if len(nilTimeline.Entries(end)) != 0 {
+		t.Error("expected nil timeline to record nothing")
+	}
+}
+
+func TestEventsAndAlerts(t *testing.T) {
+	start := time.Date(2019, 8, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	event := func(eventType, reason string, at time.Time) kubev1.Event {
+		return kubev1.Event{
+			Type:           eventType,
+			Reason:         reason,
+			Message:        "back-off restarting failed container",
+			Count:          3,
+			LastTimestamp:  metav1.NewTime(at),
+			InvolvedObject: kubev1.ObjectReference{Kind: "Pod", Namespace: "openshift-dns", Name: "dns-default-abc"},
+		}
+	}
+
+	events := Events([]kubev1.Event{
+		event(kubev1.EventTypeWarning, "BackOff", start.Add(30*time.Minute)),
+		event(kubev1.EventTypeNormal, "Pulled", start.Add(30*time.Minute)),
+		event(kubev1.EventTypeWarning, "Old", start.Add(-time.Minute)),
+	}, start, end)
+	if len(events) != 1 || events[0].Name != "BackOff pod 'openshift-dns/dns-default-abc' (x3)" || events[0].Source != SourceEvent {
+		t.Errorf("expected only the warning event during the run, got %+v", events)
+	}
+
+	alerts := Alerts([]metrics.Sample{
+		{Labels: map[string]string{"alertname": "KubePodCrashLooping", "severity": "critical", "namespace": "openshift-dns"},
+			Value: float64(start.Add(40 * time.Minute).Unix())},
+		{Labels: map[string]string{"alertname": "Watchdog", "severity": "none"}, Value: float64(start.Add(-time.Hour).Unix())},
+	}, start, end)
+	if len(alerts) != 1 || alerts[0].Name != "KubePodCrashLooping became active" || !alerts[0].Failed ||
+		alerts[0].Detail != "severity=critical, namespace=openshift-dns" {
+		t.Errorf("expected only the alert which became active during the run, got %+v", alerts)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "timeline")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	start := time.Date(2019, 8, 1, 0, 0, 0, 0, time.UTC)
+	tl := New()
+	tl.StartPhase("tests", start)
+	tl.Add(Entry{Source: SourceAlert, Name: "<KubeAPIDown> became active", Start: start.Add(time.Minute), Failed: true})
+	if err = tl.Write(dir, "osde2e run 'abc'", start.Add(time.Hour)); err != nil {
+		t.Fatalf("failed writing timeline: %v", err)
+	}
+
+	var entries []Entry
+	if data, err := ioutil.ReadFile(filepath.Join(dir, JSONFile)); err != nil {
+		t.Fatal(err)
+	} else if err = json.Unmarshal(data, &entries); err != nil || len(entries) != 2 {
+		t.Errorf("expected 2 entries written, got %d: %v", len(entries), err)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, HTMLFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := string(data)
+	for _, expected := range []string{"osde2e run &#39;abc&#39;", "&lt;KubeAPIDown&gt; became active", `class="bar failed"`,
+		"left: 0%; width: 100%", "1h0m0s"} {
+		if !strings.Contains(html, expected) {
+			t.Errorf("expected viewer to contain '%s'", expected)
+		}
+	}
+}
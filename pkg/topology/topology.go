@@ -0,0 +1,153 @@
+// Package topology describes where and on what instances a cluster runs so failures can be attributed to them.
+package topology
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	kubev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// RegionLabel is the label of nodes containing their cloud region.
+	RegionLabel = "failure-domain.beta.kubernetes.io/region"
+
+	// ZoneLabel is the label of nodes containing their availability zone.
+	ZoneLabel = "failure-domain.beta.kubernetes.io/zone"
+
+	// InstanceTypeLabel is the label of nodes containing their instance type.
+	InstanceTypeLabel = "beta.kubernetes.io/instance-type"
+
+	// roleLabelPrefix is the prefix of labels identifying the roles of nodes.
+	roleLabelPrefix = "node-role.kubernetes.io/"
+)
+
+const (
+	// CloudKey is the metadata key of the cloud provider the cluster runs on.
+	CloudKey = "cloud"
+
+	// RegionKey is the metadata key of the cluster's region.
+	RegionKey = "region"
+
+	// ZonesKey is the metadata key of the availability zones nodes run in.
+	ZonesKey = "availability-zones"
+
+	// AZLayoutKey is the metadata key of whether the cluster spans multiple availability zones.
+	AZLayoutKey = "az-layout"
+
+	// ArchitectureKey is the metadata key of the CPU architectures of nodes.
+	ArchitectureKey = "architecture"
+
+	// instanceTypesKeySuffix follows the role in metadata keys of instance types, such as 'worker-instance-types'.
+	instanceTypesKeySuffix = "-instance-types"
+)
+
+// AZ layouts of clusters.
+const (
+	SingleAZ = "single-az"
+	MultiAZ  = "multi-az"
+)
+
+// Topology is the layout of a cluster's nodes.
+type Topology struct {
+	// Cloud is the provider nodes run on, such as 'aws', taken from their provider IDs.
+	Cloud string
+
+	Region string
+
+	// Zones are the availability zones nodes run in.
+	Zones []string
+
+	// Architectures are the CPU architectures of nodes, such as 'amd64'.
+	Architectures []string
+
+	// InstanceTypes are the types of instances used by each node role.
+	InstanceTypes map[string][]string
+}
+
+// Get returns the topology of the cluster using kube.
+func Get(kube kubernetes.Interface) (Topology, error) {
+	list, err := kube.CoreV1().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		return Topology{}, fmt.Errorf("couldn't list nodes: %v", err)
+	}
+	return FromNodes(list.Items), nil
+}
+
+// FromNodes returns the topology of a cluster made up of nodes.
+func FromNodes(nodes []kubev1.Node) Topology {
+	t := Topology{
+		InstanceTypes: map[string][]string{},
+	}
+
+	for _, node := range nodes {
+		// provider IDs are of the form '<cloud>://<id>'
+		if i := strings.Index(node.Spec.ProviderID, "://"); i > 0 {
+			t.Cloud = node.Spec.ProviderID[:i]
+		}
+
+		labels := node.Labels
+		if region := labels[RegionLabel]; region != "" {
+			t.Region = region
+		}
+		if zone := labels[ZoneLabel]; zone != "" {
+			t.Zones = appendUnique(t.Zones, zone)
+		}
+		if arch := node.Status.NodeInfo.Architecture; arch != "" {
+			t.Architectures = appendUnique(t.Architectures, arch)
+		}
+
+		instanceType := labels[InstanceTypeLabel]
+		if instanceType == "" {
+			continue
+		}
+		for label := range labels {
+			if strings.HasPrefix(label, roleLabelPrefix) {
+				role := strings.TrimPrefix(label, roleLabelPrefix)
+				t.InstanceTypes[role] = appendUnique(t.InstanceTypes[role], instanceType)
+			}
+		}
+	}
+
+	sort.Strings(t.Zones)
+	sort.Strings(t.Architectures)
+	for _, types := range t.InstanceTypes {
+		sort.Strings(types)
+	}
+	return t
+}
+
+// AZLayout is MultiAZ if nodes run in more than one availability zone, otherwise SingleAZ.
+func (t Topology) AZLayout() string {
+	if len(t.Zones) > 1 {
+		return MultiAZ
+	}
+	return SingleAZ
+}
+
+// Metadata returns the topology as labels suitable for attaching to results.
+func (t Topology) Metadata() map[string]string {
+	meta := map[string]string{
+		CloudKey:        t.Cloud,
+		RegionKey:       t.Region,
+		ZonesKey:        strings.Join(t.Zones, ","),
+		AZLayoutKey:     t.AZLayout(),
+		ArchitectureKey: strings.Join(t.Architectures, ","),
+	}
+	for role, types := range t.InstanceTypes {
+		meta[role+instanceTypesKeySuffix] = strings.Join(types, ",")
+	}
+	return meta
+}
+
+func appendUnique(strs []string, s string) []string {
+	for _, str := range strs {
+		if str == s {
+			return strs
+		}
+	}
+	return append(strs, s)
+}
@@ -0,0 +1,61 @@
+package topology
+
+import (
+	"reflect"
+	"testing"
+
+	kubev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestFromNodes(t *testing.T) {
+	nodes := []kubev1.Node{
+		node("master-0", "master", "us-east-1a", "m5.xlarge"),
+		node("worker-0", "worker", "us-east-1b", "m5.large"),
+		node("worker-1", "worker", "us-east-1a", "m5.large"),
+		node("infra-0", "infra", "us-east-1c", "r5.xlarge"),
+	}
+	nodes[3].Labels[roleLabelPrefix+"worker"] = ""
+	nodes[3].Status.NodeInfo.Architecture = "arm64"
+
+	topo := FromNodes(nodes)
+	expected := map[string]string{
+		CloudKey:                "aws",
+		RegionKey:               "us-east-1",
+		ZonesKey:                "us-east-1a,us-east-1b,us-east-1c",
+		AZLayoutKey:             MultiAZ,
+		ArchitectureKey:         "amd64,arm64",
+		"master-instance-types": "m5.xlarge",
+		"worker-instance-types": "m5.large,r5.xlarge",
+		"infra-instance-types":  "r5.xlarge",
+	}
+	if meta := topo.Metadata(); !reflect.DeepEqual(meta, expected) {
+		t.Errorf("expected metadata %v, got %v", expected, meta)
+	}
+
+	if layout := FromNodes(nodes[:1]).AZLayout(); layout != SingleAZ {
+		t.Errorf("expected single node to be %s, got %s", SingleAZ, layout)
+	}
+}
+
+func node(name, role, zone, instanceType string) kubev1.Node {
+	return kubev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				RegionLabel:            "us-east-1",
+				ZoneLabel:              zone,
+				InstanceTypeLabel:      instanceType,
+				roleLabelPrefix + role: "",
+			},
+		},
+		Spec: kubev1.NodeSpec{
+			ProviderID: "aws:///" + zone + "/i-0123456789abcdef0",
+		},
+		Status: kubev1.NodeStatus{
+			NodeInfo: kubev1.NodeSystemInfo{
+				Architecture: "amd64",
+			},
+		},
+	}
+}
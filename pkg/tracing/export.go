@@ -0,0 +1,173 @@
+package tracing
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// tracesPath is where OTLP/HTTP collectors receive traces.
+	tracesPath = "/v1/traces"
+
+	// scopeName identifies osde2e as what recorded the spans.
+	scopeName = "osde2e"
+
+	// spanKindInternal marks spans as operations within osde2e rather than requests.
+	spanKindInternal = 1
+
+	// statusOK and statusError are the OTLP status codes of spans.
+	statusOK    = 1
+	statusError = 2
+)
+
+// exportTimeout is how long the collector has to accept the trace.
+var exportTimeout = 30 * time.Second
+
+// Export sends the spans recorded to the OTLP/HTTP collector at endpoint, such as 'http://localhost:4318', including
+// headers with the request for authentication. Spans which haven't ended are ended now.
+func (t *Tracer) Export(endpoint string, headers map[string]string) error {
+	if t == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(t.request(time.Now()))
+	if err != nil {
+		return fmt.Errorf("couldn't encode trace: %v", err)
+	}
+
+	url := strings.TrimSuffix(endpoint, "/") + tracesPath
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("couldn't create request to export trace: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	client := &http.Client{Timeout: exportTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("couldn't export trace to '%s': %v", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("collector '%s' rejected trace with status %d: %s", url, resp.StatusCode, body)
+	}
+	return nil
+}
+
+// request encodes the spans as an OTLP ExportTraceServiceRequest, ending open spans at end.
+func (t *Tracer) request(end time.Time) otlpRequest {
+	resource := otlpResource{
+		Attributes: otlpAttributes(map[string]string{"service.name": t.serviceName}),
+	}
+
+	var spans []otlpSpan
+	for _, s := range t.Spans() {
+		if s.End.IsZero() {
+			s.End = end
+		}
+
+		status := otlpStatus{Code: statusOK}
+		if s.Failed() {
+			status = otlpStatus{Code: statusError, Message: s.Error}
+		}
+
+		spans = append(spans, otlpSpan{
+			TraceID:      s.TraceID,
+			SpanID:       s.SpanID,
+			ParentSpanID: s.ParentID,
+			Name:         s.Name,
+			Kind:         spanKindInternal,
+			Start:        strconv.FormatInt(s.Start.UnixNano(), 10),
+			End:          strconv.FormatInt(s.End.UnixNano(), 10),
+			Attributes:   otlpAttributes(s.Attributes),
+			Status:       status,
+		})
+	}
+
+	return otlpRequest{
+		ResourceSpans: []otlpResourceSpans{
+			{
+				Resource: resource,
+				ScopeSpans: []otlpScopeSpans{
+					{
+						Scope: otlpScope{Name: scopeName},
+						Spans: spans,
+					},
+				},
+			},
+		},
+	}
+}
+
+// otlpAttributes converts attrs to OTLP key values, sorted by key.
+func otlpAttributes(attrs map[string]string) (kvs []otlpKeyValue) {
+	for k, v := range attrs {
+		kvs = append(kvs, otlpKeyValue{Key: k, Value: otlpValue{StringValue: v}})
+	}
+	sort.Slice(kvs, func(i, j int) bool {
+		return kvs[i].Key < kvs[j].Key
+	})
+	return
+}
+
+// The following types are the JSON encoding of OTLP/HTTP trace requests.
+
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID      string         `json:"traceId"`
+	SpanID       string         `json:"spanId"`
+	ParentSpanID string         `json:"parentSpanId,omitempty"`
+	Name         string         `json:"name"`
+	Kind         int            `json:"kind"`
+	Start        string         `json:"startTimeUnixNano"`
+	End          string         `json:"endTimeUnixNano"`
+	Attributes   []otlpKeyValue `json:"attributes,omitempty"`
+	Status       otlpStatus     `json:"status"`
+}
+
+type otlpKeyValue struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
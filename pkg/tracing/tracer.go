@@ -0,0 +1,269 @@
+// Package tracing records a run as a trace, with spans for each phase and spec, and exports it to an OpenTelemetry
+// collector so runs can be viewed in tools like Jaeger and Tempo.
+package tracing
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	ginkgoconfig "github.com/onsi/ginkgo/config"
+	"github.com/onsi/ginkgo/types"
+)
+
+// Span is a timed operation of a run.
+type Span struct {
+	TraceID  string
+	SpanID   string
+	ParentID string
+	Name     string
+	Start    time.Time
+	End      time.Time
+
+	// Attributes describe what the span did.
+	Attributes map[string]string
+
+	// Error is why the span failed, if it did.
+	Error string
+}
+
+// Failed returns true if s recorded an error.
+func (s *Span) Failed() bool {
+	return s.Error != ""
+}
+
+// Tracer records a trace of a run. It's a Ginkgo reporter, adding a span for each spec run beneath the current
+// phase. A nil Tracer records nothing.
+type Tracer struct {
+	serviceName string
+
+	mu    sync.Mutex
+	root  *Span
+	phase *Span
+	spec  *Span
+	spans []*Span
+}
+
+// New starts a trace of a run reported as serviceName.
+func New(serviceName, name string) *Tracer {
+	root := &Span{
+		TraceID:    newID(16),
+		SpanID:     newID(8),
+		Name:       name,
+		Start:      time.Now(),
+		Attributes: map[string]string{},
+	}
+	return &Tracer{
+		serviceName: serviceName,
+		root:        root,
+		spans:       []*Span{root},
+	}
+}
+
+// TraceID identifies the trace of the run.
+func (t *Tracer) TraceID() string {
+	if t == nil {
+		return ""
+	}
+	return t.root.TraceID
+}
+
+// SetAttributes adds attrs to the span of the run.
+func (t *Tracer) SetAttributes(attrs map[string]string) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for k, v := range attrs {
+		t.root.Attributes[k] = v
+	}
+}
+
+// StartPhase ends the current phase and starts one named name.
+func (t *Tracer) StartPhase(name string) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.endPhase()
+	t.phase = t.start(name, t.root)
+}
+
+// EndPhase ends the current phase, if any.
+func (t *Tracer) EndPhase() {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.endPhase()
+}
+
+// Record adds a span which has already completed beneath the run, such as an upgrade or a gap in availability, so
+// it can be correlated with the phases and specs running at the time.
+func (t *Tracer) Record(name string, start, end time.Time, attrs map[string]string) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s := t.start(name, t.root)
+	s.Start, s.End = start, end
+	for k, v := range attrs {
+		s.Attributes[k] = v
+	}
+}
+
+// Spans returns the spans recorded so far.
+func (t *Tracer) Spans() []Span {
+	if t == nil {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	spans := make([]Span, len(t.spans))
+	for i, s := range t.spans {
+		spans[i] = *s
+		spans[i].Attributes = make(map[string]string, len(s.Attributes))
+		for k, v := range s.Attributes {
+			spans[i].Attributes[k] = v
+		}
+	}
+	return spans
+}
+
+// SpecSuiteWillBegin records the number of specs to be run.
+func (t *Tracer) SpecSuiteWillBegin(config ginkgoconfig.GinkgoConfigType, summary *types.SuiteSummary) {
+	t.SetAttributes(map[string]string{
+		"suite":       summary.SuiteDescription,
+		"suite.specs": strconv.Itoa(summary.NumberOfSpecsThatWillBeRun),
+	})
+}
+
+// BeforeSuiteDidRun marks the current phase failed if setup failed.
+func (t *Tracer) BeforeSuiteDidRun(summary *types.SetupSummary) {
+	t.failPhase(summary)
+}
+
+// SpecWillRun starts a span for the spec beneath the current phase.
+func (t *Tracer) SpecWillRun(summary *types.SpecSummary) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.spec = t.start(specName(summary), t.parent())
+}
+
+// SpecDidComplete ends the span of the spec, dropping it if the spec didn't run.
+func (t *Tracer) SpecDidComplete(summary *types.SpecSummary) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s := t.spec
+	t.spec = nil
+	if s == nil {
+		return
+	} else if summary.Skipped() || summary.Pending() {
+		for i := range t.spans {
+			if t.spans[i] == s {
+				t.spans = append(t.spans[:i], t.spans[i+1:]...)
+				break
+			}
+		}
+		return
+	}
+
+	s.End = time.Now()
+	if locs := summary.ComponentCodeLocations; len(locs) != 0 {
+		s.Attributes["spec.location"] = locs[len(locs)-1].String()
+	}
+	if summary.State.IsFailure() {
+		s.Error = summary.Failure.Message
+	}
+}
+
+// AfterSuiteDidRun marks the current phase failed if teardown failed.
+func (t *Tracer) AfterSuiteDidRun(summary *types.SetupSummary) {
+	t.failPhase(summary)
+}
+
+// SpecSuiteDidEnd ends the trace, marking it failed if the suite did.
+func (t *Tracer) SpecSuiteDidEnd(summary *types.SuiteSummary) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.endPhase()
+	t.root.End = time.Now()
+	t.root.Attributes["suite.failed"] = strconv.Itoa(summary.NumberOfFailedSpecs)
+	if !summary.SuiteSucceeded {
+		t.root.Error = "suite failed"
+	}
+}
+
+// failPhase marks the current phase failed if summary did.
+func (t *Tracer) failPhase(summary *types.SetupSummary) {
+	if !summary.State.IsFailure() {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.phase != nil {
+		t.phase.Error = summary.Failure.Message
+	} else {
+		t.root.Error = summary.Failure.Message
+	}
+}
+
+// parent returns the span new spans are created beneath.
+func (t *Tracer) parent() *Span {
+	if t.phase != nil {
+		return t.phase
+	}
+	return t.root
+}
+
+// start begins a span beneath parent.
+func (t *Tracer) start(name string, parent *Span) *Span {
+	s := &Span{
+		TraceID:    t.root.TraceID,
+		SpanID:     newID(8),
+		ParentID:   parent.SpanID,
+		Name:       name,
+		Start:      time.Now(),
+		Attributes: map[string]string{},
+	}
+	t.spans = append(t.spans, s)
+	return s
+}
+
+func (t *Tracer) endPhase() {
+	if t.phase != nil {
+		t.phase.End = time.Now()
+		t.phase = nil
+	}
+}
+
+// specName joins the texts of the spec, without its top level container.
+func specName(summary *types.SpecSummary) string {
+	texts := summary.ComponentTexts
+	if len(texts) > 1 {
+		texts = texts[1:]
+	}
+	return strings.Join(texts, " ")
+}
+
+// newID returns a random hex ID of n bytes.
+func newID(n int) string {
+	id := make([]byte, n)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,108 @@
+package tracing
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	ginkgoconfig "github.com/onsi/ginkgo/config"
+	"github.com/onsi/ginkgo/types"
+)
+
+func TestTracerExport(t *testing.T) {
+	var received otlpRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != tracesPath {
+			t.Errorf("unexpected request path '%s'", r.URL.Path)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer token" {
+			t.Errorf("unexpected Authorization header '%s'", auth)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Fatalf("Failed decoding trace: %v", err)
+		}
+	}))
+	defer srv.Close()
+
+	tracer := New("osde2e", "osde2e run")
+	tracer.SetAttributes(map[string]string{"version": "openshift-v4.2.0"})
+	tracer.SpecSuiteWillBegin(ginkgoconfig.GinkgoConfigType{}, &types.SuiteSummary{SuiteDescription: "OSD e2e suite", NumberOfSpecsThatWillBeRun: 2})
+
+	tracer.StartPhase("install")
+	tracer.BeforeSuiteDidRun(&types.SetupSummary{State: types.SpecStatePassed})
+	tracer.StartPhase("tests")
+
+	tracer.SpecWillRun(&types.SpecSummary{ComponentTexts: []string{"[top]", "Pods", "should run"}})
+	tracer.SpecDidComplete(&types.SpecSummary{State: types.SpecStatePassed})
+	tracer.SpecWillRun(&types.SpecSummary{ComponentTexts: []string{"[top]", "Pods", "should be skipped"}})
+	tracer.SpecDidComplete(&types.SpecSummary{State: types.SpecStateSkipped})
+	tracer.SpecWillRun(&types.SpecSummary{ComponentTexts: []string{"[top]", "Routes", "should serve"}})
+	tracer.SpecDidComplete(&types.SpecSummary{State: types.SpecStateFailed, Failure: types.SpecFailure{Message: "timed out"}})
+
+	now := time.Now()
+	tracer.Record("unavailable: route", now.Add(-time.Minute), now, map[string]string{"reason": "refused"})
+	tracer.SpecSuiteDidEnd(&types.SuiteSummary{NumberOfFailedSpecs: 1})
+
+	if err := tracer.Export(srv.URL, map[string]string{"Authorization": "Bearer token"}); err != nil {
+		t.Fatalf("Failed exporting trace: %v", err)
+	}
+
+	if len(received.ResourceSpans) != 1 || len(received.ResourceSpans[0].ScopeSpans) != 1 {
+		t.Fatalf("expected one resource and scope, got %+v", received)
+	}
+	resource := received.ResourceSpans[0]
+	if attrs := resource.Resource.Attributes; len(attrs) != 1 || attrs[0].Value.StringValue != "osde2e" {
+		t.Errorf("expected service name in resource, got %+v", attrs)
+	}
+
+	spans := map[string]otlpSpan{}
+	for _, s := range resource.ScopeSpans[0].Spans {
+		if s.TraceID != tracer.TraceID() {
+			t.Errorf("span '%s' has trace '%s', expected '%s'", s.Name, s.TraceID, tracer.TraceID())
+		}
+		if len(s.End) == len(s.Start) && s.End < s.Start {
+			t.Errorf("span '%s' ended before it started", s.Name)
+		}
+		spans[s.Name] = s
+	}
+
+	if len(spans) != 6 {
+		t.Fatalf("expected run, 2 phases, 2 specs, and a recorded span, got %+v", spans)
+	}
+	if _, ok := spans["Pods should be skipped"]; ok {
+		t.Errorf("skipped specs shouldn't have spans")
+	}
+
+	run, tests := spans["osde2e run"], spans["tests"]
+	if run.ParentSpanID != "" || run.Status.Code != statusError {
+		t.Errorf("run should be a failed root span, got %+v", run)
+	}
+	if spans["install"].ParentSpanID != run.SpanID || tests.ParentSpanID != run.SpanID {
+		t.Errorf("phases should be children of the run")
+	}
+	for _, name := range []string{"Pods should run", "Routes should serve"} {
+		if spans[name].ParentSpanID != tests.SpanID {
+			t.Errorf("span '%s' should be a child of the tests phase", name)
+		}
+	}
+	if spans["unavailable: route"].ParentSpanID != run.SpanID {
+		t.Errorf("recorded spans should be children of the run")
+	}
+	if s := spans["Routes should serve"]; s.Status.Code != statusError || s.Status.Message != "timed out" {
+		t.Errorf("failed spec should have an error status, got %+v", s.Status)
+	}
+	if s := spans["Pods should run"]; s.Status.Code != statusOK {
+		t.Errorf("passed spec should have an OK status, got %+v", s.Status)
+	}
+}
+
+func TestNilTracer(t *testing.T) {
+	var tracer *Tracer
+	tracer.StartPhase("install")
+	tracer.Record("upgrade", time.Now(), time.Now(), nil)
+	if err := tracer.Export("http://localhost:0", nil); err != nil {
+		t.Errorf("nil tracer shouldn't export, got %v", err)
+	}
+}
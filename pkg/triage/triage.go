@@ -0,0 +1,313 @@
+// Package triage suggests probable causes of failed specs, such as nodes going NotReady or OCM returning errors while
+// they ran, and adds them to failure messages to speed up triage.
+package triage
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	ginkgoconfig "github.com/onsi/ginkgo/config"
+	"github.com/onsi/ginkgo/types"
+
+	configv1 "github.com/openshift/api/config/v1"
+	configclient "github.com/openshift/client-go/config/clientset/versioned"
+	kubev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/openshift/osde2e/pkg/osd"
+)
+
+const (
+	// Header precedes hints added to failure messages.
+	Header = "Probable causes:"
+
+	// margin is how long before a spec started its window begins, as causes often precede failures.
+	margin = time.Minute
+
+	// maxEventReasons is how many reasons of warning events are hinted at.
+	maxEventReasons = 3
+
+	// maxHints is how many hints are added to a failure.
+	maxHints = 8
+)
+
+// Window is when a spec ran.
+type Window struct {
+	Start, End time.Time
+}
+
+// Contains returns true if t is within w.
+func (w Window) Contains(t time.Time) bool {
+	return !t.Before(w.Start) && !t.After(w.End)
+}
+
+// Evidence is the state of the cluster inspected for hints.
+type Evidence struct {
+	Nodes     []kubev1.Node
+	Events    []kubev1.Event
+	Operators []configv1.ClusterOperator
+}
+
+// Collector gathers evidence about what happened during a window.
+type Collector func(w Window) (Evidence, error)
+
+// ClusterCollector returns a Collector inspecting the cluster accessed with kubeconfig.
+func ClusterCollector(kubeconfig []byte) (Collector, error) {
+	restConfig, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't configure client: %v", err)
+	}
+	kube, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't create Kubernetes client: %v", err)
+	}
+	cfg, err := configclient.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't create config client: %v", err)
+	}
+
+	return func(w Window) (e Evidence, err error) {
+		nodes, err := kube.CoreV1().Nodes().List(metav1.ListOptions{})
+		if err != nil {
+			return e, fmt.Errorf("couldn't list nodes: %v", err)
+		}
+		events, err := kube.CoreV1().Events(metav1.NamespaceAll).List(metav1.ListOptions{
+			FieldSelector: fields.OneTermEqualSelector("type", kubev1.EventTypeWarning).String(),
+		})
+		if err != nil {
+			return e, fmt.Errorf("couldn't list events: %v", err)
+		}
+		cos, err := cfg.ConfigV1().ClusterOperators().List(metav1.ListOptions{})
+		if err != nil {
+			return e, fmt.Errorf("couldn't list cluster operators: %v", err)
+		}
+		return Evidence{Nodes: nodes.Items, Events: events.Items, Operators: cos.Items}, nil
+	}, nil
+}
+
+// NodeHints describes nodes which are NotReady, or changed readiness during w.
+func NodeHints(nodes []kubev1.Node, w Window) (hints []string) {
+	for _, n := range nodes {
+		for _, c := range n.Status.Conditions {
+			if c.Type != kubev1.NodeReady {
+				continue
+			}
+			if c.Status != kubev1.ConditionTrue {
+				hints = append(hints, fmt.Sprintf("node '%s' is NotReady since %s: %s", n.Name,
+					c.LastTransitionTime.UTC().Format(time.RFC3339), c.Message))
+			} else if w.Contains(c.LastTransitionTime.Time) {
+				hints = append(hints, fmt.Sprintf("node '%s' was NotReady during this window, until %s", n.Name,
+					c.LastTransitionTime.UTC().Format(time.RFC3339)))
+			}
+		}
+	}
+	sort.Strings(hints)
+	return
+}
+
+// EventHints describes the most common reasons of warning events during w, with an example of each.
+func EventHints(events []kubev1.Event, w Window) (hints []string) {
+	type reason struct {
+		name    string
+		count   int
+		example string
+	}
+	reasons := map[string]*reason{}
+	for _, e := range events {
+		if e.Type != kubev1.EventTypeWarning || !w.Contains(eventTime(e)) {
+			continue
+		}
+
+		r, ok := reasons[e.Reason]
+		if !ok {
+			r = &reason{
+				name: e.Reason,
+				example: fmt.Sprintf("%s '%s/%s': %s", strings.ToLower(e.InvolvedObject.Kind), e.InvolvedObject.Namespace,
+					e.InvolvedObject.Name, e.Message),
+			}
+			reasons[e.Reason] = r
+		}
+		if e.Count > 0 {
+			r.count += int(e.Count)
+		} else {
+			r.count++
+		}
+	}
+
+	sorted := make([]*reason, 0, len(reasons))
+	for _, r := range reasons {
+		sorted = append(sorted, r)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].count != sorted[j].count {
+			return sorted[i].count > sorted[j].count
+		}
+		return sorted[i].name < sorted[j].name
+	})
+
+	for i, r := range sorted {
+		if i == maxEventReasons {
+			break
+		}
+		hints = append(hints, fmt.Sprintf("%d %s warning events during this window, such as %s", r.count, r.name, r.example))
+	}
+	return
+}
+
+// OperatorHints describes cluster operators which are degraded or unavailable.
+func OperatorHints(operators []configv1.ClusterOperator) (hints []string) {
+	for _, co := range operators {
+		for _, c := range co.Status.Conditions {
+			switch {
+			case c.Type == configv1.OperatorDegraded && c.Status == configv1.ConditionTrue:
+				hints = append(hints, fmt.Sprintf("cluster operator '%s' is degraded: %s", co.Name, c.Message))
+			case c.Type == configv1.OperatorAvailable && c.Status != configv1.ConditionTrue:
+				hints = append(hints, fmt.Sprintf("cluster operator '%s' is unavailable: %s", co.Name, c.Message))
+			}
+		}
+	}
+	sort.Strings(hints)
+	return
+}
+
+// OCMHints describes the requests to the OCM API which failed between the before and after summaries of Stats,
+// excluding client errors.
+func OCMHints(before, after osd.APISummary) (hints []string) {
+	previous := map[string]map[string]int{}
+	for _, e := range before.Endpoints {
+		previous[e.Method+" "+e.Path] = e.Errors
+	}
+
+	for _, e := range after.Endpoints {
+		var classes []string
+		for class, count := range e.Errors {
+			if n := count - previous[e.Method+" "+e.Path][class]; class != osd.ErrorClient && n > 0 {
+				classes = append(classes, fmt.Sprintf("%d %s", n, class))
+			}
+		}
+		if len(classes) != 0 {
+			sort.Strings(classes)
+			hints = append(hints, fmt.Sprintf("OCM API errors observed during this window: %s from %s %s",
+				strings.Join(classes, ", "), e.Method, e.Path))
+		}
+	}
+	return
+}
+
+// KnownIssue returns the issue a failure of test with message is known to be caused by.
+type KnownIssue func(test, message string) (issue string, ok bool)
+
+// Analyzer is a Ginkgo reporter adding hints to the failure messages of specs. It must be run before reporters which
+// record failure messages.
+type Analyzer struct {
+	// Collect gathers evidence from the cluster. Hints from the cluster aren't given if it's nil.
+	Collect Collector
+
+	// Known matches failures to known issues. Known issues aren't hinted at if it's nil.
+	Known KnownIssue
+
+	mu     sync.Mutex
+	start  time.Time
+	before osd.APISummary
+	hinted int
+}
+
+// Hinted returns how many failures have been given hints.
+func (a *Analyzer) Hinted() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.hinted
+}
+
+// Hints returns the probable causes of test failing with message during w.
+func (a *Analyzer) Hints(test, message string, w Window, before, after osd.APISummary) []string {
+	var hints []string
+	if a.Known != nil {
+		if issue, ok := a.Known(test, message); ok {
+			hints = append(hints, "failure matches known issue "+issue)
+		}
+	}
+
+	if a.Collect != nil {
+		if e, err := a.Collect(w); err != nil {
+			log.Printf("Failed to inspect cluster for probable causes of '%s': %v", test, err)
+		} else {
+			hints = append(hints, NodeHints(e.Nodes, w)...)
+			hints = append(hints, OperatorHints(e.Operators)...)
+			hints = append(hints, EventHints(e.Events, w)...)
+		}
+	}
+	hints = append(hints, OCMHints(before, after)...)
+
+	if len(hints) > maxHints {
+		hints = hints[:maxHints]
+	}
+	return hints
+}
+
+// SpecSuiteWillBegin does nothing.
+func (a *Analyzer) SpecSuiteWillBegin(config ginkgoconfig.GinkgoConfigType, summary *types.SuiteSummary) {
+}
+
+// BeforeSuiteDidRun does nothing.
+func (a *Analyzer) BeforeSuiteDidRun(summary *types.SetupSummary) {}
+
+// SpecWillRun records when the spec started and the state of OCM API requests.
+func (a *Analyzer) SpecWillRun(summary *types.SpecSummary) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.start, a.before = time.Now(), osd.Stats.Summary()
+}
+
+// SpecDidComplete appends hints to the failure message of a failed spec.
+func (a *Analyzer) SpecDidComplete(summary *types.SpecSummary) {
+	if !summary.HasFailureState() {
+		return
+	}
+
+	a.mu.Lock()
+	w := Window{Start: a.start.Add(-margin), End: time.Now()}
+	before := a.before
+	a.mu.Unlock()
+
+	// the first component is the top level container
+	texts := summary.ComponentTexts
+	if len(texts) > 1 {
+		texts = texts[1:]
+	}
+	test := strings.Join(texts, " ")
+
+	hints := a.Hints(test, summary.Failure.Message, w, before, osd.Stats.Summary())
+	if len(hints) == 0 {
+		return
+	}
+	summary.Failure.Message += "\n\n" + Header + "\n- " + strings.Join(hints, "\n- ")
+	log.Printf("Probable causes of '%s' failing:\n- %s", test, strings.Join(hints, "\n- "))
+
+	a.mu.Lock()
+	a.hinted++
+	a.mu.Unlock()
+}
+
+// AfterSuiteDidRun does nothing.
+func (a *Analyzer) AfterSuiteDidRun(summary *types.SetupSummary) {}
+
+// SpecSuiteDidEnd does nothing.
+func (a *Analyzer) SpecSuiteDidEnd(summary *types.SuiteSummary) {}
+
+// eventTime returns when e last happened.
+func eventTime(e kubev1.Event) time.Time {
+	if !e.LastTimestamp.IsZero() {
+		return e.LastTimestamp.Time
+	} else if !e.EventTime.IsZero() {
+		return e.EventTime.Time
+	}
+	return e.FirstTimestamp.Time
+}
@@ -0,0 +1,161 @@
+package triage
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/onsi/ginkgo/types"
+
+	configv1 "github.com/openshift/api/config/v1"
+	kubev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openshift/osde2e/pkg/osd"
+)
+
+var (
+	start  = time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC)
+	window = Window{Start: start, End: start.Add(10 * time.Minute)}
+)
+
+func node(name string, status kubev1.ConditionStatus, transition time.Time) kubev1.Node {
+	return kubev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: kubev1.NodeStatus{
+			Conditions: []kubev1.NodeCondition{
+				{Type: kubev1.NodeMemoryPressure, Status: kubev1.ConditionFalse},
+				{Type: kubev1.NodeReady, Status: status, LastTransitionTime: metav1.NewTime(transition), Message: "kubelet stopped posting node status"},
+			},
+		},
+	}
+}
+
+func event(reason, name string, count int32, at time.Time) kubev1.Event {
+	return kubev1.Event{
+		Type:           kubev1.EventTypeWarning,
+		Reason:         reason,
+		Message:        reason + " happened",
+		Count:          count,
+		LastTimestamp:  metav1.NewTime(at),
+		InvolvedObject: kubev1.ObjectReference{Kind: "Pod", Namespace: "openshift-monitoring", Name: name},
+	}
+}
+
+func TestNodeHints(t *testing.T) {
+	hints := NodeHints([]kubev1.Node{
+		node("worker-a", kubev1.ConditionTrue, start.Add(-time.Hour)),
+		node("worker-b", kubev1.ConditionTrue, start.Add(5*time.Minute)),
+		node("worker-c", kubev1.ConditionUnknown, start.Add(-2*time.Hour)),
+	}, window)
+
+	expected := []string{
+		"node 'worker-b' was NotReady during this window, until 2019-10-01T10:05:00Z",
+		"node 'worker-c' is NotReady since 2019-10-01T08:00:00Z: kubelet stopped posting node status",
+	}
+	if strings.Join(hints, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected hints %q, got %q", expected, hints)
+	}
+}
+
+func TestEventHints(t *testing.T) {
+	hints := EventHints([]kubev1.Event{
+		event("BackOff", "prometheus-k8s-0", 12, start.Add(time.Minute)),
+		event("BackOff", "prometheus-k8s-1", 3, start.Add(2*time.Minute)),
+		event("FailedMount", "alertmanager-main-0", 1, start.Add(3*time.Minute)),
+		event("FailedScheduling", "grafana-1", 0, start.Add(4*time.Minute)),
+		event("Unhealthy", "grafana-1", 1, start.Add(5*time.Minute)),
+		event("Evicted", "old", 40, start.Add(-time.Hour)),
+		{Type: kubev1.EventTypeNormal, Reason: "Pulled", Count: 50, LastTimestamp: metav1.NewTime(start)},
+	}, window)
+
+	if len(hints) != maxEventReasons {
+		t.Fatalf("expected %d hints, got %q", maxEventReasons, hints)
+	}
+	expected := "15 BackOff warning events during this window, such as pod 'openshift-monitoring/prometheus-k8s-0': BackOff happened"
+	if hints[0] != expected {
+		t.Errorf("expected first hint '%s', got '%s'", expected, hints[0])
+	}
+	if !strings.HasPrefix(hints[1], "1 FailedMount") || !strings.HasPrefix(hints[2], "1 FailedScheduling") {
+		t.Errorf("expected reasons with equal counts sorted by name, got %q", hints)
+	}
+}
+
+func TestOperatorHints(t *testing.T) {
+	operator := func(name string, conditions ...configv1.ClusterOperatorStatusCondition) configv1.ClusterOperator {
+		return configv1.ClusterOperator{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status:     configv1.ClusterOperatorStatus{Conditions: conditions},
+		}
+	}
+
+	hints := OperatorHints([]configv1.ClusterOperator{
+		operator("dns", configv1.ClusterOperatorStatusCondition{Type: configv1.OperatorAvailable, Status: configv1.ConditionTrue}),
+		operator("monitoring", configv1.ClusterOperatorStatusCondition{Type: configv1.OperatorDegraded, Status: configv1.ConditionTrue, Message: "prometheus is crashlooping"}),
+		operator("ingress", configv1.ClusterOperatorStatusCondition{Type: configv1.OperatorAvailable, Status: configv1.ConditionFalse, Message: "no routers"}),
+	})
+
+	expected := []string{
+		"cluster operator 'ingress' is unavailable: no routers",
+		"cluster operator 'monitoring' is degraded: prometheus is crashlooping",
+	}
+	if strings.Join(hints, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected hints %q, got %q", expected, hints)
+	}
+}
+
+func TestOCMHints(t *testing.T) {
+	before := osd.APISummary{Endpoints: []osd.EndpointStats{
+		{Method: "GET", Path: "/api/clusters_mgmt/v1/clusters/-", Calls: 10, Errors: map[string]int{osd.ErrorServer: 1}},
+	}}
+	after := osd.APISummary{Endpoints: []osd.EndpointStats{
+		{Method: "GET", Path: "/api/clusters_mgmt/v1/clusters/-", Calls: 20, Errors: map[string]int{osd.ErrorServer: 4, osd.ErrorThrottled: 1}},
+		{Method: "GET", Path: "/api/clusters_mgmt/v1/versions", Calls: 2, Errors: map[string]int{osd.ErrorClient: 2}},
+	}}
+
+	expected := []string{"OCM API errors observed during this window: 1 throttled, 3 server from GET /api/clusters_mgmt/v1/clusters/-"}
+	if hints := OCMHints(before, after); strings.Join(hints, "\n") != expected[0] {
+		t.Errorf("expected hints %q, got %q", expected, hints)
+	}
+}
+
+func TestAnalyzer(t *testing.T) {
+	var inspected Window
+	a := &Analyzer{
+		Collect: func(w Window) (Evidence, error) {
+			inspected = w
+			return Evidence{Nodes: []kubev1.Node{node("worker-c", kubev1.ConditionFalse, start)}}, nil
+		},
+		Known: func(test, message string) (string, bool) {
+			return "https://issues.redhat.com/browse/OSD-1", test == "Routes should be admitted"
+		},
+	}
+
+	passed := &types.SpecSummary{
+		ComponentTexts: []string{"[Top Level]", "Routes", "should be admitted"},
+		State:          types.SpecStatePassed,
+	}
+	a.SpecWillRun(passed)
+	a.SpecDidComplete(passed)
+	if !inspected.Start.IsZero() || a.Hinted() != 0 {
+		t.Fatal("expected passed specs not to be analyzed")
+	}
+
+	failed := &types.SpecSummary{
+		ComponentTexts: []string{"[Top Level]", "Routes", "should be admitted"},
+		State:          types.SpecStateFailed,
+		Failure:        types.SpecFailure{Message: "route wasn't admitted"},
+	}
+	a.SpecWillRun(failed)
+	a.SpecDidComplete(failed)
+
+	if inspected.End.Sub(inspected.Start) < margin {
+		t.Errorf("expected window to start %v before the spec, got %+v", margin, inspected)
+	}
+	expected := "route wasn't admitted\n\n" + Header +
+		"\n- failure matches known issue https://issues.redhat.com/browse/OSD-1" +
+		"\n- node 'worker-c' is NotReady since 2019-10-01T10:00:00Z: kubelet stopped posting node status"
+	if failed.Failure.Message != expected || a.Hinted() != 1 {
+		t.Errorf("expected failure message:\n%s\ngot:\n%s", expected, failed.Failure.Message)
+	}
+}
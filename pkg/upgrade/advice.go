@@ -0,0 +1,106 @@
+package upgrade
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Masterminds/semver"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openshift/osde2e/pkg/helper"
+)
+
+// Advice describes the upgrades available to a cluster.
+type Advice struct {
+	// Current is the version the cluster is running.
+	Current string
+
+	// Edges are the upgrades available, newest first.
+	Edges []EdgeAdvice
+
+	// Windows are the maintenance windows upgrades were planned around.
+	Windows []MaintenanceWindow
+}
+
+// EdgeAdvice describes an upgrade to a single version.
+type EdgeAdvice struct {
+	Version string
+	Image   string
+
+	// Gates must be acknowledged before upgrading.
+	Gates []AdminGate
+
+	// Estimate is how long the upgrade is expected to take.
+	Estimate Estimate
+
+	// Conflict explains why the upgrade can't be performed at the planned time. It's empty if there's no conflict.
+	Conflict string
+
+	// NextWindow is when the upgrade could next start and complete within a maintenance window.
+	NextWindow *time.Time
+}
+
+// Advise checks the upgrades available to the cluster accessed by h if started at start, using history to
+// estimate durations and planning around windows. No changes are made to the cluster.
+func Advise(h *helper.H, history History, windows []MaintenanceWindow, start time.Time) (*Advice, error) {
+	cVersion, err := h.Cfg().ConfigV1().ClusterVersions().Get(ClusterVersionName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get current ClusterVersion '%s': %v", ClusterVersionName, err)
+	}
+
+	gates, err := PendingAdminGates(h)
+	if err != nil {
+		return nil, err
+	}
+
+	advice := &Advice{
+		Current: cVersion.Status.Desired.Version,
+		Windows: windows,
+	}
+	for _, update := range cVersion.Status.AvailableUpdates {
+		edge := EdgeAdvice{
+			Version:  update.Version,
+			Image:    update.Image,
+			Estimate: history.Estimate(update.Version),
+		}
+
+		// admin gates only block upgrades to a new minor version
+		if minorVersion(update.Version) != minorVersion(advice.Current) {
+			edge.Gates = gates
+		}
+
+		edge.Conflict, edge.NextWindow = planEdge(windows, start, edge.Estimate.Duration)
+		advice.Edges = append(advice.Edges, edge)
+	}
+
+	sort.Slice(advice.Edges, func(i, j int) bool {
+		return newer(advice.Edges[i].Version, advice.Edges[j].Version)
+	})
+	return advice, nil
+}
+
+// newer returns true if version a is newer than b, comparing them as strings if they aren't semantic versions.
+func newer(a, b string) bool {
+	va, errA := semver.NewVersion(a)
+	vb, errB := semver.NewVersion(b)
+	if errA != nil || errB != nil {
+		return a > b
+	}
+	return va.GreaterThan(vb)
+}
+
+// planEdge returns a conflict if an upgrade taking d at start isn't within one of windows and when it could next run.
+func planEdge(windows []MaintenanceWindow, start time.Time, d time.Duration) (conflict string, next *time.Time) {
+	if len(windows) == 0 {
+		return "", nil
+	}
+
+	if t, ok := NextFit(windows, start, d); !ok {
+		return fmt.Sprintf("estimated %v is longer than every maintenance window", d.Round(time.Minute)), nil
+	} else if t.Equal(start) {
+		return "", &t
+	} else {
+		return fmt.Sprintf("starting at %s would not complete within a maintenance window", start.UTC().Format(time.RFC3339)), &t
+	}
+}
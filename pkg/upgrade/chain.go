@@ -0,0 +1,113 @@
+package upgrade
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"k8s.io/test-infra/testgrid/metadata/junit"
+
+	"github.com/openshift/osde2e/pkg/config"
+	"github.com/openshift/osde2e/pkg/health"
+	"github.com/openshift/osde2e/pkg/junitprops"
+)
+
+const (
+	// name of the JUnit suite containing upgrade results
+	upgradeSuiteName = "OSD upgrade"
+
+	// property of hop results listing the admin gates acknowledged
+	ackedGatesProperty = "acked-admin-gates"
+
+	// property of failed hop results giving the reason of the failure
+	failureReasonProperty = "failure-reason"
+)
+
+// Hops returns the images a cluster will be upgraded through in order.
+func Hops(cfg *config.Config) []string {
+	if len(cfg.UpgradeImages) != 0 {
+		return cfg.UpgradeImages
+	} else if cfg.UpgradeImage != "" {
+		return []string{cfg.UpgradeImage}
+	}
+	return nil
+}
+
+// HopResult is the outcome of upgrading to a single image in a chain.
+type HopResult struct {
+	// Num is the position of the hop in the chain starting at 1.
+	Num int
+
+	// Image is the release image upgraded to.
+	Image string
+
+	// Version is the version the cluster reports it was upgraded to. It's empty if the hop failed.
+	Version string
+
+	// Started is when the hop began.
+	Started time.Time
+
+	// Duration is how long the hop took, including health checks.
+	Duration time.Duration
+
+	// Health is the last health check of the cluster after the hop. It's nil if the hop failed before it was checked.
+	Health *health.Report
+
+	// AckedGates are the admin gates acknowledged to allow the hop.
+	AckedGates []string
+
+	// Err is set when the hop failed. It's a *Failure when the upgrade itself failed.
+	Err error
+}
+
+// Failure returns why the upgrade of the hop failed, if it did.
+func (r HopResult) Failure() (*Failure, bool) {
+	f, ok := r.Err.(*Failure)
+	return f, ok
+}
+
+// Name identifies the hop in results.
+func (r HopResult) Name() string {
+	return fmt.Sprintf("[upgrade] hop %d to %s", r.Num, r.Image)
+}
+
+// writeHopResults records a JUnit testcase for each hop so failures are attributed to the hop that caused them.
+func writeHopResults(cfg *config.Config, results []HopResult) {
+	if len(results) == 0 || cfg.ReportDir == "" {
+		return
+	}
+
+	suite := junit.Suite{
+		Name:  upgradeSuiteName,
+		Tests: len(results),
+	}
+	for _, r := range results {
+		result := junit.Result{
+			Name:      r.Name(),
+			ClassName: upgradeSuiteName,
+			Time:      r.Duration.Seconds(),
+		}
+		var props []junit.Property
+		if len(r.AckedGates) != 0 {
+			props = append(props, junit.Property{Name: ackedGatesProperty, Value: strings.Join(r.AckedGates, ",")})
+		}
+		if f, ok := r.Failure(); ok {
+			props = append(props, junit.Property{Name: failureReasonProperty, Value: string(f.Reason)})
+		}
+		if len(props) != 0 {
+			result.Properties = &junit.Properties{PropertyList: props}
+		}
+		if r.Err != nil {
+			msg := r.Err.Error()
+			result.Failure = &msg
+			suite.Failures++
+		}
+		suite.Time += result.Time
+		suite.Results = append(suite.Results, result)
+	}
+
+	if err := junitprops.WriteSuite(cfg.ReportDir, "upgrade", cfg.Suffix, suite); err != nil {
+		log.Printf("Failed to write upgrade results: %v", err)
+	}
+}
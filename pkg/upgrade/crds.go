@@ -0,0 +1,76 @@
+package upgrade
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/openshift/osde2e/pkg/config"
+	"github.com/openshift/osde2e/pkg/crd"
+	"github.com/openshift/osde2e/pkg/helper"
+)
+
+// crdChecker compares CRD schemas before and after each upgrade.
+type crdChecker struct {
+	cfg  *config.Config
+	prev map[string]*crd.CRD
+}
+
+// snapshot records the current CRD schemas as the baseline for the next check.
+func (c *crdChecker) snapshot(h *helper.H, hop int) (map[string]*crd.CRD, error) {
+	crds, err := crd.Snapshot(h)
+	if err != nil {
+		return nil, err
+	}
+	c.write(fmt.Sprintf("crd-schemas-%d.json", hop), crds)
+	return crds, nil
+}
+
+// check returns an error describing platform CRDs that changed incompatibly since the last snapshot.
+func (c *crdChecker) check(h *helper.H, hop int) error {
+	cur, err := c.snapshot(h, hop)
+	if err != nil {
+		log.Printf("Skipping CRD compatibility check: %v", err)
+		return nil
+	}
+
+	prev := c.prev
+	c.prev = cur
+	if prev == nil {
+		return nil
+	}
+
+	incompatible := crd.Compare(prev, cur)
+	if len(incompatible) == 0 {
+		return nil
+	}
+	c.write(fmt.Sprintf("crd-incompatibilities-%d.json", hop), incompatible)
+
+	msgs := make([]string, len(incompatible))
+	for i, inc := range incompatible {
+		msgs[i] = inc.String()
+	}
+	return fmt.Errorf("platform CRDs changed incompatibly:\n%s", strings.Join(msgs, "\n"))
+}
+
+func (c *crdChecker) write(name string, v interface{}) {
+	if c.cfg.ReportDir == "" {
+		return
+	}
+
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		log.Printf("Failed to encode '%s': %v", name, err)
+		return
+	}
+
+	os.MkdirAll(c.cfg.ReportDir, os.ModePerm)
+	filename := filepath.Join(c.cfg.ReportDir, name)
+	if err = ioutil.WriteFile(filename, data, os.ModePerm); err != nil {
+		log.Printf("Failed to write '%s': %v", filename, err)
+	}
+}
@@ -0,0 +1,98 @@
+package upgrade
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/test-infra/testgrid/metadata/junit"
+)
+
+// hopName matches the names of hop results, capturing the tag of the image upgraded to.
+var hopName = regexp.MustCompile(`^\[upgrade\] hop [0-9]+ to .*:([^:]+)$`)
+
+// History is the duration of previous successful upgrades by the version upgraded to.
+type History map[string][]time.Duration
+
+// Add records the successful hops in suites.
+func (h History) Add(suites junit.Suites) {
+	for _, suite := range suites.Suites {
+		if suite.Name != upgradeSuiteName {
+			continue
+		}
+		for _, r := range suite.Results {
+			match := hopName.FindStringSubmatch(r.Name)
+			if match == nil || r.Failure != nil {
+				continue
+			}
+			version := match[1]
+			h[version] = append(h[version], time.Duration(r.Time*float64(time.Second)))
+		}
+	}
+}
+
+// Estimate is the expected duration of an upgrade.
+type Estimate struct {
+	Duration time.Duration
+
+	// Samples is the number of previous upgrades the estimate is based on. It's 0 if there were none.
+	Samples int
+
+	// Basis describes which previous upgrades were used.
+	Basis string
+}
+
+func (e Estimate) String() string {
+	if e.Samples == 0 {
+		return fmt.Sprintf("%v (%s)", e.Duration, e.Basis)
+	}
+	return fmt.Sprintf("%v (median of %d upgrades to %s)", e.Duration.Round(time.Minute), e.Samples, e.Basis)
+}
+
+// Estimate returns the median duration of previous upgrades to version. Upgrades to the same minor version are
+// used if there are none to version, then all upgrades. MaxDuration is used without any history.
+func (h History) Estimate(version string) Estimate {
+	if d := h[version]; len(d) != 0 {
+		return Estimate{Duration: median(d), Samples: len(d), Basis: version}
+	}
+
+	minor := minorVersion(version)
+	var all, sameMinor []time.Duration
+	for v, d := range h {
+		all = append(all, d...)
+		if minor != "" && minorVersion(v) == minor {
+			sameMinor = append(sameMinor, d...)
+		}
+	}
+
+	if len(sameMinor) != 0 {
+		return Estimate{Duration: median(sameMinor), Samples: len(sameMinor), Basis: minor + ".x"}
+	} else if len(all) != 0 {
+		return Estimate{Duration: median(all), Samples: len(all), Basis: "any version"}
+	}
+	return Estimate{Duration: MaxDuration, Basis: "no history, upgrade timeout used"}
+}
+
+// minorVersion returns the major and minor parts of version, such as '4.2' for '4.2.0-0.nightly-2019-09-23'.
+func minorVersion(version string) string {
+	parts := strings.SplitN(strings.TrimPrefix(version, "v"), ".", 3)
+	if len(parts) < 2 {
+		return ""
+	}
+	return parts[0] + "." + parts[1]
+}
+
+func median(durations []time.Duration) time.Duration {
+	sorted := append([]time.Duration(nil), durations...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i] < sorted[j]
+	})
+
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
@@ -0,0 +1,49 @@
+package upgrade
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/test-infra/testgrid/metadata/junit"
+)
+
+func TestHistoryEstimate(t *testing.T) {
+	failure := "upgrade failed"
+	history := History{}
+	history.Add(junit.Suites{
+		Suites: []junit.Suite{
+			{
+				Name: upgradeSuiteName,
+				Results: []junit.Result{
+					{Name: "[upgrade] hop 1 to quay.io/openshift-release-dev/ocp-release:4.2.0", Time: 1800},
+					{Name: "[upgrade] hop 2 to quay.io/openshift-release-dev/ocp-release:4.2.2", Time: 2400},
+					{Name: "[upgrade] hop 3 to quay.io/openshift-release-dev/ocp-release:4.2.2", Time: 3000},
+					{Name: "[upgrade] hop 4 to quay.io/openshift-release-dev/ocp-release:4.3.0", Time: 600, Failure: &failure},
+				},
+			},
+			{
+				Name:    "other suite",
+				Results: []junit.Result{{Name: "[upgrade] hop 1 to registry:4.3.0", Time: 60}},
+			},
+		},
+	})
+
+	tests := []struct {
+		version  string
+		duration time.Duration
+		samples  int
+	}{
+		{"4.2.2", 45 * time.Minute, 2},
+		{"4.2.5", 40 * time.Minute, 3},
+		{"4.3.0", 40 * time.Minute, 3},
+	}
+	for _, tt := range tests {
+		if e := history.Estimate(tt.version); e.Duration != tt.duration || e.Samples != tt.samples {
+			t.Errorf("%s: expected %v from %d samples, got %v", tt.version, tt.duration, tt.samples, e)
+		}
+	}
+
+	if e := (History{}).Estimate("4.2.2"); e.Duration != MaxDuration || e.Samples != 0 {
+		t.Errorf("expected upgrade timeout without history, got %v", e)
+	}
+}
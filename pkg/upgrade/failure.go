@@ -0,0 +1,232 @@
+package upgrade
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openshift/osde2e/pkg/artifacts"
+	"github.com/openshift/osde2e/pkg/health"
+	"github.com/openshift/osde2e/pkg/helper"
+)
+
+// FailureFile is the name of the description of why the upgrade failed in the report directory.
+const FailureFile = "upgrade-failure.json"
+
+// failingCondition is reported true by the cluster-version-operator when it can't make progress.
+const failingCondition = configv1.ClusterStatusConditionType("Failing")
+
+// FailureReason is the kind of problem an upgrade failed because of.
+type FailureReason string
+
+const (
+	// ReasonPayload is when the release payload couldn't be retrieved or verified.
+	ReasonPayload FailureReason = "payload"
+
+	// ReasonPrecondition is when the cluster didn't meet the preconditions of upgrading.
+	ReasonPrecondition FailureReason = "precondition"
+
+	// ReasonOperatorDegraded is when ClusterOperators stayed degraded or unavailable while upgrading.
+	ReasonOperatorDegraded FailureReason = "operator-degraded"
+
+	// ReasonCVOFailing is when the cluster-version-operator reported failing for another reason.
+	ReasonCVOFailing FailureReason = "cvo-failing"
+
+	// ReasonRolledBack is when the cluster went back to its previous version instead of upgrading.
+	ReasonRolledBack FailureReason = "rolled-back"
+
+	// ReasonTimeout is when the upgrade didn't complete within MaxDuration without reporting a failure.
+	ReasonTimeout FailureReason = "timeout"
+)
+
+// failingReasons are the reasons of the cluster-version-operator's Failing condition by the FailureReason they
+// indicate. Other reasons are ReasonCVOFailing.
+var failingReasons = map[string]FailureReason{
+	"RetrievePayload":                ReasonPayload,
+	"UpdatePayloadRetrievalFailed":   ReasonPayload,
+	"ImageVerificationFailed":        ReasonPayload,
+	"UpdatePayloadIntegrity":         ReasonPayload,
+	"UpgradePreconditionCheckFailed": ReasonPrecondition,
+	"ClusterOperatorDegraded":        ReasonOperatorDegraded,
+	"ClusterOperatorsDegraded":       ReasonOperatorDegraded,
+	"ClusterOperatorNotAvailable":    ReasonOperatorDegraded,
+	"ClusterOperatorsNotAvailable":   ReasonOperatorDegraded,
+}
+
+// Failure describes why an upgrade failed.
+type Failure struct {
+	Reason FailureReason `json:"reason"`
+
+	// Version and Image are what the cluster was being upgraded to.
+	Version string `json:"version,omitempty"`
+	Image   string `json:"image,omitempty"`
+
+	// Message is what the cluster-version-operator reported.
+	Message string `json:"message,omitempty"`
+
+	// Operators are the ClusterOperators which were degraded or unavailable.
+	Operators []string `json:"operators,omitempty"`
+
+	// Since is when the failure was first reported.
+	Since time.Time `json:"since,omitempty"`
+
+	// Attempts is how many times the upgrade was triggered.
+	Attempts int `json:"attempts"`
+}
+
+func (f *Failure) Error() string {
+	desc := fmt.Sprintf("upgrade to %s failed because of %s", f.Version, f.Reason)
+	if len(f.Operators) != 0 {
+		desc += fmt.Sprintf(" (%s)", strings.Join(f.Operators, ", "))
+	}
+	if f.Message != "" {
+		desc += ": " + f.Message
+	}
+	return desc
+}
+
+// Retryable returns true if triggering the upgrade again may fix the failure. Clusters which rolled back were
+// deliberately returned to their previous version.
+func (f *Failure) Retryable() bool {
+	return f.Reason != ReasonRolledBack
+}
+
+// Metadata returns the reason and attempts of the failure, suitable for reporting.
+func (f *Failure) Metadata() map[string]interface{} {
+	return map[string]interface{}{
+		"upgrade-failure-reason":   string(f.Reason),
+		"upgrade-failure-attempts": f.Attempts,
+	}
+}
+
+// Write stores f in dir as FailureFile.
+func (f *Failure) Write(dir string) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("couldn't encode upgrade failure: %v", err)
+	}
+
+	os.MkdirAll(dir, os.ModePerm)
+	filename := filepath.Join(dir, FailureFile)
+	if err = ioutil.WriteFile(filename, data, os.ModePerm); err != nil {
+		return fmt.Errorf("couldn't write upgrade failure to '%s': %v", filename, err)
+	}
+	return nil
+}
+
+// DetectFailure returns why the upgrade of cv to desired, attempted since started, failed, or nil if it hasn't. Failures
+// must be reported for grace during the attempt before they're considered permanent, since operators commonly report
+// them briefly while upgrading and failures of earlier attempts are still reported while they're retried.
+func DetectFailure(cv *configv1.ClusterVersion, operators []configv1.ClusterOperator, desired *configv1.Update,
+	started, now time.Time, grace time.Duration) *Failure {
+	// the cluster stopped working towards the version requested
+	if cur := cv.Spec.DesiredUpdate; cur == nil || cur.Image != desired.Image || cur.Version != desired.Version {
+		f := &Failure{Reason: ReasonRolledBack, Version: desired.Version, Image: desired.Image, Since: now}
+		if cur != nil {
+			f.Message = fmt.Sprintf("desired update was changed to %s", cur.Version)
+		} else {
+			f.Message = "desired update was removed"
+		}
+		return f
+	}
+
+	// the cluster went back to an earlier version after partially upgrading
+	if history := cv.Status.History; len(history) > 1 && history[0].Version != desired.Version {
+		for _, entry := range history[1:] {
+			if entry.Version == desired.Version && entry.State == configv1.PartialUpdate {
+				return &Failure{
+					Reason:  ReasonRolledBack,
+					Version: desired.Version,
+					Image:   desired.Image,
+					Message: fmt.Sprintf("cluster returned to %s", history[0].Version),
+					Since:   history[0].StartedTime.Time,
+				}
+			}
+		}
+	}
+
+	for _, c := range cv.Status.Conditions {
+		since := c.LastTransitionTime.Time
+		if since.Before(started) {
+			since = started
+		}
+		if c.Type != failingCondition || c.Status != configv1.ConditionTrue || now.Sub(since) < grace {
+			continue
+		}
+
+		reason, ok := failingReasons[c.Reason]
+		if !ok {
+			reason = ReasonCVOFailing
+		}
+		f := &Failure{
+			Reason:  reason,
+			Version: desired.Version,
+			Image:   desired.Image,
+			Message: c.Message,
+			Since:   since,
+		}
+		if reason == ReasonOperatorDegraded {
+			f.Operators = health.UnhealthyOperators(operators)
+		}
+		return f
+	}
+	return nil
+}
+
+// checkFailure returns why the upgrade of the cluster accessed by h to desired, attempted since started, failed, or nil
+// if it hasn't or its state couldn't be retrieved.
+func checkFailure(h *helper.H, desired *configv1.Update, started time.Time) *Failure {
+	cv, err := h.Cfg().ConfigV1().ClusterVersions().Get(ClusterVersionName, metav1.GetOptions{})
+	if err != nil {
+		log.Printf("Error getting ClusterVersion '%s': %v", ClusterVersionName, err)
+		return nil
+	}
+
+	var operators []configv1.ClusterOperator
+	if list, err := h.Cfg().ConfigV1().ClusterOperators().List(metav1.ListOptions{}); err != nil {
+		log.Printf("Error listing ClusterOperators: %v", err)
+	} else {
+		operators = list.Items
+	}
+	return DetectFailure(cv, operators, desired, started, time.Now(), h.UpgradeFailureGrace)
+}
+
+// storeDiagnostics stores the ClusterVersion and ClusterOperators of the cluster accessed by h with the artifacts of
+// the run, describing the state of the failed upgrade.
+func storeDiagnostics(h *helper.H, attempt int) {
+	if artifacts.Current == nil {
+		return
+	}
+
+	state := map[string]interface{}{}
+	if cv, err := h.Cfg().ConfigV1().ClusterVersions().Get(ClusterVersionName, metav1.GetOptions{}); err != nil {
+		log.Printf("Failed to get ClusterVersion for diagnostics: %v", err)
+	} else {
+		state["clusterVersion"] = cv
+	}
+	if list, err := h.Cfg().ConfigV1().ClusterOperators().List(metav1.ListOptions{}); err != nil {
+		log.Printf("Failed to list ClusterOperators for diagnostics: %v", err)
+	} else {
+		sort.Slice(list.Items, func(i, j int) bool { return list.Items[i].Name < list.Items[j].Name })
+		state["clusterOperators"] = list.Items
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		log.Printf("Failed to encode upgrade diagnostics: %v", err)
+		return
+	}
+	name := fmt.Sprintf("upgrade-failure-%d.json", attempt)
+	if err = artifacts.Current.Write(artifacts.State, name, data); err != nil {
+		log.Printf("Failed to store upgrade diagnostics: %v", err)
+	}
+}
@@ -0,0 +1,148 @@
+package upgrade
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDetectFailure(t *testing.T) {
+	now := time.Now()
+	desired := &configv1.Update{Version: "4.3.1", Image: "quay.io/openshift-release-dev/ocp-release:4.3.1"}
+	failing := func(reason string, since time.Duration) configv1.ClusterOperatorStatusCondition {
+		return configv1.ClusterOperatorStatusCondition{
+			Type:               failingCondition,
+			Status:             configv1.ConditionTrue,
+			Reason:             reason,
+			Message:            "upgrade is stuck",
+			LastTransitionTime: metav1.NewTime(now.Add(-since)),
+		}
+	}
+	operators := []configv1.ClusterOperator{{
+		ObjectMeta: metav1.ObjectMeta{Name: "authentication"},
+		Status: configv1.ClusterOperatorStatus{Conditions: []configv1.ClusterOperatorStatusCondition{
+			{Type: configv1.OperatorAvailable, Status: configv1.ConditionFalse},
+		}},
+	}}
+
+	for name, test := range map[string]struct {
+		cv        configv1.ClusterVersion
+		started   time.Duration
+		reason    FailureReason
+		operators []string
+	}{
+		"progressing": {},
+		"briefly failing": {
+			cv: configv1.ClusterVersion{Status: configv1.ClusterVersionStatus{
+				Conditions: []configv1.ClusterOperatorStatusCondition{failing("RetrievePayload", time.Minute)},
+			}},
+		},
+		"payload": {
+			cv: configv1.ClusterVersion{Status: configv1.ClusterVersionStatus{
+				Conditions: []configv1.ClusterOperatorStatusCondition{failing("RetrievePayload", time.Hour)},
+			}},
+			reason: ReasonPayload,
+		},
+		"operator degraded": {
+			cv: configv1.ClusterVersion{Status: configv1.ClusterVersionStatus{
+				Conditions: []configv1.ClusterOperatorStatusCondition{failing("ClusterOperatorNotAvailable", time.Hour)},
+			}},
+			reason:    ReasonOperatorDegraded,
+			operators: []string{"authentication"},
+		},
+		"failing before retry": {
+			cv: configv1.ClusterVersion{Status: configv1.ClusterVersionStatus{
+				Conditions: []configv1.ClusterOperatorStatusCondition{failing("RetrievePayload", time.Hour)},
+			}},
+			started: 5 * time.Minute,
+		},
+		"failing since retry": {
+			cv: configv1.ClusterVersion{Status: configv1.ClusterVersionStatus{
+				Conditions: []configv1.ClusterOperatorStatusCondition{failing("RetrievePayload", 2*time.Hour)},
+			}},
+			started: time.Hour,
+			reason:  ReasonPayload,
+		},
+		"unknown reason": {
+			cv: configv1.ClusterVersion{Status: configv1.ClusterVersionStatus{
+				Conditions: []configv1.ClusterOperatorStatusCondition{failing("SomethingNew", time.Hour)},
+			}},
+			reason: ReasonCVOFailing,
+		},
+		"returned to previous version": {
+			cv: configv1.ClusterVersion{Status: configv1.ClusterVersionStatus{
+				History: []configv1.UpdateHistory{
+					{Version: "4.3.0", State: configv1.CompletedUpdate},
+					{Version: "4.3.1", State: configv1.PartialUpdate},
+					{Version: "4.3.0", State: configv1.CompletedUpdate},
+				},
+			}},
+			reason: ReasonRolledBack,
+		},
+		"desired update changed": {
+			cv: configv1.ClusterVersion{Spec: configv1.ClusterVersionSpec{
+				DesiredUpdate: &configv1.Update{Version: "4.3.0"},
+			}},
+			reason: ReasonRolledBack,
+		},
+	} {
+		cv := test.cv
+		if cv.Spec.DesiredUpdate == nil {
+			cv.Spec.DesiredUpdate = desired
+		}
+		started := now.Add(-3 * time.Hour)
+		if test.started != 0 {
+			started = now.Add(-test.started)
+		}
+
+		f := DetectFailure(&cv, operators, desired, started, now, 20*time.Minute)
+		if test.reason == "" {
+			if f != nil {
+				t.Errorf("%s: expected no failure, got: %v", name, f)
+			}
+			continue
+		} else if f == nil {
+			t.Errorf("%s: expected failure because of %s", name, test.reason)
+			continue
+		}
+
+		if f.Reason != test.reason {
+			t.Errorf("%s: expected reason %s, got %s", name, test.reason, f.Reason)
+		}
+		if !reflect.DeepEqual(f.Operators, test.operators) {
+			t.Errorf("%s: expected operators %v, got %v", name, test.operators, f.Operators)
+		}
+		if f.Retryable() == (test.reason == ReasonRolledBack) {
+			t.Errorf("%s: expected only rolled back upgrades not to be retryable", name)
+		}
+	}
+}
+
+func TestWriteFailure(t *testing.T) {
+	dir, err := ioutil.TempDir("", "upgrade-failure")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	f := &Failure{Reason: ReasonPayload, Version: "4.3.1", Attempts: 2}
+	if err = f.Write(dir); err != nil {
+		t.Fatalf("failed to write failure: %v", err)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, FailureFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var read Failure
+	if err = json.Unmarshal(data, &read); err != nil || read.Reason != ReasonPayload || read.Attempts != 2 {
+		t.Errorf("expected failure to be readable, got %+v: %v", read, err)
+	}
+}
@@ -0,0 +1,136 @@
+package upgrade
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+
+	kubev1 "k8s.io/api/core/v1"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openshift/osde2e/pkg/helper"
+)
+
+const (
+	// AdminGatesNamespace contains the gates an administrator must acknowledge before upgrading.
+	AdminGatesNamespace = "openshift-config-managed"
+
+	// AdminGatesConfigMap lists gates by name with their descriptions.
+	AdminGatesConfigMap = "admin-gates"
+
+	// AdminAcksNamespace contains the acknowledgements of admin gates.
+	AdminAcksNamespace = "openshift-config"
+
+	// AdminAcksConfigMap has the names of acknowledged gates set to "true".
+	AdminAcksConfigMap = "admin-acks"
+)
+
+// gateVersion matches the version a gate applies to, such as 'ack-4.8-kube-1.22-api-removals-in-4.9'.
+var gateVersion = regexp.MustCompile(`^ack-([0-9]+[.][0-9]+)-[^-]`)
+
+// AdminGate must be acknowledged by an administrator before upgrading.
+type AdminGate struct {
+	Name        string
+	Description string
+}
+
+// PendingAdminGates returns gates that haven't been acknowledged and apply to the current cluster version.
+func PendingAdminGates(h *helper.H) ([]AdminGate, error) {
+	configMaps := h.Kube().CoreV1().ConfigMaps
+	gates, err := configMaps(AdminGatesNamespace).Get(AdminGatesConfigMap, metav1.GetOptions{})
+	if kerror.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("couldn't get admin gates: %v", err)
+	}
+
+	acks, err := configMaps(AdminAcksNamespace).Get(AdminAcksConfigMap, metav1.GetOptions{})
+	if err != nil && !kerror.IsNotFound(err) {
+		return nil, fmt.Errorf("couldn't get admin acks: %v", err)
+	}
+
+	cVersion, err := h.Cfg().ConfigV1().ClusterVersions().Get(ClusterVersionName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get current ClusterVersion '%s': %v", ClusterVersionName, err)
+	}
+	return pendingGates(gates.Data, acks.Data, cVersion.Status.Desired.Version), nil
+}
+
+// pendingGates returns gates for version that aren't acknowledged in acks.
+func pendingGates(gates, acks map[string]string, version string) (pending []AdminGate) {
+	for name, desc := range gates {
+		match := gateVersion.FindStringSubmatch(name)
+		if match == nil || !strings.HasPrefix(version, match[1]+".") || acks[name] == "true" {
+			continue
+		}
+		pending = append(pending, AdminGate{
+			Name:        name,
+			Description: desc,
+		})
+	}
+
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].Name < pending[j].Name
+	})
+	return
+}
+
+// AckAdminGates acknowledges gates so the upgrade may proceed.
+func AckAdminGates(h *helper.H, gates []AdminGate) error {
+	configMaps := h.Kube().CoreV1().ConfigMaps(AdminAcksNamespace)
+	acks, err := configMaps.Get(AdminAcksConfigMap, metav1.GetOptions{})
+	if kerror.IsNotFound(err) {
+		acks = &kubev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      AdminAcksConfigMap,
+				Namespace: AdminAcksNamespace,
+			},
+		}
+	} else if err != nil {
+		return fmt.Errorf("couldn't get admin acks: %v", err)
+	}
+
+	if acks.Data == nil {
+		acks.Data = map[string]string{}
+	}
+	for _, gate := range gates {
+		acks.Data[gate.Name] = "true"
+	}
+
+	if acks.ResourceVersion == "" {
+		_, err = configMaps.Create(acks)
+	} else {
+		_, err = configMaps.Update(acks)
+	}
+	if err != nil {
+		return fmt.Errorf("couldn't save admin acks: %v", err)
+	}
+	return nil
+}
+
+// handleAdminGates acknowledges pending gates if configured to, returning the names of those acknowledged.
+func handleAdminGates(h *helper.H) ([]string, error) {
+	gates, err := PendingAdminGates(h)
+	if err != nil || len(gates) == 0 {
+		return nil, err
+	}
+
+	names := make([]string, len(gates))
+	for i, gate := range gates {
+		names[i] = gate.Name
+		log.Printf("Upgrade requires acknowledging admin gate '%s': %s", gate.Name, gate.Description)
+	}
+
+	if !h.UpgradeAckGates {
+		return nil, fmt.Errorf("upgrade is blocked by unacknowledged admin gates %v, set UPGRADE_ACK_GATES to acknowledge them", names)
+	}
+
+	if err = AckAdminGates(h, gates); err != nil {
+		return nil, err
+	}
+	log.Printf("Acknowledged admin gates %v.", names)
+	return names, nil
+}
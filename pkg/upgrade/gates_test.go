@@ -0,0 +1,30 @@
+package upgrade
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPendingGates(t *testing.T) {
+	gates := map[string]string{
+		"ack-4.8-kube-1.22-api-removals-in-4.9":  "Kubernetes 1.22 removes several APIs.",
+		"ack-4.8-cgroups-v2":                     "Nodes move to cgroups v2.",
+		"ack-4.9-kube-1.23-api-removals-in-4.10": "Kubernetes 1.23 removes several APIs.",
+		"not-a-gate":                             "Malformed gates are ignored.",
+	}
+	acks := map[string]string{
+		"ack-4.8-cgroups-v2": "true",
+	}
+
+	pending := pendingGates(gates, acks, "4.8.12")
+	expected := []AdminGate{
+		{Name: "ack-4.8-kube-1.22-api-removals-in-4.9", Description: "Kubernetes 1.22 removes several APIs."},
+	}
+	if !reflect.DeepEqual(pending, expected) {
+		t.Errorf("expected pending gates %v, got %v", expected, pending)
+	}
+
+	if pending = pendingGates(gates, nil, "4.10.3"); len(pending) != 0 {
+		t.Errorf("gates for other versions shouldn't be pending, got %v", pending)
+	}
+}
@@ -0,0 +1,133 @@
+package upgrade
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// MUONamespace is where the managed-upgrade-operator runs and reads UpgradeConfigs from.
+	MUONamespace = "openshift-managed-upgrade-operator"
+
+	// MUODeployment runs the managed-upgrade-operator.
+	MUODeployment = "managed-upgrade-operator"
+
+	// upgradeTypeOSD is the type of UpgradeConfigs for OSD clusters.
+	upgradeTypeOSD = "OSD"
+)
+
+// Phases of managed upgrades.
+const (
+	PhasePending   = "Pending"
+	PhaseUpgrading = "Upgrading"
+	PhaseUpgraded  = "Upgraded"
+	PhaseFailed    = "Failed"
+)
+
+// Conditions recorded by the managed-upgrade-operator as it performs the steps of an upgrade.
+const (
+	// ConditionPreHealthCheck is true once the cluster was found healthy enough to upgrade.
+	ConditionPreHealthCheck = "PreHealthCheck"
+
+	// ConditionScaleUpExtraNodes is true once extra workers were added to reserve capacity during the upgrade.
+	ConditionScaleUpExtraNodes = "ScaleUpExtraNodes"
+
+	// ConditionCommenceUpgrade is true once the ClusterVersion was updated to start the upgrade.
+	ConditionCommenceUpgrade = "CommenceUpgrade"
+)
+
+// UpgradeConfigs ask the managed-upgrade-operator to upgrade the cluster.
+var UpgradeConfigs = schema.GroupVersionResource{
+	Group:    "upgrade.managed.openshift.io",
+	Version:  "v1alpha1",
+	Resource: "upgradeconfigs",
+}
+
+// UpgradePolicy is an upgrade requested from the managed-upgrade-operator.
+type UpgradePolicy struct {
+	Name    string
+	Version string
+	Channel string
+
+	// UpgradeAt is when the upgrade may start.
+	UpgradeAt time.Time
+
+	// PDBForceDrainTimeout is how long nodes are drained while PodDisruptionBudgets prevent it before pods are
+	// removed anyway.
+	PDBForceDrainTimeout time.Duration
+
+	// CapacityReservation adds workers before upgrading so workloads have room while nodes are drained.
+	CapacityReservation bool
+}
+
+// UpgradeConfig returns the UpgradeConfig requesting p.
+func (p UpgradePolicy) UpgradeConfig() *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": UpgradeConfigs.GroupVersion().String(),
+		"kind":       "UpgradeConfig",
+		"metadata": map[string]interface{}{
+			"name":      p.Name,
+			"namespace": MUONamespace,
+		},
+		"spec": map[string]interface{}{
+			"type":                 upgradeTypeOSD,
+			"upgradeAt":            p.UpgradeAt.UTC().Format(time.RFC3339),
+			"PDBForceDrainTimeout": int64(p.PDBForceDrainTimeout.Minutes()),
+			"capacityReservation":  p.CapacityReservation,
+			"desired": map[string]interface{}{
+				"version": p.Version,
+				"channel": p.Channel,
+			},
+		},
+	}}
+}
+
+// ManagedUpgrade is the progress of an upgrade recorded in the history of an UpgradeConfig.
+type ManagedUpgrade struct {
+	Version string
+	Phase   string
+
+	StartTime    time.Time
+	CompleteTime time.Time
+
+	// Conditions are the statuses of the steps of the upgrade by type.
+	Conditions map[string]string
+}
+
+// Commenced returns true if the upgrade was started.
+func (u ManagedUpgrade) Commenced() bool {
+	return u.Phase == PhaseUpgrading || u.Phase == PhaseUpgraded || u.Conditions[ConditionCommenceUpgrade] == "True"
+}
+
+// ManagedUpgradeOf returns the upgrade to version recorded in the history of the UpgradeConfig obj, if any.
+func ManagedUpgradeOf(obj *unstructured.Unstructured, version string) (ManagedUpgrade, bool) {
+	history, _, _ := unstructured.NestedSlice(obj.Object, "status", "history")
+	for _, item := range history {
+		entry := asMap(item)
+		if v, _, _ := unstructured.NestedString(entry, "version"); v != version {
+			continue
+		}
+
+		u := ManagedUpgrade{Version: version, Conditions: map[string]string{}}
+		u.Phase, _, _ = unstructured.NestedString(entry, "phase")
+		u.StartTime = nestedTime(entry, "startTime")
+		u.CompleteTime = nestedTime(entry, "completeTime")
+
+		conditions, _, _ := unstructured.NestedSlice(entry, "conditions")
+		for _, c := range conditions {
+			condition := asMap(c)
+			condType, _, _ := unstructured.NestedString(condition, "type")
+			u.Conditions[condType], _, _ = unstructured.NestedString(condition, "status")
+		}
+		return u, true
+	}
+	return ManagedUpgrade{}, false
+}
+
+func nestedTime(obj map[string]interface{}, fields ...string) time.Time {
+	s, _, _ := unstructured.NestedString(obj, fields...)
+	t, _ := time.Parse(time.RFC3339, s)
+	return t
+}
@@ -0,0 +1,70 @@
+package upgrade
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestUpgradeConfig(t *testing.T) {
+	at := time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)
+	obj := UpgradePolicy{
+		Name:                 "osde2e",
+		Version:              "4.3.5",
+		Channel:              "stable-4.3",
+		UpgradeAt:            at,
+		PDBForceDrainTimeout: 5 * time.Minute,
+		CapacityReservation:  true,
+	}.UpgradeConfig()
+
+	if obj.GetNamespace() != MUONamespace || obj.GetKind() != "UpgradeConfig" {
+		t.Errorf("unexpected UpgradeConfig: %v", obj.Object)
+	}
+	if v, _, _ := unstructured.NestedString(obj.Object, "spec", "desired", "version"); v != "4.3.5" {
+		t.Errorf("expected desired version 4.3.5, got '%s'", v)
+	}
+	if s, _, _ := unstructured.NestedString(obj.Object, "spec", "upgradeAt"); s != "2020-03-01T12:00:00Z" {
+		t.Errorf("expected upgradeAt in RFC 3339, got '%s'", s)
+	}
+	if timeout, _, _ := unstructured.NestedInt64(obj.Object, "spec", "PDBForceDrainTimeout"); timeout != 5 {
+		t.Errorf("expected drain timeout in minutes, got %d", timeout)
+	}
+}
+
+func TestManagedUpgradeOf(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"status": map[string]interface{}{
+			"history": []interface{}{
+				map[string]interface{}{"version": "4.3.1", "phase": PhaseUpgraded},
+				map[string]interface{}{
+					"version":   "4.3.5",
+					"phase":     PhaseUpgrading,
+					"startTime": "2020-03-01T12:01:00Z",
+					"conditions": []interface{}{
+						map[string]interface{}{"type": ConditionPreHealthCheck, "status": "True"},
+						map[string]interface{}{"type": ConditionCommenceUpgrade, "status": "True"},
+					},
+				},
+			},
+		},
+	}}
+
+	u, ok := ManagedUpgradeOf(obj, "4.3.5")
+	if !ok {
+		t.Fatal("expected upgrade to 4.3.5 to be found")
+	} else if !u.Commenced() || u.Conditions[ConditionPreHealthCheck] != "True" {
+		t.Errorf("expected upgrade to have commenced after its health check, got %+v", u)
+	} else if !u.StartTime.Equal(time.Date(2020, 3, 1, 12, 1, 0, 0, time.UTC)) {
+		t.Errorf("unexpected start time %v", u.StartTime)
+	}
+
+	if _, ok = ManagedUpgradeOf(obj, "4.4.0"); ok {
+		t.Error("expected no upgrade to 4.4.0")
+	}
+
+	pending := ManagedUpgrade{Phase: PhasePending, Conditions: map[string]string{ConditionPreHealthCheck: "False"}}
+	if pending.Commenced() {
+		t.Error("expected pending upgrade not to have commenced")
+	}
+}
@@ -0,0 +1,254 @@
+package upgrade
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/test-infra/testgrid/metadata/junit"
+
+	"github.com/openshift/osde2e/pkg/config"
+	"github.com/openshift/osde2e/pkg/helper"
+	"github.com/openshift/osde2e/pkg/junitprops"
+)
+
+const (
+	// name of the JUnit suite reporting use of APIs removed by the upgrade
+	removalsSuiteName = "API removals"
+
+	// serviceAccountPrefix begins the usernames of service accounts, followed by their namespace and name.
+	serviceAccountPrefix = "system:serviceaccount:"
+)
+
+var (
+	// apiRequestCounts count the requests of each API by user over the last day.
+	apiRequestCounts = schema.GroupVersionResource{Group: "apiserver.openshift.io", Version: "v1", Resource: "apirequestcounts"}
+
+	// openshiftVersion matches the major and minor version of OpenShift in release names and images.
+	openshiftVersion = regexp.MustCompile(`(?:^|[^0-9.])4\.([0-9]+)\.[0-9]+`)
+)
+
+// RemovedAPIUsage is a client's requests of an API removed in a Kubernetes version the cluster is being upgraded to.
+type RemovedAPIUsage struct {
+	// API is the resource, version, and group used, such as 'ingresses.v1beta1.extensions'.
+	API string `json:"api"`
+
+	// RemovedIn is the Kubernetes version the API is removed in, such as '1.22'.
+	RemovedIn string `json:"removedIn"`
+
+	User      string `json:"user"`
+	UserAgent string `json:"userAgent,omitempty"`
+
+	// Namespace of the user if it's a service account.
+	Namespace string `json:"namespace,omitempty"`
+
+	// Requests made by the user in the last day.
+	Requests int64 `json:"requests"`
+}
+
+func (u RemovedAPIUsage) String() string {
+	client := u.User
+	if u.UserAgent != "" {
+		client += " (" + u.UserAgent + ")"
+	}
+	return fmt.Sprintf("%s made %d requests in the last day", client, u.Requests)
+}
+
+// KubeVersion returns the Kubernetes minor version of the first OpenShift 4 version found in s, such as '1.22' for
+// 'quay.io/openshift-release-dev/ocp-release:4.9.0-x86_64'.
+func KubeVersion(s string) (string, bool) {
+	m := openshiftVersion.FindStringSubmatch(s)
+	if m == nil {
+		return "", false
+	}
+	minor, _ := strconv.Atoi(m[1])
+	if minor < 3 {
+		return fmt.Sprintf("1.%d", minor+12), true
+	}
+	return fmt.Sprintf("1.%d", minor+13), true
+}
+
+// targetVersion returns the Kubernetes version of what cfg upgrades to last.
+func targetVersion(cfg *config.Config) (string, bool) {
+	if hops := Hops(cfg); len(hops) != 0 {
+		if v, ok := KubeVersion(hops[len(hops)-1]); ok {
+			return v, true
+		}
+	}
+	return KubeVersion(cfg.UpgradeReleaseName)
+}
+
+// RemovedAPIsUsed returns clients which requested APIs removed in Kubernetes versions up to target in the last day,
+// using the APIRequestCounts of the cluster. Nothing is returned for clusters without them.
+func RemovedAPIsUsed(h *helper.H, target string) ([]RemovedAPIUsage, error) {
+	list, err := h.Dynamic().Resource(apiRequestCounts).List(metav1.ListOptions{})
+	if err != nil {
+		if _, discoveryErr := h.Discovery().ServerResourcesForGroupVersion(apiRequestCounts.GroupVersion().String()); discoveryErr != nil {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("couldn't list %s: %v", apiRequestCounts.Resource, err)
+	}
+	return removedUsage(list.Items, target), nil
+}
+
+// removedUsage returns the usage recorded in counts of APIs removed in Kubernetes versions up to target, ordered by
+// API then most requests.
+func removedUsage(counts []unstructured.Unstructured, target string) (usage []RemovedAPIUsage) {
+	for _, c := range counts {
+		removedIn, _, _ := unstructured.NestedString(c.Object, "status", "removedInRelease")
+		if removedIn == "" || !versionAtMost(removedIn, target) {
+			continue
+		}
+
+		byClient := map[[2]string]int64{}
+		hours, _, _ := unstructured.NestedSlice(c.Object, "status", "last24h")
+		for _, hour := range hours {
+			nodes, _, _ := unstructured.NestedSlice(asMap(hour), "byNode")
+			for _, node := range nodes {
+				users, _, _ := unstructured.NestedSlice(asMap(node), "byUser")
+				for _, user := range users {
+					u := asMap(user)
+					name, _, _ := unstructured.NestedString(u, "username")
+					agent, _, _ := unstructured.NestedString(u, "userAgent")
+					count, _, _ := unstructured.NestedInt64(u, "requestCount")
+					byClient[[2]string{name, agent}] += count
+				}
+			}
+		}
+
+		for client, requests := range byClient {
+			if requests == 0 {
+				continue
+			}
+			u := RemovedAPIUsage{
+				API:       c.GetName(),
+				RemovedIn: removedIn,
+				User:      client[0],
+				UserAgent: client[1],
+				Requests:  requests,
+			}
+			if strings.HasPrefix(u.User, serviceAccountPrefix) {
+				u.Namespace = strings.SplitN(strings.TrimPrefix(u.User, serviceAccountPrefix), ":", 2)[0]
+			}
+			usage = append(usage, u)
+		}
+	}
+
+	sort.Slice(usage, func(i, j int) bool {
+		if usage[i].API != usage[j].API {
+			return usage[i].API < usage[j].API
+		} else if usage[i].Requests != usage[j].Requests {
+			return usage[i].Requests > usage[j].Requests
+		}
+		return usage[i].User+usage[i].UserAgent < usage[j].User+usage[j].UserAgent
+	})
+	return
+}
+
+// checkRemovedAPIs reports clients using APIs removed by the version the cluster is upgraded to. Their use is
+// informing, so doesn't stop the upgrade.
+func checkRemovedAPIs(h *helper.H, cfg *config.Config) {
+	target, ok := targetVersion(cfg)
+	if !ok {
+		log.Println("Not checking for use of removed APIs, the version being upgraded to isn't known.")
+		return
+	}
+
+	usage, err := RemovedAPIsUsed(h, target)
+	if err != nil {
+		log.Printf("Failed to check for use of APIs removed in Kubernetes %s: %v", target, err)
+		return
+	}
+	for _, u := range usage {
+		log.Printf("API %s removed in Kubernetes %s is used: %s", u.API, u.RemovedIn, u)
+	}
+	writeRemovalsJUnit(cfg, target, usage)
+}
+
+// writeRemovalsJUnit records a testcase failing for each removed API in use, or a passing testcase if none are.
+func writeRemovalsJUnit(cfg *config.Config, target string, usage []RemovedAPIUsage) {
+	if cfg.ReportDir == "" {
+		return
+	}
+
+	var apis []string
+	byAPI := map[string][]RemovedAPIUsage{}
+	for _, u := range usage {
+		if _, ok := byAPI[u.API]; !ok {
+			apis = append(apis, u.API)
+		}
+		byAPI[u.API] = append(byAPI[u.API], u)
+	}
+
+	suite := junit.Suite{Name: removalsSuiteName}
+	if len(apis) == 0 {
+		suite.Results = append(suite.Results, junit.Result{
+			Name:      fmt.Sprintf("[api-removals] APIs removed in Kubernetes %s should not be used", target),
+			ClassName: removalsSuiteName,
+		})
+	}
+	for _, api := range apis {
+		clients := byAPI[api]
+		lines := make([]string, len(clients))
+		namespaces := map[string]bool{}
+		for i, u := range clients {
+			lines[i] = u.String()
+			if u.Namespace != "" {
+				namespaces[u.Namespace] = true
+			}
+		}
+
+		msg := fmt.Sprintf("%s is removed in Kubernetes %s but used by %d clients", api, clients[0].RemovedIn, len(clients))
+		if len(namespaces) != 0 {
+			msg += " in namespaces " + strings.Join(sortedKeys(namespaces), ", ")
+		}
+		output := strings.Join(lines, "\n")
+		suite.Results = append(suite.Results, junit.Result{
+			Name:      fmt.Sprintf("[api-removals] %s should not be used", api),
+			ClassName: removalsSuiteName,
+			Failure:   &msg,
+			Output:    &output,
+		})
+		suite.Failures++
+	}
+	suite.Tests = len(suite.Results)
+
+	if err := junitprops.WriteSuite(cfg.ReportDir, "api_removals", cfg.Suffix, suite); err != nil {
+		log.Printf("Failed to write API removal results: %v", err)
+	}
+}
+
+// versionAtMost returns true if the Kubernetes version a, such as '1.22', isn't newer than b.
+func versionAtMost(a, b string) bool {
+	aMinor, aErr := kubeMinor(a)
+	bMinor, bErr := kubeMinor(b)
+	return aErr == nil && bErr == nil && aMinor <= bMinor
+}
+
+func kubeMinor(v string) (int, error) {
+	parts := strings.SplitN(strings.TrimPrefix(v, "v"), ".", 3)
+	if len(parts) < 2 || parts[0] != "1" {
+		return 0, fmt.Errorf("invalid Kubernetes version '%s'", v)
+	}
+	return strconv.Atoi(parts[1])
+}
+
+func asMap(v interface{}) map[string]interface{} {
+	m, _ := v.(map[string]interface{})
+	return m
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,110 @@
+package upgrade
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/openshift/osde2e/pkg/config"
+)
+
+func TestKubeVersion(t *testing.T) {
+	for _, test := range []struct {
+		s, expected string
+	}{
+		{"quay.io/openshift-release-dev/ocp-release:4.9.0-x86_64", "1.22"},
+		{"4.10.0-0.nightly-2021-12-01-000000", "1.23"},
+		{"openshift-v4.3.8", "1.16"},
+		{"4.2.16", "1.14"},
+		{"quay.io/openshift-release-dev/ocp-release@sha256:abc", ""},
+	} {
+		if v, _ := KubeVersion(test.s); v != test.expected {
+			t.Errorf("expected Kubernetes version of '%s' to be '%s', got '%s'", test.s, test.expected, v)
+		}
+	}
+}
+
+func requestCount(name, removedIn string, users ...map[string]interface{}) unstructured.Unstructured {
+	byUser := make([]interface{}, len(users))
+	for i, u := range users {
+		byUser[i] = u
+	}
+	return unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": name},
+		"status": map[string]interface{}{
+			"removedInRelease": removedIn,
+			"last24h": []interface{}{
+				map[string]interface{}{"byNode": []interface{}{
+					map[string]interface{}{"nodeName": "master-0", "byUser": byUser},
+					map[string]interface{}{"nodeName": "master-1", "byUser": byUser},
+				}},
+			},
+		},
+	}}
+}
+
+func user(name, agent string, count int64) map[string]interface{} {
+	return map[string]interface{}{"username": name, "userAgent": agent, "requestCount": count}
+}
+
+func TestRemovedUsage(t *testing.T) {
+	counts := []unstructured.Unstructured{
+		requestCount("ingresses.v1beta1.extensions", "1.22",
+			user("system:serviceaccount:redhat-rhmi:operator", "rhmi-operator/v0.0.0", 5),
+			user("kube:admin", "oc/4.8", 1)),
+		requestCount("flowschemas.v1beta1.flowcontrol.apiserver.k8s.io", "1.26",
+			user("system:serviceaccount:openshift-apiserver:apiserver", "apiserver", 100)),
+		requestCount("pods.v1", "", user("system:admin", "oc", 50)),
+		requestCount("customresourcedefinitions.v1beta1.apiextensions.k8s.io", "1.22", user("idle", "", 0)),
+	}
+
+	expected := []RemovedAPIUsage{
+		{API: "ingresses.v1beta1.extensions", RemovedIn: "1.22", User: "system:serviceaccount:redhat-rhmi:operator",
+			UserAgent: "rhmi-operator/v0.0.0", Namespace: "redhat-rhmi", Requests: 10},
+		{API: "ingresses.v1beta1.extensions", RemovedIn: "1.22", User: "kube:admin", UserAgent: "oc/4.8", Requests: 2},
+	}
+	if usage := removedUsage(counts, "1.22"); !reflect.DeepEqual(usage, expected) {
+		t.Errorf("expected %+v, got %+v", expected, usage)
+	}
+
+	if usage := removedUsage(counts, "1.21"); len(usage) != 0 {
+		t.Errorf("expected nothing removed by 1.21, got %+v", usage)
+	}
+}
+
+func TestWriteRemovalsJUnit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "removals")
+	if err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	cfg := &config.Config{ReportDir: dir, Suffix: "abc"}
+	writeRemovalsJUnit(cfg, "1.22", []RemovedAPIUsage{
+		{API: "ingresses.v1beta1.extensions", RemovedIn: "1.22", User: "system:serviceaccount:redhat-rhmi:operator",
+			Namespace: "redhat-rhmi", Requests: 10},
+	})
+
+	data, _ := ioutil.ReadFile(filepath.Join(dir, "junit_api_removals_abc.xml"))
+	for _, expected := range []string{
+		`failures="1"`,
+		"[api-removals] ingresses.v1beta1.extensions should not be used",
+		"used by 1 clients in namespaces redhat-rhmi",
+		"system:serviceaccount:redhat-rhmi:operator made 10 requests in the last day",
+	} {
+		if !strings.Contains(string(data), expected) {
+			t.Errorf("expected results to contain '%s', got:\n%s", expected, data)
+		}
+	}
+
+	writeRemovalsJUnit(cfg, "1.22", nil)
+	data, _ = ioutil.ReadFile(filepath.Join(dir, "junit_api_removals_abc.xml"))
+	if !strings.Contains(string(data), `failures="0"`) {
+		t.Errorf("expected a passing testcase without removed APIs in use, got:\n%s", data)
+	}
+}
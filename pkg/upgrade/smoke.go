@@ -0,0 +1,80 @@
+package upgrade
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"k8s.io/test-infra/testgrid/metadata/junit"
+
+	"github.com/openshift/osde2e/pkg/helper"
+	"github.com/openshift/osde2e/pkg/runner"
+)
+
+// smokeTest runs the UpgradeSmokeSuite after hop, storing its results and returning an error if any of its tests
+// failed.
+func smokeTest(h *helper.H, hop int) error {
+	log.Printf("Running smoke suite '%s' after upgrade %d...", h.UpgradeSmokeSuite, hop)
+	cmd := fmt.Sprintf("openshift-tests run %s --include-success --junit-dir=%s", h.UpgradeSmokeSuite,
+		runner.DefaultRunner.OutputDir)
+	r := h.Runner(cmd)
+	r.Name = fmt.Sprintf("upgrade-%d-smoke", hop)
+	if err := r.Run(h.Context().Done()); err != nil {
+		return fmt.Errorf("couldn't run smoke suite '%s': %v", h.UpgradeSmokeSuite, err)
+	}
+
+	results, err := r.RetrieveResults()
+	if err != nil {
+		return fmt.Errorf("couldn't get results of smoke suite '%s': %v", h.UpgradeSmokeSuite, err)
+	}
+	h.WriteResults(hopResults(hop, results))
+
+	failed, err := smokeFailures(results)
+	if err != nil {
+		return err
+	} else if len(failed) != 0 {
+		return fmt.Errorf("%d tests of smoke suite '%s' failed: %s", len(failed), h.UpgradeSmokeSuite,
+			strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// hopResults names results after hop so those of each hop are kept apart. JUnit files keep their prefix so they're
+// reported with the other results of the run.
+func hopResults(hop int, results map[string][]byte) map[string][]byte {
+	named := make(map[string][]byte, len(results))
+	for name, data := range results {
+		if strings.HasPrefix(name, "junit") {
+			named[fmt.Sprintf("junit_upgrade-%d%s", hop, strings.TrimPrefix(name, "junit"))] = data
+		} else {
+			named[fmt.Sprintf("upgrade-%d-%s", hop, name)] = data
+		}
+	}
+	return named
+}
+
+// smokeFailures returns the names of the tests which failed in the JUnit files of results.
+func smokeFailures(results map[string][]byte) (failed []string, err error) {
+	found := false
+	for name, data := range results {
+		if !strings.HasPrefix(name, "junit") || !strings.HasSuffix(name, ".xml") {
+			continue
+		}
+		suites, err := junit.Parse(data)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't decode smoke suite results '%s': %v", name, err)
+		}
+		found = true
+		for _, suite := range suites.Suites {
+			for _, r := range suite.Results {
+				if r.Failure != nil {
+					failed = append(failed, r.Name)
+				}
+			}
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("smoke suite didn't produce any JUnit results")
+	}
+	return failed, nil
+}
@@ -0,0 +1,33 @@
+package upgrade
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSmokeFailures(t *testing.T) {
+	results := map[string][]byte{
+		"junit_e2e_1.xml": []byte(`<testsuite name="smoke"><testcase name="passes"></testcase>` +
+			`<testcase name="fails"><failure>broken</failure></testcase></testsuite>`),
+		"e2e.log": []byte("not JUnit"),
+	}
+	failed, err := smokeFailures(results)
+	if err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(failed, []string{"fails"}) {
+		t.Errorf("expected the failed test to be found, got %v", failed)
+	}
+
+	if _, err = smokeFailures(map[string][]byte{"e2e.log": nil}); err == nil {
+		t.Error("expected results without JUnit to fail")
+	}
+}
+
+func TestHopResults(t *testing.T) {
+	named := hopResults(2, map[string][]byte{"junit_e2e_1.xml": nil, "e2e.log": nil})
+	for _, name := range []string{"junit_upgrade-2_e2e_1.xml", "upgrade-2-e2e.log"} {
+		if _, ok := named[name]; !ok {
+			t.Errorf("expected result '%s', got %v", name, named)
+		}
+	}
+}
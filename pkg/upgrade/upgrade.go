@@ -9,10 +9,11 @@ import (
 
 	configv1 "github.com/openshift/api/config/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/util/wait"
 
 	"github.com/openshift/osde2e/pkg/config"
+	"github.com/openshift/osde2e/pkg/health"
 	"github.com/openshift/osde2e/pkg/helper"
+	"github.com/openshift/osde2e/pkg/hosted"
 )
 
 const (
@@ -30,10 +31,19 @@ var (
 
 	// MaxDuration is how long an upgrade will run before failing.
 	MaxDuration = 90 * time.Minute
+
+	// HealthCheckDuration is how long to wait for a cluster to become healthy after each upgrade.
+	HealthCheckDuration = 20 * time.Minute
+
+	// healthCheckInterval is how often the health of the cluster is checked after each upgrade.
+	healthCheckInterval = 15 * time.Second
 )
 
 // RunUpgrade uses the OpenShift extended suite to upgrade a cluster to the image provided in cfg.
-func RunUpgrade(cfg *config.Config) error {
+// When multiple images are configured the cluster is upgraded to each in order, checking health after each and running
+// the smoke suite after each but the last.
+// The result of each hop attempted is returned.
+func RunUpgrade(cfg *config.Config) (results []HopResult, err error) {
 	// setup helper
 	h := &helper.H{
 		Config: cfg,
@@ -41,29 +51,162 @@ func RunUpgrade(cfg *config.Config) error {
 	h.Setup()
 	defer h.Cleanup()
 
-	log.Printf("Upgrading cluster to UPGRADE_IMAGE '%s'", cfg.UpgradeImage)
-	desired, err := TriggerUpgrade(h, cfg)
+	hops := Hops(cfg)
+	results = make([]HopResult, 0, len(hops))
+	defer func() {
+		writeHopResults(cfg, results)
+	}()
+
+	// record CRD schemas before upgrading to check compatibility after each hop
+	crds := &crdChecker{cfg: cfg}
+	crds.check(h, 0)
+
+	// warn clients of APIs the upgrade removes before they break
+	checkRemovedAPIs(h, cfg)
+
+	for i, image := range hops {
+		hop := HopResult{
+			Num:     i + 1,
+			Image:   image,
+			Started: time.Now(),
+		}
+
+		if hop.AckedGates, hop.Err = handleAdminGates(h); hop.Err == nil {
+			hop.Version, hop.Err = upgradeTo(h, image)
+		}
+		if hop.Err == nil {
+			hop.Err = crds.check(h, hop.Num)
+		}
+		if hop.Err == nil {
+			log.Println("Checking cluster health after upgrade...")
+			hop.Health, hop.Err = CheckHealth(h, fmt.Sprintf("upgrade-%d", hop.Num))
+		}
+		if hop.Err == nil && cfg.UpgradeSmokeSuite != "" && hop.Num < len(hops) {
+			hop.Err = smokeTest(h, hop.Num)
+		}
+		hop.Duration = time.Since(hop.Started)
+
+		results = append(results, hop)
+		if hop.Err != nil {
+			return results, fmt.Errorf("upgrade %d of %d to '%s' failed: %v", hop.Num, len(hops), image, hop.Err)
+		}
+	}
+	return results, nil
+}
+
+// CheckHealth waits for the cluster to be healthy after stage, recording the result of each check in JUnit. The last
+// report is returned.
+func CheckHealth(h *helper.H, stage string) (*health.Report, error) {
+	checker, err := hosted.Checker(h.Config, h.Kube(), h.Cfg())
 	if err != nil {
-		return fmt.Errorf("failed triggering upgrade: %v", err)
+		return nil, err
+	}
+	r, err := checker.Wait(h.Context(), healthCheckInterval, HealthCheckDuration)
+	if writeErr := health.WriteJUnit(h.ReportDir, h.Suffix, stage, r); writeErr != nil {
+		log.Printf("Failed to record cluster health: %v", writeErr)
 	}
-	log.Println("Cluster acknowledged update request.")
+	return r, err
+}
+
+// upgradeTo triggers an upgrade to image and waits for it to complete. Upgrades which fail are retriggered up to
+// UpgradeRetries times, the documented remediation for most failures reported by the cluster-version-operator.
+// Diagnostics are stored after each failed attempt. The version upgraded to is returned once it completes.
+func upgradeTo(h *helper.H, image string) (string, error) {
+	for attempt := 1; ; attempt++ {
+		log.Printf("Upgrading cluster to '%s', attempt %d of %d", image, attempt, h.UpgradeRetries+1)
+		started := time.Now()
 
-	log.Println("Upgrading...")
-	if err = wait.PollImmediate(10*time.Second, MaxDuration, func() (bool, error) {
-		done, msg, err := IsUpgradeDone(h, desired.Spec.DesiredUpdate)
-		if !done {
-			log.Printf("Upgrade in progress: %s", msg)
+		// requesting the same update again doesn't change the ClusterVersion, so it's cleared first for the
+		// cluster-version-operator to notice the retry
+		if attempt > 1 {
+			if err := clearUpgrade(h); err != nil {
+				return "", fmt.Errorf("failed clearing upgrade to retry it: %v", err)
+			}
 		}
-		return done, err
-	}); err != nil {
-		return fmt.Errorf("failed to upgrade cluster: %v", err)
+		desired, err := TriggerUpgrade(h, image)
+		if err != nil {
+			return "", fmt.Errorf("failed triggering upgrade: %v", err)
+		}
+		log.Println("Cluster acknowledged update request.")
+
+		log.Println("Upgrading...")
+		f := waitForUpgrade(h, desired.Spec.DesiredUpdate, started)
+		if f == nil {
+			log.Println("Upgrade complete!")
+			return completedVersion(h), nil
+		}
+		f.Attempts = attempt
+		log.Printf("Upgrade failed: %v", f)
+		storeDiagnostics(h, attempt)
+
+		if !f.Retryable() || attempt > h.UpgradeRetries {
+			return "", f
+		}
+		log.Printf("Retrying upgrade to '%s'...", image)
+	}
+}
+
+// waitForUpgrade waits for the upgrade to desired, attempted since started, to complete, returning why it failed if it
+// didn't.
+func waitForUpgrade(h *helper.H, desired *configv1.Update, started time.Time) *Failure {
+	var failure *Failure
+	err := h.PollImmediate(10*time.Second, MaxDuration, func() (bool, error) {
+		done, msg, err := IsUpgradeDone(h, desired)
+		if done || err != nil {
+			return done, err
+		}
+		log.Printf("Upgrade in progress: %s", msg)
+
+		// stop waiting as soon as the upgrade can't complete
+		if failure = checkFailure(h, desired, started); failure != nil {
+			return false, failure
+		}
+		return false, nil
+	})
+	if err == nil {
+		return nil
+	} else if failure != nil {
+		return failure
+	}
+	return &Failure{
+		Reason:  ReasonTimeout,
+		Version: desired.Version,
+		Image:   desired.Image,
+		Message: fmt.Sprintf("failed to upgrade cluster: %v", err),
+	}
+}
+
+// completedVersion returns the version of the update the cluster last completed, from the history of its
+// ClusterVersion. It's empty if the ClusterVersion can't be read.
+func completedVersion(h *helper.H) string {
+	cv, err := h.Cfg().ConfigV1().ClusterVersions().Get(ClusterVersionName, metav1.GetOptions{})
+	if err != nil {
+		log.Printf("Failed to get version upgraded to: %v", err)
+		return ""
+	}
+	for _, update := range cv.Status.History {
+		if update.State == configv1.CompletedUpdate {
+			return update.Version
+		}
+	}
+	return ""
+}
+
+// clearUpgrade removes the update requested of the ClusterVersion.
+func clearUpgrade(h *helper.H) error {
+	cv, err := h.Cfg().ConfigV1().ClusterVersions().Get(ClusterVersionName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("couldn't get current ClusterVersion '%s': %v", ClusterVersionName, err)
+	}
+	cv.Spec.DesiredUpdate = nil
+	if _, err = h.Cfg().ConfigV1().ClusterVersions().Update(cv); err != nil {
+		return fmt.Errorf("couldn't clear desired update of ClusterVersion: %v", err)
 	}
-	log.Println("Upgrade complete!")
 	return nil
 }
 
-// TriggerUpgrade uses a helper to perform an upgrade.
-func TriggerUpgrade(h *helper.H, cfg *config.Config) (*configv1.ClusterVersion, error) {
+// TriggerUpgrade uses a helper to perform an upgrade to image.
+func TriggerUpgrade(h *helper.H, image string) (*configv1.ClusterVersion, error) {
 	// setup Config client
 	cfgClient := h.Cfg()
 
@@ -75,15 +218,15 @@ func TriggerUpgrade(h *helper.H, cfg *config.Config) (*configv1.ClusterVersion,
 	}
 
 	// split image into name and tag
-	imageParts := strings.Split(cfg.UpgradeImage, ":")
+	imageParts := strings.Split(image, ":")
 	if len(imageParts) != 2 {
-		return cVersion, fmt.Errorf("an UPGRADE_IMAGE should have a name and an a tag, got '%s'", cfg.UpgradeImage)
+		return cVersion, fmt.Errorf("an upgrade image should have a name and an a tag, got '%s'", image)
 	}
 
 	// set requested upgrade targets
 	cVersion.Spec.DesiredUpdate = &configv1.Update{
 		Version: imageParts[1],
-		Image:   cfg.UpgradeImage,
+		Image:   image,
 		Force:   true,
 	}
 	updatedCV, err := cfgClient.ConfigV1().ClusterVersions().Update(cVersion)
@@ -93,7 +236,7 @@ func TriggerUpgrade(h *helper.H, cfg *config.Config) (*configv1.ClusterVersion,
 
 	// wait for update acknowledgement
 	updateGeneration := updatedCV.Generation
-	if err = wait.PollImmediate(5*time.Second, 2*time.Minute, func() (bool, error) {
+	if err = h.PollImmediate(5*time.Second, 2*time.Minute, func() (bool, error) {
 		if cVersion, err = cfgClient.ConfigV1().ClusterVersions().Get(ClusterVersionName, getOpts); err != nil {
 			return false, err
 		}
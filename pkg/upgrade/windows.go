@@ -0,0 +1,117 @@
+package upgrade
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MaintenanceWindow is a weekly period, in UTC, during which a cluster may be upgraded.
+type MaintenanceWindow struct {
+	Day      time.Weekday
+	Start    time.Duration
+	Duration time.Duration
+}
+
+// ParseMaintenanceWindow decodes a window in the form '<day> <HH:MM> <duration>', such as 'Sat 02:00 4h'.
+func ParseMaintenanceWindow(s string) (w MaintenanceWindow, err error) {
+	parts := strings.Fields(s)
+	if len(parts) != 3 {
+		return w, fmt.Errorf("maintenance window '%s' should be in the form '<day> <HH:MM> <duration>'", s)
+	}
+
+	var ok bool
+	if w.Day, ok = parseWeekday(parts[0]); !ok {
+		return w, fmt.Errorf("invalid day in maintenance window '%s'", s)
+	}
+
+	start, err := time.Parse("15:04", parts[1])
+	if err != nil {
+		return w, fmt.Errorf("invalid start in maintenance window '%s': %v", s, err)
+	}
+	w.Start = time.Duration(start.Hour())*time.Hour + time.Duration(start.Minute())*time.Minute
+
+	if w.Duration, err = time.ParseDuration(parts[2]); err != nil {
+		return w, fmt.Errorf("invalid duration in maintenance window '%s': %v", s, err)
+	} else if w.Duration <= 0 || w.Duration > 7*24*time.Hour {
+		return w, fmt.Errorf("duration of maintenance window '%s' must be positive and at most a week", s)
+	}
+	return w, nil
+}
+
+// ParseMaintenanceWindows decodes each window in windows.
+func ParseMaintenanceWindows(windows []string) ([]MaintenanceWindow, error) {
+	parsed := make([]MaintenanceWindow, len(windows))
+	for i, s := range windows {
+		w, err := ParseMaintenanceWindow(s)
+		if err != nil {
+			return nil, err
+		}
+		parsed[i] = w
+	}
+	return parsed, nil
+}
+
+func (w MaintenanceWindow) String() string {
+	start := time.Time{}.Add(w.Start)
+	return fmt.Sprintf("%s %s UTC for %v", w.Day.String()[:3], start.Format("15:04"), w.Duration)
+}
+
+// occurrence returns the start of the window that contains t or, if none does, the next one after t.
+func (w MaintenanceWindow) occurrence(t time.Time) time.Time {
+	t = t.UTC()
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	days := int(w.Day - t.Weekday())
+	start := midnight.AddDate(0, 0, days).Add(w.Start)
+
+	// the window from the previous week may still be open, otherwise find the next
+	if prev := start.AddDate(0, 0, -7); t.Before(prev.Add(w.Duration)) {
+		return prev
+	}
+	for !t.Before(start.Add(w.Duration)) {
+		start = start.AddDate(0, 0, 7)
+	}
+	return start
+}
+
+// Fits returns true if an upgrade starting at start and taking d completes within the window.
+func (w MaintenanceWindow) Fits(start time.Time, d time.Duration) bool {
+	open := w.occurrence(start)
+	return !start.Before(open) && !start.Add(d).After(open.Add(w.Duration))
+}
+
+// NextFit returns the earliest time at or after t an upgrade taking d can start and complete within one of
+// windows. It returns false if d is longer than every window.
+func NextFit(windows []MaintenanceWindow, t time.Time, d time.Duration) (next time.Time, ok bool) {
+	for _, w := range windows {
+		if d > w.Duration {
+			continue
+		}
+
+		start := w.occurrence(t)
+		if start.Before(t) {
+			if w.Fits(t, d) {
+				start = t
+			} else {
+				start = start.AddDate(0, 0, 7)
+			}
+		}
+		if !ok || start.Before(next) {
+			next, ok = start, true
+		}
+	}
+	return
+}
+
+// parseWeekday accepts full or abbreviated day names of at least three letters, such as 'sat' or 'Saturday'.
+func parseWeekday(s string) (time.Weekday, bool) {
+	if len(s) < 3 {
+		return 0, false
+	}
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if strings.HasPrefix(strings.ToLower(d.String()), strings.ToLower(s)) {
+			return d, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,70 @@
+package upgrade
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseMaintenanceWindow(t *testing.T) {
+	w, err := ParseMaintenanceWindow("saturday 02:30 4h")
+	if err != nil {
+		t.Fatalf("failed to parse window: %v", err)
+	}
+	expected := MaintenanceWindow{Day: time.Saturday, Start: 2*time.Hour + 30*time.Minute, Duration: 4 * time.Hour}
+	if w != expected {
+		t.Errorf("expected window %v, got %v", expected, w)
+	}
+
+	for _, s := range []string{"Sa 02:00 4h", "Sat 2am 4h", "Sat 02:00", "Sat 02:00 -1h", "Sat 02:00 200h"} {
+		if _, err = ParseMaintenanceWindow(s); err == nil {
+			t.Errorf("expected error parsing '%s'", s)
+		}
+	}
+}
+
+func TestNextFit(t *testing.T) {
+	windows, err := ParseMaintenanceWindows([]string{"Sat 02:00 4h", "Wed 22:00 1h"})
+	if err != nil {
+		t.Fatalf("failed to parse windows: %v", err)
+	}
+
+	// 2019-10-05 is a Saturday
+	sat := func(hour, min int) time.Time {
+		return time.Date(2019, 10, 5, hour, min, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name  string
+		start time.Time
+		d     time.Duration
+		next  time.Time
+		ok    bool
+	}{
+		{"within window", sat(3, 0), time.Hour, sat(3, 0), true},
+		{"runs past window", sat(5, 30), 2 * time.Hour, sat(2, 0).AddDate(0, 0, 7), true},
+		{"before window", sat(1, 0), 2 * time.Hour, sat(2, 0), true},
+		{"shorter window sooner", sat(7, 0), 30 * time.Minute, time.Date(2019, 10, 9, 22, 0, 0, 0, time.UTC), true},
+		{"longer than every window", sat(2, 0), 5 * time.Hour, time.Time{}, false},
+	}
+	for _, tt := range tests {
+		next, ok := NextFit(windows, tt.start, tt.d)
+		if ok != tt.ok || !next.Equal(tt.next) {
+			t.Errorf("%s: expected %v (%t), got %v (%t)", tt.name, tt.next, tt.ok, next, ok)
+		}
+	}
+}
+
+func TestPlanEdge(t *testing.T) {
+	windows := []MaintenanceWindow{{Day: time.Saturday, Start: 2 * time.Hour, Duration: 4 * time.Hour}}
+	start := time.Date(2019, 10, 5, 5, 0, 0, 0, time.UTC)
+
+	if conflict, next := planEdge(windows, start, 30*time.Minute); conflict != "" || !next.Equal(start) {
+		t.Errorf("expected no conflict, got '%s' next at %v", conflict, next)
+	}
+	if conflict, next := planEdge(windows, start, 2*time.Hour); conflict == "" || next == nil || !next.Equal(start.Add(165*time.Hour)) {
+		t.Errorf("expected conflict with next window a week later, got '%s' next at %v", conflict, next)
+	}
+	if conflict, next := planEdge(nil, start, 2*time.Hour); conflict != "" || next != nil {
+		t.Errorf("expected no conflict without windows, got '%s'", conflict)
+	}
+}
@@ -0,0 +1,217 @@
+// Package usage accounts for the CPU and memory consumed by the workloads each suite creates, so the suites needing
+// the most resources can be found when sizing clusters for CI.
+package usage
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openshift/osde2e/pkg/metrics"
+)
+
+const (
+	// File is the name of the report of each suite's usage.
+	File = "suite-usage.json"
+
+	// scrapeGrace extends the window of suites so usage scraped after their last spec ends is included.
+	scrapeGrace = time.Minute
+
+	// memoryResolution is how often memory usage is sampled when finding its peak.
+	memoryResolution = "30s"
+)
+
+// Current accounts for the usage of suites in this run.
+var Current = NewAccountant()
+
+// Querier evaluates a PromQL query at a point in time.
+type Querier func(query string, at time.Time) ([]metrics.Sample, error)
+
+// Usage is what the workloads of a suite consumed while it ran.
+type Usage struct {
+	Suite      string    `json:"suite"`
+	Namespaces []string  `json:"namespaces"`
+	Start      time.Time `json:"start"`
+	End        time.Time `json:"end"`
+
+	// CPUSeconds is the CPU time consumed by containers of the suite, in core seconds.
+	CPUSeconds float64 `json:"cpuSeconds"`
+
+	// PeakMemoryBytes is the highest working set of the suite's containers at once.
+	PeakMemoryBytes float64 `json:"peakMemoryBytes"`
+}
+
+func (u Usage) String() string {
+	return fmt.Sprintf("%s: %.1f CPU core seconds, %.1f MiB peak memory over %v", u.Suite, u.CPUSeconds,
+		u.PeakMemoryBytes/(1<<20), u.End.Sub(u.Start).Round(time.Second))
+}
+
+// window is when a suite ran and the namespaces its specs created.
+type window struct {
+	namespaces []string
+	start, end time.Time
+}
+
+// Accountant records the namespaces and time windows of suites.
+type Accountant struct {
+	mu     sync.Mutex
+	suites map[string]*window
+}
+
+// NewAccountant returns an Accountant which hasn't seen any suites.
+func NewAccountant() *Accountant {
+	return &Accountant{suites: map[string]*window{}}
+}
+
+// Start records a spec of suite beginning at t in namespace.
+func (a *Accountant) Start(suite, namespace string, t time.Time) {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	w, ok := a.suites[suite]
+	if !ok {
+		w = &window{start: t}
+		a.suites[suite] = w
+	}
+	if t.Before(w.start) {
+		w.start = t
+	}
+	if t.After(w.end) {
+		w.end = t
+	}
+	w.namespaces = append(w.namespaces, namespace)
+}
+
+// End records a spec of suite ending at t. Suites without started specs are ignored.
+func (a *Accountant) End(suite string, t time.Time) {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if w, ok := a.suites[suite]; ok && t.After(w.end) {
+		w.end = t
+	}
+}
+
+// Measure queries the usage of each suite, returning them with the most CPU consumed first.
+func (a *Accountant) Measure(query Querier) ([]Usage, error) {
+	if a == nil {
+		return nil, nil
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	usages := make([]Usage, 0, len(a.suites))
+	for suite, w := range a.suites {
+		u := Usage{
+			Suite:      suite,
+			Namespaces: w.namespaces,
+			Start:      w.start,
+			End:        w.end,
+		}
+		at := w.end.Add(scrapeGrace)
+
+		cpu, err := query(CPUQuery(w.namespaces, at.Sub(w.start)), at)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't query CPU usage of suite '%s': %v", suite, err)
+		}
+		u.CPUSeconds = sum(cpu)
+
+		memory, err := query(MemoryQuery(w.namespaces, at.Sub(w.start)), at)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't query memory usage of suite '%s': %v", suite, err)
+		}
+		u.PeakMemoryBytes = sum(memory)
+
+		usages = append(usages, u)
+	}
+
+	sort.Slice(usages, func(i, j int) bool {
+		if usages[i].CPUSeconds != usages[j].CPUSeconds {
+			return usages[i].CPUSeconds > usages[j].CPUSeconds
+		}
+		return usages[i].Suite < usages[j].Suite
+	})
+	return usages, nil
+}
+
+// CPUQuery returns the CPU core seconds consumed by containers in namespaces over the window before it's evaluated.
+func CPUQuery(namespaces []string, window time.Duration) string {
+	return fmt.Sprintf(`sum(increase(container_cpu_usage_seconds_total{container_name!="",container_name!="POD",namespace=~"%s"}[%s]))`,
+		strings.Join(namespaces, "|"), promDuration(window))
+}
+
+// MemoryQuery returns the peak working set of containers in namespaces over the window before it's evaluated.
+func MemoryQuery(namespaces []string, window time.Duration) string {
+	return fmt.Sprintf(`max_over_time(sum(container_memory_working_set_bytes{container_name!="",container_name!="POD",namespace=~"%s"})[%s:%s])`,
+		strings.Join(namespaces, "|"), promDuration(window), memoryResolution)
+}
+
+// Top returns the names of the suites of usages consuming the most of a resource, up to n of each.
+func Top(usages []Usage, n int) (cpu, memory []Usage) {
+	cpu = append([]Usage(nil), usages...)
+	sort.SliceStable(cpu, func(i, j int) bool { return cpu[i].CPUSeconds > cpu[j].CPUSeconds })
+	memory = append([]Usage(nil), usages...)
+	sort.SliceStable(memory, func(i, j int) bool { return memory[i].PeakMemoryBytes > memory[j].PeakMemoryBytes })
+
+	if len(cpu) > n {
+		cpu, memory = cpu[:n], memory[:n]
+	}
+	return
+}
+
+// Metadata describes the suites consuming the most CPU and memory for TestGrid.
+func Metadata(usages []Usage) map[string]interface{} {
+	meta := map[string]interface{}{}
+	cpu, memory := Top(usages, 1)
+	if len(cpu) == 0 {
+		return meta
+	}
+	meta["suite-usage-top-cpu"] = cpu[0].Suite
+	meta["suite-usage-top-cpu-seconds"] = int(cpu[0].CPUSeconds)
+	meta["suite-usage-top-memory"] = memory[0].Suite
+	meta["suite-usage-top-memory-mib"] = int(memory[0].PeakMemoryBytes / (1 << 20))
+	return meta
+}
+
+// Write stores usages in dir as File.
+func Write(dir string, usages []Usage) error {
+	data, err := json.MarshalIndent(usages, "", "  ")
+	if err != nil {
+		return fmt.Errorf("couldn't encode suite usage: %v", err)
+	}
+
+	os.MkdirAll(dir, os.ModePerm)
+	filename := filepath.Join(dir, File)
+	if err = ioutil.WriteFile(filename, data, os.ModePerm); err != nil {
+		return fmt.Errorf("couldn't write suite usage to '%s': %v", filename, err)
+	}
+	return nil
+}
+
+// promDuration formats d as a PromQL duration in whole seconds, which is at least a second.
+func promDuration(d time.Duration) string {
+	secs := int64(d / time.Second)
+	if secs < 1 {
+		secs = 1
+	}
+	return fmt.Sprintf("%ds", secs)
+}
+
+func sum(samples []metrics.Sample) (total float64) {
+	for _, s := range samples {
+		total += s.Value
+	}
+	return
+}
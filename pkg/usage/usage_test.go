@@ -0,0 +1,109 @@
+package usage
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift/osde2e/pkg/metrics"
+)
+
+func TestMeasure(t *testing.T) {
+	start := time.Date(2020, 4, 1, 12, 0, 0, 0, time.UTC)
+	a := NewAccountant()
+	a.Start("Pods", "osde2e-abcde", start)
+	a.End("Pods", start.Add(2*time.Minute))
+	a.Start("Pods", "osde2e-fghij", start.Add(3*time.Minute))
+	a.End("Pods", start.Add(4*time.Minute))
+	a.Start("Routes", "osde2e-klmno", start.Add(5*time.Minute))
+	a.End("Routes", start.Add(6*time.Minute))
+	a.End("Unknown", start.Add(7*time.Minute))
+
+	var queries []string
+	usages, err := a.Measure(func(query string, at time.Time) ([]metrics.Sample, error) {
+		queries = append(queries, fmt.Sprintf("%s@%s", query, at.Format(time.Kitchen)))
+		value := 10.0
+		if strings.Contains(query, "osde2e-klmno") {
+			value = 100
+		}
+		return []metrics.Sample{{Value: value}, {Value: value}}, nil
+	})
+	if err != nil {
+		t.Fatalf("failed to measure: %v", err)
+	}
+
+	if len(usages) != 2 || usages[0].Suite != "Routes" || usages[0].CPUSeconds != 200 || usages[1].PeakMemoryBytes != 20 {
+		t.Fatalf("expected Routes to consume the most, got %+v", usages)
+	}
+	if pods := usages[1]; !pods.Start.Equal(start) || !pods.End.Equal(start.Add(4*time.Minute)) || len(pods.Namespaces) != 2 {
+		t.Errorf("expected window of Pods to cover both specs, got %+v", pods)
+	}
+
+	expected := `namespace=~"osde2e-abcde|osde2e-fghij"}[300s]))@12:05PM`
+	found := false
+	for _, q := range queries {
+		found = found || strings.HasSuffix(q, expected)
+	}
+	if !found {
+		t.Errorf("expected a query ending in '%s', got %v", expected, queries)
+	}
+}
+
+func TestMeasureError(t *testing.T) {
+	a := NewAccountant()
+	a.Start("Pods", "osde2e-abcde", time.Now())
+	_, err := a.Measure(func(string, time.Time) ([]metrics.Sample, error) {
+		return nil, fmt.Errorf("unavailable")
+	})
+	if err == nil || !strings.Contains(err.Error(), "Pods") {
+		t.Errorf("expected error naming the suite, got %v", err)
+	}
+
+	var unaccounted *Accountant
+	unaccounted.Start("Pods", "osde2e-abcde", time.Now())
+	if usages, err := unaccounted.Measure(nil); usages != nil || err != nil {
+		t.Errorf("expected nothing measured without an accountant, got %v, %v", usages, err)
+	}
+}
+
+func TestTopAndMetadata(t *testing.T) {
+	usages := []Usage{
+		{Suite: "Pods", CPUSeconds: 50, PeakMemoryBytes: 512 << 20},
+		{Suite: "Routes", CPUSeconds: 20, PeakMemoryBytes: 1 << 30},
+		{Suite: "Storage", CPUSeconds: 10, PeakMemoryBytes: 64 << 20},
+	}
+
+	cpu, memory := Top(usages, 2)
+	if len(cpu) != 2 || cpu[0].Suite != "Pods" || memory[0].Suite != "Routes" || memory[1].Suite != "Pods" {
+		t.Errorf("unexpected top suites: %v, %v", cpu, memory)
+	}
+
+	meta := Metadata(usages)
+	if meta["suite-usage-top-cpu"] != "Pods" || meta["suite-usage-top-memory"] != "Routes" ||
+		meta["suite-usage-top-memory-mib"] != 1024 {
+		t.Errorf("unexpected metadata: %v", meta)
+	}
+	if meta = Metadata(nil); len(meta) != 0 {
+		t.Errorf("expected no metadata without usage, got %v", meta)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "usage")
+	if err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err = Write(dir, []Usage{{Suite: "Pods", CPUSeconds: 1.5}}); err != nil {
+		t.Fatalf("failed to write usage: %v", err)
+	}
+	data, _ := ioutil.ReadFile(filepath.Join(dir, File))
+	if !strings.Contains(string(data), `"cpuSeconds": 1.5`) {
+		t.Errorf("expected usage to be written, got %s", data)
+	}
+}
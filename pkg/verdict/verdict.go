@@ -0,0 +1,223 @@
+// Package verdict summarizes how each phase of a run ended and chooses the exit code of the run from it, so
+// automation running osde2e can tell failures of the product from those of the infrastructure it ran on.
+package verdict
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	ginkgoconfig "github.com/onsi/ginkgo/config"
+	"github.com/onsi/ginkgo/types"
+
+	"github.com/openshift/osde2e/pkg/config"
+	"github.com/openshift/osde2e/pkg/redact"
+)
+
+// File is the name of the verdict in the report directory.
+const File = "verdict.json"
+
+// Exit codes of runs. Runs which fail before any phase, such as with invalid options, exit with 1.
+const (
+	ExitPassed              = 0
+	ExitTestFailure         = 2
+	ExitInfraFailure        = 3
+	ExitProvisioningFailure = 4
+	ExitUpgradeFailure      = 5
+)
+
+// Class is what failed.
+type Class string
+
+const (
+	// Test failures are specs which failed.
+	Test Class = "test"
+
+	// Infra failures are of what the run relies on rather than the cluster, such as OCM or runner Pods which couldn't
+	// be scheduled, and of setting up tests or tearing down.
+	Infra Class = "infra"
+
+	// Provisioning failures are clusters which couldn't be installed and configured.
+	Provisioning Class = "provisioning"
+
+	// Upgrade failures are clusters which couldn't be upgraded.
+	Upgrade Class = "upgrade"
+)
+
+// Classes are all classes, from the one deciding the exit code first to last. Infrastructure failures decide over
+// test failures since specs fail when what they rely on does.
+var Classes = []Class{Provisioning, Upgrade, Infra, Test}
+
+// exitCodes are the exit codes of runs failing with each class.
+var exitCodes = map[Class]int{
+	Test:         ExitTestFailure,
+	Infra:        ExitInfraFailure,
+	Provisioning: ExitProvisioningFailure,
+	Upgrade:      ExitUpgradeFailure,
+}
+
+// Outcomes of phases.
+const (
+	Passed  = "passed"
+	Failed  = "failed"
+	Skipped = "skipped"
+
+	// NotRun phases were selected but didn't start, because an earlier phase failed.
+	NotRun = "not-run"
+)
+
+// Failure is something which failed in a phase.
+type Failure struct {
+	Class  Class  `json:"class"`
+	Reason string `json:"reason"`
+}
+
+// Phase is how a phase of the run ended.
+type Phase struct {
+	Name     config.Phase `json:"name"`
+	Outcome  string       `json:"outcome"`
+	Failures []Failure    `json:"failures,omitempty"`
+}
+
+// Verdict is how a run ended.
+type Verdict struct {
+	// Result is the class of failure deciding the exit code, or passed.
+	Result   string  `json:"result"`
+	ExitCode int     `json:"exitCode"`
+	Phases   []Phase `json:"phases"`
+}
+
+// Write stores v in dir.
+func (v *Verdict) Write(dir string) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("couldn't encode verdict: %v", err)
+	}
+
+	os.MkdirAll(dir, os.ModePerm)
+	filename := filepath.Join(dir, File)
+	if err = ioutil.WriteFile(filename, data, os.ModePerm); err != nil {
+		return fmt.Errorf("couldn't write verdict to '%s': %v", filename, err)
+	}
+	return nil
+}
+
+// Recorder is a Ginkgo reporter recording failures in the phase they happened. Setup failing fails the phase it was
+// in with the class of that phase, and specs failing fails the tests phase.
+type Recorder struct {
+	mu       sync.Mutex
+	current  config.Phase
+	started  map[config.Phase]bool
+	failures map[config.Phase][]Failure
+}
+
+// Start records the phase p beginning. Failures without a phase are recorded in it.
+func (r *Recorder) Start(p config.Phase) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.started == nil {
+		r.started = map[config.Phase]bool{}
+	}
+	r.current, r.started[p] = p, true
+}
+
+// Fail records a failure of class in the phase p, or in the current phase if p is empty.
+func (r *Recorder) Fail(p config.Phase, class Class, reason string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if p == "" {
+		p = r.current
+	}
+	if r.failures == nil {
+		r.failures = map[config.Phase][]Failure{}
+	}
+	r.failures[p] = append(r.failures[p], Failure{Class: class, Reason: redact.Current.String(reason)})
+}
+
+// Verdict returns how the run of cfg ended so far.
+func (r *Recorder) Verdict(cfg *config.Config) *Verdict {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	v := &Verdict{Result: Passed, ExitCode: ExitPassed}
+	failed := map[Class]bool{}
+	for _, p := range config.Phases {
+		phase := Phase{Name: p, Outcome: Passed, Failures: r.failures[p]}
+		switch {
+		case len(phase.Failures) != 0:
+			phase.Outcome = Failed
+			for _, f := range phase.Failures {
+				failed[f.Class] = true
+			}
+		case !cfg.RunPhase(p):
+			phase.Outcome = Skipped
+		case !r.started[p]:
+			phase.Outcome = NotRun
+		}
+		v.Phases = append(v.Phases, phase)
+	}
+
+	for _, class := range Classes {
+		if failed[class] {
+			v.Result, v.ExitCode = string(class), exitCodes[class]
+			break
+		}
+	}
+	return v
+}
+
+// setupClass returns the class of setup failing in p.
+func setupClass(p config.Phase) Class {
+	switch p {
+	case config.PhaseInstall:
+		return Provisioning
+	case config.PhaseUpgrade:
+		return Upgrade
+	}
+	return Infra
+}
+
+// SpecSuiteWillBegin does nothing.
+func (r *Recorder) SpecSuiteWillBegin(config ginkgoconfig.GinkgoConfigType, summary *types.SuiteSummary) {
+}
+
+// BeforeSuiteDidRun records setup failing in the current phase.
+func (r *Recorder) BeforeSuiteDidRun(summary *types.SetupSummary) {
+	if summary.State.IsFailure() {
+		r.mu.Lock()
+		p := r.current
+		r.mu.Unlock()
+		r.Fail(p, setupClass(p), summary.Failure.Message)
+	}
+}
+
+// SpecWillRun does nothing.
+func (r *Recorder) SpecWillRun(summary *types.SpecSummary) {}
+
+// SpecDidComplete records the spec failing the tests phase.
+func (r *Recorder) SpecDidComplete(summary *types.SpecSummary) {
+	if !summary.State.IsFailure() {
+		return
+	}
+
+	// the first component is the top level container
+	texts := summary.ComponentTexts
+	if len(texts) > 1 {
+		texts = texts[1:]
+	}
+	r.Fail(config.PhaseTests, Test, strings.Join(texts, " ")+": "+summary.Failure.Message)
+}
+
+// AfterSuiteDidRun records teardown failing.
+func (r *Recorder) AfterSuiteDidRun(summary *types.SetupSummary) {
+	if summary.State.IsFailure() {
+		r.Fail(config.PhaseTeardown, Infra, summary.Failure.Message)
+	}
+}
+
+// SpecSuiteDidEnd does nothing.
+func (r *Recorder) SpecSuiteDidEnd(summary *types.SuiteSummary) {}
@@ -0,0 +1,145 @@
+package verdict
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/onsi/ginkgo/types"
+
+	"github.com/openshift/osde2e/pkg/config"
+)
+
+// outcomes returns the outcome of each phase of v.
+func outcomes(v *Verdict) (o []string) {
+	for _, p := range v.Phases {
+		o = append(o, p.Outcome)
+	}
+	return
+}
+
+func TestVerdict(t *testing.T) {
+	failedSetup := &types.SetupSummary{State: types.SpecStateFailed, Failure: types.SpecFailure{Message: "failed"}}
+	failedSpec := &types.SpecSummary{
+		ComponentTexts: []string{"[Top Level]", "Routes", "should be admitted"},
+		State:          types.SpecStateFailed,
+		Failure:        types.SpecFailure{Message: "route wasn't admitted"},
+	}
+
+	for _, tc := range []struct {
+		name     string
+		phases   []string
+		record   func(r *Recorder)
+		result   string
+		exitCode int
+		outcomes []string
+	}{
+		{
+			name: "passed",
+			record: func(r *Recorder) {
+				for _, p := range config.Phases {
+					r.Start(p)
+				}
+				r.BeforeSuiteDidRun(&types.SetupSummary{State: types.SpecStatePassed})
+			},
+			result:   Passed,
+			exitCode: ExitPassed,
+			outcomes: []string{Passed, Passed, Passed, Passed},
+		},
+		{
+			name: "provisioning",
+			record: func(r *Recorder) {
+				r.Start(config.PhaseInstall)
+				r.BeforeSuiteDidRun(failedSetup)
+				r.Start(config.PhaseTeardown)
+			},
+			result:   string(Provisioning),
+			exitCode: ExitProvisioningFailure,
+			outcomes: []string{Failed, NotRun, NotRun, Passed},
+		},
+		{
+			name: "upgrade",
+			record: func(r *Recorder) {
+				r.Start(config.PhaseInstall)
+				r.Start(config.PhaseUpgrade)
+				r.BeforeSuiteDidRun(failedSetup)
+			},
+			result:   string(Upgrade),
+			exitCode: ExitUpgradeFailure,
+			outcomes: []string{Passed, Failed, NotRun, NotRun},
+		},
+		{
+			name:   "tests",
+			phases: []string{"tests"},
+			record: func(r *Recorder) {
+				for _, p := range config.Phases {
+					r.Start(p)
+				}
+				r.SpecDidComplete(failedSpec)
+			},
+			result:   string(Test),
+			exitCode: ExitTestFailure,
+			outcomes: []string{Skipped, Skipped, Failed, Skipped},
+		},
+		{
+			name: "infra decides over tests",
+			record: func(r *Recorder) {
+				for _, p := range config.Phases {
+					r.Start(p)
+				}
+				r.SpecDidComplete(failedSpec)
+				r.AfterSuiteDidRun(failedSetup)
+			},
+			result:   string(Infra),
+			exitCode: ExitInfraFailure,
+			outcomes: []string{Passed, Passed, Failed, Failed},
+		},
+	} {
+		r := new(Recorder)
+		tc.record(r)
+		v := r.Verdict(&config.Config{Phases: tc.phases})
+		if v.Result != tc.result || v.ExitCode != tc.exitCode {
+			t.Errorf("%s: expected %s with exit code %d, got %s with %d", tc.name, tc.result, tc.exitCode, v.Result,
+				v.ExitCode)
+		}
+		if o := outcomes(v); len(o) != len(tc.outcomes) {
+			t.Errorf("%s: expected outcomes %v, got %v", tc.name, tc.outcomes, o)
+		} else {
+			for i := range o {
+				if o[i] != tc.outcomes[i] {
+					t.Errorf("%s: expected outcomes %v, got %v", tc.name, tc.outcomes, o)
+					break
+				}
+			}
+		}
+	}
+}
+
+func TestWrite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "verdict")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	r := new(Recorder)
+	r.Start(config.PhaseTests)
+	r.Fail("", Infra, "2.00% of OCM API calls failed")
+	if err = r.Verdict(&config.Config{}).Write(dir); err != nil {
+		t.Fatalf("failed to write verdict: %v", err)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, File))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var v Verdict
+	if err = json.Unmarshal(data, &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.ExitCode != ExitInfraFailure || len(v.Phases[2].Failures) != 1 || v.Phases[2].Failures[0].Class != Infra {
+		t.Errorf("expected infra failure of the tests phase, got %+v", v)
+	}
+}
@@ -0,0 +1,404 @@
+// Package webhook triggers runs of osde2e from webhooks announcing releases, such as a new cluster image set being
+// published or an add-on version being released, so runs are driven by events rather than cron jobs. Every webhook
+// and the runs it triggered are recorded in an audit log.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// EventClusterImageSet is sent when a cluster image set is published, naming the image set.
+	EventClusterImageSet = "cluster-image-set"
+
+	// EventAddonVersion is sent when a version of an add-on is released, naming the add-on.
+	EventAddonVersion = "addon-version"
+
+	// SignatureHeader holds 'sha256=' followed by the hex encoded HMAC-SHA256 of the timestamp, a '.', and the body
+	// using the shared secret.
+	SignatureHeader = "X-Osde2e-Signature"
+
+	// TimestampHeader holds when the request was signed, in seconds since the Unix epoch.
+	TimestampHeader = "X-Osde2e-Timestamp"
+
+	// MaxSignatureAge is how far the timestamp of a signed request may be from the time it's received. Older requests
+	// are refused so captured requests can't be replayed.
+	MaxSignatureAge = 5 * time.Minute
+
+	// signaturePrefix begins signatures.
+	signaturePrefix = "sha256="
+
+	// maxBodySize limits the size of webhooks.
+	maxBodySize = 1 << 20
+
+	// recentRecords is how many audit records are kept to be served.
+	recentRecords = 100
+
+	// DefaultDedupeWindow is how long events which started runs are ignored if redelivered.
+	DefaultDedupeWindow = 24 * time.Hour
+)
+
+// Outcomes of webhooks recorded in the audit log.
+const (
+	OutcomeRejected  = "rejected"
+	OutcomeUnmatched = "unmatched"
+	OutcomeDuplicate = "duplicate"
+	OutcomeBusy      = "busy"
+	OutcomeStarted   = "started"
+	OutcomePassed    = "passed"
+	OutcomeFailed    = "failed"
+)
+
+// Events are the types of events which can trigger runs.
+var Events = []string{EventClusterImageSet, EventAddonVersion}
+
+// Event is the body of a webhook.
+type Event struct {
+	// Type of the event, such as 'cluster-image-set'.
+	Type string `json:"event"`
+
+	// Name of what was released, such as the cluster image set or add-on ID.
+	Name string `json:"name"`
+
+	// Version released, if it isn't part of the name.
+	Version string `json:"version,omitempty"`
+}
+
+func (e Event) String() string {
+	if e.Version == "" {
+		return fmt.Sprintf("%s '%s'", e.Type, e.Name)
+	}
+	return fmt.Sprintf("%s '%s' version '%s'", e.Type, e.Name, e.Version)
+}
+
+// Trigger starts a run profile for events matching it.
+type Trigger struct {
+	// Event is the type of event triggering the run.
+	Event string `json:"event"`
+
+	// Match is a regular expression the name of events must match. All events of the type match if it's empty.
+	Match string `json:"match,omitempty"`
+
+	// Profile names the run.
+	Profile string `json:"profile"`
+
+	// Env configures the run. '${EVENT}', '${NAME}', and '${VERSION}' in values are replaced by those of the event.
+	Env map[string]string `json:"env,omitempty"`
+
+	match *regexp.Regexp
+}
+
+// Run is an osde2e run triggered by an event.
+type Run struct {
+	Profile string `json:"profile"`
+
+	// Env are the options of the run as KEY=value.
+	Env []string `json:"env"`
+}
+
+// Load reads triggers from a YAML file.
+func Load(file string) ([]Trigger, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read webhook triggers '%s': %v", file, err)
+	}
+	return Parse(data)
+}
+
+// Parse decodes YAML triggers, returning an error if any are invalid.
+func Parse(data []byte) ([]Trigger, error) {
+	var file struct {
+		Triggers []Trigger `json:"triggers"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("couldn't decode webhook triggers: %v", err)
+	}
+
+	for i := range file.Triggers {
+		t := &file.Triggers[i]
+		if !known(t.Event) {
+			return nil, fmt.Errorf("trigger '%s' has unknown event '%s', must be one of %v", t.Profile, t.Event, Events)
+		} else if t.Profile == "" {
+			return nil, fmt.Errorf("triggers of '%s' events must name a profile", t.Event)
+		}
+
+		var err error
+		if t.match, err = regexp.Compile(t.Match); err != nil {
+			return nil, fmt.Errorf("trigger '%s' has invalid match: %v", t.Profile, err)
+		}
+	}
+	return file.Triggers, nil
+}
+
+// Runs returns the runs triggers start for e.
+func Runs(triggers []Trigger, e Event) (runs []Run) {
+	vars := map[string]string{"EVENT": e.Type, "NAME": e.Name, "VERSION": e.Version}
+	for _, t := range triggers {
+		if t.Event != e.Type || (t.match != nil && !t.match.MatchString(e.Name)) {
+			continue
+		}
+
+		run := Run{Profile: t.Profile}
+		for k, v := range t.Env {
+			run.Env = append(run.Env, k+"="+os.Expand(v, func(key string) string {
+				if val, ok := vars[key]; ok {
+					return val
+				}
+				return "$" + key
+			}))
+		}
+		sort.Strings(run.Env)
+		runs = append(runs, run)
+	}
+	return
+}
+
+// Sign returns the signature of body at timestamp using secret, as expected in SignatureHeader. The timestamp is
+// sent in TimestampHeader.
+func Sign(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignRequest sets the signature of body and the current time on req.
+func SignRequest(req *http.Request, secret, body []byte) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, Sign(secret, timestamp, body))
+}
+
+// Record is an entry of the audit log.
+type Record struct {
+	Time   time.Time `json:"time"`
+	Remote string    `json:"remote,omitempty"`
+	Event  *Event    `json:"event,omitempty"`
+	Run    *Run      `json:"run,omitempty"`
+
+	// Outcome is what happened, such as the run starting or the webhook being rejected.
+	Outcome string `json:"outcome"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// Receiver starts runs for the webhooks it receives.
+type Receiver struct {
+	// Secret signs webhooks and requests for the audit log. Unsigned requests are accepted if it's empty.
+	Secret []byte
+
+	// Triggers select the runs started for events.
+	Triggers []Trigger
+
+	// Start performs a run, returning once it's finished.
+	Start func(Run) error
+
+	// MaxRuns limits how many runs are performed at once. Webhooks are refused while at the limit, so they're retried
+	// by their sender. There's no limit if it's 0.
+	MaxRuns int
+
+	// Audit has a JSON record written to it for each webhook and run.
+	Audit io.Writer
+
+	// DedupeWindow is how long events which started runs are ignored if redelivered. Events whose runs failed may be
+	// redelivered straight away. DefaultDedupeWindow is used if it's 0.
+	DedupeWindow time.Duration
+
+	mu      sync.Mutex
+	running int
+	seen    map[Event]time.Time
+	recent  []Record
+}
+
+// ServeHTTP starts the runs triggered by webhooks POSTed to it, and serves recent records of the audit log for GET.
+// Requests for the audit log are signed like webhooks, using their request URI as the body. Requests signed longer
+// than MaxSignatureAge ago are refused.
+func (r *Receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+		if !r.signed(req, []byte(req.URL.RequestURI())) {
+			http.Error(w, "invalid signature", http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(r.Recent()); err != nil {
+			log.Printf("Failed writing webhook audit records to %s: %v", req.RemoteAddr, err)
+		}
+		return
+	case http.MethodPost:
+	default:
+		http.Error(w, "only GET and POST are supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	status, msg := r.receive(req)
+	if status != http.StatusOK && status != http.StatusAccepted {
+		http.Error(w, msg, status)
+		return
+	}
+	w.WriteHeader(status)
+	fmt.Fprintln(w, msg)
+}
+
+// receive handles a webhook, returning the status and message of the response.
+func (r *Receiver) receive(req *http.Request) (int, string) {
+	rec := Record{Time: time.Now().UTC(), Remote: req.RemoteAddr, Outcome: OutcomeRejected}
+	reject := func(status int, reason string) (int, string) {
+		rec.Reason = reason
+		r.record(rec)
+		return status, reason
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(req.Body, maxBodySize))
+	if err != nil {
+		return reject(http.StatusBadRequest, fmt.Sprintf("couldn't read body: %v", err))
+	}
+	if !r.signed(req, body) {
+		return reject(http.StatusUnauthorized, "invalid signature")
+	}
+
+	var e Event
+	if err = json.Unmarshal(body, &e); err != nil {
+		return reject(http.StatusBadRequest, fmt.Sprintf("couldn't decode event: %v", err))
+	} else if !known(e.Type) || e.Name == "" {
+		return reject(http.StatusBadRequest, fmt.Sprintf("events must have a name and be one of %v", Events))
+	}
+	rec.Event = &e
+
+	runs := Runs(r.Triggers, e)
+	if len(runs) == 0 {
+		rec.Outcome = OutcomeUnmatched
+		r.record(rec)
+		return http.StatusOK, fmt.Sprintf("no runs are triggered by %s", e)
+	}
+
+	r.mu.Lock()
+	r.expireSeen(rec.Time)
+	if _, ok := r.seen[e]; ok {
+		r.mu.Unlock()
+		rec.Outcome = OutcomeDuplicate
+		r.record(rec)
+		return http.StatusOK, fmt.Sprintf("runs were already triggered by %s", e)
+	} else if r.MaxRuns > 0 && r.running+len(runs) > r.MaxRuns {
+		r.mu.Unlock()
+		rec.Outcome, rec.Reason = OutcomeBusy, fmt.Sprintf("%d runs are in progress", r.running)
+		r.record(rec)
+		return http.StatusServiceUnavailable, fmt.Sprintf("too many runs in progress to start %d more", len(runs))
+	}
+	if r.seen == nil {
+		r.seen = map[Event]time.Time{}
+	}
+	r.seen[e] = rec.Time
+	r.running += len(runs)
+	r.mu.Unlock()
+
+	profiles := make([]string, len(runs))
+	for i := range runs {
+		profiles[i] = runs[i].Profile
+		go r.perform(e, runs[i])
+	}
+	return http.StatusAccepted, fmt.Sprintf("started %s for %s", strings.Join(profiles, ", "), e)
+}
+
+// perform runs run, recording when it starts and its result.
+func (r *Receiver) perform(e Event, run Run) {
+	r.record(Record{Time: time.Now().UTC(), Event: &e, Run: &run, Outcome: OutcomeStarted})
+
+	err := r.Start(run)
+
+	r.mu.Lock()
+	r.running--
+	if err != nil {
+		// failed runs are retried when the event is redelivered
+		delete(r.seen, e)
+	}
+	r.mu.Unlock()
+
+	rec := Record{Time: time.Now().UTC(), Event: &e, Run: &run, Outcome: OutcomePassed}
+	if err != nil {
+		rec.Outcome, rec.Reason = OutcomeFailed, err.Error()
+	}
+	r.record(rec)
+}
+
+// signed returns true if req has a recent signature of body, or no secret is used.
+func (r *Receiver) signed(req *http.Request, body []byte) bool {
+	if len(r.Secret) == 0 {
+		return true
+	}
+
+	timestamp := req.Header.Get(TimestampHeader)
+	secs, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	} else if age := time.Since(time.Unix(secs, 0)); age > MaxSignatureAge || age < -MaxSignatureAge {
+		return false
+	}
+	return hmac.Equal([]byte(req.Header.Get(SignatureHeader)), []byte(Sign(r.Secret, timestamp, body)))
+}
+
+// expireSeen forgets events seen longer than the dedupe window before now. r.mu must be held.
+func (r *Receiver) expireSeen(now time.Time) {
+	window := r.DedupeWindow
+	if window == 0 {
+		window = DefaultDedupeWindow
+	}
+	for e, seen := range r.seen {
+		if now.Sub(seen) >= window {
+			delete(r.seen, e)
+		}
+	}
+}
+
+// Recent returns the latest records of the audit log, oldest first.
+func (r *Receiver) Recent() []Record {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Record{}, r.recent...)
+}
+
+// record writes rec to the audit log.
+func (r *Receiver) record(rec Record) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.recent = append(r.recent, rec)
+	if len(r.recent) > recentRecords {
+		r.recent = r.recent[len(r.recent)-recentRecords:]
+	}
+
+	if r.Audit == nil {
+		return
+	}
+	if data, err := json.Marshal(rec); err != nil {
+		log.Printf("Failed encoding webhook audit record: %v", err)
+	} else if _, err = r.Audit.Write(append(data, '\n')); err != nil {
+		log.Printf("Failed writing webhook audit record: %v", err)
+	}
+}
+
+// known returns true if event is one of Events.
+func known(event string) bool {
+	for _, e := range Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
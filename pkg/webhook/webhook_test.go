@@ -0,0 +1,243 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const triggersYAML = `
+triggers:
+- event: cluster-image-set
+  match: '^openshift-v4\.4\..*$'
+  profile: install
+  env:
+    CLUSTER_VERSION: ${NAME}
+- event: addon-version
+  profile: addons
+  env:
+    ADDON: ${NAME}-${VERSION}
+    KEEP: $OTHER
+`
+
+func TestLoadTriggers(t *testing.T) {
+	if _, err := Load("../../webhooks.yaml"); err != nil {
+		t.Errorf("failed to load webhooks.yaml: %v", err)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, data := range []string{
+		"triggers:\n- event: unknown\n  profile: a",
+		"triggers:\n- event: addon-version",
+		"triggers:\n- event: addon-version\n  profile: a\n  match: '('",
+	} {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("expected error parsing %q", data)
+		}
+	}
+}
+
+func TestRuns(t *testing.T) {
+	triggers, err := Parse([]byte(triggersYAML))
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+
+	runs := Runs(triggers, Event{Type: EventClusterImageSet, Name: "openshift-v4.4.0"})
+	expected := []Run{{Profile: "install", Env: []string{"CLUSTER_VERSION=openshift-v4.4.0"}}}
+	if !reflect.DeepEqual(runs, expected) {
+		t.Errorf("expected %v, got %v", expected, runs)
+	}
+
+	if runs = Runs(triggers, Event{Type: EventClusterImageSet, Name: "openshift-v4.3.8"}); len(runs) != 0 {
+		t.Errorf("expected image set not to match, got %v", runs)
+	}
+
+	runs = Runs(triggers, Event{Type: EventAddonVersion, Name: "managed-odh", Version: "0.5.0"})
+	expected = []Run{{Profile: "addons", Env: []string{"ADDON=managed-odh-0.5.0", "KEEP=$OTHER"}}}
+	if !reflect.DeepEqual(runs, expected) {
+		t.Errorf("expected %v, got %v", expected, runs)
+	}
+}
+
+func TestReceiver(t *testing.T) {
+	triggers, err := Parse([]byte(triggersYAML))
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+
+	secret := []byte("secret")
+	var audit bytes.Buffer
+	var mu sync.Mutex
+	started := make(chan Run, 1)
+	release := make(chan error)
+	r := &Receiver{
+		Secret:   secret,
+		Triggers: triggers,
+		MaxRuns:  1,
+		Audit:    &syncWriter{w: &audit, mu: &mu},
+		Start: func(run Run) error {
+			started <- run
+			return <-release
+		},
+	}
+
+	post := func(body string, signed bool) int {
+		req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(body))
+		if signed {
+			SignRequest(req, secret, []byte(body))
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	addon := `{"event": "addon-version", "name": "managed-odh", "version": "0.5.0"}`
+	if code := post(addon, false); code != http.StatusUnauthorized {
+		t.Errorf("expected unsigned webhook to be refused, got %d", code)
+	}
+	if code := post(`{"event": "unknown", "name": "a"}`, true); code != http.StatusBadRequest {
+		t.Errorf("expected unknown event to be refused, got %d", code)
+	}
+	if code := post(`{"event": "cluster-image-set", "name": "openshift-v4.3.8"}`, true); code != http.StatusOK {
+		t.Errorf("expected unmatched event to be accepted, got %d", code)
+	}
+	if code := post(addon, true); code != http.StatusAccepted {
+		t.Errorf("expected run to start, got %d", code)
+	}
+	if run := <-started; run.Profile != "addons" {
+		t.Errorf("expected addons to run, got %v", run)
+	}
+	if code := post(addon, true); code != http.StatusOK {
+		t.Errorf("expected duplicate to be accepted, got %d", code)
+	}
+	if code := post(`{"event": "cluster-image-set", "name": "openshift-v4.4.0"}`, true); code != http.StatusServiceUnavailable {
+		t.Errorf("expected webhook to be refused while busy, got %d", code)
+	}
+
+	release <- fmt.Errorf("tests failed")
+	deadline := time.Now().Add(5 * time.Second)
+	for !hasOutcome(r.Recent(), OutcomeFailed) && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	var outcomes []string
+	for _, rec := range r.Recent() {
+		outcomes = append(outcomes, rec.Outcome)
+	}
+	expected := []string{OutcomeRejected, OutcomeRejected, OutcomeUnmatched, OutcomeStarted, OutcomeDuplicate,
+		OutcomeBusy, OutcomeFailed}
+	if !reflect.DeepEqual(outcomes, expected) {
+		t.Errorf("expected outcomes %v, got %v", expected, outcomes)
+	}
+
+	mu.Lock()
+	lines := strings.Split(strings.TrimSpace(audit.String()), "\n")
+	mu.Unlock()
+	var last Record
+	if err = json.Unmarshal([]byte(lines[len(lines)-1]), &last); err != nil || len(lines) != len(expected) {
+		t.Fatalf("expected %d audit records, got %d: %v", len(expected), len(lines), err)
+	}
+	if last.Run == nil || last.Run.Profile != "addons" || last.Reason != "tests failed" {
+		t.Errorf("expected failure of addons to be audited, got %+v", last)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/webhook", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected unsigned request for the audit log to be refused, got %d", w.Code)
+	}
+
+	// signatures can't be replayed once they're stale
+	stale := strconv.FormatInt(time.Now().Add(-2*MaxSignatureAge).Unix(), 10)
+	req.Header.Set(TimestampHeader, stale)
+	req.Header.Set(SignatureHeader, Sign(secret, stale, []byte("/webhook")))
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected stale request for the audit log to be refused, got %d", w.Code)
+	}
+
+	SignRequest(req, secret, []byte("/webhook"))
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	var served []Record
+	if err = json.Unmarshal(w.Body.Bytes(), &served); err != nil || len(served) != len(expected) {
+		t.Errorf("expected recent records to be served, got %s: %v", w.Body, err)
+	}
+
+	// the run failed so redelivering its event retries it
+	if code := post(addon, true); code != http.StatusAccepted {
+		t.Errorf("expected failed run to be retried, got %d", code)
+	}
+	<-started
+	release <- nil
+}
+
+func TestReceiverDedupeWindow(t *testing.T) {
+	triggers, err := Parse([]byte(triggersYAML))
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+
+	r := &Receiver{
+		Triggers:     triggers,
+		DedupeWindow: 50 * time.Millisecond,
+		Start:        func(Run) error { return nil },
+	}
+	post := func() int {
+		req := httptest.NewRequest(http.MethodPost, "/webhook",
+			strings.NewReader(`{"event": "addon-version", "name": "managed-odh", "version": "0.5.0"}`))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := post(); code != http.StatusAccepted {
+		t.Fatalf("expected run to start, got %d", code)
+	}
+	if code := post(); code != http.StatusOK {
+		t.Errorf("expected redelivery within the window to be a duplicate, got %d", code)
+	}
+	time.Sleep(60 * time.Millisecond)
+	if code := post(); code != http.StatusAccepted {
+		t.Errorf("expected event to be forgotten after the window, got %d", code)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.seen) != 1 {
+		t.Errorf("expected expired events to be removed, got %v", r.seen)
+	}
+}
+
+func hasOutcome(records []Record, outcome string) bool {
+	for _, rec := range records {
+		if rec.Outcome == outcome {
+			return true
+		}
+	}
+	return false
+}
+
+// syncWriter guards writes to w so they can be read while runs finish.
+type syncWriter struct {
+	w  *bytes.Buffer
+	mu *sync.Mutex
+}
+
+func (s *syncWriter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.Write(p)
+}
@@ -0,0 +1,91 @@
+package workloads
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	kubev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openshift/osde2e/pkg/helper"
+)
+
+const (
+	// BatchImage runs the jobs of the batch workload.
+	BatchImage = "registry.access.redhat.com/ubi8/ubi-minimal"
+
+	// BatchSchedule is how often the batch workload runs a job.
+	BatchSchedule = "*/2 * * * *"
+
+	batchName = "batch"
+)
+
+// batch is a CronJob which must keep completing jobs after upgrading without any failing.
+type batch struct{}
+
+func (batch) Deploy(h *helper.H, namespace string) error {
+	_, err := h.Kube().BatchV1beta1().CronJobs(namespace).Create(&batchv1beta1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{Name: batchName},
+		Spec: batchv1beta1.CronJobSpec{
+			Schedule:          BatchSchedule,
+			ConcurrencyPolicy: batchv1beta1.ForbidConcurrent,
+			JobTemplate: batchv1beta1.JobTemplateSpec{
+				Spec: batchv1.JobSpec{
+					Template: kubev1.PodTemplateSpec{
+						Spec: kubev1.PodSpec{
+							RestartPolicy: kubev1.RestartPolicyOnFailure,
+							Containers: []kubev1.Container{
+								{
+									Name:    batchName,
+									Image:   BatchImage,
+									Command: []string{"/bin/sh", "-c", "sha256sum /dev/zero | head -c 100M > /dev/null"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't create cronjob '%s': %v", batchName, err)
+	}
+	return waitForJob(h, namespace, time.Now())
+}
+
+func (batch) Verify(h *helper.H, namespace string) error {
+	jobs, err := h.Kube().BatchV1().Jobs(namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("couldn't list jobs: %v", err)
+	}
+	for _, job := range jobs.Items {
+		for _, cond := range job.Status.Conditions {
+			if cond.Type == batchv1.JobFailed && cond.Status == kubev1.ConditionTrue {
+				return fmt.Errorf("job '%s' failed: %s", job.Name, cond.Message)
+			}
+		}
+	}
+
+	// jobs must still be scheduled and complete
+	return waitForJob(h, namespace, time.Now())
+}
+
+// waitForJob until a job completes after since.
+func waitForJob(h *helper.H, namespace string, since time.Time) error {
+	return h.PollImmediate(pollInterval, ReadyTimeout, func() (bool, error) {
+		jobs, err := h.Kube().BatchV1().Jobs(namespace).List(metav1.ListOptions{})
+		if err != nil {
+			return false, fmt.Errorf("couldn't list jobs: %v", err)
+		}
+		for _, job := range jobs.Items {
+			if job.Status.CompletionTime != nil && job.Status.CompletionTime.After(since) {
+				return true, nil
+			}
+		}
+		log.Printf("Waiting for a job of '%s/%s' to complete...", namespace, batchName)
+		return false, nil
+	})
+}
@@ -0,0 +1,132 @@
+package workloads
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	kubev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openshift/osde2e/pkg/helper"
+	"github.com/openshift/osde2e/pkg/seed"
+)
+
+const (
+	// DatabaseImage runs PostgreSQL, storing data in databaseDataDir.
+	DatabaseImage = "registry.access.redhat.com/rhscl/postgresql-10-rhel7"
+
+	databaseName    = "database"
+	databaseDataDir = "/var/lib/pgsql/data"
+	databaseSize    = "1Gi"
+)
+
+// database is PostgreSQL storing a row on a persistent volume, which must be readable after upgrading.
+type database struct{}
+
+func (database) Deploy(h *helper.H, namespace string) error {
+	marker := strconv.FormatInt(seed.Int63(seed.Workloads), 36)
+	replicas := int32(1)
+	labels := map[string]string{"app": databaseName}
+
+	_, err := h.Kube().AppsV1().StatefulSets(namespace).Create(&appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        databaseName,
+			Annotations: map[string]string{markerAnnotation: marker},
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas:    &replicas,
+			ServiceName: databaseName,
+			Selector:    &metav1.LabelSelector{MatchLabels: labels},
+			Template: kubev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: kubev1.PodSpec{
+					Containers: []kubev1.Container{
+						{
+							Name:  databaseName,
+							Image: DatabaseImage,
+							Env: []kubev1.EnvVar{
+								{Name: "POSTGRESQL_USER", Value: "osde2e"},
+								{Name: "POSTGRESQL_PASSWORD", Value: marker},
+								{Name: "POSTGRESQL_DATABASE", Value: "osde2e"},
+							},
+							ReadinessProbe: &kubev1.Probe{
+								Handler: kubev1.Handler{
+									Exec: &kubev1.ExecAction{Command: []string{"/usr/libexec/check-container"}},
+								},
+							},
+							VolumeMounts: []kubev1.VolumeMount{{Name: "data", MountPath: databaseDataDir}},
+						},
+					},
+				},
+			},
+			VolumeClaimTemplates: []kubev1.PersistentVolumeClaim{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "data"},
+					Spec: kubev1.PersistentVolumeClaimSpec{
+						AccessModes: []kubev1.PersistentVolumeAccessMode{kubev1.ReadWriteOnce},
+						Resources: kubev1.ResourceRequirements{
+							Requests: kubev1.ResourceList{kubev1.ResourceStorage: resource.MustParse(databaseSize)},
+						},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't create statefulset '%s': %v", databaseName, err)
+	}
+
+	if err = waitForStatefulSet(h, namespace, databaseName); err != nil {
+		return err
+	}
+	_, err = psql(h, namespace, fmt.Sprintf("CREATE TABLE osde2e (marker text); INSERT INTO osde2e VALUES ('%s');", marker))
+	return err
+}
+
+func (database) Verify(h *helper.H, namespace string) error {
+	set, err := h.Kube().AppsV1().StatefulSets(namespace).Get(databaseName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("couldn't get statefulset '%s': %v", databaseName, err)
+	}
+	if err = waitForStatefulSet(h, namespace, databaseName); err != nil {
+		return err
+	}
+
+	out, err := psql(h, namespace, "SELECT marker FROM osde2e;")
+	if err != nil {
+		return err
+	}
+	if marker := set.Annotations[markerAnnotation]; out != marker {
+		return fmt.Errorf("expected database to contain '%s', found '%s'", marker, out)
+	}
+	return nil
+}
+
+// psql runs query in the database, returning its unaligned output.
+func psql(h *helper.H, namespace, query string) (string, error) {
+	cmd := `psql -U "$POSTGRESQL_USER" -d "$POSTGRESQL_DATABASE" -v ON_ERROR_STOP=1 -tAc "$0"`
+	result, err := h.Exec(namespace, databaseName+"-0", databaseName, "bash", "-c", cmd, query)
+	if err != nil {
+		return "", fmt.Errorf("couldn't query database: %v: %s", err, result.Stderr)
+	}
+	return strings.TrimSpace(string(result.Stdout)), nil
+}
+
+// waitForStatefulSet until all replicas of the StatefulSet name are ready.
+func waitForStatefulSet(h *helper.H, namespace, name string) error {
+	return h.PollImmediate(pollInterval, ReadyTimeout, func() (bool, error) {
+		set, err := h.Kube().AppsV1().StatefulSets(namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return false, fmt.Errorf("couldn't get statefulset '%s': %v", name, err)
+		}
+		if set.Spec.Replicas != nil && set.Status.ReadyReplicas >= *set.Spec.Replicas && set.Status.ObservedGeneration >= set.Generation {
+			return true, nil
+		}
+		log.Printf("Waiting for statefulset '%s/%s' to be ready (%d/%d)...", namespace, name, set.Status.ReadyReplicas, *set.Spec.Replicas)
+		return false, nil
+	})
+}
@@ -0,0 +1,66 @@
+package workloads
+
+import (
+	"fmt"
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/openshift/osde2e/pkg/helper"
+)
+
+const operatorName = "operator"
+
+// PrometheusRuleGVR is the resource of alerting rules reconciled by the Prometheus operator.
+var PrometheusRuleGVR = schema.GroupVersionResource{Group: "monitoring.coreos.com", Version: "v1", Resource: "prometheusrules"}
+
+// operator is a custom resource managed by an operator, which must keep its spec after upgrading.
+type operator struct{}
+
+func (operator) Deploy(h *helper.H, namespace string) error {
+	_, err := h.ApplyResource(PrometheusRuleGVR, prometheusRule(namespace))
+	return err
+}
+
+func (operator) Verify(h *helper.H, namespace string) error {
+	obj, err := h.GetResource(PrometheusRuleGVR, namespace, operatorName)
+	if err != nil {
+		return err
+	}
+
+	expected := prometheusRule(namespace).Object["spec"]
+	if spec := obj.Object["spec"]; !reflect.DeepEqual(spec, expected) {
+		return fmt.Errorf("spec of %s '%s' changed, expected %v but found %v", PrometheusRuleGVR.Resource, operatorName, expected, spec)
+	}
+	return nil
+}
+
+func prometheusRule(namespace string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"groups": []interface{}{
+					map[string]interface{}{
+						"name": "osde2e-workload",
+						"rules": []interface{}{
+							map[string]interface{}{
+								"alert": "OSDE2EWorkloadDown",
+								"expr":  fmt.Sprintf(`kube_deployment_status_replicas_available{namespace="%s"} == 0`, namespace),
+								"for":   "10m",
+								"labels": map[string]interface{}{
+									"severity": "warning",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	obj.SetAPIVersion(PrometheusRuleGVR.GroupVersion().String())
+	obj.SetKind("PrometheusRule")
+	obj.SetName(operatorName)
+	obj.SetNamespace(namespace)
+	return obj
+}
@@ -0,0 +1,120 @@
+package workloads
+
+import (
+	"fmt"
+	"log"
+
+	routev1 "github.com/openshift/api/route/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	kubev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/openshift/osde2e/pkg/helper"
+)
+
+const (
+	// WebImage serves HTTP on webPort.
+	WebImage = "docker.io/openshift/hello-openshift"
+
+	webName     = "web"
+	webPort     = 8080
+	webReplicas = 3
+)
+
+// web is a stateless tier of several replicas behind a service and route.
+type web struct{}
+
+func (web) Deploy(h *helper.H, namespace string) error {
+	replicas := int32(webReplicas)
+	labels := map[string]string{"app": webName}
+	_, err := h.Kube().AppsV1().Deployments(namespace).Create(&appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: webName},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: kubev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: kubev1.PodSpec{
+					Containers: []kubev1.Container{
+						{
+							Name:  webName,
+							Image: WebImage,
+							Ports: []kubev1.ContainerPort{{ContainerPort: webPort}},
+						},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't create deployment '%s': %v", webName, err)
+	}
+
+	_, err = h.Kube().CoreV1().Services(namespace).Create(&kubev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: webName},
+		Spec: kubev1.ServiceSpec{
+			Selector: labels,
+			Ports:    []kubev1.ServicePort{{Port: webPort, TargetPort: intstr.FromInt(webPort)}},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't create service '%s': %v", webName, err)
+	}
+
+	_, err = h.Route().RouteV1().Routes(namespace).Create(&routev1.Route{
+		ObjectMeta: metav1.ObjectMeta{Name: webName},
+		Spec: routev1.RouteSpec{
+			To: routev1.RouteTargetReference{Kind: "Service", Name: webName},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't create route '%s': %v", webName, err)
+	}
+	return web{}.Verify(h, namespace)
+}
+
+func (web) Verify(h *helper.H, namespace string) error {
+	if err := waitForDeployment(h, namespace, webName); err != nil {
+		return err
+	}
+
+	svc, err := h.Kube().CoreV1().Services(namespace).Get(webName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("couldn't get service '%s': %v", webName, err)
+	}
+	if err = h.WaitForEndpointReady(svc, int(ReadyTimeout/pollInterval), pollInterval); err != nil {
+		return err
+	}
+
+	return h.PollImmediate(pollInterval, ReadyTimeout, func() (bool, error) {
+		route, err := h.Route().RouteV1().Routes(namespace).Get(webName, metav1.GetOptions{})
+		if err != nil {
+			return false, fmt.Errorf("couldn't get route '%s': %v", webName, err)
+		}
+		for _, ingress := range route.Status.Ingress {
+			for _, cond := range ingress.Conditions {
+				if cond.Type == routev1.RouteAdmitted && cond.Status == kubev1.ConditionTrue {
+					return true, nil
+				}
+			}
+		}
+		log.Printf("Waiting for route '%s/%s' to be admitted...", namespace, webName)
+		return false, nil
+	})
+}
+
+// waitForDeployment until all replicas of the Deployment name are available.
+func waitForDeployment(h *helper.H, namespace, name string) error {
+	return h.PollImmediate(pollInterval, ReadyTimeout, func() (bool, error) {
+		d, err := h.Kube().AppsV1().Deployments(namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return false, fmt.Errorf("couldn't get deployment '%s': %v", name, err)
+		}
+		if d.Spec.Replicas != nil && d.Status.AvailableReplicas >= *d.Spec.Replicas && d.Status.ObservedGeneration >= d.Generation {
+			return true, nil
+		}
+		log.Printf("Waiting for deployment '%s/%s' to be available (%d/%d)...", namespace, name, d.Status.AvailableReplicas, *d.Spec.Replicas)
+		return false, nil
+	})
+}
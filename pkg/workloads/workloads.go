@@ -0,0 +1,159 @@
+// Package workloads deploys workloads resembling those run by customers before an upgrade and verifies they
+// survived it, so upgrades are tested on clusters holding data rather than empty ones.
+package workloads
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	kubev1 "k8s.io/api/core/v1"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/test-infra/testgrid/metadata/junit"
+
+	"github.com/openshift/osde2e/pkg/helper"
+	"github.com/openshift/osde2e/pkg/junitprops"
+)
+
+const (
+	// SuiteName is the JUnit suite containing workload results.
+	SuiteName = "OSD workloads"
+
+	// NamespacePrefix is followed by the name of the profile in the namespace its workload runs in.
+	NamespacePrefix = "osde2e-workload-"
+
+	// ReadyTimeout is how long a workload has to become ready after it's deployed and after upgrading.
+	ReadyTimeout = 10 * time.Minute
+
+	// pollInterval is how often workloads are checked while waiting for them.
+	pollInterval = 10 * time.Second
+
+	// markerAnnotation holds data written to a workload when deployed, to be checked when verified.
+	markerAnnotation = "osde2e.openshift.io/marker"
+)
+
+// Workload is deployed to a namespace before upgrading then verified afterward.
+type Workload interface {
+	// Deploy creates the workload in namespace, returning once it's ready.
+	Deploy(h *helper.H, namespace string) error
+
+	// Verify checks the workload in namespace is ready and its data intact.
+	Verify(h *helper.H, namespace string) error
+}
+
+// Profiles are the workloads available by name.
+var Profiles = map[string]Workload{
+	"web":      web{},
+	"database": database{},
+	"batch":    batch{},
+	"operator": operator{},
+}
+
+// Names returns the names of Profiles in order.
+func Names() []string {
+	names := make([]string, 0, len(Profiles))
+	for name := range Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Validate returns an error if any of names isn't a profile.
+func Validate(names []string) error {
+	for _, name := range names {
+		if _, ok := Profiles[name]; !ok {
+			return fmt.Errorf("unknown workload profile '%s', must be one of: %s", name, strings.Join(Names(), ", "))
+		}
+	}
+	return nil
+}
+
+// Result is the outcome of deploying and verifying a profile.
+type Result struct {
+	Profile string
+
+	// Err is set if the workload couldn't be deployed or didn't survive the upgrade.
+	Err error
+}
+
+// Name identifies the result in JUnit.
+func (r Result) Name() string {
+	return fmt.Sprintf("[workload] %s", r.Profile)
+}
+
+// Deploy creates the workloads of profiles, each in its own namespace. Results are returned for those that failed.
+func Deploy(h *helper.H, profiles []string) (failed []Result) {
+	for _, name := range profiles {
+		namespace := NamespacePrefix + name
+		log.Printf("Deploying %s workload to '%s'...", name, namespace)
+
+		_, err := h.Kube().CoreV1().Namespaces().Create(&kubev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: namespace},
+		})
+		if err != nil {
+			err = fmt.Errorf("couldn't create namespace '%s': %v", namespace, err)
+		} else {
+			err = Profiles[name].Deploy(h, namespace)
+		}
+
+		if err != nil {
+			log.Printf("Failed to deploy %s workload: %v", name, err)
+			failed = append(failed, Result{Profile: name, Err: fmt.Errorf("deploying: %v", err)})
+		}
+	}
+	return
+}
+
+// Verify checks the workloads of profiles which were deployed then removes them. Profiles in failed aren't verified
+// and keep their result.
+func Verify(h *helper.H, profiles []string, failed []Result) (results []Result) {
+	deployErrs := map[string]error{}
+	for _, r := range failed {
+		deployErrs[r.Profile] = r.Err
+	}
+
+	for _, name := range profiles {
+		namespace := NamespacePrefix + name
+		result := Result{Profile: name, Err: deployErrs[name]}
+		if result.Err == nil {
+			log.Printf("Verifying %s workload in '%s'...", name, namespace)
+			if err := Profiles[name].Verify(h, namespace); err != nil {
+				log.Printf("The %s workload didn't survive upgrading: %v", name, err)
+				result.Err = fmt.Errorf("verifying: %v", err)
+			}
+		}
+		results = append(results, result)
+
+		err := h.Kube().CoreV1().Namespaces().Delete(namespace, &metav1.DeleteOptions{})
+		if err != nil && !kerror.IsNotFound(err) {
+			log.Printf("Failed to delete namespace '%s': %v", namespace, err)
+		}
+	}
+	return
+}
+
+// WriteJUnit records a testcase for each result in dir.
+func WriteJUnit(dir, suffix string, results []Result) error {
+	suite := junit.Suite{
+		Name:  SuiteName,
+		Tests: len(results),
+	}
+	for _, r := range results {
+		result := junit.Result{
+			Name:      r.Name(),
+			ClassName: SuiteName,
+		}
+		if r.Err != nil {
+			msg := r.Err.Error()
+			result.Failure = &msg
+			suite.Failures++
+		}
+		suite.Results = append(suite.Results, result)
+	}
+
+	return junitprops.WriteSuite(dir, "workloads", suffix, suite)
+}
@@ -0,0 +1,44 @@
+package workloads
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	if err := Validate([]string{"web", "database", "batch", "operator"}); err != nil {
+		t.Errorf("expected profiles to be valid: %v", err)
+	}
+	if err := Validate([]string{"web", "mainframe"}); err == nil || !strings.Contains(err.Error(), "batch, database, operator, web") {
+		t.Errorf("expected unknown profile to list those available, got: %v", err)
+	}
+}
+
+func TestWriteJUnit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "workloads")
+	if err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	results := []Result{
+		{Profile: "web"},
+		{Profile: "database", Err: errors.New("verifying: expected database to contain 'abc', found ''")},
+	}
+	if err = WriteJUnit(dir, "xyz", results); err != nil {
+		t.Fatalf("failed to write JUnit: %v", err)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "junit_workloads_xyz.xml"))
+	if err != nil {
+		t.Fatalf("failed to read JUnit: %v", err)
+	}
+	junit := string(data)
+	if !strings.Contains(junit, `failures="1" tests="2"`) || !strings.Contains(junit, "[workload] database") {
+		t.Errorf("unexpected JUnit: %s", junit)
+	}
+}
@@ -0,0 +1,72 @@
+package osde2e
+
+import (
+	"fmt"
+	"log"
+	"os/exec"
+	"time"
+
+	"github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/openshift/osde2e/pkg/config"
+	"github.com/openshift/osde2e/pkg/plugin"
+)
+
+// ClusterProvider creates and manages the cluster being tested.
+type ClusterProvider interface {
+	LaunchCluster(cfg *config.Config) (string, error)
+	WaitForClusterReady(clusterID string, timeout, interval time.Duration) error
+	ClusterKubeconfig(clusterID string) ([]byte, error)
+	FullLogs(clusterID string, ids ...string) (map[string][]byte, error)
+	DeleteCluster(clusterID string) error
+}
+
+// startProviderPlugin runs the provider plugin configured in cfg.
+func startProviderPlugin(cfg *config.Config) (*plugin.ProviderClient, error) {
+	client, err := plugin.Start(exec.Command(cfg.ProviderPlugin))
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Using provider plugin '%s'.", cfg.ProviderPlugin)
+	return &plugin.ProviderClient{Client: client}, nil
+}
+
+// startSuitePlugins runs the suite plugins configured in cfg and defines a test for each test they contain.
+func startSuitePlugins(cfg *config.Config) (suites []*plugin.SuiteClient, err error) {
+	for _, path := range cfg.SuitePlugins {
+		client, err := plugin.Start(exec.Command(path))
+		if err != nil {
+			return suites, err
+		}
+
+		suite := &plugin.SuiteClient{Client: client}
+		suites = append(suites, suite)
+
+		info, err := suite.Info()
+		if err != nil {
+			return suites, fmt.Errorf("couldn't describe suite plugin '%s': %v", path, err)
+		}
+		log.Printf("Loaded suite plugin '%s' with %d tests.", info.Name, len(info.Tests))
+		describeSuitePlugin(suite, info)
+	}
+	return
+}
+
+// describeSuitePlugin defines a test for each test contained in the suite plugin.
+func describeSuitePlugin(suite *plugin.SuiteClient, info plugin.SuiteInfo) {
+	ginkgo.Describe(fmt.Sprintf("[Plugin: %s]", info.Name), func() {
+		for _, test := range info.Tests {
+			test := test
+			ginkgo.It(test, func() {
+				result, err := suite.Run(test, config.Cfg.Kubeconfig)
+				Expect(err).NotTo(HaveOccurred())
+
+				if result.Output != "" {
+					log.Printf("Output of '%s':\n%s", test, result.Output)
+				}
+				Expect(result.Passed).To(BeTrue(), result.Message)
+			})
+		}
+	})
+}
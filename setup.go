@@ -1,39 +1,205 @@
 package osde2e
 
 import (
+	"bytes"
+	"context"
 	"fmt"
-	"io/ioutil"
 	"log"
-	"math/rand"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
 
 	"github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
+	kubev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+
+	"github.com/openshift/osde2e/pkg/addonbundle"
+	"github.com/openshift/osde2e/pkg/artifacts"
+	"github.com/openshift/osde2e/pkg/clusterspec"
+	"github.com/openshift/osde2e/pkg/conditions"
 	"github.com/openshift/osde2e/pkg/config"
+	"github.com/openshift/osde2e/pkg/configurator"
+	"github.com/openshift/osde2e/pkg/debug"
+	"github.com/openshift/osde2e/pkg/drift"
+	"github.com/openshift/osde2e/pkg/fingerprint"
+	"github.com/openshift/osde2e/pkg/health"
+	"github.com/openshift/osde2e/pkg/helper"
+	"github.com/openshift/osde2e/pkg/hosted"
+	"github.com/openshift/osde2e/pkg/imagescan"
+	"github.com/openshift/osde2e/pkg/logmetrics"
+	"github.com/openshift/osde2e/pkg/machinepool"
+	"github.com/openshift/osde2e/pkg/naming"
+	"github.com/openshift/osde2e/pkg/nodelogs"
+	"github.com/openshift/osde2e/pkg/olm"
 	"github.com/openshift/osde2e/pkg/osd"
+	"github.com/openshift/osde2e/pkg/privilege"
+	"github.com/openshift/osde2e/pkg/regions"
+	"github.com/openshift/osde2e/pkg/seed"
+	"github.com/openshift/osde2e/pkg/synthetics"
+	"github.com/openshift/osde2e/pkg/tenant"
+	"github.com/openshift/osde2e/pkg/timeline"
+	"github.com/openshift/osde2e/pkg/topology"
 	"github.com/openshift/osde2e/pkg/upgrade"
+	"github.com/openshift/osde2e/pkg/usage"
+	"github.com/openshift/osde2e/pkg/workloads"
 )
 
-func init() {
-	rand.Seed(time.Now().Unix())
-}
+const (
+	// addonTimeout is how long to wait for each add-on of a bundle to be ready.
+	addonTimeout = 45 * time.Minute
+
+	// machinePoolTimeout is how long to wait for the nodes of machine pools to join the cluster.
+	machinePoolTimeout = 30 * time.Minute
+
+	// operatorPinTimeout is how long to wait for each pinned operator version to install.
+	operatorPinTimeout = 10 * time.Minute
+
+	// profileTimeout is how long to wait for nodes to be updated with the configuration profile.
+	profileTimeout = 45 * time.Minute
+
+	// forensicsTimeout is how long OSD is given to describe why a cluster failed to install.
+	forensicsTimeout = 5 * time.Minute
+
+	// topUsageSuites is how many of the suites consuming the most CPU and memory are logged.
+	topUsageSuites = 5
+)
+
+// setupStarted is when cluster setup began. Node logs are analyzed from this point.
+var setupStarted time.Time
+
+// endPhase cancels the context of the phase being run.
+var endPhase context.CancelFunc = func() {}
+
+// prober continuously probes the cluster once installed. It is nil when synthetics aren't enabled.
+var prober *synthetics.Prober
+
+// upgradedVersion is the version the cluster was last successfully upgraded to. It is empty when it wasn't upgraded.
+var upgradedVersion string
 
 // Setup cluster before testing begins.
 var _ = ginkgo.SynchronizedBeforeSuite(func() []byte {
 	defer ginkgo.GinkgoRecover()
 	cfg := config.Cfg
+	setupStarted = time.Now()
 
+	startPhase(cfg, config.PhaseInstall)
+	providedKubeconfig := len(cfg.Kubeconfig) != 0
 	err := setupCluster(cfg)
 	Expect(err).ShouldNot(HaveOccurred(), "failed to setup cluster for testing")
 
+	// suites changing the cluster, such as by hibernating it, write to its service log too
+	helper.ServiceLogger = func(summary, format string, args ...interface{}) {
+		serviceLog(cfg, summary, format, args...)
+	}
+
+	// hosted control planes are checked through the cluster hosting them
+	if cfg.Hypershift {
+		err = hosted.LoadKubeconfig(cfg, OSD)
+		Expect(err).ShouldNot(HaveOccurred(), "failed getting kubeconfig of management cluster")
+	}
+
+	// installed clusters should pass every health check, including those registered by suites, before they're tested
+	if !providedKubeconfig && cfg.RunPhase(config.PhaseInstall) {
+		h := &helper.H{
+			Config: cfg,
+		}
+		h.SetupClients()
+		r, err := upgrade.CheckHealth(h, string(config.PhaseInstall))
+		recordHealth(r)
+		Expect(err).ShouldNot(HaveOccurred(), "cluster not healthy after install")
+	}
+
+	// wait for room on a cluster shared with other runs
+	if cfg.SharedCluster {
+		err = joinSharedCluster(cfg)
+		Expect(err).ShouldNot(HaveOccurred(), "failed joining shared cluster")
+	}
+
+	// add machine pools before recording topology so their nodes are included
+	if cfg.MachinePools != "" && cfg.RunPhase(config.PhaseInstall) {
+		Progress.Update("Adding machine pools to cluster '%s' from '%s'", cfg.ClusterID, cfg.MachinePools)
+		err = addMachinePools(cfg)
+		serviceLog(cfg, "osde2e added machine pools", "Added the machine pools of '%s'%s.", cfg.MachinePools, outcome(err))
+		Expect(err).ShouldNot(HaveOccurred(), "failed adding machine pools")
+	}
+
+	// record where the cluster runs for reporting
+	if cfg.RunPhase(config.PhaseInstall) || cfg.RunPhase(config.PhaseTests) {
+		if err = recordTopology(cfg); err != nil {
+			log.Printf("Failed to get cluster topology: %v", err)
+		}
+	}
+
+	// probe the cluster for the rest of the run
+	if cfg.Synthetics && (cfg.RunPhase(config.PhaseUpgrade) || cfg.RunPhase(config.PhaseTests)) {
+		h := &helper.H{
+			Config: cfg,
+		}
+		h.SetupClients()
+		if prober, err = synthetics.Start(h, cfg.SyntheticsInterval); err != nil {
+			log.Printf("Failed to start synthetic probes: %v", err)
+		}
+	}
+
+	// configure cluster like customers would if requested
+	if cfg.ConfigProfile != "" && cfg.RunPhase(config.PhaseInstall) {
+		Progress.Update("Configuring cluster '%s' with profile '%s'", cfg.ClusterID, cfg.ConfigProfile)
+		err = applyProfile(cfg)
+		serviceLog(cfg, "osde2e configured the cluster", "Applied configuration profile '%s'%s.", cfg.ConfigProfile, outcome(err))
+		Expect(err).ShouldNot(HaveOccurred(), "failed applying configuration profile")
+	}
+
+	// install add-ons which are tested together
+	if cfg.AddonBundle != "" && cfg.RunPhase(config.PhaseInstall) {
+		Progress.Update("Installing add-ons on cluster '%s' from bundle '%s'", cfg.ClusterID, cfg.AddonBundle)
+		err = installAddonBundle(cfg)
+		serviceLog(cfg, "osde2e installed add-ons for testing", "Installed the add-ons of bundle '%s'%s.", cfg.AddonBundle, outcome(err))
+		Expect(err).ShouldNot(HaveOccurred(), "failed installing add-on bundle")
+	}
+
+	// OCM and the cluster should agree on what was installed
+	if cfg.RunPhase(config.PhaseInstall) {
+		checkSpec(cfg, "install")
+	}
+
 	// upgrade cluster if requested
-	if cfg.UpgradeImage != "" || cfg.UpgradeReleaseStream != "" {
-		err = upgrade.RunUpgrade(cfg)
+	startPhase(cfg, config.PhaseUpgrade)
+	if (len(upgrade.Hops(cfg)) != 0 || cfg.UpgradeReleaseStream != "") && cfg.RunPhase(config.PhaseUpgrade) {
+		Progress.Update("Upgrading cluster '%s'", cfg.ClusterID)
+		err = upgradeCluster(cfg)
+		checkSpec(cfg, "upgrade")
 		Expect(err).ShouldNot(HaveOccurred(), "failed performing upgrade")
+		Progress.Update("Upgraded cluster '%s'", cfg.ClusterID)
+	}
+
+	// install pinned operator versions if requested
+	startPhase(cfg, config.PhaseTests)
+	if len(cfg.OperatorVersions) != 0 && cfg.RunPhase(config.PhaseTests) {
+		err = pinOperators(cfg)
+		serviceLog(cfg, "osde2e installed operator versions", "Installed operator versions %v%s.", cfg.OperatorVersions, outcome(err))
+		Expect(err).ShouldNot(HaveOccurred(), "failed pinning operator versions")
+	}
+
+	// the cluster should stay at the version tested until testing ends
+	if cfg.VersionDriftInterval != 0 && len(cfg.Kubeconfig) != 0 && cfg.RunPhase(config.PhaseTests) {
+		if err = guardVersion(cfg); err != nil {
+			log.Printf("Failed to guard against cluster version drift: %v", err)
+		}
+	}
+
+	// notice operators degrading between health checks
+	if conditions.Current != nil && cfg.OperatorConditionInterval != 0 && len(cfg.Kubeconfig) != 0 &&
+		cfg.RunPhase(config.PhaseTests) {
+		if list, err := conditions.ClusterOperators(cfg.Kubeconfig); err != nil {
+			log.Printf("Failed to record ClusterOperator conditions: %v", err)
+		} else {
+			conditions.Current.Start(list, cfg.OperatorConditionInterval)
+		}
 	}
 
 	return []byte{}
@@ -46,28 +212,184 @@ var _ = ginkgo.AfterSuite(func() {
 	defer ginkgo.GinkgoRecover()
 	cfg := config.Cfg
 
-	if OSD == nil {
-		log.Println("OSD was not configured. Skipping AfterSuite...")
+	// let developers inspect failures before anything is removed from the cluster
+	if cfg.Interactive {
+		pauseOnFailure(cfg)
+	}
+
+	startPhase(cfg, config.PhaseTeardown)
+	defer endPhase()
+
+	// make room for other runs of a shared cluster, removing what's left of this one
+	if tenant.Current != nil {
+		if err := tenant.Current.Leave(); err != nil {
+			log.Printf("Failed to leave shared cluster: %v", err)
+		}
+	}
+
+	// report whether the cluster's version changed while testing
+	if drift.Current != nil {
+		drift.Current.Stop()
+		if err := drift.Current.WriteJUnit(cfg.ReportDir, cfg.Suffix); err != nil {
+			log.Printf("Failed to report cluster version drift: %v", err)
+		}
+	}
+
+	// report operators which repeatedly became Degraded or Progressing, even if they recovered
+	conditions.Current.Stop()
+	if len(conditions.Current.Operators()) != 0 {
+		if err := conditions.Current.WriteJUnit(cfg.ReportDir, cfg.Suffix); err != nil {
+			log.Printf("Failed to report ClusterOperator condition churn: %v", err)
+		}
+		if err := conditions.Current.Write(cfg.ReportDir); err != nil {
+			log.Printf("Failed to write ClusterOperator condition history: %v", err)
+		}
+	}
+
+	// report requests suites made without declaring the permissions they need
+	if privilege.Current != nil {
+		if err := privilege.Current.WriteJUnit(cfg.ReportDir, cfg.Suffix); err != nil {
+			log.Printf("Failed to report least privilege denials: %v", err)
+		}
+	}
+
+	// measure what suites consumed while Prometheus still has their metrics
+	if len(cfg.Kubeconfig) != 0 && cfg.RunPhase(config.PhaseTests) {
+		if err := reportUsage(cfg); err != nil {
+			log.Printf("Failed to measure usage of suites: %v", err)
+		}
+	}
+
+	// report gaps in availability while the cluster is still available
+	if prober != nil {
+		if err := reportSynthetics(cfg); err != nil {
+			log.Printf("Failed to report synthetic probes: %v", err)
+		}
+	}
+
+	// place the run in the context of the cluster while its events and alerts can still be collected
+	if cfg.Timeline {
+		if err := reportTimeline(cfg); err != nil {
+			log.Printf("Failed to write timeline: %v", err)
+		}
+	}
+
+	// check nodes for problems while the cluster is still available
+	if cfg.NodeLogAnalysis && len(cfg.Kubeconfig) != 0 && cfg.RunPhase(config.PhaseTests) {
+		if err := analyzeNodeLogs(cfg); err != nil {
+			log.Printf("Failed to analyze node logs: %v", err)
+		}
+	}
+
+	// scan images of managed components while the cluster is still available
+	if cfg.ImageScanner != "" && len(cfg.Kubeconfig) != 0 && cfg.RunPhase(config.PhaseTests) {
+		if err := scanImages(cfg); err != nil {
+			log.Printf("Failed to scan images: %v", err)
+		}
+	}
+
+	// compare the cluster to OCM once more after testing
+	if cfg.RunPhase(config.PhaseTests) {
+		checkSpec(cfg, "tests")
+	}
+	if len(SpecDrift.Checkpoints) != 0 {
+		if err := SpecDrift.Write(cfg.ReportDir); err != nil {
+			log.Printf("Failed to store cluster spec drift: %v", err)
+		}
+		if err := SpecDrift.WriteJUnit(cfg.ReportDir, cfg.Suffix); err != nil {
+			log.Printf("Failed to report cluster spec drift: %v", err)
+		}
+	}
+
+	// describe what the cluster is made of for bug reports while it's still available
+	if len(cfg.Kubeconfig) != 0 {
+		if err := writeFingerprint(cfg); err != nil {
+			log.Printf("Failed to fingerprint cluster: %v", err)
+		}
+	}
+
+	if !cfg.RunPhase(config.PhaseTeardown) {
+		log.Printf("The %s phase isn't selected. Skipping AfterSuite...", config.PhaseTeardown)
+	} else if Provider == nil {
+		log.Println("No cluster provider was configured. Skipping AfterSuite...")
 	} else if cfg.ClusterID == "" {
 		log.Println("CLUSTER_ID is not set, likely due to a setup failure. Skipping AfterSuite...")
+	} else if cfg.SharedCluster {
+		log.Printf("Cluster '%s' is shared with other runs, leaving it running.", cfg.ClusterID)
 	} else {
 		log.Printf("Getting logs for cluster '%s'...", cfg.ClusterID)
 
-		logs, err := OSD.FullLogs(cfg.ClusterID)
+		logs, err := Provider.FullLogs(cfg.ClusterID)
 		Expect(err).NotTo(HaveOccurred(), "failed to collect cluster logs")
-		writeLogs(cfg, logs)
+		writeLogs(logs)
 
-		if cfg.NoDestroy {
-			log.Println("NO_DESTROY is set, skipping deleting cluster.")
+		if cfg.KeepCluster(Failures.SetupFailed(), Failures.TestsFailed()) {
+			keepCluster(cfg)
 			return
 		}
 
 		log.Printf("Destroying cluster '%s'...", cfg.ClusterID)
-		err = OSD.DeleteCluster(cfg.ClusterID)
+		Progress.Update("Destroying cluster '%s'", cfg.ClusterID)
+		err = Provider.DeleteCluster(cfg.ClusterID)
 		Expect(err).NotTo(HaveOccurred(), "failed to destroy cluster")
 	}
 })
 
+// keepCluster leaves the cluster running, changing when OSD deletes it if the teardown policy has an expiry.
+func keepCluster(cfg *config.Config) {
+	log.Printf("TEARDOWN_POLICY is %s, skipping deleting cluster '%s'.", cfg.Teardown(), cfg.ClusterID)
+
+	// kept clusters are accessed later, which their kubeconfig only allows when it can be stored encrypted
+	if _, err := storeKubeconfig(cfg); err != nil {
+		log.Printf("Failed to store kubeconfig: %v", err)
+	}
+
+	expiry, err := cfg.KeptExpiry()
+	if err != nil {
+		log.Printf("Not changing expiry of cluster '%s': %v", cfg.ClusterID, err)
+		return
+	} else if expiry == 0 {
+		return
+	} else if OSD == nil {
+		log.Printf("Expiry of cluster '%s' can only be changed when using OSD, leaving it unchanged.", cfg.ClusterID)
+		return
+	}
+
+	expires := time.Now().Add(expiry)
+	if err = OSD.SetClusterExpiry(cfg.ClusterID, expires); err != nil {
+		log.Printf("Failed to change expiry of cluster '%s': %v", cfg.ClusterID, err)
+		return
+	}
+	log.Printf("Cluster '%s' will be deleted by OSD at %s.", cfg.ClusterID, expires.Format(time.RFC3339))
+}
+
+// startPhase ends the previous phase and limits the work done by helpers and OSD until the next to the timeout of p.
+// The phase is traced until the next starts.
+func startPhase(cfg *config.Config, p config.Phase) {
+	endPhase()
+
+	ctx, cancel := context.Background(), context.CancelFunc(func() {})
+	if timeout, err := cfg.PhaseTimeout(p); err != nil {
+		log.Printf("Not limiting the %s phase: %v", p, err)
+	} else if timeout != 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		go func() {
+			if <-ctx.Done(); ctx.Err() == context.DeadlineExceeded {
+				log.Printf("The %s phase exceeded its timeout of %v, stopping work in progress.", p, timeout)
+			}
+		}()
+	}
+
+	Verdict.Start(p)
+	Tracer.StartPhase(string(p))
+	timeline.Current.StartPhase(string(p), time.Now())
+	helper.SetPhaseContext(ctx)
+	if OSD != nil {
+		OSD.SetContext(ctx)
+	}
+	endPhase = cancel
+}
+
 // setupCluster brings up a cluster, waits for it to be ready, then returns it's name.
 func setupCluster(cfg *config.Config) (err error) {
 	// if TEST_KUBECONFIG has been set, skip configuring UHC
@@ -81,55 +403,653 @@ func setupCluster(cfg *config.Config) (err error) {
 			cfg.ClusterName = clusterName(cfg)
 		}
 
-		if cfg.ClusterID, err = OSD.LaunchCluster(cfg); err != nil {
+		if cfg.ClusterID, err = Provider.LaunchCluster(cfg); err != nil {
+			if class, evidence := osd.ClassifyInstallFailure(&osd.InstallFailure{ErrorMessage: err.Error()}); class == osd.ClassCapacity {
+				blockRegion(cfg, evidence)
+			}
 			return fmt.Errorf("could not launch cluster: %v", err)
 		}
+		Progress.Update("Provisioning cluster '%s'", cfg.ClusterID)
+		serviceLog(cfg, "osde2e created the cluster for testing", "Created to test %s in %s.", cfg.ClusterVersion, cfg.Region)
 	} else {
 		log.Printf("CLUSTER_ID of '%s' was provided, skipping cluster creation and using it instead", cfg.ClusterID)
 	}
 
-	if err = OSD.WaitForClusterReady(cfg.ClusterID, cfg.ClusterUpTimeout); err != nil {
-		return fmt.Errorf("failed waiting for cluster ready: %v", err)
+	// clusters used by later phases may not be healthy, such as when only tearing down
+	if cfg.RunPhase(config.PhaseInstall) {
+		if err = Provider.WaitForClusterReady(cfg.ClusterID, cfg.ClusterUpTimeout, cfg.InstallHeartbeat); err != nil {
+			if OSD != nil {
+				if f := collectInstallForensics(cfg); f != nil {
+					if f.Class == osd.ClassCapacity {
+						blockRegion(cfg, f.Evidence)
+					}
+					return fmt.Errorf("failed waiting for cluster ready: %v: %v", err, f)
+				}
+			}
+			return fmt.Errorf("failed waiting for cluster ready: %v", err)
+		}
+		Progress.Update("Cluster '%s' is provisioned and healthy", cfg.ClusterID)
 	}
 
-	if cfg.Kubeconfig, err = OSD.ClusterKubeconfig(cfg.ClusterID); err != nil {
+	if cfg.Kubeconfig, err = Provider.ClusterKubeconfig(cfg.ClusterID); err != nil {
 		return fmt.Errorf("could not get kubeconfig for cluster: %v", err)
 	}
 	return nil
 }
 
+// upgradeCluster upgrades the cluster with the selected workload profiles running, verifying they survive it.
+func upgradeCluster(cfg *config.Config) error {
+	h := &helper.H{
+		Config: cfg,
+	}
+	h.SetupClients()
+	failed := workloads.Deploy(h, cfg.WorkloadProfiles)
+
+	// OCM configuration of the cluster should be unchanged by upgrading
+	var before *osd.ClusterSnapshot
+	if OSD != nil {
+		var snapErr error
+		if before, snapErr = OSD.ClusterSnapshot(cfg.ClusterID); snapErr != nil {
+			log.Printf("Not checking OCM configuration survives upgrade: %v", snapErr)
+		}
+	}
+
+	hops, err := upgrade.RunUpgrade(cfg)
+	for _, hop := range hops {
+		if hop.Err == nil {
+			upgradedVersion = hop.Version
+		}
+		conditions.Current.Exclude(hop.Started, hop.Started.Add(hop.Duration))
+		Tracer.Record(hop.Name(), hop.Started, hop.Started.Add(hop.Duration), nil)
+		entry := timeline.Entry{Source: timeline.SourceUpgrade, Name: hop.Name(), Start: hop.Started,
+			End: hop.Started.Add(hop.Duration), Failed: hop.Err != nil}
+		if hop.Err != nil {
+			entry.Detail = hop.Err.Error()
+		}
+		timeline.Current.Add(entry)
+		recordHealth(hop.Health)
+		serviceLog(cfg, "osde2e upgraded the cluster", "Upgraded to %s%s.", hop.Image, outcome(hop.Err))
+
+		// record why the upgrade failed so it's reported as an upgrade failure
+		if f, ok := hop.Failure(); ok {
+			UpgradeFailure = f
+			if writeErr := f.Write(cfg.ReportDir); writeErr != nil {
+				log.Printf("Failed to record upgrade failure: %v", writeErr)
+			}
+		}
+	}
+
+	if before != nil {
+		if drift, err := checkSnapshot(cfg, before); err != nil {
+			log.Printf("Failed to check OCM configuration survived upgrade: %v", err)
+		} else if err = osd.WriteDriftJUnit(cfg.ReportDir, cfg.Suffix, "upgrade", drift); err != nil {
+			log.Printf("Failed to record OCM configuration drift: %v", err)
+		}
+	}
+
+	if len(cfg.WorkloadProfiles) != 0 {
+		results := workloads.Verify(h, cfg.WorkloadProfiles, failed)
+		if err := workloads.WriteJUnit(cfg.ReportDir, cfg.Suffix, results); err != nil {
+			log.Printf("Failed to record workload results: %v", err)
+		}
+	}
+	return err
+}
+
+// checkSnapshot returns how the OCM configuration of the cluster drifted from before, logging each change.
+func checkSnapshot(cfg *config.Config, before *osd.ClusterSnapshot) ([]string, error) {
+	after, err := OSD.ClusterSnapshot(cfg.ClusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	drift := before.Drift(after)
+	for _, d := range drift {
+		log.Printf("OCM configuration of cluster '%s' drifted: %s", cfg.ClusterID, d)
+	}
+	return drift, nil
+}
+
+// analyzeNodeLogs runs log metrics and built-in patterns over the journal of each node, recording findings in JUnit.
+func analyzeNodeLogs(cfg *config.Config) error {
+	engine, err := logmetrics.Load(cfg.LogMetricsFile)
+	if err != nil {
+		return err
+	}
+	if err = engine.Add(nodelogs.Patterns...); err != nil {
+		return err
+	}
+
+	h := &helper.H{
+		Config: cfg,
+	}
+	h.Setup()
+	defer h.Cleanup()
+
+	logs, err := nodelogs.Collect(h, setupStarted)
+	if err != nil {
+		return err
+	}
+	for node, data := range logs {
+		if err = artifacts.Current.Write(artifacts.Logs, "node-"+node+"-journal.txt", data); err != nil {
+			log.Printf("Failed to store journal of node '%s': %v", node, err)
+		}
+	}
+
+	findings := nodelogs.Analyze(engine, logs)
+	for _, f := range findings {
+		if !f.Passed() {
+			log.Printf("Node '%s' has %d lines matching %s.", f.Node, f.Count, f.Metric.Name)
+		}
+	}
+
+	var metrics bytes.Buffer
+	if err = nodelogs.WriteMetrics(&metrics, findings); err != nil {
+		log.Printf("Failed to export node log metrics: %v", err)
+	} else if err = artifacts.Current.Write(artifacts.Logs, "node-log-metrics.prom", metrics.Bytes()); err != nil {
+		log.Printf("Failed to store node log metrics: %v", err)
+	}
+	return nodelogs.WriteJUnit(cfg.ReportDir, cfg.Suffix, findings)
+}
+
+// scanImages scans the images run in managed namespaces for vulnerabilities, recording components with critical ones
+// in JUnit.
+func scanImages(cfg *config.Config) error {
+	scanner, err := imagescan.New(cfg.ImageScanner, cfg.ClairURL)
+	if err != nil {
+		return err
+	}
+
+	var namespaces []*regexp.Regexp
+	for _, expr := range cfg.ImageScanNamespaces {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return fmt.Errorf("invalid image scan namespace '%s': %v", expr, err)
+		}
+		namespaces = append(namespaces, re)
+	}
+
+	h := &helper.H{
+		Config: cfg,
+	}
+	h.SetupClients()
+
+	pods, err := h.Kube().CoreV1().Pods(metav1.NamespaceAll).List(metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("couldn't list pods: %v", err)
+	}
+
+	images := imagescan.Images(pods.Items, namespaces)
+	log.Printf("Scanning images of %d components with %s...", len(images), cfg.ImageScanner)
+	components := imagescan.Scan(scanner, images)
+	for _, c := range components {
+		if critical := c.Critical(); len(critical) != 0 {
+			log.Printf("Images of '%s' have %d critical vulnerabilities.", c.Name, len(critical))
+		}
+	}
+
+	var metrics bytes.Buffer
+	if err = imagescan.WriteMetrics(&metrics, components); err != nil {
+		log.Printf("Failed to export image vulnerability metrics: %v", err)
+	} else if err = artifacts.Current.Write(artifacts.Logs, "image-vulnerabilities.prom", metrics.Bytes()); err != nil {
+		log.Printf("Failed to store image vulnerability metrics: %v", err)
+	}
+	return imagescan.WriteJUnit(cfg.ReportDir, cfg.Suffix, components)
+}
+
+// checkSpec records where the cluster disagrees with what OCM expects it to be at checkpoint. Only clusters in OCM are
+// checked.
+func checkSpec(cfg *config.Config, checkpoint string) {
+	if !cfg.SpecChecks || OSD == nil || cfg.ClusterID == "" || len(cfg.Kubeconfig) == 0 {
+		return
+	}
+
+	findings, err := compareSpec(cfg, checkpoint)
+	if err != nil {
+		log.Printf("Failed to compare cluster to OCM after %s: %v", checkpoint, err)
+	}
+	for _, f := range findings {
+		log.Printf("After %s, %v.", checkpoint, f)
+	}
+	SpecDrift.Add(checkpoint, findings, err)
+}
+
+// compareSpec returns the properties of the cluster which aren't what OCM expects at checkpoint.
+func compareSpec(cfg *config.Config, checkpoint string) ([]clusterspec.Finding, error) {
+	spec, err := OSD.ClusterSpec(cfg.ClusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	// OCM learns of upgrades made directly through the ClusterVersion late, so the cluster is expected to be the
+	// version it was upgraded to. The version isn't compared after upgrades which didn't complete a hop.
+	if checkpoint == "upgrade" || upgradedVersion != "" {
+		spec.Version = upgradedVersion
+	}
+
+	h := &helper.H{
+		Config: cfg,
+	}
+	h.SetupClients()
+
+	f, err := fingerprint.Collect(h)
+	if err != nil {
+		return nil, err
+	}
+	csvs, err := h.Dynamic().Resource(olm.CSVGVR).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("couldn't list ClusterServiceVersions: %v", err)
+	}
+	return clusterspec.Compare(spec, f, clusterspec.CSVPhases(csvs.Items)), nil
+}
+
+// writeFingerprint stores what the cluster is made of in the report directory, including its add-ons when using OSD.
+func writeFingerprint(cfg *config.Config) error {
+	h := &helper.H{
+		Config: cfg,
+	}
+	h.SetupClients()
+
+	f, err := fingerprint.Collect(h)
+	if err != nil {
+		return err
+	}
+	if OSD != nil && cfg.ClusterID != "" {
+		if snapshot, err := OSD.ClusterSnapshot(cfg.ClusterID); err != nil {
+			log.Printf("Not fingerprinting add-ons of cluster '%s': %v", cfg.ClusterID, err)
+		} else {
+			f.SetAddons(snapshot)
+		}
+	}
+	return f.Write(cfg.ReportDir)
+}
+
+// guardVersion watches for the cluster's version changing from the version about to be tested.
+func guardVersion(cfg *config.Config) error {
+	get, err := drift.ClusterVersion(cfg.Kubeconfig)
+	if err != nil {
+		return err
+	}
+	version, err := get()
+	if err != nil {
+		return err
+	}
+
+	drift.Current = drift.NewGuard(version, cfg.AbortOnVersionDrift)
+	drift.Current.Start(get, cfg.VersionDriftInterval)
+	log.Printf("Checking cluster version stays %s every %v while testing.", version, cfg.VersionDriftInterval)
+	return nil
+}
+
+// reportUsage measures the CPU and memory consumed by the workloads of each suite, logging the suites consuming the
+// most and storing the usage of all of them.
+func reportUsage(cfg *config.Config) error {
+	h := &helper.H{
+		Config: cfg,
+	}
+	h.SetupClients()
+
+	usages, err := usage.Current.Measure(h.QueryPrometheus)
+	if err != nil {
+		return err
+	}
+	SuiteUsage = usages
+
+	cpu, memory := usage.Top(usages, topUsageSuites)
+	if len(cpu) != 0 {
+		log.Println("Suites consuming the most CPU:")
+		for _, u := range cpu {
+			log.Printf("  %s", u)
+		}
+		log.Println("Suites consuming the most memory:")
+		for _, u := range memory {
+			log.Printf("  %s", u)
+		}
+	}
+	return usage.Write(cfg.ReportDir, usages)
+}
+
+// reportTimeline adds the warning events and alerts of the cluster since setup started to the timeline, then writes
+// it.
+func reportTimeline(cfg *config.Config) error {
+	now := time.Now()
+	if len(cfg.Kubeconfig) != 0 {
+		h := &helper.H{
+			Config: cfg,
+		}
+		h.SetupClients()
+
+		events, err := h.Kube().CoreV1().Events(metav1.NamespaceAll).List(metav1.ListOptions{
+			FieldSelector: fields.OneTermEqualSelector("type", kubev1.EventTypeWarning).String(),
+		})
+		if err != nil {
+			log.Printf("Failed to list events for timeline: %v", err)
+		} else {
+			timeline.Current.Add(timeline.Events(events.Items, setupStarted, now)...)
+		}
+
+		window := fmt.Sprintf("%ds", int(now.Sub(setupStarted).Seconds())+1)
+		if samples, err := h.QueryPrometheus(fmt.Sprintf(timeline.AlertsQuery, window), now); err != nil {
+			log.Printf("Failed to query alerts for timeline: %v", err)
+		} else {
+			timeline.Current.Add(timeline.Alerts(samples, setupStarted, now)...)
+		}
+	}
+	return timeline.Current.Write(cfg.ReportDir, fmt.Sprintf("osde2e run '%s'", cfg.Suffix), now)
+}
+
+// reportSynthetics stops the prober and records gaps in availability along with the events they overlap in JUnit.
+func reportSynthetics(cfg *config.Config) error {
+	results, err := prober.Stop()
+	prober = nil
+	if err != nil {
+		log.Printf("Failed to remove synthetic probes: %v", err)
+	}
+
+	gaps := synthetics.Gaps(results, cfg.SyntheticsInterval)
+	for _, g := range gaps {
+		log.Printf("Synthetic %s probe was unavailable for %v from %s: %s", g.Check, g.Duration(), g.Start.Format(time.RFC3339), g.Reason)
+		Tracer.Record(fmt.Sprintf("%s unavailable", g.Check), g.Start, g.End, map[string]string{"reason": g.Reason})
+		timeline.Current.Add(timeline.Entry{Source: timeline.SourceProbe, Name: fmt.Sprintf("%s unavailable", g.Check),
+			Start: g.Start, End: g.End, Detail: g.Reason, Failed: true})
+	}
+
+	// failed specs and upgrades may explain gaps
+	var events []synthetics.Event
+	for _, e := range timeline.Current.Entries(time.Now()) {
+		if e.Source == timeline.SourceUpgrade || (e.Source == timeline.SourceSpec && e.Failed) {
+			if e.Source == timeline.SourceSpec {
+				e.Name = "failed test '" + e.Name + "'"
+			}
+			events = append(events, synthetics.Event{Name: e.Name, Start: e.Start, End: e.End})
+		}
+	}
+	return synthetics.WriteJUnit(cfg.ReportDir, cfg.Suffix, gaps, events)
+}
+
+// recordHealth places the checks of r which changed since they were last checked on the timeline.
+func recordHealth(r *health.Report) {
+	if r == nil {
+		return
+	}
+	for _, res := range r.Results {
+		timeline.Current.Health(res.Name, res.Healthy, res.Problems, r.Checked)
+	}
+}
+
+// pauseOnFailure waits for input before teardown if anything has failed, printing how to access the cluster.
+func pauseOnFailure(cfg *config.Config) {
+	failures := Failures.Failures()
+	if len(failures) == 0 {
+		return
+	}
+
+	info := debug.Info{
+		ClusterID:   cfg.ClusterID,
+		ClusterName: cfg.ClusterName,
+		Version:     cfg.ClusterVersion,
+	}
+	path, err := storeKubeconfig(cfg)
+	if err != nil {
+		log.Printf("Failed to store kubeconfig for debugging: %v", err)
+	}
+	info.Kubeconfig = path
+
+	Progress.Update("Paused before teardown to inspect %d failures", len(failures))
+	debug.Pause(os.Stdout, os.Stdin, info, failures, cfg.InteractiveTimeout)
+}
+
+// storeKubeconfig stores the kubeconfig of the cluster as an artifact, returning its path. It's only stored when
+// credentials are encrypted, as artifacts are uploaded, and only once.
+func storeKubeconfig(cfg *config.Config) (string, error) {
+	if len(cfg.Kubeconfig) == 0 || artifacts.Current == nil || !artifacts.Current.Encrypts(artifacts.Credentials) {
+		return "", nil
+	}
+
+	stored := func() string {
+		for _, rec := range artifacts.Current.Records() {
+			if rec.Category == artifacts.Credentials && rec.Name == "kubeconfig" && rec.File != "" {
+				return filepath.Join(artifacts.Current.Dir, rec.File)
+			}
+		}
+		return ""
+	}
+	if path := stored(); path != "" {
+		return path, nil
+	}
+	if err := artifacts.Current.Write(artifacts.Credentials, "kubeconfig", cfg.Kubeconfig); err != nil {
+		return "", fmt.Errorf("couldn't store kubeconfig of cluster '%s': %v", cfg.ClusterID, err)
+	}
+	return stored(), nil
+}
+
+// recordTopology sets Topology to the layout of the cluster's nodes.
+func recordTopology(cfg *config.Config) error {
+	h := &helper.H{
+		Config: cfg,
+	}
+	h.Setup()
+	defer h.Cleanup()
+
+	topo, err := topology.Get(h.Kube())
+	if err != nil {
+		return err
+	}
+	Topology = &topo
+	log.Printf("Cluster is running in '%s' across zones %v.", topo.Region, topo.Zones)
+	return nil
+}
+
+// addMachinePools creates the machine pools specified in cfg which the cluster doesn't have yet, then waits for their
+// labels and taints to propagate to their nodes.
+func addMachinePools(cfg *config.Config) error {
+	preset, err := machinepool.Load(cfg.MachinePools)
+	if err != nil {
+		return err
+	} else if OSD == nil {
+		return fmt.Errorf("machine pools can only be added to clusters when using OSD")
+	}
+
+	snapshot, err := OSD.ClusterSnapshot(cfg.ClusterID)
+	if err != nil {
+		return err
+	}
+	for _, pool := range preset.Pools {
+		if _, ok := snapshot.MachinePools[pool.ID]; ok {
+			log.Printf("Cluster '%s' already has machine pool '%s', not creating it.", cfg.ClusterID, pool.ID)
+			continue
+		}
+		if err = OSD.CreateMachinePool(cfg.ClusterID, pool); err != nil {
+			return err
+		}
+	}
+
+	h := &helper.H{
+		Config: cfg,
+	}
+	h.SetupClients()
+	return machinepool.Wait(h, preset, machinePoolTimeout)
+}
+
+// installAddonBundle installs the add-ons of the bundle specified in cfg in the order they depend on each other.
+func installAddonBundle(cfg *config.Config) error {
+	bundle, err := addonbundle.Load(cfg.AddonBundle)
+	if err != nil {
+		return err
+	} else if OSD == nil {
+		return fmt.Errorf("add-ons can only be installed on clusters when using OSD")
+	}
+	return addonbundle.Install(OSD, cfg.ClusterID, bundle, addonTimeout)
+}
+
+// applyProfile configures the cluster using the profile specified in cfg.
+func applyProfile(cfg *config.Config) error {
+	profile, err := configurator.LoadProfile(cfg.ConfigProfile)
+	if err != nil {
+		return err
+	}
+
+	h := &helper.H{
+		Config: cfg,
+	}
+	h.Setup()
+	defer h.Cleanup()
+
+	return configurator.Apply(h, profile, profileTimeout)
+}
+
+// pinOperators installs the operator versions specified in cfg.
+func pinOperators(cfg *config.Config) error {
+	pins, err := olm.ParsePins(cfg.OperatorVersions)
+	if err != nil {
+		return fmt.Errorf("invalid operator versions: %v", err)
+	}
+
+	h := &helper.H{
+		Config: cfg,
+	}
+	h.Setup()
+	defer h.Cleanup()
+
+	for _, pin := range pins {
+		if err = olm.PinOperator(h, pin, operatorPinTimeout); err != nil {
+			return fmt.Errorf("couldn't pin operator '%s': %v", pin, err)
+		}
+	}
+	return nil
+}
+
+// collectInstallForensics stores why the cluster failed to install according to OSD with the artifacts of the run.
+// The install phase may have timed out, so requests to OSD are given their own time.
+func collectInstallForensics(cfg *config.Config) *osd.InstallFailure {
+	ctx, cancel := context.WithTimeout(context.Background(), forensicsTimeout)
+	defer cancel()
+	previous := OSD.SetContext(ctx)
+	defer OSD.SetContext(previous)
+
+	log.Printf("Collecting why cluster '%s' failed to install...", cfg.ClusterID)
+	f, err := OSD.InstallForensics(cfg.ClusterID)
+	if err != nil {
+		log.Printf("Failed to collect why cluster '%s' failed to install: %v", cfg.ClusterID, err)
+		return nil
+	}
+	log.Printf("Install failure classified as %s: %s", f.Class, f.Evidence)
+
+	if artifacts.Current != nil {
+		for id, data := range f.Logs {
+			if err = artifacts.Current.Write(artifacts.Logs, "install-failure-"+id+"-log.txt", data); err != nil {
+				log.Printf("Failed to store %s log of cluster '%s': %v", id, cfg.ClusterID, err)
+			}
+		}
+	}
+	if err = f.Write(cfg.ReportDir); err != nil {
+		log.Printf("Failed to record install failure: %v", err)
+	}
+	return f
+}
+
+// serviceLog writes what the run did to the cluster to its service log in OSD, with the description formatted from format
+// and args, so SREs coming across the cluster can see what was done to it and by which run.
+func serviceLog(cfg *config.Config, summary, format string, args ...interface{}) {
+	if OSD == nil || !cfg.ServiceLogs || cfg.ClusterID == "" {
+		return
+	}
+
+	description := fmt.Sprintf(format, args...) + fmt.Sprintf(" Done by osde2e run '%s'", cfg.Suffix)
+	if cfg.JobID != "" {
+		description += fmt.Sprintf(" of job '%s'", cfg.JobID)
+	}
+	if err := OSD.WriteServiceLog(cfg.ClusterID, summary, description+"."); err != nil {
+		log.Printf("Failed to write service log of cluster '%s': %v", cfg.ClusterID, err)
+	}
+}
+
+// outcome describes err at the end of a service log entry, which is empty when there's no error.
+func outcome(err error) string {
+	if err != nil {
+		return fmt.Sprintf(", which failed: %v", err)
+	}
+	return ""
+}
+
+// rotateRegion creates the cluster in the region of list after the one its cloud provider used last, skipping blocked
+// regions. If every region is blocked, the one unblocked soonest is used.
+func rotateRegion(cfg *config.Config, list []string) {
+	var region string
+	var chooseErr error
+	err := regions.Update(cfg.RegionBlocklist, func(state *regions.State) {
+		region, chooseErr = state.Choose(cfg.CloudProvider, list, time.Now())
+	})
+	if err != nil {
+		log.Printf("Failed to record region used in '%s': %v", cfg.RegionBlocklist, err)
+		if region == "" {
+			region, chooseErr = regions.Read(cfg.RegionBlocklist).Choose(cfg.CloudProvider, list, time.Now())
+		}
+	}
+	if chooseErr != nil {
+		log.Printf("Using region '%s' anyway: %v", region, chooseErr)
+	}
+	log.Printf("Rotating %s clusters to region '%s'", cfg.CloudProvider, region)
+	cfg.Region = region
+}
+
+// blockRegion skips the cluster's region in later runs because it ran out of capacity, when its cloud provider rotates
+// through regions.
+func blockRegion(cfg *config.Config, reason string) {
+	if _, ok := cfg.RegionRotation[cfg.CloudProvider]; !ok {
+		return
+	}
+
+	err := regions.Update(cfg.RegionBlocklist, func(state *regions.State) {
+		state.Block(cfg.CloudProvider, cfg.Region, reason, time.Now(), cfg.RegionBlockDuration)
+	})
+	if err != nil {
+		log.Printf("Failed to block region '%s' in '%s': %v", cfg.Region, cfg.RegionBlocklist, err)
+		return
+	}
+	log.Printf("Blocked region '%s' of %s for %s because it ran out of capacity", cfg.Region, cfg.CloudProvider,
+		cfg.RegionBlockDuration)
+}
+
+// joinSharedCluster makes the run a tenant of the cluster, waiting for room if other runs are testing it.
+func joinSharedCluster(cfg *config.Config) error {
+	h := &helper.H{
+		Config: cfg,
+	}
+	h.SetupClients()
+
+	quota, err := tenant.ParseQuota(cfg.SharedClusterQuota)
+	if err != nil {
+		return err
+	}
+	t := tenant.New(h.Kube(), seed.String(seed.Tenants, tenant.NameLength), cfg.SharedClusterTenants, quota)
+	if err = t.Join(cfg.SharedClusterWait); err != nil {
+		return err
+	}
+	tenant.Current = t
+	log.Printf("Joined shared cluster as tenant '%s', namespaces are prefixed with '%s'.", t.Name, t.Prefix())
+	return nil
+}
+
 // useKubeconfig reads the path provided for a TEST_KUBECONFIG and uses it for testing.
 func useKubeconfig(cfg *config.Config) (err error) {
 	filename := string(cfg.Kubeconfig)
-	cfg.Kubeconfig, err = ioutil.ReadFile(filename)
-	if err != nil {
-		return fmt.Errorf("failed reading '%s' which has been set as the TEST_KUBECONFIG: %v", filename, err)
+	if err = cfg.ReadKubeconfig(); err != nil {
+		return err
 	}
 	log.Printf("Using a set TEST_KUBECONFIG of '%s' for Origin API calls.", filename)
 	return nil
 }
 
-// cluster name format must be short enough to support all versions
+// clusterName names the cluster after its version and the run, keeping it short enough to be a DNS label.
 func clusterName(cfg *config.Config) string {
-	vers := strings.TrimPrefix(cfg.ClusterVersion, osd.VersionPrefix)
-	safeVersion := strings.Replace(vers, ".", "-", -1)
-	return "ci-cluster-" + safeVersion + "-" + cfg.Suffix
-}
-
-func randomStr(length int) (str string) {
-	chars := "0123456789abcdefghijklmnopqrstuvwxyz"
-	for i := 0; i < length; i++ {
-		c := string(chars[rand.Intn(len(chars))])
-		str += c
-	}
-	return
+	return naming.ClusterName(strings.TrimPrefix(cfg.ClusterVersion, osd.VersionPrefix), cfg.Suffix)
 }
 
-func writeLogs(cfg *config.Config, m map[string][]byte) {
+func writeLogs(m map[string][]byte) {
 	for k, v := range m {
 		name := k + "-log.txt"
-		filePath := filepath.Join(cfg.ReportDir, name)
-		err := ioutil.WriteFile(filePath, v, os.ModePerm)
-		Expect(err).NotTo(HaveOccurred(), "failed to write log '%s'", filePath)
+		err := artifacts.Current.Write(artifacts.Logs, name, v)
+		Expect(err).NotTo(HaveOccurred(), "failed to write log '%s'", name)
 	}
 }
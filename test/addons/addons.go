@@ -0,0 +1,143 @@
+// Package addons tests bundles of interdependent add-ons installed together, checking they share SSO and federate
+// monitoring before running each add-on's harness with the context of the whole bundle.
+package addons
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	kubev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/openshift/osde2e/pkg/addonbundle"
+	"github.com/openshift/osde2e/pkg/config"
+	"github.com/openshift/osde2e/pkg/groups"
+	"github.com/openshift/osde2e/pkg/harness"
+	"github.com/openshift/osde2e/pkg/helper"
+	"github.com/openshift/osde2e/pkg/osd"
+	"github.com/openshift/osde2e/pkg/skips"
+)
+
+// promTargetsURL is queried in the Prometheus of the bundle for its targets.
+const promTargetsURL = "http://localhost:9090/api/v1/targets"
+
+var (
+	oauths = schema.GroupVersionResource{
+		Group:    "config.openshift.io",
+		Version:  "v1",
+		Resource: "oauths",
+	}
+
+	oauthClients = schema.GroupVersionResource{
+		Group:    "oauth.openshift.io",
+		Version:  "v1",
+		Resource: "oauthclients",
+	}
+)
+
+var _ = groups.Describe(groups.Operators, "Add-on Bundle", func() {
+	h := helper.New()
+	ginkgo.BeforeEach(h.SkipLocal)
+	ginkgo.BeforeEach(h.SkipShared)
+
+	var bundle *addonbundle.Bundle
+	ginkgo.BeforeEach(func() {
+		if h.AddonBundle == "" {
+			skips.Skip(skips.ConfigExcluded, "ADDON_BUNDLE is not set")
+		}
+		h.SkipFedRAMP("add-on bundles")
+
+		var err error
+		bundle, err = addonbundle.Load(h.AddonBundle)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	ginkgo.It("should have every add-on installed and ready", func() {
+		if h.Provider != config.ProviderOSD || h.ProviderPlugin != "" || h.ClusterID == "" {
+			skips.Skip(skips.CapabilityMissing, "cluster isn't managed by OSD")
+		}
+		client, err := osd.NewForConfig(h.Config)
+		Expect(err).NotTo(HaveOccurred(), "couldn't connect to OSD")
+
+		snapshot, err := client.ClusterSnapshot(h.ClusterID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(addonbundle.InstallProblems(bundle, snapshot)).To(BeEmpty(), "add-ons of bundle '%s' aren't ready", bundle.Name)
+	})
+
+	ginkgo.It("should sign users in to add-ons through shared SSO", func() {
+		if bundle.SSO == nil {
+			skips.Skip(skips.ConfigExcluded, "add-on bundle doesn't share SSO")
+		}
+
+		oauth, err := h.GetResource(oauths, "", "cluster")
+		Expect(err).NotTo(HaveOccurred())
+		idps, _, err := unstructured.NestedSlice(oauth.Object, "spec", "identityProviders")
+		Expect(err).NotTo(HaveOccurred(), "couldn't read identity providers")
+
+		var providers []string
+		for _, idp := range idps {
+			if idp, ok := idp.(map[string]interface{}); ok {
+				name, _, _ := unstructured.NestedString(idp, "name")
+				providers = append(providers, name)
+			}
+		}
+
+		list, err := h.Dynamic().Resource(oauthClients).List(metav1.ListOptions{})
+		Expect(err).NotTo(HaveOccurred(), "couldn't list OAuth clients")
+		var clients []string
+		for _, c := range list.Items {
+			clients = append(clients, c.GetName())
+		}
+
+		Expect(addonbundle.SSOProblems(bundle, providers, clients)).To(BeEmpty(), "add-ons of bundle '%s' don't share SSO", bundle.Name)
+	})
+
+	ginkgo.It("should federate the metrics of add-ons", func() {
+		if bundle.Monitoring == nil {
+			skips.Skip(skips.ConfigExcluded, "add-on bundle doesn't federate monitoring")
+		}
+
+		m := bundle.Monitoring
+		result, err := h.Exec(m.Namespace, m.Pod, m.Container, "curl", "-s", promTargetsURL)
+		Expect(err).NotTo(HaveOccurred(), "couldn't get targets from Prometheus '%s/%s'", m.Namespace, m.Pod)
+
+		targets, err := addonbundle.ParseTargets(result.Stdout)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(addonbundle.FederationProblems(bundle, targets)).To(BeEmpty(), "metrics of bundle '%s' aren't federated", bundle.Name)
+	})
+
+	ginkgo.It("should pass the harness of every add-on", func() {
+		var failed []string
+		for _, a := range bundle.Addons {
+			if a.Harness == "" {
+				continue
+			}
+
+			// harnesses share the project and are told about every add-on of the bundle
+			r := h.Runner(a.HarnessCmd)
+			r.Name = a.ID
+			r.ImageName = a.Harness
+			for i := range r.PodSpec.Containers {
+				r.PodSpec.Containers[i].Env = append(r.PodSpec.Containers[i].Env, kubev1.EnvVar{
+					Name:  harness.AddonsEnv,
+					Value: bundle.HarnessEnv(),
+				})
+			}
+
+			if err := r.Run(helper.PhaseContext().Done()); err != nil {
+				log.Printf("Harness of add-on '%s' failed: %v", a.ID, err)
+				failed = append(failed, fmt.Sprintf("%s: %v", a.ID, err))
+			}
+
+			results, err := r.RetrieveResults()
+			Expect(err).NotTo(HaveOccurred(), "couldn't get results of add-on '%s'", a.ID)
+			h.WriteResults(results)
+		}
+		Expect(failed).To(BeEmpty(), "harnesses of bundle '%s' failed", bundle.Name)
+	})
+})
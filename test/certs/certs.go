@@ -0,0 +1,241 @@
+// Package certs tests the certificate management of clusters beyond the secrets certman creates: the API server serves
+// a valid certificate, certificates managed by operators are rotated before they expire, serving certificates are
+// reissued when removed, and service account tokens expire and are revoked as requested.
+package certs
+
+import (
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	kubev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/openshift/osde2e/pkg/certs"
+	"github.com/openshift/osde2e/pkg/groups"
+	"github.com/openshift/osde2e/pkg/helper"
+	"github.com/openshift/osde2e/pkg/skips"
+)
+
+const (
+	// annotations asking the service CA operator for serving certificates and its CA bundle
+	servingCertAnnotation = "service.alpha.openshift.io/serving-cert-secret-name"
+	injectCABundle        = "service.alpha.openshift.io/inject-cabundle"
+	caBundleKey           = "service-ca.crt"
+	serviceCANamespace    = "openshift-service-ca"
+
+	// managedPrefix begins the namespaces of operators whose certificates are checked.
+	managedPrefix = "openshift-"
+
+	// tokenLifetime is how long requested tokens are valid for, the shortest the API server allows.
+	tokenLifetime = 10 * time.Minute
+
+	dialTimeout   = 30 * time.Second
+	pollInterval  = 5 * time.Second
+	issueTimeout  = 5 * time.Minute
+	revokeTimeout = time.Minute
+)
+
+var _ = groups.Describe(groups.Security, "Certificate Rotation", func() {
+	h := helper.New()
+	ginkgo.BeforeEach(h.SkipLocal)
+
+	ginkgo.It("should serve the API with a valid certificate which isn't about to expire", func() {
+		restConfig, err := clientcmd.RESTConfigFromKubeConfig(h.Kubeconfig)
+		Expect(err).NotTo(HaveOccurred(), "failed loading kubeconfig")
+		apiURL, err := url.Parse(restConfig.Host)
+		Expect(err).NotTo(HaveOccurred(), "failed parsing API server address")
+		addr := apiURL.Host
+		if apiURL.Port() == "" {
+			addr = net.JoinHostPort(addr, "443")
+		}
+
+		served, err := certs.Served(addr, dialTimeout)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(served).NotTo(BeEmpty(), "API server served no certificates")
+
+		leaf := served[0]
+		Expect(leaf.VerifyHostname(apiURL.Hostname())).To(Succeed(), "API certificate isn't for %s", apiURL.Hostname())
+		problems := certs.FromCert(leaf).Problems(time.Now(), h.CertMinRemaining, h.CertMaxLifetimeUsed)
+		Expect(problems).To(BeEmpty(), "API certificate issued by '%s' isn't fine", leaf.Issuer.CommonName)
+	})
+
+	ginkgo.It("should rotate certificates managed by operators before they expire", func() {
+		secrets, err := h.Kube().CoreV1().Secrets(metav1.NamespaceAll).List(metav1.ListOptions{})
+		Expect(err).NotTo(HaveOccurred(), "failed listing secrets")
+
+		var checked int
+		var problems []string
+		now := time.Now()
+		for _, s := range secrets.Items {
+			if !strings.HasPrefix(s.Namespace, managedPrefix) {
+				continue
+			}
+			validity, ok, err := certs.FromAnnotations(s.Annotations)
+			if !ok {
+				continue
+			} else if err != nil {
+				problems = append(problems, fmt.Sprintf("%s/%s: %v", s.Namespace, s.Name, err))
+				continue
+			}
+			checked++
+			for _, p := range validity.Problems(now, 0, h.CertMaxLifetimeUsed) {
+				problems = append(problems, fmt.Sprintf("%s/%s: %s", s.Namespace, s.Name, p))
+			}
+		}
+		if checked == 0 {
+			skips.Skip(skips.CapabilityMissing, "no certificates are managed by operators")
+		}
+		Expect(problems).To(BeEmpty(), "%d of %d certificates managed by operators aren't rotated", len(problems), checked)
+	})
+
+	ginkgo.It("should reissue service serving certificates when they're removed", func() {
+		if _, err := h.Kube().CoreV1().Namespaces().Get(serviceCANamespace, metav1.GetOptions{}); errors.IsNotFound(err) {
+			skips.Skip(skips.CapabilityMissing, "service CA isn't installed")
+		}
+
+		ns := h.CurrentProject()
+		svc, err := h.Kube().CoreV1().Services(ns).Create(&kubev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "serving-cert",
+				Annotations: map[string]string{servingCertAnnotation: "serving-cert-tls"},
+			},
+			Spec: kubev1.ServiceSpec{
+				Ports: []kubev1.ServicePort{{Port: 443}},
+			},
+		})
+		Expect(err).NotTo(HaveOccurred(), "failed creating service")
+		bundle, err := h.Kube().CoreV1().ConfigMaps(ns).Create(&kubev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "service-ca",
+				Annotations: map[string]string{injectCABundle: "true"},
+			},
+		})
+		Expect(err).NotTo(HaveOccurred(), "failed creating CA bundle")
+
+		var roots []*x509.Certificate
+		err = h.Poll(pollInterval, issueTimeout, func() (bool, error) {
+			if bundle, err = h.Kube().CoreV1().ConfigMaps(ns).Get(bundle.Name, metav1.GetOptions{}); err != nil {
+				return false, err
+			} else if data := bundle.Data[caBundleKey]; data != "" {
+				roots, err = certs.ParsePEM([]byte(data))
+				return err == nil, err
+			}
+			return false, nil
+		})
+		Expect(err).NotTo(HaveOccurred(), "failed getting service CA bundle")
+
+		secretName := svc.Annotations[servingCertAnnotation]
+		issued := servingCert(h, secretName, nil)
+		checkServingCert(issued, svc, roots)
+
+		Expect(h.Kube().CoreV1().Secrets(ns).Delete(secretName, &metav1.DeleteOptions{})).To(Succeed(),
+			"failed removing serving certificate")
+		reissued := servingCert(h, secretName, issued)
+		checkServingCert(reissued, svc, roots)
+	})
+
+	ginkgo.It("should expire and revoke service account tokens as requested", func() {
+		ns := h.CurrentProject()
+		sa, err := h.Kube().CoreV1().ServiceAccounts(ns).Create(&kubev1.ServiceAccount{
+			ObjectMeta: metav1.ObjectMeta{Name: "token-rotation"},
+		})
+		Expect(err).NotTo(HaveOccurred(), "failed creating service account")
+		binding, err := h.Kube().CoreV1().Secrets(ns).Create(&kubev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "token-binding"},
+		})
+		Expect(err).NotTo(HaveOccurred(), "failed creating secret to bind token to")
+
+		seconds := int64(tokenLifetime / time.Second)
+		requested := time.Now()
+		tr, err := h.Kube().CoreV1().ServiceAccounts(ns).CreateToken(sa.Name, &authenticationv1.TokenRequest{
+			Spec: authenticationv1.TokenRequestSpec{
+				ExpirationSeconds: &seconds,
+				BoundObjectRef: &authenticationv1.BoundObjectReference{
+					Kind:       "Secret",
+					APIVersion: "v1",
+					Name:       binding.Name,
+					UID:        binding.UID,
+				},
+			},
+		})
+		if errors.IsNotFound(err) || errors.IsMethodNotSupported(err) {
+			skips.Skip(skips.CapabilityMissing, "token requests aren't served")
+		}
+		Expect(err).NotTo(HaveOccurred(), "failed requesting token")
+
+		// tokens expire when requested, with some leeway for clock skew
+		exp, err := certs.TokenExpiry(tr.Status.Token)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(exp).To(BeTemporally("~", tr.Status.ExpirationTimestamp.Time, time.Second), "token expires at another time than reported")
+		Expect(exp).To(BeTemporally("~", requested.Add(tokenLifetime), time.Minute), "token doesn't expire when requested")
+
+		username := fmt.Sprintf("system:serviceaccount:%s:%s", ns, sa.Name)
+		Expect(authenticated(h, tr.Status.Token)).To(Equal(username), "token wasn't authenticated")
+
+		// tokens can't be extended without being signed again
+		extended, err := certs.WithExpiry(tr.Status.Token, exp.Add(365*24*time.Hour))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(authenticated(h, extended)).To(BeEmpty(), "token with a changed expiry was authenticated")
+
+		Expect(h.Kube().CoreV1().Secrets(ns).Delete(binding.Name, &metav1.DeleteOptions{})).To(Succeed())
+		err = h.Poll(pollInterval, revokeTimeout, func() (bool, error) {
+			return authenticated(h, tr.Status.Token) == "", nil
+		})
+		Expect(err).NotTo(HaveOccurred(), "token was still authenticated after the object it's bound to was removed")
+	})
+})
+
+// servingCert waits for the service CA to issue the serving certificate stored in secretName, returning it once it's
+// been issued again if it replaces previous.
+func servingCert(h *helper.H, secretName string, previous []*x509.Certificate) (chain []*x509.Certificate) {
+	err := h.Poll(pollInterval, issueTimeout, func() (bool, error) {
+		secret, err := h.Kube().CoreV1().Secrets(h.CurrentProject()).Get(secretName, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			return false, nil
+		} else if err != nil {
+			return false, err
+		}
+		if chain, err = certs.ParsePEM(secret.Data[kubev1.TLSCertKey]); err != nil {
+			return false, err
+		}
+		return previous == nil || chain[0].SerialNumber.Cmp(previous[0].SerialNumber) != 0, nil
+	})
+	Expect(err).NotTo(HaveOccurred(), "serving certificate '%s' wasn't issued", secretName)
+	return chain
+}
+
+// checkServingCert expects chain to serve svc and be trusted by roots.
+func checkServingCert(chain []*x509.Certificate, svc *kubev1.Service, roots []*x509.Certificate) {
+	leaf := chain[0]
+	name := svc.Name + "." + svc.Namespace + ".svc"
+	Expect(leaf.VerifyHostname(name)).To(Succeed(), "serving certificate isn't for %s", name)
+	_, err := leaf.Verify(x509.VerifyOptions{
+		DNSName:       name,
+		Roots:         certs.Pool(roots),
+		Intermediates: certs.Pool(chain[1:]),
+	})
+	Expect(err).NotTo(HaveOccurred(), "serving certificate isn't trusted by the service CA")
+	Expect(certs.FromCert(leaf).Problems(time.Now(), 0, 0)).To(BeEmpty(), "serving certificate isn't valid")
+}
+
+// authenticated returns who token authenticates as, or an empty string if it isn't authenticated.
+func authenticated(h *helper.H, token string) string {
+	review, err := h.Kube().AuthenticationV1().TokenReviews().Create(&authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{Token: token},
+	})
+	Expect(err).NotTo(HaveOccurred(), "failed reviewing token")
+	if !review.Status.Authenticated {
+		return ""
+	}
+	return review.Status.User.Username
+}
@@ -0,0 +1,98 @@
+// Package cloud verifies the infrastructure of clusters through the APIs of the cloud they run on, catching drift that
+// isn't visible from Kubernetes such as leaked instances, unhealthy load balancers, or loosened firewall rules.
+package cloud
+
+import (
+	"path/filepath"
+
+	"github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/openshift/osde2e/pkg/groups"
+	"github.com/openshift/osde2e/pkg/helper"
+	"github.com/openshift/osde2e/pkg/infra"
+	"github.com/openshift/osde2e/pkg/skips"
+	"github.com/openshift/osde2e/pkg/topology"
+)
+
+const (
+	// routerNamespace and routerService expose the default router through a cloud load balancer.
+	routerNamespace = "openshift-ingress"
+	routerService   = "router-default"
+)
+
+var infrastructures = schema.GroupVersionResource{
+	Group:    "config.openshift.io",
+	Version:  "v1",
+	Resource: "infrastructures",
+}
+
+var _ = groups.Describe(groups.Other, "Cloud Infrastructure", func() {
+	h := helper.New()
+	ginkgo.BeforeEach(h.SkipLocal)
+
+	var (
+		inv      *infra.Inventory
+		baseline *infra.Baseline
+		infraID  string
+	)
+	ginkgo.BeforeEach(func() {
+		if !h.CloudVerification {
+			skips.Skip(skips.ConfigExcluded, "CLOUD_VERIFICATION is not set")
+		}
+
+		obj, err := h.GetResource(infrastructures, "", "cluster")
+		Expect(err).NotTo(HaveOccurred())
+		infraID, _, _ = unstructured.NestedString(obj.Object, "status", "infrastructureName")
+		Expect(infraID).NotTo(BeEmpty(), "cluster has no infrastructure name")
+
+		topo, err := topology.Get(h.Kube())
+		Expect(err).NotTo(HaveOccurred())
+
+		var provider infra.Provider
+		switch topo.Cloud {
+		case "aws":
+			provider, err = infra.NewAWS(topo.Region)
+		case "gce":
+			if h.GCPProject == "" || len(h.GCPServiceAccount) == 0 {
+				skips.Skip(skips.ConfigExcluded, "GCP_PROJECT and GCP_SERVICE_ACCOUNT are needed to verify GCP clusters")
+			}
+			provider, err = infra.NewGCP(h.GCPProject, h.GCPServiceAccount)
+			topo.Cloud = "gcp"
+		default:
+			skips.Skip(skips.CapabilityMissing, "infrastructure on '"+topo.Cloud+"' can't be verified")
+		}
+		Expect(err).NotTo(HaveOccurred(), "couldn't connect to %s", topo.Cloud)
+
+		baseline, err = infra.LoadBaseline(filepath.Join(h.CloudBaselineDir, topo.Cloud+".yaml"))
+		Expect(err).NotTo(HaveOccurred())
+
+		svc, err := h.Kube().CoreV1().Services(routerNamespace).Get(routerService, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred(), "couldn't get router service")
+		var lbHosts []string
+		for _, ingress := range svc.Status.LoadBalancer.Ingress {
+			lbHosts = append(lbHosts, ingress.Hostname, ingress.IP)
+		}
+
+		inv, err = provider.Inventory(infraID, lbHosts)
+		Expect(err).NotTo(HaveOccurred(), "couldn't read infrastructure of '%s' from %s", infraID, topo.Cloud)
+	})
+
+	ginkgo.It("should run an instance for every node in each availability zone", func() {
+		nodes, err := h.Kube().CoreV1().Nodes().List(metav1.ListOptions{})
+		Expect(err).NotTo(HaveOccurred(), "couldn't list nodes")
+		Expect(infra.InstanceProblems(inv, nodes.Items)).To(BeEmpty(), "instances don't match nodes")
+	})
+
+	ginkgo.It("should have tagged and healthy load balancers", func() {
+		Expect(infra.LoadBalancerProblems(inv, baseline, infraID)).To(BeEmpty(), "load balancers don't match the baseline")
+	})
+
+	ginkgo.It("should have firewall rules matching the baseline", func() {
+		Expect(infra.RuleProblems(inv, baseline)).To(BeEmpty(), "firewall rules don't match the baseline")
+	})
+})
@@ -0,0 +1,295 @@
+// Package console performs synthetic checks of the web console using a headless browser.
+package console
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	kubev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openshift/osde2e/pkg/helper"
+	"github.com/openshift/osde2e/pkg/skips"
+)
+
+const (
+	// BrowserImage is the Docker image used to run a headless browser driven by puppeteer.
+	BrowserImage = "docker.io/zenika/alpine-chrome:with-puppeteer"
+
+	consoleNamespace = "openshift-console"
+	consoleLabel     = "console"
+
+	// sessionCookie holds the token the console authenticates requests with.
+	sessionCookie = "openshift-session-token"
+
+	// tokenEnv holds the token of the ServiceAccount checks are authenticated as.
+	tokenEnv = "CONSOLE_TOKEN"
+
+	// tokenSecret stores the token so it isn't part of the pod spec.
+	tokenSecret = "console-check-token"
+
+	// directory browser results are written to
+	outputDir  = "/tmp/console-results"
+	volumeName = "console-results"
+
+	// file listing the outcome of each check
+	checksFile = "checks.txt"
+)
+
+// Check is a page of the console that is expected to render.
+type Check struct {
+	// Name identifies the check and is used for artifact names.
+	Name string
+
+	// Path is requested from the console.
+	Path string
+
+	// Expect is a regular expression that must match the text of the rendered page.
+	Expect string
+
+	// Authenticated checks are made logged in to the console, and fail if redirected to log in. Other checks must be
+	// redirected to the OAuth login page.
+	Authenticated bool
+}
+
+// DefaultChecks are performed against the console.
+var DefaultChecks = []Check{
+	{
+		Name:   "login",
+		Path:   "/",
+		Expect: "log ?in",
+	},
+	{
+		Name:          "dashboard",
+		Path:          "/dashboards",
+		Expect:        "cluster utilization|inventory",
+		Authenticated: true,
+	},
+	{
+		Name:          "operators",
+		Path:          "/k8s/cluster/config.openshift.io~v1~ClusterOperator",
+		Expect:        "kube-apiserver",
+		Authenticated: true,
+	},
+}
+
+// errorPattern matches pages that indicate the console is not being served.
+const errorPattern = "application is not available|503 service unavailable|502 bad gateway"
+
+// checkScript renders each page, recording the outcome and a screenshot, the page, and a HAR of its requests for
+// failures. Cookies and credentials are left out of the HAR.
+const checkScript = `
+const fs = require('fs');
+const puppeteer = require('puppeteer');
+
+const consoleURL = {{json .URL}};
+const checks = {{json .Checks}};
+const errorPattern = new RegExp({{json .ErrorPattern}}, 'i');
+const hidden = ['authorization', 'cookie', 'set-cookie'];
+
+const headers = h => Object.keys(h).filter(k => !hidden.includes(k.toLowerCase())).map(k => ({name: k, value: h[k]}));
+
+const entry = (req, started) => {
+	const res = req.response();
+	return {
+		startedDateTime: started.toISOString(),
+		time: Date.now() - started.getTime(),
+		request: {method: req.method(), url: req.url(), httpVersion: 'HTTP/1.1', headers: headers(req.headers()),
+			queryString: [], cookies: [], headersSize: -1, bodySize: -1},
+		response: {status: res ? res.status() : 0, statusText: res ? res.statusText() : (req.failure() || {}).errorText,
+			httpVersion: 'HTTP/1.1', headers: res ? headers(res.headers()) : [], cookies: [],
+			content: {size: -1, mimeType: res ? res.headers()['content-type'] || '' : ''}, redirectURL: '',
+			headersSize: -1, bodySize: -1},
+		cache: {},
+		timings: {send: 0, wait: Date.now() - started.getTime(), receive: 0},
+	};
+};
+
+(async () => {
+	process.chdir({{json .OutputDir}});
+	const browser = await puppeteer.launch({executablePath: '/usr/bin/chromium-browser',
+		args: ['--no-sandbox', '--disable-gpu'], ignoreHTTPSErrors: true});
+
+	for (const check of checks) {
+		const page = await browser.newPage();
+		await page.setViewport({width: 1280, height: 1024});
+		if (check.Authenticated) {
+			await page.setCookie({name: {{json .SessionCookie}}, value: process.env[{{json .TokenEnv}}], url: consoleURL,
+				secure: true});
+		}
+
+		const started = new Map(), entries = [];
+		page.on('request', req => started.set(req, new Date()));
+		const finished = req => entries.push(entry(req, started.get(req) || new Date()));
+		page.on('requestfinished', finished);
+		page.on('requestfailed', finished);
+
+		let reason = '';
+		try {
+			await page.goto(consoleURL + check.Path, {waitUntil: 'networkidle0', timeout: 60000});
+			await page.waitForFunction(expect => new RegExp(expect, 'i').test(document.body.innerText),
+				{timeout: 30000}, check.Expect);
+
+			const onConsole = new URL(page.url()).host == new URL(consoleURL).host;
+			const text = await page.evaluate(() => document.body.innerText);
+			if (check.Authenticated && !onConsole) {
+				reason = 'redirected to log in';
+			} else if (!check.Authenticated && onConsole) {
+				reason = 'not redirected to log in';
+			} else if (errorPattern.test(text)) {
+				reason = 'console is not being served';
+			}
+		} catch (err) {
+			reason = err.message;
+		}
+
+		if (reason == '') {
+			fs.appendFileSync({{json .ChecksFile}}, check.Name + ' pass\n');
+		} else {
+			console.log(check.Name + ' failed: ' + reason);
+			fs.appendFileSync({{json .ChecksFile}}, check.Name + ' fail\n');
+			await page.screenshot({path: check.Name + '.png'}).catch(err => console.log(err.message));
+			fs.writeFileSync(check.Name + '.html', await page.content().catch(err => err.message));
+			fs.writeFileSync(check.Name + '.har', JSON.stringify({log: {version: '1.2',
+				creator: {name: 'osde2e', version: '1'}, pages: [], entries: entries}}, null, 2));
+		}
+		await page.close();
+	}
+	await browser.close();
+})().catch(err => {
+	console.log(err);
+	process.exit(1);
+});
+`
+
+var scriptTmpl = template.Must(template.New("checkScript").Funcs(template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}).Parse(checkScript))
+
+var _ = ginkgo.Describe("Console", func() {
+	defer ginkgo.GinkgoRecover()
+	h := helper.New()
+
+	ginkgo.It("should render pages in a browser", func() {
+		if !h.ConsoleChecks {
+			skips.Skip(skips.ConfigExcluded, "CONSOLE_CHECKS is not set")
+		}
+
+		url := consoleURL(h)
+		script, err := checkCmd(url, DefaultChecks)
+		Expect(err).NotTo(HaveOccurred())
+
+		// browser runs as an init container writing to a volume served by the runner
+		r := h.Runner("ls " + outputDir)
+
+		// checks are logged in as the runner's ServiceAccount, which administers the cluster
+		token, err := h.ServiceAccountToken(h.CurrentProject(), "default")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = h.Kube().CoreV1().Secrets(h.CurrentProject()).Create(&kubev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: tokenSecret},
+			StringData: map[string]string{tokenEnv: token},
+		})
+		Expect(err).NotTo(HaveOccurred(), "failed creating secret for the console token")
+
+		r.Name = "console-check"
+		r.OutputDir = outputDir
+		r.PodSpec.Volumes = append(r.PodSpec.Volumes, kubev1.Volume{
+			Name: volumeName,
+			VolumeSource: kubev1.VolumeSource{
+				EmptyDir: &kubev1.EmptyDirVolumeSource{},
+			},
+		})
+		mount := kubev1.VolumeMount{
+			Name:      volumeName,
+			MountPath: outputDir,
+		}
+		r.PodSpec.InitContainers = append(r.PodSpec.InitContainers, kubev1.Container{
+			Name:    "browser",
+			Image:   BrowserImage,
+			Command: []string{"node", "-e", script},
+			Env: []kubev1.EnvVar{{
+				Name: tokenEnv,
+				ValueFrom: &kubev1.EnvVarSource{
+					SecretKeyRef: &kubev1.SecretKeySelector{
+						LocalObjectReference: kubev1.LocalObjectReference{Name: tokenSecret},
+						Key:                  tokenEnv,
+					},
+				},
+			}},
+			VolumeMounts: []kubev1.VolumeMount{mount},
+		})
+		for i := range r.PodSpec.Containers {
+			r.PodSpec.Containers[i].VolumeMounts = append(r.PodSpec.Containers[i].VolumeMounts, mount)
+		}
+
+		// run checks
+		err = r.Run(helper.PhaseContext().Done())
+		Expect(err).NotTo(HaveOccurred())
+
+		// get results
+		results, err := r.RetrieveResults()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(results).To(HaveKey(checksFile))
+
+		// only keep screenshots, pages, and HARs when checks fail
+		failed := failedChecks(results[checksFile])
+		if len(failed) != 0 {
+			h.WriteResults(results)
+		}
+		Expect(failed).To(BeEmpty(), "console pages failed to render: %v", failed)
+	})
+})
+
+// checkCmd returns a node script that performs checks against the console at url.
+func checkCmd(url string, checks []Check) (string, error) {
+	var buf bytes.Buffer
+	err := scriptTmpl.Execute(&buf, struct {
+		URL, OutputDir, ChecksFile, ErrorPattern string
+		SessionCookie, TokenEnv                  string
+		Checks                                   []Check
+	}{
+		URL:           url,
+		OutputDir:     outputDir,
+		ChecksFile:    checksFile,
+		ErrorPattern:  errorPattern,
+		SessionCookie: sessionCookie,
+		TokenEnv:      tokenEnv,
+		Checks:        checks,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed templating console checks: %v", err)
+	}
+	return buf.String(), nil
+}
+
+// failedChecks returns the names of checks not recorded as passing.
+func failedChecks(data []byte) (failed []string) {
+	for _, line := range strings.Split(string(data), "\n") {
+		if fields := strings.Fields(line); len(fields) == 2 && fields[1] != "pass" {
+			failed = append(failed, fields[0])
+		}
+	}
+	return
+}
+
+func consoleURL(h *helper.H) string {
+	labelSelector := fmt.Sprintf("app=%s", consoleLabel)
+	list, err := h.Route().RouteV1().Routes(consoleNamespace).List(metav1.ListOptions{
+		LabelSelector: labelSelector,
+	})
+	Expect(err).NotTo(HaveOccurred(), "failed requesting routes")
+	Expect(list.Items).NotTo(BeEmpty(), "no routes matching '%s' in namespace '%s'", labelSelector, consoleNamespace)
+
+	route := list.Items[0]
+	Expect(route.Status.Ingress).NotTo(BeEmpty(), "no ingresses have been setup for the route '%s/%s'", route.Namespace, route.Name)
+	return fmt.Sprintf("https://%s", route.Status.Ingress[0].Host)
+}
@@ -0,0 +1,239 @@
+// Package dns tests that pods resolve services and external names through cluster DNS from every node, quickly enough
+// under light load. The logs and configuration of CoreDNS are stored when a spec fails to diagnose it.
+package dns
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	kubev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openshift/osde2e/pkg/artifacts"
+	"github.com/openshift/osde2e/pkg/dns"
+	"github.com/openshift/osde2e/pkg/groups"
+	"github.com/openshift/osde2e/pkg/helper"
+	"github.com/openshift/osde2e/pkg/skips"
+)
+
+const (
+	// location of CoreDNS, which runs on every node
+	dnsNamespace = "openshift-dns"
+	dnsName      = "dns-default"
+	dnsContainer = "dns"
+	corefileKey  = "Corefile"
+
+	// clusterDomain ends the names of services.
+	clusterDomain = "svc.cluster.local"
+
+	clientImage = "registry.access.redhat.com/ubi8/ubi-minimal"
+	podTimeout  = 5 * time.Minute
+
+	// lookupLoops is how many loops make timed lookups at once.
+	lookupLoops = 4
+
+	// logLines is how many lines of each CoreDNS pod's log are stored when a spec fails.
+	logLines = 1000
+
+	// workerLabel marks nodes which run workloads.
+	workerLabel = "node-role.kubernetes.io/worker"
+)
+
+var _ = groups.Describe(groups.Networking, "DNS", func() {
+	h := helper.New()
+
+	// runs before the spec's project is removed
+	ginkgo.JustAfterEach(func() {
+		if ginkgo.CurrentGinkgoTestDescription().Failed {
+			storeDiagnostics(h)
+		}
+	})
+
+	ginkgo.It("should resolve services by their short and fully qualified names", func() {
+		svc := createTarget(h)
+		client := createClient(h, "dns-client", "")
+
+		ns := h.CurrentProject()
+		for _, name := range []string{
+			svc.Name,
+			svc.Name + "." + ns,
+			svc.Name + "." + ns + ".svc",
+			svc.Name + "." + ns + "." + clusterDomain,
+		} {
+			Expect(resolve(h, client, name)).To(Equal(svc.Spec.ClusterIP), "%s resolved to another address", name)
+		}
+
+		kubernetes, err := h.Kube().CoreV1().Services("default").Get("kubernetes", metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred(), "failed getting kubernetes service")
+		Expect(resolve(h, client, "kubernetes.default."+clusterDomain)).To(Equal(kubernetes.Spec.ClusterIP))
+	})
+
+	ginkgo.It("should resolve external names", func() {
+		if h.DNSExternalHost == "" {
+			skips.Skip(skips.ConfigExcluded, "DNS_EXTERNAL_HOST is not set")
+		}
+
+		svc := createService(h, &kubev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "dns-external"},
+			Spec: kubev1.ServiceSpec{
+				Type:         kubev1.ServiceTypeExternalName,
+				ExternalName: h.DNSExternalHost,
+			},
+		})
+		client := createClient(h, "dns-client", "")
+
+		Expect(resolve(h, client, h.DNSExternalHost)).NotTo(BeEmpty(), "%s didn't resolve", h.DNSExternalHost)
+		Expect(resolve(h, client, svc.Name)).NotTo(BeEmpty(), "ExternalName service didn't resolve")
+	})
+
+	ginkgo.It("should configure pods to use the cluster DNS service", func() {
+		svc, err := h.Kube().CoreV1().Services(dnsNamespace).Get(dnsName, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred(), "failed getting cluster DNS service")
+		client := createClient(h, "dns-client", "")
+
+		result, err := h.Exec(client.Namespace, client.Name, client.Spec.Containers[0].Name, "cat", "/etc/resolv.conf")
+		Expect(err).NotTo(HaveOccurred(), "failed reading resolv.conf")
+		resolvConf := string(result.Stdout)
+		Expect(resolvConf).To(ContainSubstring("nameserver "+svc.Spec.ClusterIP), "pod doesn't use cluster DNS")
+		Expect(resolvConf).To(ContainSubstring(h.CurrentProject()+"."+clusterDomain), "pod doesn't search its namespace")
+	})
+
+	ginkgo.It("should resolve services from pods on every node", func() {
+		ds, err := h.Kube().AppsV1().DaemonSets(dnsNamespace).Get(dnsName, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred(), "failed getting CoreDNS")
+		Expect(ds.Status.DesiredNumberScheduled).To(BeNumerically(">", 0), "CoreDNS isn't scheduled to any nodes")
+		Expect(ds.Status.NumberReady).To(Equal(ds.Status.DesiredNumberScheduled), "CoreDNS isn't ready on every node")
+
+		svc := createTarget(h)
+
+		nodes, err := h.Kube().CoreV1().Nodes().List(metav1.ListOptions{LabelSelector: workerLabel})
+		Expect(err).NotTo(HaveOccurred(), "failed listing worker nodes")
+
+		var clients []*kubev1.Pod
+		for _, node := range nodes.Items {
+			if node.Spec.Unschedulable {
+				continue
+			}
+			clients = append(clients, createClient(h, "dns-client-"+node.Name, node.Name))
+		}
+		Expect(clients).NotTo(BeEmpty(), "no schedulable worker nodes")
+
+		var failures []string
+		for _, client := range clients {
+			if ip := resolve(h, client, svc.Name); ip != svc.Spec.ClusterIP {
+				failures = append(failures, fmt.Sprintf("%s resolved '%s'", client.Spec.NodeName, ip))
+			}
+		}
+		Expect(failures).To(BeEmpty(), "pods on some nodes couldn't resolve services")
+	})
+
+	ginkgo.It("should answer lookups within latency limits under light load", func() {
+		svc := createTarget(h)
+		client := createClient(h, "dns-client", "")
+
+		name := svc.Name + "." + h.CurrentProject() + "." + clusterDomain
+		script := dns.LookupScript(name, h.DNSLookups, lookupLoops)
+		result, err := h.Exec(client.Namespace, client.Name, client.Spec.Containers[0].Name, "sh", "-c", script)
+		Expect(err).NotTo(HaveOccurred(), "failed timing lookups: %s", result.Stderr)
+
+		lookups, err := dns.ParseLookups(result.Stdout)
+		Expect(err).NotTo(HaveOccurred(), "failed parsing lookups")
+		log.Printf("DNS made %d lookups, %d failed, latency p50 %v, p99 %v, max %v", lookups.Total(), lookups.Failed,
+			lookups.Percentile(50), lookups.Percentile(99), lookups.Percentile(100))
+
+		Expect(lookups.Failed).To(BeZero(), "%d of %d lookups failed", lookups.Failed, lookups.Total())
+		Expect(lookups.Percentile(99)).To(BeNumerically("<=", h.DNSMaxP99), "p99 latency of lookups is over DNS_MAX_P99")
+	})
+})
+
+// createTarget creates a service to resolve in the spec's project. It has no endpoints, but is assigned an address.
+func createTarget(h *helper.H) *kubev1.Service {
+	return createService(h, &kubev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "dns-target"},
+		Spec: kubev1.ServiceSpec{
+			Ports: []kubev1.ServicePort{{Port: 80}},
+		},
+	})
+}
+
+// createService creates svc in the spec's project.
+func createService(h *helper.H, svc *kubev1.Service) *kubev1.Service {
+	svc, err := h.Kube().CoreV1().Services(h.CurrentProject()).Create(svc)
+	Expect(err).NotTo(HaveOccurred(), "failed creating service")
+	return svc
+}
+
+// createClient runs a pod on node to make lookups from, waiting for it to be running. It's scheduled anywhere if node
+// is empty.
+func createClient(h *helper.H, name, node string) *kubev1.Pod {
+	pod, err := h.Kube().CoreV1().Pods(h.CurrentProject()).Create(&kubev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: kubev1.PodSpec{
+			NodeName: node,
+			Containers: []kubev1.Container{
+				{
+					Name:    "client",
+					Image:   clientImage,
+					Command: []string{"sleep", "infinity"},
+				},
+			},
+		},
+	})
+	Expect(err).NotTo(HaveOccurred(), "failed creating DNS client")
+
+	phase := h.WaitForPodPhase(pod, kubev1.PodRunning, int(podTimeout/(10*time.Second)), 10*time.Second)
+	Expect(phase).To(Equal(kubev1.PodRunning), "DNS client '%s' isn't running", name)
+	return pod
+}
+
+// resolve returns the first address name resolves to from client, or an empty string if it doesn't resolve.
+func resolve(h *helper.H, client *kubev1.Pod, name string) string {
+	result, err := h.Exec(client.Namespace, client.Name, client.Spec.Containers[0].Name, "getent", "hosts", name)
+	if err != nil {
+		log.Printf("Failed resolving '%s' from '%s': %v", name, client.Name, err)
+		return ""
+	}
+	fields := strings.Fields(string(result.Stdout))
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
+
+// storeDiagnostics stores the configuration of CoreDNS and the recent logs of its pods.
+func storeDiagnostics(h *helper.H) {
+	diagnostics := map[string][]byte{}
+	if cm, err := h.Kube().CoreV1().ConfigMaps(dnsNamespace).Get(dnsName, metav1.GetOptions{}); err != nil {
+		log.Printf("Failed getting CoreDNS configuration: %v", err)
+	} else {
+		diagnostics["dns-Corefile"] = []byte(cm.Data[corefileKey])
+	}
+
+	pods, err := h.Kube().CoreV1().Pods(dnsNamespace).List(metav1.ListOptions{})
+	if err != nil {
+		log.Printf("Failed listing CoreDNS pods: %v", err)
+	} else {
+		tail := int64(logLines)
+		for _, pod := range pods.Items {
+			data, err := h.Kube().CoreV1().Pods(dnsNamespace).GetLogs(pod.Name, &kubev1.PodLogOptions{
+				Container: dnsContainer,
+				TailLines: &tail,
+			}).DoRaw()
+			if err != nil {
+				log.Printf("Failed getting logs of CoreDNS pod '%s': %v", pod.Name, err)
+				continue
+			}
+			diagnostics["dns-"+pod.Name+".log"] = data
+		}
+	}
+
+	log.Printf("Storing CoreDNS configuration and logs to diagnose DNS.")
+	h.WriteArtifacts(artifacts.Logs, diagnostics)
+}
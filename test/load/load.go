@@ -0,0 +1,168 @@
+// Package load sends a light, steady load to the API server and a sample application route, recording latency
+// percentiles so gross control plane performance regressions are caught without running the scale tests.
+package load
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	vegeta "github.com/tsenart/vegeta/lib"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openshift/osde2e/pkg/groups"
+	"github.com/openshift/osde2e/pkg/helper"
+	"github.com/openshift/osde2e/pkg/metrics"
+	"github.com/openshift/osde2e/pkg/skips"
+	"github.com/openshift/osde2e/pkg/topology"
+	"github.com/openshift/osde2e/pkg/workloads"
+)
+
+const (
+	// sampleWorkload is the workload profile serving the sample application route.
+	sampleWorkload = "web"
+
+	// requestTimeout is how long each request may take before it's counted as failed.
+	requestTimeout = 30 * time.Second
+)
+
+// target is an endpoint sent load.
+type target struct {
+	name   string
+	url    string
+	client *http.Client
+}
+
+var _ = groups.Describe(groups.Networking, "Load", func() {
+	h := helper.New()
+
+	ginkgo.It("should serve API and route requests within latency limits", func() {
+		if !h.LoadTest {
+			skips.Skip(skips.ConfigExcluded, "LOAD_TEST is not set")
+		}
+
+		// the sample application is served through the router
+		err := workloads.Profiles[sampleWorkload].Deploy(h, h.CurrentProject())
+		Expect(err).NotTo(HaveOccurred(), "failed deploying sample application")
+
+		routes, err := h.Route().RouteV1().Routes(h.CurrentProject()).List(metav1.ListOptions{})
+		Expect(err).NotTo(HaveOccurred(), "failed getting route of sample application")
+		Expect(routes.Items).NotTo(BeEmpty(), "sample application has no route")
+
+		// failures of a route which isn't served yet would be counted against the router
+		routeURL := "http://" + routes.Items[0].Spec.Host
+		routeChecker := h.HTTPChecker()
+		Expect(routeChecker.Check(routeURL)).To(Succeed(), "sample application isn't served")
+
+		apiClient := h.HTTPClient()
+		apiClient.Timeout = requestTimeout
+		routeClient := routeChecker.Client()
+		routeClient.Timeout = requestTimeout
+		targets := []target{
+			{
+				name:   "api",
+				url:    h.REST().Get().AbsPath("/healthz").URL().String(),
+				client: apiClient,
+			},
+			{
+				name:   "route",
+				url:    routeURL,
+				client: routeClient,
+			},
+		}
+
+		snapshot := metrics.Snapshot{
+			Time:    time.Now().UTC(),
+			Metrics: map[string][]metrics.Sample{},
+		}
+
+		var failures []string
+		for _, t := range targets {
+			m := attack(h, t)
+			log.Printf("Load test of %s sent %d requests: %.1f%% succeeded, latency p50 %v, p95 %v, p99 %v, max %v",
+				t.name, m.Requests, m.Success*100, m.Latencies.P50, m.Latencies.P95, m.Latencies.P99, m.Latencies.Max)
+
+			for name, samples := range samples(t.name, m) {
+				snapshot.Metrics[name] = append(snapshot.Metrics[name], samples...)
+			}
+
+			if m.Latencies.P99 > h.LoadTestMaxP99 {
+				failures = append(failures, fmt.Sprintf("%s p99 latency of %v is over %v", t.name, m.Latencies.P99, h.LoadTestMaxP99))
+			}
+			if m.Success < h.LoadTestMinSuccess {
+				failures = append(failures, fmt.Sprintf("%s success ratio of %.3f is under %.3f: %v", t.name, m.Success,
+					h.LoadTestMinSuccess, m.Errors))
+			}
+		}
+
+		// label snapshot with where the cluster runs so changes can be attributed
+		topo, err := topology.Get(h.Kube())
+		Expect(err).NotTo(HaveOccurred(), "failed getting cluster topology")
+		snapshot.Labels = topo.Metadata()
+
+		data, err := json.MarshalIndent(snapshot, "", "  ")
+		Expect(err).NotTo(HaveOccurred())
+
+		h.WriteResults(map[string][]byte{
+			metrics.LoadSnapshotFile: data,
+		})
+		Expect(failures).To(BeEmpty(), "load test exceeded limits")
+	})
+})
+
+// attack sends requests to t at the configured rate until the load test duration passes or the spec is stopped.
+func attack(h *helper.H, t target) *vegeta.Metrics {
+	attacker := vegeta.NewAttacker(vegeta.Client(t.client))
+	targeter := vegeta.NewStaticTargeter(vegeta.Target{
+		Method: http.MethodGet,
+		URL:    t.url,
+	})
+	rate := vegeta.Rate{Freq: h.LoadTestRate, Per: time.Second}
+
+	m := new(vegeta.Metrics)
+	results, done := attacker.Attack(targeter, rate, h.LoadTestDuration, t.name), h.Context().Done()
+	for {
+		select {
+		case r, ok := <-results:
+			if !ok {
+				m.Close()
+				return m
+			}
+			m.Add(r)
+		case <-done:
+			attacker.Stop()
+			done = nil
+		}
+	}
+}
+
+// samples converts the results of attacking target into series of a snapshot.
+func samples(target string, m *vegeta.Metrics) map[string][]metrics.Sample {
+	labels := func(kv ...string) map[string]string {
+		l := map[string]string{"target": target}
+		for i := 0; i+1 < len(kv); i += 2 {
+			l[kv[i]] = kv[i+1]
+		}
+		return l
+	}
+
+	return map[string][]metrics.Sample{
+		"load_latency_seconds": {
+			{Labels: labels("quantile", "0.5"), Value: m.Latencies.P50.Seconds()},
+			{Labels: labels("quantile", "0.95"), Value: m.Latencies.P95.Seconds()},
+			{Labels: labels("quantile", "0.99"), Value: m.Latencies.P99.Seconds()},
+			{Labels: labels("quantile", "1"), Value: m.Latencies.Max.Seconds()},
+		},
+		"load_success_ratio": {
+			{Labels: labels(), Value: m.Success},
+		},
+		"load_requests_per_second": {
+			{Labels: labels(), Value: m.Throughput},
+		},
+	}
+}
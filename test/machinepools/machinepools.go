@@ -0,0 +1,131 @@
+// Package machinepools checks the labels and taints of machine pools added when clusters are provisioned reach their
+// nodes and decide where pods are scheduled.
+package machinepools
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	kubev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+
+	"github.com/openshift/osde2e/pkg/helper"
+	"github.com/openshift/osde2e/pkg/machinepool"
+	"github.com/openshift/osde2e/pkg/osd"
+	"github.com/openshift/osde2e/pkg/skips"
+)
+
+const (
+	// image used by Pods scheduled onto machine pools
+	podImage = "registry.access.redhat.com/ubi8/ubi-minimal"
+
+	podTimeout = 5 * time.Minute
+)
+
+var _ = ginkgo.Describe("Machine Pools", func() {
+	h := helper.New()
+	ginkgo.BeforeEach(h.SkipLocal)
+	ginkgo.BeforeEach(h.SkipShared)
+
+	var preset *machinepool.Preset
+	ginkgo.BeforeEach(func() {
+		if h.MachinePools == "" {
+			skips.Skip(skips.ConfigExcluded, "no machine pools were added to the cluster")
+		}
+
+		var err error
+		preset, err = machinepool.Load(h.MachinePools)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	ginkgo.It("should label and taint the nodes of each machine pool", func() {
+		nodes, err := h.Kube().CoreV1().Nodes().List(metav1.ListOptions{})
+		Expect(err).NotTo(HaveOccurred(), "couldn't list nodes")
+
+		var problems []string
+		for _, pool := range preset.Pools {
+			problems = append(problems, machinepool.Problems(pool, nodes.Items)...)
+		}
+		Expect(problems).To(BeEmpty(), "labels and taints of machine pools weren't propagated")
+	})
+
+	ginkgo.It("should only run pods tolerating the taints of machine pool nodes", func() {
+		nodes, err := h.Kube().CoreV1().Nodes().List(metav1.ListOptions{})
+		Expect(err).NotTo(HaveOccurred(), "couldn't list nodes")
+
+		var problems []string
+		for _, pool := range preset.Pools {
+			for _, node := range machinepool.Nodes(pool, nodes.Items) {
+				pods, err := h.Kube().CoreV1().Pods(metav1.NamespaceAll).List(metav1.ListOptions{
+					FieldSelector: fields.OneTermEqualSelector("spec.nodeName", node.Name).String(),
+				})
+				Expect(err).NotTo(HaveOccurred(), "couldn't list pods of node '%s'", node.Name)
+
+				for i := range pods.Items {
+					pod := &pods.Items[i]
+					if pod.Status.Phase != kubev1.PodRunning {
+						continue
+					}
+					for _, taint := range machinepool.Untolerated(pod, &node) {
+						problems = append(problems, fmt.Sprintf("pod '%s/%s' runs on node '%s' of machine pool '%s' without tolerating %s",
+							pod.Namespace, pod.Name, node.Name, pool.ID, taint.ToString()))
+					}
+				}
+			}
+		}
+		Expect(problems).To(BeEmpty(), "pods run on machine pools they don't tolerate")
+	})
+
+	ginkgo.It("should schedule pods selecting and tolerating a machine pool onto its nodes", func() {
+		for _, pool := range preset.Pools {
+			pod := createPod(h, pool)
+			Expect(pod.Spec.NodeName).NotTo(BeEmpty(), "pod for machine pool '%s' wasn't scheduled", pool.ID)
+
+			node, err := h.Kube().CoreV1().Nodes().Get(pod.Spec.NodeName, metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred(), "couldn't get node '%s'", pod.Spec.NodeName)
+			Expect(machinepool.Nodes(pool, []kubev1.Node{*node})).To(HaveLen(1),
+				"pod for machine pool '%s' was scheduled onto node '%s' outside of it", pool.ID, node.Name)
+		}
+	})
+})
+
+// createPod creates a Pod selecting the nodes of pool and tolerating its taints, then waits for it to be running.
+func createPod(h *helper.H, pool osd.MachinePool) *kubev1.Pod {
+	pod := &kubev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "machine-pool-" + pool.ID + "-",
+		},
+		Spec: kubev1.PodSpec{
+			NodeSelector: pool.Labels,
+			Containers: []kubev1.Container{
+				{
+					Name:    "sleep",
+					Image:   podImage,
+					Command: []string{"sleep", "infinity"},
+				},
+			},
+		},
+	}
+	for _, t := range pool.Taints {
+		pod.Spec.Tolerations = append(pod.Spec.Tolerations, kubev1.Toleration{
+			Key:      t.Key,
+			Operator: kubev1.TolerationOpEqual,
+			Value:    t.Value,
+			Effect:   kubev1.TaintEffect(t.Effect),
+		})
+	}
+
+	pod, err := h.Kube().CoreV1().Pods(h.CurrentProject()).Create(pod)
+	Expect(err).NotTo(HaveOccurred(), "couldn't create pod for machine pool '%s'", pool.ID)
+
+	phase := h.WaitForPodPhase(pod, kubev1.PodRunning, int(podTimeout/(10*time.Second)), 10*time.Second)
+	Expect(phase).To(Equal(kubev1.PodRunning), "pod for machine pool '%s' isn't running", pool.ID)
+
+	pod, err = h.Kube().CoreV1().Pods(pod.Namespace).Get(pod.Name, metav1.GetOptions{})
+	Expect(err).NotTo(HaveOccurred(), "couldn't get pod for machine pool '%s'", pool.ID)
+	return pod
+}
@@ -0,0 +1,87 @@
+package management
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/openshift/osde2e/pkg/groups"
+	"github.com/openshift/osde2e/pkg/helper"
+	"github.com/openshift/osde2e/pkg/osd"
+)
+
+// clusterAutoscalers are created by OSD from the autoscaler configuration.
+var clusterAutoscalers = schema.GroupVersionResource{
+	Group:    "autoscaling.openshift.io",
+	Version:  "v1",
+	Resource: "clusterautoscalers",
+}
+
+var _ = groups.Describe(groups.Operators, "Cluster autoscaler", func() {
+	h := helper.New()
+	ginkgo.BeforeEach(h.SkipLocal)
+	ginkgo.BeforeEach(h.SkipShared)
+
+	ginkgo.It("should be configured through OSD", func() {
+		client := osdClient(h)
+
+		previous, err := client.ClusterAutoscaler(h.ClusterID)
+		Expect(err).NotTo(HaveOccurred())
+		defer func() {
+			if previous == nil {
+				err = client.DeleteClusterAutoscaler(h.ClusterID)
+			} else {
+				err = client.SetClusterAutoscaler(h.ClusterID, previous)
+			}
+			if err != nil {
+				log.Printf("Failed to restore autoscaler: %v", err)
+			}
+		}()
+
+		autoscaler := &osd.Autoscaler{
+			BalanceSimilarNodeGroups: true,
+			ResourceLimits:           &osd.AutoscalerResourceLimits{MaxNodesTotal: 20},
+			ScaleDown:                &osd.AutoscalerScaleDown{Enabled: true, UnneededTime: "7m"},
+		}
+		err = client.SetClusterAutoscaler(h.ClusterID, autoscaler)
+		Expect(err).NotTo(HaveOccurred())
+
+		var mismatch error
+		err = h.PollImmediate(pollInterval, settingsTimeout, func() (bool, error) {
+			obj, err := h.GetResource(clusterAutoscalers, "", "default")
+			if err != nil {
+				mismatch = err
+				return false, nil
+			}
+			mismatch = checkAutoscaler(obj, autoscaler)
+			return mismatch == nil, nil
+		})
+		Expect(err).NotTo(HaveOccurred(), "autoscaler wasn't configured: %v", mismatch)
+	})
+})
+
+// checkAutoscaler returns an error if the ClusterAutoscaler obj doesn't match autoscaler.
+func checkAutoscaler(obj *unstructured.Unstructured, autoscaler *osd.Autoscaler) error {
+	balance, _, _ := unstructured.NestedBool(obj.Object, "spec", "balanceSimilarNodeGroups")
+	if balance != autoscaler.BalanceSimilarNodeGroups {
+		return fmt.Errorf("expected balanceSimilarNodeGroups %t, got %t", autoscaler.BalanceSimilarNodeGroups, balance)
+	}
+
+	maxNodes, _, _ := unstructured.NestedInt64(obj.Object, "spec", "resourceLimits", "maxNodesTotal")
+	if int(maxNodes) != autoscaler.ResourceLimits.MaxNodesTotal {
+		return fmt.Errorf("expected maxNodesTotal %d, got %d", autoscaler.ResourceLimits.MaxNodesTotal, maxNodes)
+	}
+
+	enabled, _, _ := unstructured.NestedBool(obj.Object, "spec", "scaleDown", "enabled")
+	unneeded, _, _ := unstructured.NestedString(obj.Object, "spec", "scaleDown", "unneededTime")
+	if enabled != autoscaler.ScaleDown.Enabled || unneeded != autoscaler.ScaleDown.UnneededTime {
+		return fmt.Errorf("expected scale down enabled=%t after %s, got enabled=%t after %s",
+			autoscaler.ScaleDown.Enabled, autoscaler.ScaleDown.UnneededTime, enabled, unneeded)
+	}
+	return nil
+}
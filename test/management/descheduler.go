@@ -0,0 +1,82 @@
+package management
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/openshift/osde2e/pkg/groups"
+	"github.com/openshift/osde2e/pkg/helper"
+	"github.com/openshift/osde2e/pkg/osd"
+)
+
+const (
+	// deschedulerNamespace is where the descheduler add-on creates its configuration.
+	deschedulerNamespace = "openshift-kube-descheduler-operator"
+
+	// intervalParam is the add-on parameter setting how often pods are descheduled.
+	intervalParam = "descheduling-interval-seconds"
+
+	// interval is set on the descheduler by the test. It's unlikely to be the default.
+	interval = 1800
+)
+
+// kubeDeschedulers are configured by the descheduler add-on.
+var kubeDeschedulers = schema.GroupVersionResource{
+	Group:    "operator.openshift.io",
+	Version:  "v1",
+	Resource: "kubedeschedulers",
+}
+
+var _ = groups.Describe(groups.Operators, "Descheduler", func() {
+	h := helper.New()
+	ginkgo.BeforeEach(h.SkipLocal)
+	ginkgo.BeforeEach(h.SkipShared)
+
+	ginkgo.It("should be configured through OSD", func() {
+		client := osdClient(h)
+
+		previous, err := client.Addon(h.ClusterID, osd.DeschedulerAddon)
+		Expect(err).NotTo(HaveOccurred())
+		defer func() {
+			if previous == nil {
+				err = client.DeleteAddon(h.ClusterID, osd.DeschedulerAddon)
+			} else {
+				err = client.ConfigureAddon(h.ClusterID, osd.DeschedulerAddon, previous.Params())
+			}
+			if err != nil {
+				log.Printf("Failed to restore descheduler: %v", err)
+			}
+		}()
+
+		params := map[string]string{intervalParam: strconv.Itoa(interval)}
+		err = client.ConfigureAddon(h.ClusterID, osd.DeschedulerAddon, params)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = client.WaitForAddon(h.ClusterID, osd.DeschedulerAddon, pollInterval, settingsTimeout)
+		Expect(err).NotTo(HaveOccurred(), "descheduler add-on wasn't installed")
+
+		var mismatch error
+		err = h.PollImmediate(pollInterval, settingsTimeout, func() (bool, error) {
+			obj, err := h.GetResource(kubeDeschedulers, deschedulerNamespace, "cluster")
+			if err != nil {
+				mismatch = err
+				return false, nil
+			}
+
+			actual, _, _ := unstructured.NestedInt64(obj.Object, "spec", "deschedulingIntervalSeconds")
+			if actual != interval {
+				mismatch = fmt.Errorf("expected deschedulingIntervalSeconds %d, got %d", interval, actual)
+				return false, nil
+			}
+			return true, nil
+		})
+		Expect(err).NotTo(HaveOccurred(), "descheduler wasn't configured: %v", mismatch)
+	})
+})
@@ -0,0 +1,96 @@
+package management
+
+import (
+	"log"
+	"time"
+
+	"github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	v1 "github.com/openshift-online/uhc-sdk-go/pkg/client/clustersmgmt/v1"
+
+	"github.com/openshift/osde2e/pkg/helper"
+	"github.com/openshift/osde2e/pkg/osd"
+	"github.com/openshift/osde2e/pkg/skips"
+)
+
+const (
+	// hibernateTimeout is how long the cluster has to stop its nodes once hibernated.
+	hibernateTimeout = 30 * time.Minute
+
+	// resumeTimeout is how long the cluster has to be ready again once resumed.
+	resumeTimeout = 45 * time.Minute
+
+	// markerProperty is set before hibernating to check properties added during the life of the cluster survive.
+	markerProperty = "osde2e-hibernation-check"
+)
+
+var _ = ginkgo.Describe("Hibernation", func() {
+	h := helper.New()
+	ginkgo.BeforeEach(h.SkipLocal)
+	ginkgo.BeforeEach(h.SkipShared)
+
+	ginkgo.It("should keep the OCM configuration of the cluster", func() {
+		if !h.HibernationChecks {
+			skips.Skip(skips.ConfigExcluded, "HIBERNATION_CHECKS is not set")
+		}
+		client := managedClient(h)
+
+		before, err := client.ClusterSnapshot(h.ClusterID)
+		Expect(err).NotTo(HaveOccurred(), "failed getting OCM configuration of cluster")
+
+		// mark the cluster, restoring its properties afterward
+		original := before.Properties
+		properties := map[string]string{markerProperty: time.Now().UTC().Format(time.RFC3339)}
+		for k, v := range original {
+			properties[k] = v
+		}
+		err = client.SetClusterProperties(h.ClusterID, properties)
+		Expect(err).NotTo(HaveOccurred(), "failed marking cluster")
+		defer func() {
+			if err := client.SetClusterProperties(h.ClusterID, original); err != nil {
+				log.Printf("Failed to restore properties of cluster '%s': %v", h.ClusterID, err)
+			}
+		}()
+		before.Properties = properties
+
+		// the cluster must always be resumed for later specs
+		err = client.HibernateCluster(h.ClusterID)
+		Expect(err).NotTo(HaveOccurred())
+		h.ServiceLog("osde2e hibernated the cluster", "Hibernated to check its OCM configuration is kept, then resumed.")
+		defer func() {
+			if err := resume(client, h.ClusterID); err != nil {
+				log.Printf("Failed to resume cluster '%s': %v", h.ClusterID, err)
+			}
+		}()
+
+		err = client.WaitForClusterState(h.ClusterID, osd.ClusterStateHibernating, pollInterval, hibernateTimeout)
+		Expect(err).NotTo(HaveOccurred(), "cluster didn't hibernate")
+
+		hibernated, err := client.ClusterSnapshot(h.ClusterID)
+		Expect(err).NotTo(HaveOccurred(), "failed getting OCM configuration of hibernated cluster")
+		Expect(before.Drift(hibernated)).To(BeEmpty(), "OCM configuration changed when hibernating")
+
+		err = resume(client, h.ClusterID)
+		Expect(err).NotTo(HaveOccurred(), "cluster didn't resume")
+
+		after, err := client.ClusterSnapshot(h.ClusterID)
+		Expect(err).NotTo(HaveOccurred(), "failed getting OCM configuration of resumed cluster")
+		Expect(before.Drift(after)).To(BeEmpty(), "OCM configuration changed when resuming")
+	})
+})
+
+// resume wakes clusterID if it's hibernating, waiting for it to be ready.
+func resume(client *osd.OSD, clusterID string) error {
+	state, err := client.ClusterState(clusterID)
+	if err != nil {
+		return err
+	} else if state == v1.ClusterStateReady {
+		return nil
+	} else if state != osd.ClusterStateResuming {
+		if err = client.ResumeCluster(clusterID); err != nil {
+			return err
+		}
+	}
+	return client.WaitForClusterState(clusterID, v1.ClusterStateReady, pollInterval, resumeTimeout)
+}
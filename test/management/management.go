@@ -0,0 +1,42 @@
+// Package management checks settings made through the OSD API, such as autoscaling and descheduling, take effect
+// on the cluster.
+package management
+
+import (
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/openshift/osde2e/pkg/config"
+	"github.com/openshift/osde2e/pkg/helper"
+	"github.com/openshift/osde2e/pkg/osd"
+	"github.com/openshift/osde2e/pkg/skips"
+)
+
+const (
+	// settingsTimeout is how long settings have to be applied to the cluster after they're made through OSD.
+	settingsTimeout = 15 * time.Minute
+
+	// pollInterval is how often the cluster is checked for settings.
+	pollInterval = 15 * time.Second
+)
+
+// osdClient connects to the OSD API managing the cluster, skipping the spec if management checks aren't enabled or
+// the cluster isn't managed by OSD.
+func osdClient(h *helper.H) *osd.OSD {
+	if !h.ManagementChecks {
+		skips.Skip(skips.ConfigExcluded, "MANAGEMENT_CHECKS is not set")
+	}
+	return managedClient(h)
+}
+
+// managedClient connects to the OSD API managing the cluster, skipping the spec if the cluster isn't managed by OSD.
+func managedClient(h *helper.H) *osd.OSD {
+	if h.Provider != config.ProviderOSD || h.ProviderPlugin != "" || h.ClusterID == "" {
+		skips.Skip(skips.CapabilityMissing, "cluster isn't managed by OSD")
+	}
+
+	client, err := osd.NewForConfig(h.Config)
+	Expect(err).NotTo(HaveOccurred(), "couldn't connect to OSD")
+	return client
+}
@@ -0,0 +1,68 @@
+// Package monitoring checks the managed monitoring stack is healthy and configured as OSD requires.
+package monitoring
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openshift/osde2e/pkg/groups"
+	"github.com/openshift/osde2e/pkg/helper"
+)
+
+const (
+	monitoringNamespace   = "openshift-monitoring"
+	userWorkloadNamespace = "openshift-user-workload-monitoring"
+
+	// location of Prometheus queried for its rules
+	promPod       = "prometheus-k8s-0"
+	promContainer = "prometheus"
+	promRulesURL  = "http://localhost:9090/api/v1/rules"
+)
+
+var (
+	// deployments make up the monitoring stack along with statefulSets and daemonSets.
+	deployments  = []string{"cluster-monitoring-operator", "prometheus-operator", "grafana", "kube-state-metrics", "openshift-state-metrics", "prometheus-adapter"}
+	statefulSets = []string{"prometheus-k8s", "alertmanager-main"}
+	daemonSets   = []string{"node-exporter"}
+)
+
+var _ = groups.Describe(groups.Operators, "Monitoring", func() {
+	h := helper.New()
+	ginkgo.BeforeEach(h.SkipLocal)
+
+	ginkgo.It("should have every component ready", func() {
+		var notReady []string
+		apps := h.Kube().AppsV1()
+
+		for _, name := range deployments {
+			d, err := apps.Deployments(monitoringNamespace).Get(name, metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred(), "couldn't get deployment '%s'", name)
+			if d.Spec.Replicas != nil && d.Status.AvailableReplicas < *d.Spec.Replicas {
+				notReady = append(notReady, fmt.Sprintf("deployment %s (%d/%d)", name, d.Status.AvailableReplicas, *d.Spec.Replicas))
+			}
+		}
+
+		for _, name := range statefulSets {
+			s, err := apps.StatefulSets(monitoringNamespace).Get(name, metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred(), "couldn't get statefulset '%s'", name)
+			if s.Spec.Replicas != nil && s.Status.ReadyReplicas < *s.Spec.Replicas {
+				notReady = append(notReady, fmt.Sprintf("statefulset %s (%d/%d)", name, s.Status.ReadyReplicas, *s.Spec.Replicas))
+			}
+		}
+
+		for _, name := range daemonSets {
+			d, err := apps.DaemonSets(monitoringNamespace).Get(name, metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred(), "couldn't get daemonset '%s'", name)
+			if d.Status.NumberReady < d.Status.DesiredNumberScheduled {
+				notReady = append(notReady, fmt.Sprintf("daemonset %s (%d/%d)", name, d.Status.NumberReady, d.Status.DesiredNumberScheduled))
+			}
+		}
+
+		Expect(notReady).To(BeEmpty(), "monitoring components not ready: %s", strings.Join(notReady, ", "))
+	})
+})
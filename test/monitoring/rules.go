@@ -0,0 +1,71 @@
+package monitoring
+
+import (
+	"encoding/json"
+
+	"github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/openshift/osde2e/pkg/groups"
+	"github.com/openshift/osde2e/pkg/helper"
+)
+
+// RequiredAlerts must be loaded by the platform Prometheus for OSD to be alerted of problems.
+var RequiredAlerts = []string{
+	"Watchdog",
+	"ClusterOperatorDown",
+	"ClusterOperatorDegraded",
+	"KubeAPIDown",
+	"KubeNodeNotReady",
+	"KubePodCrashLooping",
+	"TargetDown",
+	"etcdMembersDown",
+	"AlertmanagerDown",
+	"PrometheusDown",
+}
+
+// rulesResponse is the response of the Prometheus rules API.
+type rulesResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Data   struct {
+		Groups []struct {
+			Rules []struct {
+				Name string `json:"name"`
+				Type string `json:"type"`
+			} `json:"rules"`
+		} `json:"groups"`
+	} `json:"data"`
+}
+
+var _ = groups.Describe(groups.Operators, "Monitoring", func() {
+	h := helper.New()
+	ginkgo.BeforeEach(h.SkipLocal)
+
+	ginkgo.It("should have required alerts loaded", func() {
+		result, err := h.Exec(monitoringNamespace, promPod, promContainer, "curl", "-s", promRulesURL)
+		Expect(err).NotTo(HaveOccurred(), "couldn't get rules from Prometheus")
+
+		var resp rulesResponse
+		err = json.Unmarshal(result.Stdout, &resp)
+		Expect(err).NotTo(HaveOccurred(), "couldn't decode rules")
+		Expect(resp.Status).To(Equal("success"), "failed getting rules: %s", resp.Error)
+
+		loaded := map[string]bool{}
+		for _, group := range resp.Data.Groups {
+			for _, rule := range group.Rules {
+				if rule.Type == "alerting" {
+					loaded[rule.Name] = true
+				}
+			}
+		}
+
+		var missing []string
+		for _, alert := range RequiredAlerts {
+			if !loaded[alert] {
+				missing = append(missing, alert)
+			}
+		}
+		Expect(missing).To(BeEmpty(), "required alerts aren't loaded")
+	})
+})
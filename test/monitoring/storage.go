@@ -0,0 +1,41 @@
+package monitoring
+
+import (
+	"strconv"
+
+	"github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	kubev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openshift/osde2e/pkg/groups"
+	"github.com/openshift/osde2e/pkg/helper"
+)
+
+var _ = groups.Describe(groups.Operators, "Monitoring", func() {
+	h := helper.New()
+	ginkgo.BeforeEach(h.SkipLocal)
+
+	ginkgo.It("should store Prometheus data as configured", func() {
+		sts, err := h.Kube().AppsV1().StatefulSets(monitoringNamespace).Get("prometheus-k8s", metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred(), "couldn't get Prometheus statefulset")
+
+		if !h.PrometheusStorage {
+			Expect(sts.Spec.VolumeClaimTemplates).To(BeEmpty(), "Prometheus shouldn't have persistent storage")
+			return
+		}
+		Expect(sts.Spec.VolumeClaimTemplates).NotTo(BeEmpty(), "Prometheus should have persistent storage")
+
+		// every replica must have its claim bound
+		claims := h.Kube().CoreV1().PersistentVolumeClaims(monitoringNamespace)
+		for _, template := range sts.Spec.VolumeClaimTemplates {
+			for i := 0; sts.Spec.Replicas != nil && i < int(*sts.Spec.Replicas); i++ {
+				name := template.Name + "-" + sts.Name + "-" + strconv.Itoa(i)
+				pvc, err := claims.Get(name, metav1.GetOptions{})
+				Expect(err).NotTo(HaveOccurred(), "couldn't get claim '%s'", name)
+				Expect(pvc.Status.Phase).To(Equal(kubev1.ClaimBound), "claim '%s' isn't bound", name)
+			}
+		}
+	})
+})
@@ -0,0 +1,32 @@
+package monitoring
+
+import (
+	"github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openshift/osde2e/pkg/groups"
+	"github.com/openshift/osde2e/pkg/helper"
+)
+
+// userWorkloadPrometheus runs when user workload monitoring is enabled.
+const userWorkloadPrometheus = "prometheus-user-workload"
+
+var _ = groups.Describe(groups.Operators, "Monitoring", func() {
+	h := helper.New()
+	ginkgo.BeforeEach(h.SkipLocal)
+
+	ginkgo.It("should monitor user workloads as configured", func() {
+		sts, err := h.Kube().AppsV1().StatefulSets(userWorkloadNamespace).Get(userWorkloadPrometheus, metav1.GetOptions{})
+		if !h.UserWorkloadMonitoring {
+			Expect(kerror.IsNotFound(err)).To(BeTrue(), "user workload monitoring should be disabled")
+			return
+		}
+
+		Expect(err).NotTo(HaveOccurred(), "user workload monitoring should be enabled")
+		Expect(sts.Spec.Replicas).NotTo(BeNil())
+		Expect(sts.Status.ReadyReplicas).To(Equal(*sts.Spec.Replicas), "user workload Prometheus isn't ready")
+	})
+})
@@ -0,0 +1,283 @@
+// Package netperf measures network throughput and latency between pods on different nodes, through services, and
+// across availability zones, failing when they're worse than previous runs on the same cloud and instance type.
+package netperf
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	kubev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/openshift/osde2e/pkg/groups"
+	"github.com/openshift/osde2e/pkg/helper"
+	"github.com/openshift/osde2e/pkg/metrics"
+	"github.com/openshift/osde2e/pkg/netperf"
+	"github.com/openshift/osde2e/pkg/skips"
+	osdtestgrid "github.com/openshift/osde2e/pkg/testgrid"
+	"github.com/openshift/osde2e/pkg/topology"
+)
+
+const (
+	// iperfImage runs the iperf3 servers and clients.
+	iperfImage = "docker.io/networkstatic/iperf3"
+
+	iperfPort = 5201
+
+	// workerLabel marks nodes which run workloads.
+	workerLabel = "node-role.kubernetes.io/worker"
+
+	podTimeout = 5 * time.Minute
+)
+
+var _ = groups.Describe(groups.Networking, "Network Performance", func() {
+	h := helper.New()
+
+	ginkgo.It("should move traffic between pods, through services, and across zones as fast as previous runs", func() {
+		if !h.NetworkPerfTest {
+			skips.Skip(skips.ConfigExcluded, "NETWORK_PERF_TEST is not set")
+		}
+
+		nodes, err := h.Kube().CoreV1().Nodes().List(metav1.ListOptions{LabelSelector: workerLabel})
+		Expect(err).NotTo(HaveOccurred(), "couldn't list worker nodes")
+		workers := schedulable(nodes.Items)
+		if len(workers) < 2 {
+			skips.Skip(skips.CapabilityMissing, "network performance needs at least 2 schedulable worker nodes")
+		}
+		topo := topology.FromNodes(nodes.Items)
+
+		server, sameZone, otherZone := pickNodes(workers)
+		serverPod, svc := createServer(h, server)
+
+		var measurements []netperf.Measurement
+		measure := func(path string, client *kubev1.Node, host string) {
+			throughput, rtt := runClient(h, path, client, host)
+			log.Printf("Network performance of %s from '%s' to '%s': %.2f Gbit/s, mean RTT %v", path, client.Name,
+				server.Name, throughput/1e9, rtt)
+			measurements = append(measurements, netperf.Measurement{
+				Path:         path,
+				Cloud:        topo.Cloud,
+				InstanceType: instanceType(server, client),
+				Throughput:   throughput,
+				RTT:          rtt,
+			})
+		}
+
+		if sameZone != nil {
+			measure(netperf.PodToPod, sameZone, serverPod.Status.PodIP)
+			measure(netperf.PodToService, sameZone, svc.Spec.ClusterIP)
+		}
+		if otherZone != nil {
+			measure(netperf.CrossAZ, otherZone, serverPod.Status.PodIP)
+			if sameZone == nil {
+				measure(netperf.PodToService, otherZone, svc.Spec.ClusterIP)
+			}
+		} else {
+			log.Printf("Worker nodes run in a single availability zone, cross-AZ network performance wasn't measured.")
+		}
+
+		snapshot := &metrics.Snapshot{
+			Time:    time.Now().UTC(),
+			Metrics: netperf.Samples(measurements),
+			Labels:  topo.Metadata(),
+		}
+		data, err := json.MarshalIndent(snapshot, "", "  ")
+		Expect(err).NotTo(HaveOccurred())
+		h.WriteResults(map[string][]byte{
+			metrics.NetperfSnapshotFile: data,
+		})
+
+		history, err := loadHistory(h)
+		if err != nil {
+			log.Printf("Failed to load network performance history, results won't be compared to previous runs: %v", err)
+			return
+		}
+
+		var regressions []string
+		for _, r := range history.Regressions(snapshot, h.NetworkPerfMaxRegression, h.NetworkPerfMinSamples) {
+			regressions = append(regressions, r.String())
+		}
+		Expect(regressions).To(BeEmpty(), "network performance regressed by more than %.1f%%", h.NetworkPerfMaxRegression)
+	})
+})
+
+// schedulable returns the nodes pods without tolerations can be scheduled onto.
+func schedulable(nodes []kubev1.Node) (matched []kubev1.Node) {
+	for _, n := range nodes {
+		if n.Spec.Unschedulable {
+			continue
+		}
+		tainted := false
+		for _, t := range n.Spec.Taints {
+			if t.Effect == kubev1.TaintEffectNoSchedule || t.Effect == kubev1.TaintEffectNoExecute {
+				tainted = true
+			}
+		}
+		if !tainted {
+			matched = append(matched, n)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		return matched[i].Name < matched[j].Name
+	})
+	return
+}
+
+// pickNodes chooses the node running the server and nodes running clients in the same and another zone, preferring a
+// server with nodes in both. Either client node is nil if there isn't one.
+func pickNodes(nodes []kubev1.Node) (server, sameZone, otherZone *kubev1.Node) {
+	for i := range nodes {
+		var same, other *kubev1.Node
+		zone := nodes[i].Labels[topology.ZoneLabel]
+		for j := range nodes {
+			if i == j {
+				continue
+			}
+			if nodes[j].Labels[topology.ZoneLabel] == zone {
+				if same == nil {
+					same = &nodes[j]
+				}
+			} else if other == nil {
+				other = &nodes[j]
+			}
+		}
+
+		if server == nil || (same != nil && other != nil) {
+			server, sameZone, otherZone = &nodes[i], same, other
+		}
+		if same != nil && other != nil {
+			break
+		}
+	}
+	return
+}
+
+// createServer runs an iperf3 server on node behind a service, waiting for it to be running.
+func createServer(h *helper.H, node *kubev1.Node) (*kubev1.Pod, *kubev1.Service) {
+	labels := map[string]string{"app": "netperf-server"}
+	pod, err := h.Kube().CoreV1().Pods(h.CurrentProject()).Create(&kubev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "netperf-server",
+			Labels: labels,
+		},
+		Spec: kubev1.PodSpec{
+			NodeName: node.Name,
+			Containers: []kubev1.Container{
+				{
+					Name:    "iperf3",
+					Image:   iperfImage,
+					Command: []string{"iperf3", "-s", "-p", strconv.Itoa(iperfPort)},
+					Ports:   []kubev1.ContainerPort{{ContainerPort: iperfPort}},
+				},
+			},
+		},
+	})
+	Expect(err).NotTo(HaveOccurred(), "couldn't create iperf3 server")
+
+	svc, err := h.Kube().CoreV1().Services(h.CurrentProject()).Create(&kubev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "netperf-server",
+		},
+		Spec: kubev1.ServiceSpec{
+			Selector: labels,
+			Ports: []kubev1.ServicePort{
+				{Port: iperfPort, TargetPort: intstr.FromInt(iperfPort)},
+			},
+		},
+	})
+	Expect(err).NotTo(HaveOccurred(), "couldn't create iperf3 service")
+
+	phase := h.WaitForPodPhase(pod, kubev1.PodRunning, int(podTimeout/(10*time.Second)), 10*time.Second)
+	Expect(phase).To(Equal(kubev1.PodRunning), "iperf3 server isn't running")
+
+	pod, err = h.Kube().CoreV1().Pods(pod.Namespace).Get(pod.Name, metav1.GetOptions{})
+	Expect(err).NotTo(HaveOccurred(), "couldn't get iperf3 server")
+	return pod, svc
+}
+
+// runClient sends traffic from an iperf3 client on node to host, returning the throughput and mean round trip time.
+func runClient(h *helper.H, path string, node *kubev1.Node, host string) (float64, time.Duration) {
+	seconds := int(h.NetworkPerfDuration.Seconds())
+	pod, err := h.Kube().CoreV1().Pods(h.CurrentProject()).Create(&kubev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "netperf-client-" + path,
+		},
+		Spec: kubev1.PodSpec{
+			NodeName:      node.Name,
+			RestartPolicy: kubev1.RestartPolicyNever,
+			Containers: []kubev1.Container{
+				{
+					Name:  "iperf3",
+					Image: iperfImage,
+					Command: []string{"iperf3", "-c", host, "-p", strconv.Itoa(iperfPort), "-t", strconv.Itoa(seconds),
+						"-J"},
+				},
+			},
+		},
+	})
+	Expect(err).NotTo(HaveOccurred(), "couldn't create iperf3 client for %s", path)
+
+	timeout := podTimeout + h.NetworkPerfDuration
+	phase := h.WaitForPodPhase(pod, kubev1.PodSucceeded, int(timeout/(10*time.Second)), 10*time.Second)
+
+	data, err := h.Kube().CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &kubev1.PodLogOptions{}).DoRaw()
+	Expect(err).NotTo(HaveOccurred(), "couldn't get output of iperf3 client for %s", path)
+	Expect(phase).To(Equal(kubev1.PodSucceeded), "iperf3 client for %s didn't succeed: %s", path, data)
+
+	throughput, rtt, err := netperf.ParseIperf(data)
+	Expect(err).NotTo(HaveOccurred(), "couldn't measure %s", path)
+	return throughput, rtt
+}
+
+// instanceType returns the instance type of server and client, or both if they differ.
+func instanceType(server, client *kubev1.Node) string {
+	s, c := server.Labels[topology.InstanceTypeLabel], client.Labels[topology.InstanceTypeLabel]
+	if s == c {
+		return s
+	}
+	return s + "," + c
+}
+
+// loadHistory reads network performance snapshots from the most recent builds recorded in TestGrid.
+func loadHistory(h *helper.H) (netperf.History, error) {
+	if h.NoTestGrid || h.TestGridBucket == "" {
+		return nil, fmt.Errorf("TestGrid isn't configured")
+	}
+	tg, err := osdtestgrid.NewTestGrid(h.TestGridBucket, h.TestGridPrefix, h.TestGridServiceAccount)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't setup TestGrid: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(h.Context(), time.Minute)
+	defer cancel()
+	_, latest, err := tg.LatestFinished(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get latest build: %v", err)
+	}
+
+	history := netperf.History{}
+	for i := latest; i > 0 && i > latest-h.NetworkPerfBuilds; i-- {
+		// builds which didn't measure network performance have no snapshot
+		data, err := tg.Artifact(ctx, i, metrics.NetperfSnapshotFile)
+		if err != nil {
+			continue
+		}
+
+		var snapshot metrics.Snapshot
+		if err = json.Unmarshal(data, &snapshot); err != nil {
+			log.Printf("Error decoding network performance of build %d: %v", i, err)
+			continue
+		}
+		history.Add(&snapshot)
+	}
+	return history, nil
+}
@@ -0,0 +1,105 @@
+// Package netplugin checks the cluster runs the network plugin it was installed with, so jobs for every plugin in
+// NETWORK_TYPE share the same conformance checks.
+package netplugin
+
+import (
+	"fmt"
+
+	"github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openshift/osde2e/pkg/groups"
+	"github.com/openshift/osde2e/pkg/helper"
+	"github.com/openshift/osde2e/pkg/netplugin"
+	"github.com/openshift/osde2e/pkg/skips"
+)
+
+// networkConfig is the name of the cluster's Network config.
+const networkConfig = "cluster"
+
+var _ = groups.Describe(groups.Networking, "Network plugin", func() {
+	h := helper.New()
+
+	// plugin returns the plugin the cluster reports running, skipping clusters which don't report one known.
+	plugin := func() netplugin.Plugin {
+		network, err := h.Cfg().ConfigV1().Networks().Get(networkConfig, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			skips.Skip(skips.CapabilityMissing, "cluster has no Network config", 1)
+		}
+		Expect(err).NotTo(HaveOccurred(), "failed getting Network config")
+
+		p, err := netplugin.Get(network.Status.NetworkType)
+		if err != nil {
+			skips.Skip(skips.CapabilityMissing, err.Error(), 1)
+		}
+		return p
+	}
+
+	ginkgo.It("should be the configured network type", func() {
+		if h.NetworkType == "" {
+			skips.Skip(skips.ConfigExcluded, "NETWORK_TYPE is not set")
+		}
+
+		network, err := h.Cfg().ConfigV1().Networks().Get(networkConfig, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred(), "failed getting Network config")
+		Expect(network.Spec.NetworkType).To(Equal(h.NetworkType), "cluster was installed with another plugin")
+		Expect(network.Status.NetworkType).To(Equal(h.NetworkType), "cluster is running another plugin")
+	})
+
+	ginkgo.It("should run the plugin on every node", func() {
+		p := plugin()
+
+		var problems []string
+		for _, name := range p.DaemonSets {
+			ds, err := h.Kube().AppsV1().DaemonSets(p.Namespace).Get(name, metav1.GetOptions{})
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("couldn't get %s: %v", name, err))
+				continue
+			}
+			problems = append(problems, netplugin.DaemonSetProblems(ds)...)
+		}
+		Expect(problems).To(BeEmpty(), "%s isn't running on every node", p.Type)
+	})
+
+	ginkgo.It("should serve the plugin's APIs", func() {
+		p := plugin()
+
+		var missing []string
+		for groupVersion, resources := range p.APIs {
+			list, err := h.Kube().Discovery().ServerResourcesForGroupVersion(groupVersion)
+			if !apierrors.IsNotFound(err) {
+				Expect(err).NotTo(HaveOccurred(), "failed discovering %s", groupVersion)
+			}
+
+			served := map[string]bool{}
+			if list != nil {
+				for _, r := range list.APIResources {
+					served[r.Name] = true
+				}
+			}
+			for _, r := range resources {
+				if !served[r] {
+					missing = append(missing, fmt.Sprintf("%s/%s", groupVersion, r))
+				}
+			}
+		}
+		Expect(missing).To(BeEmpty(), "%s APIs aren't served", p.Type)
+	})
+
+	ginkgo.It("should not run other plugins", func() {
+		p := plugin()
+
+		for _, other := range netplugin.Plugins {
+			if other.Type == p.Type {
+				continue
+			}
+			for _, name := range other.DaemonSets {
+				_, err := h.Kube().AppsV1().DaemonSets(other.Namespace).Get(name, metav1.GetOptions{})
+				Expect(apierrors.IsNotFound(err)).To(BeTrue(), "%s is running %s/%s", p.Type, other.Type, name)
+			}
+		}
+	})
+})
@@ -32,8 +32,7 @@ var _ = ginkgo.Describe("OpenShift E2E", func() {
 		r := h.Runner(cmd)
 
 		// run tests
-		stopCh := make(chan struct{})
-		err := r.Run(stopCh)
+		err := r.Run(helper.PhaseContext().Done())
 		Expect(err).NotTo(HaveOccurred())
 
 		// get results
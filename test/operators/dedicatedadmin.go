@@ -13,14 +13,14 @@ package verify
 
 import (
 	"log"
-	"math/rand"
-	"strings"
 	"time"
 
 	"github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
+	"github.com/openshift/osde2e/pkg/groups"
 	"github.com/openshift/osde2e/pkg/helper"
+	"github.com/openshift/osde2e/pkg/seed"
 	v1 "github.com/openshift/api/project/v1"
 
 	appsv1 "k8s.io/api/apps/v1"
@@ -48,8 +48,9 @@ var roleBindings = [2]string{
 	"dedicated-admins-project-1",
 }
 
-var _ = ginkgo.Describe("The Dedicated Admin Operator", func() {
+var _ = groups.Describe(groups.Operators, "The Dedicated Admin Operator", func() {
 	h := helper.New()
+	ginkgo.BeforeEach(h.SkipLocal)
 
 	// Check that the operator deployment exists in the operator namespace
 	ginkgo.Context("deployments", func() {
@@ -111,8 +112,9 @@ var _ = ginkgo.Describe("The Dedicated Admin Operator", func() {
 
 
 // Test the controller; make sure new rolebindings are created for new project
-var _ = ginkgo.Describe("The Operator Controller", func() {
+var _ = groups.Describe(groups.Operators, "The Operator Controller", func() {
 	h := helper.New()
+	ginkgo.BeforeEach(h.SkipLocal)
 	ginkgo.Context("when a new project is created", func() {
 		ginkgo.It("should create the expected roleBindings", func() {
 			projectRequest := v1.ProjectRequest{}
@@ -175,7 +177,7 @@ func pollRoleBinding(h *helper.H, projectName string, roleBindingName string) er
 				log.Printf("Found rolebinding %v", roleBindingName)
 				break Loop
 			default:
-				if elapsed < timeoutDuration {
+				if elapsed < timeoutDuration && h.Context().Err() == nil {
 					timeTilTimeout := timeoutDuration - elapsed
 					log.Printf("Failed to get rolebinding %v, will retry (timeout in: %v)", roleBindingName, timeTilTimeout)
 					time.Sleep(intervalDuration)
@@ -218,7 +220,7 @@ func pollLockFile(h *helper.H) (error) {
 				// Success
 				break Loop
 			default:
-				if elapsed < timeoutDuration {
+				if elapsed < timeoutDuration && h.Context().Err() == nil {
 					timeTilTimeout := timeoutDuration - elapsed
 					log.Printf("Failed to get configmap, will retry (timeout in: %v", timeTilTimeout)
 					time.Sleep(intervalDuration)
@@ -263,7 +265,7 @@ func pollDeploymentList(h *helper.H) (*appsv1.DeploymentList, error) {
 				// Success
 				break Loop
 			default:
-				if elapsed < timeoutDuration {
+				if elapsed < timeoutDuration && h.Context().Err() == nil {
 					timeTilTimeout := timeoutDuration - elapsed
 					log.Printf("Failed to get Deployments, will retry (timeout in: %v", timeTilTimeout)
 					time.Sleep(intervalDuration)
@@ -282,14 +284,5 @@ func genSuffix(prefix string) string {
 	// genSuffix creates a random 8 character string to append to object
 	// names when creating Kubernetes objects so there aren't any
 	// accidental name collisions
-
-	// Seed rand so there's actual randomness
-	// otherwise the string is always the same
-	rand.Seed(time.Now().UnixNano())
-
-	bytes := make([]byte, 8)
-	for i := 0; i < 8; i++ {
-		bytes[i] = byte(65 + rand.Intn(25))
-	}
-	return prefix + "-" + strings.ToLower(string(bytes))
+	return prefix + "-" + seed.String(seed.Namespaces, 8)
 }
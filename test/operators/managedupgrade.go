@@ -0,0 +1,230 @@
+package verify
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	configv1 "github.com/openshift/api/config/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/openshift/osde2e/pkg/conditions"
+	"github.com/openshift/osde2e/pkg/drift"
+	"github.com/openshift/osde2e/pkg/groups"
+	"github.com/openshift/osde2e/pkg/helper"
+	"github.com/openshift/osde2e/pkg/skips"
+	"github.com/openshift/osde2e/pkg/upgrade"
+)
+
+const (
+	// upgradePolicyName is the UpgradeConfig created by checks, separate from the one OSD manages.
+	upgradePolicyName = "osde2e-upgrade-policy"
+
+	// unavailableVersion is a version clusters can never upgrade to.
+	unavailableVersion = "4.0.0"
+
+	// policyObservation is how long the operator is watched to check it doesn't act on a policy.
+	policyObservation = 5 * time.Minute
+
+	// policyPollInterval is how often UpgradeConfigs and the ClusterVersion are checked.
+	policyPollInterval = 15 * time.Second
+
+	// scheduleDelay is how far ahead upgrades which should run are scheduled.
+	scheduleDelay = 3 * time.Minute
+
+	// drainTimeout is how long nodes are drained despite PodDisruptionBudgets before pods are removed anyway.
+	drainTimeout = 5 * time.Minute
+)
+
+var _ = groups.Describe(groups.Operators, "Managed Upgrade Operator", func() {
+	h := helper.New()
+	ginkgo.BeforeEach(h.SkipLocal)
+
+	ginkgo.BeforeEach(func() {
+		if !h.MUOChecks {
+			skips.Skip(skips.ConfigExcluded, "MUO_CHECKS is not set")
+		}
+		h.SkipShared()
+
+		_, err := h.Kube().AppsV1().Deployments(upgrade.MUONamespace).Get(upgrade.MUODeployment, metav1.GetOptions{})
+		if kerror.IsNotFound(err) {
+			skips.Skip(skips.CapabilityMissing, "cluster doesn't run the managed-upgrade-operator")
+		}
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	ginkgo.AfterEach(func() {
+		if err := h.DeleteResource(upgrade.UpgradeConfigs, upgrade.MUONamespace, upgradePolicyName); err != nil {
+			log.Printf("Failed to remove upgrade policy: %v", err)
+		}
+	})
+
+	ginkgo.It("should be available", func() {
+		deployment, err := h.Kube().AppsV1().Deployments(upgrade.MUONamespace).Get(upgrade.MUODeployment, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deployment.Status.AvailableReplicas).To(BeNumerically(">", 0), "operator has no available replicas")
+	})
+
+	ginkgo.It("should not commence upgrades to unavailable versions", func() {
+		cv := clusterVersion(h)
+		applyPolicy(h, upgrade.UpgradePolicy{
+			Name:      upgradePolicyName,
+			Version:   unavailableVersion,
+			Channel:   cv.Spec.Channel,
+			UpgradeAt: time.Now(),
+		})
+		expectNotCommenced(h, unavailableVersion)
+	})
+
+	ginkgo.It("should not commence upgrades before they're scheduled", func() {
+		cv := clusterVersion(h)
+		target := nextVersion(cv)
+		applyPolicy(h, upgrade.UpgradePolicy{
+			Name:      upgradePolicyName,
+			Version:   target,
+			Channel:   cv.Spec.Channel,
+			UpgradeAt: time.Now().Add(24 * time.Hour),
+		})
+		expectNotCommenced(h, target)
+	})
+
+	ginkgo.It("should upgrade in its window once pre-checks pass", func() {
+		if !h.MUOUpgradeChecks {
+			skips.Skip(skips.ConfigExcluded, "MUO_UPGRADE_CHECKS is not set")
+		}
+		// the upgrade alone may take longer than SPEC_TIMEOUT
+		h.ExtendContext(h.SpecTimeout + scheduleDelay + upgrade.MaxDuration)
+		cv := clusterVersion(h)
+		target := nextVersion(cv)
+
+		// workloads protected by PodDisruptionBudgets must not stop the upgrade
+		createGuardedWorkload(h)
+
+		// the upgrade is intended, so it isn't drift and operators may churn while it runs
+		drift.Current.Allow(target)
+		started := time.Now()
+		defer func() {
+			conditions.Current.Exclude(started, time.Now())
+		}()
+
+		upgradeAt := time.Now().Add(scheduleDelay)
+		applyPolicy(h, upgrade.UpgradePolicy{
+			Name:                 upgradePolicyName,
+			Version:              target,
+			Channel:              cv.Spec.Channel,
+			UpgradeAt:            upgradeAt,
+			PDBForceDrainTimeout: drainTimeout,
+			CapacityReservation:  true,
+		})
+
+		var managed upgrade.ManagedUpgrade
+		err := h.PollImmediate(policyPollInterval, scheduleDelay+upgrade.MaxDuration, func() (bool, error) {
+			obj, err := h.GetResource(upgrade.UpgradeConfigs, upgrade.MUONamespace, upgradePolicyName)
+			if err != nil {
+				log.Printf("Failed to get upgrade policy: %v", err)
+				return false, nil
+			}
+
+			var ok bool
+			if managed, ok = upgrade.ManagedUpgradeOf(obj, target); !ok {
+				return false, nil
+			} else if managed.Phase == upgrade.PhaseFailed {
+				return false, fmt.Errorf("managed upgrade to %s failed: %v", target, managed.Conditions)
+			}
+			log.Printf("Managed upgrade to %s is %s.", target, managed.Phase)
+			return managed.Phase == upgrade.PhaseUpgraded, nil
+		})
+		Expect(err).NotTo(HaveOccurred(), "managed upgrade to %s didn't complete", target)
+
+		Expect(managed.StartTime.Before(upgradeAt.Truncate(time.Second))).To(BeFalse(),
+			"upgrade started at %v, before it was scheduled at %v", managed.StartTime, upgradeAt)
+		for _, condition := range []string{upgrade.ConditionPreHealthCheck, upgrade.ConditionScaleUpExtraNodes,
+			upgrade.ConditionCommenceUpgrade} {
+			Expect(managed.Conditions[condition]).To(Equal("True"), "step %s of the upgrade didn't succeed", condition)
+		}
+		Expect(clusterVersion(h).Status.Desired.Version).To(Equal(target), "ClusterVersion wasn't upgraded")
+	})
+})
+
+// clusterVersion returns the ClusterVersion of the cluster.
+func clusterVersion(h *helper.H) *configv1.ClusterVersion {
+	cv, err := h.Cfg().ConfigV1().ClusterVersions().Get(upgrade.ClusterVersionName, metav1.GetOptions{})
+	Expect(err).NotTo(HaveOccurred())
+	return cv
+}
+
+// nextVersion returns a version cv can upgrade to, skipping the spec if there's none.
+func nextVersion(cv *configv1.ClusterVersion) string {
+	if len(cv.Status.AvailableUpdates) == 0 {
+		skips.Skip(skips.CapabilityMissing, fmt.Sprintf("no upgrades are available in channel '%s'", cv.Spec.Channel))
+	}
+	return cv.Status.AvailableUpdates[0].Version
+}
+
+// applyPolicy creates an UpgradeConfig requesting policy.
+func applyPolicy(h *helper.H, policy upgrade.UpgradePolicy) {
+	_, err := h.ApplyResource(upgrade.UpgradeConfigs, policy.UpgradeConfig())
+	Expect(err).NotTo(HaveOccurred(), "failed creating upgrade policy")
+	h.ServiceLog("osde2e scheduled a managed upgrade", "Applied upgrade policy '%s' to upgrade to %s at %s.",
+		policy.Name, policy.Version, policy.UpgradeAt.UTC().Format(time.RFC3339))
+}
+
+// expectNotCommenced fails if the upgrade to version is started while the operator is observed.
+func expectNotCommenced(h *helper.H, version string) {
+	err := h.PollImmediate(policyPollInterval, policyObservation, func() (bool, error) {
+		if obj, err := h.GetResource(upgrade.UpgradeConfigs, upgrade.MUONamespace, upgradePolicyName); err != nil {
+			log.Printf("Failed to get upgrade policy: %v", err)
+		} else if managed, ok := upgrade.ManagedUpgradeOf(obj, version); ok && managed.Commenced() {
+			return false, fmt.Errorf("upgrade to %s was commenced, it's %s", version, managed.Phase)
+		}
+
+		if desired := clusterVersion(h).Spec.DesiredUpdate; desired != nil && desired.Version == version {
+			return false, fmt.Errorf("ClusterVersion was updated to %s", version)
+		}
+		return false, nil
+	})
+	Expect(err).To(Equal(wait.ErrWaitTimeout))
+}
+
+// createGuardedWorkload runs a Deployment in the project of h whose PodDisruptionBudget prevents draining its node.
+func createGuardedWorkload(h *helper.H) {
+	labels := map[string]string{"app": "guarded"}
+	replicas := int32(1)
+	_, err := h.Kube().AppsV1().Deployments(h.CurrentProject()).Create(&appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "guarded"},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name:    "sleep",
+						Image:   "registry.access.redhat.com/ubi8/ubi-minimal",
+						Command: []string{"sleep", "infinity"},
+					}},
+				},
+			},
+		},
+	})
+	Expect(err).NotTo(HaveOccurred(), "failed creating guarded workload")
+
+	minAvailable := intstr.FromInt(1)
+	_, err = h.Kube().PolicyV1beta1().PodDisruptionBudgets(h.CurrentProject()).Create(&policyv1beta1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{Name: "guarded"},
+		Spec: policyv1beta1.PodDisruptionBudgetSpec{
+			MinAvailable: &minAvailable,
+			Selector:     &metav1.LabelSelector{MatchLabels: labels},
+		},
+	})
+	Expect(err).NotTo(HaveOccurred(), "failed creating PodDisruptionBudget")
+}
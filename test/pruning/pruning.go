@@ -0,0 +1,283 @@
+// Package pruning verifies resources which are no longer needed are garbage collected as managed clusters are
+// configured to, so they don't accumulate over the life of a cluster.
+package pruning
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	imagev1 "github.com/openshift/api/image/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	kubev1 "k8s.io/api/core/v1"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/openshift/osde2e/pkg/groups"
+	"github.com/openshift/osde2e/pkg/helper"
+	"github.com/openshift/osde2e/pkg/skips"
+)
+
+const (
+	// eventTTL is how long the API server of managed clusters keeps events after they were last updated.
+	eventTTL = 3 * time.Hour
+
+	// eventTTLSlack allows for events being removed by etcd some time after their lease expires.
+	eventTTLSlack = 15 * time.Minute
+
+	// registryNamespace holds the image pruner.
+	registryNamespace = "openshift-image-registry"
+
+	// prunerCronJob runs the image pruner on its schedule.
+	prunerCronJob = "image-pruner"
+
+	// prunerName is the name of the cluster's ImagePruner.
+	prunerName = "cluster"
+
+	// seededCronJob completes jobs which should be removed beyond its history limit.
+	seededCronJob = "osde2e-pruning"
+
+	// jobImage runs the jobs of seededCronJob.
+	jobImage = "registry.access.redhat.com/ubi8/ubi-minimal"
+
+	// jobHistoryLimit is how many completed jobs of seededCronJob are kept.
+	jobHistoryLimit = 1
+
+	// jobsTimeout is how long seededCronJob has to run and have its old jobs removed. It runs every minute.
+	jobsTimeout = 10 * time.Minute
+
+	// prunerTimeout is how long the image pruner has to complete when run.
+	prunerTimeout = 15 * time.Minute
+
+	// seededImageStream imports seededImage, and is removed so the image is no longer referenced.
+	seededImageStream = "osde2e-pruning"
+
+	// seededImage is imported to be pruned. It's not expected to be used by anything else on the cluster.
+	seededImage = "quay.io/prometheus/busybox:latest"
+
+	// importTimeout is how long seededImage has to be imported.
+	importTimeout = 5 * time.Minute
+
+	pollInterval = 15 * time.Second
+)
+
+// imagePrunerGVR is the resource of the ImagePruner configuring image pruning.
+var imagePrunerGVR = schema.GroupVersionResource{Group: "imageregistry.operator.openshift.io", Version: "v1", Resource: "imagepruners"}
+
+var _ = groups.Describe(groups.Operators, "Pruning", func() {
+	h := helper.New()
+	ginkgo.BeforeEach(h.SkipLocal)
+
+	ginkgo.It("should remove completed jobs beyond the history limit of their CronJob", func() {
+		limit := int32(jobHistoryLimit)
+		_, err := h.Kube().BatchV1beta1().CronJobs(h.CurrentProject()).Create(&batchv1beta1.CronJob{
+			ObjectMeta: metav1.ObjectMeta{Name: seededCronJob},
+			Spec: batchv1beta1.CronJobSpec{
+				Schedule:                   "*/1 * * * *",
+				ConcurrencyPolicy:          batchv1beta1.ForbidConcurrent,
+				SuccessfulJobsHistoryLimit: &limit,
+				FailedJobsHistoryLimit:     &limit,
+				JobTemplate: batchv1beta1.JobTemplateSpec{
+					Spec: batchv1.JobSpec{
+						Template: kubev1.PodTemplateSpec{
+							Spec: kubev1.PodSpec{
+								RestartPolicy: kubev1.RestartPolicyNever,
+								Containers: []kubev1.Container{
+									{
+										Name:    seededCronJob,
+										Image:   jobImage,
+										Command: []string{"true"},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		})
+		Expect(err).NotTo(HaveOccurred(), "failed creating CronJob '%s'", seededCronJob)
+
+		// wait for more jobs to have run than are kept, then for the oldest to be removed
+		seen := map[string]bool{}
+		err = h.Poll(pollInterval, jobsTimeout, func() (bool, error) {
+			jobs, err := h.Kube().BatchV1().Jobs(h.CurrentProject()).List(metav1.ListOptions{})
+			if err != nil {
+				return false, fmt.Errorf("couldn't list jobs: %v", err)
+			}
+
+			completed := 0
+			for _, job := range jobs.Items {
+				if !ownedBy(job.OwnerReferences, seededCronJob) {
+					continue
+				}
+				if jobFinished(&job) {
+					seen[job.Name] = true
+					completed++
+				}
+			}
+			log.Printf("CronJob '%s' has %d of %d completed jobs remaining.", seededCronJob, completed, len(seen))
+			return len(seen) > jobHistoryLimit+1 && completed <= jobHistoryLimit, nil
+		})
+		Expect(err).NotTo(HaveOccurred(), "completed jobs of CronJob '%s' weren't removed beyond its history limit of %d",
+			seededCronJob, jobHistoryLimit)
+	})
+
+	ginkgo.It("should not keep events past their TTL", func() {
+		events, err := h.Kube().CoreV1().Events(metav1.NamespaceAll).List(metav1.ListOptions{})
+		Expect(err).NotTo(HaveOccurred(), "failed listing events")
+
+		// events are kept for their TTL after being last updated, so any older should have been removed
+		cutoff := time.Now().Add(-(eventTTL + eventTTLSlack))
+		var stale []string
+		for _, e := range events.Items {
+			if updated := eventUpdated(&e); updated.Before(cutoff) {
+				stale = append(stale, fmt.Sprintf("%s/%s last updated %s", e.Namespace, e.Name, updated.Format(time.RFC3339)))
+			}
+		}
+		Expect(stale).To(BeEmpty(), "events remain more than %v after they were last updated", eventTTL)
+	})
+
+	ginkgo.It("should prune unreferenced images", func() {
+		pruner, err := h.Dynamic().Resource(imagePrunerGVR).Get(prunerName, metav1.GetOptions{})
+		if kerror.IsNotFound(err) {
+			skips.Skip(skips.CapabilityMissing, "ImagePruner isn't available on this version")
+		}
+		Expect(err).NotTo(HaveOccurred(), "failed getting ImagePruner '%s'", prunerName)
+
+		suspended, _, _ := unstructured.NestedBool(pruner.Object, "spec", "suspend")
+		Expect(suspended).To(BeFalse(), "image pruning is suspended")
+
+		cronJob, err := h.Kube().BatchV1beta1().CronJobs(registryNamespace).Get(prunerCronJob, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred(), "image pruner isn't scheduled")
+		Expect(cronJob.Spec.Suspend == nil || !*cronJob.Spec.Suspend).To(BeTrue(), "image pruner CronJob is suspended")
+
+		image := seedImage(h)
+
+		// run the pruner now rather than waiting for its schedule, without keeping the freshly seeded image
+		spec := cronJob.Spec.JobTemplate.Spec.DeepCopy()
+		for i := range spec.Template.Spec.Containers {
+			c := &spec.Template.Spec.Containers[i]
+			c.Args = append(c.Args, "--keep-younger-than=0s")
+		}
+		job, err := h.Kube().BatchV1().Jobs(registryNamespace).Create(&batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: prunerCronJob + "-osde2e-",
+				Labels:       cronJob.Spec.JobTemplate.Labels,
+			},
+			Spec: *spec,
+		})
+		Expect(err).NotTo(HaveOccurred(), "failed running image pruner")
+
+		defer func() {
+			propagation := metav1.DeletePropagationBackground
+			err := h.Kube().BatchV1().Jobs(registryNamespace).Delete(job.Name, &metav1.DeleteOptions{
+				PropagationPolicy: &propagation,
+			})
+			if err != nil && !kerror.IsNotFound(err) {
+				log.Printf("Failed to delete image pruner job '%s': %v", job.Name, err)
+			}
+		}()
+
+		err = h.Poll(pollInterval, prunerTimeout, func() (bool, error) {
+			job, err = h.Kube().BatchV1().Jobs(registryNamespace).Get(job.Name, metav1.GetOptions{})
+			if err != nil {
+				return false, fmt.Errorf("couldn't get image pruner job: %v", err)
+			} else if jobFailed(job) {
+				return false, fmt.Errorf("image pruner job '%s' failed", job.Name)
+			}
+			return job.Status.Succeeded > 0, nil
+		})
+		Expect(err).NotTo(HaveOccurred(), "image pruner didn't complete")
+
+		_, err = h.Image().ImageV1().Images().Get(image, metav1.GetOptions{})
+		Expect(kerror.IsNotFound(err)).To(BeTrue(), "unreferenced image '%s' wasn't pruned: %v", image, err)
+	})
+})
+
+// seedImage imports seededImage into an ImageStream which is then removed, leaving an image which nothing references.
+// It returns the name of the image.
+func seedImage(h *helper.H) (image string) {
+	streams := h.Image().ImageV1().ImageStreams(h.CurrentProject())
+	_, err := streams.Create(&imagev1.ImageStream{
+		ObjectMeta: metav1.ObjectMeta{Name: seededImageStream},
+		Spec: imagev1.ImageStreamSpec{
+			Tags: []imagev1.TagReference{
+				{
+					Name: "latest",
+					From: &kubev1.ObjectReference{Kind: "DockerImage", Name: seededImage},
+				},
+			},
+		},
+	})
+	Expect(err).NotTo(HaveOccurred(), "failed creating ImageStream '%s'", seededImageStream)
+
+	err = h.Poll(pollInterval, importTimeout, func() (bool, error) {
+		stream, err := streams.Get(seededImageStream, metav1.GetOptions{})
+		if err != nil {
+			return false, fmt.Errorf("couldn't get ImageStream '%s': %v", seededImageStream, err)
+		}
+		for _, tag := range stream.Status.Tags {
+			if len(tag.Items) != 0 {
+				image = tag.Items[0].Image
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+	Expect(err).NotTo(HaveOccurred(), "'%s' wasn't imported", seededImage)
+
+	err = streams.Delete(seededImageStream, &metav1.DeleteOptions{})
+	Expect(err).NotTo(HaveOccurred(), "failed deleting ImageStream '%s'", seededImageStream)
+	log.Printf("Seeded unreferenced image '%s' from '%s'.", image, seededImage)
+	return
+}
+
+// ownedBy returns true if refs include the CronJob name.
+func ownedBy(refs []metav1.OwnerReference, name string) bool {
+	for _, ref := range refs {
+		if ref.Kind == "CronJob" && ref.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// jobFinished returns true if job completed or failed.
+func jobFinished(job *batchv1.Job) bool {
+	for _, cond := range job.Status.Conditions {
+		if (cond.Type == batchv1.JobComplete || cond.Type == batchv1.JobFailed) && cond.Status == kubev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// jobFailed returns true if job failed.
+func jobFailed(job *batchv1.Job) bool {
+	for _, cond := range job.Status.Conditions {
+		if cond.Type == batchv1.JobFailed && cond.Status == kubev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// eventUpdated returns when e was last created or updated.
+func eventUpdated(e *kubev1.Event) time.Time {
+	updated := e.CreationTimestamp.Time
+	for _, t := range []time.Time{e.LastTimestamp.Time, e.EventTime.Time} {
+		if t.After(updated) {
+			updated = t
+		}
+	}
+	if e.Series != nil && e.Series.LastObservedTime.Time.After(updated) {
+		updated = e.Series.LastObservedTime.Time
+	}
+	return updated
+}
@@ -0,0 +1,42 @@
+// Package scale gates scale runs on their KPIs. Scale jobs run kube-burner against the cluster and leave its results in
+// SCALE_RESULTS_DIR, which are checked against SCALE_THRESHOLDS.
+package scale
+
+import (
+	"github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/openshift/osde2e/pkg/groups"
+	"github.com/openshift/osde2e/pkg/helper"
+	"github.com/openshift/osde2e/pkg/scale"
+	"github.com/openshift/osde2e/pkg/skips"
+)
+
+var _ = groups.Describe(groups.Other, scale.SuiteName, func() {
+	h := helper.New()
+
+	ginkgo.It("should have KPIs within their thresholds", func() {
+		if h.ScaleResultsDir == "" {
+			skips.Skip(skips.ConfigExcluded, "SCALE_RESULTS_DIR is not set")
+		}
+
+		kpis, err := scale.Load(h.ScaleResultsDir)
+		Expect(err).NotTo(HaveOccurred(), "failed loading scale results")
+		Expect(kpis).NotTo(BeEmpty(), "no KPIs found in scale results")
+
+		thresholds, err := scale.ParseThresholds(h.ScaleThresholds)
+		Expect(err).NotTo(HaveOccurred(), "invalid scale thresholds")
+
+		results := scale.Check(kpis, thresholds)
+		err = scale.WriteJUnit(h.ReportDir, h.Suffix, results)
+		Expect(err).NotTo(HaveOccurred(), "failed writing scale results")
+
+		var failed []string
+		for _, r := range results {
+			if !r.Passed() {
+				failed = append(failed, r.KPI)
+			}
+		}
+		Expect(failed).To(BeEmpty(), "KPIs exceeded their thresholds or weren't found")
+	})
+})
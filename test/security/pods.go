@@ -0,0 +1,48 @@
+// Package security checks workloads in managed namespaces only use the privileges they are expected to.
+package security
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openshift/osde2e/pkg/groups"
+	"github.com/openshift/osde2e/pkg/helper"
+	"github.com/openshift/osde2e/pkg/security"
+)
+
+// violationsFile is written to the report directory when violations are found.
+const violationsFile = "security-violations.txt"
+
+var _ = groups.Describe(groups.Security, "Pod Security", func() {
+	h := helper.New()
+	ginkgo.BeforeEach(h.SkipLocal)
+
+	ginkgo.It("should only use allowed privileges in managed namespaces", func() {
+		allowlist, err := security.LoadAllowlist(h.SecurityAllowlist)
+		Expect(err).NotTo(HaveOccurred())
+
+		list, err := h.Kube().CoreV1().Pods(metav1.NamespaceAll).List(metav1.ListOptions{})
+		Expect(err).NotTo(HaveOccurred(), "couldn't list Pods")
+
+		var violations []security.Violation
+		for _, pod := range list.Items {
+			violations = append(violations, allowlist.Check(pod)...)
+		}
+
+		if len(violations) != 0 {
+			var buf bytes.Buffer
+			for _, v := range violations {
+				fmt.Fprintln(&buf, v)
+			}
+			h.WriteResults(map[string][]byte{
+				violationsFile: buf.Bytes(),
+			})
+		}
+		Expect(violations).To(BeEmpty(), "pods are using privileges not on the allowlist '%s'", h.SecurityAllowlist)
+	})
+})
@@ -4,6 +4,7 @@ import (
 	"github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
+	"github.com/openshift/osde2e/pkg/artifacts"
 	"github.com/openshift/osde2e/pkg/helper"
 	"github.com/openshift/osde2e/pkg/runner"
 )
@@ -24,8 +25,7 @@ var _ = ginkgo.Describe("Cluster state", func() {
 		r.Tarball = true
 
 		// run tests
-		stopCh := make(chan struct{})
-		err := r.Run(stopCh)
+		err := r.Run(helper.PhaseContext().Done())
 		Expect(err).NotTo(HaveOccurred())
 
 		// get results
@@ -33,6 +33,6 @@ var _ = ginkgo.Describe("Cluster state", func() {
 		Expect(err).NotTo(HaveOccurred())
 
 		// write results
-		h.WriteResults(results)
+		h.WriteArtifacts(artifacts.MustGather, results)
 	})
 })
@@ -1,11 +1,16 @@
 package state
 
 import (
+	"encoding/json"
+	"time"
+
 	"github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
 	"github.com/openshift/osde2e/pkg/helper"
+	"github.com/openshift/osde2e/pkg/metrics"
 	"github.com/openshift/osde2e/pkg/runner"
+	"github.com/openshift/osde2e/pkg/topology"
 )
 
 const (
@@ -24,8 +29,7 @@ var _ = ginkgo.Describe("Cluster state", func() {
 		r.Name = "collect-prometheus"
 
 		// run tests
-		stopCh := make(chan struct{})
-		err := r.Run(stopCh)
+		err := r.Run(helper.PhaseContext().Done())
 		Expect(err).NotTo(HaveOccurred())
 
 		// get results
@@ -36,3 +40,35 @@ var _ = ginkgo.Describe("Cluster state", func() {
 		h.WriteResults(results)
 	})
 })
+
+var _ = ginkgo.Describe("Cluster state", func() {
+	defer ginkgo.GinkgoRecover()
+	h := helper.New()
+
+	ginkgo.It("should include a snapshot of key metrics", func() {
+		snapshot := metrics.Snapshot{
+			Time:    time.Now().UTC(),
+			Metrics: make(map[string][]metrics.Sample, len(metrics.KeyQueries)),
+		}
+
+		// query each metric from inside the Prometheus pod
+		for name, query := range metrics.KeyQueries {
+			samples, err := h.QueryPrometheus(query, time.Time{})
+			Expect(err).NotTo(HaveOccurred(), "failed querying metric '%s'", name)
+			snapshot.Metrics[name] = samples
+		}
+
+		// label snapshot with where the cluster runs so changes can be attributed
+		topo, err := topology.Get(h.Kube())
+		Expect(err).NotTo(HaveOccurred(), "failed getting cluster topology")
+		snapshot.Labels = topo.Metadata()
+
+		data, err := json.MarshalIndent(snapshot, "", "  ")
+		Expect(err).NotTo(HaveOccurred())
+
+		// write results
+		h.WriteResults(map[string][]byte{
+			metrics.SnapshotFile: data,
+		})
+	})
+})
@@ -2,14 +2,13 @@
 package state
 
 import (
-	"bytes"
-	"compress/gzip"
 	"encoding/json"
 	"fmt"
 
 	"github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
+	"github.com/openshift/osde2e/pkg/artifacts"
 	"github.com/openshift/osde2e/pkg/helper"
 )
 
@@ -19,25 +18,21 @@ var _ = ginkgo.Describe("Cluster state", func() {
 
 	ginkgo.It("should be gathered", func() {
 		state := h.GetClusterState()
-		results := make(map[string][]byte, len(state))
+		results, events := make(map[string][]byte, len(state)), map[string][]byte{}
 		for resource, list := range state {
 			data, err := json.MarshalIndent(list, "", "    ")
 			Expect(err).NotTo(HaveOccurred())
 
-			var gbuf bytes.Buffer
-			zw := gzip.NewWriter(&gbuf)
-			_, err = zw.Write(data)
-			Expect(err).NotTo(HaveOccurred())
-
-			err = zw.Close()
-			Expect(err).NotTo(HaveOccurred())
-
-			// include gzip in filename to mark compressed data
-			filename := fmt.Sprintf("%s-%s-%s.json.gzip", resource.Group, resource.Version, resource.Resource)
-			results[filename] = gbuf.Bytes()
+			filename := fmt.Sprintf("%s-%s-%s.json", resource.Group, resource.Version, resource.Resource)
+			if resource.Resource == "events" {
+				events[filename] = data
+			} else {
+				results[filename] = data
+			}
 		}
 
-		// write results to disk
-		h.WriteResults(results)
+		// write results to disk, events are budgeted separately as they can be large
+		h.WriteArtifacts(artifacts.State, results)
+		h.WriteArtifacts(artifacts.Events, events)
 	})
 })
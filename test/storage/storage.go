@@ -0,0 +1,316 @@
+// Package storage provides tests of the storage provisioned for workloads on each cloud.
+package storage
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	configv1 "github.com/openshift/api/config/v1"
+	kubev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/openshift/osde2e/pkg/groups"
+	"github.com/openshift/osde2e/pkg/helper"
+	"github.com/openshift/osde2e/pkg/skips"
+)
+
+const (
+	// defaultClassAnnotation marks the default StorageClass.
+	defaultClassAnnotation = "storageclass.kubernetes.io/is-default-class"
+
+	// image used by Pods mounting volumes
+	volumeImage = "registry.access.redhat.com/ubi8/ubi-minimal"
+
+	// where volumes are mounted and the file written to them
+	mountPath = "/data"
+	dataFile  = mountPath + "/osde2e"
+
+	volumeSize   = "1Gi"
+	expandedSize = "2Gi"
+
+	storageTimeout = 10 * time.Minute
+)
+
+// cloudStorage is the storage expected on a cloud provider.
+type cloudStorage struct {
+	// Provisioners may provision volumes of the default StorageClass.
+	Provisioners []string
+
+	// RWXClass is a StorageClass supporting ReadWriteMany, if the cloud has one.
+	RWXClass string
+}
+
+var (
+	// clouds maps platforms to the storage expected on them.
+	clouds = map[configv1.PlatformType]cloudStorage{
+		configv1.AWSPlatformType: {
+			Provisioners: []string{"kubernetes.io/aws-ebs", "ebs.csi.aws.com"},
+		},
+		configv1.GCPPlatformType: {
+			Provisioners: []string{"kubernetes.io/gce-pd", "pd.csi.storage.gke.io"},
+		},
+		configv1.AzurePlatformType: {
+			Provisioners: []string{"kubernetes.io/azure-disk", "disk.csi.azure.com"},
+			RWXClass:     "azure-file",
+		},
+	}
+
+	// snapshotGVR is the resource of VolumeSnapshots.
+	snapshotGVR = schema.GroupVersionResource{Group: "snapshot.storage.k8s.io", Version: "v1beta1", Resource: "volumesnapshots"}
+
+	// snapshotClassGVR is the resource of VolumeSnapshotClasses.
+	snapshotClassGVR = schema.GroupVersionResource{Group: "snapshot.storage.k8s.io", Version: "v1beta1", Resource: "volumesnapshotclasses"}
+)
+
+var _ = groups.Describe(groups.Storage, "Storage", func() {
+	h := helper.New()
+
+	ginkgo.It("should provision volumes using the default StorageClass", func() {
+		class := defaultClass(h)
+		cloud := platformStorage(h)
+		Expect(cloud.Provisioners).To(ContainElement(class.Provisioner),
+			"default StorageClass '%s' uses unexpected provisioner", class.Name)
+
+		pvc := createPVC(h, "default", "", kubev1.ReadWriteOnce)
+		pod := createPod(h, "default-writer", pvc.Name)
+		writeData(h, pod, "default")
+		Expect(readData(h, pod)).To(Equal("default"))
+	})
+
+	ginkgo.It("should expand volumes", func() {
+		class := defaultClass(h)
+		if class.AllowVolumeExpansion == nil || !*class.AllowVolumeExpansion {
+			skips.Skip(skips.CapabilityMissing, fmt.Sprintf("default StorageClass '%s' doesn't allow volume expansion", class.Name))
+		}
+
+		pvc := createPVC(h, "expand", "", kubev1.ReadWriteOnce)
+		pod := createPod(h, "expand-writer", pvc.Name)
+		writeData(h, pod, "expand")
+
+		pvcs := h.Kube().CoreV1().PersistentVolumeClaims(h.CurrentProject())
+		pvc, err := pvcs.Get(pvc.Name, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		pvc.Spec.Resources.Requests[kubev1.ResourceStorage] = resource.MustParse(expandedSize)
+		_, err = pvcs.Update(pvc)
+		Expect(err).NotTo(HaveOccurred(), "couldn't request expansion of PVC '%s'", pvc.Name)
+
+		expanded := resource.MustParse(expandedSize)
+		err = h.PollImmediate(10*time.Second, storageTimeout, func() (bool, error) {
+			if pvc, err = pvcs.Get(pvc.Name, metav1.GetOptions{}); err != nil {
+				return false, err
+			}
+			capacity := pvc.Status.Capacity[kubev1.ResourceStorage]
+			return capacity.Cmp(expanded) >= 0, nil
+		})
+		Expect(err).NotTo(HaveOccurred(), "PVC '%s' wasn't expanded to %s", pvc.Name, expandedSize)
+		Expect(readData(h, pod)).To(Equal("expand"), "data should be kept after expansion")
+	})
+
+	ginkgo.It("should restore volumes from snapshots", func() {
+		class := defaultClass(h)
+		snapshotClass := snapshotClassFor(h, class.Provisioner)
+		if snapshotClass == "" {
+			skips.Skip(skips.CapabilityMissing, fmt.Sprintf("no VolumeSnapshotClass exists for provisioner '%s'", class.Provisioner))
+		}
+
+		pvc := createPVC(h, "snapshot-source", "", kubev1.ReadWriteOnce)
+		pod := createPod(h, "snapshot-writer", pvc.Name)
+		writeData(h, pod, "snapshot")
+
+		snapshot := createSnapshot(h, "snapshot", snapshotClass, pvc.Name)
+
+		restored := newPVC("snapshot-restore", "", kubev1.ReadWriteOnce)
+		apiGroup := snapshotGVR.Group
+		restored.Spec.DataSource = &kubev1.TypedLocalObjectReference{
+			APIGroup: &apiGroup,
+			Kind:     "VolumeSnapshot",
+			Name:     snapshot,
+		}
+		restored, err := h.Kube().CoreV1().PersistentVolumeClaims(h.CurrentProject()).Create(restored)
+		Expect(err).NotTo(HaveOccurred(), "couldn't create PVC from snapshot")
+
+		reader := createPod(h, "snapshot-reader", restored.Name)
+		Expect(readData(h, reader)).To(Equal("snapshot"), "restored volume should contain snapshot data")
+	})
+
+	ginkgo.It("should share ReadWriteMany volumes between Pods", func() {
+		cloud := platformStorage(h)
+		if cloud.RWXClass == "" {
+			skips.Skip(skips.CapabilityMissing, "cloud doesn't provide ReadWriteMany storage")
+		}
+
+		_, err := h.Kube().StorageV1().StorageClasses().Get(cloud.RWXClass, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred(), "couldn't get ReadWriteMany StorageClass '%s'", cloud.RWXClass)
+
+		pvc := createPVC(h, "shared", cloud.RWXClass, kubev1.ReadWriteMany)
+		writer := createPod(h, "shared-writer", pvc.Name)
+		reader := createPod(h, "shared-reader", pvc.Name)
+
+		writeData(h, writer, "shared")
+		Expect(readData(h, reader)).To(Equal("shared"), "data written by one Pod should be read by another")
+	})
+})
+
+// platformStorage returns the storage expected on the cluster's cloud provider.
+func platformStorage(h *helper.H) cloudStorage {
+	infra, err := h.Cfg().ConfigV1().Infrastructures().Get("cluster", metav1.GetOptions{})
+	Expect(err).NotTo(HaveOccurred(), "couldn't get cluster infrastructure")
+
+	cloud, ok := clouds[infra.Status.Platform]
+	if !ok {
+		skips.Skip(skips.CapabilityMissing, fmt.Sprintf("no storage expectations for platform '%s'", infra.Status.Platform))
+	}
+	return cloud
+}
+
+// defaultClass returns the cluster's default StorageClass.
+func defaultClass(h *helper.H) storagev1.StorageClass {
+	list, err := h.Kube().StorageV1().StorageClasses().List(metav1.ListOptions{})
+	Expect(err).NotTo(HaveOccurred(), "couldn't list StorageClasses")
+
+	var defaults []storagev1.StorageClass
+	for _, class := range list.Items {
+		if class.Annotations[defaultClassAnnotation] == "true" {
+			defaults = append(defaults, class)
+		}
+	}
+	Expect(defaults).To(HaveLen(1), "there should be exactly one default StorageClass")
+	return defaults[0]
+}
+
+// snapshotClassFor returns the name of a VolumeSnapshotClass for driver, or an empty string if there isn't one.
+func snapshotClassFor(h *helper.H, driver string) string {
+	list, err := h.Dynamic().Resource(snapshotClassGVR).List(metav1.ListOptions{})
+	if err != nil {
+		log.Printf("Couldn't list VolumeSnapshotClasses: %v", err)
+		return ""
+	}
+
+	for _, class := range list.Items {
+		if d, _, _ := unstructured.NestedString(class.Object, "driver"); d == driver {
+			return class.GetName()
+		}
+	}
+	return ""
+}
+
+// createSnapshot snapshots the PVC claim, returning the name of the VolumeSnapshot once it's ready to use.
+func createSnapshot(h *helper.H, name, class, claim string) string {
+	snapshot := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"volumeSnapshotClassName": class,
+				"source": map[string]interface{}{
+					"persistentVolumeClaimName": claim,
+				},
+			},
+		},
+	}
+	snapshot.SetAPIVersion(snapshotGVR.GroupVersion().String())
+	snapshot.SetKind("VolumeSnapshot")
+	snapshot.SetName(name)
+	snapshot.SetNamespace(h.CurrentProject())
+
+	client := h.Dynamic().Resource(snapshotGVR).Namespace(h.CurrentProject())
+	_, err := client.Create(snapshot, metav1.CreateOptions{})
+	Expect(err).NotTo(HaveOccurred(), "couldn't create VolumeSnapshot of '%s'", claim)
+
+	err = h.PollImmediate(10*time.Second, storageTimeout, func() (bool, error) {
+		obj, err := client.Get(name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		ready, _, _ := unstructured.NestedBool(obj.Object, "status", "readyToUse")
+		return ready, nil
+	})
+	Expect(err).NotTo(HaveOccurred(), "VolumeSnapshot '%s' wasn't ready to use", name)
+	return name
+}
+
+func newPVC(name, class string, mode kubev1.PersistentVolumeAccessMode) *kubev1.PersistentVolumeClaim {
+	pvc := &kubev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: kubev1.PersistentVolumeClaimSpec{
+			AccessModes: []kubev1.PersistentVolumeAccessMode{mode},
+			Resources: kubev1.ResourceRequirements{
+				Requests: kubev1.ResourceList{
+					kubev1.ResourceStorage: resource.MustParse(volumeSize),
+				},
+			},
+		},
+	}
+	if class != "" {
+		pvc.Spec.StorageClassName = &class
+	}
+	return pvc
+}
+
+// createPVC creates a PVC of class, using the default StorageClass if class is empty.
+func createPVC(h *helper.H, name, class string, mode kubev1.PersistentVolumeAccessMode) *kubev1.PersistentVolumeClaim {
+	pvc, err := h.Kube().CoreV1().PersistentVolumeClaims(h.CurrentProject()).Create(newPVC(name, class, mode))
+	Expect(err).NotTo(HaveOccurred(), "couldn't create PVC '%s'", name)
+	return pvc
+}
+
+// createPod creates a Pod mounting the PVC claim and waits for it to be running.
+func createPod(h *helper.H, name, claim string) *kubev1.Pod {
+	pod, err := h.Kube().CoreV1().Pods(h.CurrentProject()).Create(&kubev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: kubev1.PodSpec{
+			Containers: []kubev1.Container{
+				{
+					Name:    "volume",
+					Image:   volumeImage,
+					Command: []string{"sleep", "infinity"},
+					VolumeMounts: []kubev1.VolumeMount{
+						{
+							Name:      "data",
+							MountPath: mountPath,
+						},
+					},
+				},
+			},
+			Volumes: []kubev1.Volume{
+				{
+					Name: "data",
+					VolumeSource: kubev1.VolumeSource{
+						PersistentVolumeClaim: &kubev1.PersistentVolumeClaimVolumeSource{
+							ClaimName: claim,
+						},
+					},
+				},
+			},
+		},
+	})
+	Expect(err).NotTo(HaveOccurred(), "couldn't create Pod '%s'", name)
+
+	phase := h.WaitForPodPhase(pod, kubev1.PodRunning, 60, 10*time.Second)
+	Expect(phase).To(Equal(kubev1.PodRunning), "Pod '%s' mounting '%s' isn't running", name, claim)
+	return pod
+}
+
+// writeData writes data to the volume mounted by pod.
+func writeData(h *helper.H, pod *kubev1.Pod, data string) {
+	_, err := h.Exec(pod.Namespace, pod.Name, "volume", "sh", "-c", fmt.Sprintf("echo %s > %s && sync", data, dataFile))
+	Expect(err).NotTo(HaveOccurred(), "couldn't write to volume of Pod '%s'", pod.Name)
+}
+
+// readData returns the data written to the volume mounted by pod.
+func readData(h *helper.H, pod *kubev1.Pod) string {
+	result, err := h.Exec(pod.Namespace, pod.Name, "volume", "cat", dataFile)
+	Expect(err).NotTo(HaveOccurred(), "couldn't read volume of Pod '%s'", pod.Name)
+	return strings.TrimSpace(string(result.Stdout))
+}
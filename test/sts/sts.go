@@ -0,0 +1,177 @@
+// Package sts checks clusters using AWS Security Token Service (STS) give operators short-lived credentials by
+// assuming IAM roles which trust the cluster's service account issuer.
+package sts
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	kubev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/openshift/osde2e/pkg/groups"
+	"github.com/openshift/osde2e/pkg/helper"
+	"github.com/openshift/osde2e/pkg/security"
+	"github.com/openshift/osde2e/pkg/skips"
+	"github.com/openshift/osde2e/pkg/sts"
+)
+
+const (
+	// credentialsRequestNamespace contains the CredentialsRequests of operators.
+	credentialsRequestNamespace = "openshift-cloud-credential-operator"
+
+	// awsProviderSpec is the kind of CredentialsRequests for AWS credentials.
+	awsProviderSpec = "AWSProviderSpec"
+
+	// issuerInterval is how often the OIDC configuration of the service account issuer is requested.
+	issuerInterval = 5 * time.Second
+
+	// issuerTimeout is how long the service account issuer has to serve its OIDC configuration.
+	issuerTimeout = 30 * time.Second
+)
+
+var (
+	credentialsRequests = schema.GroupVersionResource{Group: "cloudcredential.openshift.io", Version: "v1", Resource: "credentialsrequests"}
+	authentications     = schema.GroupVersionResource{Group: "config.openshift.io", Version: "v1", Resource: "authentications"}
+)
+
+var _ = groups.Describe(groups.Security, "STS Credentials", func() {
+	h := helper.New()
+
+	var issuer string
+	ginkgo.BeforeEach(func() {
+		h.SkipLocal()
+
+		auth, err := h.GetResource(authentications, "", "cluster")
+		Expect(err).NotTo(HaveOccurred())
+		if issuer, _, _ = unstructured.NestedString(auth.Object, "spec", "serviceAccountIssuer"); issuer == "" {
+			skips.Skip(skips.CapabilityMissing, "cluster doesn't use STS")
+		}
+	})
+
+	ginkgo.It("should give each operator an IAM role to assume", func() {
+		var problems []string
+		for _, req := range awsCredentialsRequests(h) {
+			if len(req.serviceAccounts) == 0 {
+				problems = append(problems, fmt.Sprintf("%s: no service accounts are trusted by the role", req))
+			}
+
+			secret, err := h.Kube().CoreV1().Secrets(req.secretNamespace).Get(req.secretName, metav1.GetOptions{})
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s: couldn't get credentials: %v", req, err))
+				continue
+			}
+
+			creds, err := sts.ParseCredentials(secret.Data[sts.CredentialsKey])
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s: couldn't parse credentials: %v", req, err))
+				continue
+			}
+			for _, p := range creds.Problems() {
+				problems = append(problems, fmt.Sprintf("%s: %s", req, p))
+			}
+		}
+		Expect(problems).To(BeEmpty(), "operators aren't given IAM roles")
+	})
+
+	ginkgo.It("should serve the OIDC configuration trusted by operator roles", func() {
+		// AWS only trusts issuers serving valid certificates
+		checker := h.HTTPChecker().VerifyTLS().Retry(issuerInterval, issuerTimeout)
+		Expect(sts.CheckIssuer(checker.Get, issuer)).To(Succeed())
+	})
+
+	ginkgo.It("should run operators as the service accounts their roles trust", func() {
+		var problems []string
+		checked := 0
+		for _, req := range awsCredentialsRequests(h) {
+			pods, err := h.Kube().CoreV1().Pods(req.secretNamespace).List(metav1.ListOptions{})
+			Expect(err).NotTo(HaveOccurred(), "couldn't list pods of %s", req)
+
+			for i := range pods.Items {
+				pod := &pods.Items[i]
+				if pod.Status.Phase != kubev1.PodRunning || !sts.MountsSecret(pod, req.secretName) {
+					continue
+				}
+				checked++
+
+				name := pod.Namespace + "/" + pod.Name
+				if !req.trusts(pod.Spec.ServiceAccountName) {
+					problems = append(problems, fmt.Sprintf("pod '%s' runs as '%s' which isn't trusted by the role of %s",
+						name, pod.Spec.ServiceAccountName, req))
+				}
+				if len(sts.TokenAudiences(pod)) == 0 {
+					problems = append(problems, fmt.Sprintf("pod '%s' has no service account token to assume the role of %s",
+						name, req))
+				}
+			}
+		}
+		Expect(checked).NotTo(BeZero(), "no running pods use operator credentials")
+		Expect(problems).To(BeEmpty(), "operators can't assume their IAM roles")
+	})
+
+	ginkgo.It("should not have static AWS keys in managed namespaces", func() {
+		allowlist, err := security.LoadAllowlist(h.SecurityAllowlist)
+		Expect(err).NotTo(HaveOccurred())
+
+		secrets, err := h.Kube().CoreV1().Secrets(metav1.NamespaceAll).List(metav1.ListOptions{})
+		Expect(err).NotTo(HaveOccurred(), "couldn't list secrets")
+
+		var found []string
+		for i := range secrets.Items {
+			secret := &secrets.Items[i]
+			if !allowlist.Managed(secret.Namespace) {
+				continue
+			}
+			for _, k := range sts.StaticKeys(secret) {
+				found = append(found, fmt.Sprintf("%s/%s: %s", secret.Namespace, secret.Name, k))
+			}
+		}
+		Expect(found).To(BeEmpty(), "static AWS keys were found")
+	})
+})
+
+// credentialsRequest is the AWS credentials requested by an operator.
+type credentialsRequest struct {
+	name                        string
+	secretNamespace, secretName string
+	serviceAccounts             []string
+}
+
+func (r credentialsRequest) String() string {
+	return fmt.Sprintf("CredentialsRequest '%s'", r.name)
+}
+
+// trusts returns true if the role of the request may be assumed by serviceAccount.
+func (r credentialsRequest) trusts(serviceAccount string) bool {
+	for _, sa := range r.serviceAccounts {
+		if sa == serviceAccount {
+			return true
+		}
+	}
+	return false
+}
+
+// awsCredentialsRequests lists the requests of operators for AWS credentials.
+func awsCredentialsRequests(h *helper.H) (reqs []credentialsRequest) {
+	list, err := h.Dynamic().Resource(credentialsRequests).Namespace(credentialsRequestNamespace).List(metav1.ListOptions{})
+	Expect(err).NotTo(HaveOccurred(), "couldn't list CredentialsRequests")
+
+	for _, obj := range list.Items {
+		if kind, _, _ := unstructured.NestedString(obj.Object, "spec", "providerSpec", "kind"); kind != awsProviderSpec {
+			continue
+		}
+
+		req := credentialsRequest{name: obj.GetName()}
+		req.secretNamespace, _, _ = unstructured.NestedString(obj.Object, "spec", "secretRef", "namespace")
+		req.secretName, _, _ = unstructured.NestedString(obj.Object, "spec", "secretRef", "name")
+		req.serviceAccounts, _, _ = unstructured.NestedStringSlice(obj.Object, "spec", "serviceAccountNames")
+		reqs = append(reqs, req)
+	}
+	Expect(reqs).NotTo(BeEmpty(), "no CredentialsRequests for AWS were found")
+	return
+}
@@ -0,0 +1,78 @@
+package telemetry
+
+import (
+	"fmt"
+
+	"github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	configv1 "github.com/openshift/api/config/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openshift/osde2e/pkg/groups"
+	"github.com/openshift/osde2e/pkg/helper"
+)
+
+const (
+	// insightsOperator is the ClusterOperator which uploads Insights data.
+	insightsOperator = "insights"
+
+	// insightsDisabled is the condition of the insights operator set when uploads are turned off.
+	insightsDisabled configv1.ClusterStatusConditionType = "Disabled"
+
+	// insightsUploads counts upload attempts by status code.
+	insightsUploads = `sum(insightsclient_request_send_total{client="insights",status_code=~"2.."})`
+)
+
+var _ = groups.Describe(groups.Operators, "Insights", func() {
+	h := helper.New()
+
+	// compliance environments don't send data back to Red Hat
+	ginkgo.BeforeEach(func() {
+		h.SkipFedRAMP("Insights uploading")
+	})
+
+	ginkgo.It("should upload if enabled", func() {
+		enabled, err := reportingEnabled(h)
+		Expect(err).NotTo(HaveOccurred())
+
+		var notUploaded error
+		err = h.PollImmediate(pollInterval, reportTimeout, func() (bool, error) {
+			co, err := h.Cfg().ConfigV1().ClusterOperators().Get(insightsOperator, metav1.GetOptions{})
+			if err != nil {
+				notUploaded = err
+				return false, nil
+			}
+
+			conditions := map[configv1.ClusterStatusConditionType]configv1.ClusterOperatorStatusCondition{}
+			for _, c := range co.Status.Conditions {
+				conditions[c.Type] = c
+			}
+
+			// uploads failing mark the operator as degraded
+			if c := conditions[configv1.OperatorDegraded]; c.Status == configv1.ConditionTrue {
+				notUploaded = fmt.Errorf("operator is degraded: %s", c.Message)
+				return false, nil
+			}
+
+			if disabled := conditions[insightsDisabled].Status == configv1.ConditionTrue; disabled == enabled {
+				notUploaded = fmt.Errorf("expected uploads enabled to be %t, got %t: %s",
+					enabled, !disabled, conditions[insightsDisabled].Message)
+				return false, nil
+			} else if !enabled {
+				return true, nil
+			}
+
+			uploads, err := queryPrometheus(h, insightsUploads)
+			if err != nil {
+				notUploaded = fmt.Errorf("couldn't query uploads: %v", err)
+				return false, nil
+			} else if uploads <= 0 {
+				notUploaded = fmt.Errorf("no successful uploads")
+				return false, nil
+			}
+			return true, nil
+		})
+		Expect(err).NotTo(HaveOccurred(), "insights data wasn't uploaded: %v", notUploaded)
+	})
+})
@@ -0,0 +1,122 @@
+// Package telemetry checks the cluster reports telemetry and Insights data to Red Hat, or has reporting disabled
+// when it isn't configured to.
+package telemetry
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openshift/osde2e/pkg/groups"
+	"github.com/openshift/osde2e/pkg/helper"
+)
+
+const (
+	// cloudRegistry is the registry in the pull secret whose token is used to report telemetry and Insights data.
+	// Reporting is disabled when it's missing.
+	cloudRegistry = "cloud.openshift.com"
+
+	// telemeter-client forwards metrics to Red Hat
+	telemeterNamespace  = "openshift-monitoring"
+	telemeterDeployment = "telemeter-client"
+
+	// reportTimeout is how long to wait for data to be reported. telemeter-client forwards every 4m30s.
+	reportTimeout = 10 * time.Minute
+
+	// pollInterval is how often reporting is checked.
+	pollInterval = 30 * time.Second
+)
+
+// telemeterQueries must return a value above zero when telemetry is being sent.
+var telemeterQueries = map[string]string{
+	"successful requests": `sum(federate_requests_total{job="telemeter-client"}) - sum(federate_requests_failed_total{job="telemeter-client"})`,
+	"samples forwarded":   `sum(federate_samples{job="telemeter-client"})`,
+}
+
+var _ = groups.Describe(groups.Operators, "Telemetry", func() {
+	h := helper.New()
+
+	// compliance environments don't send data back to Red Hat
+	ginkgo.BeforeEach(func() {
+		h.SkipFedRAMP("telemetry")
+	})
+
+	ginkgo.It("should be reported if enabled", func() {
+		enabled, err := reportingEnabled(h)
+		Expect(err).NotTo(HaveOccurred())
+
+		deployments := h.Kube().AppsV1().Deployments(telemeterNamespace)
+		if !enabled {
+			_, err = deployments.Get(telemeterDeployment, metav1.GetOptions{})
+			Expect(kerror.IsNotFound(err)).To(BeTrue(), "telemeter-client should not run without a %s token", cloudRegistry)
+			return
+		}
+
+		var notReported error
+		err = h.PollImmediate(pollInterval, reportTimeout, func() (bool, error) {
+			d, err := deployments.Get(telemeterDeployment, metav1.GetOptions{})
+			if err != nil {
+				notReported = err
+				return false, nil
+			} else if d.Status.AvailableReplicas == 0 {
+				notReported = fmt.Errorf("deployment '%s' isn't available", telemeterDeployment)
+				return false, nil
+			}
+
+			for name, query := range telemeterQueries {
+				value, err := queryPrometheus(h, query)
+				if err != nil {
+					notReported = fmt.Errorf("couldn't query %s: %v", name, err)
+					return false, nil
+				} else if value <= 0 {
+					notReported = fmt.Errorf("no %s by telemeter-client", name)
+					return false, nil
+				}
+			}
+			return true, nil
+		})
+		Expect(err).NotTo(HaveOccurred(), "telemetry wasn't reported: %v", notReported)
+	})
+})
+
+// reportingEnabled is true if the cluster's pull secret allows it to report to Red Hat.
+func reportingEnabled(h *helper.H) (bool, error) {
+	secret, err := h.Kube().CoreV1().Secrets("openshift-config").Get("pull-secret", metav1.GetOptions{})
+	if err != nil {
+		return false, fmt.Errorf("couldn't get pull secret: %v", err)
+	}
+
+	var config struct {
+		Auths map[string]struct {
+			Auth string `json:"auth"`
+		} `json:"auths"`
+	}
+	if err = json.Unmarshal(secret.Data[".dockerconfigjson"], &config); err != nil {
+		return false, fmt.Errorf("couldn't decode pull secret: %v", err)
+	}
+
+	enabled := config.Auths[cloudRegistry].Auth != ""
+	log.Printf("Reporting to Red Hat is enabled: %t", enabled)
+	return enabled, nil
+}
+
+// queryPrometheus returns the sum of the samples returned by query.
+func queryPrometheus(h *helper.H, query string) (float64, error) {
+	samples, err := h.QueryPrometheus(query, time.Time{})
+	if err != nil {
+		return 0, err
+	}
+
+	var sum float64
+	for _, s := range samples {
+		sum += s.Value
+	}
+	return sum, nil
+}
@@ -9,14 +9,15 @@ import (
 	. "github.com/onsi/gomega"
 
 	"k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/util/wait"
 
 	"github.com/openshift/osde2e/pkg/helper"
 )
 
 var _ = ginkgo.Describe("Pods", func() {
 	h := helper.New()
+	h.Requires(rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"list"}})
 
 	ginkgo.It("should be Running or Succeeded", func() {
 		var (
@@ -28,7 +29,7 @@ var _ = ginkgo.Describe("Pods", func() {
 			notReady      []v1.Pod
 		)
 
-		err := wait.Poll(interval, timeout, func() (done bool, err error) {
+		err := h.Poll(interval, timeout, func() (done bool, err error) {
 			if curRatio != 0 {
 				log.Printf("Checking that all Pods are running or completed (currently %f%%)...", curRatio)
 			}
@@ -3,14 +3,15 @@ package verify
 import (
 	"crypto/tls"
 	"fmt"
-	"net/http"
 
 	"github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
 	"github.com/openshift/api/route/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/openshift/osde2e/pkg/groups"
 	"github.com/openshift/osde2e/pkg/helper"
 )
 
@@ -19,8 +20,9 @@ const (
 	consoleLabel     = "console"
 )
 
-var _ = ginkgo.Describe("Routes", func() {
+var _ = groups.Describe(groups.Networking, "Routes", func() {
 	h := helper.New()
+	h.Requires(rbacv1.PolicyRule{APIGroups: []string{"route.openshift.io"}, Resources: []string{"routes"}, Verbs: []string{"list"}})
 
 	ginkgo.It("should be created for Console", func() {
 		consoleRoutes(h)
@@ -28,7 +30,7 @@ var _ = ginkgo.Describe("Routes", func() {
 
 	ginkgo.It("should be functioning for Console", func() {
 		for _, route := range consoleRoutes(h) {
-			testRouteIngresses(route)
+			testRouteIngresses(h, route)
 		}
 	})
 })
@@ -48,24 +50,13 @@ func consoleRoutes(h *helper.H) []v1.Route {
 	return list.Items
 }
 
-func testRouteIngresses(route v1.Route) {
+func testRouteIngresses(h *helper.H, route v1.Route) {
 	Expect(route.Status.Ingress).ShouldNot(HaveLen(0),
 		"no ingresses have been setup for the route '%s/%s'", route.Namespace, route.Name)
 
-	client := &http.Client{
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: true,
-			},
-		},
-	}
-
 	for _, ingress := range route.Status.Ingress {
 		consoleURL := fmt.Sprintf("https://%s", ingress.Host)
-
-		resp, err := client.Get(consoleURL)
+		err := h.HTTPChecker().ExpectTLS(tls.VersionTLS12).Check(consoleURL)
 		Expect(err).NotTo(HaveOccurred(), "failed retrieving Console site")
-		Expect(resp).NotTo(BeNil())
-		Expect(resp.StatusCode).To(Equal(http.StatusOK))
 	}
 }
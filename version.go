@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"strings"
 
 	"github.com/openshift/osde2e/pkg/config"
 	"github.com/openshift/osde2e/pkg/osd"
@@ -18,7 +19,7 @@ func ChooseVersions(cfg *config.Config, osd *osd.OSD) (err error) {
 		return nil
 	} else if osd == nil {
 		return errors.New("osd must be setup when upgrading with release stream")
-	} else if cfg.UpgradeImage == "" && cfg.UpgradeReleaseStream != "" {
+	} else if cfg.UpgradeImage == "" && len(cfg.UpgradeImages) == 0 && cfg.UpgradeReleaseStream != "" {
 		return setupUpgradeVersion(cfg, osd)
 	} else {
 		return setupVersion(cfg, osd)
@@ -65,9 +66,24 @@ func setupUpgradeVersion(cfg *config.Config, osd *osd.OSD) (err error) {
 
 func buildVersion(cfg *config.Config) string {
 	// use just version if not upgrading
-	if cfg.UpgradeReleaseStream == "" && cfg.UpgradeImage == "" {
+	if cfg.UpgradeReleaseStream == "" && cfg.UpgradeImage == "" && len(cfg.UpgradeImages) == 0 {
 		return cfg.ClusterVersion
 	}
 
+	// include every image when upgrading through a chain
+	if len(cfg.UpgradeImages) != 0 {
+		return fmt.Sprintf("%s-%s", cfg.ClusterVersion, strings.Join(cfg.UpgradeImages, "-"))
+	}
+
 	return fmt.Sprintf("%s-%s", cfg.ClusterVersion, cfg.UpgradeReleaseName)
 }
+
+// upgradeVersion is the version the cluster is upgraded to, or every image when upgrading through a chain.
+func upgradeVersion(cfg *config.Config) string {
+	if len(cfg.UpgradeImages) != 0 {
+		return strings.Join(cfg.UpgradeImages, ",")
+	} else if cfg.UpgradeReleaseName != "" {
+		return cfg.UpgradeReleaseName
+	}
+	return cfg.UpgradeImage
+}